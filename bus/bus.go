@@ -0,0 +1,173 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bus
+
+import (
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	sched "github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_scheduler"
+	"github.com/verizonlabs/mesos-framework-sdk/scheduler"
+	"sync"
+	"time"
+)
+
+// Event types published on the bus.
+const (
+	TASK_EVENT   = "task"
+	OFFERS_EVENT = "offers"
+)
+
+// How many events a subscriber can fall behind by before it starts missing them.
+const DEFAULT_BUFFER = 64
+
+type Event struct {
+	Type string      `json:"type"`
+	Time int64       `json:"time"` // Unix time in nanoseconds the event was published.
+	Data interface{} `json:"data"`
+}
+
+// Published whenever a task's status changes.
+type TaskTransition struct {
+	Id      string `json:"id"`
+	State   string `json:"state"`
+	Agent   string `json:"agent,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+	Healthy *bool  `json:"healthy,omitempty"`
+}
+
+// Published for every batch of offers received.
+type OfferStats struct {
+	Offers int     `json:"offers"`
+	Agents int     `json:"agents"`
+	Cpus   float64 `json:"cpus"`
+	Mem    float64 `json:"mem"`
+	Disk   float64 `json:"disk"`
+	Gpus   float64 `json:"gpus"`
+}
+
+type subscriber struct {
+	events chan *Event
+	types  map[string]bool
+}
+
+// Republishes what happens in the framework to anyone listening, such as dashboards.
+// Subscribers that fall too far behind miss events instead of holding up the framework.
+type Bus struct {
+	lock        sync.RWMutex
+	subscribers map[*subscriber]struct{}
+	buffer      int
+}
+
+func NewBus(buffer int) *Bus {
+	return &Bus{
+		subscribers: make(map[*subscriber]struct{}),
+		buffer:      buffer,
+	}
+}
+
+// Sends the event to every subscriber that wants its type.
+func (b *Bus) Publish(event *Event) {
+	if event.Time == 0 {
+		event.Time = time.Now().UnixNano()
+	}
+
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	for s := range b.subscribers {
+		if len(s.types) > 0 && !s.types[event.Type] {
+			continue
+		}
+
+		select {
+		case s.events <- event:
+		default:
+		}
+	}
+}
+
+// Gets events of the given types, or of every type when none are given, until cancel is called.
+func (b *Bus) Subscribe(types ...string) (events <-chan *Event, cancel func()) {
+	s := &subscriber{
+		events: make(chan *Event, b.buffer),
+		types:  make(map[string]bool, len(types)),
+	}
+	for _, t := range types {
+		s.types[t] = true
+	}
+
+	b.lock.Lock()
+	b.subscribers[s] = struct{}{}
+	b.lock.Unlock()
+
+	var once sync.Once
+	return s.events, func() {
+		once.Do(func() {
+			b.lock.Lock()
+			delete(b.subscribers, s)
+			b.lock.Unlock()
+		})
+	}
+}
+
+// Publishes task transitions and offer statistics from the events the scheduler dispatches.
+func (b *Bus) EventInterceptor() scheduler.EventInterceptor {
+	return func(event *sched.Event, next scheduler.EventInvoker) {
+		switch event.GetType() {
+		case sched.Event_UPDATE:
+			status := event.GetUpdate().GetStatus()
+			transition := &TaskTransition{
+				Id:      status.GetTaskId().GetValue(),
+				State:   status.GetState().String(),
+				Agent:   status.GetAgentId().GetValue(),
+				Message: status.GetMessage(),
+				Healthy: status.Healthy,
+			}
+			if status.Reason != nil {
+				transition.Reason = status.GetReason().String()
+			}
+			b.Publish(&Event{Type: TASK_EVENT, Data: transition})
+		case sched.Event_OFFERS:
+			b.Publish(&Event{Type: OFFERS_EVENT, Data: stats(event.GetOffers().GetOffers())})
+		}
+
+		next(event)
+	}
+}
+
+// Totals up the scalar resources in the offers.
+func stats(offers []*mesos_v1.Offer) *OfferStats {
+	s := &OfferStats{Offers: len(offers)}
+	agents := make(map[string]bool)
+	for _, offer := range offers {
+		agents[offer.GetAgentId().GetValue()] = true
+		for _, r := range offer.GetResources() {
+			value := r.GetScalar().GetValue()
+			switch r.GetName() {
+			case "cpus":
+				s.Cpus += value
+			case "mem":
+				s.Mem += value
+			case "disk":
+				s.Disk += value
+			case "gpus":
+				s.Gpus += value
+			}
+		}
+	}
+	s.Agents = len(agents)
+
+	return s
+}
@@ -0,0 +1,113 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bus
+
+import (
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	sched "github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_scheduler"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"testing"
+)
+
+func offer(agent string, cpus, mem float64) *mesos_v1.Offer {
+	return &mesos_v1.Offer{
+		AgentId: &mesos_v1.AgentID{Value: utils.ProtoString(agent)},
+		Resources: []*mesos_v1.Resource{
+			{Name: utils.ProtoString("cpus"), Scalar: &mesos_v1.Value_Scalar{Value: utils.ProtoFloat64(cpus)}},
+			{Name: utils.ProtoString("mem"), Scalar: &mesos_v1.Value_Scalar{Value: utils.ProtoFloat64(mem)}},
+		},
+	}
+}
+
+// Ensures subscribers only get the event types they asked for and slow ones don't block.
+func TestBus(t *testing.T) {
+	t.Parallel()
+
+	b := NewBus(1)
+	all, cancelAll := b.Subscribe()
+	tasks, cancelTasks := b.Subscribe(TASK_EVENT)
+
+	b.Publish(&Event{Type: OFFERS_EVENT})
+	b.Publish(&Event{Type: TASK_EVENT})
+	if e := <-all; e.Type != OFFERS_EVENT || e.Time == 0 {
+		t.Fatal("Subscribers to every type should get events in order")
+	}
+	if e := <-tasks; e.Type != TASK_EVENT {
+		t.Fatal("Subscribers should only get the types they asked for")
+	}
+
+	cancelTasks()
+	cancelTasks()
+	b.Publish(&Event{Type: TASK_EVENT})
+	select {
+	case <-tasks:
+		t.Fatal("Cancelled subscribers shouldn't get events")
+	default:
+	}
+	cancelAll()
+}
+
+// Measures performance of publishing an event.
+func BenchmarkBus(b *testing.B) {
+	bus := NewBus(DEFAULT_BUFFER)
+	bus.Subscribe()
+	for n := 0; n < b.N; n++ {
+		bus.Publish(&Event{Type: TASK_EVENT})
+	}
+}
+
+// Ensures task transitions and offer statistics are published from scheduler events.
+func TestBus_EventInterceptor(t *testing.T) {
+	t.Parallel()
+
+	b := NewBus(2)
+	events, cancel := b.Subscribe()
+	defer cancel()
+
+	intercept := b.EventInterceptor()
+	next := func(*sched.Event) {}
+	intercept(&sched.Event{
+		Type: sched.Event_UPDATE.Enum(),
+		Update: &sched.Event_Update{Status: &mesos_v1.TaskStatus{
+			TaskId: &mesos_v1.TaskID{Value: utils.ProtoString("web")},
+			State:  mesos_v1.TaskState_TASK_RUNNING.Enum(),
+		}},
+	}, next)
+	intercept(&sched.Event{
+		Type:   sched.Event_OFFERS.Enum(),
+		Offers: &sched.Event_Offers{Offers: []*mesos_v1.Offer{offer("a", 1, 128), offer("a", 2, 256), offer("b", 1, 64)}},
+	}, next)
+
+	transition, ok := (<-events).Data.(*TaskTransition)
+	if !ok || transition.Id != "web" || transition.State != "TASK_RUNNING" {
+		t.Fatal("Status updates should be published as task transitions")
+	}
+	s, ok := (<-events).Data.(*OfferStats)
+	if !ok || s.Offers != 3 || s.Agents != 2 || s.Cpus != 4 || s.Mem != 448 {
+		t.Fatalf("Unexpected offer stats %+v", s)
+	}
+}
+
+// Measures performance of publishing offer statistics.
+func BenchmarkBus_EventInterceptor(b *testing.B) {
+	intercept := NewBus(DEFAULT_BUFFER).EventInterceptor()
+	event := &sched.Event{
+		Type:   sched.Event_OFFERS.Enum(),
+		Offers: &sched.Event_Offers{Offers: []*mesos_v1.Offer{offer("a", 1, 128)}},
+	}
+	for n := 0; n < b.N; n++ {
+		intercept(event, func(*sched.Event) {})
+	}
+}
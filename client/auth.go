@@ -0,0 +1,53 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"encoding/base64"
+)
+
+// Supplies the Authorization header sent with the SUBSCRIBE call and every call after it.
+type Credentials interface {
+	Authorization() (string, error)
+}
+
+// Principal and secret used for HTTP basic authentication against the master.
+type BasicCredentials struct {
+	Principal string
+	Secret    string
+}
+
+func (b BasicCredentials) Authorization() (string, error) {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(b.Principal+":"+b.Secret)), nil
+}
+
+// Static bearer token, such as a DC/OS service account token.
+type TokenCredentials string
+
+func (t TokenCredentials) Authorization() (string, error) {
+	return "Bearer " + string(t), nil
+}
+
+// Fetches a bearer token on each call so tokens can be refreshed before they expire.
+type TokenFunc func() (string, error)
+
+func (f TokenFunc) Authorization() (string, error) {
+	token, err := f()
+	if err != nil {
+		return "", err
+	}
+
+	return "Bearer " + token, nil
+}
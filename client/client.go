@@ -16,67 +16,76 @@ package client
 
 import (
 	"bytes"
-	"errors"
-	"io/ioutil"
+	"context"
 	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_executor"
 	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_scheduler"
 	"github.com/verizonlabs/mesos-framework-sdk/logging"
-	"net"
 	"net/http"
-	"strings"
-	"time"
 
 	"github.com/golang/protobuf/proto"
 )
 
 type Client interface {
 	Request(interface{}) (*http.Response, error)
+	RequestContext(context.Context, interface{}) (*http.Response, error)
 	StreamID() string
 	SetStreamID(string) Client
 }
 
+// Implemented by clients that can be pointed at another master, such as when a new leader is detected.
+type Redirectable interface {
+	Endpoint() string
+	SetEndpoint(string)
+}
+
+// Where to send calls and how to authenticate them.
+// Credentials take precedence over the raw Auth header when both are set.
 type ClientData struct {
-	Endpoint string
-	Auth     string
+	Endpoint    string
+	Auth        string
+	Credentials Credentials
 }
 
 // HTTP client.
 type DefaultClient struct {
 	streamID string
 	data     ClientData
-	client   *http.Client
+	client   *http.Client // Pooled connections for regular calls.
+	stream   *http.Client // Connections for subscriptions.
 	logger   logging.Logger
 }
 
 // Return a new HTTP client.
 func NewClient(data ClientData, logger logging.Logger) Client {
-	return &DefaultClient{
-		data: data,
-		client: &http.Client{
-			Transport: &http.Transport{
-				Dial: (&net.Dialer{
-					Timeout:   10 * time.Second,
-					KeepAlive: 30 * time.Second,
-				}).Dial,
-			},
-		},
-		logger: logger,
-	}
+
+	// Without TLS there's nothing in the default configuration that can fail.
+	c, _ := NewClientWithConfig(data, DefaultClientConfig(), logger)
+
+	return c
 }
 
 // Makes a new request with data and sends it to the server.
 // Determines whether the request/response should be handled for an executor or a scheduler.
 func (c *DefaultClient) Request(call interface{}) (*http.Response, error) {
+	return c.RequestContext(context.Background(), call)
+}
+
+// Same as Request, but the request is canceled when the context is done.
+// For subscriptions this includes reading the event stream.
+func (c *DefaultClient) RequestContext(ctx context.Context, call interface{}) (*http.Response, error) {
 	var data []byte
 	var err error
 	var executorCall bool
+	var subscribe bool
 
 	switch call := call.(type) {
 	case *mesos_v1_scheduler.Call:
 		data, err = proto.Marshal(call)
+		subscribe = call.GetType() == mesos_v1_scheduler.Call_SUBSCRIBE
 	case *mesos_v1_executor.Call:
 		data, err = proto.Marshal(call)
 		executorCall = true
+		subscribe = call.GetType() == mesos_v1_executor.Call_SUBSCRIBE
 	}
 
 	if err != nil {
@@ -87,8 +96,17 @@ func (c *DefaultClient) Request(call interface{}) (*http.Response, error) {
 	if err != nil {
 		return nil, err
 	}
+	req = req.WithContext(ctx)
+
+	auth := c.data.Auth
+	if c.data.Credentials != nil {
+		auth, err = c.data.Credentials.Authorization()
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	req.Header.Set("Authorization", c.data.Auth)
+	req.Header.Set("Authorization", auth)
 	req.Header.Set("Connection", "keep-alive")
 	req.Header.Set("Content-Type", "application/x-protobuf")
 	req.Header.Set("Accept", "application/x-protobuf")
@@ -100,22 +118,18 @@ func (c *DefaultClient) Request(call interface{}) (*http.Response, error) {
 		req.Header.Set("Mesos-Stream-Id", c.streamID)
 	}
 
-	resp, err := c.client.Do(req)
+	httpClient := c.client
+	if subscribe {
+		httpClient = c.stream
+	}
+
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 
 	if resp.StatusCode >= 400 {
-		if resp.StatusCode == 401 {
-			return resp, errors.New("Unauthorized")
-		}
-
-		data, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return resp, err
-		}
-
-		return resp, errors.New(string(data))
+		return resp, decodeError(resp)
 	}
 
 	// Our master detection only applies to the scheduler.
@@ -130,16 +144,10 @@ func (c *DefaultClient) Request(call interface{}) (*http.Response, error) {
 		if resp.StatusCode == http.StatusTemporaryRedirect || resp.StatusCode == http.StatusPermanentRedirect {
 			c.logger.Emit(logging.INFO, "Old master: %s", c.data.Endpoint)
 
-			master := resp.Header.Get("Location")
-			if strings.Contains(master, "http") {
-				c.data.Endpoint = master
-			} else {
-				c.data.Endpoint = resp.Request.URL.Scheme + ":" + master
-			}
-
+			c.data.Endpoint = leaderURL(resp)
 			c.logger.Emit(logging.INFO, "New master: %s", c.data.Endpoint)
 
-			return nil, errors.New("Redirect encountered, new master found")
+			return nil, &ErrNotLeader{LeaderURL: c.data.Endpoint}
 		}
 	}
 
@@ -157,3 +165,13 @@ func (c *DefaultClient) SetStreamID(id string) Client {
 
 	return c
 }
+
+// Gets the endpoint we're sending calls to.
+func (c *DefaultClient) Endpoint() string {
+	return c.data.Endpoint
+}
+
+// Points the client at a new endpoint.
+func (c *DefaultClient) SetEndpoint(endpoint string) {
+	c.data.Endpoint = endpoint
+}
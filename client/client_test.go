@@ -15,12 +15,34 @@
 package client
 
 import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"github.com/golang/protobuf/proto"
 	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
 	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_executor"
 	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_scheduler"
+	"github.com/verizonlabs/mesos-framework-sdk/recordio"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 type mockLogger struct{}
@@ -165,3 +187,590 @@ func BenchmarkDefaultClient_Request(b *testing.B) {
 		c.Request(nil)
 	}
 }
+
+// Ensures transient failures are retried only for calls that are safe to repeat.
+func TestRetryClient_Request(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1)%3 != 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	policy := NewRetryPolicy()
+	policy.MinBackoff = time.Millisecond
+	c := NewRetryClient(NewClient(ClientData{Endpoint: ts.URL}, l), policy, l)
+
+	_, err := c.Request(&mesos_v1_scheduler.Call{Type: mesos_v1_scheduler.Call_DECLINE.Enum()})
+	if err != nil || atomic.LoadInt32(&calls) != 3 {
+		t.Fatal("Idempotent calls should be retried until they succeed")
+	}
+
+	_, err = c.Request(&mesos_v1_scheduler.Call{Type: mesos_v1_scheduler.Call_ACCEPT.Enum()})
+	if err == nil || atomic.LoadInt32(&calls) != 4 {
+		t.Fatal("Non-idempotent calls should not be retried")
+	}
+
+	policy.RetryNonIdempotent = true
+	_, err = c.Request(&mesos_v1_scheduler.Call{Type: mesos_v1_scheduler.Call_ACCEPT.Enum()})
+	if err != nil || atomic.LoadInt32(&calls) != 6 {
+		t.Fatal("Non-idempotent calls should be retried when instructed to")
+	}
+
+	policy.MinBackoff = time.Minute
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = c.RequestContext(ctx, &mesos_v1_scheduler.Call{Type: mesos_v1_scheduler.Call_KILL.Enum()})
+	if err != context.Canceled {
+		t.Fatal("Retrying should stop once the context is done")
+	}
+
+	policy.MinBackoff = time.Millisecond
+	c = NewRetryClient(NewClient(ClientData{Endpoint: ts.URL}, l), &RetryPolicy{MaxAttempts: 10}, l)
+	atomic.StoreInt32(&calls, 0)
+	_, err = c.Request(&mesos_v1_scheduler.Call{Type: mesos_v1_scheduler.Call_KILL.Enum()})
+	if err == nil || atomic.LoadInt32(&calls) != 1 {
+		t.Fatal("Calls should not be retried once the budget is spent")
+	}
+}
+
+// Ensures calls are held back or rejected once their type is over its rate limit.
+func TestRateLimitClient_Request(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	var throttled, rejected int32
+	c := NewRateLimitClient(NewClient(ClientData{Endpoint: ts.URL}, l), map[mesos_v1_scheduler.Call_Type]RateLimit{
+		mesos_v1_scheduler.Call_DECLINE: {Rate: 100, Burst: 2},
+	})
+	c.Throttled = func(_ mesos_v1_scheduler.Call_Type, _ time.Duration, r bool) {
+		atomic.AddInt32(&throttled, 1)
+		if r {
+			atomic.AddInt32(&rejected, 1)
+		}
+	}
+
+	for n := 0; n < 3; n++ {
+		if _, err := c.Request(&mesos_v1_scheduler.Call{Type: mesos_v1_scheduler.Call_DECLINE.Enum()}); err != nil {
+			t.Fatal("Limited calls should be delayed rather than fail: " + err.Error())
+		}
+	}
+	if atomic.LoadInt32(&calls) != 3 || atomic.LoadInt32(&throttled) != 1 {
+		t.Fatal("Only calls beyond the burst should be throttled")
+	}
+
+	for n := 0; n < 5; n++ {
+		if _, err := c.Request(&mesos_v1_scheduler.Call{Type: mesos_v1_scheduler.Call_ACCEPT.Enum()}); err != nil {
+			t.Fatal("Calls without a limit should go straight through")
+		}
+	}
+	if atomic.LoadInt32(&throttled) != 1 {
+		t.Fatal("Calls without a limit should never be throttled")
+	}
+
+	c.MaxWait = time.Nanosecond
+	c.Request(&mesos_v1_scheduler.Call{Type: mesos_v1_scheduler.Call_DECLINE.Enum()})
+	c.Request(&mesos_v1_scheduler.Call{Type: mesos_v1_scheduler.Call_DECLINE.Enum()})
+	_, err := c.Request(&mesos_v1_scheduler.Call{Type: mesos_v1_scheduler.Call_DECLINE.Enum()})
+	if err != ErrThrottled || atomic.LoadInt32(&rejected) == 0 {
+		t.Fatal("Calls that would wait too long should be rejected")
+	}
+}
+
+// Ensures calls that give up waiting hand their reserved token back.
+func TestRateLimitClient_RequestContext(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	c := NewRateLimitClient(NewClient(ClientData{Endpoint: ts.URL}, l), map[mesos_v1_scheduler.Call_Type]RateLimit{
+		mesos_v1_scheduler.Call_DECLINE: {Rate: 0.1, Burst: 1},
+	})
+	call := &mesos_v1_scheduler.Call{Type: mesos_v1_scheduler.Call_DECLINE.Enum()}
+	if _, err := c.Request(call); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	for n := 0; n < 3; n++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		_, err := c.RequestContext(ctx, call)
+		cancel()
+		if err != context.DeadlineExceeded {
+			t.Fatal("Calls should give up waiting once their context is done")
+		}
+	}
+
+	c.Lock()
+	tokens := c.buckets[mesos_v1_scheduler.Call_DECLINE].tokens
+	c.Unlock()
+	if tokens < -0.5 {
+		t.Fatal("Abandoned calls should give their tokens back")
+	}
+}
+
+// Measures performance of abandoning throttled calls.
+func BenchmarkRateLimitClient_RequestContext(b *testing.B) {
+	c := NewRateLimitClient(NewClient(ClientData{Endpoint: "http://127.0.0.1:0"}, l), map[mesos_v1_scheduler.Call_Type]RateLimit{
+		mesos_v1_scheduler.Call_DECLINE: {Rate: 1e-9, Burst: 1},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	call := &mesos_v1_scheduler.Call{Type: mesos_v1_scheduler.Call_DECLINE.Enum()}
+	c.buckets[mesos_v1_scheduler.Call_DECLINE].tokens = 0
+	for n := 0; n < b.N; n++ {
+		c.RequestContext(ctx, call)
+	}
+}
+
+// Measures performance of sending calls within their rate limit.
+func BenchmarkRateLimitClient_Request(b *testing.B) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	c := NewRateLimitClient(NewClient(ClientData{Endpoint: ts.URL}, l), map[mesos_v1_scheduler.Call_Type]RateLimit{
+		mesos_v1_scheduler.Call_DECLINE: {Rate: 1e9, Burst: 1e9},
+	})
+	call := &mesos_v1_scheduler.Call{Type: mesos_v1_scheduler.Call_DECLINE.Enum()}
+	for n := 0; n < b.N; n++ {
+		c.Request(call)
+	}
+}
+
+type recording struct {
+	bytes.Buffer
+}
+
+func (r *recording) Close() error {
+	return nil
+}
+
+// Ensures a recorded event stream replays the same events through the decoder.
+func TestReplayClient_Request(t *testing.T) {
+	t.Parallel()
+
+	var stream bytes.Buffer
+	for _, typ := range []mesos_v1_scheduler.Event_Type{mesos_v1_scheduler.Event_SUBSCRIBED, mesos_v1_scheduler.Event_HEARTBEAT} {
+		data, err := proto.Marshal(&mesos_v1_scheduler.Event{Type: typ.Enum()})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		stream.WriteString(strconv.Itoa(len(data)) + "\n")
+		stream.Write(data)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Mesos-Stream-Id", "stream")
+		w.WriteHeader(http.StatusOK)
+		w.Write(stream.Bytes())
+	}))
+	defer ts.Close()
+
+	rec := new(recording)
+	r := NewEventRecorder(NewClient(ClientData{Endpoint: ts.URL}, l), rec)
+	resp, err := r.Request(&mesos_v1_scheduler.Call{Type: mesos_v1_scheduler.Call_SUBSCRIBE.Enum()})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	events := make(chan *mesos_v1_scheduler.Event, 10)
+	if err := recordio.Decode(resp.Body, events); err != io.EOF {
+		t.Fatal("The recorded stream should decode until EOF")
+	}
+	if !bytes.Equal(rec.Bytes(), stream.Bytes()) || r.StreamID() != "stream" {
+		t.Fatal("The raw event stream was not recorded")
+	}
+
+	c := NewReplayClient(rec.Bytes())
+	for n := 0; n < 2; n++ {
+		resp, err = c.Request(&mesos_v1_scheduler.Call{Type: mesos_v1_scheduler.Call_SUBSCRIBE.Enum()})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		replayed := make(chan *mesos_v1_scheduler.Event, 10)
+		if err := recordio.Decode(resp.Body, replayed); err != io.EOF || len(replayed) != 2 {
+			t.Fatal("Every subscription should replay the whole recording")
+		}
+		if (<-replayed).GetType() != mesos_v1_scheduler.Event_SUBSCRIBED {
+			t.Fatal("Events should be replayed in order")
+		}
+	}
+
+	c.Request(&mesos_v1_scheduler.Call{Type: mesos_v1_scheduler.Call_DECLINE.Enum()})
+	calls := c.Calls()
+	if len(calls) != 3 || calls[2].(*mesos_v1_scheduler.Call).GetType() != mesos_v1_scheduler.Call_DECLINE || c.StreamID() != REPLAY_STREAM_ID {
+		t.Fatal("Calls made during the replay were not kept")
+	}
+}
+
+// Ensures a stream cut off mid-record doesn't break the recording of the streams after it.
+func TestEventRecorder_PartialRecord(t *testing.T) {
+	t.Parallel()
+
+	data, err := proto.Marshal(&mesos_v1_scheduler.Event{Type: mesos_v1_scheduler.Event_HEARTBEAT.Enum()})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	record := strconv.Itoa(len(data)) + "\n" + string(data)
+
+	// The first subscription is cut off partway through its second record.
+	var subscriptions int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		if atomic.AddInt32(&subscriptions, 1) == 1 {
+			w.Write([]byte(record + record[:len(record)-1]))
+			return
+		}
+		w.Write([]byte(record))
+	}))
+	defer ts.Close()
+
+	rec := new(recording)
+	r := NewEventRecorder(NewClient(ClientData{Endpoint: ts.URL}, l), rec)
+	for n := 0; n < 2; n++ {
+		resp, err := r.Request(&mesos_v1_scheduler.Call{Type: mesos_v1_scheduler.Call_SUBSCRIBE.Enum()})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
+
+	if rec.String() != record+record {
+		t.Fatal("Only whole records should be recorded")
+	}
+	events := make(chan *mesos_v1_scheduler.Event, 10)
+	if err := recordio.Decode(ioutil.NopCloser(bytes.NewReader(rec.Bytes())), events); err != io.EOF || len(events) != 2 {
+		t.Fatal("Recordings of cut off streams should still decode")
+	}
+}
+
+// Measures performance of recording a subscription in small reads.
+func BenchmarkEventRecorder_Write(b *testing.B) {
+	record := []byte("2\n{}")
+	for n := 0; n < b.N; n++ {
+		f := &frames{recorder: NewEventRecorder(nil, new(recording))}
+		for i := range record {
+			f.Write(record[i : i+1])
+		}
+	}
+}
+
+// Measures performance of replaying a subscription.
+func BenchmarkReplayClient_Request(b *testing.B) {
+	c := NewReplayClient([]byte("2\n{}"))
+	call := &mesos_v1_scheduler.Call{Type: mesos_v1_scheduler.Call_SUBSCRIBE.Enum()}
+	for n := 0; n < b.N; n++ {
+		c.Request(call)
+	}
+}
+
+// Ensures requests give up once their context is done.
+func TestDefaultClient_RequestContext(t *testing.T) {
+	t.Parallel()
+
+	block := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	defer ts.Close()
+	defer close(block)
+
+	c := NewClient(ClientData{Endpoint: ts.URL}, l)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := c.RequestContext(ctx, &mesos_v1_scheduler.Call{Type: mesos_v1_scheduler.Call_DECLINE.Enum()})
+	if err == nil || ctx.Err() != context.DeadlineExceeded {
+		t.Fatal("Requests should fail once their deadline passes")
+	}
+}
+
+// Ensures error responses from the master are decoded into typed errors.
+func TestDefaultClient_RequestErrors(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bad":
+			http.Error(w, "Failed to validate scheduler::Call", http.StatusBadRequest)
+		case "/forbidden":
+			w.WriteHeader(http.StatusForbidden)
+		case "/follower":
+			w.Header().Set("Location", "//leader:5050")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/unavailable":
+			http.Error(w, "Not the leading master", http.StatusServiceUnavailable)
+		default:
+			http.Error(w, "Internal error", http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+
+	request := func(path string) error {
+		_, err := NewClient(ClientData{Endpoint: ts.URL + path}, l).Request(&mesos_v1_scheduler.Call{})
+		return err
+	}
+
+	if err, ok := request("/bad").(*ErrBadRequest); !ok || !strings.Contains(err.Message, "Failed to validate") {
+		t.Fatal("Bad requests should carry the master's reason")
+	}
+	if request("/forbidden") != ErrUnauthorized {
+		t.Fatal("Forbidden calls should be unauthorized")
+	}
+	if err, ok := request("/follower").(*ErrNotLeader); !ok || err.LeaderURL != "http://leader:5050" {
+		t.Fatal("Followers should point at the leader")
+	}
+	if err, ok := request("/unavailable").(*ErrNotLeader); !ok || err.LeaderURL != "" {
+		t.Fatal("Masters that aren't leading should be reported even without knowing the leader")
+	}
+	if _, ok := request("/").(*ErrBadRequest); ok {
+		t.Fatal("Other errors should not be typed")
+	}
+}
+
+// Writes PEM blocks to a file in dir and returns its path.
+func writePEM(t *testing.T, dir, name, kind string, der []byte) string {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: kind, Bytes: der}), 0600); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	return path
+}
+
+// Ensures the client can talk to a master that requires mutual TLS.
+func TestNewTLSClient(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "client")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "framework"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	clientCert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	certFile := writePEM(t, dir, "client.pem", "CERTIFICATE", der)
+	keyFile := writePEM(t, dir, "client-key.pem", "EC PRIVATE KEY", keyDER)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	ts.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	ts.StartTLS()
+	defer ts.Close()
+
+	caFile := writePEM(t, dir, "ca.pem", "CERTIFICATE", ts.TLS.Certificates[0].Certificate[0])
+
+	tests := []struct {
+		name   string
+		config TLSConfig
+		ok     bool
+	}{
+		{"mutual", TLSConfig{CAFile: caFile, CertFile: certFile, KeyFile: keyFile}, true},
+		{"server name", TLSConfig{CAFile: caFile, CertFile: certFile, KeyFile: keyFile, ServerName: "example.com"}, true},
+		{"wrong server name", TLSConfig{CAFile: caFile, CertFile: certFile, KeyFile: keyFile, ServerName: "master.invalid"}, false},
+		{"no client certificate", TLSConfig{CAFile: caFile}, false},
+		{"unknown authority", TLSConfig{CertFile: certFile, KeyFile: keyFile}, false},
+		{"insecure", TLSConfig{CertFile: certFile, KeyFile: keyFile, InsecureSkipVerify: true}, true},
+	}
+
+	for _, test := range tests {
+		c, err := NewTLSClient(ClientData{Endpoint: ts.URL}, test.config, l)
+		if err != nil {
+			t.Fatal(test.name + ": " + err.Error())
+		}
+
+		resp, err := c.Request(&mesos_v1_scheduler.Call{})
+		if test.ok && err != nil {
+			t.Fatal(test.name + ": request should have succeeded: " + err.Error())
+		}
+		if !test.ok && err == nil {
+			t.Fatal(test.name + ": request should have failed the handshake")
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+	}
+
+	if _, err := NewTLSClient(ClientData{}, TLSConfig{CertFile: certFile}, l); err == nil {
+		t.Fatal("A certificate without its key should be rejected")
+	}
+	if _, err := NewTLSClient(ClientData{}, TLSConfig{CAFile: filepath.Join(dir, "missing.pem")}, l); err == nil {
+		t.Fatal("A missing CA bundle should be rejected")
+	}
+	if _, err := NewTLSClient(ClientData{}, TLSConfig{CAFile: keyFile}, l); err == nil {
+		t.Fatal("A CA bundle without certificates should be rejected")
+	}
+}
+
+// Measures performance of building a TLS client.
+func BenchmarkNewTLSClient(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		NewTLSClient(ClientData{}, TLSConfig{InsecureSkipVerify: true}, l)
+	}
+}
+
+// Ensures credentials are sent with the subscription and every call after it.
+func TestDefaultClient_Credentials(t *testing.T) {
+	t.Parallel()
+
+	var auth atomic.Value
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth.Store(r.Header.Get("Authorization"))
+		w.Header().Set("Mesos-Stream-Id", "stream")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	refreshes := 0
+	tests := []struct {
+		credentials Credentials
+		expected    []string
+	}{
+		{BasicCredentials{Principal: "principal", Secret: "secret"}, []string{"Basic cHJpbmNpcGFsOnNlY3JldA==", "Basic cHJpbmNpcGFsOnNlY3JldA=="}},
+		{TokenCredentials("token"), []string{"Bearer token", "Bearer token"}},
+		{TokenFunc(func() (string, error) {
+			refreshes++
+			return "token" + strconv.Itoa(refreshes), nil
+		}), []string{"Bearer token1", "Bearer token2"}},
+	}
+
+	for _, test := range tests {
+		c := NewClient(ClientData{Endpoint: ts.URL, Auth: "ignored", Credentials: test.credentials}, l)
+		calls := []*mesos_v1_scheduler.Call{
+			{Type: mesos_v1_scheduler.Call_SUBSCRIBE.Enum()},
+			{Type: mesos_v1_scheduler.Call_ACCEPT.Enum()},
+		}
+
+		for i, call := range calls {
+			if _, err := c.Request(call); err != nil {
+				t.Fatal("Request could not be made successfully: " + err.Error())
+			}
+			if auth.Load().(string) != test.expected[i] {
+				t.Fatal("Expected Authorization " + test.expected[i] + " but got " + auth.Load().(string))
+			}
+		}
+	}
+
+	c := NewClient(ClientData{Endpoint: ts.URL, Credentials: TokenFunc(func() (string, error) {
+		return "", errors.New("Token expired.")
+	})}, l)
+	if _, err := c.Request(&mesos_v1_scheduler.Call{}); err == nil {
+		t.Fatal("A failure to fetch credentials should fail the call")
+	}
+}
+
+// Measures performance of building basic authentication headers.
+func BenchmarkBasicCredentials_Authorization(b *testing.B) {
+	c := BasicCredentials{Principal: "principal", Secret: "secret"}
+	for n := 0; n < b.N; n++ {
+		c.Authorization()
+	}
+}
+
+// Ensures regular calls reuse pooled connections while a subscription holds its own.
+func TestNewClientWithConfig(t *testing.T) {
+	t.Parallel()
+
+	var conns int32
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		if r.Header.Get("Mesos-Stream-Id") == "" {
+			<-r.Context().Done()
+		}
+	}))
+	ts.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	ts.Start()
+	defer ts.Close()
+
+	config := DefaultClientConfig()
+	config.ResponseHeaderTimeout = time.Second
+	c, err := NewClientWithConfig(ClientData{Endpoint: ts.URL}, config, l)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := c.RequestContext(ctx, &mesos_v1_scheduler.Call{Type: mesos_v1_scheduler.Call_SUBSCRIBE.Enum()})
+	if err != nil {
+		t.Fatal("Subscription could not be made successfully: " + err.Error())
+	}
+	defer stream.Body.Close()
+	c.SetStreamID("stream")
+
+	for i := 0; i < 10; i++ {
+		resp, err := c.Request(&mesos_v1_scheduler.Call{Type: mesos_v1_scheduler.Call_ACCEPT.Enum()})
+		if err != nil {
+			t.Fatal("Call could not be made successfully: " + err.Error())
+		}
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	if n := atomic.LoadInt32(&conns); n != 2 {
+		t.Fatal("Expected one connection for the stream and one for calls, got " + strconv.Itoa(int(n)))
+	}
+
+	config.TLS = &TLSConfig{CAFile: filepath.Join(os.TempDir(), "missing-ca.pem")}
+	if _, err := NewClientWithConfig(ClientData{}, config, l); err == nil {
+		t.Fatal("An invalid TLS configuration should be rejected")
+	}
+}
+
+// Measures performance of creating a client with a custom configuration.
+func BenchmarkNewClientWithConfig(b *testing.B) {
+	config := DefaultClientConfig()
+	for n := 0; n < b.N; n++ {
+		NewClientWithConfig(ClientData{}, config, l)
+	}
+}
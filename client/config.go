@@ -0,0 +1,87 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"crypto/tls"
+	"github.com/verizonlabs/mesos-framework-sdk/logging"
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	DIAL_TIMEOUT            = 10 * time.Second
+	KEEP_ALIVE              = 30 * time.Second
+	MAX_IDLE_CONNS          = 100
+	MAX_IDLE_CONNS_PER_HOST = 10
+	IDLE_CONN_TIMEOUT       = 90 * time.Second
+)
+
+// Transport tuning for the connections to the master.
+// Subscriptions get a connection of their own so the long lived stream never holds one from the call pool.
+type ClientConfig struct {
+	DialTimeout           time.Duration
+	KeepAlive             time.Duration // TCP keep-alive period for net.Dialer, 0 uses its default and a negative value disables it.
+	MaxIdleConns          int
+	MaxIdleConnsPerHost   int
+	IdleConnTimeout       time.Duration
+	ResponseHeaderTimeout time.Duration // 0 waits as long as the request context allows.
+	TLS                   *TLSConfig
+}
+
+// Returns the configuration used by NewClient.
+func DefaultClientConfig() ClientConfig {
+	return ClientConfig{
+		DialTimeout:         DIAL_TIMEOUT,
+		KeepAlive:           KEEP_ALIVE,
+		MaxIdleConns:        MAX_IDLE_CONNS,
+		MaxIdleConnsPerHost: MAX_IDLE_CONNS_PER_HOST,
+		IdleConnTimeout:     IDLE_CONN_TIMEOUT,
+	}
+}
+
+// Returns a new HTTP client with tuned transports.
+func NewClientWithConfig(data ClientData, config ClientConfig, logger logging.Logger) (Client, error) {
+	var tlsConfig *tls.Config
+	if config.TLS != nil {
+		var err error
+		tlsConfig, err = config.TLS.tls()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &DefaultClient{
+		data:   data,
+		client: &http.Client{Transport: config.transport(tlsConfig, config.MaxIdleConnsPerHost)},
+		stream: &http.Client{Transport: config.transport(tlsConfig, 1)},
+		logger: logger,
+	}, nil
+}
+
+func (c ClientConfig) transport(tlsConfig *tls.Config, idlePerHost int) *http.Transport {
+	return &http.Transport{
+		Dial: (&net.Dialer{
+			Timeout:   c.DialTimeout,
+			KeepAlive: c.KeepAlive,
+		}).Dial,
+		TLSClientConfig:       tlsConfig,
+		MaxIdleConns:          c.MaxIdleConns,
+		MaxIdleConnsPerHost:   idlePerHost,
+		IdleConnTimeout:       c.IdleConnTimeout,
+		ResponseHeaderTimeout: c.ResponseHeaderTimeout,
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// Returned when the master rejects our credentials or doesn't allow the call.
+var ErrUnauthorized = errors.New("Unauthorized")
+
+// Returned when the master rejects a call as malformed.
+type ErrBadRequest struct {
+	Message string // Reason given by the master.
+}
+
+func (e *ErrBadRequest) Error() string {
+	return e.Message
+}
+
+// Returned when the master we talked to isn't leading.
+// The leader is empty when the master didn't know who is.
+type ErrNotLeader struct {
+	LeaderURL string
+}
+
+func (e *ErrNotLeader) Error() string {
+	if e.LeaderURL == "" {
+		return "Master is not the leader and the leader is unknown"
+	}
+
+	return "Master is not the leader, the leader is at " + e.LeaderURL
+}
+
+// Gets the leader a master pointed us at, which may be relative to the scheme we used.
+func leaderURL(resp *http.Response) string {
+	master := resp.Header.Get("Location")
+	if master == "" || strings.Contains(master, "http") {
+		return master
+	}
+
+	return resp.Request.URL.Scheme + ":" + master
+}
+
+// Turns an error response from the master into a typed error.
+func decodeError(resp *http.Response) error {
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrUnauthorized
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	message := string(data)
+
+	switch resp.StatusCode {
+	case http.StatusBadRequest:
+		return &ErrBadRequest{Message: message}
+	case http.StatusServiceUnavailable:
+
+		// Masters that aren't leading or haven't recovered yet answer with 503, sometimes pointing at the leader.
+		leader := leaderURL(resp)
+		if leader != "" || strings.Contains(strings.ToLower(message), "leading master") {
+			return &ErrNotLeader{LeaderURL: leader}
+		}
+	}
+
+	return errors.New(message)
+}
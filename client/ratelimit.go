@@ -0,0 +1,195 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_scheduler"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var ErrThrottled = errors.New("Call was throttled for longer than the maximum wait.")
+
+// Limits how often a type of call can be sent.
+type RateLimit struct {
+	Rate  float64 // Calls per second.
+	Burst float64 // Calls that can be sent at once after a quiet period.
+}
+
+// Token bucket that hands out reservations for calls.
+type tokenBucket struct {
+	limit  RateLimit
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit RateLimit) *tokenBucket {
+	return &tokenBucket{
+		limit:  limit,
+		tokens: limit.Burst,
+		last:   time.Now(),
+	}
+}
+
+// Reserves a token, returning how long to wait before it can be used.
+// Reservations that would wait longer than max are not taken when max is positive.
+func (b *tokenBucket) reserve(now time.Time, max time.Duration) (time.Duration, bool) {
+	b.tokens += now.Sub(b.last).Seconds() * b.limit.Rate
+	if b.tokens > b.limit.Burst {
+		b.tokens = b.limit.Burst
+	}
+	b.last = now
+
+	var wait time.Duration
+	if b.tokens < 1 {
+		wait = time.Duration((1 - b.tokens) / b.limit.Rate * float64(time.Second))
+	}
+	if max > 0 && wait > max {
+		return wait, false
+	}
+	b.tokens--
+
+	return wait, true
+}
+
+// Hands back a reserved token that was never used.
+func (b *tokenBucket) cancel() {
+	b.tokens++
+	if b.tokens > b.limit.Burst {
+		b.tokens = b.limit.Burst
+	}
+}
+
+// Client that limits how often each type of scheduler call is sent, so large frameworks don't overwhelm the master.
+// Call types without a limit, and anything that isn't a scheduler call, are sent straight away.
+type RateLimitClient struct {
+	client    Client
+	buckets   map[mesos_v1_scheduler.Call_Type]*tokenBucket
+	MaxWait   time.Duration                                                           // Calls that would wait longer fail with ErrThrottled. Unbounded when 0.
+	Throttled func(t mesos_v1_scheduler.Call_Type, wait time.Duration, rejected bool) // Called for every call that was delayed or rejected.
+	sync.Mutex
+}
+
+func NewRateLimitClient(c Client, limits map[mesos_v1_scheduler.Call_Type]RateLimit) *RateLimitClient {
+	buckets := make(map[mesos_v1_scheduler.Call_Type]*tokenBucket, len(limits))
+	for t, limit := range limits {
+		if limit.Rate <= 0 {
+			continue
+		}
+		if limit.Burst < 1 {
+			limit.Burst = 1
+		}
+		buckets[t] = newTokenBucket(limit)
+	}
+
+	return &RateLimitClient{
+		client:  c,
+		buckets: buckets,
+	}
+}
+
+// Reserves a slot for the call, returning how long it has to wait to be sent.
+func (r *RateLimitClient) reserve(call interface{}) (time.Duration, error) {
+	c, ok := call.(*mesos_v1_scheduler.Call)
+	if !ok {
+		return 0, nil
+	}
+
+	r.Lock()
+	b, ok := r.buckets[c.GetType()]
+	if !ok {
+		r.Unlock()
+		return 0, nil
+	}
+	wait, reserved := b.reserve(time.Now(), r.MaxWait)
+	r.Unlock()
+
+	if wait > 0 && r.Throttled != nil {
+		r.Throttled(c.GetType(), wait, !reserved)
+	}
+	if !reserved {
+		return wait, ErrThrottled
+	}
+
+	return wait, nil
+}
+
+// Gives back the slot reserved for a call that's no longer being sent.
+func (r *RateLimitClient) release(call interface{}) {
+	c, ok := call.(*mesos_v1_scheduler.Call)
+	if !ok {
+		return
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	if b, ok := r.buckets[c.GetType()]; ok {
+		b.cancel()
+	}
+}
+
+func (r *RateLimitClient) Request(call interface{}) (*http.Response, error) {
+	return r.RequestContext(context.Background(), call)
+}
+
+// Sends a call once its type is within its rate limit, or gives up when the context is done.
+func (r *RateLimitClient) RequestContext(ctx context.Context, call interface{}) (*http.Response, error) {
+	wait, err := r.reserve(call)
+	if err != nil {
+		return nil, err
+	}
+
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			r.release(call)
+			return nil, ctx.Err()
+		}
+	}
+
+	return r.client.RequestContext(ctx, call)
+}
+
+func (r *RateLimitClient) StreamID() string {
+	return r.client.StreamID()
+}
+
+func (r *RateLimitClient) SetStreamID(id string) Client {
+	r.client.SetStreamID(id)
+	return r
+}
+
+// Gets the endpoint of the wrapped client, if it has one.
+func (r *RateLimitClient) Endpoint() string {
+	if c, ok := r.client.(Redirectable); ok {
+		return c.Endpoint()
+	}
+
+	return ""
+}
+
+// Points the wrapped client at a new endpoint, if it supports it.
+func (r *RateLimitClient) SetEndpoint(endpoint string) {
+	if c, ok := r.client.(Redirectable); ok {
+		c.SetEndpoint(endpoint)
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_scheduler"
+	"github.com/verizonlabs/mesos-framework-sdk/recordio"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+)
+
+const REPLAY_STREAM_ID = "replay"
+
+// Client that captures the raw RecordIO event stream of every subscription while it's being consumed.
+// The recording can be fed back through the event loop with a ReplayClient.
+type EventRecorder struct {
+	client Client
+	w      io.WriteCloser
+	sync.Mutex
+}
+
+func NewEventRecorder(c Client, w io.WriteCloser) *EventRecorder {
+	return &EventRecorder{
+		client: c,
+		w:      w,
+	}
+}
+
+// Records event streams to the file at path, replacing anything already there.
+func NewFileEventRecorder(c Client, path string) (*EventRecorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewEventRecorder(c, f), nil
+}
+
+// Lets the recorder share its writer between the streams of successive subscriptions.
+func (r *EventRecorder) Write(p []byte) (int, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.w.Write(p)
+}
+
+func (r *EventRecorder) Request(call interface{}) (*http.Response, error) {
+	return r.RequestContext(context.Background(), call)
+}
+
+// Sends a call, teeing the response body of subscriptions into the recording as it's read.
+// Only whole records are recorded, so a stream cut off mid-record doesn't corrupt the ones recorded after it.
+func (r *EventRecorder) RequestContext(ctx context.Context, call interface{}) (*http.Response, error) {
+	resp, err := r.client.RequestContext(ctx, call)
+	if err != nil || resp == nil || resp.Body == nil {
+		return resp, err
+	}
+
+	if c, ok := call.(*mesos_v1_scheduler.Call); ok && c.GetType() == mesos_v1_scheduler.Call_SUBSCRIBE {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.TeeReader(resp.Body, &frames{recorder: r}), resp.Body}
+	}
+
+	return resp, nil
+}
+
+func (r *EventRecorder) StreamID() string {
+	return r.client.StreamID()
+}
+
+func (r *EventRecorder) SetStreamID(id string) Client {
+	r.client.SetStreamID(id)
+	return r
+}
+
+// Closes the recording.
+func (r *EventRecorder) Close() error {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.w.Close()
+}
+
+// Holds back the bytes of a single subscription's stream until they make up a whole record.
+type frames struct {
+	recorder *EventRecorder
+	buf      []byte
+	corrupt  bool
+}
+
+func (f *frames) Write(p []byte) (int, error) {
+	if f.corrupt {
+		return len(p), nil
+	}
+
+	f.buf = append(f.buf, p...)
+	for {
+		// The rest of a stream can't be framed once a header is bad, so none of it is recorded.
+		header := bytes.IndexByte(f.buf, '\n')
+		if header < 0 {
+			if len(f.buf) > recordio.MAX_HEADER_LENGTH {
+				f.corrupt = true
+				f.buf = nil
+			}
+			return len(p), nil
+		}
+		size, err := strconv.ParseUint(string(f.buf[:header]), 10, 63)
+		if err != nil {
+			f.corrupt = true
+			f.buf = nil
+			return len(p), nil
+		}
+
+		if uint64(len(f.buf)-header-1) < size {
+			return len(p), nil
+		}
+		end := header + 1 + int(size)
+		if _, err := f.recorder.Write(f.buf[:end]); err != nil {
+			return 0, err
+		}
+		f.buf = f.buf[end:]
+	}
+}
+
+// Client that answers subscriptions with a recorded event stream instead of talking to a master.
+// Every call is kept so tests can check what the framework sent in response to the events.
+type ReplayClient struct {
+	recording []byte
+	streamID  string
+	calls     []interface{}
+	sync.Mutex
+}
+
+func NewReplayClient(recording []byte) *ReplayClient {
+	return &ReplayClient{recording: recording}
+}
+
+// Replays the recording in the file at path.
+func NewFileReplayClient(path string) (*ReplayClient, error) {
+	recording, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewReplayClient(recording), nil
+}
+
+// Records the call and accepts it.
+// Subscriptions get the whole recording from the start, ending in EOF once it's been read.
+func (r *ReplayClient) Request(call interface{}) (*http.Response, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.calls = append(r.calls, call)
+
+	if c, ok := call.(*mesos_v1_scheduler.Call); ok && c.GetType() == mesos_v1_scheduler.Call_SUBSCRIBE {
+		r.streamID = REPLAY_STREAM_ID
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Mesos-Stream-Id": []string{REPLAY_STREAM_ID}},
+			Body:       ioutil.NopCloser(bytes.NewReader(r.recording)),
+		}, nil
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusAccepted,
+		Header:     make(http.Header),
+		Body:       ioutil.NopCloser(bytes.NewReader(nil)),
+	}, nil
+}
+
+// Same as Request, failing straight away when the context is already done.
+func (r *ReplayClient) RequestContext(ctx context.Context, call interface{}) (*http.Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return r.Request(call)
+}
+
+// Gets every call sent so far, in order.
+func (r *ReplayClient) Calls() []interface{} {
+	r.Lock()
+	defer r.Unlock()
+
+	return append([]interface{}(nil), r.calls...)
+}
+
+func (r *ReplayClient) StreamID() string {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.streamID
+}
+
+func (r *ReplayClient) SetStreamID(id string) Client {
+	r.Lock()
+	defer r.Unlock()
+
+	r.streamID = id
+	return r
+}
@@ -0,0 +1,197 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_scheduler"
+	"github.com/verizonlabs/mesos-framework-sdk/logging"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Controls how failed calls are retried.
+type RetryPolicy struct {
+	MaxAttempts        int           // Attempts per call, including the first one.
+	MinBackoff         time.Duration // Wait before the first retry.
+	MaxBackoff         time.Duration // Upper bound for the exponential backoff.
+	Jitter             float64       // Fraction of each wait that is randomized, between 0 and 1.
+	Budget             float64       // Retries that can be spent across all calls before retrying stops.
+	BudgetRefill       float64       // Retries given back to the budget by every successful call.
+	RetryNonIdempotent bool          // Whether calls that are unsafe to repeat, such as ACCEPT, are retried as well.
+}
+
+// Creates a retry policy with sensible defaults.
+func NewRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:  4,
+		MinBackoff:   250 * time.Millisecond,
+		MaxBackoff:   10 * time.Second,
+		Jitter:       0.2,
+		Budget:       20,
+		BudgetRefill: 0.1,
+	}
+}
+
+// Client that retries transient failures, such as the master being unavailable or a leader change.
+// Wraps any other client, so the scheduler can use it as a drop-in replacement.
+type RetryClient struct {
+	client Client
+	policy *RetryPolicy
+	logger logging.Logger
+	budget float64
+	sync.Mutex
+}
+
+func NewRetryClient(c Client, policy *RetryPolicy, logger logging.Logger) *RetryClient {
+	return &RetryClient{
+		client: c,
+		policy: policy,
+		logger: logger,
+		budget: policy.Budget,
+	}
+}
+
+// Reports whether a call can safely be sent more than once.
+// Subscribing opens a long-lived stream that's re-established elsewhere, and executor calls are left to the agent.
+func idempotent(call interface{}) bool {
+	c, ok := call.(*mesos_v1_scheduler.Call)
+	if !ok {
+		return false
+	}
+
+	switch c.GetType() {
+	case mesos_v1_scheduler.Call_SUBSCRIBE, mesos_v1_scheduler.Call_ACCEPT, mesos_v1_scheduler.Call_MESSAGE:
+		return false
+	}
+
+	return true
+}
+
+// Reports whether a failed call is worth retrying.
+// Failures without a response are network errors or master redirects, both of which may succeed when tried again.
+func transient(resp *http.Response, err error) bool {
+	if err == nil {
+		return false
+	}
+	if resp == nil {
+		return true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// Gets how long to wait before the next attempt, randomized by the policy's jitter.
+func (r *RetryClient) wait(backoff time.Duration) time.Duration {
+	if r.policy.Jitter <= 0 {
+		return backoff
+	}
+
+	return backoff + time.Duration(float64(backoff)*r.policy.Jitter*(rand.Float64()*2-1))
+}
+
+// Takes a retry out of the budget, reporting whether there was one left.
+func (r *RetryClient) spend() bool {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.budget < 1 {
+		return false
+	}
+	r.budget--
+
+	return true
+}
+
+// Gives back part of a retry to the budget after a successful call.
+func (r *RetryClient) refill() {
+	r.Lock()
+	defer r.Unlock()
+
+	r.budget += r.policy.BudgetRefill
+	if r.budget > r.policy.Budget {
+		r.budget = r.policy.Budget
+	}
+}
+
+func (r *RetryClient) Request(call interface{}) (*http.Response, error) {
+	return r.RequestContext(context.Background(), call)
+}
+
+// Sends a call, retrying transient failures with exponential backoff until it succeeds,
+// the attempts or the retry budget run out, or the context is done.
+func (r *RetryClient) RequestContext(ctx context.Context, call interface{}) (*http.Response, error) {
+	retry := r.policy.RetryNonIdempotent || idempotent(call)
+	backoff := r.policy.MinBackoff
+
+	for attempt := 1; ; attempt++ {
+		resp, err := r.client.RequestContext(ctx, call)
+		if err == nil {
+			r.refill()
+			return resp, nil
+		}
+		if !retry || !transient(resp, err) || attempt >= r.policy.MaxAttempts || !r.spend() {
+			return resp, err
+		}
+
+		r.logger.Emit(logging.ERROR, "Call failed, retrying: %s", err.Error())
+		if resp != nil && resp.Body != nil {
+			resp.Body.Close()
+		}
+
+		select {
+		case <-time.After(r.wait(backoff)):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		backoff *= 2
+		if backoff > r.policy.MaxBackoff {
+			backoff = r.policy.MaxBackoff
+		}
+	}
+}
+
+func (r *RetryClient) StreamID() string {
+	return r.client.StreamID()
+}
+
+func (r *RetryClient) SetStreamID(id string) Client {
+	r.client.SetStreamID(id)
+	return r
+}
+
+// Gets the endpoint of the wrapped client, if it has one.
+func (r *RetryClient) Endpoint() string {
+	if c, ok := r.client.(Redirectable); ok {
+		return c.Endpoint()
+	}
+
+	return ""
+}
+
+// Points the wrapped client at a new endpoint, if it supports it.
+func (r *RetryClient) SetEndpoint(endpoint string) {
+	if c, ok := r.client.(Redirectable); ok {
+		c.SetEndpoint(endpoint)
+	}
+}
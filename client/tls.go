@@ -0,0 +1,71 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"github.com/verizonlabs/mesos-framework-sdk/logging"
+	"io/ioutil"
+)
+
+// How to reach a master that's fronted by TLS.
+// The CA bundle and client certificate are read from PEM files, system roots are used when no CA is given.
+type TLSConfig struct {
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	ServerName         string // Overrides the name used for SNI and certificate verification.
+	InsecureSkipVerify bool
+}
+
+// Returns a new HTTP client that talks to the master over TLS.
+func NewTLSClient(data ClientData, config TLSConfig, logger logging.Logger) (Client, error) {
+	clientConfig := DefaultClientConfig()
+	clientConfig.TLS = &config
+
+	return NewClientWithConfig(data, clientConfig, logger)
+}
+
+func (c TLSConfig) tls() (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, errors.New("Failed to load the client certificate: " + err.Error())
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if c.CAFile != "" {
+		ca, err := ioutil.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, errors.New("Failed to read the CA bundle: " + err.Error())
+		}
+
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, errors.New("No certificates found in the CA bundle.")
+		}
+	}
+
+	return config, nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Detects the leading Mesos master so that the scheduler can follow it through failovers.
+package detector
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Finds the address, as host:port, of the leading master.
+type MasterDetector interface {
+	Detect() (string, error)
+}
+
+// Creates the right detector for a master URL.
+// zk:// URLs are resolved through ZooKeeper, anything else is treated as a comma separated list of masters to ask.
+func New(master string, timeout time.Duration) (MasterDetector, error) {
+	if strings.HasPrefix(master, "zk://") {
+		return NewZKDetector(master, timeout)
+	}
+
+	return NewRedirectDetector(strings.Split(master, ","), timeout)
+}
+
+// Finds the leader by asking any of the known masters, which redirect to the leader.
+type RedirectDetector struct {
+	masters []string
+	client  *http.Client
+}
+
+func NewRedirectDetector(masters []string, timeout time.Duration) (*RedirectDetector, error) {
+	if len(masters) == 0 || masters[0] == "" {
+		return nil, errors.New("No masters given to detect the leader from")
+	}
+
+	return &RedirectDetector{
+		masters: masters,
+		client: &http.Client{
+			Timeout: timeout,
+
+			// We want the redirect itself since it points at the leader.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}, nil
+}
+
+// Asks each master in turn for the leader, returning the first answer.
+func (r *RedirectDetector) Detect() (string, error) {
+	err := errors.New("No masters given to detect the leader from")
+	for _, master := range r.masters {
+		var leader string
+		leader, err = r.ask(master)
+		if err == nil {
+			return leader, nil
+		}
+	}
+
+	return "", err
+}
+
+func (r *RedirectDetector) ask(master string) (string, error) {
+	master = strings.TrimSpace(master)
+	if !strings.Contains(master, "://") {
+		master = "http://" + master
+	}
+
+	u, err := url.Parse(master)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := r.client.Get(u.Scheme + "://" + u.Host + "/redirect")
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusTemporaryRedirect && resp.StatusCode != http.StatusPermanentRedirect {
+		return "", errors.New("Master did not redirect to the leader: " + resp.Status)
+	}
+
+	// Masters redirect with a scheme-relative location such as //host:port.
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return "", err
+	}
+	if _, _, err := net.SplitHostPort(location.Host); err != nil {
+		return "", errors.New("Master redirected to an invalid leader address")
+	}
+
+	return location.Host, nil
+}
@@ -0,0 +1,120 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package detector
+
+import (
+	"github.com/verizonlabs/mesos-framework-sdk/internal/zk/test"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Starts a ZooKeeper server with the given children and their data registered under /mesos.
+func fakeZK(t *testing.T, nodes map[string]string) *test.MockServer {
+	server, err := test.NewMockServer()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	for child, data := range nodes {
+		server.Set("/mesos/"+child, data)
+	}
+
+	return server
+}
+
+// Ensures the master with the lowest sequence in ZooKeeper is detected as the leader.
+func TestZKDetector_Detect(t *testing.T) {
+	t.Parallel()
+
+	ln := fakeZK(t, map[string]string{
+		"json.info_0000000002": `{"hostname":"standby","port":5050}`,
+		"json.info_0000000001": `{"address":{"hostname":"leader","ip":"10.0.0.1","port":5051}}`,
+		"log_replicas":         "",
+	})
+	defer ln.Close()
+
+	d, err := New("zk://"+ln.Addr()+"/mesos", time.Second)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	leader, err := d.Detect()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if leader != "leader:5051" {
+		t.Fatal("Wrong leader detected: " + leader)
+	}
+
+	if _, err := NewZKDetector("zk://localhost:2181", time.Second); err == nil {
+		t.Fatal("ZooKeeper URLs without a path should be rejected")
+	}
+
+	empty := fakeZK(t, map[string]string{})
+	defer empty.Close()
+
+	d, _ = NewZKDetector("zk://"+empty.Addr()+"/mesos", time.Second)
+	if _, err := d.Detect(); err == nil {
+		t.Fatal("Detection should fail without any registered masters")
+	}
+}
+
+// Measures performance of detecting the leader through ZooKeeper.
+func BenchmarkZKDetector_Detect(b *testing.B) {
+	ln := fakeZK(&testing.T{}, map[string]string{"json.info_0000000001": `{"hostname":"leader","port":5050}`})
+	defer ln.Close()
+
+	d, _ := NewZKDetector("zk://"+ln.Addr()+"/mesos", time.Second)
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		d.Detect()
+	}
+}
+
+// Ensures the leader is detected from a master's redirect.
+func TestRedirectDetector_Detect(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/redirect" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Location", "//leader:5050")
+		w.WriteHeader(http.StatusTemporaryRedirect)
+	}))
+	defer ts.Close()
+
+	d, err := New("127.0.0.1:1,"+strings.TrimPrefix(ts.URL, "http://"), time.Second)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	leader, err := d.Detect()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if leader != "leader:5050" {
+		t.Fatal("Wrong leader detected: " + leader)
+	}
+
+	if _, err := NewRedirectDetector(nil, time.Second); err == nil {
+		t.Fatal("Detectors need at least one master")
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package detector
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/verizonlabs/mesos-framework-sdk/internal/zk"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Prefix of the znodes masters register themselves under, which hold their MasterInfo as JSON.
+const ZK_INFO_PREFIX = "json.info_"
+
+// Finds the leader from the masters registered in ZooKeeper.
+// Masters register ephemeral sequential znodes and the one with the lowest sequence is leading.
+type ZKDetector struct {
+	servers []string
+	path    string
+	timeout time.Duration
+}
+
+// Parses a zk://host1:port1,host2:port2/path URL as handed to Mesos.
+func NewZKDetector(zkURL string, timeout time.Duration) (*ZKDetector, error) {
+	u, err := url.Parse(zkURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "zk" || u.Host == "" {
+		return nil, errors.New("ZooKeeper URL must look like zk://host:port/path")
+	}
+
+	path := strings.TrimSuffix(u.Path, "/")
+	if path == "" {
+		return nil, errors.New("ZooKeeper URL must include the path masters register under")
+	}
+
+	return &ZKDetector{
+		servers: strings.Split(u.Host, ","),
+		path:    path,
+		timeout: timeout,
+	}, nil
+}
+
+// Asks each ZooKeeper server in turn for the leader, returning the first answer.
+func (z *ZKDetector) Detect() (string, error) {
+	var err error
+	for _, server := range z.servers {
+		var leader string
+		leader, err = z.detect(server)
+		if err == nil {
+			return leader, nil
+		}
+	}
+
+	return "", err
+}
+
+func (z *ZKDetector) detect(server string) (string, error) {
+	conn, err := zk.Connect([]string{server}, z.timeout)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	children, err := conn.Children(z.path)
+	if err != nil {
+		return "", err
+	}
+
+	masters := make([]string, 0, len(children))
+	for _, child := range children {
+		if strings.HasPrefix(child, ZK_INFO_PREFIX) {
+			masters = append(masters, child)
+		}
+	}
+	if len(masters) == 0 {
+		return "", errors.New("No masters are registered in ZooKeeper")
+	}
+
+	// Sequence numbers are zero padded so they sort as strings.
+	sort.Strings(masters)
+
+	data, _, err := conn.Get(z.path + "/" + masters[0])
+	if err != nil {
+		return "", err
+	}
+
+	return leaderAddress(data)
+}
+
+// The part of MasterInfo we need to reach the master.
+type masterInfo struct {
+	Hostname string `json:"hostname"`
+	Port     int    `json:"port"`
+	Address  struct {
+		Hostname string `json:"hostname"`
+		IP       string `json:"ip"`
+		Port     int    `json:"port"`
+	} `json:"address"`
+}
+
+// Gets host:port out of a master's JSON MasterInfo.
+func leaderAddress(data []byte) (string, error) {
+	info := masterInfo{}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return "", err
+	}
+
+	host, port := info.Address.Hostname, info.Address.Port
+	if host == "" {
+		host = info.Address.IP
+	}
+	if host == "" {
+		host = info.Hostname
+	}
+	if port == 0 {
+		port = info.Port
+	}
+	if host == "" || port == 0 {
+		return "", errors.New("Leading master did not register an address")
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(port)), nil
+}
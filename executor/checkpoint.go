@@ -0,0 +1,128 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"encoding/json"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	exec "github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_executor"
+	"github.com/verizonlabs/mesos-framework-sdk/logging"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// File in the sandbox that unacknowledged updates are checkpointed to.
+const CHECKPOINT_FILE = "executor.checkpoint"
+
+// What's kept on disk between executor runs.
+type checkpoint struct {
+	Tasks   []*mesos_v1.TaskInfo `json:"tasks,omitempty"`
+	Updates []*exec.Call_Update  `json:"updates,omitempty"`
+}
+
+// Checkpoints unacknowledged tasks and updates to the file at path after every change,
+// first restoring anything a previous run of the executor left there.
+// Restored updates are sent again when subscribing, so nothing is lost when the executor or agent restarts.
+func (e *DefaultExecutor) Checkpoint(path string) error {
+	e.Lock()
+	defer e.Unlock()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if len(data) > 0 {
+		var c checkpoint
+		if err := json.Unmarshal(data, &c); err != nil {
+			return err
+		}
+		for _, task := range c.Tasks {
+			e.unackedTasks[task.GetTaskId().GetValue()] = *task
+		}
+		for _, update := range c.Updates {
+			e.unackedUpdates[string(update.GetStatus().GetUuid())] = *update
+		}
+	}
+
+	e.checkpoint = path
+	return e.persist()
+}
+
+// Writes the unacknowledged tasks and updates to the checkpoint, if there is one.
+// The file is replaced in one go so a crash never leaves a partial checkpoint behind.
+// Must be called with the lock held.
+func (e *DefaultExecutor) persist() error {
+	if e.checkpoint == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(checkpoint{
+		Tasks:   e.unacknowledgedTasks(),
+		Updates: e.unacknowledgedUpdates(),
+	})
+	if err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(filepath.Dir(e.checkpoint), "."+filepath.Base(e.checkpoint)+".tmp")
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, e.checkpoint)
+}
+
+// Sends every unacknowledged update to the agent again, returning how many were sent successfully.
+// The agent deduplicates updates by UUID, so resending ones that were already received is harmless.
+func (e *DefaultExecutor) Retry() int {
+	e.Lock()
+	updates := e.unacknowledgedUpdates()
+	e.Unlock()
+
+	sent := 0
+	for _, update := range updates {
+		_, err := e.client.Request(&exec.Call{
+			FrameworkId: e.frameworkId,
+			ExecutorId:  e.executorId,
+			Type:        exec.Call_UPDATE.Enum(),
+			Update:      update,
+		})
+		if err != nil {
+			e.logger.Emit(logging.ERROR, "Failed to resend update for task %s: %s", update.GetStatus().GetTaskId().GetValue(), err.Error())
+			continue
+		}
+		sent++
+	}
+
+	return sent
+}
+
+// Resends unacknowledged updates on an interval until stop is closed.
+func (e *DefaultExecutor) RunRetries(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			e.Retry()
+		case <-stop:
+			return
+		}
+	}
+}
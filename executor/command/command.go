@@ -0,0 +1,289 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"errors"
+	"github.com/verizonlabs/mesos-framework-sdk/executor"
+	"github.com/verizonlabs/mesos-framework-sdk/executor/healthcheck"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	exec "github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_executor"
+	"github.com/verizonlabs/mesos-framework-sdk/logging"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"os"
+	osexec "os/exec"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Grace period given to tasks without a kill policy.
+const DEFAULT_GRACE_PERIOD = 3 * time.Second
+
+// A running task's process.
+type process struct {
+	cmd    *osexec.Cmd
+	policy *mesos_v1.KillPolicy
+	killed bool
+	exited chan struct{}
+}
+
+// Reference executor that runs the command of each task as a process in the sandbox.
+// Processes are monitored and their exit is reported as TASK_FINISHED, TASK_FAILED or TASK_KILLED.
+// Tasks with a health check are checked while they run.
+// Handles the executor's events, so it's meant to be run with RunEventLoop on the default executor.
+type CommandExecutor struct {
+	executor executor.Executor
+	logger   logging.Logger
+	Sandbox  string // Directory the commands are run in. Defaults to the sandbox the agent gave the executor.
+	tasks    map[string]*process
+	done     chan struct{}
+	shutdown bool
+	sync.Mutex
+}
+
+func NewCommandExecutor(e executor.Executor, lgr logging.Logger) *CommandExecutor {
+	return &CommandExecutor{
+		executor: e,
+		logger:   lgr,
+		Sandbox:  os.Getenv("MESOS_SANDBOX"),
+		tasks:    make(map[string]*process),
+		done:     make(chan struct{}),
+	}
+}
+
+// Closed once the executor has been shut down and all of its tasks have exited.
+func (c *CommandExecutor) Done() <-chan struct{} {
+	return c.done
+}
+
+// Sends a status update for a task, logging any failure.
+func (c *CommandExecutor) update(task *mesos_v1.TaskInfo, state mesos_v1.TaskState, message string) {
+	status := &mesos_v1.TaskStatus{
+		TaskId:  task.GetTaskId(),
+		AgentId: task.GetAgentId(),
+		State:   state.Enum(),
+	}
+	if message != "" {
+		status.Message = utils.ProtoString(message)
+	}
+
+	if err := c.executor.Update(status); err != nil {
+		c.logger.Emit(logging.ERROR, "Failed to update task %s: %s", task.GetTaskId().GetValue(), err.Error())
+	}
+}
+
+// Builds the process for a task's command.
+// Shell commands are run through /bin/sh, otherwise the value is run directly with the arguments after the first.
+func (c *CommandExecutor) command(task *mesos_v1.TaskInfo) (*osexec.Cmd, error) {
+	command := task.GetCommand()
+	if command.GetValue() == "" {
+		return nil, errors.New("Task has no command to run")
+	}
+
+	var cmd *osexec.Cmd
+	if command.GetShell() {
+		cmd = osexec.Command("/bin/sh", "-c", command.GetValue())
+	} else {
+		var args []string
+		if len(command.GetArguments()) > 1 {
+			args = command.GetArguments()[1:]
+		}
+		cmd = osexec.Command(command.GetValue(), args...)
+	}
+
+	// Each task gets its own process group so killing it also reaches anything it started.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Dir = c.Sandbox
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	for _, v := range command.GetEnvironment().GetVariables() {
+		cmd.Env = append(cmd.Env, v.GetName()+"="+v.GetValue())
+	}
+
+	return cmd, nil
+}
+
+// Starts a task's process and reports it as running, or failed when it can't be started.
+func (c *CommandExecutor) launch(task *mesos_v1.TaskInfo) {
+	id := task.GetTaskId().GetValue()
+
+	c.Lock()
+	if c.shutdown {
+		c.Unlock()
+		c.update(task, mesos_v1.TaskState_TASK_FAILED, "Executor is shutting down")
+		return
+	}
+	if _, ok := c.tasks[id]; ok {
+		c.Unlock()
+		c.update(task, mesos_v1.TaskState_TASK_FAILED, "Task is already running")
+		return
+	}
+
+	cmd, err := c.command(task)
+	if err == nil {
+		err = cmd.Start()
+	}
+	if err != nil {
+		c.Unlock()
+		c.update(task, mesos_v1.TaskState_TASK_FAILED, "Failed to start command: "+err.Error())
+		return
+	}
+
+	p := &process{cmd: cmd, policy: task.GetKillPolicy(), exited: make(chan struct{})}
+	c.tasks[id] = p
+	c.Unlock()
+
+	c.update(task, mesos_v1.TaskState_TASK_RUNNING, "")
+	go c.wait(task, p)
+
+	// Tasks that fail their health check too many times in a row are killed, like with the built-in executors.
+	if task.GetHealthCheck() != nil {
+		checker, err := healthcheck.NewChecker(task, c.executor)
+		if err != nil {
+			c.logger.Emit(logging.ERROR, "Not health checking task %s: %s", id, err.Error())
+			return
+		}
+
+		checker.Kill = func(*mesos_v1.TaskInfo) {
+			c.kill(id, nil)
+		}
+		go checker.Run(p.exited)
+	}
+}
+
+// Waits for a task's process to exit and reports how it went.
+func (c *CommandExecutor) wait(task *mesos_v1.TaskInfo, p *process) {
+	err := p.cmd.Wait()
+	close(p.exited)
+
+	c.Lock()
+	delete(c.tasks, task.GetTaskId().GetValue())
+	killed := p.killed
+	c.Unlock()
+
+	switch {
+	case killed:
+		// Don't leave anything the task started running after it's reported as killed.
+		syscall.Kill(-p.cmd.Process.Pid, syscall.SIGKILL)
+		c.update(task, mesos_v1.TaskState_TASK_KILLED, "Command was killed")
+	case err == nil:
+		c.update(task, mesos_v1.TaskState_TASK_FINISHED, "Command exited with status 0")
+	default:
+		message := "Command failed: " + err.Error()
+		if exit, ok := err.(*osexec.ExitError); ok {
+			if status, ok := exit.Sys().(syscall.WaitStatus); ok {
+				message = "Command exited with status " + strconv.Itoa(status.ExitStatus())
+			}
+		}
+		c.update(task, mesos_v1.TaskState_TASK_FAILED, message)
+	}
+
+	c.finish()
+}
+
+// Closes done once we're shut down and nothing is left running.
+func (c *CommandExecutor) finish() {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.shutdown && len(c.tasks) == 0 {
+		select {
+		case <-c.done:
+		default:
+			close(c.done)
+		}
+	}
+}
+
+// Asks a task's process group to stop, forcibly killing it once the grace period is over.
+// The grace period comes from the given kill policy, falling back to the one the task was launched with.
+func (c *CommandExecutor) kill(id string, policy *mesos_v1.KillPolicy) {
+	c.Lock()
+	p, ok := c.tasks[id]
+	if ok {
+		p.killed = true
+		if policy == nil {
+			policy = p.policy
+		}
+	}
+	c.Unlock()
+
+	if !ok {
+		return
+	}
+
+	grace := DEFAULT_GRACE_PERIOD
+	if policy.GetGracePeriod() != nil {
+		grace = time.Duration(policy.GetGracePeriod().GetNanoseconds())
+	}
+
+	pgid := p.cmd.Process.Pid
+	syscall.Kill(-pgid, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-p.exited:
+		case <-time.After(grace):
+			syscall.Kill(-pgid, syscall.SIGKILL)
+		}
+	}()
+}
+
+func (c *CommandExecutor) Subscribed(event *exec.Event_Subscribed) {
+	c.logger.Emit(logging.INFO, "Subscribed to agent %s", event.GetAgentInfo().GetHostname())
+}
+
+func (c *CommandExecutor) Launch(event *exec.Event_Launch) {
+	c.launch(event.GetTask())
+}
+
+func (c *CommandExecutor) LaunchGroup(event *exec.Event_LaunchGroup) {
+	for _, task := range event.GetTaskGroup().GetTasks() {
+		c.launch(task)
+	}
+}
+
+// Kills a task, honoring the kill policy from the event over the one it was launched with.
+func (c *CommandExecutor) Kill(event *exec.Event_Kill) {
+	c.kill(event.GetTaskId().GetValue(), event.GetKillPolicy())
+}
+
+func (c *CommandExecutor) Acknowledged(*exec.Event_Acknowledged) {}
+
+func (c *CommandExecutor) Message(event *exec.Event_Message) {
+	c.logger.Emit(logging.INFO, "Received a %d byte message from the framework", len(event.GetData()))
+}
+
+// Kills every task and closes done once they've all exited.
+func (c *CommandExecutor) Shutdown() {
+	c.Lock()
+	c.shutdown = true
+	ids := make([]string, 0, len(c.tasks))
+	for id := range c.tasks {
+		ids = append(ids, id)
+	}
+	c.Unlock()
+
+	for _, id := range ids {
+		c.kill(id, nil)
+	}
+	c.finish()
+}
+
+func (c *CommandExecutor) Error(event *exec.Event_Error) {
+	c.logger.Emit(logging.ERROR, "Agent sent an error: %s", event.GetMessage())
+}
@@ -0,0 +1,160 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	exec "github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_executor"
+	"github.com/verizonlabs/mesos-framework-sdk/resources"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mockLogger struct{}
+
+func (m *mockLogger) Emit(severity uint8, template string, args ...interface{}) {
+
+}
+
+// Executor that hands every update to the test.
+type updates chan *mesos_v1.TaskStatus
+
+func (u updates) Subscribe(chan *exec.Event) error { return nil }
+func (u updates) Message([]byte) error             { return nil }
+func (u updates) Update(status *mesos_v1.TaskStatus) error {
+	u <- status
+	return nil
+}
+
+func (u updates) next(t *testing.T, want mesos_v1.TaskState) *mesos_v1.TaskStatus {
+	select {
+	case status := <-u:
+		if status.GetState() != want {
+			t.Fatal("Expected " + want.String() + " but got " + status.GetState().String() + ": " + status.GetMessage())
+		}
+		return status
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for " + want.String())
+	}
+
+	return nil
+}
+
+func task(id, cmd string) *exec.Event_Launch {
+	return &exec.Event_Launch{Task: &mesos_v1.TaskInfo{
+		Name:    utils.ProtoString(id),
+		TaskId:  &mesos_v1.TaskID{Value: utils.ProtoString(id)},
+		Command: &mesos_v1.CommandInfo{Value: utils.ProtoString(cmd)},
+	}}
+}
+
+// Ensures commands are run and their exit is reported.
+func TestCommandExecutor(t *testing.T) {
+	t.Parallel()
+
+	u := make(updates, 10)
+	c := NewCommandExecutor(u, new(mockLogger))
+
+	c.Launch(task("finished", "exit 0"))
+	u.next(t, mesos_v1.TaskState_TASK_RUNNING)
+	u.next(t, mesos_v1.TaskState_TASK_FINISHED)
+
+	c.Launch(task("failed", "exit 3"))
+	u.next(t, mesos_v1.TaskState_TASK_RUNNING)
+	if u.next(t, mesos_v1.TaskState_TASK_FAILED).GetMessage() != "Command exited with status 3" {
+		t.Fatal("Failures should carry the exit code")
+	}
+
+	c.Launch(task("empty", ""))
+	u.next(t, mesos_v1.TaskState_TASK_FAILED)
+
+	c.Launch(task("killed", "trap '' TERM; exec sleep 30"))
+	u.next(t, mesos_v1.TaskState_TASK_RUNNING)
+	c.Kill(&exec.Event_Kill{
+		TaskId:     &mesos_v1.TaskID{Value: utils.ProtoString("killed")},
+		KillPolicy: resources.CreateKillPolicy(10 * time.Millisecond),
+	})
+	u.next(t, mesos_v1.TaskState_TASK_KILLED)
+
+	c.Launch(task("shutdown", "exec sleep 30"))
+	u.next(t, mesos_v1.TaskState_TASK_RUNNING)
+	c.Shutdown()
+	u.next(t, mesos_v1.TaskState_TASK_KILLED)
+	select {
+	case <-c.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Shutting down should finish once every task has exited")
+	}
+}
+
+// Ensures killing a task also kills whatever it started, even if that ignores the kill signal.
+func TestCommandExecutor_KillGroup(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "command")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	u := make(updates, 10)
+	c := NewCommandExecutor(u, new(mockLogger))
+	c.Sandbox = dir
+
+	c.Launch(task("group", "sh -c \"trap '' TERM; exec sleep 30\" & echo $! > child; wait"))
+	u.next(t, mesos_v1.TaskState_TASK_RUNNING)
+
+	var child []byte
+	for i := 0; i < 100 && len(child) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+		child, _ = ioutil.ReadFile(filepath.Join(dir, "child"))
+	}
+	if len(child) == 0 {
+		t.Fatal("Task never started its child")
+	}
+
+	c.Kill(&exec.Event_Kill{
+		TaskId:     &mesos_v1.TaskID{Value: utils.ProtoString("group")},
+		KillPolicy: resources.CreateKillPolicy(time.Minute),
+	})
+	u.next(t, mesos_v1.TaskState_TASK_KILLED)
+
+	// The child is reparented once the task exits, so it may linger as a zombie if nothing reaps it.
+	stat := filepath.Join("/proc", strings.TrimSpace(string(child)), "stat")
+	for i := 0; i < 100; i++ {
+		data, err := ioutil.ReadFile(stat)
+		if err != nil || strings.Contains(string(data), ") Z ") {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Child of a killed task is still running")
+}
+
+// Measures performance of running a command to completion.
+func BenchmarkCommandExecutor(b *testing.B) {
+	u := make(updates, 2)
+	c := NewCommandExecutor(u, new(mockLogger))
+	for n := 0; n < b.N; n++ {
+		c.Launch(task("task", "true"))
+		<-u
+		<-u
+	}
+}
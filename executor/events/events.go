@@ -39,3 +39,39 @@ type ExecutorEvents interface {
 	Error(*mesos_v1_executor.Event_Error)
 	Run(*mesos_v1_executor.Event)
 }
+
+// Handles the events of an executor event stream.
+// RunEventLoop on the default executor dispatches events to a handler so executors don't need their own event switch.
+type EventHandler interface {
+	Subscribed(*mesos_v1_executor.Event_Subscribed)
+	Launch(*mesos_v1_executor.Event_Launch)
+	LaunchGroup(*mesos_v1_executor.Event_LaunchGroup)
+	Kill(*mesos_v1_executor.Event_Kill)
+	Acknowledged(*mesos_v1_executor.Event_Acknowledged)
+	Message(*mesos_v1_executor.Event_Message)
+	Shutdown()
+	Error(*mesos_v1_executor.Event_Error)
+}
+
+// Calls the handler method that matches the event's type.
+// Event types the handler doesn't know about are ignored.
+func Dispatch(handler EventHandler, event *mesos_v1_executor.Event) {
+	switch event.GetType() {
+	case mesos_v1_executor.Event_SUBSCRIBED:
+		handler.Subscribed(event.GetSubscribed())
+	case mesos_v1_executor.Event_LAUNCH:
+		handler.Launch(event.GetLaunch())
+	case mesos_v1_executor.Event_LAUNCH_GROUP:
+		handler.LaunchGroup(event.GetLaunchGroup())
+	case mesos_v1_executor.Event_KILL:
+		handler.Kill(event.GetKill())
+	case mesos_v1_executor.Event_ACKNOWLEDGED:
+		handler.Acknowledged(event.GetAcknowledged())
+	case mesos_v1_executor.Event_MESSAGE:
+		handler.Message(event.GetMessage())
+	case mesos_v1_executor.Event_SHUTDOWN:
+		handler.Shutdown()
+	case mesos_v1_executor.Event_ERROR:
+		handler.Error(event.GetError())
+	}
+}
@@ -15,26 +15,38 @@
 package executor
 
 import (
+	"context"
+	"errors"
 	"github.com/verizonlabs/mesos-framework-sdk/client"
 	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
 	exec "github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_executor"
 	"github.com/verizonlabs/mesos-framework-sdk/logging"
 	"github.com/verizonlabs/mesos-framework-sdk/recordio"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
 )
 
+const EXECUTOR_PATH = "/api/v1/executor"
+
 type Executor interface {
 	Subscribe(chan *exec.Event) error
 	Update(*mesos_v1.TaskStatus) error
 	Message([]byte) error
 }
 
+// Executor that keeps track of unacknowledged tasks and updates so they can be re-sent when re-subscribing to the agent.
 type DefaultExecutor struct {
 	frameworkId    *mesos_v1.FrameworkID
 	executorId     *mesos_v1.ExecutorID
 	client         client.Client
 	logger         logging.Logger
-	unackedTasks   map[*mesos_v1.TaskID]mesos_v1.TaskInfo
+	unackedTasks   map[string]mesos_v1.TaskInfo
 	unackedUpdates map[string]exec.Call_Update
+	checkpoint     string
+	sync.Mutex
 }
 
 // Creates a new default executor
@@ -49,11 +61,42 @@ func NewDefaultExecutor(
 		executorId:     e,
 		client:         c,
 		logger:         lgr,
-		unackedTasks:   make(map[*mesos_v1.TaskID]mesos_v1.TaskInfo),
+		unackedTasks:   make(map[string]mesos_v1.TaskInfo),
 		unackedUpdates: make(map[string]exec.Call_Update),
 	}
 }
 
+// Creates a default executor from the environment the agent launches executors with.
+// Executors of frameworks that checkpoint also checkpoint their unacknowledged updates to the sandbox.
+func NewExecutorFromEnvironment(lgr logging.Logger) (Executor, error) {
+	framework := os.Getenv("MESOS_FRAMEWORK_ID")
+	executor := os.Getenv("MESOS_EXECUTOR_ID")
+	agent := os.Getenv("MESOS_AGENT_ENDPOINT")
+	if framework == "" || executor == "" || agent == "" {
+		return nil, errors.New("MESOS_FRAMEWORK_ID, MESOS_EXECUTOR_ID and MESOS_AGENT_ENDPOINT must be set.")
+	}
+
+	data := client.ClientData{Endpoint: "http://" + agent + EXECUTOR_PATH}
+	if token := os.Getenv("MESOS_EXECUTOR_AUTHENTICATION_TOKEN"); token != "" {
+		data.Auth = "Bearer " + token
+	}
+
+	e := NewDefaultExecutor(
+		&mesos_v1.FrameworkID{Value: utils.ProtoString(framework)},
+		&mesos_v1.ExecutorID{Value: utils.ProtoString(executor)},
+		client.NewClient(data, lgr),
+		lgr,
+	).(*DefaultExecutor)
+
+	if os.Getenv("MESOS_CHECKPOINT") == "1" {
+		if err := e.Checkpoint(filepath.Join(os.Getenv("MESOS_SANDBOX"), CHECKPOINT_FILE)); err != nil {
+			return nil, err
+		}
+	}
+
+	return e, nil
+}
+
 func (e *DefaultExecutor) unacknowledgedTasks() []*mesos_v1.TaskInfo {
 	numTasks := len(e.unackedTasks)
 	if numTasks == 0 {
@@ -84,7 +127,48 @@ func (e *DefaultExecutor) unacknowledgedUpdates() []*exec.Call_Update {
 	return updates
 }
 
+// Returns the number of tasks and updates the agent hasn't acknowledged yet.
+func (e *DefaultExecutor) Unacknowledged() (tasks, updates int) {
+	e.Lock()
+	defer e.Unlock()
+
+	return len(e.unackedTasks), len(e.unackedUpdates)
+}
+
+// Keeps track of launched tasks and acknowledged updates as events arrive.
+func (e *DefaultExecutor) track(event *exec.Event) {
+	e.Lock()
+	defer e.Unlock()
+
+	switch event.GetType() {
+	case exec.Event_LAUNCH:
+		task := event.GetLaunch().GetTask()
+		e.unackedTasks[task.GetTaskId().GetValue()] = *task
+	case exec.Event_LAUNCH_GROUP:
+		for _, task := range event.GetLaunchGroup().GetTaskGroup().GetTasks() {
+			e.unackedTasks[task.GetTaskId().GetValue()] = *task
+		}
+	case exec.Event_ACKNOWLEDGED:
+		ack := event.GetAcknowledged()
+		delete(e.unackedTasks, ack.GetTaskId().GetValue())
+		delete(e.unackedUpdates, string(ack.GetUuid()))
+	default:
+		return
+	}
+
+	if err := e.persist(); err != nil {
+		e.logger.Emit(logging.ERROR, "Failed to checkpoint: %s", err.Error())
+	}
+}
+
 func (e *DefaultExecutor) Subscribe(eventChan chan *exec.Event) error {
+	return e.SubscribeContext(context.Background(), eventChan)
+}
+
+// Subscribes to the agent, re-sending anything it hasn't acknowledged, and hands events over until the stream ends.
+// The subscription is dropped once the context is done.
+func (e *DefaultExecutor) SubscribeContext(ctx context.Context, eventChan chan *exec.Event) error {
+	e.Lock()
 	subscribe := &exec.Call{
 		FrameworkId: e.frameworkId,
 		ExecutorId:  e.executorId,
@@ -94,16 +178,47 @@ func (e *DefaultExecutor) Subscribe(eventChan chan *exec.Event) error {
 			UnacknowledgedUpdates: e.unacknowledgedUpdates(),
 		},
 	}
+	e.Unlock()
 
-	resp, err := e.client.Request(subscribe)
+	resp, err := e.client.RequestContext(ctx, subscribe)
 	if err != nil {
 		return err
-	} else {
-		return recordio.Decode(resp.Body, eventChan)
+	}
+	defer resp.Body.Close()
+
+	events := make(chan *exec.Event)
+	done := make(chan error, 1)
+	go func() {
+		done <- recordio.Decode(resp.Body, events)
+	}()
+
+	for {
+		select {
+		case event := <-events:
+			e.track(event)
+			eventChan <- event
+		case err := <-done:
+			return err
+		}
 	}
 }
 
+// Sends a status update for a task.
+// Updates are given a UUID when they don't have one and kept until the agent acknowledges them.
 func (e *DefaultExecutor) Update(taskStatus *mesos_v1.TaskStatus) error {
+	if taskStatus.Uuid == nil {
+		taskStatus.Uuid = utils.Uuid()
+	}
+	if taskStatus.Source == nil {
+		taskStatus.Source = mesos_v1.TaskStatus_SOURCE_EXECUTOR.Enum()
+	}
+	if taskStatus.ExecutorId == nil {
+		taskStatus.ExecutorId = e.executorId
+	}
+	if taskStatus.Timestamp == nil {
+		taskStatus.Timestamp = utils.ProtoFloat64(float64(time.Now().UnixNano()) / float64(time.Second))
+	}
+
 	update := &exec.Call{
 		FrameworkId: e.frameworkId,
 		ExecutorId:  e.executorId,
@@ -112,7 +227,20 @@ func (e *DefaultExecutor) Update(taskStatus *mesos_v1.TaskStatus) error {
 			Status: taskStatus,
 		},
 	}
-	_, err := e.client.Request(update)
+
+	e.Lock()
+	e.unackedUpdates[string(taskStatus.Uuid)] = *update.Update
+	err := e.persist()
+	e.Unlock()
+
+	if err != nil {
+		e.logger.Emit(logging.ERROR, "Failed to checkpoint: %s", err.Error())
+	}
+
+	_, err = e.client.Request(update)
+	if err != nil {
+		e.logger.Emit(logging.ERROR, "Failed to send update for task %s: %s", taskStatus.GetTaskId().GetValue(), err.Error())
+	}
 
 	return err
 }
@@ -0,0 +1,223 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"github.com/golang/protobuf/proto"
+	"github.com/verizonlabs/mesos-framework-sdk/client"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	exec "github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_executor"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+type mockLogger struct{}
+
+func (m *mockLogger) Emit(severity uint8, template string, args ...interface{}) {
+
+}
+
+// Fake agent that streams the queued events to each subscription and keeps every call it gets.
+type agent struct {
+	stream []*exec.Event
+	calls  []*exec.Call
+	sync.Mutex
+}
+
+func (a *agent) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	call := new(exec.Call)
+	proto.Unmarshal(body, call)
+
+	a.Lock()
+	defer a.Unlock()
+
+	a.calls = append(a.calls, call)
+	if call.GetType() != exec.Call_SUBSCRIBE {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	for _, event := range a.stream {
+		data, _ := proto.Marshal(event)
+		w.Write(append([]byte(strconv.Itoa(len(data))+"\n"), data...))
+	}
+}
+
+func (a *agent) last() *exec.Call {
+	a.Lock()
+	defer a.Unlock()
+
+	return a.calls[len(a.calls)-1]
+}
+
+type recordingHandler struct {
+	launched, acked int
+}
+
+func (r *recordingHandler) Subscribed(*exec.Event_Subscribed)     {}
+func (r *recordingHandler) Launch(*exec.Event_Launch)             { r.launched++ }
+func (r *recordingHandler) LaunchGroup(*exec.Event_LaunchGroup)   { r.launched++ }
+func (r *recordingHandler) Kill(*exec.Event_Kill)                 {}
+func (r *recordingHandler) Acknowledged(*exec.Event_Acknowledged) { r.acked++ }
+func (r *recordingHandler) Message(*exec.Event_Message)           {}
+func (r *recordingHandler) Shutdown()                             {}
+func (r *recordingHandler) Error(*exec.Event_Error)               {}
+
+func launch(id string) *exec.Event {
+	return &exec.Event{
+		Type: exec.Event_LAUNCH.Enum(),
+		Launch: &exec.Event_Launch{Task: &mesos_v1.TaskInfo{
+			Name:   utils.ProtoString(id),
+			TaskId: &mesos_v1.TaskID{Value: utils.ProtoString(id)},
+		}},
+	}
+}
+
+// Ensures launched tasks and sent updates are tracked until the agent acknowledges them.
+func TestDefaultExecutor_RunEventLoop(t *testing.T) {
+	t.Parallel()
+
+	a := &agent{stream: []*exec.Event{launch("a"), launch("b")}}
+	srv := httptest.NewServer(a)
+	defer srv.Close()
+
+	e := NewDefaultExecutor(
+		&mesos_v1.FrameworkID{Value: utils.ProtoString("framework")},
+		&mesos_v1.ExecutorID{Value: utils.ProtoString("executor")},
+		client.NewClient(client.ClientData{Endpoint: srv.URL}, new(mockLogger)),
+		new(mockLogger),
+	).(*DefaultExecutor)
+
+	handler := new(recordingHandler)
+	if err := e.RunEventLoop(context.Background(), handler); err != io.EOF {
+		t.Fatal("The event loop should run until the stream ends")
+	}
+	if tasks, _ := e.Unacknowledged(); tasks != 2 || handler.launched != 2 {
+		t.Fatal("Launched tasks should be dispatched and tracked")
+	}
+
+	status := &mesos_v1.TaskStatus{TaskId: &mesos_v1.TaskID{Value: utils.ProtoString("a")}, State: mesos_v1.TaskState_TASK_RUNNING.Enum()}
+	if err := e.Update(status); err != nil {
+		t.Fatal(err.Error())
+	}
+	update := a.last().GetUpdate().GetStatus()
+	if update.GetUuid() == nil || update.GetSource() != mesos_v1.TaskStatus_SOURCE_EXECUTOR || update.GetExecutorId().GetValue() != "executor" {
+		t.Fatal("Updates should be filled in with what the agent requires")
+	}
+
+	a.Lock()
+	a.stream = []*exec.Event{{
+		Type:         exec.Event_ACKNOWLEDGED.Enum(),
+		Acknowledged: &exec.Event_Acknowledged{TaskId: status.TaskId, Uuid: status.Uuid},
+	}}
+	a.Unlock()
+
+	if err := e.RunEventLoop(context.Background(), handler); err != io.EOF {
+		t.Fatal("The event loop should run until the stream ends")
+	}
+	subscribe := a.last().GetSubscribe()
+	if len(subscribe.GetUnacknowledgedTasks()) != 2 || len(subscribe.GetUnacknowledgedUpdates()) != 1 {
+		t.Fatal("Unacknowledged tasks and updates should be sent when re-subscribing")
+	}
+	if tasks, updates := e.Unacknowledged(); tasks != 1 || updates != 0 || handler.acked != 1 {
+		t.Fatal("Acknowledged updates and their tasks should no longer be tracked")
+	}
+}
+
+// Measures performance of sending status updates.
+func BenchmarkDefaultExecutor_Update(b *testing.B) {
+	srv := httptest.NewServer(new(agent))
+	defer srv.Close()
+
+	e := NewDefaultExecutor(
+		&mesos_v1.FrameworkID{Value: utils.ProtoString("framework")},
+		&mesos_v1.ExecutorID{Value: utils.ProtoString("executor")},
+		client.NewClient(client.ClientData{Endpoint: srv.URL}, new(mockLogger)),
+		new(mockLogger),
+	)
+	for n := 0; n < b.N; n++ {
+		e.Update(&mesos_v1.TaskStatus{TaskId: &mesos_v1.TaskID{Value: utils.ProtoString("a")}})
+	}
+}
+
+// Ensures executors can be set up from what the agent puts in their environment.
+func TestNewExecutorFromEnvironment(t *testing.T) {
+	if _, err := NewExecutorFromEnvironment(new(mockLogger)); err == nil {
+		t.Fatal("Executors can't be created outside of an agent")
+	}
+}
+
+// Ensures unacknowledged updates survive an executor restart and are sent again.
+func TestDefaultExecutor_Checkpoint(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "executor")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	a := new(agent)
+	srv := httptest.NewServer(a)
+	defer srv.Close()
+
+	create := func() *DefaultExecutor {
+		e := NewDefaultExecutor(
+			&mesos_v1.FrameworkID{Value: utils.ProtoString("framework")},
+			&mesos_v1.ExecutorID{Value: utils.ProtoString("executor")},
+			client.NewClient(client.ClientData{Endpoint: srv.URL}, new(mockLogger)),
+			new(mockLogger),
+		).(*DefaultExecutor)
+		if err := e.Checkpoint(filepath.Join(dir, CHECKPOINT_FILE)); err != nil {
+			t.Fatal(err.Error())
+		}
+		return e
+	}
+
+	e := create()
+	e.Update(&mesos_v1.TaskStatus{TaskId: &mesos_v1.TaskID{Value: utils.ProtoString("a")}, State: mesos_v1.TaskState_TASK_RUNNING.Enum()})
+
+	restarted := create()
+	if _, updates := restarted.Unacknowledged(); updates != 1 {
+		t.Fatal("Unacknowledged updates should be restored from the checkpoint")
+	}
+	if restarted.Retry() != 1 || a.last().GetUpdate().GetStatus().GetTaskId().GetValue() != "a" {
+		t.Fatal("Restored updates should be resent")
+	}
+
+	status := a.last().GetUpdate().GetStatus()
+	a.Lock()
+	a.stream = []*exec.Event{{
+		Type:         exec.Event_ACKNOWLEDGED.Enum(),
+		Acknowledged: &exec.Event_Acknowledged{TaskId: status.TaskId, Uuid: status.Uuid},
+	}}
+	a.Unlock()
+
+	restarted.RunEventLoop(context.Background(), new(recordingHandler))
+	if _, updates := create().Unacknowledged(); updates != 0 {
+		t.Fatal("Acknowledged updates should be removed from the checkpoint")
+	}
+}
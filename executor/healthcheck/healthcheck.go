@@ -0,0 +1,229 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"github.com/verizonlabs/mesos-framework-sdk/executor"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"net"
+	"net/http"
+	osexec "os/exec"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Runs a task's health check the same way the built-in Mesos executors do.
+// Healthy and unhealthy transitions are reported as TASK_RUNNING updates,
+// and the task is handed to Kill once it fails too many checks in a row.
+type Checker struct {
+	task     *mesos_v1.TaskInfo
+	check    *mesos_v1.HealthCheck
+	executor executor.Executor
+	Host     string                        // Host that HTTP and TCP checks connect to.
+	Kill     func(task *mesos_v1.TaskInfo) // Called once after too many consecutive failures. Nothing is killed when unset.
+	healthy  *bool
+	failures uint32
+	sync.Mutex
+}
+
+// Creates a checker for a task, failing if the task doesn't have a health check we know how to run.
+func NewChecker(task *mesos_v1.TaskInfo, e executor.Executor) (*Checker, error) {
+	check := task.GetHealthCheck()
+	if check == nil {
+		return nil, errors.New("Task has no health check")
+	}
+
+	switch check.GetType() {
+	case mesos_v1.HealthCheck_COMMAND:
+		if check.GetCommand().GetValue() == "" {
+			return nil, errors.New("Command health check has no command")
+		}
+	case mesos_v1.HealthCheck_HTTP:
+		if check.GetHttp() == nil {
+			return nil, errors.New("HTTP health check has no port")
+		}
+	case mesos_v1.HealthCheck_TCP:
+		if check.GetTcp() == nil {
+			return nil, errors.New("TCP health check has no port")
+		}
+	default:
+		return nil, errors.New("Unsupported health check type " + check.GetType().String())
+	}
+
+	return &Checker{
+		task:     task,
+		check:    check,
+		executor: e,
+		Host:     "127.0.0.1",
+	}, nil
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// Runs a single check, returning why it failed.
+func (c *Checker) Check(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, seconds(c.check.GetTimeoutSeconds()))
+	defer cancel()
+
+	switch c.check.GetType() {
+	case mesos_v1.HealthCheck_COMMAND:
+		return c.command(ctx)
+	case mesos_v1.HealthCheck_HTTP:
+		return c.http(ctx)
+	default:
+		return c.tcp(ctx)
+	}
+}
+
+// Healthy when the command exits with status 0.
+func (c *Checker) command(ctx context.Context) error {
+	command := c.check.GetCommand()
+
+	var cmd *osexec.Cmd
+	if command.GetShell() {
+		cmd = osexec.CommandContext(ctx, "/bin/sh", "-c", command.GetValue())
+	} else {
+		var args []string
+		if len(command.GetArguments()) > 1 {
+			args = command.GetArguments()[1:]
+		}
+		cmd = osexec.CommandContext(ctx, command.GetValue(), args...)
+	}
+
+	return cmd.Run()
+}
+
+// Healthy when the response status is one of the expected ones, or between 200 and 399 when none are given.
+func (c *Checker) http(ctx context.Context) error {
+	info := c.check.GetHttp()
+	scheme := info.GetScheme()
+	if scheme == "" {
+		scheme = "http"
+	}
+
+	req, err := http.NewRequest("GET", scheme+"://"+net.JoinHostPort(c.Host, strconv.Itoa(int(info.GetPort())))+info.GetPath(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if len(info.GetStatuses()) == 0 {
+		if resp.StatusCode >= 200 && resp.StatusCode < 400 {
+			return nil
+		}
+	}
+	for _, status := range info.GetStatuses() {
+		if int(status) == resp.StatusCode {
+			return nil
+		}
+	}
+
+	return errors.New("Unexpected HTTP status " + resp.Status)
+}
+
+// Healthy when a connection can be established.
+func (c *Checker) tcp(ctx context.Context) error {
+	deadline, _ := ctx.Deadline()
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(c.Host, strconv.Itoa(int(c.check.GetTcp().GetPort()))), deadline.Sub(time.Now()))
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
+
+// Records the result of a check, reporting transitions.
+// Failures during the grace period don't count until the task has been healthy once.
+// Returns whether the task should be killed.
+func (c *Checker) record(err error, inGrace bool) bool {
+	c.Lock()
+	defer c.Unlock()
+
+	if err == nil {
+		c.failures = 0
+		c.report(true, "")
+		return false
+	}
+	if inGrace && c.healthy == nil {
+		return false
+	}
+
+	c.failures++
+	c.report(false, err.Error())
+
+	return c.failures >= c.check.GetConsecutiveFailures()
+}
+
+// Sends a TASK_RUNNING update when the task's health changes.
+// Must be called with the lock held.
+func (c *Checker) report(healthy bool, message string) {
+	if c.healthy != nil && *c.healthy == healthy {
+		return
+	}
+	c.healthy = &healthy
+
+	status := &mesos_v1.TaskStatus{
+		TaskId:  c.task.GetTaskId(),
+		AgentId: c.task.GetAgentId(),
+		State:   mesos_v1.TaskState_TASK_RUNNING.Enum(),
+		Healthy: &healthy,
+	}
+	if message != "" {
+		status.Message = &message
+	}
+
+	c.executor.Update(status)
+}
+
+// Checks the task on its interval after the initial delay, until stop is closed or the task is handed to Kill.
+func (c *Checker) Run(stop <-chan struct{}) {
+	start := time.Now()
+	grace := seconds(c.check.GetGracePeriodSeconds())
+
+	select {
+	case <-time.After(seconds(c.check.GetDelaySeconds())):
+	case <-stop:
+		return
+	}
+
+	ticker := time.NewTicker(seconds(c.check.GetIntervalSeconds()))
+	defer ticker.Stop()
+
+	for {
+		err := c.Check(context.Background())
+		if c.record(err, time.Since(start) < grace) {
+			if c.Kill != nil {
+				c.Kill(c.task)
+			}
+			return
+		}
+
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"context"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	exec "github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_executor"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Executor that hands every update to the test.
+type updates chan *mesos_v1.TaskStatus
+
+func (u updates) Subscribe(chan *exec.Event) error { return nil }
+func (u updates) Message([]byte) error             { return nil }
+func (u updates) Update(status *mesos_v1.TaskStatus) error {
+	u <- status
+	return nil
+}
+
+func task(check *mesos_v1.HealthCheck) *mesos_v1.TaskInfo {
+	check.DelaySeconds = utils.ProtoFloat64(0)
+	check.IntervalSeconds = utils.ProtoFloat64(0.001)
+	check.TimeoutSeconds = utils.ProtoFloat64(1)
+	check.GracePeriodSeconds = utils.ProtoFloat64(0)
+
+	return &mesos_v1.TaskInfo{
+		TaskId:      &mesos_v1.TaskID{Value: utils.ProtoString("task")},
+		HealthCheck: check,
+	}
+}
+
+func port(t *testing.T, addr string) *uint32 {
+	_, p, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	n, _ := strconv.Atoi(p)
+	return utils.ProtoUint32(uint32(n))
+}
+
+// Ensures every kind of check can tell healthy from unhealthy.
+func TestChecker_Check(t *testing.T) {
+	t.Parallel()
+
+	var status int32 = http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+
+	checks := map[string]*mesos_v1.HealthCheck{
+		"command": {Type: mesos_v1.HealthCheck_COMMAND.Enum(), Command: &mesos_v1.CommandInfo{Value: utils.ProtoString("exit 0")}},
+		"http":    {Type: mesos_v1.HealthCheck_HTTP.Enum(), Http: &mesos_v1.HealthCheck_HTTPCheckInfo{Port: port(t, u.Host)}},
+		"tcp":     {Type: mesos_v1.HealthCheck_TCP.Enum(), Tcp: &mesos_v1.HealthCheck_TCPCheckInfo{Port: port(t, u.Host)}},
+	}
+	for name, check := range checks {
+		c, err := NewChecker(task(check), make(updates))
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if err := c.Check(context.Background()); err != nil {
+			t.Fatal("The " + name + " check should pass: " + err.Error())
+		}
+	}
+
+	failing, _ := NewChecker(task(&mesos_v1.HealthCheck{Type: mesos_v1.HealthCheck_COMMAND.Enum(), Command: &mesos_v1.CommandInfo{Value: utils.ProtoString("exit 1")}}), make(updates))
+	if failing.Check(context.Background()) == nil {
+		t.Fatal("Commands that fail should be unhealthy")
+	}
+
+	atomic.StoreInt32(&status, http.StatusInternalServerError)
+	unhealthy, _ := NewChecker(task(checks["http"]), make(updates))
+	if unhealthy.Check(context.Background()) == nil {
+		t.Fatal("Error statuses should be unhealthy")
+	}
+
+	if _, err := NewChecker(task(&mesos_v1.HealthCheck{Type: mesos_v1.HealthCheck_HTTP.Enum()}), make(updates)); err == nil {
+		t.Fatal("Checks without what they need to run should be rejected")
+	}
+}
+
+// Measures performance of running a TCP check.
+func BenchmarkChecker_Check(b *testing.B) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		b.Fatal(err.Error())
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	_, p, _ := net.SplitHostPort(ln.Addr().String())
+	n, _ := strconv.Atoi(p)
+	c, _ := NewChecker(task(&mesos_v1.HealthCheck{
+		Type: mesos_v1.HealthCheck_TCP.Enum(),
+		Tcp:  &mesos_v1.HealthCheck_TCPCheckInfo{Port: utils.ProtoUint32(uint32(n))},
+	}), make(updates))
+	for i := 0; i < b.N; i++ {
+		c.Check(context.Background())
+	}
+}
+
+// Ensures health transitions are reported and the task is killed after too many failures.
+func TestChecker_Run(t *testing.T) {
+	t.Parallel()
+
+	var healthy int32 = 1
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&healthy) == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+	host, _ := url.Parse(srv.URL)
+
+	check := task(&mesos_v1.HealthCheck{
+		Type:                mesos_v1.HealthCheck_HTTP.Enum(),
+		Http:                &mesos_v1.HealthCheck_HTTPCheckInfo{Port: port(t, host.Host), Path: utils.ProtoString("/health")},
+		ConsecutiveFailures: utils.ProtoUint32(3),
+	})
+	u := make(updates, 10)
+	c, _ := NewChecker(check, u)
+	killed := make(chan struct{})
+	c.Kill = func(*mesos_v1.TaskInfo) {
+		close(killed)
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go c.Run(stop)
+
+	if status := <-u; !status.GetHealthy() || status.GetState() != mesos_v1.TaskState_TASK_RUNNING {
+		t.Fatal("Passing checks should report the task as healthy")
+	}
+
+	atomic.StoreInt32(&healthy, 0)
+	if (<-u).GetHealthy() {
+		t.Fatal("Failing checks should report the task as unhealthy")
+	}
+
+	select {
+	case <-killed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("The task should be killed after too many consecutive failures")
+	}
+	if len(u) != 0 {
+		t.Fatal("Only health transitions should be reported")
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ha
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/verizonlabs/mesos-framework-sdk/persistence"
+)
+
+// Elects a single leader among scheduler instances by having them race to create the same leased key.
+// The winner leads for as long as it keeps refreshing its lease, everyone else waits for the key to go away.
+// Each instance must campaign with a unique ID since the key's value is how the winner finds out it won.
+type Elector struct {
+	storage  persistence.Storage
+	key      string
+	id       string
+	ttl      int64
+	interval time.Duration
+	mu       sync.Mutex
+	status   Status
+	stop     chan struct{}
+	lost     chan struct{}
+}
+
+// Creates an election held under the key, the TTL in seconds is how long a dead leader keeps its leadership.
+func NewElector(storage persistence.Storage, key, id string, ttl int64) *Elector {
+	return &Elector{
+		storage:  storage,
+		key:      key,
+		id:       id,
+		ttl:      ttl,
+		interval: time.Duration(ttl) * time.Second / 3,
+		status:   Listening,
+	}
+}
+
+// Blocks until we become the leader or the context is done.
+// The returned channel is closed once we stop leading, either because our lease couldn't be refreshed or we resigned.
+func (e *Elector) Campaign(ctx context.Context) (<-chan struct{}, error) {
+	e.mu.Lock()
+	if e.status == Leading {
+		defer e.mu.Unlock()
+		return e.lost, nil
+	}
+	e.status = Election
+	e.mu.Unlock()
+
+	for {
+		// Drivers report losing the race differently, so the key's value tells us who won.
+		lease, err := e.storage.CreateWithLease(e.key, e.id, e.ttl)
+		leader, readErr := e.storage.Read(e.key)
+		if readErr != nil {
+			e.setStatus(Listening)
+			return nil, readErr
+		}
+		if err == nil && leader == e.id {
+			return e.lead(lease), nil
+		}
+
+		select {
+		case <-ctx.Done():
+			e.setStatus(Listening)
+			return nil, ctx.Err()
+		case <-time.After(e.interval):
+		}
+	}
+}
+
+// Gives up leadership so another instance can take over right away instead of waiting for our lease to run out.
+func (e *Elector) Resign() error {
+	e.mu.Lock()
+	if e.status != Leading {
+		e.mu.Unlock()
+		return nil
+	}
+	close(e.stop)
+	e.stepDown(e.lost)
+	e.mu.Unlock()
+
+	// Only remove the key if it's still ours, someone else may have taken over since we read it.
+	leader, version, err := e.storage.ReadVersion(e.key)
+	if err != nil || leader != e.id {
+		return err
+	}
+
+	err = e.storage.DeleteIf(e.key, version)
+	if err == persistence.ErrVersionMismatch {
+		return nil
+	}
+
+	return err
+}
+
+// Gets the ID of the current leader, which is empty if there isn't one.
+func (e *Elector) Leader() (string, error) {
+	return e.storage.Read(e.key)
+}
+
+// Sends the leader's ID every time it changes until the context is done.
+// The current leader is sent first, an empty ID means there's no leader.
+func (e *Elector) Observe(ctx context.Context) <-chan string {
+	leaders := make(chan string)
+	go func() {
+		defer close(leaders)
+
+		last := ""
+		first := true
+		for {
+			leader, err := e.Leader()
+			if err == nil && (first || leader != last) {
+				select {
+				case leaders <- leader:
+				case <-ctx.Done():
+					return
+				}
+				last, first = leader, false
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(e.interval):
+			}
+		}
+	}()
+
+	return leaders
+}
+
+// Gets where we currently are in the election.
+func (e *Elector) Status() Status {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.status
+}
+
+func (e *Elector) lead(lease int64) <-chan struct{} {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.status = Leading
+	e.stop = make(chan struct{})
+	e.lost = make(chan struct{})
+	go e.keepAlive(lease, e.stop, e.lost)
+
+	return e.lost
+}
+
+// Refreshes our lease well within its TTL, leadership is lost as soon as a refresh fails.
+func (e *Elector) keepAlive(lease int64, stop, lost chan struct{}) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := e.storage.RefreshLease(lease); err != nil {
+				e.mu.Lock()
+				e.stepDown(lost)
+				e.mu.Unlock()
+				return
+			}
+		}
+	}
+}
+
+// Must be called with the lock held.
+// Leadership that was already lost, or belongs to a later term, is left alone.
+func (e *Elector) stepDown(lost chan struct{}) {
+	if e.status != Leading || e.lost != lost {
+		return
+	}
+
+	e.status = Listening
+	close(lost)
+}
+
+func (e *Elector) setStatus(status Status) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.status = status
+}
@@ -0,0 +1,283 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ha
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/verizonlabs/mesos-framework-sdk/persistence"
+)
+
+// Keeps leased keys in memory, expiring a lease removes its key.
+type leaseStorage struct {
+	data     map[string]string
+	versions map[string]int64
+	leases   map[int64]string
+	lease    int64
+	version  int64
+	sync.Mutex
+}
+
+func newLeaseStorage() *leaseStorage {
+	return &leaseStorage{data: make(map[string]string), versions: make(map[string]int64), leases: make(map[int64]string)}
+}
+
+// Must be called with the lock held.
+func (l *leaseStorage) put(key, value string) {
+	l.version++
+	l.data[key] = value
+	l.versions[key] = l.version
+}
+
+func (l *leaseStorage) remove(key string) {
+	delete(l.data, key)
+	delete(l.versions, key)
+}
+
+func (l *leaseStorage) Create(key, value string) error {
+	_, err := l.CreateWithLease(key, value, 0)
+	return err
+}
+
+func (l *leaseStorage) CreateWithLease(key, value string, ttl int64) (int64, error) {
+	l.Lock()
+	defer l.Unlock()
+	if _, ok := l.data[key]; ok {
+		return -1, errors.New("exists")
+	}
+	l.lease++
+	l.put(key, value)
+	l.leases[l.lease] = key
+	return l.lease, nil
+}
+
+func (l *leaseStorage) Read(key string) (string, error) {
+	l.Lock()
+	defer l.Unlock()
+	return l.data[key], nil
+}
+
+func (l *leaseStorage) ReadVersion(key string) (string, int64, error) {
+	l.Lock()
+	defer l.Unlock()
+	return l.data[key], l.versions[key], nil
+}
+
+func (l *leaseStorage) ReadAll(key string) (map[string]string, error) {
+	return nil, nil
+}
+
+func (l *leaseStorage) Update(key, value string) error {
+	l.Lock()
+	defer l.Unlock()
+	l.put(key, value)
+	return nil
+}
+
+func (l *leaseStorage) UpdateIf(key, value string, expectedVersion int64) error {
+	l.Lock()
+	defer l.Unlock()
+	if l.versions[key] != expectedVersion {
+		return persistence.ErrVersionMismatch
+	}
+	l.put(key, value)
+	return nil
+}
+
+func (l *leaseStorage) RefreshLease(id int64) error {
+	l.Lock()
+	defer l.Unlock()
+	if _, ok := l.leases[id]; !ok {
+		return errors.New("expired")
+	}
+	return nil
+}
+
+func (l *leaseStorage) Delete(key string) error {
+	l.Lock()
+	defer l.Unlock()
+	l.remove(key)
+	return nil
+}
+
+func (l *leaseStorage) DeleteIf(key string, expectedVersion int64) error {
+	l.Lock()
+	defer l.Unlock()
+	if l.versions[key] != expectedVersion {
+		return persistence.ErrVersionMismatch
+	}
+	l.remove(key)
+	return nil
+}
+
+func (l *leaseStorage) expire(id int64) {
+	l.Lock()
+	defer l.Unlock()
+	l.remove(l.leases[id])
+	delete(l.leases, id)
+}
+
+func newTestElector(storage persistence.Storage, id string) *Elector {
+	e := NewElector(storage, "/leader", id, 1)
+	e.interval = 10 * time.Millisecond
+	return e
+}
+
+// Ensures only one instance leads and another takes over once the leader's lease expires.
+func TestElector_Campaign(t *testing.T) {
+	t.Parallel()
+
+	storage := newLeaseStorage()
+	first := newTestElector(storage, "first")
+	second := newTestElector(storage, "second")
+
+	lost, err := first.Campaign(context.Background())
+	if err != nil || first.Status() != Leading {
+		t.Fatal("The first instance should lead the election")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := second.Campaign(ctx); err == nil || second.Status() != Listening {
+		t.Fatal("The second instance should not lead while the first one holds the lease")
+	}
+
+	storage.expire(1)
+	select {
+	case <-lost:
+	case <-time.After(time.Second):
+		t.Fatal("Leadership should be lost once the lease expires")
+	}
+	if first.Status() != Listening {
+		t.Fatal("The first instance should stop leading after losing its lease")
+	}
+
+	if _, err := second.Campaign(context.Background()); err != nil {
+		t.Fatal(err.Error())
+	}
+	if leader, _ := first.Leader(); leader != "second" {
+		t.Fatal("The second instance should take over, got " + leader)
+	}
+}
+
+// Measures how long it takes to win an uncontested election.
+func BenchmarkElector_Campaign(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		e := newTestElector(newLeaseStorage(), "first")
+		e.Campaign(context.Background())
+		e.Resign()
+	}
+}
+
+// Ensures resigning frees the key and observers see every change in leadership.
+func TestElector_ResignObserve(t *testing.T) {
+	t.Parallel()
+
+	storage := newLeaseStorage()
+	first := newTestElector(storage, "first")
+	second := newTestElector(storage, "second")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	leaders := second.Observe(ctx)
+	if leader := <-leaders; leader != "" {
+		t.Fatal("There should be no leader before anyone campaigns")
+	}
+
+	lost, _ := first.Campaign(context.Background())
+	if leader := <-leaders; leader != "first" {
+		t.Fatal("Observers should see the new leader, got " + leader)
+	}
+
+	if err := first.Resign(); err != nil {
+		t.Fatal(err.Error())
+	}
+	select {
+	case <-lost:
+	default:
+		t.Fatal("Resigning should close the lost channel")
+	}
+	if leader := <-leaders; leader != "" {
+		t.Fatal("Observers should see the leader resign, got " + leader)
+	}
+
+	if err := second.Resign(); err != nil {
+		t.Fatal("Resigning without leading should do nothing")
+	}
+}
+
+// Measures how quickly leadership changes reach observers.
+func BenchmarkElector_ResignObserve(b *testing.B) {
+	storage := newLeaseStorage()
+	e := newTestElector(storage, "first")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	leaders := e.Observe(ctx)
+	<-leaders
+
+	for n := 0; n < b.N; n++ {
+		e.Campaign(context.Background())
+		<-leaders
+		e.Resign()
+		<-leaders
+	}
+}
+
+// Hands over the key to another instance right after it's read, as if they took over in between.
+type takeoverStorage struct {
+	*leaseStorage
+}
+
+func (t takeoverStorage) ReadVersion(key string) (string, int64, error) {
+	value, version, err := t.leaseStorage.ReadVersion(key)
+	t.Update(key, "second")
+	return value, version, err
+}
+
+// Ensures resigning only deletes the exact key and leaves it alone once someone else holds it.
+func TestElector_Resign(t *testing.T) {
+	t.Parallel()
+
+	storage := newLeaseStorage()
+	storage.Create("/leader2", "other")
+	first := newTestElector(storage, "first")
+	if _, err := first.Campaign(context.Background()); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := first.Resign(); err != nil {
+		t.Fatal(err.Error())
+	}
+	if leader, _ := storage.Read("/leader"); leader != "" {
+		t.Fatal("Resigning should remove our key")
+	}
+	if other, _ := storage.Read("/leader2"); other != "other" {
+		t.Fatal("Resigning should not remove keys our key prefixes")
+	}
+
+	first = newTestElector(takeoverStorage{storage}, "first")
+	if _, err := first.Campaign(context.Background()); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := first.Resign(); err != nil {
+		t.Fatal(err.Error())
+	}
+	if leader, _ := storage.Read("/leader"); leader != "second" {
+		t.Fatal("Resigning should not remove a key someone else has taken over, got " + leader)
+	}
+}
@@ -0,0 +1,399 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Operation and error codes understood by the mock.
+const (
+	opCreate      = 1
+	opDelete      = 2
+	opGetData     = 4
+	opSetData     = 5
+	opGetChildren = 8
+	opPing        = 11
+	opClose       = -11
+
+	errNoNode     = -101
+	errBadVersion = -103
+	errNodeExists = -110
+	errNotEmpty   = -111
+
+	flagEphemeral = 1
+)
+
+type node struct {
+	data    []byte
+	version int32
+	owner   int64
+}
+
+// In-memory ZooKeeper server speaking the wire protocol, with sessions and ephemeral znodes.
+// A notification is sent before every reply so clients have to skip packets that aren't theirs.
+type MockServer struct {
+	mu       sync.Mutex
+	listener net.Listener
+	nodes    map[string]*node
+	conns    map[net.Conn]int64
+	sessions map[int64]bool
+	session  int64
+	zxid     int64
+}
+
+// Starts a server on a random local port.
+func NewMockServer() (*MockServer, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	m := &MockServer{
+		listener: ln,
+		nodes:    make(map[string]*node),
+		conns:    make(map[net.Conn]int64),
+		sessions: make(map[int64]bool),
+	}
+	go m.accept()
+
+	return m, nil
+}
+
+func (m *MockServer) Addr() string {
+	return m.listener.Addr().String()
+}
+
+func (m *MockServer) Close() {
+	m.listener.Close()
+	m.Drop()
+}
+
+// Sets a znode's data, creating it and its parents as needed.
+func (m *MockServer) Set(p, data string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for dir := path.Dir(p); dir != "/"; dir = path.Dir(dir) {
+		if _, ok := m.nodes[dir]; !ok {
+			m.nodes[dir] = &node{}
+		}
+	}
+
+	if n, ok := m.nodes[p]; ok {
+		n.data = []byte(data)
+		n.version++
+		return
+	}
+	m.nodes[p] = &node{data: []byte(data)}
+}
+
+// Gets a znode's data.
+func (m *MockServer) Get(p string) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n, ok := m.nodes[p]
+	if !ok {
+		return "", false
+	}
+
+	return string(n.data), true
+}
+
+// Closes every client connection while keeping their sessions alive.
+func (m *MockServer) Drop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for conn := range m.conns {
+		conn.Close()
+	}
+}
+
+// Ends a session, removing its ephemeral znodes and refusing to resume it.
+func (m *MockServer) Expire(session int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.expire(session)
+	for conn, s := range m.conns {
+		if s == session {
+			conn.Close()
+		}
+	}
+}
+
+func (m *MockServer) expire(session int64) {
+	delete(m.sessions, session)
+	for p, n := range m.nodes {
+		if n.owner == session {
+			delete(m.nodes, p)
+		}
+	}
+}
+
+func (m *MockServer) accept() {
+	for {
+		conn, err := m.listener.Accept()
+		if err != nil {
+			return
+		}
+		go m.serve(conn)
+	}
+}
+
+func (m *MockServer) serve(conn net.Conn) {
+	defer func() {
+		m.mu.Lock()
+		delete(m.conns, conn)
+		m.mu.Unlock()
+		conn.Close()
+	}()
+
+	req := read(conn)
+	if len(req) < 24 {
+		return
+	}
+
+	// Protocol version, last zxid seen, session timeout and session ID, followed by the password.
+	timeout := int32(binary.BigEndian.Uint32(req[12:16]))
+	session := int64(binary.BigEndian.Uint64(req[16:24]))
+
+	m.mu.Lock()
+	if session == 0 {
+		m.session++
+		session = m.session
+		m.sessions[session] = true
+	} else if !m.sessions[session] {
+		timeout = 0
+	}
+	m.conns[conn] = session
+	m.mu.Unlock()
+
+	conn.Write(frame(appendBytes(appendInt64(appendInt32(appendInt32(nil, 0), timeout), session), make([]byte, 16))))
+	if timeout == 0 {
+		return
+	}
+
+	for {
+		req := read(conn)
+		if len(req) < 8 {
+			return
+		}
+
+		xid := int32(binary.BigEndian.Uint32(req[0:4]))
+		op := int32(binary.BigEndian.Uint32(req[4:8]))
+
+		m.mu.Lock()
+		code, body := m.handle(session, op, req[8:])
+		zxid := m.zxid
+		m.mu.Unlock()
+
+		conn.Write(frame(appendInt32(appendInt64(appendInt32(nil, -1), -1), 0)))
+		conn.Write(frame(append(appendInt32(appendInt64(appendInt32(nil, xid), zxid), code), body...)))
+
+		if op == opClose {
+			return
+		}
+	}
+}
+
+// Applies a request, returning the error code and body of the reply.
+func (m *MockServer) handle(session int64, op int32, req []byte) (int32, []byte) {
+	r := &reader{b: req}
+
+	switch op {
+	case opPing:
+		return 0, nil
+	case opClose:
+		m.expire(session)
+		return 0, nil
+	case opCreate:
+		p, data := r.string(), r.bytes()
+		for acls := r.int32(); acls > 0; acls-- {
+			r.int32()
+			r.string()
+			r.string()
+		}
+		flags := r.int32()
+
+		if _, ok := m.nodes[p]; ok {
+			return errNodeExists, nil
+		}
+		if _, ok := m.nodes[path.Dir(p)]; !ok && path.Dir(p) != "/" {
+			return errNoNode, nil
+		}
+
+		n := &node{data: data}
+		if flags&flagEphemeral != 0 {
+			n.owner = session
+		}
+		m.nodes[p] = n
+		m.zxid++
+
+		return 0, appendBytes(nil, []byte(p))
+	case opDelete:
+		p, version := r.string(), r.int32()
+		n, ok := m.nodes[p]
+		if !ok {
+			return errNoNode, nil
+		}
+		if version != -1 && version != n.version {
+			return errBadVersion, nil
+		}
+		if len(m.children(p)) > 0 {
+			return errNotEmpty, nil
+		}
+		delete(m.nodes, p)
+		m.zxid++
+
+		return 0, nil
+	case opGetData:
+		p := r.string()
+		n, ok := m.nodes[p]
+		if !ok {
+			return errNoNode, nil
+		}
+
+		return 0, m.stat(appendBytes(nil, n.data), p, n)
+	case opSetData:
+		p, data, version := r.string(), r.bytes(), r.int32()
+		n, ok := m.nodes[p]
+		if !ok {
+			return errNoNode, nil
+		}
+		if version != -1 && version != n.version {
+			return errBadVersion, nil
+		}
+		n.data = data
+		n.version++
+		m.zxid++
+
+		return 0, m.stat(nil, p, n)
+	case opGetChildren:
+		p := r.string()
+		if _, ok := m.nodes[p]; !ok && p != "/" {
+			return errNoNode, nil
+		}
+
+		children := m.children(p)
+		body := appendInt32(nil, int32(len(children)))
+		for _, child := range children {
+			body = appendBytes(body, []byte(child))
+		}
+
+		return 0, body
+	}
+
+	return -1, nil
+}
+
+// Names of the znodes directly under a path.
+func (m *MockServer) children(p string) []string {
+	prefix := strings.TrimSuffix(p, "/") + "/"
+
+	var children []string
+	for child := range m.nodes {
+		if strings.HasPrefix(child, prefix) && !strings.Contains(strings.TrimPrefix(child, prefix), "/") {
+			children = append(children, strings.TrimPrefix(child, prefix))
+		}
+	}
+	sort.Strings(children)
+
+	return children
+}
+
+func (m *MockServer) stat(b []byte, p string, n *node) []byte {
+	for i := 0; i < 4; i++ {
+		b = appendInt64(b, 0)
+	}
+	b = appendInt32(b, n.version)
+	b = appendInt32(appendInt32(b, 0), 0)
+	b = appendInt64(b, n.owner)
+	b = appendInt32(b, int32(len(n.data)))
+	b = appendInt32(b, int32(len(m.children(p))))
+
+	return appendInt64(b, 0)
+}
+
+// Reads through a request, leaving zero values once it runs out.
+type reader struct {
+	b []byte
+}
+
+func (r *reader) int32() int32 {
+	if len(r.b) < 4 {
+		r.b = nil
+		return 0
+	}
+
+	v := int32(binary.BigEndian.Uint32(r.b))
+	r.b = r.b[4:]
+
+	return v
+}
+
+func (r *reader) bytes() []byte {
+	length := r.int32()
+	if length < 0 || int(length) > len(r.b) {
+		return nil
+	}
+
+	v := append([]byte(nil), r.b[:length]...)
+	r.b = r.b[length:]
+
+	return v
+}
+
+func (r *reader) string() string {
+	return string(r.bytes())
+}
+
+func read(conn net.Conn) []byte {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return nil
+	}
+
+	packet := make([]byte, binary.BigEndian.Uint32(header))
+	if _, err := io.ReadFull(conn, packet); err != nil {
+		return nil
+	}
+
+	return packet
+}
+
+func frame(packet []byte) []byte {
+	return append(appendInt32(nil, int32(len(packet))), packet...)
+}
+
+func appendInt32(b []byte, v int32) []byte {
+	return append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+func appendInt64(b []byte, v int64) []byte {
+	return appendInt32(appendInt32(b, int32(v>>32)), int32(v))
+}
+
+func appendBytes(b, v []byte) []byte {
+	return append(appendInt32(b, int32(len(v))), v...)
+}
@@ -0,0 +1,514 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package zk is a minimal ZooKeeper client shared by master detection and the ZooKeeper persistence driver.
+// It speaks just enough of the wire protocol for reading and writing znodes without any watches.
+package zk
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Operation codes we rely on.
+const (
+	OP_CREATE       = 1
+	OP_DELETE       = 2
+	OP_GET_DATA     = 4
+	OP_SET_DATA     = 5
+	OP_GET_CHILDREN = 8
+	OP_PING         = 11
+	OP_CLOSE        = -11
+	PING_XID        = -2
+)
+
+const (
+	FLAG_EPHEMERAL = 1
+	PERM_ALL       = 31
+	MAX_PACKET     = 1 << 20
+)
+
+var (
+	ErrNoNode         = errors.New("ZooKeeper node does not exist.")
+	ErrNodeExists     = errors.New("ZooKeeper node already exists.")
+	ErrBadVersion     = errors.New("ZooKeeper node version does not match.")
+	ErrNotEmpty       = errors.New("ZooKeeper node has children.")
+	ErrSessionExpired = errors.New("ZooKeeper session has expired.")
+	ErrClosed         = errors.New("ZooKeeper connection is closed.")
+)
+
+// Error codes sent back by servers.
+var codes = map[int32]error{
+	-101: ErrNoNode,
+	-103: ErrBadVersion,
+	-110: ErrNodeExists,
+	-111: ErrNotEmpty,
+	-112: ErrSessionExpired,
+}
+
+type ACL struct {
+	Perms  int32
+	Scheme string
+	ID     string
+}
+
+// Gives everyone the permissions.
+func WorldACL(perms int32) []ACL {
+	return []ACL{{Perms: perms, Scheme: "world", ID: "anyone"}}
+}
+
+// Metadata of a znode.
+type Stat struct {
+	Czxid          int64
+	Mzxid          int64
+	Ctime          int64
+	Mtime          int64
+	Version        int32
+	Cversion       int32
+	Aversion       int32
+	EphemeralOwner int64
+	DataLength     int32
+	NumChildren    int32
+	Pzxid          int64
+}
+
+// Session with a ZooKeeper ensemble.
+// Requests are serialized over a single connection, which is re-established against the next server when it breaks.
+// The session is kept alive with pings so ephemeral znodes last until it's closed.
+type Conn struct {
+	mu        sync.Mutex
+	servers   []string
+	next      int
+	timeout   time.Duration
+	conn      net.Conn
+	reader    *bufio.Reader
+	xid       int32
+	zxid      int64
+	sessionID int64
+	password  []byte
+	done      chan struct{}
+	closed    bool
+}
+
+// Opens a session against the first server that accepts it.
+func Connect(servers []string, timeout time.Duration) (*Conn, error) {
+	if len(servers) == 0 {
+		return nil, errors.New("At least one ZooKeeper server is required.")
+	}
+	if timeout <= 0 {
+		return nil, errors.New("The ZooKeeper session timeout must be positive.")
+	}
+
+	c := &Conn{
+		servers:  servers,
+		timeout:  timeout,
+		password: make([]byte, 16),
+		done:     make(chan struct{}),
+	}
+	if err := c.connect(); err != nil {
+		return nil, err
+	}
+	go c.keepalive()
+
+	return c, nil
+}
+
+// Gets the ID of the current session, which changes if the session expired and had to be replaced.
+func (c *Conn) SessionID() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.sessionID
+}
+
+// Ends the session, removing its ephemeral znodes.
+func (c *Conn) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+	c.closed = true
+	close(c.done)
+
+	if c.conn != nil {
+		c.write(appendInt32(appendInt32(nil, c.nextXid()), OP_CLOSE))
+		c.conn.Close()
+		c.conn = nil
+	}
+}
+
+// Creates a znode and returns its path.
+func (c *Conn) Create(path string, data []byte, flags int32, acl []ACL) (string, error) {
+	body := appendBytes(appendString(nil, path), data)
+	body = appendInt32(body, int32(len(acl)))
+	for _, a := range acl {
+		body = appendString(appendString(appendInt32(body, a.Perms), a.Scheme), a.ID)
+	}
+
+	resp, err := c.request(OP_CREATE, appendInt32(body, flags))
+	if err != nil {
+		return "", err
+	}
+
+	created, _, err := readBytes(resp)
+	return string(created), err
+}
+
+// Deletes a znode if it's at the given version, -1 matches any version.
+func (c *Conn) Delete(path string, version int32) error {
+	_, err := c.request(OP_DELETE, appendInt32(appendString(nil, path), version))
+
+	return err
+}
+
+// Gets the data held by a znode.
+func (c *Conn) Get(path string) ([]byte, *Stat, error) {
+	resp, err := c.request(OP_GET_DATA, appendBool(appendString(nil, path), false))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, resp, err := readBytes(resp)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stat, err := readStat(resp)
+	return data, stat, err
+}
+
+// Sets the data of a znode if it's at the given version, -1 matches any version.
+func (c *Conn) Set(path string, data []byte, version int32) (*Stat, error) {
+	resp, err := c.request(OP_SET_DATA, appendInt32(appendBytes(appendString(nil, path), data), version))
+	if err != nil {
+		return nil, err
+	}
+
+	return readStat(resp)
+}
+
+// Lists the children of a znode.
+func (c *Conn) Children(path string) ([]string, error) {
+	resp, err := c.request(OP_GET_CHILDREN, appendBool(appendString(nil, path), false))
+	if err != nil {
+		return nil, err
+	}
+
+	count, resp, err := readInt32(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make([]string, 0, count)
+	for n := int32(0); n < count; n++ {
+		var child []byte
+		child, resp, err = readBytes(resp)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, string(child))
+	}
+
+	return children, nil
+}
+
+// Pings often enough that the server never times the session out, reconnecting when the connection drops.
+func (c *Conn) keepalive() {
+	ticker := time.NewTicker(c.timeout / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			c.mu.Lock()
+			if !c.closed {
+				c.roundTrip(PING_XID, OP_PING, nil)
+			}
+			c.mu.Unlock()
+		}
+	}
+}
+
+func (c *Conn) request(op int32, body []byte) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil, ErrClosed
+	}
+
+	return c.roundTrip(c.nextXid(), op, body)
+}
+
+// Sends a request and returns the body of its reply, the caller must hold the lock.
+func (c *Conn) roundTrip(xid, op int32, body []byte) ([]byte, error) {
+	if c.conn == nil {
+		if err := c.connect(); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := c.exchange(xid, op, body)
+	if err != nil && err != ErrNoNode && err != ErrNodeExists && err != ErrBadVersion && err != ErrNotEmpty {
+
+		// Start over on the next request since we can't tell where the stream is.
+		c.conn.Close()
+		c.conn = nil
+	}
+
+	return resp, err
+}
+
+func (c *Conn) exchange(xid, op int32, body []byte) ([]byte, error) {
+	req := appendInt32(appendInt32(make([]byte, 0, 8+len(body)), xid), op)
+	if err := c.write(append(req, body...)); err != nil {
+		return nil, err
+	}
+
+	for {
+		resp, err := c.read()
+		if err != nil {
+			return nil, err
+		}
+
+		// Reply header is the xid, the zxid and an error code.
+		if len(resp) < 16 {
+			return nil, errors.New("ZooKeeper sent a truncated reply.")
+		}
+
+		// Skip anything that isn't our reply, such as pings and notifications.
+		if int32(binary.BigEndian.Uint32(resp[0:4])) != xid {
+			continue
+		}
+
+		if zxid := int64(binary.BigEndian.Uint64(resp[4:12])); zxid > 0 {
+			c.zxid = zxid
+		}
+
+		code := int32(binary.BigEndian.Uint32(resp[12:16]))
+		if code == 0 {
+			return resp[16:], nil
+		}
+		if err, ok := codes[code]; ok {
+			return nil, err
+		}
+
+		return nil, errors.New("ZooKeeper error " + strconv.Itoa(int(code)) + ".")
+	}
+}
+
+// Connects to the servers in turn, resuming our session if we have one.
+// An expired session is replaced with a new one, so its ephemeral znodes are gone.
+func (c *Conn) connect() error {
+	var err error
+	for range c.servers {
+		server := c.servers[c.next]
+		c.next = (c.next + 1) % len(c.servers)
+
+		err = c.handshake(server)
+		if err == ErrSessionExpired {
+			c.sessionID = 0
+			c.password = make([]byte, 16)
+			err = c.handshake(server)
+		}
+		if err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
+
+func (c *Conn) handshake(server string) error {
+	conn, err := net.DialTimeout("tcp", server, c.timeout)
+	if err != nil {
+		return err
+	}
+	c.conn = conn
+	c.reader = bufio.NewReader(conn)
+
+	// Protocol version, last zxid seen, session timeout, session ID and password.
+	req := make([]byte, 0, 44)
+	req = appendInt32(req, 0)
+	req = appendInt64(req, c.zxid)
+	req = appendInt32(req, int32(c.timeout/time.Millisecond))
+	req = appendInt64(req, c.sessionID)
+	req = appendBytes(req, c.password)
+
+	if err := c.session(req); err != nil {
+		conn.Close()
+		c.conn = nil
+
+		return err
+	}
+
+	return nil
+}
+
+func (c *Conn) session(req []byte) error {
+	if err := c.write(req); err != nil {
+		return err
+	}
+
+	resp, err := c.read()
+	if err != nil {
+		return err
+	}
+
+	// Protocol version, negotiated timeout, session ID and password, a timeout of 0 means the session expired.
+	_, resp, err = readInt32(resp)
+	if err != nil {
+		return err
+	}
+	timeout, resp, err := readInt32(resp)
+	if err != nil {
+		return err
+	}
+	if timeout <= 0 {
+		return ErrSessionExpired
+	}
+	if len(resp) < 8 {
+		return errors.New("ZooKeeper sent a truncated reply.")
+	}
+	sessionID := int64(binary.BigEndian.Uint64(resp[0:8]))
+	password, _, err := readBytes(resp[8:])
+	if err != nil {
+		return err
+	}
+
+	c.sessionID = sessionID
+	c.password = append([]byte(nil), password...)
+
+	return nil
+}
+
+func (c *Conn) nextXid() int32 {
+	c.xid++
+	if c.xid <= 0 {
+		c.xid = 1
+	}
+
+	return c.xid
+}
+
+// Writes a length prefixed packet.
+func (c *Conn) write(packet []byte) error {
+	c.conn.SetDeadline(time.Now().Add(c.timeout))
+	_, err := c.conn.Write(append(appendInt32(make([]byte, 0, 4+len(packet)), int32(len(packet))), packet...))
+
+	return err
+}
+
+// Reads a length prefixed packet.
+func (c *Conn) read() ([]byte, error) {
+	c.conn.SetDeadline(time.Now().Add(c.timeout))
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(c.reader, header); err != nil {
+		return nil, err
+	}
+
+	length := int32(binary.BigEndian.Uint32(header))
+	if length < 0 || length > MAX_PACKET {
+		return nil, errors.New("ZooKeeper sent an invalid packet length.")
+	}
+
+	packet := make([]byte, length)
+	_, err := io.ReadFull(c.reader, packet)
+
+	return packet, err
+}
+
+func readStat(b []byte) (*Stat, error) {
+	if len(b) < 68 {
+		return nil, errors.New("ZooKeeper sent a truncated reply.")
+	}
+
+	return &Stat{
+		Czxid:          int64(binary.BigEndian.Uint64(b[0:8])),
+		Mzxid:          int64(binary.BigEndian.Uint64(b[8:16])),
+		Ctime:          int64(binary.BigEndian.Uint64(b[16:24])),
+		Mtime:          int64(binary.BigEndian.Uint64(b[24:32])),
+		Version:        int32(binary.BigEndian.Uint32(b[32:36])),
+		Cversion:       int32(binary.BigEndian.Uint32(b[36:40])),
+		Aversion:       int32(binary.BigEndian.Uint32(b[40:44])),
+		EphemeralOwner: int64(binary.BigEndian.Uint64(b[44:52])),
+		DataLength:     int32(binary.BigEndian.Uint32(b[52:56])),
+		NumChildren:    int32(binary.BigEndian.Uint32(b[56:60])),
+		Pzxid:          int64(binary.BigEndian.Uint64(b[60:68])),
+	}, nil
+}
+
+func appendInt32(b []byte, v int32) []byte {
+	return append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+func appendInt64(b []byte, v int64) []byte {
+	return appendInt32(appendInt32(b, int32(v>>32)), int32(v))
+}
+
+func appendBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, 1)
+	}
+
+	return append(b, 0)
+}
+
+// Appends a length prefixed buffer, where nil is sent as a length of -1.
+func appendBytes(b, v []byte) []byte {
+	if v == nil {
+		return appendInt32(b, -1)
+	}
+
+	return append(appendInt32(b, int32(len(v))), v...)
+}
+
+func appendString(b []byte, v string) []byte {
+	return append(appendInt32(b, int32(len(v))), v...)
+}
+
+func readInt32(b []byte) (int32, []byte, error) {
+	if len(b) < 4 {
+		return 0, nil, errors.New("ZooKeeper sent a truncated reply.")
+	}
+
+	return int32(binary.BigEndian.Uint32(b)), b[4:], nil
+}
+
+// Reads a length prefixed buffer, where a negative length means it's empty.
+func readBytes(b []byte) ([]byte, []byte, error) {
+	length, b, err := readInt32(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	if length < 0 {
+		return nil, b, nil
+	}
+	if int(length) > len(b) {
+		return nil, nil, errors.New("ZooKeeper sent a truncated reply.")
+	}
+
+	return b[:length], b[length:], nil
+}
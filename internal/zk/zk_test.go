@@ -0,0 +1,163 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zk
+
+import (
+	"github.com/verizonlabs/mesos-framework-sdk/internal/zk/test"
+	"testing"
+	"time"
+)
+
+func connect(t *testing.T) (*test.MockServer, *Conn) {
+	server, err := test.NewMockServer()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	c, err := Connect([]string{"127.0.0.1:1", server.Addr()}, time.Second)
+	if err != nil {
+		t.Fatal("Could not connect to the second server: " + err.Error())
+	}
+
+	return server, c
+}
+
+// Ensures znodes can be created, read, updated and deleted.
+func TestConn(t *testing.T) {
+	t.Parallel()
+
+	server, c := connect(t)
+	defer server.Close()
+	defer c.Close()
+
+	if _, err := c.Create("/a/b", []byte("b"), 0, WorldACL(PERM_ALL)); err != ErrNoNode {
+		t.Fatal("Creating a znode without its parent should fail")
+	}
+	if _, err := c.Create("/a", nil, 0, WorldACL(PERM_ALL)); err != nil {
+		t.Fatal(err.Error())
+	}
+	if p, err := c.Create("/a/b", []byte("b"), 0, WorldACL(PERM_ALL)); err != nil || p != "/a/b" {
+		t.Fatal("Znode should have been created")
+	}
+	if _, err := c.Create("/a/b", []byte("b"), 0, WorldACL(PERM_ALL)); err != ErrNodeExists {
+		t.Fatal("Creating an existing znode should fail")
+	}
+
+	data, stat, err := c.Get("/a/b")
+	if err != nil || string(data) != "b" || stat.Version != 0 {
+		t.Fatal("Znode data or version is wrong")
+	}
+
+	if stat, err = c.Set("/a/b", []byte("c"), 0); err != nil || stat.Version != 1 {
+		t.Fatal("Znode should have been updated to version 1")
+	}
+	if _, err = c.Set("/a/b", []byte("d"), 0); err != ErrBadVersion {
+		t.Fatal("Updating a stale version should fail")
+	}
+
+	children, err := c.Children("/a")
+	if err != nil || len(children) != 1 || children[0] != "b" {
+		t.Fatal("Znode children are wrong")
+	}
+
+	if err := c.Delete("/a", -1); err != ErrNotEmpty {
+		t.Fatal("Deleting a znode with children should fail")
+	}
+	if err := c.Delete("/a/b", 1); err != nil {
+		t.Fatal(err.Error())
+	}
+	if _, _, err := c.Get("/a/b"); err != ErrNoNode {
+		t.Fatal("Deleted znode should be gone")
+	}
+
+	if _, err := Connect(nil, time.Second); err == nil {
+		t.Fatal("Connecting without servers should fail")
+	}
+	if _, err := Connect([]string{"127.0.0.1:1"}, time.Second); err == nil {
+		t.Fatal("Connecting to a server that isn't there should fail")
+	}
+}
+
+// Measures performance of reading a znode.
+func BenchmarkConn_Get(b *testing.B) {
+	server, c := connect(&testing.T{})
+	defer server.Close()
+	defer c.Close()
+	server.Set("/a", "a")
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		c.Get("/a")
+	}
+}
+
+// Ensures sessions survive dropped connections and are replaced once they expire.
+func TestConn_Reconnect(t *testing.T) {
+	t.Parallel()
+
+	server, c := connect(t)
+	defer server.Close()
+	defer c.Close()
+
+	session := c.SessionID()
+	if _, err := c.Create("/ephemeral", nil, FLAG_EPHEMERAL, WorldACL(PERM_ALL)); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	server.Drop()
+	c.Get("/ephemeral")
+	if _, _, err := c.Get("/ephemeral"); err != nil {
+		t.Fatal("Ephemeral znode should outlive the connection: " + err.Error())
+	}
+	if c.SessionID() != session {
+		t.Fatal("The session should have been resumed")
+	}
+
+	server.Expire(session)
+	c.Get("/ephemeral")
+	if _, _, err := c.Get("/ephemeral"); err != ErrNoNode {
+		t.Fatal("Ephemeral znode should be gone with its session")
+	}
+	if c.SessionID() == session {
+		t.Fatal("An expired session should have been replaced")
+	}
+}
+
+// Ensures closing the session removes its ephemeral znodes.
+func TestConn_Close(t *testing.T) {
+	t.Parallel()
+
+	server, c := connect(t)
+	defer server.Close()
+
+	if _, err := c.Create("/ephemeral", nil, FLAG_EPHEMERAL, WorldACL(PERM_ALL)); err != nil {
+		t.Fatal(err.Error())
+	}
+	c.Close()
+	c.Close()
+
+	if _, _, err := c.Get("/ephemeral"); err != ErrClosed {
+		t.Fatal("Requests should fail once the session is closed")
+	}
+
+	// The server handles the close asynchronously.
+	for i := 0; i < 100; i++ {
+		if _, ok := server.Get("/ephemeral"); !ok {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Ephemeral znode should be gone once the session is closed")
+}
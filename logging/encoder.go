@@ -0,0 +1,100 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Turns log entries into the bytes written out, including the trailing newline.
+type Encoder interface {
+	Encode(entry *Entry) ([]byte, error)
+}
+
+// Writes each entry as a JSON object on its own line.
+// Fields sit next to the time, level, caller and msg keys, which win if a field uses the same name.
+type JSONEncoder struct{}
+
+func (JSONEncoder) Encode(entry *Entry) ([]byte, error) {
+	object := make(map[string]interface{}, len(entry.Fields)+4)
+	for k, v := range entry.Fields {
+
+		// Errors have no exported fields and would come out as empty objects.
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		object[k] = v
+	}
+	object["time"] = entry.Time.Format(timestamp)
+	object["level"] = entry.Level.String()
+	object["caller"] = entry.Caller
+	object["msg"] = entry.Message
+
+	data, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
+}
+
+// Writes each entry as a line meant for people, with fields as sorted key=value pairs after the message.
+type ConsoleEncoder struct{}
+
+func (ConsoleEncoder) Encode(entry *Entry) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(entry.Time.Format(timestamp))
+	buf.WriteByte(' ')
+	buf.WriteString(fmt.Sprintf("%-5s", entry.Level.String()))
+	buf.WriteByte(' ')
+	buf.WriteString(entry.Caller)
+	buf.WriteByte(' ')
+	buf.WriteString(entry.Message)
+	if len(entry.Fields) > 0 {
+		buf.WriteByte(' ')
+		buf.Write(encodeFields(entry.Fields))
+	}
+	buf.WriteByte('\n')
+
+	return buf.Bytes(), nil
+}
+
+func encodeFields(fields Fields) []byte {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	for i, k := range keys {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+
+		value := fmt.Sprint(fields[k])
+		if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+			value = strconv.Quote(value)
+		}
+		buf.WriteString(k + "=" + value)
+	}
+
+	return buf.Bytes()
+}
@@ -14,7 +14,15 @@
 
 package logging
 
-import "testing"
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
 
 // Ensure we can use our default logger.
 func TestNewDefaultLogger(t *testing.T) {
@@ -52,3 +60,74 @@ func BenchmarkDefaultLogger_Emit(b *testing.B) {
 		l.Emit(TEST, "TEST %s", "VALUE")
 	}
 }
+
+// Ensures structured entries are filtered by level and carry their fields.
+func TestStructuredLogger(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	l := NewStructuredLogger(LEVEL_INFO, JSONEncoder{}, &buf)
+	l.Debug("dropped")
+	l.With(Fields{"task": "web-0", "err": errors.New("boom")}).Warn("task %s", "failed")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatal("Expected a single JSON entry: " + err.Error())
+	}
+	if entry["msg"] != "task failed" || entry["level"] != "WARN" || entry["task"] != "web-0" || entry["err"] != "boom" {
+		t.Fatalf("Unexpected entry %v", entry)
+	}
+	if caller, _ := entry["caller"].(string); !strings.HasPrefix(caller, "logging_test.go:") {
+		t.Fatal("The caller should be the line that logged, got " + caller)
+	}
+
+	buf.Reset()
+	l = NewStructuredLogger(LEVEL_DEBUG, ConsoleEncoder{}, &buf)
+	l.With(Fields{"b": "two words", "a": 1}).Emit(STAT, "offers %d", 3)
+	line := buf.String()
+	if !strings.Contains(line, "INFO  logging_test.go:") || !strings.HasSuffix(line, `offers 3 a=1 b="two words" severity=STAT`+"\n") {
+		t.Fatal("Unexpected console line " + line)
+	}
+}
+
+// Measures encoding and writing structured entries.
+func BenchmarkStructuredLogger(b *testing.B) {
+	l := NewStructuredLogger(LEVEL_INFO, JSONEncoder{}, ioutil.Discard).With(Fields{"task": "web-0"})
+	for n := 0; n < b.N; n++ {
+		l.Info("task %s", "running")
+	}
+}
+
+type recordingLogger struct {
+	severity uint8
+	message  string
+}
+
+func (r *recordingLogger) Emit(severity uint8, template string, args ...interface{}) {
+	r.severity = severity
+	r.message = fmt.Sprintf(template, args...)
+}
+
+// Ensures old loggers can be used where structured ones are expected.
+func TestStructured(t *testing.T) {
+	t.Parallel()
+
+	r := &recordingLogger{}
+	Structured(r).With(Fields{"task": "web-0"}).Error("100%% failed")
+	if r.severity != ERROR || r.message != "100% failed task=web-0" {
+		t.Fatal("Unexpected message " + r.message)
+	}
+
+	l := NewStructuredLogger(LEVEL_INFO, JSONEncoder{})
+	if Structured(l) != l {
+		t.Fatal("Structured loggers should be used as they are")
+	}
+}
+
+// Measures logging through the shim.
+func BenchmarkStructured(b *testing.B) {
+	l := Structured(&recordingLogger{}).With(Fields{"task": "web-0"})
+	for n := 0; n < b.N; n++ {
+		l.Info("task %s", "running")
+	}
+}
@@ -0,0 +1,261 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// How important a structured log entry is, entries below a logger's level are dropped.
+type Level uint8
+
+const (
+	LEVEL_DEBUG Level = iota
+	LEVEL_INFO
+	LEVEL_WARN
+	LEVEL_ERROR
+)
+
+func (l Level) String() string {
+	switch l {
+	case LEVEL_DEBUG:
+		return "DEBUG"
+	case LEVEL_INFO:
+		return "INFO"
+	case LEVEL_WARN:
+		return "WARN"
+	case LEVEL_ERROR:
+		return "ERROR"
+	}
+
+	return "UNKNOWN"
+}
+
+// Key/values attached to log entries.
+type Fields map[string]interface{}
+
+// Logs leveled messages with fields attached.
+// It's also a Logger, so it can be handed to anything that still emits severities.
+type StructuredLogger interface {
+	Logger
+	With(fields Fields) StructuredLogger
+	Debug(template string, args ...interface{})
+	Info(template string, args ...interface{})
+	Warn(template string, args ...interface{})
+	Error(template string, args ...interface{})
+}
+
+// A single log entry as it's handed to an encoder.
+type Entry struct {
+	Time    time.Time
+	Level   Level
+	Caller  string
+	Message string
+	Fields  Fields
+}
+
+// Shared by a logger and everything derived from it with With.
+type sink struct {
+	lock    sync.Mutex
+	level   Level
+	encoder Encoder
+	writers []io.Writer
+}
+
+type structuredLogger struct {
+	sink   *sink
+	fields Fields
+}
+
+// Creates a structured logger that writes entries at or above the level to every writer.
+// Stdout is used when no writers are given.
+func NewStructuredLogger(level Level, encoder Encoder, writers ...io.Writer) StructuredLogger {
+	if len(writers) == 0 {
+		writers = []io.Writer{os.Stdout}
+	}
+
+	return &structuredLogger{
+		sink:   &sink{level: level, encoder: encoder, writers: writers},
+		fields: Fields{},
+	}
+}
+
+// Creates a logger with the fields added to the ones it already has.
+func (l *structuredLogger) With(fields Fields) StructuredLogger {
+	merged := make(Fields, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+
+	return &structuredLogger{sink: l.sink, fields: merged}
+}
+
+func (l *structuredLogger) Debug(template string, args ...interface{}) {
+	l.log(LEVEL_DEBUG, template, args...)
+}
+
+func (l *structuredLogger) Info(template string, args ...interface{}) {
+	l.log(LEVEL_INFO, template, args...)
+}
+
+func (l *structuredLogger) Warn(template string, args ...interface{}) {
+	l.log(LEVEL_WARN, template, args...)
+}
+
+func (l *structuredLogger) Error(template string, args ...interface{}) {
+	l.log(LEVEL_ERROR, template, args...)
+}
+
+// Logs the old severities at the closest level, keeping the severity's name as a field.
+func (l *structuredLogger) Emit(severity uint8, template string, args ...interface{}) {
+	var level Level
+	switch severity {
+	case TEST:
+		return
+	case ALARM, ERROR:
+		level = LEVEL_ERROR
+	case DEBUG:
+		level = LEVEL_DEBUG
+	default:
+		level = LEVEL_INFO
+	}
+
+	entry := l.entry(3, level, template, args...)
+	if entry == nil {
+		return
+	}
+	if name, ok := severities[severity]; ok {
+		entry.Fields["severity"] = name
+	}
+	l.write(entry)
+}
+
+func (l *structuredLogger) log(level Level, template string, args ...interface{}) {
+	if entry := l.entry(4, level, template, args...); entry != nil {
+		l.write(entry)
+	}
+}
+
+// Builds the entry, nil means the level is filtered out.
+// Depth is handed to caller, so the entry reports the function that called Debug, Emit and so on.
+func (l *structuredLogger) entry(depth int, level Level, template string, args ...interface{}) *Entry {
+	if level < l.sink.level {
+		return nil
+	}
+
+	fields := make(Fields, len(l.fields)+1)
+	for k, v := range l.fields {
+		fields[k] = v
+	}
+
+	return &Entry{
+		Time:    time.Now().UTC(),
+		Level:   level,
+		Caller:  caller(depth),
+		Message: fmt.Sprintf(template, args...),
+		Fields:  fields,
+	}
+}
+
+func (l *structuredLogger) write(entry *Entry) {
+	data, err := l.sink.encoder.Encode(entry)
+	if err != nil {
+		data = []byte(fmt.Sprintf("Failed to encode log entry %q: %s\n", entry.Message, err.Error()))
+	}
+
+	l.sink.lock.Lock()
+	defer l.sink.lock.Unlock()
+
+	for _, w := range l.sink.writers {
+		w.Write(data)
+	}
+}
+
+// Wraps a logger that only emits severities so it can be used as a structured logger.
+// Fields are appended to each message.
+func Structured(logger Logger) StructuredLogger {
+	if s, ok := logger.(StructuredLogger); ok {
+		return s
+	}
+
+	return &shim{logger: logger}
+}
+
+type shim struct {
+	logger Logger
+	fields Fields
+}
+
+func (s *shim) With(fields Fields) StructuredLogger {
+	merged := make(Fields, len(s.fields)+len(fields))
+	for k, v := range s.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+
+	return &shim{logger: s.logger, fields: merged}
+}
+
+func (s *shim) Debug(template string, args ...interface{}) { s.emit(DEBUG, template, args...) }
+func (s *shim) Info(template string, args ...interface{})  { s.emit(INFO, template, args...) }
+func (s *shim) Warn(template string, args ...interface{})  { s.emit(ALARM, template, args...) }
+func (s *shim) Error(template string, args ...interface{}) { s.emit(ERROR, template, args...) }
+
+func (s *shim) Emit(severity uint8, template string, args ...interface{}) {
+	s.logger.Emit(severity, template, args...)
+}
+
+func (s *shim) emit(severity uint8, template string, args ...interface{}) {
+	message := fmt.Sprintf(template, args...)
+	if len(s.fields) > 0 {
+		message += " " + string(encodeFields(s.fields))
+	}
+
+	// Escape the message since it's already been formatted.
+	s.logger.Emit(severity, "%s", message)
+}
+
+var severities = map[uint8]string{
+	NOP:     "NOP",
+	ALARM:   "ALARM",
+	ERROR:   "ERROR",
+	STAT:    "STAT",
+	INFO:    "INFO",
+	EVENT:   "EVENT",
+	DEBUG:   "DEBUG",
+	UNKNOWN: "UNKNOWN",
+}
+
+// Gets the file and line of the function the given number of frames up the stack, 0 being this one.
+func caller(skip int) string {
+	_, file, line, ok := runtime.Caller(skip)
+	if !ok {
+		return "???:0"
+	}
+
+	return file[strings.LastIndex(file, "/")+1:] + ":" + strconv.Itoa(line)
+}
@@ -14,8 +14,26 @@
 
 package test
 
+import "github.com/verizonlabs/mesos-framework-sdk/logging"
+
 type MockLogger struct{}
 
 func (m MockLogger) Emit(severity uint8, template string, args ...interface{}) {
 
 }
+
+type MockStructuredLogger struct {
+	MockLogger
+}
+
+func (m MockStructuredLogger) With(fields logging.Fields) logging.StructuredLogger {
+	return m
+}
+
+func (m MockStructuredLogger) Debug(template string, args ...interface{}) {}
+
+func (m MockStructuredLogger) Info(template string, args ...interface{}) {}
+
+func (m MockStructuredLogger) Warn(template string, args ...interface{}) {}
+
+func (m MockStructuredLogger) Error(template string, args ...interface{}) {}
@@ -0,0 +1,262 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Client for the master's v1 operator API, used to query the cluster and drive maintenance.
+// Calls are sent as JSON since the operator protobufs aren't part of this SDK.
+package operator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// Operator call types we support.
+const (
+	GET_AGENTS                  = "GET_AGENTS"
+	GET_TASKS                   = "GET_TASKS"
+	GET_STATE                   = "GET_STATE"
+	RESERVE_RESOURCES           = "RESERVE_RESOURCES"
+	UNRESERVE_RESOURCES         = "UNRESERVE_RESOURCES"
+	GET_MAINTENANCE_SCHEDULE    = "GET_MAINTENANCE_SCHEDULE"
+	UPDATE_MAINTENANCE_SCHEDULE = "UPDATE_MAINTENANCE_SCHEDULE"
+	START_MAINTENANCE           = "START_MAINTENANCE"
+	STOP_MAINTENANCE            = "STOP_MAINTENANCE"
+)
+
+type Call struct {
+	Type                      string             `json:"type"`
+	ReserveResources          *Resources         `json:"reserve_resources,omitempty"`
+	UnreserveResources        *Resources         `json:"unreserve_resources,omitempty"`
+	UpdateMaintenanceSchedule *MaintenanceUpdate `json:"update_maintenance_schedule,omitempty"`
+	StartMaintenance          *Machines          `json:"start_maintenance,omitempty"`
+	StopMaintenance           *Machines          `json:"stop_maintenance,omitempty"`
+}
+
+type Resources struct {
+	AgentId   *mesos_v1.AgentID    `json:"agent_id"`
+	Resources []*mesos_v1.Resource `json:"resources"`
+}
+
+type MaintenanceUpdate struct {
+	Schedule *MaintenanceSchedule `json:"schedule"`
+}
+
+type Machines struct {
+	Machines []*mesos_v1.MachineID `json:"machines"`
+}
+
+// Machines that will be unavailable together for the same interval.
+type MaintenanceWindow struct {
+	MachineIds     []*mesos_v1.MachineID    `json:"machine_ids"`
+	Unavailability *mesos_v1.Unavailability `json:"unavailability"`
+}
+
+type MaintenanceSchedule struct {
+	Windows []*MaintenanceWindow `json:"windows"`
+}
+
+type Response struct {
+	Type                   string                  `json:"type"`
+	GetAgents              *GetAgents              `json:"get_agents,omitempty"`
+	GetTasks               *GetTasks               `json:"get_tasks,omitempty"`
+	GetState               *GetState               `json:"get_state,omitempty"`
+	GetMaintenanceSchedule *GetMaintenanceSchedule `json:"get_maintenance_schedule,omitempty"`
+}
+
+type Agent struct {
+	AgentInfo          *mesos_v1.AgentInfo  `json:"agent_info"`
+	Active             bool                 `json:"active"`
+	Version            string               `json:"version"`
+	Pid                string               `json:"pid"`
+	RegisteredTime     *mesos_v1.TimeInfo   `json:"registered_time"`
+	TotalResources     []*mesos_v1.Resource `json:"total_resources"`
+	AllocatedResources []*mesos_v1.Resource `json:"allocated_resources"`
+	OfferedResources   []*mesos_v1.Resource `json:"offered_resources"`
+}
+
+type GetAgents struct {
+	Agents          []*Agent              `json:"agents"`
+	RecoveredAgents []*mesos_v1.AgentInfo `json:"recovered_agents"`
+}
+
+type GetTasks struct {
+	PendingTasks     []*mesos_v1.Task `json:"pending_tasks"`
+	Tasks            []*mesos_v1.Task `json:"tasks"`
+	UnreachableTasks []*mesos_v1.Task `json:"unreachable_tasks"`
+	CompletedTasks   []*mesos_v1.Task `json:"completed_tasks"`
+	OrphanTasks      []*mesos_v1.Task `json:"orphan_tasks"`
+}
+
+type GetState struct {
+	GetTasks  *GetTasks  `json:"get_tasks"`
+	GetAgents *GetAgents `json:"get_agents"`
+}
+
+type GetMaintenanceSchedule struct {
+	Schedule *MaintenanceSchedule `json:"schedule"`
+}
+
+// Operator API client.
+type Client struct {
+	endpoint string
+	auth     string
+	client   *http.Client
+}
+
+// Creates a client for the operator endpoint, such as http://master:5050/api/v1.
+// Calls sent to a master that isn't leading are redirected to the leader.
+func NewClient(endpoint, auth string, timeout time.Duration) *Client {
+	return &Client{
+		endpoint: endpoint,
+		auth:     auth,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+// Sends a call, decoding the response into resp when it's given.
+func (c *Client) Call(call *Call, resp *Response) error {
+	data, err := json.Marshal(call)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", c.endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "mesos-framework-sdk")
+	if c.auth != "" {
+		req.Header.Set("Authorization", c.auth)
+	}
+
+	r, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+
+	if r.StatusCode >= 400 {
+		if r.StatusCode == http.StatusUnauthorized {
+			return errors.New("Unauthorized")
+		}
+
+		return errors.New(call.Type + " failed: " + string(body))
+	}
+
+	if resp == nil {
+		return nil
+	}
+
+	return json.Unmarshal(body, resp)
+}
+
+// Gets every agent known to the master.
+func (c *Client) GetAgents() (*GetAgents, error) {
+	resp := &Response{}
+	if err := c.Call(&Call{Type: GET_AGENTS}, resp); err != nil {
+		return nil, err
+	}
+	if resp.GetAgents == nil {
+		return nil, errors.New("Master did not return any agents")
+	}
+
+	return resp.GetAgents, nil
+}
+
+// Gets every task known to the master.
+func (c *Client) GetTasks() (*GetTasks, error) {
+	resp := &Response{}
+	if err := c.Call(&Call{Type: GET_TASKS}, resp); err != nil {
+		return nil, err
+	}
+	if resp.GetTasks == nil {
+		return nil, errors.New("Master did not return any tasks")
+	}
+
+	return resp.GetTasks, nil
+}
+
+// Gets the overall cluster state.
+func (c *Client) GetState() (*GetState, error) {
+	resp := &Response{}
+	if err := c.Call(&Call{Type: GET_STATE}, resp); err != nil {
+		return nil, err
+	}
+	if resp.GetState == nil {
+		return nil, errors.New("Master did not return its state")
+	}
+
+	return resp.GetState, nil
+}
+
+// Dynamically reserves resources on an agent, outside of any offer.
+func (c *Client) ReserveResources(agentId *mesos_v1.AgentID, resources []*mesos_v1.Resource) error {
+	return c.Call(&Call{
+		Type:             RESERVE_RESOURCES,
+		ReserveResources: &Resources{AgentId: agentId, Resources: resources},
+	}, nil)
+}
+
+// Releases dynamically reserved resources on an agent.
+func (c *Client) UnreserveResources(agentId *mesos_v1.AgentID, resources []*mesos_v1.Resource) error {
+	return c.Call(&Call{
+		Type:               UNRESERVE_RESOURCES,
+		UnreserveResources: &Resources{AgentId: agentId, Resources: resources},
+	}, nil)
+}
+
+// Gets the cluster's maintenance schedule.
+func (c *Client) GetMaintenanceSchedule() (*MaintenanceSchedule, error) {
+	resp := &Response{}
+	if err := c.Call(&Call{Type: GET_MAINTENANCE_SCHEDULE}, resp); err != nil {
+		return nil, err
+	}
+
+	if resp.GetMaintenanceSchedule == nil || resp.GetMaintenanceSchedule.Schedule == nil {
+		return &MaintenanceSchedule{}, nil
+	}
+
+	return resp.GetMaintenanceSchedule.Schedule, nil
+}
+
+// Replaces the cluster's maintenance schedule.
+func (c *Client) UpdateMaintenanceSchedule(schedule *MaintenanceSchedule) error {
+	return c.Call(&Call{
+		Type:                      UPDATE_MAINTENANCE_SCHEDULE,
+		UpdateMaintenanceSchedule: &MaintenanceUpdate{Schedule: schedule},
+	}, nil)
+}
+
+// Takes scheduled machines down for maintenance.
+func (c *Client) StartMaintenance(machines ...*mesos_v1.MachineID) error {
+	return c.Call(&Call{Type: START_MAINTENANCE, StartMaintenance: &Machines{Machines: machines}}, nil)
+}
+
+// Brings machines back up after maintenance, removing them from the schedule.
+func (c *Client) StopMaintenance(machines ...*mesos_v1.MachineID) error {
+	return c.Call(&Call{Type: STOP_MAINTENANCE, StopMaintenance: &Machines{Machines: machines}}, nil)
+}
@@ -0,0 +1,119 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"encoding/json"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Serves canned operator responses and records the calls it gets.
+func fakeMaster(calls chan *Call) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := &Call{}
+		if err := json.NewDecoder(r.Body).Decode(call); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		calls <- call
+
+		switch call.Type {
+		case GET_AGENTS:
+			w.Write([]byte(`{"type":"GET_AGENTS","get_agents":{"agents":[{"agent_info":{"hostname":"agent1","id":{"value":"a1"}},"active":true}]}}`))
+		case GET_TASKS:
+			w.Write([]byte(`{"type":"GET_TASKS","get_tasks":{"tasks":[{"name":"task","task_id":{"value":"t1"},"state":"TASK_RUNNING"}]}}`))
+		case GET_MAINTENANCE_SCHEDULE:
+			w.Write([]byte(`{"type":"GET_MAINTENANCE_SCHEDULE","get_maintenance_schedule":{"schedule":{"windows":[{"machine_ids":[{"hostname":"agent1"}],"unavailability":{"start":{"nanoseconds":1}}}]}}}`))
+		case START_MAINTENANCE:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("Machines are not scheduled for maintenance"))
+		default:
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+}
+
+// Ensures operator calls are sent and their responses decoded.
+func TestClient(t *testing.T) {
+	t.Parallel()
+
+	calls := make(chan *Call, 10)
+	ts := fakeMaster(calls)
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "", time.Second)
+
+	agents, err := c.GetAgents()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(agents.Agents) != 1 || agents.Agents[0].AgentInfo.GetHostname() != "agent1" || !agents.Agents[0].Active {
+		t.Fatal("Agents were not decoded correctly")
+	}
+
+	tasks, err := c.GetTasks()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(tasks.Tasks) != 1 || tasks.Tasks[0].GetState() != mesos_v1.TaskState_TASK_RUNNING {
+		t.Fatal("Tasks were not decoded correctly")
+	}
+
+	schedule, err := c.GetMaintenanceSchedule()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(schedule.Windows) != 1 || schedule.Windows[0].Unavailability.GetStart().GetNanoseconds() != 1 {
+		t.Fatal("Maintenance schedule was not decoded correctly")
+	}
+
+	agentId := &mesos_v1.AgentID{Value: utils.ProtoString("a1")}
+	if err := c.ReserveResources(agentId, nil); err != nil {
+		t.Fatal(err.Error())
+	}
+	for len(calls) > 1 {
+		<-calls
+	}
+	if call := <-calls; call.Type != RESERVE_RESOURCES || call.ReserveResources.AgentId.GetValue() != "a1" {
+		t.Fatal("Reserve call was not sent correctly")
+	}
+
+	if err := c.StartMaintenance(&mesos_v1.MachineID{Hostname: utils.ProtoString("agent1")}); err == nil {
+		t.Fatal("Master errors should be returned")
+	}
+}
+
+// Measures performance of getting agents from the master.
+func BenchmarkClient_GetAgents(b *testing.B) {
+	calls := make(chan *Call, 1)
+	ts := fakeMaster(calls)
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "", time.Second)
+	go func() {
+		for range calls {
+		}
+	}()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		c.GetAgents()
+	}
+}
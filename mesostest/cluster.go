@@ -0,0 +1,62 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mesostest
+
+// Group of mock masters where one leads and the rest redirect to it.
+type Cluster struct {
+	Masters []*Master
+	leader  int
+}
+
+// Starts a cluster of n masters led by the first one.
+func NewCluster(n int) *Cluster {
+	c := &Cluster{}
+	for i := 0; i < n; i++ {
+		c.Masters = append(c.Masters, NewMaster())
+	}
+	for _, m := range c.Masters[1:] {
+		m.follow(c.Masters[0].Address())
+	}
+
+	return c
+}
+
+// Gets the leading master.
+func (c *Cluster) Leader() *Master {
+	return c.Masters[c.leader]
+}
+
+// Hands leadership to the next master, dropping the framework's subscription to the old leader.
+// Every other master starts redirecting to the new leader.
+func (c *Cluster) Failover() *Master {
+	c.leader = (c.leader + 1) % len(c.Masters)
+	leader := c.Leader()
+
+	leader.follow("")
+	for i, m := range c.Masters {
+		if i != c.leader {
+			m.follow(leader.Address())
+		}
+	}
+
+	return leader
+}
+
+// Stops every master.
+func (c *Cluster) Close() {
+	for _, m := range c.Masters {
+		m.Close()
+	}
+}
@@ -0,0 +1,358 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mesostest provides an in-process mock Mesos master for testing frameworks end-to-end without a cluster.
+package mesostest
+
+import (
+	"errors"
+	"github.com/golang/protobuf/proto"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	sched "github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_scheduler"
+	"github.com/verizonlabs/mesos-framework-sdk/recordio"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	SCHEDULER_PATH = "/api/v1/scheduler"
+	EVENT_BUFFER   = 100
+)
+
+var (
+	ErrNotSubscribed = errors.New("No framework is subscribed to the master.")
+	ErrNoCall        = errors.New("Timed out waiting for the call.")
+)
+
+// A framework's event stream.
+type subscription struct {
+	events chan *sched.Event
+	done   chan struct{}
+	once   sync.Once
+}
+
+func (s *subscription) close() {
+	s.once.Do(func() {
+		close(s.done)
+	})
+}
+
+// Mock master that speaks the scheduler HTTP API.
+// Frameworks subscribe to it like a real master, while tests inject events and inspect the calls it received.
+type Master struct {
+	server            *httptest.Server
+	HeartbeatInterval time.Duration // Heartbeats are sent on the event stream this often. None are sent when 0.
+	leader            string        // Address of the leading master when this one isn't leading.
+	frameworkId       *mesos_v1.FrameworkID
+	streamId          string
+	sub               *subscription
+	calls             []*sched.Call
+	received          chan struct{}
+	sync.Mutex
+}
+
+// Starts a mock master listening on a local port.
+func NewMaster() *Master {
+	m := &Master{received: make(chan struct{})}
+	m.server = httptest.NewServer(m)
+
+	return m
+}
+
+// Gets the scheduler API endpoint of the master.
+func (m *Master) URL() string {
+	return m.server.URL + SCHEDULER_PATH
+}
+
+// Gets the host:port the master listens on.
+func (m *Master) Address() string {
+	u, _ := url.Parse(m.server.URL)
+	return u.Host
+}
+
+// Drops the subscription and stops the master.
+func (m *Master) Close() {
+	m.Disconnect()
+	m.server.Close()
+}
+
+// Drops the subscribed framework's event stream, as if the master failed or the connection was lost.
+func (m *Master) Disconnect() {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.sub != nil {
+		m.sub.close()
+		m.sub = nil
+	}
+	m.streamId = ""
+}
+
+// Gets the ID handed to the subscribed framework.
+func (m *Master) FrameworkID() *mesos_v1.FrameworkID {
+	m.Lock()
+	defer m.Unlock()
+
+	return m.frameworkId
+}
+
+// Reports whether a framework is currently subscribed.
+func (m *Master) Subscribed() bool {
+	m.Lock()
+	defer m.Unlock()
+
+	return m.sub != nil
+}
+
+// Makes the master redirect frameworks to the leader at the given address, or lead again when it's empty.
+func (m *Master) follow(leader string) {
+	m.Lock()
+	m.leader = leader
+	m.Unlock()
+
+	if leader != "" {
+		m.Disconnect()
+	}
+}
+
+// Sends an event to the subscribed framework.
+func (m *Master) Send(event *sched.Event) error {
+	m.Lock()
+	sub := m.sub
+	m.Unlock()
+
+	if sub == nil {
+		return ErrNotSubscribed
+	}
+
+	select {
+	case sub.events <- event:
+		return nil
+	case <-sub.done:
+		return ErrNotSubscribed
+	}
+}
+
+// Offers resources to the subscribed framework.
+// Offers without a framework ID get the subscribed framework's.
+func (m *Master) Offer(offers ...*mesos_v1.Offer) error {
+	id := m.FrameworkID()
+	for _, offer := range offers {
+		if offer.FrameworkId == nil {
+			offer.FrameworkId = id
+		}
+	}
+
+	return m.Send(&sched.Event{
+		Type:   sched.Event_OFFERS.Enum(),
+		Offers: &sched.Event_Offers{Offers: offers},
+	})
+}
+
+// Rescinds an offer previously sent to the framework.
+func (m *Master) Rescind(offerId *mesos_v1.OfferID) error {
+	return m.Send(&sched.Event{
+		Type:    sched.Event_RESCIND.Enum(),
+		Rescind: &sched.Event_Rescind{OfferId: offerId},
+	})
+}
+
+// Sends a task status update to the framework.
+// Updates get a UUID when they don't have one, so the framework is expected to acknowledge them.
+func (m *Master) Update(status *mesos_v1.TaskStatus) error {
+	if status.Uuid == nil {
+		status.Uuid = utils.Uuid()
+	}
+
+	return m.Send(&sched.Event{
+		Type:   sched.Event_UPDATE.Enum(),
+		Update: &sched.Event_Update{Status: status},
+	})
+}
+
+// Gets every call the master received, in order.
+func (m *Master) Calls() []*sched.Call {
+	m.Lock()
+	defer m.Unlock()
+
+	return append([]*sched.Call(nil), m.calls...)
+}
+
+// Waits for the master to receive a call of the given type, including ones it already received.
+func (m *Master) WaitForCall(t sched.Call_Type, timeout time.Duration) (*sched.Call, error) {
+	deadline := time.After(timeout)
+	for {
+		m.Lock()
+		for _, call := range m.calls {
+			if call.GetType() == t {
+				m.Unlock()
+				return call, nil
+			}
+		}
+		received := m.received
+		m.Unlock()
+
+		select {
+		case <-received:
+		case <-deadline:
+			return nil, ErrNoCall
+		}
+	}
+}
+
+// Keeps a call and wakes anything waiting for one.
+func (m *Master) record(call *sched.Call) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.calls = append(m.calls, call)
+	close(m.received)
+	m.received = make(chan struct{})
+}
+
+func (m *Master) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.Lock()
+	leader := m.leader
+	m.Unlock()
+
+	// Masters that aren't leading redirect everything to the leader, the same way real masters do.
+	if r.URL.Path == "/redirect" {
+		if leader == "" {
+			leader = m.Address()
+		}
+		http.Redirect(w, r, "//"+leader, http.StatusTemporaryRedirect)
+		return
+	}
+	if r.URL.Path != SCHEDULER_PATH {
+		http.NotFound(w, r)
+		return
+	}
+	if leader != "" {
+		http.Redirect(w, r, "//"+leader+SCHEDULER_PATH, http.StatusTemporaryRedirect)
+		return
+	}
+	if r.Method != "POST" {
+		http.Error(w, "Expecting 'POST'", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	call := new(sched.Call)
+	if err := proto.Unmarshal(body, call); err != nil {
+		http.Error(w, "Failed to parse call: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if call.GetType() == sched.Call_SUBSCRIBE {
+		m.record(call)
+		m.subscribe(w, r, call)
+		return
+	}
+
+	m.Lock()
+	streamId := m.streamId
+	m.Unlock()
+
+	if streamId == "" || r.Header.Get("Mesos-Stream-Id") != streamId {
+		http.Error(w, "The stream ID included in this request didn't match the stream ID currently associated with framework", http.StatusBadRequest)
+		return
+	}
+
+	m.record(call)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Streams events to a newly subscribed framework until it's disconnected.
+// A new subscription replaces the previous one, like a framework re-subscribing after a disconnect.
+func (m *Master) subscribe(w http.ResponseWriter, r *http.Request, call *sched.Call) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	sub := &subscription{
+		events: make(chan *sched.Event, EVENT_BUFFER),
+		done:   make(chan struct{}),
+	}
+
+	m.Lock()
+	if m.sub != nil {
+		m.sub.close()
+	}
+	m.sub = sub
+	m.streamId = utils.UuidAsString()
+	streamId := m.streamId
+
+	if id := call.GetSubscribe().GetFrameworkInfo().GetId(); id.GetValue() != "" {
+		m.frameworkId = id
+	} else if m.frameworkId == nil {
+		m.frameworkId = &mesos_v1.FrameworkID{Value: utils.ProtoString(utils.UuidAsString())}
+	}
+	subscribed := &sched.Event{
+		Type: sched.Event_SUBSCRIBED.Enum(),
+		Subscribed: &sched.Event_Subscribed{
+			FrameworkId:              m.frameworkId,
+			HeartbeatIntervalSeconds: utils.ProtoFloat64(m.HeartbeatInterval.Seconds()),
+		},
+	}
+	m.Unlock()
+
+	w.Header().Set("Mesos-Stream-Id", streamId)
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.WriteHeader(http.StatusOK)
+
+	if err := write(w, subscribed); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	var heartbeat <-chan time.Time
+	if m.HeartbeatInterval > 0 {
+		ticker := time.NewTicker(m.HeartbeatInterval)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	for {
+		var event *sched.Event
+		select {
+		case event = <-sub.events:
+		case <-heartbeat:
+			event = &sched.Event{Type: sched.Event_HEARTBEAT.Enum()}
+		case <-sub.done:
+			return
+		}
+
+		if err := write(w, event); err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// Writes an event as a RecordIO record.
+func write(w http.ResponseWriter, event *sched.Event) error {
+	return recordio.NewWriter(w).WriteMessage(event)
+}
@@ -0,0 +1,160 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mesostest
+
+import (
+	"context"
+	"github.com/verizonlabs/mesos-framework-sdk/client"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	sched "github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_scheduler"
+	"github.com/verizonlabs/mesos-framework-sdk/scheduler"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"testing"
+	"time"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Emit(severity uint8, template string, args ...interface{}) {}
+
+func framework() *mesos_v1.FrameworkInfo {
+	return &mesos_v1.FrameworkInfo{
+		Id:   &mesos_v1.FrameworkID{Value: utils.ProtoString("framework")},
+		User: utils.ProtoString("root"),
+		Name: utils.ProtoString("test"),
+	}
+}
+
+// Subscribes in the background, returning the event stream and a channel that gets the result once the stream ends.
+func subscribe(s scheduler.Scheduler) (chan *sched.Event, chan error) {
+	events := make(chan *sched.Event, EVENT_BUFFER)
+	done := make(chan error, 1)
+	go func() {
+		_, err := s.Subscribe(context.Background(), events)
+		done <- err
+	}()
+
+	return events, done
+}
+
+func next(t *testing.T, events chan *sched.Event, want sched.Event_Type) *sched.Event {
+	select {
+	case e := <-events:
+		if e.GetType() != want {
+			t.Fatal("Expected a " + want.String() + " event but got " + e.GetType().String())
+		}
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for a " + want.String() + " event")
+	}
+
+	return nil
+}
+
+// Ensures frameworks can subscribe, receive injected events and have their calls recorded.
+func TestMaster(t *testing.T) {
+	t.Parallel()
+
+	m := NewMaster()
+	defer m.Close()
+
+	s := scheduler.NewDefaultScheduler(client.NewClient(client.ClientData{Endpoint: m.URL()}, nopLogger{}), framework(), nopLogger{})
+	if _, err := s.Decline(context.Background(), []*mesos_v1.OfferID{{Value: utils.ProtoString("offer")}}, nil); err == nil {
+		t.Fatal("Calls should be rejected before subscribing")
+	}
+
+	events, done := subscribe(s)
+	subscribed := next(t, events, sched.Event_SUBSCRIBED)
+	if subscribed.GetSubscribed().GetFrameworkId().GetValue() != "framework" || !m.Subscribed() {
+		t.Fatal("Frameworks should keep their ID when subscribing")
+	}
+
+	m.Offer(&mesos_v1.Offer{Id: &mesos_v1.OfferID{Value: utils.ProtoString("offer")}})
+	offers := next(t, events, sched.Event_OFFERS)
+	if offers.GetOffers().GetOffers()[0].GetFrameworkId().GetValue() != "framework" {
+		t.Fatal("Injected offers should belong to the subscribed framework")
+	}
+
+	m.Update(&mesos_v1.TaskStatus{TaskId: &mesos_v1.TaskID{Value: utils.ProtoString("task")}, State: mesos_v1.TaskState_TASK_RUNNING.Enum()})
+	if next(t, events, sched.Event_UPDATE).GetUpdate().GetStatus().GetUuid() == nil {
+		t.Fatal("Injected updates should carry a UUID")
+	}
+
+	if _, err := s.Decline(context.Background(), []*mesos_v1.OfferID{{Value: utils.ProtoString("offer")}}, nil); err != nil {
+		t.Fatal("Calls should be accepted once subscribed: " + err.Error())
+	}
+	if _, err := m.WaitForCall(sched.Call_DECLINE, time.Second); err != nil {
+		t.Fatal("The decline was not recorded")
+	}
+	if _, err := m.WaitForCall(sched.Call_KILL, 10*time.Millisecond); err != ErrNoCall {
+		t.Fatal("Waiting for a call that never comes should time out")
+	}
+
+	m.Disconnect()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Disconnecting should end the event stream")
+	}
+	if err := m.Offer(); err != ErrNotSubscribed {
+		t.Fatal("Events can't be sent without a subscription")
+	}
+}
+
+// Measures performance of sending an event to a subscribed framework.
+func BenchmarkMaster_Send(b *testing.B) {
+	m := NewMaster()
+	defer m.Close()
+
+	s := scheduler.NewDefaultScheduler(client.NewClient(client.ClientData{Endpoint: m.URL()}, nopLogger{}), framework(), nopLogger{})
+	events, _ := subscribe(s)
+	<-events
+
+	heartbeat := &sched.Event{Type: sched.Event_HEARTBEAT.Enum()}
+	for n := 0; n < b.N; n++ {
+		m.Send(heartbeat)
+		<-events
+	}
+}
+
+// Ensures failing over moves the framework to the new leader.
+func TestCluster_Failover(t *testing.T) {
+	t.Parallel()
+
+	c := NewCluster(3)
+	defer c.Close()
+
+	old := c.Leader()
+	s := scheduler.NewDefaultScheduler(client.NewClient(client.ClientData{Endpoint: old.URL()}, nopLogger{}), framework(), nopLogger{})
+	events, done := subscribe(s)
+	next(t, events, sched.Event_SUBSCRIBED)
+
+	leader := c.Failover()
+	if leader == old {
+		t.Fatal("Failing over should elect another master")
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Failing over should drop the subscription to the old leader")
+	}
+
+	// Re-subscribing to the old leader follows its redirect to the new one.
+	events, _ = subscribe(s)
+	next(t, events, sched.Event_SUBSCRIBED)
+	if !leader.Subscribed() || old.Subscribed() {
+		t.Fatal("The framework should have re-subscribed to the new leader")
+	}
+}
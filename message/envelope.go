@@ -0,0 +1,108 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/golang/protobuf/proto"
+)
+
+var ErrMalformed = errors.New("Message is not a valid envelope.")
+
+// Wraps a payload exchanged between a framework and its executors.
+// Requests and their replies share a correlation ID.
+type Envelope struct {
+	Type          *string `protobuf:"bytes,1,opt,name=type" json:"type,omitempty"`
+	Payload       []byte  `protobuf:"bytes,2,opt,name=payload" json:"payload,omitempty"`
+	CorrelationId *string `protobuf:"bytes,3,opt,name=correlation_id,json=correlationId" json:"correlation_id,omitempty"`
+	Reply         *bool   `protobuf:"varint,4,opt,name=reply" json:"reply,omitempty"`
+}
+
+func (m *Envelope) Reset()         { *m = Envelope{} }
+func (m *Envelope) String() string { return proto.CompactTextString(m) }
+func (*Envelope) ProtoMessage()    {}
+
+func (m *Envelope) GetType() string {
+	if m != nil && m.Type != nil {
+		return *m.Type
+	}
+	return ""
+}
+
+func (m *Envelope) GetPayload() []byte {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func (m *Envelope) GetCorrelationId() string {
+	if m != nil && m.CorrelationId != nil {
+		return *m.CorrelationId
+	}
+	return ""
+}
+
+func (m *Envelope) GetReply() bool {
+	if m != nil && m.Reply != nil {
+		return *m.Reply
+	}
+	return false
+}
+
+// Turns envelopes into the raw bytes carried by Mesos messages and back.
+type Codec interface {
+	Encode(*Envelope) ([]byte, error)
+	Decode([]byte) (*Envelope, error)
+}
+
+// Encodes envelopes as protobuf.
+type ProtobufCodec struct{}
+
+func (ProtobufCodec) Encode(e *Envelope) ([]byte, error) {
+	return proto.Marshal(e)
+}
+
+func (ProtobufCodec) Decode(data []byte) (*Envelope, error) {
+	e := new(Envelope)
+	if err := proto.Unmarshal(data, e); err != nil {
+		return nil, ErrMalformed
+	}
+	if e.Type == nil {
+		return nil, ErrMalformed
+	}
+
+	return e, nil
+}
+
+// Encodes envelopes as JSON, which is easier to produce from executors that aren't written in Go.
+type JSONCodec struct{}
+
+func (JSONCodec) Encode(e *Envelope) ([]byte, error) {
+	return json.Marshal(e)
+}
+
+func (JSONCodec) Decode(data []byte) (*Envelope, error) {
+	e := new(Envelope)
+	if err := json.Unmarshal(data, e); err != nil {
+		return nil, ErrMalformed
+	}
+	if e.Type == nil {
+		return nil, ErrMalformed
+	}
+
+	return e, nil
+}
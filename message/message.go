@@ -0,0 +1,140 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"context"
+	"errors"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"sync"
+	"time"
+)
+
+var ErrTimeout = errors.New("Timed out waiting for a reply.")
+
+// Sends raw message data to the other side.
+// Executors can use their Message method directly, while schedulers wrap Message with the agent and executor to talk to.
+type Sender func(data []byte) error
+
+// Frames messages in envelopes and correlates requests with their replies,
+// so frameworks and executors can make RPC-style calls to each other.
+type Messenger struct {
+	send    Sender
+	codec   Codec
+	Timeout time.Duration // How long requests wait for a reply.
+	pending map[string]chan *Envelope
+	sync.Mutex
+}
+
+func NewMessenger(send Sender, codec Codec) *Messenger {
+	return &Messenger{
+		send:    send,
+		codec:   codec,
+		Timeout: 30 * time.Second,
+		pending: make(map[string]chan *Envelope),
+	}
+}
+
+// Sends a message that doesn't expect a reply.
+func (m *Messenger) Send(t string, payload []byte) error {
+	return m.write(&Envelope{
+		Type:          utils.ProtoString(t),
+		Payload:       payload,
+		CorrelationId: utils.ProtoString(utils.UuidAsString()),
+	})
+}
+
+// Sends a request and waits for its reply until the timeout passes or the context is done.
+func (m *Messenger) Request(ctx context.Context, t string, payload []byte) (*Envelope, error) {
+	id := utils.UuidAsString()
+	reply := make(chan *Envelope, 1)
+
+	m.Lock()
+	m.pending[id] = reply
+	m.Unlock()
+
+	defer func() {
+		m.Lock()
+		delete(m.pending, id)
+		m.Unlock()
+	}()
+
+	err := m.write(&Envelope{
+		Type:          utils.ProtoString(t),
+		Payload:       payload,
+		CorrelationId: utils.ProtoString(id),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(m.Timeout)
+	defer timer.Stop()
+
+	select {
+	case e := <-reply:
+		return e, nil
+	case <-timer.C:
+		return nil, ErrTimeout
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Replies to a request received from the other side.
+func (m *Messenger) Reply(request *Envelope, t string, payload []byte) error {
+	return m.write(&Envelope{
+		Type:          utils.ProtoString(t),
+		Payload:       payload,
+		CorrelationId: utils.ProtoString(request.GetCorrelationId()),
+		Reply:         utils.ProtoBool(true),
+	})
+}
+
+// Decodes raw message data from the other side.
+// Replies to pending requests are handed to the waiting request and nil is returned,
+// anything else is returned for the caller to handle.
+// Replies that arrive after their request gave up are dropped.
+func (m *Messenger) Receive(data []byte) (*Envelope, error) {
+	e, err := m.codec.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	if !e.GetReply() {
+		return e, nil
+	}
+
+	m.Lock()
+	reply, ok := m.pending[e.GetCorrelationId()]
+	m.Unlock()
+
+	if ok {
+		select {
+		case reply <- e:
+		default:
+		}
+	}
+
+	return nil, nil
+}
+
+func (m *Messenger) write(e *Envelope) error {
+	data, err := m.codec.Encode(e)
+	if err != nil {
+		return err
+	}
+
+	return m.send(data)
+}
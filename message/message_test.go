@@ -0,0 +1,93 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// Wires a messenger to a handler that replies to every request on the other side.
+func echo(codec Codec) *Messenger {
+	var scheduler, executor *Messenger
+	scheduler = NewMessenger(func(data []byte) error {
+		go func() {
+			request, err := executor.Receive(data)
+			if err == nil && request != nil && request.GetType() != "ignore" {
+				executor.Reply(request, "pong", request.GetPayload())
+			}
+		}()
+		return nil
+	}, codec)
+	executor = NewMessenger(func(data []byte) error {
+		go scheduler.Receive(data)
+		return nil
+	}, codec)
+
+	return scheduler
+}
+
+// Ensures requests are matched up with their replies for every codec.
+func TestMessenger_Request(t *testing.T) {
+	t.Parallel()
+
+	for _, codec := range []Codec{JSONCodec{}, ProtobufCodec{}} {
+		m := echo(codec)
+		reply, err := m.Request(context.Background(), "ping", []byte("payload"))
+		if err != nil || reply.GetType() != "pong" || string(reply.GetPayload()) != "payload" {
+			t.Fatal("Requests should receive their reply")
+		}
+
+		m.Timeout = 10 * time.Millisecond
+		if _, err := m.Request(context.Background(), "ignore", nil); err != ErrTimeout {
+			t.Fatal("Requests without a reply should time out")
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := echo(JSONCodec{}).Request(ctx, "ignore", nil); err != context.Canceled {
+		t.Fatal("Requests should stop waiting once the context is done")
+	}
+}
+
+// Measures performance of a request and reply round trip.
+func BenchmarkMessenger_Request(b *testing.B) {
+	m := echo(JSONCodec{})
+	for n := 0; n < b.N; n++ {
+		m.Request(context.Background(), "ping", nil)
+	}
+}
+
+// Ensures only replies are swallowed and malformed data is rejected.
+func TestMessenger_Receive(t *testing.T) {
+	t.Parallel()
+
+	m := NewMessenger(func([]byte) error { return nil }, JSONCodec{})
+	e, err := m.Receive([]byte(`{"type":"status","payload":"b2s="}`))
+	if err != nil || e.GetType() != "status" || string(e.GetPayload()) != "ok" {
+		t.Fatal("Messages that aren't replies should be returned to the caller")
+	}
+
+	e, err = m.Receive([]byte(`{"type":"pong","correlation_id":"unknown","reply":true}`))
+	if err != nil || e != nil {
+		t.Fatal("Late replies should be dropped")
+	}
+
+	if _, err := m.Receive([]byte("raw bytes")); err != ErrMalformed {
+		t.Fatal("Data that isn't an envelope should be rejected")
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	sched "github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_scheduler"
+	"github.com/verizonlabs/mesos-framework-sdk/resources/manager"
+	"github.com/verizonlabs/mesos-framework-sdk/scheduler"
+	"net/http"
+	"time"
+)
+
+const NAMESPACE = "mesos_framework"
+
+// Prometheus metrics for a framework built on the SDK.
+// Calls and events are measured by adding Interceptor and EventInterceptor to the scheduler,
+// reconnections by using Disconnected as the reconnect policy's OnDisconnected.
+type Metrics struct {
+	namespace      string
+	registry       *prometheus.Registry
+	offersReceived prometheus.Counter
+	offersDeclined prometheus.Counter
+	tasksLaunched  prometheus.Counter
+	tasksFailed    prometheus.Counter
+	reconnections  prometheus.Counter
+	taskUpdates    *prometheus.CounterVec
+	events         *prometheus.CounterVec
+	callErrors     *prometheus.CounterVec
+	callLatency    *prometheus.HistogramVec
+}
+
+// Creates the metrics in their own registry, with names starting with the namespace.
+func New(namespace string) *Metrics {
+	m := &Metrics{
+		namespace: namespace,
+		registry:  prometheus.NewRegistry(),
+		offersReceived: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "offers_received_total",
+			Help:      "Offers received from Mesos.",
+		}),
+		offersDeclined: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "offers_declined_total",
+			Help:      "Offers declined back to Mesos.",
+		}),
+		tasksLaunched: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tasks_launched_total",
+			Help:      "Tasks launched, including tasks launched in groups.",
+		}),
+		tasksFailed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tasks_failed_total",
+			Help:      "Status updates reporting a task failed or errored.",
+		}),
+		reconnections: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "reconnections_total",
+			Help:      "Times the event stream dropped and was re-established.",
+		}),
+		taskUpdates: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "task_updates_total",
+			Help:      "Task status updates by state.",
+		}, []string{"state"}),
+		events: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "events_total",
+			Help:      "Events received on the event stream by type.",
+		}, []string{"type"}),
+		callErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "call_errors_total",
+			Help:      "Calls to Mesos that failed by type.",
+		}, []string{"type"}),
+		callLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "call_duration_seconds",
+			Help:      "How long calls to Mesos took by type, subscriptions aren't included since they last as long as the stream.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"type"}),
+	}
+
+	m.registry.MustRegister(
+		m.offersReceived,
+		m.offersDeclined,
+		m.tasksLaunched,
+		m.tasksFailed,
+		m.reconnections,
+		m.taskUpdates,
+		m.events,
+		m.callErrors,
+		m.callLatency,
+	)
+
+	return m
+}
+
+// The registry the metrics are in, frameworks can register their own metrics here too.
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Serves the metrics for Prometheus to scrape, usually mounted at /metrics.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Measures every call the scheduler makes.
+func (m *Metrics) Interceptor() scheduler.Interceptor {
+	return func(ctx context.Context, call *sched.Call, next scheduler.Invoker) (*http.Response, error) {
+		start := time.Now()
+		resp, err := next(ctx, call)
+
+		typ := call.GetType().String()
+		if call.GetType() != sched.Call_SUBSCRIBE {
+			m.callLatency.WithLabelValues(typ).Observe(time.Since(start).Seconds())
+		}
+		if err != nil {
+			m.callErrors.WithLabelValues(typ).Inc()
+			return resp, err
+		}
+
+		switch call.GetType() {
+		case sched.Call_ACCEPT:
+			m.tasksLaunched.Add(float64(launched(call.GetAccept().GetOperations())))
+		case sched.Call_DECLINE:
+			m.offersDeclined.Add(float64(len(call.GetDecline().GetOfferIds())))
+		}
+
+		return resp, err
+	}
+}
+
+// Counts every event the scheduler dispatches.
+func (m *Metrics) EventInterceptor() scheduler.EventInterceptor {
+	return func(event *sched.Event, next scheduler.EventInvoker) {
+		m.events.WithLabelValues(event.GetType().String()).Inc()
+
+		switch event.GetType() {
+		case sched.Event_OFFERS:
+			m.offersReceived.Add(float64(len(event.GetOffers().GetOffers())))
+		case sched.Event_UPDATE:
+			state := event.GetUpdate().GetStatus().GetState()
+			m.taskUpdates.WithLabelValues(state.String()).Inc()
+			if state == mesos_v1.TaskState_TASK_FAILED || state == mesos_v1.TaskState_TASK_ERROR {
+				m.tasksFailed.Inc()
+			}
+		}
+
+		next(event)
+	}
+}
+
+// Counts a reconnection, it has the signature of a reconnect policy's OnDisconnected.
+func (m *Metrics) Disconnected(err error) {
+	m.reconnections.Inc()
+}
+
+// Reports how many tasks are waiting in the queue for offers.
+func (m *Metrics) WatchQueue(q *scheduler.TaskQueue) error {
+	return m.registry.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: m.namespace,
+		Name:      "pending_tasks",
+		Help:      "Tasks waiting in the queue for offers.",
+	}, func() float64 {
+		return float64(q.Len())
+	}))
+}
+
+// Reports how many offers the resource manager is holding.
+func (m *Metrics) WatchResources(r manager.ResourceManager) error {
+	return m.registry.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: m.namespace,
+		Name:      "offers_held",
+		Help:      "Offers held by the resource manager that haven't been used or declined.",
+	}, func() float64 {
+		return float64(len(r.Offers()))
+	}))
+}
+
+// Counts the tasks started by launch operations.
+func launched(operations []*mesos_v1.Offer_Operation) int {
+	n := 0
+	for _, op := range operations {
+		switch op.GetType() {
+		case mesos_v1.Offer_Operation_LAUNCH:
+			n += len(op.GetLaunch().GetTaskInfos())
+		case mesos_v1.Offer_Operation_LAUNCH_GROUP:
+			n += len(op.GetLaunchGroup().GetTaskGroup().GetTasks())
+		}
+	}
+
+	return n
+}
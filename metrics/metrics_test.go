@@ -0,0 +1,120 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	sched "github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_scheduler"
+	"github.com/verizonlabs/mesos-framework-sdk/scheduler"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func scrape(t *testing.T, m *Metrics) string {
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := ioutil.ReadAll(w.Body)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	return string(body)
+}
+
+func sent(ctx context.Context, call *sched.Call) (*http.Response, error) {
+	return new(http.Response), nil
+}
+
+// Ensures calls and events are counted as they pass through the interceptors.
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+
+	m := New(NAMESPACE)
+	call := m.Interceptor()
+	launch := &mesos_v1.Offer_Operation{
+		Type:   mesos_v1.Offer_Operation_LAUNCH.Enum(),
+		Launch: &mesos_v1.Offer_Operation_Launch{TaskInfos: []*mesos_v1.TaskInfo{{}, {}}},
+	}
+	call(context.Background(), &sched.Call{
+		Type:   sched.Call_ACCEPT.Enum(),
+		Accept: &sched.Call_Accept{Operations: []*mesos_v1.Offer_Operation{launch}},
+	}, sent)
+	call(context.Background(), &sched.Call{
+		Type:    sched.Call_DECLINE.Enum(),
+		Decline: &sched.Call_Decline{OfferIds: []*mesos_v1.OfferID{{Value: utils.ProtoString("offer")}}},
+	}, sent)
+	call(context.Background(), &sched.Call{Type: sched.Call_REVIVE.Enum()},
+		func(ctx context.Context, call *sched.Call) (*http.Response, error) {
+			return nil, errors.New("unreachable")
+		})
+
+	event := m.EventInterceptor()
+	dispatched := 0
+	next := func(*sched.Event) { dispatched++ }
+	event(&sched.Event{
+		Type:   sched.Event_OFFERS.Enum(),
+		Offers: &sched.Event_Offers{Offers: []*mesos_v1.Offer{{}, {}, {}}},
+	}, next)
+	event(&sched.Event{
+		Type:   sched.Event_UPDATE.Enum(),
+		Update: &sched.Event_Update{Status: &mesos_v1.TaskStatus{State: mesos_v1.TaskState_TASK_FAILED.Enum()}},
+	}, next)
+	m.Disconnected(errors.New("dropped"))
+
+	q := scheduler.NewTaskQueue()
+	if err := m.WatchQueue(q); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := m.WatchQueue(q); err == nil {
+		t.Fatal("Watching a second queue should fail")
+	}
+
+	if dispatched != 2 {
+		t.Fatal("Events should be passed on")
+	}
+	body := scrape(t, m)
+	for _, expected := range []string{
+		"mesos_framework_tasks_launched_total 2",
+		"mesos_framework_offers_declined_total 1",
+		"mesos_framework_offers_received_total 3",
+		"mesos_framework_tasks_failed_total 1",
+		"mesos_framework_reconnections_total 1",
+		`mesos_framework_task_updates_total{state="TASK_FAILED"} 1`,
+		`mesos_framework_events_total{type="OFFERS"} 1`,
+		`mesos_framework_call_errors_total{type="REVIVE"} 1`,
+		`mesos_framework_call_duration_seconds_count{type="ACCEPT"} 1`,
+		"mesos_framework_pending_tasks 0",
+	} {
+		if !strings.Contains(body, expected+"\n") {
+			t.Fatal("Missing " + expected + " in\n" + body)
+		}
+	}
+}
+
+// Measures performance of measuring a call.
+func BenchmarkMetrics(b *testing.B) {
+	m := New(NAMESPACE)
+	call := m.Interceptor()
+	revive := &sched.Call{Type: sched.Call_REVIVE.Enum()}
+	for n := 0; n < b.N; n++ {
+		call(context.Background(), revive, sent)
+	}
+}
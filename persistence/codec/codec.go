@@ -0,0 +1,143 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codec
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+
+	"github.com/golang/protobuf/proto"
+)
+
+var ErrNotProto = errors.New("Protobuf codecs can only encode protobuf messages.")
+
+// Codec Interface turns values into what's persisted and back.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+var (
+	JSON     Codec = jsonCodec{}
+	Protobuf Codec = protobufCodec{}
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+type protobufCodec struct{}
+
+func (protobufCodec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, ErrNotProto
+	}
+
+	return proto.Marshal(m)
+}
+
+func (protobufCodec) Unmarshal(data []byte, v interface{}) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return ErrNotProto
+	}
+
+	return proto.Unmarshal(data, m)
+}
+
+// Compresses whatever the codec produces.
+func Gzip(c Codec) Codec {
+	return gzipCodec{c}
+}
+
+type gzipCodec struct {
+	codec Codec
+}
+
+func (g gzipCodec) Marshal(v interface{}) ([]byte, error) {
+	data, err := g.codec.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (g gzipCodec) Unmarshal(data []byte, v interface{}) error {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	decompressed, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	return g.codec.Unmarshal(decompressed, v)
+}
+
+// Encodes whatever the codec produces as base64 text.
+// This is needed by backends that store text, such as the SQL driver, when the codec produces binary.
+func Base64(c Codec) Codec {
+	return base64Codec{c}
+}
+
+type base64Codec struct {
+	codec Codec
+}
+
+func (b base64Codec) Marshal(v interface{}) ([]byte, error) {
+	data, err := b.codec.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded := make([]byte, base64.StdEncoding.EncodedLen(len(data)))
+	base64.StdEncoding.Encode(encoded, data)
+
+	return encoded, nil
+}
+
+func (b base64Codec) Unmarshal(data []byte, v interface{}) error {
+	decoded := make([]byte, base64.StdEncoding.DecodedLen(len(data)))
+	n, err := base64.StdEncoding.Decode(decoded, data)
+	if err != nil {
+		return err
+	}
+
+	return b.codec.Unmarshal(decoded[:n], v)
+}
@@ -0,0 +1,70 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codec
+
+import (
+	"testing"
+
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+)
+
+type state struct {
+	Name      string `json:"name"`
+	Instances int    `json:"instances"`
+}
+
+// Ensures every codec, including the wrapped ones, decodes what it encoded.
+func TestCodecs(t *testing.T) {
+	t.Parallel()
+
+	for _, c := range []Codec{JSON, Gzip(JSON), Base64(Gzip(JSON))} {
+		data, err := c.Marshal(state{Name: "task", Instances: 3})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		s := state{}
+		if err := c.Unmarshal(data, &s); err != nil || s.Name != "task" || s.Instances != 3 {
+			t.Fatal("Values should survive a round trip through the codec")
+		}
+	}
+
+	info := &mesos_v1.FrameworkInfo{Name: utils.ProtoString("framework")}
+	for _, c := range []Codec{Protobuf, Base64(Gzip(Protobuf))} {
+		data, err := c.Marshal(info)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		decoded := &mesos_v1.FrameworkInfo{}
+		if err := c.Unmarshal(data, decoded); err != nil || decoded.GetName() != "framework" {
+			t.Fatal("Messages should survive a round trip through the protobuf codec")
+		}
+	}
+
+	if _, err := Protobuf.Marshal(state{}); err != ErrNotProto {
+		t.Fatal("The protobuf codec should refuse values that aren't messages")
+	}
+}
+
+// Measures encoding and decoding with compression.
+func BenchmarkCodecs(b *testing.B) {
+	c := Gzip(JSON)
+	for n := 0; n < b.N; n++ {
+		data, _ := c.Marshal(state{Name: "task", Instances: 3})
+		c.Unmarshal(data, &state{})
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codec
+
+import (
+	"errors"
+
+	"github.com/verizonlabs/mesos-framework-sdk/persistence"
+)
+
+var ErrNotFound = errors.New("The key doesn't exist.")
+
+// Persists values through a codec so callers don't have to marshal them to strings themselves.
+type Store struct {
+	storage persistence.KeyValueStore
+	codec   Codec
+}
+
+func NewStore(storage persistence.KeyValueStore, codec Codec) *Store {
+	return &Store{
+		storage: storage,
+		codec:   codec,
+	}
+}
+
+// Inserts a new key with the encoded value.
+// This will not overwrite an already existing key.
+func (s *Store) Create(key string, v interface{}) error {
+	data, err := s.codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return s.storage.Create(key, string(data))
+}
+
+// Creates a key with the encoded value and a specified TTL.
+// This will not overwrite an already existing key.
+func (s *Store) CreateWithLease(key string, v interface{}, ttl int64) (int64, error) {
+	data, err := s.codec.Marshal(v)
+	if err != nil {
+		return -1, err
+	}
+
+	return s.storage.CreateWithLease(key, string(data), ttl)
+}
+
+// Decodes a key's value into v, ErrNotFound is returned if the key doesn't exist.
+func (s *Store) Read(key string, v interface{}) error {
+	data, err := s.storage.Read(key)
+	if err != nil {
+		return err
+	}
+	if data == "" {
+		return ErrNotFound
+	}
+
+	return s.codec.Unmarshal([]byte(data), v)
+}
+
+// Decodes every value under a key, each one into a new value from the given function.
+func (s *Store) ReadAll(key string, newValue func() interface{}) (map[string]interface{}, error) {
+	kvs, err := s.storage.ReadAll(key)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{}, len(kvs))
+	for k, data := range kvs {
+		v := newValue()
+		if err := s.codec.Unmarshal([]byte(data), v); err != nil {
+			return nil, errors.New("Failed to decode " + k + ": " + err.Error())
+		}
+		values[k] = v
+	}
+
+	return values, nil
+}
+
+// Updates a key with the encoded value.
+// This will overwrite an existing key if present.
+func (s *Store) Update(key string, v interface{}) error {
+	data, err := s.codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return s.storage.Update(key, string(data))
+}
+
+// Deletes a key/value pair.
+func (s *Store) Delete(key string) error {
+	return s.storage.Delete(key)
+}
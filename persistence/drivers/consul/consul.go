@@ -0,0 +1,372 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consul
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/verizonlabs/mesos-framework-sdk/persistence"
+)
+
+var (
+	ErrKeyExists    = errors.New("The key already exists.")
+	ErrLeaseExpired = errors.New("The session holding the lease has expired.")
+)
+
+// Consul rejects session TTLs outside of this range.
+const (
+	MIN_SESSION_TTL = 10
+	MAX_SESSION_TTL = 86400
+)
+
+// Talks to the Consul KV store over its HTTP API.
+// Leases are backed by sessions that delete their keys when they expire.
+type Consul struct {
+	client   *http.Client
+	address  string
+	token    string
+	mu       sync.Mutex
+	sessions map[int64]string
+	lease    int64
+}
+
+type kvPair struct {
+	Key         string
+	Value       []byte
+	ModifyIndex uint64
+}
+
+type txnOp struct {
+	KV txnKV
+}
+
+type txnKV struct {
+	Verb    string
+	Key     string
+	Value   []byte `json:",omitempty"`
+	Session string `json:",omitempty"`
+}
+
+// Creates a new Consul client for the agent at the given address, such as http://127.0.0.1:8500.
+// The ACL token is optional.
+func NewClient(address, token string, timeout time.Duration) *Consul {
+	return &Consul{
+		client:   &http.Client{Timeout: timeout},
+		address:  strings.TrimSuffix(address, "/"),
+		token:    token,
+		sessions: make(map[int64]string),
+	}
+}
+
+// Inserts a new key/value pair.
+// This will not overwrite an already existing key.
+func (c *Consul) Create(key, value string) error {
+	ok, err := c.put(key, value, url.Values{"cas": {"0"}})
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrKeyExists
+	}
+
+	return nil
+}
+
+// Creates a key that's deleted once its session's TTL runs out without being refreshed.
+// This will not overwrite an already existing key.
+func (c *Consul) CreateWithLease(key, value string, ttl int64) (int64, error) {
+	if ttl < MIN_SESSION_TTL {
+		ttl = MIN_SESSION_TTL
+	} else if ttl > MAX_SESSION_TTL {
+		ttl = MAX_SESSION_TTL
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"TTL":      strconv.FormatInt(ttl, 10) + "s",
+		"Behavior": "delete",
+	})
+	if err != nil {
+		return -1, err
+	}
+
+	resp, err := c.do(http.MethodPut, "/v1/session/create", nil, bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+	defer resp.Body.Close()
+
+	var session struct{ ID string }
+	if err := json.NewDecoder(resp.Body).Decode(&session); err != nil {
+		return -1, err
+	}
+
+	// Checking and locking in one transaction keeps existing keys from being overwritten.
+	ops, err := json.Marshal([]txnOp{
+		{KV: txnKV{Verb: "check-not-exists", Key: trim(key)}},
+		{KV: txnKV{Verb: "lock", Key: trim(key), Value: []byte(value), Session: session.ID}},
+	})
+	if err != nil {
+		return -1, err
+	}
+
+	txn, err := c.do(http.MethodPut, "/v1/txn", nil, bytes.NewReader(ops))
+	if err == errConflict {
+		c.destroy(session.ID)
+		return -1, ErrKeyExists
+	}
+	if err != nil {
+		c.destroy(session.ID)
+		return -1, err
+	}
+	txn.Body.Close()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lease++
+	c.sessions[c.lease] = session.ID
+
+	return c.lease, nil
+}
+
+// Reads a key's value.
+func (c *Consul) Read(key string) (string, error) {
+	pairs, err := c.get(key, nil)
+	if err != nil || len(pairs) == 0 {
+		return "", err
+	}
+
+	return string(pairs[0].Value), nil
+}
+
+// Reads a key's value along with the index it was last modified at.
+// Missing keys have a version of 0.
+func (c *Consul) ReadVersion(key string) (string, int64, error) {
+	pairs, err := c.get(key, nil)
+	if err != nil || len(pairs) == 0 {
+		return "", 0, err
+	}
+
+	return string(pairs[0].Value), int64(pairs[0].ModifyIndex), nil
+}
+
+// Read all key/values under a specified key.
+func (c *Consul) ReadAll(key string) (map[string]string, error) {
+	pairs, err := c.get(key, url.Values{"recurse": {""}})
+	if err != nil || len(pairs) == 0 {
+		return nil, err
+	}
+
+	// Keys are handed back the way they were given to us.
+	prefix := ""
+	if strings.HasPrefix(key, "/") {
+		prefix = "/"
+	}
+
+	kvs := make(map[string]string, len(pairs))
+	for _, p := range pairs {
+		kvs[prefix+p.Key] = string(p.Value)
+	}
+
+	return kvs, nil
+}
+
+// Updates a key's value.
+// This will overwrite an existing key if present.
+func (c *Consul) Update(key, value string) error {
+	_, err := c.put(key, value, nil)
+
+	return err
+}
+
+// Only replaces the value if it still matches the old one.
+// Reports whether the swap happened, a missing key never matches.
+func (c *Consul) CompareAndSwap(key, old, value string) (bool, error) {
+	pairs, err := c.get(key, nil)
+	if err != nil || len(pairs) == 0 || string(pairs[0].Value) != old {
+		return false, err
+	}
+
+	return c.put(key, value, url.Values{"cas": {strconv.FormatUint(pairs[0].ModifyIndex, 10)}})
+}
+
+// Updates a key's value only if it hasn't been modified since the expected version was read.
+// An expected version of 0 only creates the key.
+func (c *Consul) UpdateIf(key, value string, expectedVersion int64) error {
+	ok, err := c.put(key, value, url.Values{"cas": {strconv.FormatInt(expectedVersion, 10)}})
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return persistence.ErrVersionMismatch
+	}
+
+	return nil
+}
+
+// Deletes only the key, not the keys under it, if it hasn't been modified since the expected version was read.
+// An expected version of 0 only succeeds when the key is already gone.
+func (c *Consul) DeleteIf(key string, expectedVersion int64) error {
+	resp, err := c.do(http.MethodDelete, kvPath(key), url.Values{"cas": {strconv.FormatInt(expectedVersion, 10)}}, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	ok, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(string(ok)) != "true" {
+		return persistence.ErrVersionMismatch
+	}
+
+	return nil
+}
+
+// Refreshes a lease once.
+func (c *Consul) RefreshLease(id int64) error {
+	c.mu.Lock()
+	session, ok := c.sessions[id]
+	c.mu.Unlock()
+	if !ok {
+		return ErrLeaseExpired
+	}
+
+	resp, err := c.do(http.MethodPut, "/v1/session/renew/"+session, nil, nil)
+	if err == errNotFound {
+		c.mu.Lock()
+		delete(c.sessions, id)
+		c.mu.Unlock()
+
+		return ErrLeaseExpired
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// Deletes a key/value pair along with everything under it.
+func (c *Consul) Delete(key string) error {
+	resp, err := c.do(http.MethodDelete, kvPath(key), url.Values{"recurse": {""}}, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+var (
+	errNotFound = errors.New("Not found.")
+	errConflict = errors.New("Conflict.")
+)
+
+func (c *Consul) do(method, p string, query url.Values, body io.Reader) (*http.Response, error) {
+	u := c.address + p
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if c.token != "" {
+		req.Header.Set("X-Consul-Token", c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		resp.Body.Close()
+		return nil, errNotFound
+	case resp.StatusCode == http.StatusConflict:
+		resp.Body.Close()
+		return nil, errConflict
+	case resp.StatusCode >= 300:
+		msg, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, errors.New("Consul returned " + resp.Status + ": " + strings.TrimSpace(string(msg)))
+	}
+
+	return resp, nil
+}
+
+func (c *Consul) get(key string, query url.Values) ([]kvPair, error) {
+	resp, err := c.do(http.MethodGet, kvPath(key), query, nil)
+	if err == errNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var pairs []kvPair
+	if err := json.NewDecoder(resp.Body).Decode(&pairs); err != nil {
+		return nil, err
+	}
+
+	return pairs, nil
+}
+
+// Writes are answered with whether they were applied, CAS writes are refused when the index moved.
+func (c *Consul) put(key, value string, query url.Values) (bool, error) {
+	resp, err := c.do(http.MethodPut, kvPath(key), query, strings.NewReader(value))
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	ok, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return false, err
+	}
+
+	return strings.TrimSpace(string(ok)) == "true", nil
+}
+
+func (c *Consul) destroy(session string) {
+	if resp, err := c.do(http.MethodPut, "/v1/session/destroy/"+session, nil, nil); err == nil {
+		resp.Body.Close()
+	}
+}
+
+// Consul keys don't start with a slash.
+func trim(key string) string {
+	return strings.TrimPrefix(key, "/")
+}
+
+func kvPath(key string) string {
+	return "/v1/kv/" + (&url.URL{Path: trim(key)}).EscapedPath()
+}
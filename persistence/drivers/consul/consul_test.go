@@ -0,0 +1,336 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consul
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/verizonlabs/mesos-framework-sdk/persistence"
+)
+
+var _ persistence.Storage = (*Consul)(nil)
+
+type entry struct {
+	value   []byte
+	index   uint64
+	session string
+}
+
+// Serves the parts of the Consul HTTP API the driver uses from memory.
+type fakeConsul struct {
+	mu       sync.Mutex
+	kv       map[string]*entry
+	sessions map[string]string
+	index    uint64
+	session  int
+	fail     bool
+}
+
+func newFakeConsul(t *testing.T) (*fakeConsul, *Consul, func()) {
+	f := &fakeConsul{kv: make(map[string]*entry), sessions: make(map[string]string)}
+	ts := httptest.NewServer(f)
+
+	return f, NewClient(ts.URL+"/", "token", time.Second), ts.Close
+}
+
+func (f *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.fail {
+		http.Error(w, "rpc error", http.StatusInternalServerError)
+		return
+	}
+	if r.Header.Get("X-Consul-Token") != "token" {
+		http.Error(w, "ACL not found", http.StatusForbidden)
+		return
+	}
+
+	body, _ := ioutil.ReadAll(r.Body)
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/v1/kv/"):
+		f.kvHandler(w, r, strings.TrimPrefix(r.URL.Path, "/v1/kv/"), body)
+	case r.URL.Path == "/v1/session/create":
+		var session map[string]string
+		json.Unmarshal(body, &session)
+		f.session++
+		id := "session-" + strconv.Itoa(f.session)
+		f.sessions[id] = session["TTL"]
+		json.NewEncoder(w).Encode(map[string]string{"ID": id})
+	case strings.HasPrefix(r.URL.Path, "/v1/session/renew/"):
+		if _, ok := f.sessions[strings.TrimPrefix(r.URL.Path, "/v1/session/renew/")]; !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("[]"))
+	case strings.HasPrefix(r.URL.Path, "/v1/session/destroy/"):
+		f.expire(strings.TrimPrefix(r.URL.Path, "/v1/session/destroy/"))
+		w.Write([]byte("true"))
+	case r.URL.Path == "/v1/txn":
+		var ops []txnOp
+		json.Unmarshal(body, &ops)
+		for _, op := range ops {
+			if _, ok := f.kv[op.KV.Key]; ok && op.KV.Verb == "check-not-exists" {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+		}
+		for _, op := range ops {
+			if op.KV.Verb == "lock" {
+				f.index++
+				f.kv[op.KV.Key] = &entry{value: op.KV.Value, index: f.index, session: op.KV.Session}
+			}
+		}
+		w.Write([]byte("{}"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeConsul) kvHandler(w http.ResponseWriter, r *http.Request, key string, body []byte) {
+	_, recurse := r.URL.Query()["recurse"]
+	e, exists := f.kv[key]
+
+	// An index of 0 only matches missing keys.
+	cas := r.URL.Query().Get("cas")
+	index, _ := strconv.ParseUint(cas, 10, 64)
+	stale := cas != "" && ((index == 0 && exists) || (index != 0 && (!exists || e.index != index)))
+
+	switch r.Method {
+	case http.MethodGet:
+		var pairs []kvPair
+		for k, e := range f.kv {
+			if k == key || (recurse && strings.HasPrefix(k, key)) {
+				pairs = append(pairs, kvPair{Key: k, Value: e.value, ModifyIndex: e.index})
+			}
+		}
+		if len(pairs) == 0 {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(pairs)
+	case http.MethodPut:
+		if stale {
+			w.Write([]byte("false"))
+			return
+		}
+		f.index++
+		f.kv[key] = &entry{value: body, index: f.index}
+		w.Write([]byte("true"))
+	case http.MethodDelete:
+		if stale {
+			w.Write([]byte("false"))
+			return
+		}
+		if cas != "" {
+			delete(f.kv, key)
+			w.Write([]byte("true"))
+			return
+		}
+		for k := range f.kv {
+			if k == key || (recurse && strings.HasPrefix(k, key)) {
+				delete(f.kv, k)
+			}
+		}
+		w.Write([]byte("true"))
+	}
+}
+
+// Ends a session, deleting the keys it holds.
+func (f *fakeConsul) expire(session string) {
+	delete(f.sessions, session)
+	for k, e := range f.kv {
+		if e.session == session {
+			delete(f.kv, k)
+		}
+	}
+}
+
+// Ensures creates and conditional updates go through the CAS index.
+func TestConsul_CAS(t *testing.T) {
+	t.Parallel()
+
+	_, c, done := newFakeConsul(t)
+	defer done()
+
+	if err := c.Create("/tasks/a", "1"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := c.Create("/tasks/a", "2"); err != ErrKeyExists {
+		t.Fatal("Existing keys should not be overwritten by create")
+	}
+
+	value, version, err := c.ReadVersion("/tasks/a")
+	if err != nil || value != "1" || version == 0 {
+		t.Fatal("Existing keys should have their modify index as the version")
+	}
+	if err := c.UpdateIf("/tasks/a", "2", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := c.UpdateIf("/tasks/a", "3", version); err != persistence.ErrVersionMismatch {
+		t.Fatal("Stale indexes should be rejected")
+	}
+	if err := c.UpdateIf("/tasks/b", "1", 0); err != nil {
+		t.Fatal("An index of 0 should create missing keys")
+	}
+	if err := c.UpdateIf("/tasks/b", "2", 0); err != persistence.ErrVersionMismatch {
+		t.Fatal("An index of 0 should not overwrite existing keys")
+	}
+
+	if ok, err := c.CompareAndSwap("/tasks/a", "2", "3"); err != nil || !ok {
+		t.Fatal("Matching values should be swapped")
+	}
+	if ok, err := c.CompareAndSwap("/tasks/a", "2", "4"); err != nil || ok {
+		t.Fatal("Values that don't match should not be swapped")
+	}
+
+	if err := c.Update("/tasks/c", "1"); err != nil {
+		t.Fatal(err.Error())
+	}
+	kvs, err := c.ReadAll("/tasks")
+	if err != nil || len(kvs) != 3 || kvs["/tasks/a"] != "3" || kvs["/tasks/c"] != "1" {
+		t.Fatal("Keys under the prefix should be read back the way they were given")
+	}
+	if kvs, _ = c.ReadAll("tasks"); kvs["tasks/a"] != "3" {
+		t.Fatal("Relative prefixes should return relative keys")
+	}
+
+	c.Update("/tasks/ab", "1")
+	_, version, _ = c.ReadVersion("/tasks/a")
+	if err := c.DeleteIf("/tasks/a", version-1); err != persistence.ErrVersionMismatch {
+		t.Fatal("Stale indexes should not delete")
+	}
+	if err := c.DeleteIf("/tasks/a", 0); err != persistence.ErrVersionMismatch {
+		t.Fatal("An index of 0 should not delete existing keys")
+	}
+	if err := c.DeleteIf("/tasks/a", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	if value, _ := c.Read("/tasks/a"); value != "" {
+		t.Fatal("Matching indexes should delete the key")
+	}
+	if value, _ := c.Read("/tasks/ab"); value != "1" {
+		t.Fatal("Conditional deletes should only remove the exact key")
+	}
+	if err := c.DeleteIf("/tasks/a", 0); err != nil {
+		t.Fatal("An index of 0 should match missing keys")
+	}
+}
+
+// Measures performance of conditional updates.
+func BenchmarkConsul_UpdateIf(b *testing.B) {
+	_, c, done := newFakeConsul(&testing.T{})
+	defer done()
+	c.Update("/tasks/a", "1")
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		_, version, _ := c.ReadVersion("/tasks/a")
+		c.UpdateIf("/tasks/a", "1", version)
+	}
+}
+
+// Ensures leases are backed by sessions that hold their keys.
+func TestConsul_Leases(t *testing.T) {
+	t.Parallel()
+
+	f, c, done := newFakeConsul(t)
+	defer done()
+
+	id, err := c.CreateWithLease("/leader", "me", 1)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if f.sessions["session-1"] != strconv.Itoa(MIN_SESSION_TTL)+"s" {
+		t.Fatal("Session TTLs should be raised to the minimum Consul accepts")
+	}
+	if value, _ := c.Read("/leader"); value != "me" {
+		t.Fatal("Leased key should have been written")
+	}
+	if err := c.RefreshLease(id); err != nil {
+		t.Fatal("Live sessions should be renewed: " + err.Error())
+	}
+
+	if _, err := c.CreateWithLease("/leader", "you", MAX_SESSION_TTL+1); err != ErrKeyExists {
+		t.Fatal("Leased keys should not overwrite existing keys")
+	}
+	if _, ok := f.sessions["session-2"]; ok {
+		t.Fatal("The session of a failed lease should be destroyed")
+	}
+
+	f.mu.Lock()
+	f.expire("session-1")
+	f.mu.Unlock()
+
+	if err := c.RefreshLease(id); err != ErrLeaseExpired {
+		t.Fatal("Expired sessions should expire the lease")
+	}
+	if err := c.RefreshLease(id); err != ErrLeaseExpired {
+		t.Fatal("Forgotten leases should stay expired")
+	}
+	if value, _ := c.Read("/leader"); value != "" {
+		t.Fatal("Keys should be deleted with their session")
+	}
+}
+
+// Ensures missing keys aren't errors while other failures are.
+func TestConsul_NotFound(t *testing.T) {
+	t.Parallel()
+
+	f, c, done := newFakeConsul(t)
+	defer done()
+
+	if value, err := c.Read("/missing"); err != nil || value != "" {
+		t.Fatal("Missing keys should read as empty")
+	}
+	if _, version, err := c.ReadVersion("/missing"); err != nil || version != 0 {
+		t.Fatal("Missing keys should have a version of 0")
+	}
+	if kvs, err := c.ReadAll("/missing"); err != nil || kvs != nil {
+		t.Fatal("Missing prefixes should return nothing")
+	}
+	if ok, err := c.CompareAndSwap("/missing", "", "1"); err != nil || ok {
+		t.Fatal("Missing keys should never be swapped")
+	}
+
+	c.Update("/tasks/a", "1")
+	c.Update("/tasks/b/c", "1")
+	c.Update("/other", "1")
+	if err := c.Delete("/tasks"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(f.kv) != 1 {
+		t.Fatal("Deletes should remove everything under the key")
+	}
+
+	f.mu.Lock()
+	f.fail = true
+	f.mu.Unlock()
+
+	if _, err := c.Read("/other"); err == nil || !strings.Contains(err.Error(), "rpc error") {
+		t.Fatal("Server errors should be returned with their message")
+	}
+	if err := c.Update("/other", "2"); err == nil {
+		t.Fatal("Failed writes should be returned")
+	}
+}
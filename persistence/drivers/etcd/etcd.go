@@ -16,43 +16,128 @@ package etcd
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
 	"runtime"
+	"sync"
 	"time"
 
 	etcd "github.com/coreos/etcd/clientv3"
+	"github.com/verizonlabs/mesos-framework-sdk/persistence"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/keepalive"
 )
 
+// The parts of the etcd client we use, tests swap in a fake.
+type client interface {
+	Get(ctx context.Context, key string, opts ...etcd.OpOption) (*etcd.GetResponse, error)
+	Put(ctx context.Context, key, val string, opts ...etcd.OpOption) (*etcd.PutResponse, error)
+	Delete(ctx context.Context, key string, opts ...etcd.OpOption) (*etcd.DeleteResponse, error)
+	Txn(ctx context.Context) etcd.Txn
+	Grant(ctx context.Context, ttl int64) (*etcd.LeaseGrantResponse, error)
+	Revoke(ctx context.Context, id etcd.LeaseID) (*etcd.LeaseRevokeResponse, error)
+	TimeToLive(ctx context.Context, id etcd.LeaseID, opts ...etcd.LeaseOption) (*etcd.LeaseTimeToLiveResponse, error)
+	KeepAlive(ctx context.Context, id etcd.LeaseID) (<-chan *etcd.LeaseKeepAliveResponse, error)
+	KeepAliveOnce(ctx context.Context, id etcd.LeaseID) (*etcd.LeaseKeepAliveResponse, error)
+	Watch(ctx context.Context, key string, opts ...etcd.OpOption) etcd.WatchChan
+	Close() error
+}
+
 type Etcd struct {
-	client     *etcd.Client
+	client     client
 	ctxTimeout time.Duration
+	mu         sync.Mutex
+	locks      map[string]lock
+}
+
+// How to reach and authenticate against an etcd cluster.
+// Client certificates and the CA bundle are read from PEM files, TLS is only used when any of them are given.
+type Config struct {
+	Endpoints        []string
+	Timeout          time.Duration
+	KeepaliveTime    time.Duration
+	KeepaliveTimeout time.Duration
+	CertFile         string
+	KeyFile          string
+	CAFile           string
+	Username         string
+	Password         string
 }
 
 // Creates a new etcd client with the specified configuration.
-func NewClient(endpoints []string, timeout, kaTime, kaTimeout time.Duration) *Etcd {
-	client, err := etcd.New(etcd.Config{
-		Endpoints:   endpoints,
-		DialTimeout: timeout,
+func NewClient(config Config) (*Etcd, error) {
+	etcdConfig, err := config.etcd()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := etcd.New(etcdConfig)
+	if err != nil {
+		return nil, errors.New("Failed to create etcd client: " + err.Error())
+	}
+
+	c := &Etcd{
+		client:     client,
+		ctxTimeout: config.Timeout,
+		locks:      make(map[string]lock),
+	}
+	runtime.SetFinalizer(c, c.finalizer)
+
+	return c, nil
+}
+
+// Builds the client's configuration, loading the TLS files if there are any.
+func (c Config) etcd() (etcd.Config, error) {
+	tlsConfig, err := c.tls()
+	if err != nil {
+		return etcd.Config{}, err
+	}
+
+	return etcd.Config{
+		Endpoints:   c.Endpoints,
+		DialTimeout: c.Timeout,
 		DialOptions: []grpc.DialOption{
 			grpc.WithKeepaliveParams(keepalive.ClientParameters{
-				Time:                kaTime,
-				Timeout:             kaTimeout,
+				Time:                c.KeepaliveTime,
+				Timeout:             c.KeepaliveTimeout,
 				PermitWithoutStream: true,
 			}),
 		},
-	})
-	if err != nil {
-		panic("Failed to create etcd client: " + err.Error())
+		TLS:      tlsConfig,
+		Username: c.Username,
+		Password: c.Password,
+	}, nil
+}
+
+func (c Config) tls() (*tls.Config, error) {
+	if c.CertFile == "" && c.KeyFile == "" && c.CAFile == "" {
+		return nil, nil
 	}
 
-	c := &Etcd{
-		client:     client,
-		ctxTimeout: timeout,
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, errors.New("Failed to load the etcd client certificate: " + err.Error())
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if c.CAFile != "" {
+		ca, err := ioutil.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, errors.New("Failed to read the etcd CA bundle: " + err.Error())
+		}
+
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, errors.New("No certificates found in the etcd CA bundle.")
+		}
 	}
-	runtime.SetFinalizer(c, c.finalizer)
 
-	return c
+	return config, nil
 }
 
 // Close the connection once we're GCed.
@@ -117,6 +202,24 @@ func (e *Etcd) Read(key string) (string, error) {
 	return "", nil
 }
 
+// Reads a key's value along with the revision it was last modified at.
+// Missing keys have a version of 0.
+func (e *Etcd) ReadVersion(key string) (string, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), e.ctxTimeout)
+	defer cancel()
+
+	resp, err := e.client.Get(ctx, key)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if len(resp.Kvs) > 0 {
+		return string(resp.Kvs[0].Value), resp.Kvs[0].ModRevision, nil
+	}
+
+	return "", 0, nil
+}
+
 // Read all key/values under a specified key.
 func (e *Etcd) ReadAll(key string) (map[string]string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), e.ctxTimeout)
@@ -139,6 +242,39 @@ func (e *Etcd) ReadAll(key string) (map[string]string, error) {
 	return nil, nil
 }
 
+// Reads at most limit key/values under the prefix, starting from the start key or the beginning of the prefix.
+// The returned key starts the next page and is empty once there's nothing left.
+func (e *Etcd) ReadAllPaged(prefix string, limit int64, startKey string) (map[string]string, string, error) {
+	if startKey == "" {
+		startKey = prefix
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.ctxTimeout)
+	defer cancel()
+
+	resp, err := e.client.Get(ctx, startKey,
+		etcd.WithRange(etcd.GetPrefixRangeEnd(prefix)),
+		etcd.WithSort(etcd.SortByKey, etcd.SortAscend),
+		etcd.WithLimit(limit),
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	kvs := make(map[string]string, len(resp.Kvs))
+	for _, value := range resp.Kvs {
+		kvs[string(value.Key)] = string(value.Value)
+	}
+
+	next := ""
+	if resp.More && len(resp.Kvs) > 0 {
+		// The smallest key after the last one we read.
+		next = string(resp.Kvs[len(resp.Kvs)-1].Key) + "\x00"
+	}
+
+	return kvs, next, nil
+}
+
 // Updates a key's value.
 // This will overwrite an existing key if present.
 func (e *Etcd) Update(key, value string) error {
@@ -150,6 +286,50 @@ func (e *Etcd) Update(key, value string) error {
 	return err
 }
 
+// Updates a key's value only if it hasn't been modified since the expected version was read.
+// An expected version of 0 only creates the key.
+func (e *Etcd) UpdateIf(key, value string, expectedVersion int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), e.ctxTimeout)
+	defer cancel()
+
+	cmp := etcd.Compare(etcd.ModRevision(key), "=", expectedVersion)
+	if expectedVersion == 0 {
+		cmp = etcd.Compare(etcd.Version(key), "=", 0)
+	}
+
+	resp, err := e.client.Txn(ctx).If(cmp).Then(etcd.OpPut(key, value)).Commit()
+	if err != nil {
+		return err
+	}
+	if !resp.Succeeded {
+		return persistence.ErrVersionMismatch
+	}
+
+	return nil
+}
+
+// Deletes only the key, not the keys it prefixes, if it hasn't been modified since the expected version was read.
+// An expected version of 0 only succeeds when the key is already gone.
+func (e *Etcd) DeleteIf(key string, expectedVersion int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), e.ctxTimeout)
+	defer cancel()
+
+	cmp := etcd.Compare(etcd.ModRevision(key), "=", expectedVersion)
+	if expectedVersion == 0 {
+		cmp = etcd.Compare(etcd.Version(key), "=", 0)
+	}
+
+	resp, err := e.client.Txn(ctx).If(cmp).Then(etcd.OpDelete(key)).Commit()
+	if err != nil {
+		return err
+	}
+	if !resp.Succeeded {
+		return persistence.ErrVersionMismatch
+	}
+
+	return nil
+}
+
 // Refreshes a lease once.
 func (e *Etcd) RefreshLease(id int64) error {
 	ctx, cancel := context.WithTimeout(context.Background(), e.ctxTimeout)
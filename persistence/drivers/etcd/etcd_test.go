@@ -0,0 +1,777 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	etcd "github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/etcdserver/api/v3rpc/rpctypes"
+	pb "github.com/coreos/etcd/etcdserver/etcdserverpb"
+	"github.com/coreos/etcd/mvcc/mvccpb"
+	"github.com/verizonlabs/mesos-framework-sdk/persistence"
+)
+
+type fakeLease struct {
+	ttl  int64
+	keys map[string]struct{}
+}
+
+type fakeWatcher struct {
+	key, end []byte
+	ch       chan etcd.WatchResponse
+}
+
+// Keeps keys, revisions and leases in memory the way etcd does.
+type fakeClient struct {
+	mu       sync.Mutex
+	rev      int64
+	kvs      map[string]*mvccpb.KeyValue
+	leases   map[etcd.LeaseID]*fakeLease
+	lease    etcd.LeaseID
+	watchers map[*fakeWatcher]struct{}
+	err      error
+
+	// Reads stop after this many keys since the limit they ask for can't be read back out of the options.
+	page int64
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		kvs:      make(map[string]*mvccpb.KeyValue),
+		leases:   make(map[etcd.LeaseID]*fakeLease),
+		watchers: make(map[*fakeWatcher]struct{}),
+	}
+}
+
+func newFake() (*Etcd, *fakeClient) {
+	client := newFakeClient()
+
+	return newEtcd(client), client
+}
+
+// Several drivers can share a fake client to act as separate clients of the same cluster.
+func newEtcd(client *fakeClient) *Etcd {
+	return &Etcd{client: client, ctxTimeout: time.Second, locks: make(map[string]lock)}
+}
+
+// Reports whether the key is in the range, an empty end matches only the key itself.
+func inRange(key, start, end []byte) bool {
+	if len(end) == 0 {
+		return bytes.Equal(key, start)
+	}
+	if bytes.Equal(end, []byte{0}) {
+		return bytes.Compare(key, start) >= 0
+	}
+
+	return bytes.Compare(key, start) >= 0 && bytes.Compare(key, end) < 0
+}
+
+func (f *fakeClient) keys(start, end []byte) []string {
+	var keys []string
+	for k := range f.kvs {
+		if inRange([]byte(k), start, end) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (f *fakeClient) notify(ev *mvccpb.Event) {
+	for w := range f.watchers {
+		if inRange(ev.Kv.Key, w.key, w.end) {
+			select {
+			case w.ch <- etcd.WatchResponse{Events: []*etcd.Event{(*etcd.Event)(ev)}}:
+			default:
+			}
+		}
+	}
+}
+
+// The options aren't readable so the lease is found by rebuilding the put with each lease we've granted.
+func (f *fakeClient) leaseOf(op etcd.Op) *fakeLease {
+	for id, l := range f.leases {
+		if reflect.DeepEqual(op, etcd.OpPut(string(op.KeyBytes()), string(op.ValueBytes()), etcd.WithLease(id))) {
+			return l
+		}
+	}
+
+	return nil
+}
+
+func (f *fakeClient) put(op etcd.Op) {
+	f.rev++
+	key := string(op.KeyBytes())
+	kv, ok := f.kvs[key]
+	if !ok {
+		kv = &mvccpb.KeyValue{Key: op.KeyBytes(), CreateRevision: f.rev}
+		f.kvs[key] = kv
+	}
+	kv.Value, kv.ModRevision = op.ValueBytes(), f.rev
+	kv.Version++
+
+	if l := f.leaseOf(op); l != nil {
+		l.keys[key] = struct{}{}
+	}
+	f.notify(&mvccpb.Event{Type: mvccpb.PUT, Kv: &mvccpb.KeyValue{Key: kv.Key, Value: kv.Value, ModRevision: kv.ModRevision}})
+}
+
+func (f *fakeClient) delete(start, end []byte) int64 {
+	keys := f.keys(start, end)
+	if len(keys) == 0 {
+		return 0
+	}
+
+	f.rev++
+	for _, k := range keys {
+		delete(f.kvs, k)
+		f.notify(&mvccpb.Event{Type: mvccpb.DELETE, Kv: &mvccpb.KeyValue{Key: []byte(k), ModRevision: f.rev}})
+	}
+
+	return int64(len(keys))
+}
+
+func (f *fakeClient) compare(c etcd.Cmp) bool {
+	kv, ok := f.kvs[string(c.Key)]
+	if !ok {
+		kv = &mvccpb.KeyValue{}
+	}
+
+	var result int
+	switch u := c.TargetUnion.(type) {
+	case *pb.Compare_Version:
+		result = compareInt(kv.Version, u.Version)
+	case *pb.Compare_CreateRevision:
+		result = compareInt(kv.CreateRevision, u.CreateRevision)
+	case *pb.Compare_ModRevision:
+		result = compareInt(kv.ModRevision, u.ModRevision)
+	case *pb.Compare_Value:
+		if !ok {
+			return false
+		}
+		result = bytes.Compare(kv.Value, u.Value)
+	default:
+		return false
+	}
+
+	switch c.Result {
+	case pb.Compare_EQUAL:
+		return result == 0
+	case pb.Compare_NOT_EQUAL:
+		return result != 0
+	case pb.Compare_GREATER:
+		return result > 0
+	case pb.Compare_LESS:
+		return result < 0
+	}
+
+	return false
+}
+
+func compareInt(a, b int64) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+
+	return 0
+}
+
+func (f *fakeClient) Get(ctx context.Context, key string, opts ...etcd.OpOption) (*etcd.GetResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	op := etcd.OpGet(key, opts...)
+	resp := &etcd.GetResponse{}
+	for _, k := range f.keys(op.KeyBytes(), op.RangeBytes()) {
+		if f.page > 0 && int64(len(resp.Kvs)) == f.page {
+			resp.More = true
+			break
+		}
+		kv := *f.kvs[k]
+		resp.Kvs = append(resp.Kvs, &kv)
+	}
+
+	return resp, nil
+}
+
+func (f *fakeClient) Put(ctx context.Context, key, val string, opts ...etcd.OpOption) (*etcd.PutResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	f.put(etcd.OpPut(key, val, opts...))
+
+	return &etcd.PutResponse{}, nil
+}
+
+func (f *fakeClient) Delete(ctx context.Context, key string, opts ...etcd.OpOption) (*etcd.DeleteResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	op := etcd.OpDelete(key, opts...)
+
+	return &etcd.DeleteResponse{Deleted: f.delete(op.KeyBytes(), op.RangeBytes())}, nil
+}
+
+type fakeTxn struct {
+	f    *fakeClient
+	cmps []etcd.Cmp
+	then []etcd.Op
+	els  []etcd.Op
+}
+
+func (t *fakeTxn) If(cs ...etcd.Cmp) etcd.Txn {
+	t.cmps = append(t.cmps, cs...)
+	return t
+}
+
+func (t *fakeTxn) Then(ops ...etcd.Op) etcd.Txn {
+	t.then = append(t.then, ops...)
+	return t
+}
+
+func (t *fakeTxn) Else(ops ...etcd.Op) etcd.Txn {
+	t.els = append(t.els, ops...)
+	return t
+}
+
+func (t *fakeTxn) Commit() (*etcd.TxnResponse, error) {
+	t.f.mu.Lock()
+	defer t.f.mu.Unlock()
+	if t.f.err != nil {
+		return nil, t.f.err
+	}
+
+	succeeded := true
+	for _, c := range t.cmps {
+		if !t.f.compare(c) {
+			succeeded = false
+		}
+	}
+
+	ops := t.then
+	if !succeeded {
+		ops = t.els
+	}
+	for _, op := range ops {
+		if op.IsPut() {
+			t.f.put(op)
+		} else if op.IsDelete() {
+			t.f.delete(op.KeyBytes(), op.RangeBytes())
+		}
+	}
+
+	return &etcd.TxnResponse{Succeeded: succeeded}, nil
+}
+
+func (f *fakeClient) Txn(ctx context.Context) etcd.Txn {
+	return &fakeTxn{f: f}
+}
+
+func (f *fakeClient) Grant(ctx context.Context, ttl int64) (*etcd.LeaseGrantResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	f.lease++
+	f.leases[f.lease] = &fakeLease{ttl: ttl, keys: make(map[string]struct{})}
+
+	return &etcd.LeaseGrantResponse{ID: f.lease, TTL: ttl}, nil
+}
+
+// Drops the lease and the keys attached to it, as etcd does once a lease is revoked or expires.
+func (f *fakeClient) expire(id etcd.LeaseID) bool {
+	l, ok := f.leases[id]
+	if !ok {
+		return false
+	}
+
+	delete(f.leases, id)
+	for k := range l.keys {
+		if _, ok := f.kvs[k]; ok {
+			f.delete([]byte(k), nil)
+		}
+	}
+
+	return true
+}
+
+func (f *fakeClient) Revoke(ctx context.Context, id etcd.LeaseID) (*etcd.LeaseRevokeResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	if !f.expire(id) {
+		return nil, rpctypes.ErrLeaseNotFound
+	}
+
+	return &etcd.LeaseRevokeResponse{}, nil
+}
+
+func (f *fakeClient) TimeToLive(ctx context.Context, id etcd.LeaseID, opts ...etcd.LeaseOption) (*etcd.LeaseTimeToLiveResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	l, ok := f.leases[id]
+	if !ok {
+		return &etcd.LeaseTimeToLiveResponse{ID: id, TTL: -1}, nil
+	}
+
+	return &etcd.LeaseTimeToLiveResponse{ID: id, TTL: l.ttl, GrantedTTL: l.ttl}, nil
+}
+
+func (f *fakeClient) KeepAlive(ctx context.Context, id etcd.LeaseID) (<-chan *etcd.LeaseKeepAliveResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	if _, ok := f.leases[id]; !ok {
+		return nil, rpctypes.ErrLeaseNotFound
+	}
+
+	ch := make(chan *etcd.LeaseKeepAliveResponse)
+	go func() {
+		<-ctx.Done()
+		close(ch)
+	}()
+
+	return ch, nil
+}
+
+func (f *fakeClient) KeepAliveOnce(ctx context.Context, id etcd.LeaseID) (*etcd.LeaseKeepAliveResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	l, ok := f.leases[id]
+	if !ok {
+		return nil, rpctypes.ErrLeaseNotFound
+	}
+
+	return &etcd.LeaseKeepAliveResponse{ID: id, TTL: l.ttl}, nil
+}
+
+// Events are buffered and dropped once the buffer is full so writes never wait on a watcher.
+// The channel closes once the context is done.
+func (f *fakeClient) Watch(ctx context.Context, key string, opts ...etcd.OpOption) etcd.WatchChan {
+	op := etcd.OpGet(key, opts...)
+	w := &fakeWatcher{key: op.KeyBytes(), end: op.RangeBytes(), ch: make(chan etcd.WatchResponse, 64)}
+
+	f.mu.Lock()
+	f.watchers[w] = struct{}{}
+	f.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		f.mu.Lock()
+		delete(f.watchers, w)
+		close(w.ch)
+		f.mu.Unlock()
+	}()
+
+	return w.ch
+}
+
+func (f *fakeClient) Close() error {
+	return nil
+}
+
+// Ensures creating a key never overwrites an existing one.
+func TestEtcd_Create(t *testing.T) {
+	t.Parallel()
+
+	e, _ := newFake()
+	if err := e.Create("key", "a"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := e.Create("key", "b"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if value, err := e.Read("key"); err != nil || value != "a" {
+		t.Fatal("Creating an existing key shouldn't overwrite it")
+	}
+}
+
+// Measures performance of creating a key.
+func BenchmarkEtcd_Create(b *testing.B) {
+	e, _ := newFake()
+	for n := 0; n < b.N; n++ {
+		e.Create("key", "value")
+	}
+}
+
+// Ensures the version tracks every write, even ones that set the same value again.
+func TestEtcd_ReadVersion(t *testing.T) {
+	t.Parallel()
+
+	e, _ := newFake()
+	if value, version, err := e.ReadVersion("key"); err != nil || value != "" || version != 0 {
+		t.Fatal("Missing keys should have a version of 0")
+	}
+
+	e.Create("key", "a")
+	value, first, err := e.ReadVersion("key")
+	if err != nil || value != "a" || first == 0 {
+		t.Fatal("Created keys should have a version")
+	}
+
+	e.Update("key", "a")
+	if _, second, _ := e.ReadVersion("key"); second == first {
+		t.Fatal("Writing the same value again should still change the version")
+	}
+}
+
+// Ensures updates only apply against the version they were read at.
+func TestEtcd_UpdateIf(t *testing.T) {
+	t.Parallel()
+
+	e, _ := newFake()
+	if err := e.UpdateIf("key", "a", 0); err != nil {
+		t.Fatal("A version of 0 should create the missing key: " + err.Error())
+	}
+	if err := e.UpdateIf("key", "b", 0); err != persistence.ErrVersionMismatch {
+		t.Fatalf("A version of 0 shouldn't overwrite an existing key, got %v", err)
+	}
+
+	_, version, _ := e.ReadVersion("key")
+	if err := e.UpdateIf("key", "b", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := e.UpdateIf("key", "c", version); err != persistence.ErrVersionMismatch {
+		t.Fatalf("Stale versions should be rejected, got %v", err)
+	}
+
+	_, version, _ = e.ReadVersion("key")
+	e.Update("key", "b")
+	if err := e.UpdateIf("key", "c", version); err != persistence.ErrVersionMismatch {
+		t.Fatalf("Versions should change even when the same value is written back, got %v", err)
+	}
+	if value, _ := e.Read("key"); value != "b" {
+		t.Fatal("Rejected updates shouldn't be applied")
+	}
+}
+
+// Measures performance of a versioned update.
+func BenchmarkEtcd_UpdateIf(b *testing.B) {
+	e, _ := newFake()
+	e.Create("key", "value")
+	for n := 0; n < b.N; n++ {
+		_, version, _ := e.ReadVersion("key")
+		e.UpdateIf("key", "value", version)
+	}
+}
+
+// Ensures versioned deletes only remove the exact key at the version it was read at.
+func TestEtcd_DeleteIf(t *testing.T) {
+	t.Parallel()
+
+	e, _ := newFake()
+	if err := e.DeleteIf("key", 0); err != nil {
+		t.Fatal("A version of 0 should succeed when the key is already gone")
+	}
+
+	e.Create("key", "a")
+	e.Create("key/child", "b")
+	if err := e.DeleteIf("key", 0); err != persistence.ErrVersionMismatch {
+		t.Fatalf("A version of 0 shouldn't delete an existing key, got %v", err)
+	}
+
+	_, version, _ := e.ReadVersion("key")
+	e.Update("key", "a")
+	if err := e.DeleteIf("key", version); err != persistence.ErrVersionMismatch {
+		t.Fatalf("Stale versions should be rejected, got %v", err)
+	}
+
+	_, version, _ = e.ReadVersion("key")
+	if err := e.DeleteIf("key", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	if _, version, _ := e.ReadVersion("key"); version != 0 {
+		t.Fatal("The key should be deleted")
+	}
+	if value, _ := e.Read("key/child"); value != "b" {
+		t.Fatal("Keys under the deleted key should be left alone")
+	}
+}
+
+// Ensures deletes remove the key and every key under it.
+func TestEtcd_Delete(t *testing.T) {
+	t.Parallel()
+
+	e, _ := newFake()
+	e.Create("key", "a")
+	e.Create("key/child", "b")
+	e.Create("other", "c")
+
+	if err := e.Delete("key"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if kvs, _ := e.ReadAll("key"); len(kvs) != 0 {
+		t.Fatal("Keys under the deleted key should be removed too")
+	}
+	if value, _ := e.Read("other"); value != "c" {
+		t.Fatal("Other keys should be left alone")
+	}
+}
+
+// Ensures leased keys are created once and refreshing needs a live lease.
+func TestEtcd_CreateWithLease(t *testing.T) {
+	t.Parallel()
+
+	e, client := newFake()
+	id, err := e.CreateWithLease("key", "a", 10)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if value, _ := e.Read("key"); value != "a" {
+		t.Fatal("Leased key was not created")
+	}
+	if err := e.RefreshLease(id); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	client.mu.Lock()
+	client.expire(etcd.LeaseID(id))
+	client.mu.Unlock()
+
+	if value, _ := e.Read("key"); value != "" {
+		t.Fatal("The key should go away with its lease")
+	}
+	if err := e.RefreshLease(id); err == nil {
+		t.Fatal("Expired leases can't be refreshed")
+	}
+}
+
+// Writes a self-signed certificate and its key as PEM files, returning their paths.
+func writeCert(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "etcd"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	return certFile, keyFile
+}
+
+// Ensures TLS is only set up when files are given and bad files are reported.
+func TestConfig_TLS(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "etcd")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile := writeCert(t, dir)
+	empty := filepath.Join(dir, "empty.pem")
+	if err := ioutil.WriteFile(empty, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err.Error())
+	}
+	missing := filepath.Join(dir, "missing.pem")
+
+	tests := []struct {
+		name   string
+		config Config
+		certs  int
+		ca     bool
+		fail   bool
+	}{
+		{"none", Config{}, 0, false, false},
+		{"client certificate", Config{CertFile: certFile, KeyFile: keyFile}, 1, false, false},
+		{"ca", Config{CAFile: certFile}, 0, true, false},
+		{"both", Config{CertFile: certFile, KeyFile: keyFile, CAFile: certFile}, 1, true, false},
+		{"no key", Config{CertFile: certFile}, 0, false, true},
+		{"no certificate", Config{KeyFile: keyFile}, 0, false, true},
+		{"missing ca", Config{CAFile: missing}, 0, false, true},
+		{"empty ca", Config{CAFile: empty}, 0, false, true},
+	}
+
+	for _, test := range tests {
+		config, err := test.config.tls()
+		if (err != nil) != test.fail {
+			t.Fatalf("%s: unexpected error state: %v", test.name, err)
+		}
+		if err != nil {
+			continue
+		}
+		if test.name == "none" {
+			if config != nil {
+				t.Fatal("TLS shouldn't be used without any files")
+			}
+			continue
+		}
+		if config.MinVersion != tls.VersionTLS12 {
+			t.Fatal(test.name + ": TLS 1.2 should be the minimum")
+		}
+		if len(config.Certificates) != test.certs || (config.RootCAs != nil) != test.ca {
+			t.Fatal(test.name + ": unexpected certificates")
+		}
+	}
+}
+
+// Measures performance of building the client's configuration.
+func BenchmarkConfig_Etcd(b *testing.B) {
+	config := Config{Endpoints: []string{"localhost:2379"}, Timeout: time.Second}
+	for n := 0; n < b.N; n++ {
+		config.etcd()
+	}
+}
+
+// Ensures the endpoints, timeouts and credentials are handed to the client.
+func TestConfig_Etcd(t *testing.T) {
+	t.Parallel()
+
+	config, err := Config{
+		Endpoints: []string{"localhost:2379"},
+		Timeout:   time.Second,
+		Username:  "user",
+		Password:  "pass",
+	}.etcd()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(config.Endpoints) != 1 || config.DialTimeout != time.Second || len(config.DialOptions) != 1 {
+		t.Fatal("Endpoints and timeouts were not carried over")
+	}
+	if config.Username != "user" || config.Password != "pass" || config.TLS != nil {
+		t.Fatal("Credentials were not carried over")
+	}
+
+	if _, err := (Config{CAFile: "missing.pem"}).etcd(); err == nil {
+		t.Fatal("TLS errors should be reported")
+	}
+	if _, err := NewClient(Config{CAFile: "missing.pem"}); err == nil {
+		t.Fatal("Clients shouldn't be created with broken TLS")
+	}
+}
+
+// Ensures paging walks every key under the prefix once and stops with an empty continuation key.
+func TestEtcd_ReadAllPaged(t *testing.T) {
+	t.Parallel()
+
+	e, client := newFake()
+	for _, k := range []string{"p/a", "p/b", "p/b/c", "p/d", "p/e", "p", "q/a"} {
+		e.Create(k, k)
+	}
+	client.page = 2
+
+	pages := [][]string{{"p/a", "p/b"}, {"p/b/c", "p/d"}, {"p/e"}}
+	start := ""
+	for i, page := range pages {
+		kvs, next, err := e.ReadAllPaged("p/", 2, start)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if len(kvs) != len(page) {
+			t.Fatalf("Page %d: expected %v, got %v", i, page, kvs)
+		}
+		for _, k := range page {
+			if kvs[k] != k {
+				t.Fatalf("Page %d: expected %v, got %v", i, page, kvs)
+			}
+		}
+
+		last := i == len(pages)-1
+		if last != (next == "") {
+			t.Fatalf("Page %d: unexpected continuation key %q", i, next)
+		}
+		if !last && next != page[len(page)-1]+"\x00" {
+			t.Fatalf("Page %d: the next page should start right after the last key, got %q", i, next)
+		}
+		start = next
+	}
+
+	kvs, next, err := e.ReadAllPaged("missing/", 2, "")
+	if err != nil || len(kvs) != 0 || next != "" {
+		t.Fatal("Empty prefixes should return a single empty page")
+	}
+}
+
+// Measures performance of reading a page.
+func BenchmarkEtcd_ReadAllPaged(b *testing.B) {
+	e, client := newFake()
+	for _, k := range []string{"p/a", "p/b", "p/c"} {
+		e.Create(k, k)
+	}
+	client.page = 2
+
+	for n := 0; n < b.N; n++ {
+		e.ReadAllPaged("p/", 2, "")
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	etcd "github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/etcdserver/api/v3rpc/rpctypes"
+)
+
+var ErrLeaseLost = errors.New("The lease expired before it could be refreshed.")
+
+// Refreshes the lease at a third of its TTL, give or take a bit of jitter so clients don't refresh in lockstep.
+// Failed refreshes are sent on the channel and retried on the next tick.
+// If the lease is gone ErrLeaseLost is sent and the channel is closed, which also happens once stop is called.
+func (e *Etcd) KeepAlive(id int64) (stop func(), errs <-chan error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan error, 1)
+
+	var once sync.Once
+	stop = func() {
+		once.Do(cancel)
+	}
+
+	go func() {
+		defer close(ch)
+
+		ttl, err := e.leaseTTL(id)
+		for {
+			if err == rpctypes.ErrLeaseNotFound || err == ErrLeaseLost {
+				report(ctx, ch, ErrLeaseLost, true)
+				return
+			}
+			if err != nil {
+				report(ctx, ch, err, false)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(jitter(ttl)):
+			}
+
+			if ttl == 0 {
+				// We never found out the TTL so keep asking before refreshing.
+				ttl, err = e.leaseTTL(id)
+				continue
+			}
+			err = e.RefreshLease(id)
+		}
+	}()
+
+	return stop, ch
+}
+
+// Gets the TTL the lease was granted with.
+func (e *Etcd) leaseTTL(id int64) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), e.ctxTimeout)
+	defer cancel()
+
+	resp, err := e.client.TimeToLive(ctx, etcd.LeaseID(id))
+	if err != nil {
+		return 0, err
+	}
+	if resp.TTL <= 0 {
+		return 0, ErrLeaseLost
+	}
+
+	return time.Duration(resp.GrantedTTL) * time.Second, nil
+}
+
+// A third of the TTL, plus or minus 10%.
+// Unknown TTLs are asked for again after a second.
+func jitter(ttl time.Duration) time.Duration {
+	if ttl == 0 {
+		return time.Second
+	}
+
+	interval := ttl / 3
+	return interval - interval/10 + time.Duration(rand.Int63n(int64(interval/5)+1))
+}
+
+// Lease lost errors always get through, other errors are dropped if the last one hasn't been read yet.
+func report(ctx context.Context, ch chan error, err error, block bool) {
+	if !block {
+		select {
+		case ch <- err:
+		default:
+		}
+		return
+	}
+
+	select {
+	case ch <- err:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	etcd "github.com/coreos/etcd/clientv3"
+)
+
+// Waits for the next error, a nil error means the channel was closed.
+func nextErr(t *testing.T, errs <-chan error) (error, bool) {
+	select {
+	case err, ok := <-errs:
+		return err, ok
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting on the keepalive")
+	}
+
+	return nil, false
+}
+
+// Ensures refreshes happen at a third of the TTL, give or take 10%.
+func TestJitter(t *testing.T) {
+	t.Parallel()
+
+	if d := jitter(0); d != time.Second {
+		t.Fatalf("Unknown TTLs should be asked for again after a second, got %v", d)
+	}
+
+	for i := 0; i < 1000; i++ {
+		if d := jitter(30 * time.Second); d < 9*time.Second || d > 11*time.Second {
+			t.Fatalf("Expected a refresh between 9s and 11s, got %v", d)
+		}
+	}
+	if d := jitter(time.Nanosecond); d != 0 {
+		t.Fatalf("TTLs too short to split should refresh right away, got %v", d)
+	}
+}
+
+// Measures performance of picking the refresh interval.
+func BenchmarkJitter(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		jitter(30 * time.Second)
+	}
+}
+
+// Ensures only lease lost errors wait for the reader.
+func TestReport(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan error, 1)
+
+	first, second := errors.New("first"), errors.New("second")
+	report(ctx, ch, first, false)
+	report(ctx, ch, second, false)
+	if err := <-ch; err != first {
+		t.Fatalf("Errors should be dropped while the last one is unread, got %v", err)
+	}
+
+	ch <- first
+	done := make(chan struct{})
+	go func() {
+		report(ctx, ch, ErrLeaseLost, true)
+		close(done)
+	}()
+	if err := <-ch; err != first {
+		t.Fatal("The unread error should be delivered first")
+	}
+	if err := <-ch; err != ErrLeaseLost {
+		t.Fatalf("Lease lost errors should always get through, got %v", err)
+	}
+	<-done
+
+	ch <- first
+	cancel()
+	report(ctx, ch, ErrLeaseLost, true)
+	if err := <-ch; err != first || len(ch) != 0 {
+		t.Fatal("Blocked reports should give up once stopped")
+	}
+}
+
+// Ensures the lease is refreshed until it's gone, which closes the channel.
+func TestEtcd_KeepAlive(t *testing.T) {
+	t.Parallel()
+
+	e, client := newFake()
+	resp, _ := client.Grant(context.Background(), 1)
+
+	_, errs := e.KeepAlive(int64(resp.ID))
+	select {
+	case err := <-errs:
+		t.Fatalf("Live leases shouldn't report errors, got %v", err)
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	client.mu.Lock()
+	client.expire(resp.ID)
+	client.mu.Unlock()
+
+	if err, _ := nextErr(t, errs); err != ErrLeaseLost {
+		t.Fatalf("Expected %v, got %v", ErrLeaseLost, err)
+	}
+	if _, ok := nextErr(t, errs); ok {
+		t.Fatal("The channel should be closed once the lease is lost")
+	}
+}
+
+// Ensures unknown leases are reported as lost straight away.
+func TestEtcd_KeepAlive_MissingLease(t *testing.T) {
+	t.Parallel()
+
+	e, _ := newFake()
+	_, errs := e.KeepAlive(int64(etcd.LeaseID(42)))
+	if err, _ := nextErr(t, errs); err != ErrLeaseLost {
+		t.Fatalf("Expected %v, got %v", ErrLeaseLost, err)
+	}
+	if _, ok := nextErr(t, errs); ok {
+		t.Fatal("The channel should be closed once the lease is lost")
+	}
+}
+
+// Ensures failures are reported without giving up and stopping closes the channel.
+func TestEtcd_KeepAlive_Stop(t *testing.T) {
+	t.Parallel()
+
+	e, client := newFake()
+	resp, _ := client.Grant(context.Background(), 30)
+
+	broken := errors.New("broken")
+	client.mu.Lock()
+	client.err = broken
+	client.mu.Unlock()
+
+	stop, errs := e.KeepAlive(int64(resp.ID))
+	if err, _ := nextErr(t, errs); err != broken {
+		t.Fatalf("Expected %v, got %v", broken, err)
+	}
+
+	stop()
+	stop()
+	if _, ok := nextErr(t, errs); ok {
+		t.Fatal("The channel should be closed once stopped")
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"context"
+	"errors"
+
+	etcd "github.com/coreos/etcd/clientv3"
+)
+
+var ErrNotLocked = errors.New("The lock isn't held by this client.")
+
+// A lock we hold, its lease is kept alive until the lock is released.
+type lock struct {
+	lease  etcd.LeaseID
+	cancel context.CancelFunc
+}
+
+// Blocks until the named lock is ours.
+// The lock is a key attached to a lease that's kept alive while we hold it, so it's freed if we die.
+func (e *Etcd) Lock(name string, ttl int64) error {
+	return e.LockContext(context.Background(), name, ttl)
+}
+
+// Same as Lock but gives up waiting once the context is done.
+func (e *Etcd) LockContext(ctx context.Context, name string, ttl int64) error {
+	for {
+		// Watch before trying so we can't miss the holder releasing the lock in between.
+		watchCtx, cancel := context.WithCancel(ctx)
+		watch := e.client.Watch(watchCtx, name)
+
+		acquired, err := e.tryLock(name, ttl)
+		if err != nil || acquired {
+			cancel()
+			return err
+		}
+
+		released := false
+		for resp := range watch {
+			for _, ev := range resp.Events {
+				if ev.Type == etcd.EventTypeDelete {
+					released = true
+				}
+			}
+			if released || resp.Canceled || resp.Err() != nil {
+				break
+			}
+		}
+		cancel()
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// Releases the named lock and revokes its lease, which removes the key.
+func (e *Etcd) Unlock(name string) error {
+	e.mu.Lock()
+	l, ok := e.locks[name]
+	delete(e.locks, name)
+	e.mu.Unlock()
+	if !ok {
+		return ErrNotLocked
+	}
+
+	l.cancel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.ctxTimeout)
+	defer cancel()
+
+	_, err := e.client.Revoke(ctx, l.lease)
+
+	return err
+}
+
+func (e *Etcd) tryLock(name string, ttl int64) (bool, error) {
+	grantCtx, grantCancel := context.WithTimeout(context.Background(), e.ctxTimeout)
+	defer grantCancel()
+
+	grant, err := e.client.Grant(grantCtx, ttl)
+	if err != nil {
+		return false, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.ctxTimeout)
+	defer cancel()
+
+	resp, err := e.client.Txn(ctx).If(
+		etcd.Compare(etcd.Version(name), "=", 0),
+	).Then(
+		etcd.OpPut(name, "", etcd.WithLease(grant.ID)),
+	).Commit()
+	if err != nil || !resp.Succeeded {
+		e.client.Revoke(ctx, grant.ID)
+		return false, err
+	}
+
+	kaCtx, kaCancel := context.WithCancel(context.Background())
+	keepAlive, err := e.client.KeepAlive(kaCtx, grant.ID)
+	if err != nil {
+		kaCancel()
+		e.client.Revoke(ctx, grant.ID)
+		return false, err
+	}
+
+	// The responses have to be drained or the client stops refreshing.
+	go func() {
+		for range keepAlive {
+		}
+	}()
+
+	e.mu.Lock()
+	e.locks[name] = lock{lease: grant.ID, cancel: kaCancel}
+	e.mu.Unlock()
+
+	return true, nil
+}
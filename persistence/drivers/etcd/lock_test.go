@@ -0,0 +1,168 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// Waits for the lock in the background so the caller can check whether it's still blocked.
+func lockAsync(ctx context.Context, e *Etcd, name string) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		done <- e.LockContext(ctx, name, 10)
+	}()
+
+	return done
+}
+
+func blocked(done <-chan error) bool {
+	select {
+	case <-done:
+		return false
+	case <-time.After(50 * time.Millisecond):
+		return true
+	}
+}
+
+func acquired(t *testing.T, done <-chan error) {
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("The lock should have been acquired")
+	}
+}
+
+// Ensures only one client holds the lock at a time and waiters get it once it's released.
+func TestEtcd_Lock(t *testing.T) {
+	t.Parallel()
+
+	first, client := newFake()
+	second := newEtcd(client)
+
+	if err := first.Lock("lock", 10); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	done := lockAsync(context.Background(), second, "lock")
+	if !blocked(done) {
+		t.Fatal("The lock is already held")
+	}
+
+	client.mu.Lock()
+	leases := len(client.leases)
+	client.mu.Unlock()
+	if leases != 1 {
+		t.Fatalf("Leases from failed attempts should be revoked, %d are left", leases)
+	}
+
+	if err := second.Unlock("lock"); err != ErrNotLocked {
+		t.Fatalf("Only the holder can unlock, got %v", err)
+	}
+	if err := first.Unlock("lock"); err != nil {
+		t.Fatal(err.Error())
+	}
+	acquired(t, done)
+
+	if err := first.Unlock("lock"); err != ErrNotLocked {
+		t.Fatalf("Released locks can't be unlocked again, got %v", err)
+	}
+	if err := second.Unlock("lock"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if value, version, _ := first.ReadVersion("lock"); value != "" || version != 0 {
+		t.Fatal("Unlocking should remove the lock's key")
+	}
+}
+
+// Measures performance of taking and releasing an uncontended lock.
+func BenchmarkEtcd_Lock(b *testing.B) {
+	e, _ := newFake()
+	for n := 0; n < b.N; n++ {
+		e.Lock("lock", 10)
+		e.Unlock("lock")
+	}
+}
+
+// Ensures waiting for a lock stops once the context is cancelled.
+func TestEtcd_LockContext(t *testing.T) {
+	t.Parallel()
+
+	first, client := newFake()
+	second := newEtcd(client)
+	if err := first.Lock("lock", 10); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := lockAsync(ctx, second, "lock")
+	if !blocked(done) {
+		t.Fatal("The lock is already held")
+	}
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Fatalf("Expected %v, got %v", context.Canceled, err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Cancelling the context should stop the wait")
+	}
+
+	if err := second.Unlock("lock"); err != ErrNotLocked {
+		t.Fatal("Giving up shouldn't leave the lock held")
+	}
+	if value, version, _ := first.ReadVersion("lock"); value != "" || version == 0 {
+		t.Fatal("The holder should keep the lock")
+	}
+}
+
+// Ensures the lock is freed when the holder's lease expires.
+func TestEtcd_Lock_ExpiredLease(t *testing.T) {
+	t.Parallel()
+
+	first, client := newFake()
+	second := newEtcd(client)
+	if err := first.Lock("lock", 10); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	done := lockAsync(context.Background(), second, "lock")
+	if !blocked(done) {
+		t.Fatal("The lock is already held")
+	}
+
+	first.mu.Lock()
+	lease := first.locks["lock"].lease
+	first.mu.Unlock()
+
+	client.mu.Lock()
+	client.expire(lease)
+	client.mu.Unlock()
+	acquired(t, done)
+
+	if err := first.Unlock("lock"); err == nil {
+		t.Fatal("Unlocking with an expired lease should report it")
+	}
+	if value, version, _ := second.ReadVersion("lock"); value != "" || version == 0 {
+		t.Fatal("The new holder's lock shouldn't be touched by the old holder")
+	}
+}
@@ -38,18 +38,36 @@ func (m MockKVStore) CreateWithLease(key, value string, ttl int64) (int64, error
 func (m MockKVStore) Read(key string) (string, error) {
 	return "1", nil
 }
+func (m MockKVStore) ReadVersion(key string) (string, int64, error) {
+	return "1", 1, nil
+}
 func (m MockKVStore) ReadAll(key string) (map[string]string, error) {
 	return map[string]string{}, nil
 }
+func (m MockKVStore) ReadAllPaged(prefix string, limit int64, startKey string) (map[string]string, string, error) {
+	return map[string]string{}, "", nil
+}
 func (m MockKVStore) Update(key, value string) error {
 	return validateData(key, value)
 }
+func (m MockKVStore) UpdateIf(key, value string, expectedVersion int64) error {
+	return validateData(key, value)
+}
+func (m MockKVStore) DeleteIf(key string, expectedVersion int64) error {
+	return nil
+}
 func (m MockKVStore) RefreshLease(id int64) error {
 	return nil
 }
 func (m MockKVStore) Delete(key string) error {
 	return nil
 }
+func (m MockKVStore) Lock(name string, ttl int64) error {
+	return nil
+}
+func (m MockKVStore) Unlock(name string) error {
+	return nil
+}
 
 type MockBrokenKVStore struct{}
 
@@ -64,18 +82,36 @@ func (m MockBrokenKVStore) CreateWithLease(key, value string, ttl int64) (int64,
 func (m MockBrokenKVStore) Read(key string) (string, error) {
 	return "1", brokenStorage
 }
+func (m MockBrokenKVStore) ReadVersion(key string) (string, int64, error) {
+	return "1", 1, brokenStorage
+}
 func (m MockBrokenKVStore) ReadAll(key string) (map[string]string, error) {
 	return map[string]string{}, brokenStorage
 }
+func (m MockBrokenKVStore) ReadAllPaged(prefix string, limit int64, startKey string) (map[string]string, string, error) {
+	return map[string]string{}, "", brokenStorage
+}
 func (m MockBrokenKVStore) Update(key, value string) error {
 	return brokenStorage
 }
+func (m MockBrokenKVStore) UpdateIf(key, value string, expectedVersion int64) error {
+	return brokenStorage
+}
+func (m MockBrokenKVStore) DeleteIf(key string, expectedVersion int64) error {
+	return brokenStorage
+}
 func (m MockBrokenKVStore) RefreshLease(id int64) error {
 	return brokenStorage
 }
 func (m MockBrokenKVStore) Delete(key string) error {
 	return brokenStorage
 }
+func (m MockBrokenKVStore) Lock(name string, ttl int64) error {
+	return brokenStorage
+}
+func (m MockBrokenKVStore) Unlock(name string) error {
+	return brokenStorage
+}
 
 type MockEtcd struct{}
 
@@ -88,15 +124,33 @@ func (m MockEtcd) CreateWithLease(key, value string, ttl int64) (int64, error) {
 func (m MockEtcd) Read(key string) (string, error) {
 	return "1", nil
 }
+func (m MockEtcd) ReadVersion(key string) (string, int64, error) {
+	return "1", 1, nil
+}
 func (m MockEtcd) ReadAll(key string) (map[string]string, error) {
 	return map[string]string{}, nil
 }
+func (m MockEtcd) ReadAllPaged(prefix string, limit int64, startKey string) (map[string]string, string, error) {
+	return map[string]string{}, "", nil
+}
 func (m MockEtcd) Update(key, value string) error {
 	return validateData(key, value)
 }
+func (m MockEtcd) UpdateIf(key, value string, expectedVersion int64) error {
+	return validateData(key, value)
+}
+func (m MockEtcd) DeleteIf(key string, expectedVersion int64) error {
+	return nil
+}
 func (m MockEtcd) RefreshLease(id int64) error {
 	return nil
 }
 func (m MockEtcd) Delete(key string) error {
 	return nil
 }
+func (m MockEtcd) Lock(name string, ttl int64) error {
+	return nil
+}
+func (m MockEtcd) Unlock(name string) error {
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"context"
+	"errors"
+
+	etcd "github.com/coreos/etcd/clientv3"
+)
+
+var ErrTxnFailed = errors.New("The transaction's conditions weren't met.")
+
+// Something a transaction checks before it applies any of its writes.
+type Condition struct {
+	cmp etcd.Cmp
+}
+
+// Holds while the key is still at the version handed back by ReadVersion, a version of 0 means the key is missing.
+func VersionEq(key string, version int64) Condition {
+	if version == 0 {
+		return Condition{cmp: etcd.Compare(etcd.Version(key), "=", 0)}
+	}
+
+	return Condition{cmp: etcd.Compare(etcd.ModRevision(key), "=", version)}
+}
+
+// Holds while the key's value is still the given one.
+func ValueEq(key, value string) Condition {
+	return Condition{cmp: etcd.Compare(etcd.Value(key), "=", value)}
+}
+
+// Writes to several keys atomically, either every write is applied or none are.
+type Txn struct {
+	driver     *Etcd
+	conditions []etcd.Cmp
+	ops        []etcd.Op
+}
+
+// Starts a transaction, nothing is sent to etcd until it's committed.
+func (e *Etcd) Txn() *Txn {
+	return &Txn{driver: e}
+}
+
+// Only applies the writes if every condition holds.
+func (t *Txn) If(conditions ...Condition) *Txn {
+	for _, c := range conditions {
+		t.conditions = append(t.conditions, c.cmp)
+	}
+
+	return t
+}
+
+// Sets a key's value, overwriting it if present.
+func (t *Txn) Put(key, value string) *Txn {
+	t.ops = append(t.ops, etcd.OpPut(key, value))
+
+	return t
+}
+
+// Deletes a single key, unlike Etcd.Delete keys under it are left alone.
+func (t *Txn) Delete(key string) *Txn {
+	t.ops = append(t.ops, etcd.OpDelete(key))
+
+	return t
+}
+
+// Applies the writes, ErrTxnFailed is returned if any condition didn't hold.
+func (t *Txn) Commit() error {
+	ctx, cancel := context.WithTimeout(context.Background(), t.driver.ctxTimeout)
+	defer cancel()
+
+	resp, err := t.driver.client.Txn(ctx).If(t.conditions...).Then(t.ops...).Commit()
+	if err != nil {
+		return err
+	}
+	if !resp.Succeeded {
+		return ErrTxnFailed
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"errors"
+	"testing"
+)
+
+// Ensures writes are only applied when every condition holds.
+func TestEtcd_Txn(t *testing.T) {
+	t.Parallel()
+
+	e, _ := newFake()
+	e.Create("a", "1")
+	e.Create("b", "2")
+	_, version, _ := e.ReadVersion("a")
+
+	tests := []struct {
+		name       string
+		conditions []Condition
+		err        error
+	}{
+		{"stale version", []Condition{VersionEq("a", version+100)}, ErrTxnFailed},
+		{"missing", []Condition{VersionEq("a", 0)}, ErrTxnFailed},
+		{"wrong value", []Condition{VersionEq("a", version), ValueEq("b", "3")}, ErrTxnFailed},
+		{"missing value", []Condition{ValueEq("c", "")}, ErrTxnFailed},
+		{"holds", []Condition{VersionEq("a", version), ValueEq("b", "2"), VersionEq("c", 0)}, nil},
+	}
+
+	for _, test := range tests {
+		err := e.Txn().If(test.conditions...).Put("a", "3").Put("c", "4").Delete("b").Commit()
+		if err != test.err {
+			t.Fatalf("%s: expected %v, got %v", test.name, test.err, err)
+		}
+
+		if test.err != nil {
+			a, _ := e.Read("a")
+			b, _ := e.Read("b")
+			c, _ := e.Read("c")
+			if a != "1" || b != "2" || c != "" {
+				t.Fatal(test.name + ": no writes should be applied when a condition fails")
+			}
+		}
+	}
+
+	kvs, _ := e.ReadAll("")
+	if len(kvs) != 2 || kvs["a"] != "3" || kvs["c"] != "4" {
+		t.Fatalf("Every write should be applied, got %v", kvs)
+	}
+}
+
+// Measures performance of a conditional transaction.
+func BenchmarkEtcd_Txn(b *testing.B) {
+	e, _ := newFake()
+	for n := 0; n < b.N; n++ {
+		_, version, _ := e.ReadVersion("a")
+		e.Txn().If(VersionEq("a", version)).Put("a", "1").Put("b", "2").Commit()
+	}
+}
+
+// Ensures transactions only delete the exact key and pass on client errors.
+func TestEtcd_Txn_Delete(t *testing.T) {
+	t.Parallel()
+
+	e, client := newFake()
+	e.Create("key", "a")
+	e.Create("key/child", "b")
+
+	if err := e.Txn().Delete("key").Commit(); err != nil {
+		t.Fatal(err.Error())
+	}
+	if value, _ := e.Read("key/child"); value != "b" {
+		t.Fatal("Keys under the deleted key should be left alone")
+	}
+
+	broken := errors.New("broken")
+	client.mu.Lock()
+	client.err = broken
+	client.mu.Unlock()
+	if err := e.Txn().Put("key", "a").Commit(); err != broken {
+		t.Fatalf("Expected %v, got %v", broken, err)
+	}
+}
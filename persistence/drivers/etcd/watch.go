@@ -0,0 +1,82 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"context"
+
+	etcd "github.com/coreos/etcd/clientv3"
+)
+
+type EventType int
+
+const (
+	PUT EventType = iota
+	DELETE
+)
+
+// A change to a watched key.
+// Deleted keys have no value, the version is the revision the change happened at.
+type Event struct {
+	Type    EventType
+	Key     string
+	Value   string
+	Version int64
+}
+
+// Watches a key, or every key under it if prefix is set, and sends each change on the channel.
+// The channel is closed once the watch is cancelled or fails, such as when etcd compacts the revisions we need.
+// Callers should read the current state again before starting a new watch so no change is missed.
+func (e *Etcd) Watch(key string, prefix bool) (<-chan Event, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var opts []etcd.OpOption
+	if prefix {
+		opts = append(opts, etcd.WithPrefix())
+	}
+	watch := e.client.Watch(ctx, key, opts...)
+
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+
+		for resp := range watch {
+			if resp.Canceled || resp.Err() != nil {
+				return
+			}
+
+			for _, ev := range resp.Events {
+				event := Event{
+					Type:    PUT,
+					Key:     string(ev.Kv.Key),
+					Value:   string(ev.Kv.Value),
+					Version: ev.Kv.ModRevision,
+				}
+				if ev.Type == etcd.EventTypeDelete {
+					event.Type = DELETE
+					event.Value = ""
+				}
+
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, cancel
+}
@@ -0,0 +1,113 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"testing"
+	"time"
+
+	etcd "github.com/coreos/etcd/clientv3"
+)
+
+func nextEvent(t *testing.T, events <-chan Event) (Event, bool) {
+	select {
+	case ev, ok := <-events:
+		return ev, ok
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting on the watch")
+	}
+
+	return Event{}, false
+}
+
+// Ensures changes to the watched key are sent in order with their versions.
+func TestEtcd_Watch(t *testing.T) {
+	t.Parallel()
+
+	e, _ := newFake()
+	events, cancel := e.Watch("key", false)
+	defer cancel()
+
+	e.Create("key", "a")
+	e.Create("key/child", "b")
+	_, version, _ := e.ReadVersion("key")
+	e.Delete("key")
+
+	ev, _ := nextEvent(t, events)
+	if ev.Type != PUT || ev.Key != "key" || ev.Value != "a" || ev.Version != version {
+		t.Fatalf("Unexpected put event %+v", ev)
+	}
+
+	ev, _ = nextEvent(t, events)
+	if ev.Type != DELETE || ev.Key != "key" || ev.Value != "" || ev.Version <= version {
+		t.Fatalf("Keys under the watched key shouldn't be sent, got %+v", ev)
+	}
+}
+
+// Measures performance of sending a change to a watcher.
+func BenchmarkEtcd_Watch(b *testing.B) {
+	e, _ := newFake()
+	events, cancel := e.Watch("key", false)
+	defer cancel()
+
+	for n := 0; n < b.N; n++ {
+		e.Update("key", "value")
+		<-events
+	}
+}
+
+// Ensures prefix watches send changes to every key under the prefix.
+func TestEtcd_Watch_Prefix(t *testing.T) {
+	t.Parallel()
+
+	e, _ := newFake()
+	events, cancel := e.Watch("key/", true)
+	defer cancel()
+
+	e.Create("key/a", "a")
+	e.Create("other", "b")
+	e.Create("key/b", "c")
+
+	for _, key := range []string{"key/a", "key/b"} {
+		if ev, _ := nextEvent(t, events); ev.Key != key {
+			t.Fatalf("Expected a change to %s, got %+v", key, ev)
+		}
+	}
+}
+
+// Ensures the channel is closed once the watch is cancelled by us or by etcd.
+func TestEtcd_Watch_Cancel(t *testing.T) {
+	t.Parallel()
+
+	e, client := newFake()
+	events, cancel := e.Watch("key", false)
+	cancel()
+	if _, ok := nextEvent(t, events); ok {
+		t.Fatal("Cancelling should close the channel")
+	}
+
+	events, cancel = e.Watch("key", false)
+	defer cancel()
+
+	client.mu.Lock()
+	for w := range client.watchers {
+		w.ch <- etcd.WatchResponse{Canceled: true}
+	}
+	client.mu.Unlock()
+
+	if _, ok := nextEvent(t, events); ok {
+		t.Fatal("A watch cancelled by etcd should close the channel")
+	}
+}
@@ -0,0 +1,302 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/verizonlabs/mesos-framework-sdk/persistence"
+)
+
+var (
+	ErrKeyExists    = errors.New("The key already exists.")
+	ErrLeaseExpired = errors.New("The key held by the lease has expired.")
+)
+
+// Keeps key/value pairs in memory, meant for tests and frameworks running a single scheduler.
+// Every write bumps a store-wide revision which becomes the key's version.
+// Leased keys expire once their TTL passes without a refresh.
+type Memory struct {
+	mu       sync.Mutex
+	data     map[string]entry
+	leases   map[int64]string
+	lease    int64
+	revision int64
+	now      func() time.Time
+}
+
+type entry struct {
+	value   string
+	version int64
+	lease   int64
+	ttl     time.Duration
+	expires time.Time
+}
+
+func NewClient() *Memory {
+	return &Memory{
+		data:   make(map[string]entry),
+		leases: make(map[int64]string),
+		now:    time.Now,
+	}
+}
+
+// Inserts a new key/value pair.
+// This will not overwrite an already existing key.
+func (m *Memory) Create(key, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.get(key); ok {
+		return ErrKeyExists
+	}
+	m.put(key, entry{value: value})
+
+	return nil
+}
+
+// Creates a key that expires after the TTL in seconds unless its lease is refreshed.
+// This will not overwrite an already existing key.
+func (m *Memory) CreateWithLease(key, value string, ttl int64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.get(key); ok {
+		return -1, ErrKeyExists
+	}
+
+	m.lease++
+	d := time.Duration(ttl) * time.Second
+	m.put(key, entry{value: value, lease: m.lease, ttl: d, expires: m.now().Add(d)})
+	m.leases[m.lease] = key
+
+	return m.lease, nil
+}
+
+// Reads a key's value.
+func (m *Memory) Read(key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, _ := m.get(key)
+
+	return e.value, nil
+}
+
+// Reads a key's value along with the revision it was last written at.
+// Missing keys have a version of 0.
+func (m *Memory) ReadVersion(key string) (string, int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, _ := m.get(key)
+
+	return e.value, e.version, nil
+}
+
+// Read all key/values under a specified key.
+func (m *Memory) ReadAll(key string) (map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	kvs := make(map[string]string)
+	for k := range m.data {
+		if !strings.HasPrefix(k, key) {
+			continue
+		}
+		if e, ok := m.get(k); ok {
+			kvs[k] = e.value
+		}
+	}
+
+	if len(kvs) > 0 {
+		return kvs, nil
+	}
+
+	return nil, nil
+}
+
+// Reads at most limit key/values under the prefix in key order, starting from the start key.
+// The returned key starts the next page and is empty once there's nothing left.
+func (m *Memory) ReadAllPaged(prefix string, limit int64, startKey string) (map[string]string, string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var keys []string
+	for k := range m.data {
+		if strings.HasPrefix(k, prefix) && k >= startKey {
+			if _, ok := m.get(k); ok {
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	next := ""
+	if limit > 0 && int64(len(keys)) > limit {
+		next = keys[limit]
+		keys = keys[:limit]
+	}
+
+	kvs := make(map[string]string, len(keys))
+	for _, k := range keys {
+		kvs[k] = m.data[k].value
+	}
+
+	return kvs, next, nil
+}
+
+// Updates a key's value, detaching it from any lease.
+// This will overwrite an existing key if present.
+func (m *Memory) Update(key, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.put(key, entry{value: value})
+
+	return nil
+}
+
+// Updates a key's value only if it hasn't been written since the expected version was read.
+// An expected version of 0 only creates the key.
+func (m *Memory) UpdateIf(key, value string, expectedVersion int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, _ := m.get(key)
+	if e.version != expectedVersion {
+		return persistence.ErrVersionMismatch
+	}
+	m.put(key, entry{value: value})
+
+	return nil
+}
+
+// Deletes only the key, not the keys it prefixes, if it hasn't been written since the expected version was read.
+// An expected version of 0 only succeeds when the key is already gone.
+func (m *Memory) DeleteIf(key string, expectedVersion int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.get(key)
+	if e.version != expectedVersion {
+		return persistence.ErrVersionMismatch
+	}
+	if ok {
+		m.remove(key, e)
+	}
+
+	return nil
+}
+
+// Pushes the lease's expiry back by its TTL.
+func (m *Memory) RefreshLease(id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key, ok := m.leases[id]
+	if !ok {
+		return ErrLeaseExpired
+	}
+	e, ok := m.get(key)
+	if !ok || e.lease != id {
+		delete(m.leases, id)
+		return ErrLeaseExpired
+	}
+
+	e.expires = m.now().Add(e.ttl)
+	m.data[key] = e
+
+	return nil
+}
+
+// Deletes a key/value pair along with every key it prefixes.
+func (m *Memory) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for k, e := range m.data {
+		if strings.HasPrefix(k, key) {
+			m.remove(k, e)
+		}
+	}
+
+	return nil
+}
+
+// Copies every live key/value pair, useful for asserting on state in tests or dumping it.
+func (m *Memory) Snapshot() map[string]string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	kvs := make(map[string]string, len(m.data))
+	for k := range m.data {
+		if e, ok := m.get(k); ok {
+			kvs[k] = e.value
+		}
+	}
+
+	return kvs
+}
+
+// Replaces everything in the store with the snapshot's key/value pairs, none of which are leased.
+func (m *Memory) Restore(snapshot map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.data = make(map[string]entry, len(snapshot))
+	m.leases = make(map[int64]string)
+	for k, v := range snapshot {
+		m.put(k, entry{value: v})
+	}
+}
+
+// Must be called with the lock held.
+// Expired keys are removed as they're found.
+func (m *Memory) get(key string) (entry, bool) {
+	e, ok := m.data[key]
+	if !ok {
+		return entry{}, false
+	}
+	if e.lease != 0 && !m.now().Before(e.expires) {
+		m.remove(key, e)
+		return entry{}, false
+	}
+
+	return e, true
+}
+
+// Must be called with the lock held.
+func (m *Memory) put(key string, e entry) {
+	if old, ok := m.data[key]; ok && old.lease != 0 && old.lease != e.lease {
+		delete(m.leases, old.lease)
+	}
+
+	m.revision++
+	e.version = m.revision
+	m.data[key] = e
+}
+
+// Must be called with the lock held.
+func (m *Memory) remove(key string, e entry) {
+	delete(m.data, key)
+	if e.lease != 0 {
+		delete(m.leases, e.lease)
+	}
+}
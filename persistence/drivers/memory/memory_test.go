@@ -0,0 +1,146 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/verizonlabs/mesos-framework-sdk/persistence"
+)
+
+// Ensures keys can't be created twice and versions guard conditional updates.
+func TestMemory_Versions(t *testing.T) {
+	t.Parallel()
+
+	m := NewClient()
+	if err := m.Create("/tasks/a", "1"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := m.Create("/tasks/a", "2"); err != ErrKeyExists {
+		t.Fatal("Existing keys should not be overwritten by create")
+	}
+
+	value, version, _ := m.ReadVersion("/tasks/a")
+	if value != "1" || version == 0 {
+		t.Fatal("Existing keys should have a version")
+	}
+	if err := m.UpdateIf("/tasks/a", "2", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := m.UpdateIf("/tasks/a", "3", version); err != persistence.ErrVersionMismatch {
+		t.Fatal("Stale versions should be rejected")
+	}
+	if err := m.UpdateIf("/tasks/b", "1", 0); err != nil {
+		t.Fatal("A version of 0 should create missing keys")
+	}
+
+	m.Update("/tasks/c", "1")
+	m.Update("/other", "1")
+	m.Update("/other2", "1")
+	_, version, _ = m.ReadVersion("/other")
+	if err := m.DeleteIf("/other", version-1); err != persistence.ErrVersionMismatch {
+		t.Fatal("Stale versions should not delete")
+	}
+	if err := m.DeleteIf("/other2", 0); err != persistence.ErrVersionMismatch {
+		t.Fatal("A version of 0 should not delete existing keys")
+	}
+	if err := m.DeleteIf("/other", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := m.DeleteIf("/other", 0); err != nil {
+		t.Fatal("A version of 0 should match missing keys")
+	}
+	if _, version, _ = m.ReadVersion("/other2"); version == 0 {
+		t.Fatal("Conditional deletes should only remove the exact key")
+	}
+	m.Update("/other", "1")
+	m.Delete("/other2")
+	page, next, _ := m.ReadAllPaged("/tasks", 2, "")
+	if len(page) != 2 || next != "/tasks/c" {
+		t.Fatal("Pages should stop at the limit and point at the next key")
+	}
+	if page, next, _ = m.ReadAllPaged("/tasks", 2, next); len(page) != 1 || next != "" {
+		t.Fatal("The last page should have no next key")
+	}
+
+	m.Delete("/tasks")
+	if snapshot := m.Snapshot(); len(snapshot) != 1 || snapshot["/other"] != "1" {
+		t.Fatal("Deletes should remove keys under the prefix only")
+	}
+}
+
+// Measures conditional updates.
+func BenchmarkMemory_Versions(b *testing.B) {
+	m := NewClient()
+	m.Update("/tasks/a", "1")
+	for n := 0; n < b.N; n++ {
+		_, version, _ := m.ReadVersion("/tasks/a")
+		m.UpdateIf("/tasks/a", "1", version)
+	}
+}
+
+// Ensures leased keys expire unless refreshed.
+func TestMemory_Leases(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+	m := NewClient()
+	m.now = func() time.Time { return now }
+
+	id, err := m.CreateWithLease("/leader", "a", 10)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	now = now.Add(9 * time.Second)
+	if err := m.RefreshLease(id); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	now = now.Add(9 * time.Second)
+	if value, _ := m.Read("/leader"); value != "a" {
+		t.Fatal("Refreshed leases should keep their key alive")
+	}
+
+	now = now.Add(time.Second)
+	if value, _ := m.Read("/leader"); value != "" {
+		t.Fatal("Keys should expire once their lease runs out")
+	}
+	if err := m.RefreshLease(id); err != ErrLeaseExpired {
+		t.Fatal("Expired leases can't be refreshed")
+	}
+	if _, err := m.CreateWithLease("/leader", "b", 10); err != nil {
+		t.Fatal("Expired keys should be free to create again")
+	}
+
+	m.Restore(map[string]string{"/restored": "1"})
+	if value, _ := m.Read("/restored"); value != "1" {
+		t.Fatal("Restoring should load the snapshot")
+	}
+	if value, _ := m.Read("/leader"); value != "" {
+		t.Fatal("Restoring should drop what was there before")
+	}
+}
+
+// Measures creating and refreshing leased keys.
+func BenchmarkMemory_Leases(b *testing.B) {
+	m := NewClient()
+	for n := 0; n < b.N; n++ {
+		id, _ := m.CreateWithLease("/leader", "a", 10)
+		m.RefreshLease(id)
+		m.Delete("/leader")
+	}
+}
@@ -0,0 +1,545 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"bufio"
+	"errors"
+	"github.com/verizonlabs/mesos-framework-sdk/persistence"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrKeyExists    = errors.New("The key already exists.")
+	ErrLeaseExpired = errors.New("The key held by the lease has expired.")
+)
+
+const (
+	// How many keys are asked for per SCAN when reading or deleting by prefix.
+	SCAN_COUNT = "100"
+
+	// Appended to a key to name the hash holding its version.
+	VERSION_SUFFIX = "\x00version"
+)
+
+// Stores keys in a single redis server over one connection, which is redialed after errors.
+// Leases are keys with a TTL, refreshing a lease resets it.
+// Redis keeps no versions so every write bumps a counter in a hash next to the key within the same transaction.
+// The hash expires along with its key and versions are the counter plus one, so keys written without one are never at 0.
+type Redis struct {
+	address  string
+	password string
+	db       int
+	timeout  time.Duration
+	mu       sync.Mutex
+	conn     net.Conn
+	r        *bufio.Reader
+	w        *bufio.Writer
+	leases   map[int64]lease
+	lease    int64
+}
+
+type lease struct {
+	key string
+	ttl int64
+}
+
+// Creates a new redis client, the password is optional and db selects the logical database.
+func NewClient(address, password string, db int, timeout time.Duration) *Redis {
+	return &Redis{
+		address:  address,
+		password: password,
+		db:       db,
+		timeout:  timeout,
+		leases:   make(map[int64]lease),
+	}
+}
+
+// Inserts a new key/value pair.
+// This will not overwrite an already existing key.
+func (r *Redis) Create(key, value string) error {
+	return r.create(key, value, 0)
+}
+
+// Creates a key that expires after the TTL in seconds unless its lease is refreshed.
+// This will not overwrite an already existing key.
+func (r *Redis) CreateWithLease(key, value string, ttl int64) (int64, error) {
+	if err := r.create(key, value, ttl*1000); err != nil {
+		return -1, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lease++
+	r.leases[r.lease] = lease{key: key, ttl: ttl}
+
+	return r.lease, nil
+}
+
+// Reads a key's value.
+func (r *Redis) Read(key string) (string, error) {
+	reply, err := r.do([]string{"GET", key})
+	if err != nil {
+		return "", err
+	}
+
+	value, _ := reply[0].([]byte)
+
+	return string(value), nil
+}
+
+// Reads a key's value along with its version, missing keys have a version of 0.
+func (r *Redis) ReadVersion(key string) (string, int64, error) {
+	reply, err := r.do([]string{"MULTI"}, []string{"GET", key}, []string{"HGET", key + VERSION_SUFFIX, "version"}, []string{"EXEC"})
+	if err != nil {
+		return "", 0, err
+	}
+	read, ok := reply[3].([]interface{})
+	if !ok || len(read) != 2 {
+		return "", 0, errProtocol
+	}
+
+	value, ok := read[0].([]byte)
+	if !ok {
+		return "", 0, nil
+	}
+	v, err := version(read[1])
+	if err != nil {
+		return "", 0, err
+	}
+
+	return string(value), v, nil
+}
+
+// Read all key/values under a specified key.
+func (r *Redis) ReadAll(key string) (map[string]string, error) {
+	keys, err := r.scan(key)
+	if err != nil || len(keys) == 0 {
+		return nil, err
+	}
+
+	reply, err := r.do(append([]string{"MGET"}, keys...))
+	if err != nil {
+		return nil, err
+	}
+	values, ok := reply[0].([]interface{})
+	if !ok || len(values) != len(keys) {
+		return nil, errProtocol
+	}
+
+	kvs := make(map[string]string, len(keys))
+	for i, k := range keys {
+		// Keys can expire between the scan and the read.
+		if v, ok := values[i].([]byte); ok {
+			kvs[k] = string(v)
+		}
+	}
+
+	return kvs, nil
+}
+
+// Updates a key's value.
+// This will overwrite an existing key if present.
+func (r *Redis) Update(key, value string) error {
+	cmds := append([][]string{{"MULTI"}}, write(key, value, 0)...)
+	_, err := r.do(append(cmds, []string{"EXEC"})...)
+
+	return err
+}
+
+// Updates a key's value only if it hasn't been modified since the expected version was read.
+// The key is watched while it's compared so a write in between aborts the transaction.
+// An expected version of 0 only creates the key, leased keys keep their remaining TTL.
+func (r *Redis) UpdateIf(key, value string, expectedVersion int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, v, ttl, err := r.watch(key)
+	if err != nil {
+		return err
+	}
+	if (current == nil && expectedVersion != 0) || (current != nil && v != expectedVersion) {
+		if _, err := r.send([]string{"UNWATCH"}); err != nil {
+			return err
+		}
+
+		return persistence.ErrVersionMismatch
+	}
+
+	ok, err := r.exec(write(key, value, ttl))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return persistence.ErrVersionMismatch
+	}
+
+	return nil
+}
+
+// Deletes only the key, not the keys it prefixes, if it hasn't been modified since the expected version was read.
+// An expected version of 0 only succeeds when the key is already gone.
+func (r *Redis) DeleteIf(key string, expectedVersion int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, v, _, err := r.watch(key)
+	if err != nil {
+		return err
+	}
+	if current == nil || v != expectedVersion {
+		if _, err := r.send([]string{"UNWATCH"}); err != nil {
+			return err
+		}
+		if current == nil && expectedVersion == 0 {
+			return nil
+		}
+
+		return persistence.ErrVersionMismatch
+	}
+
+	ok, err := r.exec([][]string{{"DEL", key, key + VERSION_SUFFIX}})
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return persistence.ErrVersionMismatch
+	}
+
+	return nil
+}
+
+// Resets the TTL of the lease's key.
+func (r *Redis) RefreshLease(id int64) error {
+	r.mu.Lock()
+	l, ok := r.leases[id]
+	r.mu.Unlock()
+	if !ok {
+		return ErrLeaseExpired
+	}
+
+	ttl := strconv.FormatInt(l.ttl, 10)
+	reply, err := r.do([]string{"EXPIRE", l.key, ttl}, []string{"EXPIRE", l.key + VERSION_SUFFIX, ttl})
+	if err != nil {
+		return err
+	}
+	if n, _ := reply[0].(int64); n == 0 {
+		r.mu.Lock()
+		delete(r.leases, id)
+		r.mu.Unlock()
+
+		return ErrLeaseExpired
+	}
+
+	return nil
+}
+
+// Deletes a key/value pair along with every key it prefixes.
+func (r *Redis) Delete(key string) error {
+	keys, err := r.scan(key)
+	if err != nil || len(keys) == 0 {
+		return err
+	}
+
+	del := []string{"DEL"}
+	for _, k := range keys {
+		del = append(del, k, k+VERSION_SUFFIX)
+	}
+	_, err = r.do(del)
+
+	return err
+}
+
+// Writes the key/value pairs atomically with MULTI/EXEC, either all of them are set or none are.
+func (r *Redis) UpdateAll(kvs map[string]string) error {
+	if len(kvs) == 0 {
+		return nil
+	}
+
+	cmds := make([][]string, 0, 3*len(kvs)+2)
+	cmds = append(cmds, []string{"MULTI"})
+	for k, v := range kvs {
+		cmds = append(cmds, write(k, v, 0)...)
+	}
+	cmds = append(cmds, []string{"EXEC"})
+
+	reply, err := r.do(cmds...)
+	if err != nil {
+		return err
+	}
+	if reply[len(reply)-1] == nil {
+		return errors.New("Redis aborted the transaction.")
+	}
+
+	return nil
+}
+
+// Creates the key if it's missing, with a TTL in milliseconds if it's positive.
+func (r *Redis) create(key, value string, ttl int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, _, _, err := r.watch(key)
+	if err != nil {
+		return err
+	}
+	if current != nil {
+		if _, err := r.send([]string{"UNWATCH"}); err != nil {
+			return err
+		}
+
+		return ErrKeyExists
+	}
+
+	ok, err := r.exec(write(key, value, ttl))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// Someone else wrote the key since it was watched.
+		return ErrKeyExists
+	}
+
+	return nil
+}
+
+// Watches the key and reads its value, version and remaining TTL in milliseconds.
+// Must be called with the lock held, the transaction that follows has to be run with exec or the key unwatched.
+func (r *Redis) watch(key string) ([]byte, int64, int64, error) {
+	reply, err := r.send(
+		[]string{"WATCH", key},
+		[]string{"GET", key},
+		[]string{"HGET", key + VERSION_SUFFIX, "version"},
+		[]string{"PTTL", key},
+	)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	value, ok := reply[1].([]byte)
+	if !ok {
+		return nil, 0, 0, nil
+	}
+	v, err := version(reply[2])
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	ttl, _ := reply[3].(int64)
+
+	return value, v, ttl, nil
+}
+
+// Runs the commands in a transaction, returning false if it was aborted because a watched key changed.
+// Must be called with the lock held.
+func (r *Redis) exec(cmds [][]string) (bool, error) {
+	cmds = append([][]string{{"MULTI"}}, cmds...)
+	reply, err := r.send(append(cmds, []string{"EXEC"})...)
+	if err != nil {
+		return false, err
+	}
+
+	return reply[len(reply)-1] != nil, nil
+}
+
+// Lists the keys starting with the prefix, leaving out the hashes holding versions.
+func (r *Redis) scan(prefix string) ([]string, error) {
+	var keys []string
+	cursor := "0"
+	for {
+		reply, err := r.do([]string{"SCAN", cursor, "MATCH", escape(prefix) + "*", "COUNT", SCAN_COUNT})
+		if err != nil {
+			return nil, err
+		}
+
+		page, ok := reply[0].([]interface{})
+		if !ok || len(page) != 2 {
+			return nil, errProtocol
+		}
+		next, ok := page[0].([]byte)
+		if !ok {
+			return nil, errProtocol
+		}
+		found, _ := page[1].([]interface{})
+		for _, k := range found {
+			if b, ok := k.([]byte); ok && !strings.HasSuffix(string(b), VERSION_SUFFIX) {
+				keys = append(keys, string(b))
+			}
+		}
+
+		cursor = string(next)
+		if cursor == "0" {
+			break
+		}
+	}
+
+	// SCAN can return the same key more than once.
+	seen := make(map[string]struct{}, len(keys))
+	unique := keys[:0]
+	for _, k := range keys {
+		if _, ok := seen[k]; !ok {
+			seen[k] = struct{}{}
+			unique = append(unique, k)
+		}
+	}
+
+	return unique, nil
+}
+
+// Pipelines the commands, sending all of them before reading any replies.
+// Errors sent back by redis for any of the commands are returned.
+func (r *Redis) do(cmds ...[]string) ([]interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.send(cmds...)
+}
+
+// Same as do, but the caller holds the lock so several pipelines can share a WATCH.
+func (r *Redis) send(cmds ...[]string) ([]interface{}, error) {
+	if r.conn == nil {
+		if err := r.dial(); err != nil {
+			return nil, err
+		}
+	}
+
+	replies, err := r.roundTrip(cmds...)
+	if err != nil {
+		// The connection is in an unknown state so the next call starts over.
+		r.conn.Close()
+		r.conn = nil
+		return nil, err
+	}
+
+	for _, reply := range replies {
+		if e, ok := reply.(redisError); ok {
+			return nil, e
+		}
+		// Errors inside a transaction come back in the EXEC reply.
+		if nested, ok := reply.([]interface{}); ok {
+			for _, n := range nested {
+				if e, ok := n.(redisError); ok {
+					return nil, e
+				}
+			}
+		}
+	}
+
+	return replies, nil
+}
+
+func (r *Redis) roundTrip(cmds ...[]string) ([]interface{}, error) {
+	if r.timeout > 0 {
+		r.conn.SetDeadline(time.Now().Add(r.timeout))
+	}
+
+	for _, cmd := range cmds {
+		writeCommand(r.w, cmd...)
+	}
+	if err := r.w.Flush(); err != nil {
+		return nil, err
+	}
+
+	replies := make([]interface{}, len(cmds))
+	for i := range cmds {
+		reply, err := readReply(r.r)
+		if err != nil {
+			return nil, err
+		}
+		replies[i] = reply
+	}
+
+	return replies, nil
+}
+
+// Must be called with the lock held.
+func (r *Redis) dial() error {
+	conn, err := net.DialTimeout("tcp", r.address, r.timeout)
+	if err != nil {
+		return err
+	}
+	r.conn, r.r, r.w = conn, bufio.NewReader(conn), bufio.NewWriter(conn)
+
+	var setup [][]string
+	if r.password != "" {
+		setup = append(setup, []string{"AUTH", r.password})
+	}
+	if r.db != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(r.db)})
+	}
+	if len(setup) == 0 {
+		return nil
+	}
+
+	replies, err := r.roundTrip(setup...)
+	if err == nil {
+		for _, reply := range replies {
+			if e, ok := reply.(redisError); ok {
+				err = e
+			}
+		}
+	}
+	if err != nil {
+		conn.Close()
+		r.conn = nil
+	}
+
+	return err
+}
+
+// Commands that set the key and bump its version, with a TTL in milliseconds if it's positive.
+// The version's hash gets the same TTL so it never outlives the key.
+func write(key, value string, ttl int64) [][]string {
+	if ttl > 0 {
+		px := strconv.FormatInt(ttl, 10)
+		return [][]string{
+			{"SET", key, value, "PX", px},
+			{"HINCRBY", key + VERSION_SUFFIX, "version", "1"},
+			{"PEXPIRE", key + VERSION_SUFFIX, px},
+		}
+	}
+
+	return [][]string{
+		{"SET", key, value},
+		{"HINCRBY", key + VERSION_SUFFIX, "version", "1"},
+		{"PERSIST", key + VERSION_SUFFIX},
+	}
+}
+
+// Turns the counter read from a version's hash into the version of an existing key.
+func version(counter interface{}) (int64, error) {
+	if counter == nil {
+		return 1, nil
+	}
+	b, ok := counter.([]byte)
+	if !ok {
+		return 0, errProtocol
+	}
+	n, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil {
+		return 0, errProtocol
+	}
+
+	return n + 1, nil
+}
+
+// Escapes glob characters so keys are matched literally.
+func escape(key string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`).Replace(key)
+}
@@ -0,0 +1,370 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"bufio"
+	"bytes"
+	"github.com/verizonlabs/mesos-framework-sdk/persistence"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var _ persistence.Storage = (*Redis)(nil)
+
+// Connection that reads canned replies and records everything written to it.
+type fakeConn struct {
+	replies *bytes.Buffer
+	written bytes.Buffer
+	closed  bool
+}
+
+func newFakeConn(replies string) *fakeConn {
+	return &fakeConn{replies: bytes.NewBufferString(replies)}
+}
+
+func (f *fakeConn) Read(b []byte) (int, error)         { return f.replies.Read(b) }
+func (f *fakeConn) Write(b []byte) (int, error)        { return f.written.Write(b) }
+func (f *fakeConn) Close() error                       { f.closed = true; return nil }
+func (f *fakeConn) LocalAddr() net.Addr                { return nil }
+func (f *fakeConn) RemoteAddr() net.Addr               { return nil }
+func (f *fakeConn) SetDeadline(t time.Time) error      { return nil }
+func (f *fakeConn) SetReadDeadline(t time.Time) error  { return nil }
+func (f *fakeConn) SetWriteDeadline(t time.Time) error { return nil }
+
+// Returns a client that's already connected to a fake server answering with the replies.
+func newFake(replies string) (*Redis, *fakeConn) {
+	conn := newFakeConn(replies)
+	r := NewClient("redis", "", 0, time.Second)
+	r.conn, r.r, r.w = conn, bufio.NewReader(conn), bufio.NewWriter(conn)
+
+	return r, conn
+}
+
+// Encodes the commands the way they should go out on the wire.
+func commands(cmds ...[]string) string {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	for _, cmd := range cmds {
+		writeCommand(w, cmd...)
+	}
+	w.Flush()
+
+	return buf.String()
+}
+
+// Replies to watching a key, a nil value means the key is missing and a nil counter that it has no version yet.
+func watched(value, counter *string, ttl int) string {
+	return "+OK\r\n" + bulk(value) + bulk(counter) + ":" + strconv.Itoa(ttl) + "\r\n"
+}
+
+func bulk(s *string) string {
+	if s == nil {
+		return "$-1\r\n"
+	}
+
+	return "$" + strconv.Itoa(len(*s)) + "\r\n" + *s + "\r\n"
+}
+
+func str(s string) *string {
+	return &s
+}
+
+// Commands sent when watching a key.
+func watch(key string) []string {
+	return []string{
+		commands([]string{"WATCH", key}),
+		commands([]string{"GET", key}),
+		commands([]string{"HGET", key + VERSION_SUFFIX, "version"}),
+		commands([]string{"PTTL", key}),
+	}
+}
+
+// Ensures keys are only created when missing along with their version.
+func TestRedis_Create(t *testing.T) {
+	t.Parallel()
+
+	r, conn := newFake(watched(nil, nil, -2) + "+OK\r\n+QUEUED\r\n+QUEUED\r\n+QUEUED\r\n*3\r\n+OK\r\n:1\r\n:0\r\n" +
+		watched(str("value"), str("1"), -1) + "+OK\r\n")
+	if err := r.Create("key", "value"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := r.Create("key", "value"); err != ErrKeyExists {
+		t.Fatal("Existing keys should not be overwritten by create")
+	}
+
+	expected := strings.Join(watch("key"), "") + commands(
+		[]string{"MULTI"},
+		[]string{"SET", "key", "value"},
+		[]string{"HINCRBY", "key" + VERSION_SUFFIX, "version", "1"},
+		[]string{"PERSIST", "key" + VERSION_SUFFIX},
+		[]string{"EXEC"},
+	) + strings.Join(watch("key"), "") + commands([]string{"UNWATCH"})
+	if conn.written.String() != expected {
+		t.Fatalf("Unexpected commands %q", conn.written.String())
+	}
+
+	r, conn = newFake(watched(nil, nil, -2) + "+OK\r\n+QUEUED\r\n+QUEUED\r\n+QUEUED\r\n*-1\r\n")
+	if err := r.Create("key", "value"); err != ErrKeyExists {
+		t.Fatal("Keys written while being created should not be overwritten")
+	}
+}
+
+// Measures performance of creating keys.
+func BenchmarkRedis_Create(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		r, _ := newFake(watched(nil, nil, -2) + "+OK\r\n+QUEUED\r\n+QUEUED\r\n+QUEUED\r\n*3\r\n+OK\r\n:1\r\n:0\r\n")
+		r.Create("key", "value")
+	}
+}
+
+// Ensures leased keys and their versions expire together.
+func TestRedis_CreateWithLease(t *testing.T) {
+	t.Parallel()
+
+	r, conn := newFake(watched(nil, nil, -2) + "+OK\r\n+QUEUED\r\n+QUEUED\r\n+QUEUED\r\n*3\r\n+OK\r\n:1\r\n:1\r\n" + ":1\r\n:1\r\n")
+	id, err := r.CreateWithLease("key", "value", 5)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := r.RefreshLease(id); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := strings.Join(watch("key"), "") + commands(
+		[]string{"MULTI"},
+		[]string{"SET", "key", "value", "PX", "5000"},
+		[]string{"HINCRBY", "key" + VERSION_SUFFIX, "version", "1"},
+		[]string{"PEXPIRE", "key" + VERSION_SUFFIX, "5000"},
+		[]string{"EXEC"},
+		[]string{"EXPIRE", "key", "5"},
+		[]string{"EXPIRE", "key" + VERSION_SUFFIX, "5"},
+	)
+	if conn.written.String() != expected {
+		t.Fatalf("Unexpected commands %q", conn.written.String())
+	}
+}
+
+// Ensures versions come from the counter and missing keys are at version 0.
+func TestRedis_ReadVersion(t *testing.T) {
+	t.Parallel()
+
+	r, conn := newFake("+OK\r\n+QUEUED\r\n+QUEUED\r\n*2\r\n$5\r\nvalue\r\n$1\r\n3\r\n" +
+		"+OK\r\n+QUEUED\r\n+QUEUED\r\n*2\r\n$5\r\nvalue\r\n$-1\r\n" +
+		"+OK\r\n+QUEUED\r\n+QUEUED\r\n*2\r\n$-1\r\n$-1\r\n")
+	value, v, err := r.ReadVersion("key")
+	if err != nil || value != "value" || v != 4 {
+		t.Fatal("Existing keys should have a version one past their counter")
+	}
+	if value, v, err = r.ReadVersion("key"); err != nil || value != "value" || v != 1 {
+		t.Fatal("Existing keys without a counter should still have a positive version")
+	}
+	if value, v, err = r.ReadVersion("missing"); err != nil || value != "" || v != 0 {
+		t.Fatal("Missing keys should have a version of 0")
+	}
+
+	expected := commands([]string{"MULTI"}, []string{"GET", "key"}, []string{"HGET", "key" + VERSION_SUFFIX, "version"}, []string{"EXEC"})
+	if !strings.HasPrefix(conn.written.String(), expected) {
+		t.Fatalf("Unexpected commands %q", conn.written.String())
+	}
+}
+
+// Ensures conditional updates are made in a watched transaction that bumps the version.
+func TestRedis_UpdateIf(t *testing.T) {
+	t.Parallel()
+
+	queued := "+OK\r\n+QUEUED\r\n+QUEUED\r\n+QUEUED\r\n"
+	update := commands(
+		[]string{"MULTI"},
+		[]string{"SET", "key", "new"},
+		[]string{"HINCRBY", "key" + VERSION_SUFFIX, "version", "1"},
+		[]string{"PERSIST", "key" + VERSION_SUFFIX},
+		[]string{"EXEC"},
+	)
+	tests := []struct {
+		name     string
+		replies  string
+		expected int64
+		err      error
+		cmds     string
+	}{
+		{
+			"matching version",
+			watched(str("old"), str("2"), -1) + queued + "*3\r\n+OK\r\n:3\r\n:0\r\n", 3, nil,
+			update,
+		},
+		{
+			"stale version",
+			watched(str("old"), str("3"), -1) + "+OK\r\n", 3, persistence.ErrVersionMismatch,
+			commands([]string{"UNWATCH"}),
+		},
+		{
+			// The value went from old to something else and back, only the counter shows it changed.
+			"same value written back",
+			watched(str("old"), str("4"), -1) + "+OK\r\n", 3, persistence.ErrVersionMismatch,
+			commands([]string{"UNWATCH"}),
+		},
+		{
+			"changed during the transaction",
+			watched(str("old"), str("2"), -1) + queued + "*-1\r\n", 3, persistence.ErrVersionMismatch,
+			update,
+		},
+		{
+			"leased",
+			watched(str("old"), str("2"), 1500) + queued + "*3\r\n+OK\r\n:3\r\n:1\r\n", 3, nil,
+			commands(
+				[]string{"MULTI"},
+				[]string{"SET", "key", "new", "PX", "1500"},
+				[]string{"HINCRBY", "key" + VERSION_SUFFIX, "version", "1"},
+				[]string{"PEXPIRE", "key" + VERSION_SUFFIX, "1500"},
+				[]string{"EXEC"},
+			),
+		},
+		{
+			"create",
+			watched(nil, nil, -2) + queued + "*3\r\n+OK\r\n:1\r\n:0\r\n", 0, nil,
+			update,
+		},
+		{
+			"create existing",
+			watched(str("old"), nil, -1) + "+OK\r\n", 0, persistence.ErrVersionMismatch,
+			commands([]string{"UNWATCH"}),
+		},
+		{
+			"missing",
+			watched(nil, nil, -2) + "+OK\r\n", 3, persistence.ErrVersionMismatch,
+			commands([]string{"UNWATCH"}),
+		},
+	}
+
+	for _, test := range tests {
+		r, conn := newFake(test.replies)
+		if err := r.UpdateIf("key", "new", test.expected); err != test.err {
+			t.Fatal(test.name + ": unexpected result")
+		}
+		if conn.written.String() != strings.Join(watch("key"), "")+test.cmds {
+			t.Fatalf("%s: unexpected commands %q", test.name, conn.written.String())
+		}
+	}
+}
+
+// Ensures conditional deletes remove just the key and its version in a watched transaction.
+func TestRedis_DeleteIf(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		replies  string
+		expected int64
+		err      error
+		cmds     string
+	}{
+		{
+			"matching version",
+			watched(str("old"), str("2"), -1) + "+OK\r\n+QUEUED\r\n*1\r\n:2\r\n", 3, nil,
+			commands([]string{"MULTI"}, []string{"DEL", "key", "key" + VERSION_SUFFIX}, []string{"EXEC"}),
+		},
+		{
+			"stale version",
+			watched(str("old"), str("3"), -1) + "+OK\r\n", 3, persistence.ErrVersionMismatch,
+			commands([]string{"UNWATCH"}),
+		},
+		{
+			"changed during the transaction",
+			watched(str("old"), str("2"), -1) + "+OK\r\n+QUEUED\r\n*-1\r\n", 3, persistence.ErrVersionMismatch,
+			commands([]string{"MULTI"}, []string{"DEL", "key", "key" + VERSION_SUFFIX}, []string{"EXEC"}),
+		},
+		{
+			"already gone",
+			watched(nil, nil, -2) + "+OK\r\n", 0, nil,
+			commands([]string{"UNWATCH"}),
+		},
+		{
+			"missing",
+			watched(nil, nil, -2) + "+OK\r\n", 3, persistence.ErrVersionMismatch,
+			commands([]string{"UNWATCH"}),
+		},
+	}
+
+	for _, test := range tests {
+		r, conn := newFake(test.replies)
+		if err := r.DeleteIf("key", test.expected); err != test.err {
+			t.Fatal(test.name + ": unexpected result")
+		}
+		if conn.written.String() != strings.Join(watch("key"), "")+test.cmds {
+			t.Fatalf("%s: unexpected commands %q", test.name, conn.written.String())
+		}
+	}
+}
+
+// Ensures keys under a prefix are scanned and read in one go.
+func TestRedis_ReadAll(t *testing.T) {
+	t.Parallel()
+
+	r, conn := newFake("*2\r\n$1\r\n0\r\n*4\r\n$2\r\n/a\r\n$2\r\n/b\r\n$2\r\n/a\r\n$10\r\n/a" + VERSION_SUFFIX + "\r\n" + "*2\r\n$1\r\n1\r\n$-1\r\n")
+	kvs, err := r.ReadAll("/")
+	if err != nil || len(kvs) != 1 || kvs["/a"] != "1" {
+		t.Fatal("Expired keys and versions should be skipped and duplicates read once")
+	}
+
+	expected := commands([]string{"SCAN", "0", "MATCH", "/*", "COUNT", SCAN_COUNT}, []string{"MGET", "/a", "/b"})
+	if conn.written.String() != expected {
+		t.Fatalf("Unexpected commands %q", conn.written.String())
+	}
+}
+
+// Ensures deleting a key removes its version too.
+func TestRedis_Delete(t *testing.T) {
+	t.Parallel()
+
+	r, conn := newFake("*2\r\n$1\r\n0\r\n*2\r\n$2\r\n/a\r\n$10\r\n/a" + VERSION_SUFFIX + "\r\n" + ":2\r\n")
+	if err := r.Delete("/a"); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := commands([]string{"SCAN", "0", "MATCH", "/a*", "COUNT", SCAN_COUNT}, []string{"DEL", "/a", "/a" + VERSION_SUFFIX})
+	if conn.written.String() != expected {
+		t.Fatalf("Unexpected commands %q", conn.written.String())
+	}
+}
+
+// Ensures errors from the server are returned and broken connections are dropped.
+func TestRedis_Errors(t *testing.T) {
+	t.Parallel()
+
+	r, conn := newFake("-WRONGTYPE Operation against a key\r\n")
+	if _, err := r.Read("key"); err == nil {
+		t.Fatal("Errors from the server should be returned")
+	}
+	if r.conn == nil || conn.closed {
+		t.Fatal("Errors from the server should keep the connection")
+	}
+
+	r, conn = newFake("!garbage\r\n")
+	if _, err := r.Read("key"); err != errProtocol {
+		t.Fatal("Malformed replies should be a protocol error")
+	}
+	if r.conn != nil || !conn.closed {
+		t.Fatal("Malformed replies should drop the connection")
+	}
+
+	r, _ = newFake("+OK\r\n+QUEUED\r\n+QUEUED\r\n+QUEUED\r\n*3\r\n+OK\r\n-ERR hash value is not an integer\r\n:0\r\n")
+	if err := r.UpdateAll(map[string]string{"key": "value"}); err == nil {
+		t.Fatal("Errors inside a transaction should be returned")
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// Ensures commands are encoded as arrays of bulk strings.
+func TestWriteCommand(t *testing.T) {
+	t.Parallel()
+
+	conn := newFakeConn("")
+	w := bufio.NewWriter(conn)
+	writeCommand(w, "SET", "key", "")
+	w.Flush()
+
+	if conn.written.String() != "*3\r\n$3\r\nSET\r\n$3\r\nkey\r\n$0\r\n\r\n" {
+		t.Fatalf("Unexpected encoding %q", conn.written.String())
+	}
+}
+
+// Measures performance of encoding commands.
+func BenchmarkWriteCommand(b *testing.B) {
+	w := bufio.NewWriter(&bytes.Buffer{})
+	for n := 0; n < b.N; n++ {
+		writeCommand(w, "SET", "key", "value")
+	}
+}
+
+// Ensures every reply type is parsed.
+func TestReadReply(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		reply    string
+		expected interface{}
+	}{
+		{"+OK\r\n", "OK"},
+		{"-WRONGTYPE Operation against a key\r\n", redisError("WRONGTYPE Operation against a key")},
+		{":42\r\n", int64(42)},
+		{"$5\r\nva\r\nl\r\n", []byte("va\r\nl")},
+		{"$0\r\n\r\n", []byte{}},
+		{"$-1\r\n", nil},
+		{"*-1\r\n", nil},
+		{"*0\r\n", []interface{}{}},
+		{"*3\r\n$1\r\na\r\n$-1\r\n*1\r\n:1\r\n", []interface{}{[]byte("a"), nil, []interface{}{int64(1)}}},
+	}
+
+	for _, test := range tests {
+		reply, err := readReply(bufio.NewReader(newFakeConn(test.reply)))
+		if err != nil {
+			t.Fatalf("Reply %q could not be read: %s", test.reply, err.Error())
+		}
+		if !reflect.DeepEqual(reply, test.expected) {
+			t.Fatalf("Reply %q was read as %#v", test.reply, reply)
+		}
+	}
+
+	for _, malformed := range []string{"", "\r\n", "?x\r\n", "+OK\n", ":x\r\n", "$x\r\n", "$-2\r\n", "*-2\r\n", "*1\r\n!\r\n"} {
+		if _, err := readReply(bufio.NewReader(newFakeConn(malformed))); err != errProtocol && err != io.EOF {
+			t.Fatalf("Reply %q should be malformed", malformed)
+		}
+	}
+
+	for _, truncated := range []string{"$5\r\nab", "*2\r\n:1\r\n"} {
+		if _, err := readReply(bufio.NewReader(newFakeConn(truncated))); err == nil {
+			t.Fatalf("Reply %q should be truncated", truncated)
+		}
+	}
+}
+
+// Measures performance of parsing an array reply.
+func BenchmarkReadReply(b *testing.B) {
+	reply := []byte("*2\r\n$1\r\na\r\n$-1\r\n")
+	for n := 0; n < b.N; n++ {
+		readReply(bufio.NewReader(bytes.NewReader(reply)))
+	}
+}
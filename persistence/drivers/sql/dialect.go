@@ -0,0 +1,95 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// Covers the differences between the databases the driver supports.
+type Dialect struct {
+	name       string
+	numbered   bool
+	unique     string
+	migrations []string
+}
+
+// Migrations are applied in order and never edited once released, new ones are appended instead.
+// Every statement is formatted with the table name.
+var (
+	POSTGRES = Dialect{
+		name:     "postgres",
+		numbered: true,
+		unique:   "duplicate key value violates unique constraint",
+		migrations: []string{
+			`CREATE TABLE IF NOT EXISTS {table} (
+				name VARCHAR(512) PRIMARY KEY,
+				value TEXT NOT NULL,
+				version BIGINT NOT NULL DEFAULT 1,
+				lease BIGINT,
+				ttl BIGINT,
+				expires BIGINT
+			)`,
+			`CREATE INDEX {table}_lease ON {table} (lease)`,
+		},
+	}
+	MYSQL = Dialect{
+		name:   "mysql",
+		unique: "duplicate entry",
+		migrations: []string{
+			`CREATE TABLE IF NOT EXISTS {table} (
+				name VARCHAR(512) PRIMARY KEY,
+				value LONGTEXT NOT NULL,
+				version BIGINT NOT NULL DEFAULT 1,
+				lease BIGINT,
+				ttl BIGINT,
+				expires BIGINT
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+			`CREATE INDEX {table}_lease ON {table} (lease)`,
+		},
+	}
+)
+
+func (d Dialect) String() string {
+	return d.name
+}
+
+// Queries are written with ? placeholders and rewritten for databases that number them.
+func (d Dialect) rebind(query string) string {
+	if !d.numbered {
+		return query
+	}
+
+	var b bytes.Buffer
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(c)
+	}
+
+	return b.String()
+}
+
+// Reports whether the error is the database rejecting a row that violates a unique key.
+// Errors are matched on their message so the driver doesn't depend on any database/sql driver's types.
+func (d Dialect) duplicate(err error) bool {
+	return err != nil && d.unique != "" && strings.Contains(strings.ToLower(err.Error()), d.unique)
+}
@@ -0,0 +1,374 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"crypto/rand"
+	dbsql "database/sql"
+	"encoding/binary"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/verizonlabs/mesos-framework-sdk/persistence"
+)
+
+var (
+	ErrKeyExists    = errors.New("The key already exists.")
+	ErrLeaseExpired = errors.New("The key held by the lease has expired.")
+	ErrInvalidTable = errors.New("Table names may only contain letters, digits and underscores.")
+)
+
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Stores key/value pairs as rows in a single table.
+// Leased rows carry an expiry that's pushed back on every refresh, expired rows are ignored and replaced on create.
+type SQL struct {
+	db      *dbsql.DB
+	dialect Dialect
+	table   string
+}
+
+// Creates the driver on top of an open database, applying any schema migrations it's missing.
+// The database/sql driver for the dialect must be registered by the caller, timeouts are set in its DSN.
+func NewClient(db *dbsql.DB, dialect Dialect, table string) (*SQL, error) {
+	if !tableName.MatchString(table) {
+		return nil, ErrInvalidTable
+	}
+
+	s := &SQL{
+		db:      db,
+		dialect: dialect,
+		table:   table,
+	}
+	if err := s.migrate(); err != nil {
+		return nil, errors.New("Failed to migrate the " + table + " table: " + err.Error())
+	}
+
+	return s, nil
+}
+
+// Inserts a new key/value pair.
+// This will not overwrite an already existing key.
+func (s *SQL) Create(key, value string) error {
+	return s.create(key, value, nil, nil, nil)
+}
+
+// Creates a key that expires after the TTL in seconds unless its lease is refreshed.
+// This will not overwrite an already existing key.
+func (s *SQL) CreateWithLease(key, value string, ttl int64) (int64, error) {
+	id, err := leaseID()
+	if err != nil {
+		return -1, err
+	}
+
+	expires := time.Now().Unix() + ttl
+	if err := s.create(key, value, &id, &ttl, &expires); err != nil {
+		return -1, err
+	}
+
+	return id, nil
+}
+
+// Reads a key's value.
+func (s *SQL) Read(key string) (string, error) {
+	var value string
+	err := s.db.QueryRow(s.query(
+		"SELECT value FROM {table} WHERE name = ? AND (expires IS NULL OR expires > ?)",
+	), key, time.Now().Unix()).Scan(&value)
+	if err == dbsql.ErrNoRows {
+		return "", nil
+	}
+
+	return value, err
+}
+
+// Reads a key's value along with its row version.
+// Missing or expired keys have a version of 0.
+func (s *SQL) ReadVersion(key string) (string, int64, error) {
+	var value string
+	var version int64
+	err := s.db.QueryRow(s.query(
+		"SELECT value, version FROM {table} WHERE name = ? AND (expires IS NULL OR expires > ?)",
+	), key, time.Now().Unix()).Scan(&value, &version)
+	if err == dbsql.ErrNoRows {
+		return "", 0, nil
+	}
+
+	return value, version, err
+}
+
+// Read all key/values under a specified key.
+func (s *SQL) ReadAll(key string) (map[string]string, error) {
+	rows, err := s.db.Query(s.query(
+		"SELECT name, value FROM {table} WHERE name LIKE ? ESCAPE '!' AND (expires IS NULL OR expires > ?)",
+	), prefix(key), time.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	kvs := make(map[string]string)
+	for rows.Next() {
+		var k, v string
+		if err := rows.Scan(&k, &v); err != nil {
+			return nil, err
+		}
+		kvs[k] = v
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(kvs) > 0 {
+		return kvs, nil
+	}
+
+	return nil, nil
+}
+
+// Updates a key's value, detaching it from any lease.
+// This will overwrite an existing key if present.
+func (s *SQL) Update(key, value string) error {
+	return s.tx(func(tx *dbsql.Tx) error {
+		res, err := tx.Exec(s.query(
+			"UPDATE {table} SET value = ?, version = version + 1, lease = NULL, ttl = NULL, expires = NULL WHERE name = ?",
+		), value, key)
+		if err != nil {
+			return err
+		}
+
+		// MySQL doesn't count rows whose values didn't change so check for the row itself.
+		if n, err := res.RowsAffected(); err != nil || n > 0 {
+			return err
+		}
+		var exists int
+		err = tx.QueryRow(s.query("SELECT 1 FROM {table} WHERE name = ?"), key).Scan(&exists)
+		if err == nil {
+			return nil
+		}
+		if err != dbsql.ErrNoRows {
+			return err
+		}
+
+		_, err = tx.Exec(s.query("INSERT INTO {table} (name, value) VALUES (?, ?)"), key, value)
+
+		return err
+	})
+}
+
+// Only replaces the value if it still matches the old one.
+// Reports whether the swap happened, a missing or expired key never matches.
+func (s *SQL) CompareAndSwap(key, old, value string) (bool, error) {
+	res, err := s.db.Exec(s.query(
+		"UPDATE {table} SET value = ?, version = version + 1 WHERE name = ? AND value = ? AND (expires IS NULL OR expires > ?)",
+	), value, key, old, time.Now().Unix())
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+
+	return n > 0, err
+}
+
+// Updates a key's value only if it hasn't been modified since the expected version was read.
+// An expected version of 0 only creates the key.
+func (s *SQL) UpdateIf(key, value string, expectedVersion int64) error {
+	if expectedVersion == 0 {
+		err := s.Create(key, value)
+		if err == ErrKeyExists {
+			return persistence.ErrVersionMismatch
+		}
+
+		return err
+	}
+
+	res, err := s.db.Exec(s.query(
+		"UPDATE {table} SET value = ?, version = version + 1 WHERE name = ? AND version = ? AND (expires IS NULL OR expires > ?)",
+	), value, key, expectedVersion, time.Now().Unix())
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return persistence.ErrVersionMismatch
+	}
+
+	return nil
+}
+
+// Deletes only the key, not the keys it prefixes, if it hasn't been modified since the expected version was read.
+// An expected version of 0 only succeeds when the key is already gone.
+func (s *SQL) DeleteIf(key string, expectedVersion int64) error {
+	if expectedVersion == 0 {
+		_, version, err := s.ReadVersion(key)
+		if err != nil {
+			return err
+		}
+		if version != 0 {
+			return persistence.ErrVersionMismatch
+		}
+
+		return nil
+	}
+
+	res, err := s.db.Exec(s.query(
+		"DELETE FROM {table} WHERE name = ? AND version = ? AND (expires IS NULL OR expires > ?)",
+	), key, expectedVersion, time.Now().Unix())
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return persistence.ErrVersionMismatch
+	}
+
+	return nil
+}
+
+// Pushes the lease's expiry back by its TTL.
+func (s *SQL) RefreshLease(id int64) error {
+	now := time.Now().Unix()
+	res, err := s.db.Exec(s.query(
+		"UPDATE {table} SET expires = ? + ttl WHERE lease = ? AND expires > ?",
+	), now, id, now)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrLeaseExpired
+	}
+
+	return nil
+}
+
+// Deletes a key/value pair along with every key it prefixes.
+func (s *SQL) Delete(key string) error {
+	_, err := s.db.Exec(s.query("DELETE FROM {table} WHERE name LIKE ? ESCAPE '!'"), prefix(key))
+
+	return err
+}
+
+func (s *SQL) create(key, value string, lease, ttl, expires *int64) error {
+	return s.tx(func(tx *dbsql.Tx) error {
+		var current *int64
+		err := tx.QueryRow(s.query("SELECT expires FROM {table} WHERE name = ?"), key).Scan(&current)
+		switch {
+		case err == dbsql.ErrNoRows:
+		case err != nil:
+			return err
+		case current == nil || *current > time.Now().Unix():
+			return ErrKeyExists
+		default:
+			// The old row's lease ran out so it's replaced.
+			if _, err := tx.Exec(s.query("DELETE FROM {table} WHERE name = ?"), key); err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.Exec(s.query(
+			"INSERT INTO {table} (name, value, lease, ttl, expires) VALUES (?, ?, ?, ?, ?)",
+		), key, value, lease, ttl, expires)
+
+		// Another client can insert the key between the select and the insert, the unique key catches it.
+		if s.dialect.duplicate(err) {
+			return ErrKeyExists
+		}
+
+		return err
+	})
+}
+
+func (s *SQL) tx(f func(*dbsql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := f(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Applies the dialect's migrations that haven't run yet, each in its own transaction.
+func (s *SQL) migrate() error {
+	if _, err := s.db.Exec(s.query(
+		"CREATE TABLE IF NOT EXISTS {table}_migrations (version INT PRIMARY KEY)",
+	)); err != nil {
+		return err
+	}
+
+	var applied int
+	if err := s.db.QueryRow(s.query(
+		"SELECT COALESCE(MAX(version), 0) FROM {table}_migrations",
+	)).Scan(&applied); err != nil {
+		return err
+	}
+
+	for i := applied; i < len(s.dialect.migrations); i++ {
+		version := i + 1
+		err := s.tx(func(tx *dbsql.Tx) error {
+			if _, err := tx.Exec(s.query(s.dialect.migrations[i])); err != nil {
+				return err
+			}
+			_, err := tx.Exec(s.query("INSERT INTO {table}_migrations (version) VALUES (?)"), version)
+
+			return err
+		})
+		if err != nil {
+			return errors.New("Migration " + strconv.Itoa(version) + " failed: " + err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (s *SQL) query(q string) string {
+	return s.dialect.rebind(strings.Replace(q, "{table}", s.table, -1))
+}
+
+// Matches everything starting with the key, LIKE wildcards in the key are matched literally.
+func prefix(key string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(key) + "%"
+}
+
+// Lease IDs are random so clients sharing the table don't need to coordinate.
+func leaseID() (int64, error) {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return -1, err
+	}
+
+	// Keep IDs positive since negative ones signal failures.
+	return int64(binary.BigEndian.Uint64(b[:]) >> 1), nil
+}
@@ -0,0 +1,508 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package sql
+
+import (
+	dbsql "database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/verizonlabs/mesos-framework-sdk/persistence"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var _ persistence.Storage = (*SQL)(nil)
+
+// What the fake database answers a statement with.
+type fakeResult struct {
+	columns  []string
+	rows     [][]driver.Value
+	affected int64
+	err      error
+}
+
+// In-memory database/sql driver that records statements and answers them through a handler.
+type fakeDB struct {
+	mu         sync.Mutex
+	statements []string
+	handle     func(query string, args []driver.Value) fakeResult
+}
+
+func (f *fakeDB) run(query string, args []driver.Value) fakeResult {
+	f.mu.Lock()
+	f.statements = append(f.statements, query)
+	f.mu.Unlock()
+	if f.handle == nil {
+		return fakeResult{}
+	}
+
+	return f.handle(query, args)
+}
+
+func (f *fakeDB) executed() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]string(nil), f.statements...)
+}
+
+type fakeDriver struct{}
+type fakeConn struct{ db *fakeDB }
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+var fakes = struct {
+	sync.Mutex
+	dbs map[string]*fakeDB
+}{dbs: make(map[string]*fakeDB)}
+
+func init() {
+	dbsql.Register("fake", fakeDriver{})
+}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	fakes.Lock()
+	defer fakes.Unlock()
+
+	return &fakeConn{db: fakes.dbs[name]}, nil
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{db: c.db, query: query}, nil
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { c.db.run("BEGIN", nil); return c, nil }
+func (c *fakeConn) Commit() error             { c.db.run("COMMIT", nil); return nil }
+func (c *fakeConn) Rollback() error           { c.db.run("ROLLBACK", nil); return nil }
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	res := s.db.run(s.query, args)
+	if res.err != nil {
+		return nil, res.err
+	}
+
+	return driver.RowsAffected(res.affected), nil
+}
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	res := s.db.run(s.query, args)
+	if res.err != nil {
+		return nil, res.err
+	}
+
+	return &fakeRows{columns: res.columns, rows: res.rows}, nil
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+
+	return nil
+}
+
+// Opens a database backed by a fresh fake, every statement goes through the handler.
+func newFakeDB(t testing.TB, handle func(query string, args []driver.Value) fakeResult) (*dbsql.DB, *fakeDB) {
+	fake := &fakeDB{handle: handle}
+	fakes.Lock()
+	name := strconv.Itoa(len(fakes.dbs))
+	fakes.dbs[name] = fake
+	fakes.Unlock()
+
+	db, err := dbsql.Open("fake", name)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	return db, fake
+}
+
+// Answers the migration bookkeeping as if every migration had already been applied.
+func migrated(d Dialect, next func(query string, args []driver.Value) fakeResult) func(string, []driver.Value) fakeResult {
+	return func(query string, args []driver.Value) fakeResult {
+		if strings.Contains(query, "MAX(version)") {
+			return fakeResult{columns: []string{"version"}, rows: [][]driver.Value{{int64(len(d.migrations))}}}
+		}
+		if next == nil || strings.Contains(query, "_migrations") {
+			return fakeResult{}
+		}
+
+		return next(query, args)
+	}
+}
+
+// Ensures only the migrations that haven't been applied yet are run, each recorded in its own transaction.
+func TestSQL_Migrate(t *testing.T) {
+	t.Parallel()
+
+	db, fake := newFakeDB(t, func(query string, args []driver.Value) fakeResult {
+		if strings.Contains(query, "MAX(version)") {
+			return fakeResult{columns: []string{"version"}, rows: [][]driver.Value{{int64(1)}}}
+		}
+
+		return fakeResult{}
+	})
+	if _, err := NewClient(db, POSTGRES, "kv"); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := []string{
+		"CREATE TABLE IF NOT EXISTS kv_migrations (version INT PRIMARY KEY)",
+		"SELECT COALESCE(MAX(version), 0) FROM kv_migrations",
+		"BEGIN",
+		"CREATE INDEX kv_lease ON kv (lease)",
+		"INSERT INTO kv_migrations (version) VALUES ($1)",
+		"COMMIT",
+	}
+	statements := fake.executed()
+	if len(statements) != len(expected) {
+		t.Fatalf("Expected %d statements, got %q", len(expected), statements)
+	}
+	for i := range expected {
+		if statements[i] != expected[i] {
+			t.Fatalf("Expected %q, got %q", expected[i], statements[i])
+		}
+	}
+}
+
+// Measures performance of migrating an up to date table.
+func BenchmarkSQL_Migrate(b *testing.B) {
+	db, _ := newFakeDB(b, migrated(POSTGRES, nil))
+	for n := 0; n < b.N; n++ {
+		NewClient(db, POSTGRES, "kv")
+	}
+}
+
+// Ensures a failed migration is rolled back, reported and not recorded.
+func TestSQL_MigrateFailure(t *testing.T) {
+	t.Parallel()
+
+	db, fake := newFakeDB(t, func(query string, args []driver.Value) fakeResult {
+		if strings.Contains(query, "MAX(version)") {
+			return fakeResult{columns: []string{"version"}, rows: [][]driver.Value{{int64(0)}}}
+		}
+		if strings.HasPrefix(query, "CREATE TABLE IF NOT EXISTS kv (") {
+			return fakeResult{err: errors.New("permission denied")}
+		}
+
+		return fakeResult{}
+	})
+	_, err := NewClient(db, MYSQL, "kv")
+	if err == nil || err.Error() != "Failed to migrate the kv table: Migration 1 failed: permission denied" {
+		t.Fatalf("Unexpected migration error %v", err)
+	}
+
+	for _, s := range fake.executed() {
+		if s == "COMMIT" || strings.HasPrefix(s, "INSERT INTO kv_migrations") {
+			t.Fatal("Failed migrations should not be committed")
+		}
+	}
+}
+
+// Measures performance of failing a migration.
+func BenchmarkSQL_MigrateFailure(b *testing.B) {
+	db, _ := newFakeDB(b, func(query string, args []driver.Value) fakeResult {
+		if strings.Contains(query, "MAX(version)") {
+			return fakeResult{columns: []string{"version"}, rows: [][]driver.Value{{int64(0)}}}
+		}
+
+		return fakeResult{err: errors.New("permission denied")}
+	})
+	for n := 0; n < b.N; n++ {
+		NewClient(db, MYSQL, "kv")
+	}
+}
+
+// Ensures invalid table names are rejected before any statement is run.
+func TestSQL_InvalidTable(t *testing.T) {
+	t.Parallel()
+
+	db, fake := newFakeDB(t, nil)
+	for _, table := range []string{"", "kv; DROP TABLE users", "1kv", "kv-store"} {
+		if _, err := NewClient(db, POSTGRES, table); err != ErrInvalidTable {
+			t.Fatalf("Table name %q should be rejected", table)
+		}
+	}
+	if len(fake.executed()) > 0 {
+		t.Fatal("No statements should run for invalid table names")
+	}
+}
+
+// Measures performance of rejecting invalid table names.
+func BenchmarkSQL_InvalidTable(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		NewClient(nil, POSTGRES, "kv-store")
+	}
+}
+
+// Ensures a concurrent create that wins the race between the select and the insert surfaces as an existing key.
+func TestSQL_CreateRace(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		dialect Dialect
+		err     error
+	}{
+		{POSTGRES, errors.New(`pq: duplicate key value violates unique constraint "kv_pkey"`)},
+		{MYSQL, errors.New("Error 1062: Duplicate entry 'key' for key 'PRIMARY'")},
+	}
+	for _, test := range tests {
+		test := test
+		db, fake := newFakeDB(t, migrated(test.dialect, func(query string, args []driver.Value) fakeResult {
+			if strings.HasPrefix(query, "INSERT") {
+				return fakeResult{err: test.err}
+			}
+
+			// The select doesn't see the other client's uncommitted row.
+			return fakeResult{columns: []string{"expires"}}
+		}))
+		s, err := NewClient(db, test.dialect, "kv")
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		if err := s.Create("key", "value"); err != ErrKeyExists {
+			t.Fatalf("%s duplicate key errors should map to ErrKeyExists, got %v", test.dialect, err)
+		}
+		if _, err := s.CreateWithLease("key", "value", 10); err != ErrKeyExists {
+			t.Fatalf("%s duplicate key errors should map to ErrKeyExists for leases, got %v", test.dialect, err)
+		}
+		if err := s.UpdateIf("key", "value", 0); err != persistence.ErrVersionMismatch {
+			t.Fatalf("%s duplicate key errors should be version mismatches, got %v", test.dialect, err)
+		}
+
+		statements := fake.executed()
+		if statements[len(statements)-1] != "ROLLBACK" {
+			t.Fatal("Losing creates should be rolled back")
+		}
+	}
+}
+
+// Measures performance of losing a create race.
+func BenchmarkSQL_CreateRace(b *testing.B) {
+	db, _ := newFakeDB(b, migrated(POSTGRES, func(query string, args []driver.Value) fakeResult {
+		if strings.HasPrefix(query, "INSERT") {
+			return fakeResult{err: errors.New("pq: duplicate key value violates unique constraint")}
+		}
+
+		return fakeResult{columns: []string{"expires"}}
+	}))
+	s, _ := NewClient(db, POSTGRES, "kv")
+	for n := 0; n < b.N; n++ {
+		s.Create("key", "value")
+	}
+}
+
+// Ensures other errors while creating are passed through untouched.
+func TestSQL_CreateError(t *testing.T) {
+	t.Parallel()
+
+	failure := errors.New("connection reset by peer")
+	db, _ := newFakeDB(t, migrated(MYSQL, func(query string, args []driver.Value) fakeResult {
+		if strings.HasPrefix(query, "INSERT") {
+			return fakeResult{err: failure}
+		}
+
+		return fakeResult{columns: []string{"expires"}}
+	}))
+	s, err := NewClient(db, MYSQL, "kv")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := s.Create("key", "value"); err != failure {
+		t.Fatalf("Unexpected create error %v", err)
+	}
+}
+
+// Measures performance of failing a create.
+func BenchmarkSQL_CreateError(b *testing.B) {
+	db, _ := newFakeDB(b, migrated(MYSQL, func(query string, args []driver.Value) fakeResult {
+		return fakeResult{err: errors.New("connection reset by peer")}
+	}))
+	s, _ := NewClient(db, MYSQL, "kv")
+	for n := 0; n < b.N; n++ {
+		s.Create("key", "value")
+	}
+}
+
+// Ensures an existing live key is reported without attempting the insert.
+func TestSQL_CreateExisting(t *testing.T) {
+	t.Parallel()
+
+	db, fake := newFakeDB(t, migrated(POSTGRES, func(query string, args []driver.Value) fakeResult {
+		return fakeResult{columns: []string{"expires"}, rows: [][]driver.Value{{nil}}}
+	}))
+	s, err := NewClient(db, POSTGRES, "kv")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := s.Create("key", "value"); err != ErrKeyExists {
+		t.Fatal("Existing keys should not be overwritten by create")
+	}
+	for _, s := range fake.executed() {
+		if strings.HasPrefix(s, "INSERT INTO kv ") {
+			t.Fatal("Existing keys should not be inserted")
+		}
+	}
+}
+
+// Measures performance of creating an existing key.
+func BenchmarkSQL_CreateExisting(b *testing.B) {
+	db, _ := newFakeDB(b, migrated(POSTGRES, func(query string, args []driver.Value) fakeResult {
+		return fakeResult{columns: []string{"expires"}, rows: [][]driver.Value{{nil}}}
+	}))
+	s, _ := NewClient(db, POSTGRES, "kv")
+	for n := 0; n < b.N; n++ {
+		s.Create("key", "value")
+	}
+}
+
+// Ensures conditional deletes only remove the exact key at the expected version.
+func TestSQL_DeleteIf(t *testing.T) {
+	t.Parallel()
+
+	var deleted []driver.Value
+	db, fake := newFakeDB(t, migrated(MYSQL, func(query string, args []driver.Value) fakeResult {
+		if strings.HasPrefix(query, "DELETE") {
+			deleted = args
+			if args[1].(int64) == 3 {
+				return fakeResult{affected: 1}
+			}
+		}
+
+		return fakeResult{columns: []string{"value", "version"}}
+	}))
+	s, err := NewClient(db, MYSQL, "kv")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if err := s.DeleteIf("key", 3); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(deleted) != 3 || deleted[0] != "key" {
+		t.Fatal("Conditional deletes should match the exact key")
+	}
+	if err := s.DeleteIf("key", 2); err != persistence.ErrVersionMismatch {
+		t.Fatal("Stale versions should not delete")
+	}
+	if err := s.DeleteIf("key", 0); err != nil {
+		t.Fatal("A version of 0 should match missing keys")
+	}
+
+	statements := fake.executed()
+	if last := statements[len(statements)-1]; !strings.HasPrefix(last, "SELECT value, version FROM kv WHERE name = ?") {
+		t.Fatal("A version of 0 should only check that the key is gone, ran " + last)
+	}
+	for _, s := range statements {
+		if strings.Contains(s, "LIKE") {
+			t.Fatal("Conditional deletes should not match by prefix")
+		}
+	}
+}
+
+// Ensures placeholders are numbered for Postgres and left alone for MySQL.
+func TestDialect_Rebind(t *testing.T) {
+	t.Parallel()
+
+	query := "UPDATE kv SET value = ? WHERE name = ? AND version = ?"
+	if q := POSTGRES.rebind(query); q != "UPDATE kv SET value = $1 WHERE name = $2 AND version = $3" {
+		t.Fatalf("Unexpected Postgres query %q", q)
+	}
+	if q := MYSQL.rebind(query); q != query {
+		t.Fatalf("Unexpected MySQL query %q", q)
+	}
+	if q := POSTGRES.rebind("SELECT 1"); q != "SELECT 1" {
+		t.Fatalf("Queries without placeholders should be unchanged, got %q", q)
+	}
+}
+
+// Measures performance of rebinding placeholders.
+func BenchmarkDialect_Rebind(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		POSTGRES.rebind("UPDATE kv SET value = ? WHERE name = ? AND version = ?")
+	}
+}
+
+// Ensures only each dialect's own unique violations are treated as duplicates.
+func TestDialect_Duplicate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		dialect   Dialect
+		err       error
+		duplicate bool
+	}{
+		{POSTGRES, errors.New(`pq: duplicate key value violates unique constraint "kv_pkey"`), true},
+		{POSTGRES, errors.New(`ERROR: duplicate key value violates unique constraint "kv_pkey" (SQLSTATE 23505)`), true},
+		{POSTGRES, errors.New("Error 1062: Duplicate entry 'key' for key 'PRIMARY'"), false},
+		{POSTGRES, nil, false},
+		{MYSQL, errors.New("Error 1062: Duplicate entry 'key' for key 'PRIMARY'"), true},
+		{MYSQL, errors.New("Error 1213: Deadlock found when trying to get lock"), false},
+		{MYSQL, nil, false},
+	}
+	for _, test := range tests {
+		if test.dialect.duplicate(test.err) != test.duplicate {
+			t.Fatalf("%s: expected duplicate to be %v for %v", test.dialect, test.duplicate, test.err)
+		}
+	}
+}
+
+// Measures performance of matching unique violations.
+func BenchmarkDialect_Duplicate(b *testing.B) {
+	err := errors.New("Error 1062: Duplicate entry 'key' for key 'PRIMARY'")
+	for n := 0; n < b.N; n++ {
+		MYSQL.duplicate(err)
+	}
+}
+
+// Ensures LIKE wildcards and the escape character in keys are matched literally.
+func TestPrefix(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"/tasks/": "/tasks/%",
+		"100%":    "100!%%",
+		"task_1":  "task!_1%",
+		"wow!":    "wow!!%",
+		"":        "%",
+		"a!_%b":   "a!!!_!%b%",
+	}
+	for key, expected := range tests {
+		if p := prefix(key); p != expected {
+			t.Fatalf("Expected %q for %q, got %q", expected, key, p)
+		}
+	}
+}
+
+// Measures performance of escaping key prefixes.
+func BenchmarkPrefix(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		prefix("/tasks/task_1")
+	}
+}
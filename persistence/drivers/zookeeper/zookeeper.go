@@ -0,0 +1,298 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zookeeper
+
+import (
+	"errors"
+	"github.com/verizonlabs/mesos-framework-sdk/internal/zk"
+	"github.com/verizonlabs/mesos-framework-sdk/persistence"
+	"path"
+	"runtime"
+	"strings"
+	"time"
+)
+
+var (
+	ErrKeyExists    = errors.New("The key already exists.")
+	ErrLeaseExpired = errors.New("The session holding the lease has expired.")
+)
+
+// The subset of the ZooKeeper connection used by the driver.
+type conn interface {
+	Create(path string, data []byte, flags int32, acl []zk.ACL) (string, error)
+	Get(path string) ([]byte, *zk.Stat, error)
+	Set(path string, data []byte, version int32) (*zk.Stat, error)
+	Delete(path string, version int32) error
+	Children(path string) ([]string, error)
+	SessionID() int64
+	Close()
+}
+
+// Stores keys as znodes, parent znodes are created as needed.
+// Leases are backed by ephemeral znodes so they live as long as the client's session.
+type Zookeeper struct {
+	conn conn
+	acl  []zk.ACL
+}
+
+// Creates a new ZooKeeper client connected to the given servers.
+func NewClient(servers []string, sessionTimeout time.Duration) (*Zookeeper, error) {
+	c, err := zk.Connect(servers, sessionTimeout)
+	if err != nil {
+		return nil, errors.New("Failed to create zookeeper client: " + err.Error())
+	}
+
+	z := &Zookeeper{
+		conn: c,
+		acl:  zk.WorldACL(zk.PERM_ALL),
+	}
+	runtime.SetFinalizer(z, z.finalizer)
+
+	return z, nil
+}
+
+// Close the connection once we're GCed.
+func (z *Zookeeper) finalizer(f *Zookeeper) {
+	z.conn.Close()
+}
+
+// Inserts a new key/value pair.
+// This will not overwrite an already existing key.
+func (z *Zookeeper) Create(key, value string) error {
+	err := z.create(key, value, 0)
+	if err == zk.ErrNodeExists {
+		return ErrKeyExists
+	}
+
+	return err
+}
+
+// Creates a key that's removed once the client's session ends.
+// The TTL is covered by the session timeout, the session ID is handed back as the lease.
+// This will not overwrite an already existing key.
+func (z *Zookeeper) CreateWithLease(key, value string, ttl int64) (int64, error) {
+	id := z.conn.SessionID()
+	err := z.create(key, value, zk.FLAG_EPHEMERAL)
+	if err == zk.ErrNodeExists {
+		return -1, ErrKeyExists
+	}
+	if err != nil {
+		return -1, err
+	}
+
+	return id, nil
+}
+
+// Reads a key's value.
+func (z *Zookeeper) Read(key string) (string, error) {
+	data, _, err := z.conn.Get(normalize(key))
+	if err == zk.ErrNoNode {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// Reads a key's value along with its znode version.
+// ZooKeeper versions start at 0 so they're shifted up by one, missing keys have a version of 0.
+func (z *Zookeeper) ReadVersion(key string) (string, int64, error) {
+	data, stat, err := z.conn.Get(normalize(key))
+	if err == zk.ErrNoNode {
+		return "", 0, nil
+	}
+	if err != nil {
+		return "", 0, err
+	}
+
+	return string(data), int64(stat.Version) + 1, nil
+}
+
+// Read all key/values under a specified key, including the key itself if it holds a value.
+func (z *Zookeeper) ReadAll(key string) (map[string]string, error) {
+	kvs := make(map[string]string)
+	if err := z.readAll(normalize(key), kvs); err != nil {
+		return nil, err
+	}
+
+	if len(kvs) == 0 {
+		return nil, nil
+	}
+
+	// Keys are handed back the way they were given to us.
+	if strings.HasPrefix(key, "/") {
+		return kvs, nil
+	}
+
+	relative := make(map[string]string, len(kvs))
+	for k, v := range kvs {
+		relative[strings.TrimPrefix(k, "/")] = v
+	}
+
+	return relative, nil
+}
+
+// Updates a key's value.
+// This will overwrite an existing key if present.
+func (z *Zookeeper) Update(key, value string) error {
+	_, err := z.conn.Set(normalize(key), []byte(value), -1)
+	if err == zk.ErrNoNode {
+		return z.create(key, value, 0)
+	}
+
+	return err
+}
+
+// Updates a key's value only if it hasn't been modified since the expected version was read.
+// An expected version of 0 only creates the key.
+func (z *Zookeeper) UpdateIf(key, value string, expectedVersion int64) error {
+	var err error
+	if expectedVersion == 0 {
+		err = z.create(key, value, 0)
+	} else {
+		_, err = z.conn.Set(normalize(key), []byte(value), int32(expectedVersion-1))
+	}
+
+	switch err {
+	case zk.ErrNodeExists, zk.ErrNoNode, zk.ErrBadVersion:
+		return persistence.ErrVersionMismatch
+	}
+
+	return err
+}
+
+// Deletes only the key if it hasn't been modified since the expected version was read.
+// An expected version of 0 only succeeds when the key is already gone, ZooKeeper refuses to delete keys with children.
+func (z *Zookeeper) DeleteIf(key string, expectedVersion int64) error {
+	if expectedVersion == 0 {
+		_, _, err := z.conn.Get(normalize(key))
+		switch err {
+		case nil:
+			return persistence.ErrVersionMismatch
+		case zk.ErrNoNode:
+			return nil
+		}
+
+		return err
+	}
+
+	err := z.conn.Delete(normalize(key), int32(expectedVersion-1))
+	switch err {
+	case zk.ErrNoNode, zk.ErrBadVersion:
+		return persistence.ErrVersionMismatch
+	}
+
+	return err
+}
+
+// Leases last as long as the session so this only checks that it's still the same one.
+func (z *Zookeeper) RefreshLease(id int64) error {
+	if z.conn.SessionID() != id {
+		return ErrLeaseExpired
+	}
+
+	return nil
+}
+
+// Deletes a key/value pair along with everything under it.
+func (z *Zookeeper) Delete(key string) error {
+	err := z.delete(normalize(key))
+	if err == zk.ErrNoNode {
+		return nil
+	}
+
+	return err
+}
+
+func (z *Zookeeper) create(key, value string, flags int32) error {
+	key = normalize(key)
+	if err := z.createParents(path.Dir(key)); err != nil {
+		return err
+	}
+
+	_, err := z.conn.Create(key, []byte(value), flags, z.acl)
+
+	return err
+}
+
+// Creates every missing znode on the way to the path, ephemeral znodes can't have children.
+func (z *Zookeeper) createParents(p string) error {
+	if p == "/" {
+		return nil
+	}
+
+	current := ""
+	for _, part := range strings.Split(strings.TrimPrefix(p, "/"), "/") {
+		current += "/" + part
+		_, err := z.conn.Create(current, nil, 0, z.acl)
+		if err != nil && err != zk.ErrNodeExists {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (z *Zookeeper) readAll(p string, kvs map[string]string) error {
+	data, _, err := z.conn.Get(p)
+	if err == zk.ErrNoNode {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(data) > 0 {
+		kvs[p] = string(data)
+	}
+
+	children, err := z.conn.Children(p)
+	if err == zk.ErrNoNode {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, child := range children {
+		if err := z.readAll(path.Join(p, child), kvs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ZooKeeper refuses to delete znodes with children so they're removed first.
+func (z *Zookeeper) delete(p string) error {
+	children, err := z.conn.Children(p)
+	if err != nil {
+		return err
+	}
+
+	for _, child := range children {
+		if err := z.delete(path.Join(p, child)); err != nil && err != zk.ErrNoNode {
+			return err
+		}
+	}
+
+	return z.conn.Delete(p, -1)
+}
+
+// ZooKeeper paths must be absolute and can't end in a slash.
+func normalize(key string) string {
+	return path.Clean("/" + key)
+}
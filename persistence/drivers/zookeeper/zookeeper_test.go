@@ -0,0 +1,301 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zookeeper
+
+import (
+	"github.com/verizonlabs/mesos-framework-sdk/internal/zk"
+	"github.com/verizonlabs/mesos-framework-sdk/persistence"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+type znode struct {
+	data      []byte
+	version   int32
+	ephemeral bool
+}
+
+// In-memory stand-in for a ZooKeeper connection that enforces the same rules as a server.
+type fakeConn struct {
+	nodes   map[string]*znode
+	session int64
+}
+
+func newFakeConn() *fakeConn {
+	return &fakeConn{nodes: make(map[string]*znode), session: 1}
+}
+
+func (f *fakeConn) Create(p string, data []byte, flags int32, acl []zk.ACL) (string, error) {
+	if _, ok := f.nodes[p]; ok {
+		return "", zk.ErrNodeExists
+	}
+	if parent, ok := f.nodes[path.Dir(p)]; path.Dir(p) != "/" && (!ok || parent.ephemeral) {
+		return "", zk.ErrNoNode
+	}
+
+	f.nodes[p] = &znode{data: data, ephemeral: flags&zk.FLAG_EPHEMERAL != 0}
+
+	return p, nil
+}
+
+func (f *fakeConn) Get(p string) ([]byte, *zk.Stat, error) {
+	n, ok := f.nodes[p]
+	if !ok {
+		return nil, nil, zk.ErrNoNode
+	}
+
+	return n.data, &zk.Stat{Version: n.version}, nil
+}
+
+func (f *fakeConn) Set(p string, data []byte, version int32) (*zk.Stat, error) {
+	n, ok := f.nodes[p]
+	if !ok {
+		return nil, zk.ErrNoNode
+	}
+	if version != -1 && version != n.version {
+		return nil, zk.ErrBadVersion
+	}
+	n.data = data
+	n.version++
+
+	return &zk.Stat{Version: n.version}, nil
+}
+
+func (f *fakeConn) Delete(p string, version int32) error {
+	n, ok := f.nodes[p]
+	if !ok {
+		return zk.ErrNoNode
+	}
+	if version != -1 && version != n.version {
+		return zk.ErrBadVersion
+	}
+	if children, _ := f.Children(p); len(children) > 0 {
+		return zk.ErrNotEmpty
+	}
+	delete(f.nodes, p)
+
+	return nil
+}
+
+func (f *fakeConn) Children(p string) ([]string, error) {
+	if _, ok := f.nodes[p]; !ok && p != "/" {
+		return nil, zk.ErrNoNode
+	}
+
+	prefix := strings.TrimSuffix(p, "/") + "/"
+	var children []string
+	for child := range f.nodes {
+		if strings.HasPrefix(child, prefix) && !strings.Contains(strings.TrimPrefix(child, prefix), "/") {
+			children = append(children, strings.TrimPrefix(child, prefix))
+		}
+	}
+
+	return children, nil
+}
+
+func (f *fakeConn) SessionID() int64 {
+	return f.session
+}
+
+func (f *fakeConn) Close() {}
+
+func newFake() (*Zookeeper, *fakeConn) {
+	conn := newFakeConn()
+
+	return &Zookeeper{conn: conn, acl: zk.WorldACL(zk.PERM_ALL)}, conn
+}
+
+// Ensures keys are created with their parents and can be read back.
+func TestZookeeper_Create(t *testing.T) {
+	t.Parallel()
+
+	z, conn := newFake()
+	if err := z.Create("/tasks/a", "1"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := z.Create("/tasks/a", "2"); err != ErrKeyExists {
+		t.Fatal("Existing keys should not be overwritten by create")
+	}
+	if _, ok := conn.nodes["/tasks"]; !ok {
+		t.Fatal("Parent znodes should have been created")
+	}
+
+	if value, err := z.Read("tasks/a/"); err != nil || value != "1" {
+		t.Fatal("Keys should be read back regardless of slashes")
+	}
+	if value, err := z.Read("/tasks/missing"); err != nil || value != "" {
+		t.Fatal("Missing keys should read as empty")
+	}
+
+	if err := z.Update("/tasks/b", "1"); err != nil {
+		t.Fatal("Updating a missing key should create it: " + err.Error())
+	}
+	if err := z.Update("/tasks/b", "2"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if value, _ := z.Read("/tasks/b"); value != "2" {
+		t.Fatal("Key should have been updated")
+	}
+}
+
+// Measures performance of creating keys.
+func BenchmarkZookeeper_Create(b *testing.B) {
+	z, conn := newFake()
+	for n := 0; n < b.N; n++ {
+		z.Create("/tasks/a", "1")
+		delete(conn.nodes, "/tasks/a")
+	}
+}
+
+// Ensures versions guard conditional updates.
+func TestZookeeper_UpdateIf(t *testing.T) {
+	t.Parallel()
+
+	z, _ := newFake()
+	if _, version, _ := z.ReadVersion("/tasks/a"); version != 0 {
+		t.Fatal("Missing keys should have a version of 0")
+	}
+	if err := z.UpdateIf("/tasks/a", "1", 0); err != nil {
+		t.Fatal("A version of 0 should create missing keys: " + err.Error())
+	}
+	if err := z.UpdateIf("/tasks/a", "1", 0); err != persistence.ErrVersionMismatch {
+		t.Fatal("A version of 0 should not overwrite existing keys")
+	}
+
+	value, version, _ := z.ReadVersion("/tasks/a")
+	if value != "1" || version != 1 {
+		t.Fatal("New keys should be at version 1")
+	}
+	if err := z.UpdateIf("/tasks/a", "2", version); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := z.UpdateIf("/tasks/a", "3", version); err != persistence.ErrVersionMismatch {
+		t.Fatal("Stale versions should be rejected")
+	}
+	if err := z.UpdateIf("/tasks/missing", "1", 1); err != persistence.ErrVersionMismatch {
+		t.Fatal("Missing keys should not match a version")
+	}
+}
+
+// Ensures conditional deletes check the znode version and leave its siblings alone.
+func TestZookeeper_DeleteIf(t *testing.T) {
+	t.Parallel()
+
+	z, conn := newFake()
+	z.Update("/tasks/a", "1")
+	z.Update("/tasks/a", "2")
+	z.Update("/tasks/ab", "1")
+	if err := z.DeleteIf("/tasks/a", 1); err != persistence.ErrVersionMismatch {
+		t.Fatal("Stale versions should not delete")
+	}
+	if err := z.DeleteIf("/tasks/a", 0); err != persistence.ErrVersionMismatch {
+		t.Fatal("A version of 0 should not delete existing keys")
+	}
+	if err := z.DeleteIf("/tasks/a", 2); err != nil {
+		t.Fatal(err.Error())
+	}
+	if _, ok := conn.nodes["/tasks/a"]; ok {
+		t.Fatal("Matching versions should delete the key")
+	}
+	if _, ok := conn.nodes["/tasks/ab"]; !ok {
+		t.Fatal("Conditional deletes should only remove the exact key")
+	}
+	if err := z.DeleteIf("/tasks/a", 0); err != nil {
+		t.Fatal("A version of 0 should match missing keys")
+	}
+	if err := z.DeleteIf("/tasks/a", 2); err != persistence.ErrVersionMismatch {
+		t.Fatal("Missing keys should not match a version")
+	}
+}
+
+// Ensures keys under a prefix are returned the way they were asked for.
+func TestZookeeper_ReadAll(t *testing.T) {
+	t.Parallel()
+
+	z, _ := newFake()
+	z.Create("/tasks/a", "1")
+	z.Create("/tasks/b/c", "2")
+	z.Create("/other", "3")
+
+	kvs, err := z.ReadAll("/tasks")
+	if err != nil || len(kvs) != 2 || kvs["/tasks/a"] != "1" || kvs["/tasks/b/c"] != "2" {
+		t.Fatal("Absolute prefixes should return absolute keys without empty parents")
+	}
+
+	kvs, err = z.ReadAll("tasks")
+	if err != nil || len(kvs) != 2 || kvs["tasks/a"] != "1" || kvs["tasks/b/c"] != "2" {
+		t.Fatal("Relative prefixes should return relative keys")
+	}
+
+	if kvs, err := z.ReadAll("/missing"); err != nil || kvs != nil {
+		t.Fatal("Missing prefixes should return nothing")
+	}
+}
+
+// Ensures leased keys are ephemeral and tied to the session.
+func TestZookeeper_Leases(t *testing.T) {
+	t.Parallel()
+
+	z, conn := newFake()
+	id, err := z.CreateWithLease("/leader", "me", 10)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !conn.nodes["/leader"].ephemeral {
+		t.Fatal("Leased keys should be ephemeral")
+	}
+	if err := z.RefreshLease(id); err != nil {
+		t.Fatal("The session's lease should be refreshed: " + err.Error())
+	}
+
+	conn.session++
+	if err := z.RefreshLease(id); err != ErrLeaseExpired {
+		t.Fatal("Leases from an earlier session should be expired")
+	}
+}
+
+// Ensures deletes remove everything under a key.
+func TestZookeeper_Delete(t *testing.T) {
+	t.Parallel()
+
+	z, conn := newFake()
+	z.Create("/tasks/a/b", "1")
+	z.Create("/tasks/c", "2")
+	z.Create("/other", "3")
+
+	if err := z.Delete("/tasks"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(conn.nodes) != 1 || conn.nodes["/other"] == nil {
+		t.Fatal("Only the keys under the deleted key should be removed")
+	}
+	if err := z.Delete("/missing"); err != nil {
+		t.Fatal("Deleting a missing key should succeed")
+	}
+}
+
+// Ensures connection failures are returned instead of panicking.
+func TestNewClient(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewClient(nil, time.Second); err == nil {
+		t.Fatal("Clients without servers should fail to connect")
+	}
+	if _, err := NewClient([]string{"127.0.0.1:1"}, time.Second); err == nil {
+		t.Fatal("Clients should fail when no server is reachable")
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"github.com/verizonlabs/mesos-framework-sdk/persistence"
+)
+
+// Encrypted values are stored as the prefix, the ID of the key used and the base64 nonce and ciphertext.
+const PREFIX = "enc:v1:"
+
+var (
+	ErrNotEncrypted = errors.New("The stored value isn't encrypted.")
+	ErrInvalidKeyID = errors.New("Key IDs must be non-empty and can't contain colons.")
+)
+
+// KeyProvider Interface hands out the AES keys values are encrypted with.
+// Implementations can fetch keys from a KMS, older keys must stay available by ID so existing values can be read.
+type KeyProvider interface {
+	Current() (id string, key []byte, err error)
+	Key(id string) ([]byte, error)
+}
+
+// Encrypts values with AES-GCM before they reach the underlying store and decrypts them on the way back.
+// Keys are used as additional data so an encrypted value can't be copied under another key.
+type Store struct {
+	storage persistence.KeyValueStore
+	keys    KeyProvider
+}
+
+func NewStore(storage persistence.KeyValueStore, keys KeyProvider) *Store {
+	return &Store{
+		storage: storage,
+		keys:    keys,
+	}
+}
+
+// Inserts a new key/value pair.
+// This will not overwrite an already existing key.
+func (s *Store) Create(key, value string) error {
+	encrypted, err := s.encrypt(key, value)
+	if err != nil {
+		return err
+	}
+
+	return s.storage.Create(key, encrypted)
+}
+
+// Creates a key with a specified TTL.
+// This will not overwrite an already existing key.
+func (s *Store) CreateWithLease(key, value string, ttl int64) (int64, error) {
+	encrypted, err := s.encrypt(key, value)
+	if err != nil {
+		return -1, err
+	}
+
+	return s.storage.CreateWithLease(key, encrypted, ttl)
+}
+
+// Reads a key's value.
+func (s *Store) Read(key string) (string, error) {
+	value, err := s.storage.Read(key)
+	if err != nil || value == "" {
+		return value, err
+	}
+
+	return s.decrypt(key, value)
+}
+
+// Read all key/values under a specified key.
+func (s *Store) ReadAll(key string) (map[string]string, error) {
+	kvs, err := s.storage.ReadAll(key)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range kvs {
+		decrypted, err := s.decrypt(k, v)
+		if err != nil {
+			return nil, errors.New("Failed to decrypt " + k + ": " + err.Error())
+		}
+		kvs[k] = decrypted
+	}
+
+	return kvs, nil
+}
+
+// Updates a key's value.
+// This will overwrite an existing key if present.
+func (s *Store) Update(key, value string) error {
+	encrypted, err := s.encrypt(key, value)
+	if err != nil {
+		return err
+	}
+
+	return s.storage.Update(key, encrypted)
+}
+
+// Refreshes a lease once.
+func (s *Store) RefreshLease(id int64) error {
+	return s.storage.RefreshLease(id)
+}
+
+// Deletes a key/value pair.
+func (s *Store) Delete(key string) error {
+	return s.storage.Delete(key)
+}
+
+func (s *Store) encrypt(key, value string) (string, error) {
+	id, k, err := s.keys.Current()
+	if err != nil {
+		return "", err
+	}
+	if id == "" || strings.Contains(id, ":") {
+		return "", ErrInvalidKeyID
+	}
+
+	gcm, err := newGCM(k)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(value), []byte(key))
+
+	return PREFIX + id + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *Store) decrypt(key, value string) (string, error) {
+	if !strings.HasPrefix(value, PREFIX) {
+		return "", ErrNotEncrypted
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(value, PREFIX), ":", 2)
+	if len(parts) != 2 {
+		return "", ErrNotEncrypted
+	}
+
+	k, err := s.keys.Key(parts[0])
+	if err != nil {
+		return "", err
+	}
+	gcm, err := newGCM(k)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("The encrypted value is too short.")
+	}
+
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(key))
+	if err != nil {
+		return "", err
+	}
+
+	return string(plain), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
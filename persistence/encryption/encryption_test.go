@@ -0,0 +1,127 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+type memoryStorage struct {
+	data map[string]string
+	sync.Mutex
+}
+
+func (m *memoryStorage) Create(key, value string) error {
+	return m.Update(key, value)
+}
+
+func (m *memoryStorage) CreateWithLease(key, value string, ttl int64) (int64, error) {
+	return 0, m.Update(key, value)
+}
+
+func (m *memoryStorage) Read(key string) (string, error) {
+	m.Lock()
+	defer m.Unlock()
+	return m.data[key], nil
+}
+
+func (m *memoryStorage) ReadAll(key string) (map[string]string, error) {
+	m.Lock()
+	defer m.Unlock()
+	kvs := make(map[string]string)
+	for k, v := range m.data {
+		if strings.HasPrefix(k, key) {
+			kvs[k] = v
+		}
+	}
+	return kvs, nil
+}
+
+func (m *memoryStorage) Update(key, value string) error {
+	m.Lock()
+	defer m.Unlock()
+	m.data[key] = value
+	return nil
+}
+
+func (m *memoryStorage) RefreshLease(int64) error {
+	return nil
+}
+
+func (m *memoryStorage) Delete(key string) error {
+	m.Lock()
+	defer m.Unlock()
+	delete(m.data, key)
+	return nil
+}
+
+var testKeys = map[string][]byte{
+	"old": []byte("0123456789abcdef"),
+	"new": []byte("0123456789abcdef0123456789abcdef"),
+}
+
+// Ensures values are encrypted at rest, readable after key rotation and bound to their key.
+func TestStore(t *testing.T) {
+	t.Parallel()
+
+	storage := &memoryStorage{data: make(map[string]string)}
+	old, _ := StaticKeys("old", testKeys)
+	if err := NewStore(storage, old).Create("/tasks/a", "secret"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if strings.Contains(storage.data["/tasks/a"], "secret") || !strings.HasPrefix(storage.data["/tasks/a"], PREFIX+"old:") {
+		t.Fatal("Values should be encrypted before they're stored")
+	}
+
+	current, _ := StaticKeys("new", testKeys)
+	s := NewStore(storage, current)
+	if value, err := s.Read("/tasks/a"); err != nil || value != "secret" {
+		t.Fatal("Values encrypted with older keys should still be readable")
+	}
+
+	s.Update("/tasks/b", "other")
+	if kvs, err := s.ReadAll("/tasks"); err != nil || kvs["/tasks/a"] != "secret" || kvs["/tasks/b"] != "other" {
+		t.Fatal("Every value under the prefix should be decrypted")
+	}
+
+	storage.data["/tasks/c"] = storage.data["/tasks/a"]
+	if _, err := s.Read("/tasks/c"); err == nil {
+		t.Fatal("Values copied under another key should fail to decrypt")
+	}
+
+	storage.data["/tasks/d"] = "plain"
+	if _, err := s.Read("/tasks/d"); err != ErrNotEncrypted {
+		t.Fatal("Plaintext values should be rejected")
+	}
+
+	if _, err := StaticKeys("missing", testKeys); err != ErrUnknownKey {
+		t.Fatal("The current key must be one of the given keys")
+	}
+	if _, err := StaticKeys("bad", map[string][]byte{"bad": []byte("short")}); err == nil {
+		t.Fatal("Keys of the wrong size should be rejected")
+	}
+}
+
+// Measures a write and read through the encrypting store.
+func BenchmarkStore(b *testing.B) {
+	keys, _ := StaticKeys("new", testKeys)
+	s := NewStore(&memoryStorage{data: make(map[string]string)}, keys)
+	for n := 0; n < b.N; n++ {
+		s.Update("/tasks/a", "secret")
+		s.Read("/tasks/a")
+	}
+}
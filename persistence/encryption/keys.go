@@ -0,0 +1,58 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"crypto/aes"
+	"errors"
+	"strconv"
+)
+
+var ErrUnknownKey = errors.New("No key exists with the given ID.")
+
+// Holds a fixed set of keys, new values are encrypted with the current one.
+type staticKeys struct {
+	current string
+	keys    map[string][]byte
+}
+
+// Creates a key provider from keys held in memory, keyed by their IDs.
+// Keys must be 16, 24 or 32 bytes long to select AES-128, AES-192 or AES-256.
+func StaticKeys(current string, keys map[string][]byte) (KeyProvider, error) {
+	if _, ok := keys[current]; !ok {
+		return nil, ErrUnknownKey
+	}
+
+	for id, k := range keys {
+		if _, err := aes.NewCipher(k); err != nil {
+			return nil, errors.New("Key " + id + " is " + strconv.Itoa(len(k)) + " bytes, it must be 16, 24 or 32.")
+		}
+	}
+
+	return staticKeys{current: current, keys: keys}, nil
+}
+
+func (s staticKeys) Current() (string, []byte, error) {
+	return s.current, s.keys[s.current], nil
+}
+
+func (s staticKeys) Key(id string) ([]byte, error) {
+	k, ok := s.keys[id]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	return k, nil
+}
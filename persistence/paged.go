@@ -0,0 +1,21 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package persistence
+
+// PagedReader Interface reads the keys under a prefix a page at a time so large prefixes don't have to fit in memory.
+// Pages are in key order, the next key is passed back in to continue and is empty once every key was read.
+type PagedReader interface {
+	ReadAllPaged(prefix string, limit int64, startKey string) (map[string]string, string, error)
+}
@@ -0,0 +1,29 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package persistence
+
+import "errors"
+
+var ErrVersionMismatch = errors.New("The key was changed since the expected version was read.")
+
+// Storage Interface adds versioned reads, compare-and-swap and compare-and-delete on top of a key value backend.
+// This lets multiple scheduler replicas coordinate changes to shared state.
+// Versions are opaque to callers, a version of 0 means the key doesn't exist.
+type Storage interface {
+	KeyValueStore
+	ReadVersion(key string) (string, int64, error)
+	UpdateIf(key, value string, expectedVersion int64) error
+	DeleteIf(key string, expectedVersion int64) error
+}
@@ -15,38 +15,24 @@
 package recordio
 
 import (
-	"bufio"
 	"errors"
-	"io"
 	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_executor"
 	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_scheduler"
-	"strconv"
-	"strings"
+	"io"
 
 	"github.com/golang/protobuf/proto"
 )
 
 // Decode continually reads and constructs events from the Mesos stream.
 func Decode(data io.ReadCloser, events interface{}) error {
-	reader := bufio.NewReader(data)
+	reader := NewReader(data)
 
 	for {
-		lengthStr, err := reader.ReadString('\n')
+		buffer, err := reader.ReadRecord()
 		if err != nil {
 			return err
 		}
 
-		lengthInt, err := strconv.Atoi(strings.TrimRight(lengthStr, "\n"))
-		if err != nil {
-			return errors.New("RecordIO message length is not a number: " + err.Error())
-		}
-
-		buffer := make([]byte, lengthInt)
-		n, err := io.ReadFull(reader, buffer)
-		if n != lengthInt {
-			return errors.New("Amount of bytes read does not match the RecordIO message length")
-		}
-
 		switch events := events.(type) {
 		case chan *mesos_v1_scheduler.Event:
 			var event mesos_v1_scheduler.Event
@@ -0,0 +1,132 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recordio
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"strconv"
+
+	"github.com/golang/protobuf/proto"
+)
+
+const (
+	DEFAULT_MAX_SIZE  = 64 << 20 // Larger records are skipped instead of being buffered.
+	MAX_HEADER_LENGTH = 20       // Enough digits for any record length.
+)
+
+// Returned when a record is larger than the reader allows.
+// The record is discarded, so the next read continues with the record after it.
+type ErrRecordTooLarge struct {
+	Size uint64
+	Max  int
+}
+
+func (e *ErrRecordTooLarge) Error() string {
+	return "RecordIO record of " + strconv.FormatUint(e.Size, 10) + " bytes exceeds the limit of " +
+		strconv.Itoa(e.Max) + " bytes"
+}
+
+// Returned when a record header isn't a length.
+// The rest of the header line is discarded, so the next read resumes at the following line.
+type ErrCorruptFrame struct {
+	Header string
+}
+
+func (e *ErrCorruptFrame) Error() string {
+	return "RecordIO record length is not a number: " + strconv.Quote(e.Header)
+}
+
+// Streaming reader for RecordIO framed data, such as the Mesos event stream.
+// Records may be split across any number of underlying reads.
+type Reader struct {
+	MaxSize int
+	reader  *bufio.Reader
+	resync  bool
+}
+
+// Returns a reader that limits records to DEFAULT_MAX_SIZE.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{
+		MaxSize: DEFAULT_MAX_SIZE,
+		reader:  bufio.NewReader(r),
+	}
+}
+
+// Reads the next record.
+// Returns io.EOF at the end of the stream and io.ErrUnexpectedEOF when it ends in the middle of a record.
+func (r *Reader) ReadRecord() ([]byte, error) {
+	for r.resync {
+		_, err := r.reader.ReadSlice('\n')
+		if err == nil {
+			r.resync = false
+		} else if err != bufio.ErrBufferFull {
+			return nil, err
+		}
+	}
+
+	header := make([]byte, 0, MAX_HEADER_LENGTH)
+	for {
+		b, err := r.reader.ReadByte()
+		if err == io.EOF && len(header) > 0 {
+			return nil, io.ErrUnexpectedEOF
+		} else if err != nil {
+			return nil, err
+		}
+
+		if b == '\n' {
+			break
+		}
+
+		if len(header) == MAX_HEADER_LENGTH {
+			r.resync = true
+			return nil, &ErrCorruptFrame{Header: string(header)}
+		}
+		header = append(header, b)
+	}
+
+	size, err := strconv.ParseUint(string(header), 10, 63)
+	if err != nil {
+		return nil, &ErrCorruptFrame{Header: string(header)}
+	}
+
+	if r.MaxSize > 0 && size > uint64(r.MaxSize) {
+		if _, err := io.CopyN(ioutil.Discard, r.reader, int64(size)); err != nil {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		return nil, &ErrRecordTooLarge{Size: size, Max: r.MaxSize}
+	}
+
+	record := make([]byte, size)
+	if _, err := io.ReadFull(r.reader, record); err == io.EOF {
+		return nil, io.ErrUnexpectedEOF
+	} else if err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// Reads the next record into a protobuf message.
+func (r *Reader) ReadMessage(m proto.Message) error {
+	record, err := r.ReadRecord()
+	if err != nil {
+		return err
+	}
+
+	return proto.Unmarshal(record, m)
+}
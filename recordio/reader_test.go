@@ -0,0 +1,202 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recordio
+
+import (
+	"bytes"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_scheduler"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"testing/quick"
+)
+
+// Frames records into a single stream.
+func frame(records ...[]byte) []byte {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	for _, record := range records {
+		w.WriteRecord(record)
+	}
+
+	return buf.Bytes()
+}
+
+// Ensures records split across reads of any size are reassembled.
+func TestReader_ReadRecord(t *testing.T) {
+	t.Parallel()
+
+	stream := frame([]byte("hello"), []byte{}, []byte("world\nwith a newline"))
+	for _, r := range []io.Reader{
+		bytes.NewReader(stream),
+		iotest.OneByteReader(bytes.NewReader(stream)),
+		iotest.HalfReader(bytes.NewReader(stream)),
+		iotest.DataErrReader(bytes.NewReader(stream)),
+	} {
+		reader := NewReader(r)
+		for _, expected := range []string{"hello", "", "world\nwith a newline"} {
+			record, err := reader.ReadRecord()
+			if err != nil {
+				t.Fatal("Record could not be read: " + err.Error())
+			}
+			if string(record) != expected {
+				t.Fatal("Expected " + expected + " but got " + string(record))
+			}
+		}
+
+		if _, err := reader.ReadRecord(); err != io.EOF {
+			t.Fatal("Expected EOF at the end of the stream")
+		}
+	}
+
+	for _, truncated := range []string{"5", "5\nhel"} {
+		if _, err := NewReader(strings.NewReader(truncated)).ReadRecord(); err != io.ErrUnexpectedEOF {
+			t.Fatal("A stream ending mid record should be an unexpected EOF")
+		}
+	}
+}
+
+// Measures performance of reading records.
+func BenchmarkReader_ReadRecord(b *testing.B) {
+	stream := frame(bytes.Repeat([]byte("x"), 1024))
+	for n := 0; n < b.N; n++ {
+		NewReader(bytes.NewReader(stream)).ReadRecord()
+	}
+}
+
+// Ensures oversized records are skipped without losing the records after them.
+func TestReader_MaxSize(t *testing.T) {
+	t.Parallel()
+
+	reader := NewReader(bytes.NewReader(frame([]byte("too large"), []byte("ok"))))
+	reader.MaxSize = 4
+
+	_, err := reader.ReadRecord()
+	if tooLarge, ok := err.(*ErrRecordTooLarge); !ok || tooLarge.Size != 9 {
+		t.Fatal("Expected the first record to be too large")
+	}
+
+	record, err := reader.ReadRecord()
+	if err != nil || string(record) != "ok" {
+		t.Fatal("The record after an oversized one should still be read")
+	}
+
+	reader = NewReader(strings.NewReader("9\nshort"))
+	reader.MaxSize = 4
+	if _, err := reader.ReadRecord(); err != io.ErrUnexpectedEOF {
+		t.Fatal("A truncated oversized record should be an unexpected EOF")
+	}
+}
+
+// Ensures the reader resumes at the next line after a corrupt header.
+func TestReader_Corrupt(t *testing.T) {
+	t.Parallel()
+
+	for _, header := range []string{"abc", "-1", "+2", "", strings.Repeat("9", MAX_HEADER_LENGTH+10)} {
+		stream := append([]byte(header+"\n"), frame([]byte("ok"))...)
+		reader := NewReader(bytes.NewReader(stream))
+
+		if _, err := reader.ReadRecord(); err == nil {
+			t.Fatal("Header " + header + " should be corrupt")
+		} else if _, ok := err.(*ErrCorruptFrame); !ok {
+			t.Fatal("Expected a corrupt frame but got " + err.Error())
+		}
+
+		record, err := reader.ReadRecord()
+		if err != nil || string(record) != "ok" {
+			t.Fatal("The record after corrupt header " + header + " should still be read")
+		}
+	}
+}
+
+// Ensures any sequence of records survives a round trip through the writer and reader.
+func TestReader_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	roundTrip := func(records [][]byte, chunk uint8) bool {
+		r := io.Reader(bytes.NewReader(frame(records...)))
+		if chunk%2 == 0 {
+			r = iotest.OneByteReader(r)
+		}
+
+		reader := NewReader(r)
+		for _, expected := range records {
+			record, err := reader.ReadRecord()
+			if err != nil || !bytes.Equal(record, expected) {
+				return false
+			}
+		}
+
+		_, err := reader.ReadRecord()
+		return err == io.EOF
+	}
+
+	if err := quick.Check(roundTrip, &quick.Config{MaxCount: 500}); err != nil {
+		t.Fatal(err.Error())
+	}
+}
+
+// Ensures random input never panics or stalls the reader.
+func TestReader_Fuzz(t *testing.T) {
+	t.Parallel()
+
+	random := rand.New(rand.NewSource(1))
+	alphabet := []byte("0123456789\n\nx-")
+	for i := 0; i < 2000; i++ {
+		data := make([]byte, random.Intn(256))
+		for j := range data {
+			if random.Intn(4) == 0 {
+				data[j] = byte(random.Intn(256))
+			} else {
+				data[j] = alphabet[random.Intn(len(alphabet))]
+			}
+		}
+
+		reader := NewReader(bytes.NewReader(data))
+		reader.MaxSize = 64
+
+		// Every read consumes at least one byte, so the stream must end within len(data) reads.
+		for reads := 0; ; reads++ {
+			if reads > len(data)+1 {
+				t.Fatalf("Reader did not reach the end of %q", data)
+			}
+
+			_, err := reader.ReadRecord()
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+		}
+	}
+}
+
+// Ensures protobuf messages can be written and read back.
+func TestReader_ReadMessage(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	if err := NewWriter(&buf).WriteMessage(&mesos_v1_scheduler.Event{Type: mesos_v1_scheduler.Event_HEARTBEAT.Enum()}); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	var event mesos_v1_scheduler.Event
+	if err := NewReader(&buf).ReadMessage(&event); err != nil {
+		t.Fatal("Message could not be read: " + err.Error())
+	}
+	if event.GetType() != mesos_v1_scheduler.Event_HEARTBEAT {
+		t.Fatal("Expected a heartbeat but got " + event.GetType().String())
+	}
+}
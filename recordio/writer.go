@@ -0,0 +1,53 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recordio
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// Writes RecordIO framed records.
+type Writer struct {
+	writer io.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{writer: w}
+}
+
+// Writes a record with its length header.
+// The header and record go out in a single write so concurrent streams never see half a frame.
+func (w *Writer) WriteRecord(record []byte) error {
+	frame := make([]byte, 0, MAX_HEADER_LENGTH+1+len(record))
+	frame = strconv.AppendInt(frame, int64(len(record)), 10)
+	frame = append(frame, '\n')
+	frame = append(frame, record...)
+
+	_, err := w.writer.Write(frame)
+	return err
+}
+
+// Writes a protobuf message as a record.
+func (w *Writer) WriteMessage(m proto.Message) error {
+	data, err := proto.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	return w.WriteRecord(data)
+}
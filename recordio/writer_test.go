@@ -0,0 +1,49 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recordio
+
+import (
+	"bytes"
+	"testing"
+)
+
+// Ensures records are framed with their length.
+func TestWriter_WriteRecord(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	if err := w.WriteRecord([]byte("hello")); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := w.WriteRecord(nil); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if buf.String() != "5\nhello0\n" {
+		t.Fatalf("Unexpected framing %q", buf.String())
+	}
+}
+
+// Measures performance of writing records.
+func BenchmarkWriter_WriteRecord(b *testing.B) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	record := bytes.Repeat([]byte("x"), 1024)
+	for n := 0; n < b.N; n++ {
+		buf.Reset()
+		w.WriteRecord(record)
+	}
+}
@@ -0,0 +1,365 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package constraint
+
+/*
+Constraints are small boolean expressions that decide whether an offer can be used for a task, such as:
+
+	attr:rack in (r1, r2) && attr:disk_type == "ssd" && !(hostname ~= "canary-.*")
+
+Fields are "hostname", "agent" (the agent ID) or "attr:<name>" for agent attributes.
+Comparisons are "==", "!=", "~=" (anchored regular expression), "in (...)", "not in (...)"
+and the numeric "<", "<=", ">" and ">=".
+Comparisons can be combined with "&&", "||", "!" and parentheses, where "&&" binds tighter than "||".
+String comparisons are case insensitive.
+Set attributes match if any of their items match, and ranges attributes are equal to any number they contain.
+*/
+import (
+	"errors"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	HOSTNAME    = "hostname"
+	AGENT       = "agent"
+	ATTR_PREFIX = "attr:"
+)
+
+// A compiled constraint that can be evaluated against offers.
+type Expression interface {
+	Evaluate(offer *mesos_v1.Offer) bool
+}
+
+type (
+	and struct{ left, right Expression }
+	or  struct{ left, right Expression }
+	not struct{ expr Expression }
+
+	comparison struct {
+		field  string
+		op     string
+		values []string
+		regex  *regexp.Regexp
+		number float64
+	}
+)
+
+func (a and) Evaluate(offer *mesos_v1.Offer) bool {
+	return a.left.Evaluate(offer) && a.right.Evaluate(offer)
+}
+
+func (o or) Evaluate(offer *mesos_v1.Offer) bool {
+	return o.left.Evaluate(offer) || o.right.Evaluate(offer)
+}
+
+func (n not) Evaluate(offer *mesos_v1.Offer) bool {
+	return !n.expr.Evaluate(offer)
+}
+
+func (c comparison) Evaluate(offer *mesos_v1.Offer) bool {
+	switch c.op {
+	case "!=", "not in":
+		return !c.matches(offer)
+	default:
+		return c.matches(offer)
+	}
+}
+
+// Check if any of the field's values satisfy the comparison.
+func (c comparison) matches(offer *mesos_v1.Offer) bool {
+	switch c.field {
+	case HOSTNAME:
+		return c.matchText(offer.GetHostname())
+	case AGENT:
+		return c.matchText(offer.GetAgentId().GetValue())
+	}
+
+	name := strings.TrimPrefix(c.field, ATTR_PREFIX)
+	for _, attr := range offer.GetAttributes() {
+		if !strings.EqualFold(attr.GetName(), name) {
+			continue
+		}
+
+		switch attr.GetType() {
+		case mesos_v1.Value_TEXT:
+			if c.matchText(attr.GetText().GetValue()) {
+				return true
+			}
+		case mesos_v1.Value_SCALAR:
+			if c.matchNumber(attr.GetScalar().GetValue()) {
+				return true
+			}
+		case mesos_v1.Value_SET:
+			for _, item := range attr.GetSet().GetItem() {
+				if c.matchText(item) {
+					return true
+				}
+			}
+		case mesos_v1.Value_RANGES:
+			if c.matchRanges(attr.GetRanges().GetRange()) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (c comparison) matchText(value string) bool {
+	switch c.op {
+	case "~=":
+		return c.regex.MatchString(value)
+	case "<", "<=", ">", ">=":
+		n, err := strconv.ParseFloat(value, 64)
+		return err == nil && c.matchNumber(n)
+	}
+
+	for _, v := range c.values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c comparison) matchNumber(value float64) bool {
+	switch c.op {
+	case "<":
+		return value < c.number
+	case "<=":
+		return value <= c.number
+	case ">":
+		return value > c.number
+	case ">=":
+		return value >= c.number
+	}
+
+	return c.matchText(strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+// Ranges only support equality, which checks if the value falls within any of the ranges.
+func (c comparison) matchRanges(ranges []*mesos_v1.Value_Range) bool {
+	if c.op == "~=" {
+		return false
+	}
+
+	for _, v := range c.values {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			continue
+		}
+		for _, r := range ranges {
+			if n >= r.GetBegin() && n <= r.GetEnd() {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// Parses a constraint expression into an evaluatable tree.
+func Compile(expr string) (Expression, error) {
+	tokens, err := lex(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.typ != tokenEOF {
+		return nil, errors.New("Unexpected " + t.value + " in constraint at position " + strconv.Itoa(t.pos))
+	}
+
+	return e, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.typ != tokenEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *parser) expect(typ tokenType, what string) (token, error) {
+	t := p.next()
+	if t.typ != typ {
+		return t, errors.New("Expected " + what + " in constraint at position " + strconv.Itoa(t.pos))
+	}
+
+	return t, nil
+}
+
+func (p *parser) parseOr() (Expression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().typ == tokenOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = or{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expression, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().typ == tokenAnd {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = and{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseUnary() (Expression, error) {
+	switch p.peek().typ {
+	case tokenNot:
+		p.next()
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return not{e}, nil
+	case tokenLParen:
+		p.next()
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenRParen, ")"); err != nil {
+			return nil, err
+		}
+		return e, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (Expression, error) {
+	field, err := p.expect(tokenWord, "field")
+	if err != nil {
+		return nil, err
+	}
+
+	c := comparison{field: strings.ToLower(field.value)}
+	if c.field != HOSTNAME && c.field != AGENT &&
+		(!strings.HasPrefix(c.field, ATTR_PREFIX) || len(c.field) == len(ATTR_PREFIX)) {
+		return nil, errors.New("Unknown constraint field " + field.value)
+	}
+
+	op := p.next()
+	switch {
+	case op.typ == tokenOp:
+		c.op = op.value
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		c.values = []string{value}
+	case op.typ == tokenWord && strings.EqualFold(op.value, "in"):
+		c.op = "in"
+	case op.typ == tokenWord && strings.EqualFold(op.value, "not"):
+		if t := p.next(); t.typ != tokenWord || !strings.EqualFold(t.value, "in") {
+			return nil, errors.New("Expected in after not in constraint at position " + strconv.Itoa(t.pos))
+		}
+		c.op = "not in"
+	default:
+		return nil, errors.New("Expected comparison after " + field.value + " in constraint")
+	}
+
+	if c.op == "in" || c.op == "not in" {
+		if c.values, err = p.parseList(); err != nil {
+			return nil, err
+		}
+	}
+
+	switch c.op {
+	case "~=":
+		if c.regex, err = regexp.Compile("^(?:" + c.values[0] + ")$"); err != nil {
+			return nil, err
+		}
+	case "<", "<=", ">", ">=":
+		if c.number, err = strconv.ParseFloat(c.values[0], 64); err != nil {
+			return nil, errors.New("Numeric comparison needs a number, got " + c.values[0])
+		}
+	}
+
+	return c, nil
+}
+
+func (p *parser) parseValue() (string, error) {
+	t := p.next()
+	if t.typ != tokenWord && t.typ != tokenString {
+		return "", errors.New("Expected value in constraint at position " + strconv.Itoa(t.pos))
+	}
+
+	return t.value, nil
+}
+
+func (p *parser) parseList() ([]string, error) {
+	if _, err := p.expect(tokenLParen, "("); err != nil {
+		return nil, err
+	}
+
+	var values []string
+	for {
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+
+		t := p.next()
+		if t.typ == tokenRParen {
+			return values, nil
+		}
+		if t.typ != tokenComma {
+			return nil, errors.New("Expected , or ) in constraint at position " + strconv.Itoa(t.pos))
+		}
+	}
+}
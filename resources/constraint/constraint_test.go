@@ -0,0 +1,119 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package constraint
+
+import (
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"testing"
+)
+
+var offer = &mesos_v1.Offer{
+	Hostname: utils.ProtoString("db-01.example.com"),
+	AgentId:  &mesos_v1.AgentID{Value: utils.ProtoString("agent-1")},
+	Attributes: []*mesos_v1.Attribute{
+		{
+			Name: utils.ProtoString("rack"),
+			Type: mesos_v1.Value_TEXT.Enum(),
+			Text: &mesos_v1.Value_Text{Value: utils.ProtoString("r2")},
+		},
+		{
+			Name: utils.ProtoString("disk_type"),
+			Type: mesos_v1.Value_SET.Enum(),
+			Set:  &mesos_v1.Value_Set{Item: []string{"hdd", "ssd"}},
+		},
+		{
+			Name:   utils.ProtoString("cores"),
+			Type:   mesos_v1.Value_SCALAR.Enum(),
+			Scalar: &mesos_v1.Value_Scalar{Value: utils.ProtoFloat64(16)},
+		},
+		{
+			Name: utils.ProtoString("slots"),
+			Type: mesos_v1.Value_RANGES.Enum(),
+			Ranges: &mesos_v1.Value_Ranges{Range: []*mesos_v1.Value_Range{
+				{Begin: utils.ProtoUint64(1), End: utils.ProtoUint64(4)},
+			}},
+		},
+	},
+}
+
+// Ensures expressions are compiled and evaluated against offers correctly.
+func TestCompile(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]bool{
+		`attr:rack in (r1,r2) && attr:disk_type == "ssd"`:        true,
+		`attr:rack in (r1, r3)`:                                  false,
+		`attr:rack not in (r1, r3)`:                              true,
+		`attr:rack != R2`:                                        false,
+		`hostname ~= "db-.*" && agent == agent-1`:                true,
+		`!(hostname ~= "db-.*")`:                                 false,
+		`attr:cores >= 16 && attr:cores < 32`:                    true,
+		`attr:cores == 16`:                                       true,
+		`attr:slots == 3`:                                        true,
+		`attr:slots == 5`:                                        false,
+		`attr:missing == x || attr:rack == r2`:                   true,
+		`attr:rack == r2 || attr:rack == r1 && attr:cores > 100`: true,
+	}
+
+	for expr, want := range tests {
+		e, err := Compile(expr)
+		if err != nil {
+			if want {
+				t.Fatal(expr + ": " + err.Error())
+			}
+			continue
+		}
+		if e.Evaluate(offer) != want {
+			t.Fatal("Wrong result for " + expr)
+		}
+	}
+
+	invalid := []string{
+		``,
+		`attr:rack`,
+		`attr:rack in r1`,
+		`attr:rack in (r1`,
+		`rack == r1`,
+		`attr:rack == "r1`,
+		`attr:cores > many`,
+		`hostname ~= "("`,
+		`attr:rack == r1 &&`,
+		`(attr:rack == r1`,
+		`attr:rack == r1)`,
+		`attr:rack not r1`,
+		`attr:rack == r1 # r2`,
+	}
+	for _, expr := range invalid {
+		if _, err := Compile(expr); err == nil {
+			t.Fatal("Expected an error compiling " + expr)
+		}
+	}
+}
+
+// Measures performance of compiling an expression.
+func BenchmarkCompile(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		Compile(`attr:rack in (r1,r2) && attr:disk_type == "ssd" || hostname ~= "db-.*"`)
+	}
+}
+
+// Measures performance of evaluating a compiled expression.
+func BenchmarkExpression_Evaluate(b *testing.B) {
+	e, _ := Compile(`attr:rack in (r1,r2) && attr:disk_type == "ssd" || hostname ~= "db-.*"`)
+	for n := 0; n < b.N; n++ {
+		e.Evaluate(offer)
+	}
+}
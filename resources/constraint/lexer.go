@@ -0,0 +1,104 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package constraint
+
+import (
+	"errors"
+	"strings"
+)
+
+type tokenType uint8
+
+const (
+	tokenEOF tokenType = iota
+	tokenWord
+	tokenString
+	tokenOp
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type token struct {
+	typ   tokenType
+	value string
+	pos   int
+}
+
+// Characters allowed in bare words such as field names and unquoted values.
+func isWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' ||
+		c >= 'A' && c <= 'Z' ||
+		c >= '0' && c <= '9' ||
+		strings.IndexByte("_-.:/*", c) >= 0
+}
+
+// Splits an expression up into tokens.
+func lex(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{tokenLParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{tokenRParen, ")", i})
+			i++
+		case c == ',':
+			tokens = append(tokens, token{tokenComma, ",", i})
+			i++
+		case strings.HasPrefix(expr[i:], "&&"):
+			tokens = append(tokens, token{tokenAnd, "&&", i})
+			i += 2
+		case strings.HasPrefix(expr[i:], "||"):
+			tokens = append(tokens, token{tokenOr, "||", i})
+			i += 2
+		case strings.HasPrefix(expr[i:], "=="), strings.HasPrefix(expr[i:], "!="),
+			strings.HasPrefix(expr[i:], "~="), strings.HasPrefix(expr[i:], "<="),
+			strings.HasPrefix(expr[i:], ">="):
+			tokens = append(tokens, token{tokenOp, expr[i : i+2], i})
+			i += 2
+		case c == '<' || c == '>':
+			tokens = append(tokens, token{tokenOp, string(c), i})
+			i++
+		case c == '!':
+			tokens = append(tokens, token{tokenNot, "!", i})
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(expr[i+1:], c)
+			if end < 0 {
+				return nil, errors.New("Unterminated string in constraint: " + expr[i:])
+			}
+			tokens = append(tokens, token{tokenString, expr[i+1 : i+1+end], i})
+			i += end + 2
+		case isWordChar(c):
+			start := i
+			for i < len(expr) && isWordChar(expr[i]) {
+				i++
+			}
+			tokens = append(tokens, token{tokenWord, expr[start:i], start})
+		default:
+			return nil, errors.New("Unexpected character in constraint: " + string(c))
+		}
+	}
+
+	return append(tokens, token{tokenEOF, "", len(expr)}), nil
+}
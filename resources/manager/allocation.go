@@ -0,0 +1,51 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/task/manager"
+)
+
+// Describes exactly what was consumed from an offer for a task.
+type Allocation struct {
+	Offer     *mesos_v1.Offer
+	AgentID   *mesos_v1.AgentID
+	Resources []*mesos_v1.Resource // Consumed resources with their roles, reservations and ports, ready for a TaskInfo.
+}
+
+// Builds the allocation for a task that was just placed on an offer.
+// Resources are copied so later changes to the task don't alter the allocation.
+func newAllocation(task *manager.Task, offer *mesos_v1.Offer) *Allocation {
+	res := make([]*mesos_v1.Resource, 0, len(task.Info.Resources))
+	for _, resource := range task.Info.Resources {
+		res = append(res, proto.Clone(resource).(*mesos_v1.Resource))
+	}
+
+	return &Allocation{
+		Offer:     offer,
+		AgentID:   offer.GetAgentId(),
+		Resources: res,
+	}
+}
+
+// Assigns an offer to a task like Assign, but also returns the resources that were consumed.
+func (d *DefaultResourceManager) Allocate(task *manager.Task) (*Allocation, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return d.assign(task, len(task.Filters) == 0)
+}
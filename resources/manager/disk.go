@@ -0,0 +1,133 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+)
+
+// A disk resource from an offer along with how much of it is left.
+type OfferDisk struct {
+	Resource  *mesos_v1.Resource // The offered disk resource.
+	Available float64
+	Claimed   bool // MOUNT disks and persistent volumes can only be used by a single task.
+}
+
+func copyDisks(disks []*OfferDisk) []*OfferDisk {
+	c := make([]*OfferDisk, 0, len(disks))
+	for _, disk := range disks {
+		diskCopy := *disk
+		c = append(c, &diskCopy)
+	}
+
+	return c
+}
+
+func containsRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Gets the root the disk source is mapped to, if any.
+func diskRoot(source *mesos_v1.Resource_DiskInfo_Source) string {
+	switch source.GetType() {
+	case mesos_v1.Resource_DiskInfo_Source_PATH:
+		return source.GetPath().GetRoot()
+	case mesos_v1.Resource_DiskInfo_Source_MOUNT:
+		return source.GetMount().GetRoot()
+	}
+
+	return ""
+}
+
+// allocateDiskResource returns the disk the resource was allocated from and tells us if we have enough disk resources on this offer.
+// Persistent volumes must match by ID, otherwise the disk source type must match.
+// Root and PATH disks can be shared between tasks, while MOUNT disks are claimed whole by a single task.
+// If the task names a root for its disk source, only disks mapped to that root are used.
+func (d *DefaultResourceManager) allocateDiskResource(resource *mesos_v1.Resource, roles []string, offer *MesosOfferResources) (*OfferDisk, bool) {
+	want := resource.GetDisk()
+	size := resource.GetScalar().GetValue()
+
+	for _, disk := range offer.Disks {
+		have := disk.Resource
+		if disk.Claimed || !containsRole(roles, have.GetRole()) || !d.ownsReservation(have.GetReservation()) {
+			continue
+		}
+
+		if id := want.GetPersistence().GetId(); id != "" {
+			if have.GetDisk().GetPersistence().GetId() == id {
+				disk.Claimed = true
+				return disk, true
+			}
+			continue
+		}
+
+		// Persistent volumes are only handed to tasks that ask for them.
+		if have.GetDisk().GetPersistence() != nil {
+			continue
+		}
+
+		source := want.GetSource()
+		if source.GetType() != have.GetDisk().GetSource().GetType() {
+			continue
+		}
+		if root := diskRoot(source); root != "" && root != diskRoot(have.GetDisk().GetSource()) {
+			continue
+		}
+		if disk.Available < size {
+			continue
+		}
+
+		if source.GetType() == mesos_v1.Resource_DiskInfo_Source_MOUNT {
+			disk.Claimed = true
+			disk.Available = 0
+		} else {
+			disk.Available -= size
+		}
+
+		return disk, true
+	}
+
+	return nil, false
+}
+
+// Marks a task's disk resource with the role, reservation and source of the disk it was allocated from.
+// MOUNT disks can't be split, so the task is given the whole disk.
+func (d *DefaultResourceManager) setAllocatedDisk(resource *mesos_v1.Resource, disk *OfferDisk) {
+	have := disk.Resource
+	resource.Role = utils.ProtoString(have.GetRole())
+	resource.Reservation = have.GetReservation()
+
+	if have.GetDisk().GetPersistence() != nil {
+		return
+	}
+
+	source := have.GetDisk().GetSource()
+	if source != nil {
+		if resource.Disk == nil {
+			resource.Disk = &mesos_v1.Resource_DiskInfo{}
+		}
+		resource.Disk.Source = source
+	}
+	if source.GetType() == mesos_v1.Resource_DiskInfo_Source_MOUNT {
+		resource.Scalar = &mesos_v1.Value_Scalar{Value: utils.ProtoFloat64(have.GetScalar().GetValue())}
+	}
+}
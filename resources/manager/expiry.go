@@ -0,0 +1,65 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"time"
+)
+
+// Evicts all offers that have been held for longer than maxAge and returns them.
+// Mesos rescinds offers that are held past its offer timeout, so expired offers should be declined by the caller.
+func (d *DefaultResourceManager) ExpireOffers(maxAge time.Duration) []*mesos_v1.Offer {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	var expired []*mesos_v1.Offer
+	cutoff := time.Now().Add(-maxAge)
+	for i := 0; i < len(d.offers); {
+		if d.offers[i].Received.Before(cutoff) {
+			expired = append(expired, d.offers[i].Offer)
+			d.popOffer(i)
+			continue
+		}
+		i++
+	}
+
+	return expired
+}
+
+// Expires offers older than maxAge every interval and hands their IDs to decline, until stop is closed.
+// This blocks and is meant to be run in its own goroutine.
+func (d *DefaultResourceManager) ExpireOffersEvery(interval, maxAge time.Duration, decline func(offerIds []*mesos_v1.OfferID), stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			expired := d.ExpireOffers(maxAge)
+			if len(expired) == 0 {
+				continue
+			}
+
+			ids := make([]*mesos_v1.OfferID, 0, len(expired))
+			for _, offer := range expired {
+				ids = append(ids, offer.GetId())
+			}
+			decline(ids)
+		case <-stop:
+			return
+		}
+	}
+}
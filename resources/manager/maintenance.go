@@ -0,0 +1,88 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"time"
+)
+
+// Sets how long before an agent's maintenance window we stop placing tasks on it.
+// With no horizon, agents only stop getting tasks once their maintenance has started.
+func (d *DefaultResourceManager) SetDrainHorizon(horizon time.Duration) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.drainHorizon = horizon
+	d.markDraining()
+}
+
+// Records an agent's scheduled maintenance, typically from an inverse offer.
+// Offers from the agent are marked as draining once its maintenance is within the drain horizon.
+func (d *DefaultResourceManager) Drain(agentId *mesos_v1.AgentID, unavailability *mesos_v1.Unavailability) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.maintenance[agentId.GetValue()] = unavailability
+	d.markDraining()
+}
+
+// Forgets an agent's scheduled maintenance, such as when its inverse offer is rescinded.
+func (d *DefaultResourceManager) Undrain(agentId *mesos_v1.AgentID) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	delete(d.maintenance, agentId.GetValue())
+	d.markDraining()
+}
+
+// Reports whether an agent's maintenance is within the drain horizon.
+func (d *DefaultResourceManager) Draining(agentId *mesos_v1.AgentID) bool {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	return d.draining(agentId, nil, time.Now())
+}
+
+// Re-evaluates which held offers are draining.
+func (d *DefaultResourceManager) markDraining() {
+	now := time.Now()
+	for _, offer := range d.offers {
+		offer.Draining = d.draining(offer.Offer.GetAgentId(), offer.Offer.GetUnavailability(), now)
+	}
+}
+
+// Checks both the unavailability an offer came with and anything learned from inverse offers.
+func (d *DefaultResourceManager) draining(agentId *mesos_v1.AgentID, offered *mesos_v1.Unavailability, now time.Time) bool {
+	return UnavailableWithin(offered, now, d.drainHorizon) ||
+		UnavailableWithin(d.maintenance[agentId.GetValue()], now, d.drainHorizon)
+}
+
+// Reports whether an unavailability starts within the horizon of now and hasn't ended yet.
+func UnavailableWithin(u *mesos_v1.Unavailability, now time.Time, horizon time.Duration) bool {
+	if u == nil || u.GetStart() == nil {
+		return false
+	}
+
+	start := time.Unix(0, u.GetStart().GetNanoseconds())
+	if start.After(now.Add(horizon)) {
+		return false
+	}
+	if u.GetDuration() != nil && start.Add(time.Duration(u.GetDuration().GetNanoseconds())).Before(now) {
+		return false
+	}
+
+	return true
+}
@@ -17,10 +17,16 @@ package manager
 import (
 	"errors"
 	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/resources/constraint"
 	"github.com/verizonlabs/mesos-framework-sdk/task"
 	"github.com/verizonlabs/mesos-framework-sdk/task/manager"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 /*
@@ -30,23 +36,68 @@ The resource manager will handle offers and allocate it to a task.
 type (
 	ResourceManager interface {
 		AddOffers(offers []*mesos_v1.Offer)
+		AddOffersIncremental(offers []*mesos_v1.Offer)
+		RemoveOffer(id *mesos_v1.OfferID)
 		HasResources() bool
 		Assign(task *manager.Task) (*mesos_v1.Offer, error)
+		Allocate(task *manager.Task) (*Allocation, error)
+		AssignAll(tasks []*manager.Task) (map[*manager.Task]*mesos_v1.Offer, []error)
+		Plan(tasks []*manager.Task) ([]Placement, error)
 		Offers() []*mesos_v1.Offer
+		Snapshot() *Snapshot
+		Restore(s *Snapshot)
+		Drain(agentId *mesos_v1.AgentID, unavailability *mesos_v1.Unavailability)
+		Undrain(agentId *mesos_v1.AgentID)
+		Draining(agentId *mesos_v1.AgentID) bool
 	}
 
 	// A resource manager implementation.
+	// It is safe for concurrent use: every exported method holds an internal lock for its whole duration,
+	// so offers can be added from the event loop while tasks are assigned from other goroutines.
+	// Offers and reservations handed out are shared and should not be modified by callers.
 	DefaultResourceManager struct {
-		offers []*MesosOfferResources
+		lock         sync.RWMutex
+		offers       []*MesosOfferResources
+		role         string
+		principal    string
+		strategy     OfferSelectionStrategy
+		placements   map[string]map[string]struct{}           // Agent ID to the names of the tasks placed on it.
+		reservations map[string]*Reservation                  // Task name to the resources dynamically reserved for it.
+		volumes      map[string]map[string]*mesos_v1.Resource // Agent ID to its persistent volumes, keyed by volume ID.
+		constraints  map[string]constraint.Expression         // Compiled constraint expressions.
+		quotas       map[string]*Quota                        // Task group name to its resource quota.
+		maintenance  map[string]*mesos_v1.Unavailability      // Agent ID to its scheduled maintenance, from inverse offers.
+		drainHorizon time.Duration                            // How far ahead of maintenance agents stop getting tasks.
 	}
 
 	// Holds offer data
 	MesosOfferResources struct {
-		Offer    *mesos_v1.Offer
-		Cpu      float64
-		Mem      float64
-		Disk     *mesos_v1.Resource_DiskInfo
-		Accepted bool
+		Offer         *mesos_v1.Offer
+		Cpu           float64                     // Total cpus across all roles, including revocable cpus.
+		Mem           float64                     // Total memory across all roles, including revocable memory.
+		Gpu           float64                     // Total gpus across all roles.
+		Custom        map[string]float64          // Totals of any other named scalar resources across all roles.
+		Disk          *mesos_v1.Resource_DiskInfo // The last disk seen in the offer.
+		Disks         []*OfferDisk                // Every disk in the offer.
+		Ports         []*mesos_v1.Value_Range     // Port ranges still available on this offer.
+		ConsumedPorts []*mesos_v1.Value_Range     // Port ranges handed out to tasks from this offer.
+		Roles         map[string]*RoleResources   // Non-revocable resources per role.
+		Revocable     map[string]*RoleResources   // Revocable resources per role, kept apart from the regular pool.
+		Executors     map[string]struct{}         // Executors whose resources are already accounted for on this agent.
+		Accepted      bool
+		Received      time.Time // When the offer was handed to us by Mesos.
+		Draining      bool      // The agent's maintenance starts within the drain horizon, so no tasks are placed on it.
+	}
+
+	// Holds the scalar resources of an offer that belong to a single role.
+	// Unreserved resources are held under the "*" role.
+	RoleResources struct {
+		Role        string
+		Reservation *mesos_v1.Resource_ReservationInfo
+		Cpu         float64
+		Mem         float64
+		Gpu         float64
+		Custom      map[string]float64 // Any other named scalar resources.
 	}
 )
 
@@ -55,36 +106,240 @@ const (
 	TEXT   = mesos_v1.Value_TEXT
 	RANGES = mesos_v1.Value_RANGES
 	SET    = mesos_v1.Value_SET
+
+	UNRESERVED = mesos_v1.Default_Resource_Role
+
+	// Filter types that constrain a task based on where other tasks are placed.
+	LIKE_TASK   = "LIKE_TASK"   // Only place on agents already running one of the named tasks.
+	UNLIKE_TASK = "UNLIKE_TASK" // Never place on agents running any of the named tasks.
+
+	// Filter types that pin a task to specific agents.
+	HOSTNAME = "HOSTNAME" // Only place on agents whose hostname matches one of the values.
+	AGENT_ID = "AGENT_ID" // Only place on agents whose ID matches one of the values.
+
+	// Filter type whose values are constraint expressions that must all hold, see the constraint package.
+	CONSTRAINT = "CONSTRAINT"
 )
 
 // Creates a default resource manager implementation.
 func NewDefaultResourceManager() *DefaultResourceManager {
 	return &DefaultResourceManager{
-		offers: make([]*MesosOfferResources, 0),
+		offers:       make([]*MesosOfferResources, 0),
+		strategy:     FirstFit{},
+		placements:   make(map[string]map[string]struct{}),
+		reservations: make(map[string]*Reservation),
+		volumes:      make(map[string]map[string]*mesos_v1.Resource),
+		constraints:  make(map[string]constraint.Expression),
+		quotas:       make(map[string]*Quota),
+		maintenance:  make(map[string]*mesos_v1.Unavailability),
 	}
 }
 
+// Sets the strategy used to choose between offers that can satisfy a task.
+func (d *DefaultResourceManager) SetStrategy(strategy OfferSelectionStrategy) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.strategy = strategy
+}
+
+// Sets the role the framework is registered with.
+// Resources reserved for this role are preferred over unreserved resources when assigning tasks.
+func (d *DefaultResourceManager) SetRole(role string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.role = role
+}
+
+// Sets the principal the framework authenticates with.
+// Once set, only resources that are unreserved or reserved by this principal are used.
+func (d *DefaultResourceManager) SetPrincipal(principal string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.principal = principal
+}
+
+// Check if resources with the given reservation can be used by the framework.
+func (d *DefaultResourceManager) ownsReservation(reservation *mesos_v1.Resource_ReservationInfo) bool {
+	return d.principal == "" || reservation == nil || reservation.GetPrincipal() == d.principal
+}
+
+// Gets the pool of resources per role, either for revocable or regular resources.
+func (m *MesosOfferResources) pool(revocable bool) map[string]*RoleResources {
+	if revocable {
+		return m.Revocable
+	}
+
+	return m.Roles
+}
+
+// Gets the resources held for the role of the given resource, creating them if needed.
+// Revocable resources are held in their own pool.
+func (m *MesosOfferResources) roleResources(resource *mesos_v1.Resource) *RoleResources {
+	role := resource.GetRole()
+	pool := m.pool(resource.GetRevocable() != nil)
+	r, ok := pool[role]
+	if !ok {
+		r = &RoleResources{
+			Role:        role,
+			Reservation: resource.GetReservation(),
+		}
+		pool[role] = r
+	}
+
+	return r
+}
+
+// Makes a deep copy of the offer's resources so that deductions can be staged against it.
+// The underlying offer is shared.
+func (m *MesosOfferResources) copy() *MesosOfferResources {
+	c := *m
+	c.Ports = copyRanges(m.Ports)
+	c.ConsumedPorts = copyRanges(m.ConsumedPorts)
+	c.Roles = copyRoles(m.Roles)
+	c.Revocable = copyRoles(m.Revocable)
+	c.Custom = copyScalars(m.Custom)
+	c.Disks = copyDisks(m.Disks)
+	c.Executors = make(map[string]struct{}, len(m.Executors))
+	for id := range m.Executors {
+		c.Executors[id] = struct{}{}
+	}
+
+	return &c
+}
+
+func copyRoles(roles map[string]*RoleResources) map[string]*RoleResources {
+	c := make(map[string]*RoleResources, len(roles))
+	for role, r := range roles {
+		roleCopy := *r
+		roleCopy.Custom = copyScalars(r.Custom)
+		c[role] = &roleCopy
+	}
+
+	return c
+}
+
+func copyScalars(scalars map[string]float64) map[string]float64 {
+	c := make(map[string]float64, len(scalars))
+	for name, value := range scalars {
+		c[name] = value
+	}
+
+	return c
+}
+
 // Add in a new batch of offers
 func (d *DefaultResourceManager) AddOffers(offers []*mesos_v1.Offer) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
 	// No matter what, we clear offers on this call to make sure
 	// we don't have stale offers that are already declined.
 	d.clearOffers()
-	// Organize each offer into a MesosOfferResource struct.
+	received := time.Now()
+	for _, offer := range offers {
+		d.addOffer(offer, received)
+	}
+}
+
+// Adds a batch of offers on top of the ones already held, for frameworks that keep offers across events.
+// Offers that are already held are skipped.
+func (d *DefaultResourceManager) AddOffersIncremental(offers []*mesos_v1.Offer) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	held := make(map[string]struct{}, len(d.offers))
+	for _, o := range d.offers {
+		held[o.Offer.GetId().GetValue()] = struct{}{}
+	}
+
+	received := time.Now()
 	for _, offer := range offers {
-		mesosOffer := &MesosOfferResources{}
-		for _, resource := range offer.Resources {
-			switch resource.GetName() {
-			case "cpus":
-				mesosOffer.Cpu = resource.GetScalar().GetValue()
-			case "mem":
-				mesosOffer.Mem = resource.GetScalar().GetValue()
-			case "disk":
-				mesosOffer.Disk = resource.GetDisk()
+		if _, ok := held[offer.GetId().GetValue()]; ok {
+			continue
+		}
+		held[offer.GetId().GetValue()] = struct{}{}
+		d.addOffer(offer, received)
+	}
+}
+
+// Organizes an offer into a MesosOfferResources struct and holds on to it.
+func (d *DefaultResourceManager) addOffer(offer *mesos_v1.Offer, received time.Time) {
+	mesosOffer := &MesosOfferResources{
+		Roles:     make(map[string]*RoleResources),
+		Revocable: make(map[string]*RoleResources),
+		Custom:    make(map[string]float64),
+		Executors: make(map[string]struct{}),
+	}
+
+	mesosOffer.Draining = d.draining(offer.GetAgentId(), offer.GetUnavailability(), received)
+
+	// Executors already running on the agent have had their resources taken out of the offer.
+	for _, id := range offer.GetExecutorIds() {
+		mesosOffer.Executors[id.GetValue()] = struct{}{}
+	}
+
+	for _, resource := range offer.Resources {
+		// Resources reserved by other principals are never ours to use.
+		if !d.ownsReservation(resource.GetReservation()) {
+			continue
+		}
+
+		switch resource.GetName() {
+		case "cpus":
+			mesosOffer.Cpu += resource.GetScalar().GetValue()
+			mesosOffer.roleResources(resource).Cpu += resource.GetScalar().GetValue()
+		case "mem":
+			mesosOffer.Mem += resource.GetScalar().GetValue()
+			mesosOffer.roleResources(resource).Mem += resource.GetScalar().GetValue()
+		case "gpus":
+			mesosOffer.Gpu += resource.GetScalar().GetValue()
+			mesosOffer.roleResources(resource).Gpu += resource.GetScalar().GetValue()
+		case "disk":
+			mesosOffer.Disk = resource.GetDisk()
+			mesosOffer.Disks = append(mesosOffer.Disks, &OfferDisk{
+				Resource:  resource,
+				Available: resource.GetScalar().GetValue(),
+			})
+
+			// Keep track of any persistent volumes that are offered back to us.
+			if resource.GetDisk().GetPersistence().GetId() != "" {
+				d.trackVolume(offer.GetAgentId().GetValue(), resource)
+			}
+		case "ports":
+			// Copy the ranges so carving out ports never alters the original offer.
+			mesosOffer.Ports = append(mesosOffer.Ports, copyRanges(resource.GetRanges().GetRange())...)
+		default:
+			// Any other scalar resource is tracked by name so tasks can ask for it.
+			if resource.GetType() == SCALAR {
+				mesosOffer.Custom[resource.GetName()] += resource.GetScalar().GetValue()
+				r := mesosOffer.roleResources(resource)
+				if r.Custom == nil {
+					r.Custom = make(map[string]float64)
+				}
+				r.Custom[resource.GetName()] += resource.GetScalar().GetValue()
 			}
 		}
-		mesosOffer.Offer = offer
-		// Append to the slice of offers.
-		d.offers = append(d.offers, mesosOffer)
+	}
+	mesosOffer.Offer = offer
+	mesosOffer.Received = received
+	// Append to the slice of offers.
+	d.offers = append(d.offers, mesosOffer)
+}
+
+// Removes a single offer, typically because Mesos rescinded it.
+// Rescinded offers are never handed out by Assign once removed.
+func (d *DefaultResourceManager) RemoveOffer(id *mesos_v1.OfferID) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	for i, o := range d.offers {
+		if o.Offer.GetId().GetValue() == id.GetValue() {
+			d.popOffer(i)
+			return
+		}
 	}
 }
 
@@ -93,8 +348,21 @@ func (d *DefaultResourceManager) clearOffers() {
 	d.offers = nil
 }
 
+// Removes a specific offer from our list of offers.
+func (d *DefaultResourceManager) removeOffer(offer *MesosOfferResources) {
+	for i, o := range d.offers {
+		if o == offer {
+			d.popOffer(i)
+			return
+		}
+	}
+}
+
 // Do we have any resources left?
 func (d *DefaultResourceManager) HasResources() bool {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
 	return len(d.offers) > 0
 }
 
@@ -136,59 +404,312 @@ func (d *DefaultResourceManager) filterOnAttrScalar(f []string, a *mesos_v1.Attr
 	return false
 }
 
-// filter with attributes, does ANY (i.e. OR's)
-// TODO (tim): Allow end user to set for "best effort" and "strict" requirements for filters?
-func (d *DefaultResourceManager) filter(f []task.Filter, offer *mesos_v1.Offer) bool {
-	for _, filter := range f {
-		for _, attr := range offer.Attributes {
-			switch attr.GetType() {
-			case SCALAR:
-				if d.filterOnAttrScalar(filter.Value, attr) {
-					return true
-				}
-			case TEXT:
-				if d.filterOnAttrText(filter.Value, attr) {
-					return true
-				}
-			case SET:
-			case RANGES:
+// Check if filter applies to a single Set attribute.
+// Matches if any of the terms is a member of the attribute's set.
+func (d *DefaultResourceManager) filterOnAttrSet(f []string, a *mesos_v1.Attribute) bool {
+	for _, term := range f {
+		for _, item := range a.GetSet().GetItem() {
+			// Case insensitive
+			if strings.ToLower(term) == strings.ToLower(item) {
+				return true
 			}
 		}
 	}
+	return false
+}
 
+// Check if filter applies to a single Ranges attribute.
+// Terms can either be a single number ("5") or a range ("5-10").
+// Matches if any of the terms is fully contained within one of the attribute's ranges.
+func (d *DefaultResourceManager) filterOnAttrRanges(f []string, a *mesos_v1.Attribute) bool {
+	for _, term := range f {
+		begin, end, err := parseRangeTerm(term)
+		if err != nil {
+			// We can't parse a proper range, ignore.
+			continue
+		}
+		for _, r := range a.GetRanges().GetRange() {
+			if begin >= r.GetBegin() && end <= r.GetEnd() {
+				return true
+			}
+		}
+	}
 	return false
 }
 
-// allocateMemResources returns a boolean and tells us if we have enough memory resources on this offer.
-func (d *DefaultResourceManager) allocateMemResource(mem float64, offer *MesosOfferResources) bool {
-	if offer.Mem-mem >= 0 {
-		offer.Mem = offer.Mem - mem
-		return true
+// Parses a filter term into the beginning and end of a range.
+// A single number is treated as a range containing only that number.
+func parseRangeTerm(term string) (uint64, uint64, error) {
+	term = strings.Trim(strings.TrimSpace(term), "[]")
+	bounds := strings.SplitN(term, "-", 2)
+
+	begin, err := strconv.ParseUint(strings.TrimSpace(bounds[0]), 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(bounds) == 1 {
+		return begin, begin, nil
+	}
+
+	end, err := strconv.ParseUint(strings.TrimSpace(bounds[1]), 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	if begin > end {
+		return 0, 0, errors.New("Invalid range " + term + ", beginning is greater than the end")
+	}
+
+	return begin, end, nil
+}
+
+// Check if an attribute filter applies to any of the offer's attributes.
+func (d *DefaultResourceManager) filterOnAttributes(filter task.Filter, offer *mesos_v1.Offer) bool {
+	for _, attr := range offer.Attributes {
+		switch attr.GetType() {
+		case SCALAR:
+			if d.filterOnAttrScalar(filter.Value, attr) {
+				return true
+			}
+		case TEXT:
+			if d.filterOnAttrText(filter.Value, attr) {
+				return true
+			}
+		case SET:
+			if d.filterOnAttrSet(filter.Value, attr) {
+				return true
+			}
+		case RANGES:
+			if d.filterOnAttrRanges(filter.Value, attr) {
+				return true
+			}
+		}
 	}
 
 	return false
 }
 
-// allocateCpuResources returns a boolean and tells us if we have enough cpu resources on this offer.
-func (d *DefaultResourceManager) allocateCpuResource(cpu float64, offer *MesosOfferResources) bool {
-	if offer.Cpu-cpu >= 0 {
-		offer.Cpu = offer.Cpu - cpu
-		return true
+// Check if the offer's agent is running any of the named tasks.
+func (d *DefaultResourceManager) agentRunsTask(names []string, offer *mesos_v1.Offer) bool {
+	tasks := d.placements[offer.GetAgentId().GetValue()]
+	for _, name := range names {
+		if _, ok := tasks[name]; ok {
+			return true
+		}
 	}
 
 	return false
 }
 
-// allocateDiskResource returns a boolean and tells us if we have enough disk resources on this offer.
-func (d *DefaultResourceManager) allocateDiskResource(resource *mesos_v1.Resource, offer *MesosOfferResources) bool {
-	if resource.Disk != nil {
-		offer.Disk = resource.Disk
-		return true
+// Check if a value matches any of the filter's values.
+// Regex filters must match the entire value, otherwise values are compared case insensitively.
+func (d *DefaultResourceManager) filterOnValue(filter task.Filter, value string) bool {
+	for _, term := range filter.Value {
+		if filter.Regex {
+			// Invalid expressions never match.
+			if matched, err := regexp.MatchString("^(?:"+term+")$", value); err == nil && matched {
+				return true
+			}
+		} else if strings.ToLower(term) == strings.ToLower(value) {
+			return true
+		}
 	}
 
 	return false
 }
 
+// Check if an offer satisfies all of the given constraint expressions.
+// Expressions are compiled once and cached, invalid expressions never match.
+func (d *DefaultResourceManager) filterOnConstraints(exprs []string, offer *mesos_v1.Offer) bool {
+	for _, expr := range exprs {
+		e, ok := d.constraints[expr]
+		if !ok {
+			var err error
+			if e, err = constraint.Compile(expr); err != nil {
+				return false
+			}
+			d.constraints[expr] = e
+		}
+
+		if !e.Evaluate(offer) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Task constraints (LIKE_TASK/UNLIKE_TASK), agent constraints (HOSTNAME/AGENT_ID)
+// and constraint expressions (CONSTRAINT) must all be satisfied.
+// Attribute filters do ANY (i.e. OR's)
+// TODO (tim): Allow end user to set for "best effort" and "strict" requirements for filters?
+func (d *DefaultResourceManager) filter(f []task.Filter, offer *mesos_v1.Offer) bool {
+	attrFilters, attrMatch := 0, false
+	for _, filter := range f {
+		switch strings.ToUpper(filter.Type) {
+		case LIKE_TASK:
+			if !d.agentRunsTask(filter.Value, offer) {
+				return false
+			}
+		case UNLIKE_TASK:
+			if d.agentRunsTask(filter.Value, offer) {
+				return false
+			}
+		case HOSTNAME:
+			if !d.filterOnValue(filter, offer.GetHostname()) {
+				return false
+			}
+		case AGENT_ID:
+			if !d.filterOnValue(filter, offer.GetAgentId().GetValue()) {
+				return false
+			}
+		case CONSTRAINT:
+			if !d.filterOnConstraints(filter.Value, offer) {
+				return false
+			}
+		default:
+			attrFilters++
+			if !attrMatch && d.filterOnAttributes(filter, offer) {
+				attrMatch = true
+			}
+		}
+	}
+
+	return attrFilters == 0 || attrMatch
+}
+
+// Records that a task is placed on an agent so that task constraints can be evaluated against it.
+// Assign records placements automatically, this is useful for restoring placements of already running tasks.
+func (d *DefaultResourceManager) AddPlacement(name string, agent *mesos_v1.AgentID) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.addPlacement(name, agent)
+}
+
+func (d *DefaultResourceManager) addPlacement(name string, agent *mesos_v1.AgentID) {
+	tasks, ok := d.placements[agent.GetValue()]
+	if !ok {
+		tasks = make(map[string]struct{})
+		d.placements[agent.GetValue()] = tasks
+	}
+	tasks[name] = struct{}{}
+}
+
+// Removes all placements of a task, typically once it has reached a terminal state.
+func (d *DefaultResourceManager) RemovePlacement(name string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	for agent, tasks := range d.placements {
+		delete(tasks, name)
+		if len(tasks) == 0 {
+			delete(d.placements, agent)
+		}
+	}
+}
+
+// Returns the roles a resource can be allocated from, in order of preference.
+// A resource that explicitly asks for a role can only use that role.
+// Otherwise resources reserved for the framework's role are preferred, falling back to unreserved resources.
+func (d *DefaultResourceManager) candidateRoles(resource *mesos_v1.Resource) []string {
+	if resource.Role != nil && resource.GetRole() != UNRESERVED {
+		return []string{resource.GetRole()}
+	}
+	if d.role != "" && d.role != UNRESERVED {
+		return []string{d.role, UNRESERVED}
+	}
+
+	return []string{UNRESERVED}
+}
+
+// Marks a task's resource with the role and reservation it was allocated from so it can be launched as is.
+func (d *DefaultResourceManager) setAllocatedRole(resource *mesos_v1.Resource, r *RoleResources) {
+	resource.Role = utils.ProtoString(r.Role)
+	resource.Reservation = r.Reservation
+}
+
+// allocateMemResources returns the role the memory was allocated from and tells us if we have enough memory resources on this offer.
+func (d *DefaultResourceManager) allocateMemResource(mem float64, roles []string, revocable bool, offer *MesosOfferResources) (*RoleResources, bool) {
+	for _, role := range roles {
+		r, ok := offer.pool(revocable)[role]
+		if ok && d.ownsReservation(r.Reservation) && r.Mem-mem >= 0 {
+			r.Mem = r.Mem - mem
+			offer.Mem = offer.Mem - mem
+			return r, true
+		}
+	}
+
+	return nil, false
+}
+
+// allocateCpuResources returns the role the cpus were allocated from and tells us if we have enough cpu resources on this offer.
+func (d *DefaultResourceManager) allocateCpuResource(cpu float64, roles []string, revocable bool, offer *MesosOfferResources) (*RoleResources, bool) {
+	for _, role := range roles {
+		r, ok := offer.pool(revocable)[role]
+		if ok && d.ownsReservation(r.Reservation) && r.Cpu-cpu >= 0 {
+			r.Cpu = r.Cpu - cpu
+			offer.Cpu = offer.Cpu - cpu
+			return r, true
+		}
+	}
+
+	return nil, false
+}
+
+// allocateGpuResources returns the role the gpus were allocated from and tells us if we have enough gpu resources on this offer.
+func (d *DefaultResourceManager) allocateGpuResource(gpu float64, roles []string, revocable bool, offer *MesosOfferResources) (*RoleResources, bool) {
+	for _, role := range roles {
+		r, ok := offer.pool(revocable)[role]
+		if ok && d.ownsReservation(r.Reservation) && r.Gpu-gpu >= 0 {
+			r.Gpu = r.Gpu - gpu
+			offer.Gpu = offer.Gpu - gpu
+			return r, true
+		}
+	}
+
+	return nil, false
+}
+
+// allocateCustomResource returns the role the named scalar resource was allocated from
+// and tells us if we have enough of it on this offer.
+func (d *DefaultResourceManager) allocateCustomResource(name string, amount float64, roles []string, revocable bool, offer *MesosOfferResources) (*RoleResources, bool) {
+	for _, role := range roles {
+		r, ok := offer.pool(revocable)[role]
+		if ok && d.ownsReservation(r.Reservation) && r.Custom[name]-amount >= 0 {
+			r.Custom[name] = r.Custom[name] - amount
+			offer.Custom[name] = offer.Custom[name] - amount
+			return r, true
+		}
+	}
+
+	return nil, false
+}
+
+// allocatePortResource returns a boolean and tells us if the requested port ranges are available on this offer.
+// Each requested range is carved out of the offer's available ranges and recorded as consumed.
+func (d *DefaultResourceManager) allocatePortResource(ranges *mesos_v1.Value_Ranges, offer *MesosOfferResources) bool {
+	requested := ranges.GetRange()
+
+	// Fail fast if the task asks for more ports than the offer has in total.
+	if countPorts(requested) > countPorts(offer.Ports) {
+		return false
+	}
+
+	remaining := offer.Ports
+	for _, r := range requested {
+		var ok bool
+		remaining, ok = subtractRange(remaining, r)
+		if !ok {
+			return false
+		}
+	}
+
+	offer.Ports = remaining
+	offer.ConsumedPorts = append(offer.ConsumedPorts, copyRanges(requested)...)
+
+	return true
+}
+
 // If a task has offer filters but the offer doesn't satisfy them, return false, otherwise true.
 func (d *DefaultResourceManager) filterOnOffer(task *manager.Task, offer *MesosOfferResources) bool {
 	validOffer := d.filter(task.Filters, offer.Offer)
@@ -200,58 +721,212 @@ func (d *DefaultResourceManager) filterOnOffer(task *manager.Task, offer *MesosO
 }
 
 // Check if an offer has enough resources for a task's request.
+// Resources are consumed transactionally: deductions are staged against a copy of the offer
+// and only committed once all of the task's resources fit, otherwise the offer is left untouched.
 func (d *DefaultResourceManager) hasSufficientResources(task *manager.Task, offer *MesosOfferResources) bool {
-	// Eat up this offer's resources with the task's needs.
-	for _, resource := range task.Info.Resources {
+	staged := offer.copy()
+	allocated := make(map[*mesos_v1.Resource]*RoleResources)
+	disks := make(map[*mesos_v1.Resource]*OfferDisk)
+
+	if !d.allocateResources(task.Info.Resources, staged, allocated, disks) {
+		return false
+	}
+
+	// Custom executors need their own resources, but only once per agent no matter how many tasks they run.
+	if executor := task.Info.GetExecutor(); executor != nil {
+		id := executor.GetExecutorId().GetValue()
+		if _, ok := staged.Executors[id]; !ok {
+			if !d.allocateResources(executor.Resources, staged, allocated, disks) {
+				return false
+			}
+			staged.Executors[id] = struct{}{}
+		}
+	}
+
+	// Everything fits, commit the staged deductions.
+	*offer = *staged
+	for resource, r := range allocated {
+		d.setAllocatedRole(resource, r)
+	}
+	for resource, disk := range disks {
+		d.setAllocatedDisk(resource, disk)
+	}
+
+	return true
+}
+
+// Eats up the staged offer's resources with the given needs.
+// The role each scalar resource was consumed from is recorded in allocated, and the disk each disk resource used in disks.
+func (d *DefaultResourceManager) allocateResources(resources []*mesos_v1.Resource, staged *MesosOfferResources,
+	allocated map[*mesos_v1.Resource]*RoleResources, disks map[*mesos_v1.Resource]*OfferDisk) bool {
+
+	for _, resource := range resources {
 		res := resource.GetScalar().GetValue()
 
 		switch resource.GetName() {
 		case "cpus":
-			if d.allocateCpuResource(res, offer) {
-				break
+			r, ok := d.allocateCpuResource(res, d.candidateRoles(resource), resource.GetRevocable() != nil, staged)
+			if !ok {
+				// We can't use this offer if it has no CPUs, move on to the next offer.
+				return false
 			}
-
-			// We can't use this offer if it has no CPUs, move on to the next offer.
-			return false
+			allocated[resource] = r
 		case "mem":
-			if d.allocateMemResource(res, offer) {
-				break
+			r, ok := d.allocateMemResource(res, d.candidateRoles(resource), resource.GetRevocable() != nil, staged)
+			if !ok {
+				// We can't use this offer if it has no memory, move on to the next offer.
+				return false
 			}
-
-			// We can't use this offer if it has no memory, move on to the next offer.
-			return false
+			allocated[resource] = r
+		case "gpus":
+			r, ok := d.allocateGpuResource(res, d.candidateRoles(resource), resource.GetRevocable() != nil, staged)
+			if !ok {
+				// We can't use this offer if it doesn't have enough GPUs, move on to the next offer.
+				return false
+			}
+			allocated[resource] = r
 		case "disk":
-			d.allocateDiskResource(resource, offer)
+			disk, ok := d.allocateDiskResource(resource, d.candidateRoles(resource), staged)
+			if !ok {
+				// We can't use this offer if it doesn't have a matching disk, move on to the next offer.
+				return false
+			}
+			disks[resource] = disk
+		case "ports":
+			if !d.allocatePortResource(resource.GetRanges(), staged) {
+				// We can't use this offer if the requested ports aren't available, move on to the next offer.
+				return false
+			}
+		default:
+			if resource.GetType() != SCALAR {
+				continue
+			}
+			r, ok := d.allocateCustomResource(resource.GetName(), res, d.candidateRoles(resource), resource.GetRevocable() != nil, staged)
+			if !ok {
+				// We can't use this offer if it doesn't have enough of the named resource, move on to the next offer.
+				return false
+			}
+			allocated[resource] = r
 		}
 	}
+
 	return true
 }
 
 // Assign an offer to a task.
+// A *QuotaExceededError is returned if the task's group doesn't have enough quota left for it.
+// The task's cpu, memory and gpu resources are updated with the role and reservation they were consumed from.
 func (d *DefaultResourceManager) Assign(task *manager.Task) (*mesos_v1.Offer, error) {
-	for i, offer := range d.offers {
-		// First check if we even have enough resources before even looking at filters.
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	// If the task has no filters to apply then the offer is used up by this task alone.
+	allocation, err := d.assign(task, len(task.Filters) == 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return allocation.Offer, nil
+}
+
+// Finds an offer for a task.
+// Offers are removed once assigned if pop is set, otherwise they're marked as accepted
+// and their remaining resources can be assigned to other tasks.
+func (d *DefaultResourceManager) assign(task *manager.Task, pop bool) (*Allocation, error) {
+	if err := d.checkQuota(task); err != nil {
+		return nil, err
+	}
+
+	for _, offer := range d.strategy.Rank(task, d.offers) {
+		if offer.Draining {
+			continue
+		}
+
+		// Skip offers that don't satisfy the task's filters before we consume any of their resources.
+		if len(task.Filters) > 0 && !d.filterOnOffer(task, offer) {
+			continue
+		}
+
 		if !d.hasSufficientResources(task, offer) {
 			continue
 		}
 
-		// If the task has no filters to apply or no filters match then return the offer.
-		if len(task.Filters) == 0 || !d.filterOnOffer(task, offer) {
-			d.popOffer(i)
-			return offer.Offer, nil
+		d.addPlacement(task.Info.GetName(), offer.Offer.GetAgentId())
+		d.useQuota(task)
+		if observer, ok := d.strategy.(PlacementObserver); ok {
+			observer.Placed(task, offer.Offer)
 		}
 
-		if d.filterOnOffer(task, offer) {
-			d.offers[i].Accepted = true
-			return offer.Offer, nil
+		if pop {
+			d.removeOffer(offer)
+		} else {
+			offer.Accepted = true
 		}
+
+		return newAllocation(task, offer.Offer), nil
 	}
 
 	return nil, errors.New("Cannot find a suitable offer for task " + task.Info.GetName())
 }
 
+// Assigns offers to a whole batch of tasks at once.
+// Tasks are placed largest first so that smaller tasks can fill in the gaps, and offers stay available
+// to the rest of the batch after being assigned so that several tasks can be launched with a single accept per offer.
+// Each task's filters and group quota apply just as they do for Assign.
+// An error is returned for every task that could not be placed.
+func (d *DefaultResourceManager) AssignAll(tasks []*manager.Task) (map[*manager.Task]*mesos_v1.Offer, []error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	sorted := make([]*manager.Task, len(tasks))
+	copy(sorted, tasks)
+	sort.Stable(sort.Reverse(byRequestedResources(sorted)))
+
+	assigned := make(map[*manager.Task]*mesos_v1.Offer, len(tasks))
+	var errs []error
+	for _, task := range sorted {
+		allocation, err := d.assign(task, false)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		assigned[task] = allocation.Offer
+	}
+
+	return assigned, errs
+}
+
+// Sorts tasks by their requested cpus, then by their requested memory.
+type byRequestedResources []*manager.Task
+
+func (b byRequestedResources) Len() int      { return len(b) }
+func (b byRequestedResources) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b byRequestedResources) Less(i, j int) bool {
+	iCpu, jCpu := requestedScalar(b[i].Info, "cpus"), requestedScalar(b[j].Info, "cpus")
+	if iCpu != jCpu {
+		return iCpu < jCpu
+	}
+
+	return requestedScalar(b[i].Info, "mem") < requestedScalar(b[j].Info, "mem")
+}
+
+// Sums up the named scalar resource requested by a task.
+func requestedScalar(info *mesos_v1.TaskInfo, name string) float64 {
+	var total float64
+	for _, resource := range info.GetResources() {
+		if resource.GetName() == name {
+			total += resource.GetScalar().GetValue()
+		}
+	}
+
+	return total
+}
+
 // Returns a list of offers that have not been altered and returned to the client for accept calls.
 func (d *DefaultResourceManager) Offers() (offers []*mesos_v1.Offer) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
 	for _, o := range d.offers {
 		if !o.Accepted {
 			offers = append(offers, o.Offer)
@@ -0,0 +1,1038 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"encoding/json"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/resources"
+	"github.com/verizonlabs/mesos-framework-sdk/task"
+	"github.com/verizonlabs/mesos-framework-sdk/task/manager"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Creates an offer with the given id, resources and attributes.
+func createOffer(id string, res []*mesos_v1.Resource, attrs []*mesos_v1.Attribute) *mesos_v1.Offer {
+	return &mesos_v1.Offer{
+		Id:         &mesos_v1.OfferID{Value: utils.ProtoString(id)},
+		AgentId:    &mesos_v1.AgentID{Value: utils.ProtoString(id + "-agent")},
+		Hostname:   utils.ProtoString(id + ".host"),
+		Resources:  res,
+		Attributes: attrs,
+	}
+}
+
+// Creates a task with the given scalar resources and filters.
+func createTask(name string, cpu, mem float64, filters []task.Filter) *manager.Task {
+	info := &mesos_v1.TaskInfo{
+		Name: utils.ProtoString(name),
+		Resources: []*mesos_v1.Resource{
+			resources.CreateResource("cpus", "", cpu),
+			resources.CreateResource("mem", "", mem),
+		},
+	}
+
+	return manager.NewTask(info, manager.STAGING, filters, nil, 1, manager.GroupInfo{})
+}
+
+func createSetAttribute(name string, items ...string) *mesos_v1.Attribute {
+	return &mesos_v1.Attribute{
+		Name: utils.ProtoString(name),
+		Type: mesos_v1.Value_SET.Enum(),
+		Set:  &mesos_v1.Value_Set{Item: items},
+	}
+}
+
+func createRangesAttribute(name string, ranges ...*mesos_v1.Value_Range) *mesos_v1.Attribute {
+	return &mesos_v1.Attribute{
+		Name:   utils.ProtoString(name),
+		Type:   mesos_v1.Value_RANGES.Enum(),
+		Ranges: &mesos_v1.Value_Ranges{Range: ranges},
+	}
+}
+
+func createRange(begin, end uint64) *mesos_v1.Value_Range {
+	return &mesos_v1.Value_Range{
+		Begin: utils.ProtoUint64(begin),
+		End:   utils.ProtoUint64(end),
+	}
+}
+
+// Ensures set attributes match on membership.
+func TestDefaultResourceManager_FilterOnAttrSet(t *testing.T) {
+	t.Parallel()
+
+	d := NewDefaultResourceManager()
+	attr := createSetAttribute("racks", "r1", "R2")
+
+	if !d.filterOnAttrSet([]string{"r3", "r2"}, attr) {
+		t.Fatal("Set filter should match when any term is a member of the set")
+	}
+	if d.filterOnAttrSet([]string{"r3"}, attr) {
+		t.Fatal("Set filter should not match when no terms are members of the set")
+	}
+	if d.filterOnAttrSet([]string{}, attr) {
+		t.Fatal("Set filter with no terms should not match")
+	}
+}
+
+// Measures performance of matching set attributes.
+func BenchmarkDefaultResourceManager_FilterOnAttrSet(b *testing.B) {
+	d := NewDefaultResourceManager()
+	attr := createSetAttribute("racks", "r1", "r2", "r3")
+	terms := []string{"r4", "r3"}
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		d.filterOnAttrSet(terms, attr)
+	}
+}
+
+// Ensures ranges attributes match on numeric containment.
+func TestDefaultResourceManager_FilterOnAttrRanges(t *testing.T) {
+	t.Parallel()
+
+	d := NewDefaultResourceManager()
+	attr := createRangesAttribute("levels", createRange(1, 5), createRange(10, 20))
+
+	if !d.filterOnAttrRanges([]string{"3"}, attr) {
+		t.Fatal("Ranges filter should match a single contained value")
+	}
+	if !d.filterOnAttrRanges([]string{"12-15"}, attr) {
+		t.Fatal("Ranges filter should match a contained range")
+	}
+	if !d.filterOnAttrRanges([]string{"[10-20]"}, attr) {
+		t.Fatal("Ranges filter should match a bracketed range")
+	}
+	if d.filterOnAttrRanges([]string{"4-11"}, attr) {
+		t.Fatal("Ranges filter should not match a range spanning a gap")
+	}
+	if d.filterOnAttrRanges([]string{"7", "abc", "9-8"}, attr) {
+		t.Fatal("Ranges filter should not match uncontained or invalid terms")
+	}
+}
+
+// Measures performance of matching ranges attributes.
+func BenchmarkDefaultResourceManager_FilterOnAttrRanges(b *testing.B) {
+	d := NewDefaultResourceManager()
+	attr := createRangesAttribute("levels", createRange(1, 5), createRange(10, 20))
+	terms := []string{"12-15"}
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		d.filterOnAttrRanges(terms, attr)
+	}
+}
+
+// Ensures tasks with filters are only assigned to offers that satisfy them.
+func TestDefaultResourceManager_AssignWithFilters(t *testing.T) {
+	t.Parallel()
+
+	res := func() []*mesos_v1.Resource {
+		return []*mesos_v1.Resource{
+			resources.CreateResource("cpus", "", 4),
+			resources.CreateResource("mem", "", 1024),
+		}
+	}
+
+	d := NewDefaultResourceManager()
+	d.AddOffers([]*mesos_v1.Offer{
+		createOffer("a", res(), []*mesos_v1.Attribute{createSetAttribute("racks", "r1")}),
+		createOffer("b", res(), []*mesos_v1.Attribute{createRangesAttribute("levels", createRange(1, 5))}),
+	})
+
+	offer, err := d.Assign(createTask("set", 1, 128, []task.Filter{{Type: "racks", Value: []string{"r1"}}}))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if offer.GetId().GetValue() != "a" {
+		t.Fatal("Task with a set filter was assigned to the wrong offer")
+	}
+
+	offer, err = d.Assign(createTask("ranges", 1, 128, []task.Filter{{Type: "levels", Value: []string{"2-3"}}}))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if offer.GetId().GetValue() != "b" {
+		t.Fatal("Task with a ranges filter was assigned to the wrong offer")
+	}
+
+	_, err = d.Assign(createTask("none", 1, 128, []task.Filter{{Type: "racks", Value: []string{"r9"}}}))
+	if err == nil {
+		t.Fatal("Task with an unsatisfiable filter should not be assigned an offer")
+	}
+}
+
+// Ensures reserved resources for the framework's role are preferred over unreserved ones.
+func TestDefaultResourceManager_AssignRoles(t *testing.T) {
+	t.Parallel()
+
+	d := NewDefaultResourceManager()
+	d.SetRole("web")
+	d.AddOffers([]*mesos_v1.Offer{
+		createOffer("a", []*mesos_v1.Resource{
+			resources.CreateResource("cpus", "web", 1),
+			resources.CreateResource("mem", "web", 256),
+			resources.CreateResource("cpus", "", 4),
+			resources.CreateResource("mem", "", 1024),
+			resources.CreateResource("cpus", "other", 8),
+		}, nil),
+	})
+
+	offer := d.offers[0]
+	if offer.Cpu != 13 || offer.Roles["web"].Cpu != 1 || offer.Roles[UNRESERVED].Cpu != 4 {
+		t.Fatal("Resources were not tracked per role")
+	}
+
+	tsk := createTask("reserved", 1, 512, []task.Filter{{Type: "none", Value: []string{"none"}}})
+	if !d.hasSufficientResources(tsk, offer) {
+		t.Fatal("Task should fit on the offer")
+	}
+	if tsk.Info.Resources[0].GetRole() != "web" {
+		t.Fatal("Cpus should have been allocated from the framework's reserved role")
+	}
+	if tsk.Info.Resources[1].GetRole() != UNRESERVED {
+		t.Fatal("Memory should have fallen back to unreserved resources")
+	}
+
+	tsk = createTask("other", 8, 128, nil)
+	if d.hasSufficientResources(tsk, offer) {
+		t.Fatal("Resources reserved for another role should not be used")
+	}
+}
+
+// Ensures GPU requests are allocated from offers that have them.
+func TestDefaultResourceManager_AssignGpus(t *testing.T) {
+	t.Parallel()
+
+	d := NewDefaultResourceManager()
+	d.AddOffers([]*mesos_v1.Offer{
+		createOffer("cpu-only", []*mesos_v1.Resource{
+			resources.CreateResource("cpus", "", 4),
+			resources.CreateResource("mem", "", 1024),
+		}, nil),
+		createOffer("gpu", []*mesos_v1.Resource{
+			resources.CreateResource("cpus", "", 4),
+			resources.CreateResource("mem", "", 1024),
+			resources.CreateResource("gpus", "", 2),
+		}, nil),
+	})
+
+	tsk := createTask("gpu", 1, 128, nil)
+	tsk.Info.Resources = append(tsk.Info.Resources, resources.CreateResource("gpus", "", 2))
+	offer, err := d.Assign(tsk)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if offer.GetId().GetValue() != "gpu" {
+		t.Fatal("GPU task was assigned to an offer without GPUs")
+	}
+
+	tsk = createTask("gpu", 1, 128, nil)
+	tsk.Info.Resources = append(tsk.Info.Resources, resources.CreateResource("gpus", "", 1))
+	if _, err := d.Assign(tsk); err == nil {
+		t.Fatal("GPU task should not fit once all GPUs are consumed")
+	}
+}
+
+// Ensures task affinity and anti-affinity constraints are honored.
+func TestDefaultResourceManager_AssignTaskConstraints(t *testing.T) {
+	t.Parallel()
+
+	res := func() []*mesos_v1.Resource {
+		return []*mesos_v1.Resource{
+			resources.CreateResource("cpus", "", 4),
+			resources.CreateResource("mem", "", 1024),
+		}
+	}
+
+	d := NewDefaultResourceManager()
+	d.AddPlacement("db", &mesos_v1.AgentID{Value: utils.ProtoString("b-agent")})
+	d.AddOffers([]*mesos_v1.Offer{
+		createOffer("a", res(), nil),
+		createOffer("b", res(), nil),
+	})
+
+	offer, err := d.Assign(createTask("cache", 1, 128, []task.Filter{{Type: LIKE_TASK, Value: []string{"db"}}}))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if offer.GetId().GetValue() != "b" {
+		t.Fatal("LIKE_TASK constraint should place the task next to the named task")
+	}
+
+	offer, err = d.Assign(createTask("web", 1, 128, []task.Filter{{Type: UNLIKE_TASK, Value: []string{"db", "cache"}}}))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if offer.GetId().GetValue() != "a" {
+		t.Fatal("UNLIKE_TASK constraint should keep the task away from the named tasks")
+	}
+
+	d.RemovePlacement("db")
+	if d.agentRunsTask([]string{"db"}, createOffer("b", nil, nil)) {
+		t.Fatal("Placement should have been removed")
+	}
+}
+
+// Ensures tasks can be pinned to agents by hostname and agent ID.
+func TestDefaultResourceManager_FilterOnAgent(t *testing.T) {
+	t.Parallel()
+
+	d := NewDefaultResourceManager()
+	offer := createOffer("node1", nil, nil)
+
+	tests := []struct {
+		filter   task.Filter
+		expected bool
+	}{
+		{task.Filter{Type: "hostname", Value: []string{"NODE1.host"}}, true},
+		{task.Filter{Type: "hostname", Value: []string{"node2.host"}}, false},
+		{task.Filter{Type: "hostname", Value: []string{`node\d\.host`}, Regex: true}, true},
+		{task.Filter{Type: "hostname", Value: []string{"node"}, Regex: true}, false},
+		{task.Filter{Type: "hostname", Value: []string{"("}, Regex: true}, false},
+		{task.Filter{Type: "agent_id", Value: []string{"node1-agent"}}, true},
+		{task.Filter{Type: "agent_id", Value: []string{"node2-.*", "node1-.*"}, Regex: true}, true},
+		{task.Filter{Type: "agent_id", Value: []string{"node2-agent"}}, false},
+	}
+
+	for _, test := range tests {
+		if d.filter([]task.Filter{test.filter}, offer) != test.expected {
+			t.Fatalf("Filter %v should have returned %v", test.filter, test.expected)
+		}
+	}
+}
+
+// Ensures an offer is left untouched when only some of a task's resources fit.
+func TestDefaultResourceManager_AssignRollback(t *testing.T) {
+	t.Parallel()
+
+	d := NewDefaultResourceManager()
+	d.SetRole("web")
+	d.AddOffers([]*mesos_v1.Offer{
+		createOffer("a", []*mesos_v1.Resource{
+			resources.CreateResource("cpus", "web", 4),
+			resources.CreateResource("mem", "web", 128),
+			resources.CreateRangeResource("ports", "", []*mesos_v1.Value_Range{createRange(8000, 8010)}),
+		}, nil),
+	})
+
+	tsk := createTask("big", 1, 512, nil)
+	tsk.Info.Resources = append([]*mesos_v1.Resource{
+		resources.CreateRangeResource("ports", "", []*mesos_v1.Value_Range{createRange(8000, 8000)}),
+	}, tsk.Info.Resources...)
+	if _, err := d.Assign(tsk); err == nil {
+		t.Fatal("Task should not fit on the offer")
+	}
+
+	offer := d.offers[0]
+	if offer.Cpu != 4 || offer.Roles["web"].Cpu != 4 {
+		t.Fatal("Cpu deduction was not rolled back")
+	}
+	if countPorts(offer.Ports) != 11 || len(offer.ConsumedPorts) != 0 {
+		t.Fatal("Port deduction was not rolled back")
+	}
+	if tsk.Info.Resources[1].Role != nil {
+		t.Fatal("Task resources should not be assigned a role when allocation fails")
+	}
+
+	if _, err := d.Assign(createTask("small", 1, 64, nil)); err != nil {
+		t.Fatal("Task should fit on the untouched offer: " + err.Error())
+	}
+}
+
+// Ensures a batch of tasks is packed onto offers and unplaceable tasks are reported.
+func TestDefaultResourceManager_AssignAll(t *testing.T) {
+	t.Parallel()
+
+	d := NewDefaultResourceManager()
+	d.AddOffers([]*mesos_v1.Offer{
+		createOffer("a", []*mesos_v1.Resource{
+			resources.CreateResource("cpus", "", 3),
+			resources.CreateResource("mem", "", 1024),
+		}, nil),
+	})
+
+	small := createTask("small", 1, 128, nil)
+	large := createTask("large", 2, 128, nil)
+	huge := createTask("huge", 8, 128, nil)
+
+	assigned, errs := d.AssignAll([]*manager.Task{small, huge, large})
+	if len(errs) != 1 {
+		t.Fatal("Only the task that can't fit should fail")
+	}
+	if assigned[small] == nil || assigned[large] == nil || assigned[small] != assigned[large] {
+		t.Fatal("Both fitting tasks should share the same offer")
+	}
+	if _, ok := assigned[huge]; ok {
+		t.Fatal("Task that can't fit should not be assigned")
+	}
+}
+
+// Ensures batch assignment applies each task's group quota.
+func TestDefaultResourceManager_AssignAllQuota(t *testing.T) {
+	t.Parallel()
+
+	grouped := func(name string) *manager.Task {
+		task := createTask(name, 1, 128, nil)
+		task.GroupInfo = manager.GroupInfo{GroupName: "batch", InGroup: true}
+		return task
+	}
+
+	d := NewDefaultResourceManager()
+	d.AddOffers([]*mesos_v1.Offer{createOffer("a", []*mesos_v1.Resource{
+		resources.CreateResource("cpus", "", 4),
+		resources.CreateResource("mem", "", 1024),
+	}, nil)})
+	d.SetQuota("batch", 1, 0)
+
+	assigned, errs := d.AssignAll([]*manager.Task{grouped("a"), grouped("b"), createTask("c", 1, 128, nil)})
+	if len(assigned) != 2 || len(errs) != 1 {
+		t.Fatal("Only the task over its group's quota should fail")
+	}
+	if _, ok := errs[0].(*QuotaExceededError); !ok {
+		t.Fatalf("Expected a quota error, got %v", errs[0])
+	}
+}
+
+// Measures performance of assigning grouped tasks in a batch.
+func BenchmarkDefaultResourceManager_AssignAllQuota(b *testing.B) {
+	d := NewDefaultResourceManager()
+	d.SetQuota("batch", 1, 0)
+	task := createTask("a", 1, 128, nil)
+	task.GroupInfo = manager.GroupInfo{GroupName: "batch", InGroup: true}
+	for n := 0; n < b.N; n++ {
+		d.AddOffers(createSizedOffers())
+		d.AssignAll([]*manager.Task{task})
+		d.ReleaseQuota(task)
+	}
+}
+
+// Ensures reservations are tracked and only unreserved once released and offered back.
+func TestDefaultResourceManager_Reservations(t *testing.T) {
+	t.Parallel()
+
+	d := NewDefaultResourceManager()
+	reserved := []*mesos_v1.Resource{resources.CreateReservedResource("cpus", "web", "principal", 2)}
+	offer := createOffer("a", []*mesos_v1.Resource{resources.CreateResource("cpus", "", 4)}, nil)
+
+	op := d.Reserve("db", offer, reserved)
+	if op.GetType() != mesos_v1.Offer_Operation_RESERVE || len(op.GetReserve().GetResources()) != 1 {
+		t.Fatal("Reserve operation was not created correctly")
+	}
+	if _, ok := d.Reservation("db"); !ok || len(d.Reservations()) != 1 {
+		t.Fatal("Reservation was not tracked")
+	}
+
+	offer = createOffer("a", reserved, nil)
+	if len(d.UnreserveOperations(offer)) != 0 {
+		t.Fatal("Reservation should not be unreserved before it's released")
+	}
+
+	d.ReleaseReservation("db")
+	if len(d.UnreserveOperations(createOffer("b", reserved, nil))) != 0 {
+		t.Fatal("Reservation should only be unreserved on the agent it was made on")
+	}
+
+	ops := d.UnreserveOperations(offer)
+	if len(ops) != 1 || ops[0].GetType() != mesos_v1.Offer_Operation_UNRESERVE {
+		t.Fatal("Released reservation should have been unreserved")
+	}
+	if len(d.Reservations()) != 0 {
+		t.Fatal("Reservation should be forgotten once unreserved")
+	}
+}
+
+// Ensures persistent volumes are tracked per agent.
+func TestDefaultResourceManager_Volumes(t *testing.T) {
+	t.Parallel()
+
+	d := NewDefaultResourceManager()
+	agent := &mesos_v1.AgentID{Value: utils.ProtoString("a-agent")}
+	volume := resources.CreatePersistentVolume("data", "db", "principal", "/data", 1024)
+
+	op, err := d.CreateVolume(createOffer("a", nil, nil), volume)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if op.GetType() != mesos_v1.Offer_Operation_CREATE {
+		t.Fatal("Create operation was not created correctly")
+	}
+	if _, ok := d.Volume(agent, "data"); !ok || len(d.Volumes(agent)) != 1 {
+		t.Fatal("Persistent volume was not tracked")
+	}
+
+	if _, err := d.CreateVolume(createOffer("a", nil, nil), resources.CreateResource("disk", "db", 1)); err == nil {
+		t.Fatal("Volumes without an ID should be rejected")
+	}
+
+	op, err = d.DestroyVolume(agent, "data")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if op.GetType() != mesos_v1.Offer_Operation_DESTROY || len(d.Volumes(agent)) != 0 {
+		t.Fatal("Persistent volume was not destroyed")
+	}
+	if _, err := d.DestroyVolume(agent, "data"); err == nil {
+		t.Fatal("Destroying an unknown volume should fail")
+	}
+
+	d.AddOffers([]*mesos_v1.Offer{createOffer("b", []*mesos_v1.Resource{volume}, nil)})
+	if len(d.Volumes(&mesos_v1.AgentID{Value: utils.ProtoString("b-agent")})) != 1 {
+		t.Fatal("Offered persistent volumes should be tracked")
+	}
+}
+
+// Ensures executor resources are accounted for once per agent.
+func TestDefaultResourceManager_AssignExecutorOverhead(t *testing.T) {
+	t.Parallel()
+
+	executor := &mesos_v1.ExecutorInfo{
+		ExecutorId: &mesos_v1.ExecutorID{Value: utils.ProtoString("executor")},
+		Resources: []*mesos_v1.Resource{
+			resources.CreateResource("cpus", "", 1),
+			resources.CreateResource("mem", "", 100),
+		},
+	}
+	tasks := make([]*manager.Task, 3)
+	for i := range tasks {
+		tasks[i] = createTask("task", 1, 100, nil)
+		tasks[i].Info.Executor = executor
+	}
+
+	d := NewDefaultResourceManager()
+	d.AddOffers([]*mesos_v1.Offer{createOffer("a", []*mesos_v1.Resource{
+		resources.CreateResource("cpus", "", 3),
+		resources.CreateResource("mem", "", 300),
+	}, nil)})
+
+	assigned, errs := d.AssignAll(tasks)
+	if len(assigned) != 2 || len(errs) != 1 {
+		t.Fatal("Executor overhead should be consumed once for tasks sharing an executor")
+	}
+
+	// Executors already running on the agent don't need their resources again.
+	offer := createOffer("b", []*mesos_v1.Resource{
+		resources.CreateResource("cpus", "", 1),
+		resources.CreateResource("mem", "", 100),
+	}, nil)
+	offer.ExecutorIds = []*mesos_v1.ExecutorID{executor.ExecutorId}
+	d.AddOffers([]*mesos_v1.Offer{offer})
+
+	if _, err := d.Assign(manager.NewTask(tasks[0].Info, manager.STAGING, nil, nil, 1, manager.GroupInfo{})); err != nil {
+		t.Fatal("Running executors should not be charged for again: " + err.Error())
+	}
+}
+
+// Ensures the resource manager can be used from several goroutines at once.
+// Run with the race detector to catch unsynchronized access.
+func TestDefaultResourceManager_Concurrency(t *testing.T) {
+	t.Parallel()
+
+	d := NewDefaultResourceManager()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(3)
+		go func(i int) {
+			defer wg.Done()
+			d.AddOffers([]*mesos_v1.Offer{createOffer(strconv.Itoa(i), []*mesos_v1.Resource{
+				resources.CreateResource("cpus", "", 1),
+				resources.CreateResource("mem", "", 128),
+			}, nil)})
+		}(i)
+		go func() {
+			defer wg.Done()
+			d.Assign(createTask("task", 1, 128, nil))
+			d.AssignAll([]*manager.Task{createTask("batch", 1, 128, nil)})
+		}()
+		go func() {
+			defer wg.Done()
+			d.HasResources()
+			d.Offers()
+			d.RemovePlacement("task")
+		}()
+	}
+	wg.Wait()
+}
+
+// Ensures constraint expressions are applied as filters.
+func TestDefaultResourceManager_AssignConstraints(t *testing.T) {
+	t.Parallel()
+
+	d := NewDefaultResourceManager()
+	d.AddOffers([]*mesos_v1.Offer{
+		createOffer("a", []*mesos_v1.Resource{
+			resources.CreateResource("cpus", "", 1),
+			resources.CreateResource("mem", "", 128),
+		}, []*mesos_v1.Attribute{createSetAttribute("disk_type", "hdd")}),
+		createOffer("b", []*mesos_v1.Resource{
+			resources.CreateResource("cpus", "", 1),
+			resources.CreateResource("mem", "", 128),
+		}, []*mesos_v1.Attribute{createSetAttribute("disk_type", "ssd")}),
+	})
+
+	offer, err := d.Assign(createTask("task", 1, 128, []task.Filter{
+		{Type: CONSTRAINT, Value: []string{`attr:disk_type == "ssd" && hostname ~= "b\..*"`}},
+	}))
+	if err != nil || offer.GetId().GetValue() != "b" {
+		t.Fatal("Constraint expression was not applied")
+	}
+
+	if _, err := d.Assign(createTask("task", 1, 128, []task.Filter{
+		{Type: CONSTRAINT, Value: []string{`attr:disk_type ==`}},
+	})); err == nil {
+		t.Fatal("Invalid constraint expressions should never match")
+	}
+}
+
+// Ensures stale offers are evicted.
+func TestDefaultResourceManager_ExpireOffers(t *testing.T) {
+	t.Parallel()
+
+	d := NewDefaultResourceManager()
+	d.AddOffers([]*mesos_v1.Offer{createOffer("a", nil, nil), createOffer("b", nil, nil)})
+	d.offers[0].Received = time.Now().Add(-time.Hour)
+
+	expired := d.ExpireOffers(time.Minute)
+	if len(expired) != 1 || expired[0].GetId().GetValue() != "a" || len(d.Offers()) != 1 {
+		t.Fatal("Stale offers were not expired")
+	}
+
+	declined := make(chan []*mesos_v1.OfferID, 1)
+	stop := make(chan struct{})
+	go d.ExpireOffersEvery(time.Millisecond, 0, func(ids []*mesos_v1.OfferID) {
+		declined <- ids
+	}, stop)
+
+	ids := <-declined
+	close(stop)
+	if len(ids) != 1 || ids[0].GetValue() != "b" || d.HasResources() {
+		t.Fatal("Expired offers were not declined")
+	}
+}
+
+// Ensures revocable and regular resources are never mixed.
+func TestDefaultResourceManager_AssignRevocable(t *testing.T) {
+	t.Parallel()
+
+	revocable := func(r *mesos_v1.Resource) *mesos_v1.Resource {
+		r.Revocable = &mesos_v1.Resource_RevocableInfo{}
+		return r
+	}
+
+	d := NewDefaultResourceManager()
+	d.AddOffers([]*mesos_v1.Offer{createOffer("a", []*mesos_v1.Resource{
+		resources.CreateResource("cpus", "", 1),
+		resources.CreateResource("mem", "", 128),
+		revocable(resources.CreateResource("cpus", "", 2)),
+		revocable(resources.CreateResource("mem", "", 256)),
+	}, nil)})
+
+	if _, err := d.AssignAll([]*manager.Task{createTask("regular", 2, 256, nil)}); err == nil {
+		t.Fatal("Regular tasks should not be given revocable resources")
+	}
+
+	task := createTask("revocable", 2, 256, nil)
+	for _, r := range task.Info.Resources {
+		revocable(r)
+	}
+	if _, err := d.Assign(task); err != nil {
+		t.Fatal("Revocable tasks should be given revocable resources: " + err.Error())
+	}
+}
+
+// Ensures group quotas are enforced.
+func TestDefaultResourceManager_Quota(t *testing.T) {
+	t.Parallel()
+
+	grouped := func() *manager.Task {
+		task := createTask("task", 1, 128, []task.Filter{{Type: AGENT_ID, Value: []string{"a-agent"}}})
+		task.GroupInfo = manager.GroupInfo{GroupName: "group", InGroup: true}
+		return task
+	}
+
+	d := NewDefaultResourceManager()
+	d.SetQuota("group", 1, 0)
+	d.AddOffers([]*mesos_v1.Offer{createOffer("a", []*mesos_v1.Resource{
+		resources.CreateResource("cpus", "", 4),
+		resources.CreateResource("mem", "", 512),
+	}, nil)})
+
+	first := grouped()
+	if _, err := d.Assign(first); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	_, err := d.Assign(grouped())
+	if q, ok := err.(*QuotaExceededError); !ok || q.Group != "group" || q.Resource != "cpus" {
+		t.Fatal("Quota should have been exceeded")
+	}
+
+	d.ReleaseQuota(first)
+	if q, _ := d.Quota("group"); q.UsedCpu != 0 {
+		t.Fatal("Quota was not released")
+	}
+	if _, err := d.Assign(grouped()); err != nil {
+		t.Fatal("Released quota should be usable again: " + err.Error())
+	}
+}
+
+// Ensures rescinded offers are removed.
+func TestDefaultResourceManager_RemoveOffer(t *testing.T) {
+	t.Parallel()
+
+	d := NewDefaultResourceManager()
+	d.AddOffers([]*mesos_v1.Offer{createOffer("a", nil, nil), createOffer("b", nil, nil)})
+	d.RemoveOffer(&mesos_v1.OfferID{Value: utils.ProtoString("a")})
+	d.RemoveOffer(&mesos_v1.OfferID{Value: utils.ProtoString("missing")})
+
+	offers := d.Offers()
+	if len(offers) != 1 || offers[0].GetId().GetValue() != "b" {
+		t.Fatal("Rescinded offer was not removed")
+	}
+}
+
+// Ensures offers accumulate across incremental adds without duplicates.
+func TestDefaultResourceManager_AddOffersIncremental(t *testing.T) {
+	t.Parallel()
+
+	d := NewDefaultResourceManager()
+	d.AddOffersIncremental([]*mesos_v1.Offer{createOffer("a", nil, nil)})
+	d.AddOffersIncremental([]*mesos_v1.Offer{createOffer("a", nil, nil), createOffer("b", nil, nil), createOffer("b", nil, nil)})
+	if len(d.Offers()) != 2 {
+		t.Fatal("Offers were not accumulated and deduplicated")
+	}
+
+	d.AddOffers([]*mesos_v1.Offer{createOffer("c", nil, nil)})
+	if len(d.Offers()) != 1 {
+		t.Fatal("AddOffers should still replace held offers")
+	}
+}
+
+// Measures performance of adding offers incrementally.
+func BenchmarkDefaultResourceManager_AddOffersIncremental(b *testing.B) {
+	d := NewDefaultResourceManager()
+	offers := []*mesos_v1.Offer{createOffer("a", nil, nil), createOffer("b", nil, nil)}
+	for n := 0; n < b.N; n++ {
+		d.AddOffersIncremental(offers)
+	}
+}
+
+// Ensures custom scalar resources are matched by name.
+func TestDefaultResourceManager_AssignCustom(t *testing.T) {
+	t.Parallel()
+
+	d := NewDefaultResourceManager()
+	d.AddOffers([]*mesos_v1.Offer{createOffer("a", []*mesos_v1.Resource{
+		resources.CreateResource("cpus", "", 2),
+		resources.CreateResource("mem", "", 256),
+		resources.CreateResource("licenses", "", 1),
+	}, nil)})
+
+	licensed := func() *manager.Task {
+		task := createTask("licensed", 1, 128, nil)
+		task.Info.Resources = append(task.Info.Resources, resources.CreateResource("licenses", "", 1))
+		return task
+	}
+
+	assigned, errs := d.AssignAll([]*manager.Task{licensed(), licensed()})
+	if len(assigned) != 1 || len(errs) != 1 {
+		t.Fatal("Custom resources were not consumed")
+	}
+}
+
+// Ensures allocations carry the exact resources consumed from the offer.
+func TestDefaultResourceManager_Allocate(t *testing.T) {
+	t.Parallel()
+
+	d := NewDefaultResourceManager()
+	d.SetRole("web")
+	d.AddOffers([]*mesos_v1.Offer{createOffer("a", []*mesos_v1.Resource{
+		resources.CreateReservedResource("cpus", "web", "principal", 1),
+		resources.CreateResource("mem", "", 128),
+		resources.CreateRangeResource("ports", "", []*mesos_v1.Value_Range{createRange(8000, 8010)}),
+	}, nil)})
+
+	task := createTask("task", 1, 128, nil)
+	task.Info.Resources = append(task.Info.Resources, resources.CreateRangeResource("ports", "", []*mesos_v1.Value_Range{createRange(8005, 8005)}))
+
+	allocation, err := d.Allocate(task)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if allocation.Offer.GetId().GetValue() != "a" || allocation.AgentID.GetValue() != "a-agent" || len(allocation.Resources) != 3 {
+		t.Fatal("Allocation does not describe the assignment")
+	}
+	if allocation.Resources[0].GetRole() != "web" || allocation.Resources[0].GetReservation().GetPrincipal() != "principal" {
+		t.Fatal("Allocated cpus are missing their role and reservation")
+	}
+	if allocation.Resources[1].GetRole() != UNRESERVED || allocation.Resources[2].GetRanges().GetRange()[0].GetBegin() != 8005 {
+		t.Fatal("Allocated memory and ports are wrong")
+	}
+
+	if _, err := d.Allocate(task); err == nil {
+		t.Fatal("Used up offers should not be allocated again")
+	}
+}
+
+// Ensures only resources reserved by the framework's principal are used.
+func TestDefaultResourceManager_AssignPrincipal(t *testing.T) {
+	t.Parallel()
+
+	d := NewDefaultResourceManager()
+	d.SetRole("web")
+	d.SetPrincipal("ours")
+	d.AddOffers([]*mesos_v1.Offer{
+		createOffer("a", []*mesos_v1.Resource{
+			resources.CreateReservedResource("cpus", "web", "theirs", 1),
+			resources.CreateReservedResource("mem", "web", "theirs", 128),
+		}, nil),
+		createOffer("b", []*mesos_v1.Resource{
+			resources.CreateReservedResource("cpus", "web", "ours", 1),
+			resources.CreateReservedResource("mem", "web", "ours", 128),
+		}, nil),
+	})
+
+	offer, err := d.Assign(createTask("task", 1, 128, nil))
+	if err != nil || offer.GetId().GetValue() != "b" {
+		t.Fatal("Resources reserved by another principal should not be used")
+	}
+	if _, err := d.Assign(createTask("task", 1, 128, nil)); err == nil {
+		t.Fatal("Resources reserved by another principal should not be used")
+	}
+}
+
+// Ensures planning computes placements without assigning anything.
+func TestDefaultResourceManager_Plan(t *testing.T) {
+	t.Parallel()
+
+	d := NewDefaultResourceManager()
+	d.AddOffers([]*mesos_v1.Offer{createOffer("a", []*mesos_v1.Resource{
+		resources.CreateResource("cpus", "", 2),
+		resources.CreateResource("mem", "", 256),
+	}, nil)})
+
+	tasks := []*manager.Task{
+		createTask("a", 1, 128, nil),
+		createTask("b", 1, 128, nil),
+		createTask("c", 1, 128, nil),
+	}
+
+	placements, err := d.Plan(tasks)
+	if err == nil || len(placements) != 2 || placements[0].Offer.GetId().GetValue() != "a" {
+		t.Fatal("Plan did not place the tasks that fit")
+	}
+
+	placements, err = d.Plan(tasks[:2])
+	if err != nil || len(placements) != 2 {
+		t.Fatal("Planning should not consume offers")
+	}
+	if len(d.Offers()) != 1 {
+		t.Fatal("Planning should not alter the manager's offers")
+	}
+}
+
+// Ensures planning applies group quotas and copies the manager's state without sharing it.
+func TestDefaultResourceManager_PlanQuota(t *testing.T) {
+	t.Parallel()
+
+	grouped := func(name string) *manager.Task {
+		task := createTask(name, 1, 128, nil)
+		task.GroupInfo = manager.GroupInfo{GroupName: "batch", InGroup: true}
+		return task
+	}
+
+	d := NewDefaultResourceManager()
+	d.AddOffers([]*mesos_v1.Offer{createOffer("a", []*mesos_v1.Resource{
+		resources.CreateResource("cpus", "", 4),
+		resources.CreateResource("mem", "", 1024),
+	}, nil)})
+	d.SetQuota("batch", 1, 0)
+
+	placements, err := d.Plan([]*manager.Task{grouped("a"), grouped("b"), createTask("c", 1, 128, nil)})
+	if err == nil || len(placements) != 2 {
+		t.Fatal("Planning should reject tasks over their group's quota")
+	}
+	if q, _ := d.Quota("batch"); q.UsedCpu != 0 {
+		t.Fatal("Planning should not charge the group's quota")
+	}
+
+	offer := createOffer("b", nil, nil)
+	d.Reserve("db", offer, []*mesos_v1.Resource{resources.CreateResource("cpus", "", 1)})
+	d.trackVolume(offer.GetAgentId().GetValue(), resources.CreateResource("disk", "", 1))
+	s := d.scratch()
+	if len(s.quotas) != 1 || s.quotas["batch"] == d.quotas["batch"] {
+		t.Fatal("Quotas should be copied")
+	}
+	if len(s.reservations) != 1 || s.reservations["db"] == d.reservations["db"] {
+		t.Fatal("Reservations should be copied")
+	}
+	if len(s.volumes) != 1 {
+		t.Fatal("Volumes should be copied")
+	}
+}
+
+// Measures performance of planning grouped tasks.
+func BenchmarkDefaultResourceManager_PlanQuota(b *testing.B) {
+	d := NewDefaultResourceManager()
+	d.AddOffers(createSizedOffers())
+	d.SetQuota("batch", 1, 0)
+	task := createTask("a", 1, 128, nil)
+	task.GroupInfo = manager.GroupInfo{GroupName: "batch", InGroup: true}
+	for n := 0; n < b.N; n++ {
+		d.Plan([]*manager.Task{task})
+	}
+}
+
+// Measures performance of planning placements.
+func BenchmarkDefaultResourceManager_Plan(b *testing.B) {
+	d := NewDefaultResourceManager()
+	d.AddOffers(createSizedOffers())
+	tasks := []*manager.Task{createTask("a", 1, 128, nil), createTask("b", 2, 256, nil)}
+	for n := 0; n < b.N; n++ {
+		d.Plan(tasks)
+	}
+}
+
+// Ensures the manager's state survives a snapshot and restore through JSON.
+func TestDefaultResourceManager_Snapshot(t *testing.T) {
+	t.Parallel()
+
+	d := NewDefaultResourceManager()
+	d.SetRole("web")
+	d.SetStrategy(NewSpreadByAttribute("zone"))
+	d.SetQuota("group", 4, 1024)
+	d.SetDrainHorizon(time.Hour)
+	d.Drain(&mesos_v1.AgentID{Value: utils.ProtoString("c-agent")}, &mesos_v1.Unavailability{
+		Start: &mesos_v1.TimeInfo{Nanoseconds: utils.ProtoInt64(time.Now().Add(30 * time.Minute).UnixNano())},
+	})
+	d.AddPlacement("task", &mesos_v1.AgentID{Value: utils.ProtoString("b-agent")})
+	d.Reserve("task", createOffer("b", nil, nil), []*mesos_v1.Resource{resources.CreateReservedResource("cpus", "web", "principal", 1)})
+	d.AddOffers([]*mesos_v1.Offer{createOffer("a", []*mesos_v1.Resource{
+		resources.CreateResource("cpus", "", 1),
+		resources.CreateResource("mem", "", 128),
+	}, nil)})
+
+	data, err := json.Marshal(d.Snapshot())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	s := new(Snapshot)
+	if err := json.Unmarshal(data, s); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	restored := NewDefaultResourceManager()
+	restored.Restore(s)
+	if restored.role != "web" || len(restored.Offers()) != 1 || !restored.agentRunsTask([]string{"task"}, createOffer("b", nil, nil)) {
+		t.Fatal("Offers and placements were not restored")
+	}
+	if _, ok := restored.strategy.(*SpreadByAttribute); !ok {
+		t.Fatal("Strategy was not restored")
+	}
+	if _, ok := restored.Reservation("task"); !ok {
+		t.Fatal("Reservations were not restored")
+	}
+	if q, ok := restored.Quota("group"); !ok || q.Cpu != 4 {
+		t.Fatal("Quotas were not restored")
+	}
+	if restored.drainHorizon != time.Hour || !restored.Draining(&mesos_v1.AgentID{Value: utils.ProtoString("c-agent")}) {
+		t.Fatal("Maintenance and the drain horizon were not restored")
+	}
+	if _, err := restored.Assign(createTask("task", 1, 128, nil)); err != nil {
+		t.Fatal("Restored offers should be assignable: " + err.Error())
+	}
+}
+
+// Ensures disks are matched on their source type, size and exclusivity.
+func TestDefaultResourceManager_AssignDisks(t *testing.T) {
+	t.Parallel()
+
+	disk := func(source *mesos_v1.Resource_DiskInfo_Source, size float64) *mesos_v1.Resource {
+		r := resources.CreateResource("disk", "", size)
+		if source != nil {
+			r.Disk = &mesos_v1.Resource_DiskInfo{Source: source}
+		}
+		return r
+	}
+	mount := &mesos_v1.Resource_DiskInfo_Source{
+		Type:  mesos_v1.Resource_DiskInfo_Source_MOUNT.Enum(),
+		Mount: &mesos_v1.Resource_DiskInfo_Source_Mount{Root: utils.ProtoString("/mnt/data")},
+	}
+	path := &mesos_v1.Resource_DiskInfo_Source{Type: mesos_v1.Resource_DiskInfo_Source_PATH.Enum()}
+	withDisk := func(name string, r *mesos_v1.Resource) *manager.Task {
+		task := createTask(name, 1, 128, nil)
+		task.Info.Resources = append(task.Info.Resources, r)
+		return task
+	}
+
+	d := NewDefaultResourceManager()
+	d.AddOffers([]*mesos_v1.Offer{createOffer("a", []*mesos_v1.Resource{
+		resources.CreateResource("cpus", "", 8),
+		resources.CreateResource("mem", "", 1024),
+		disk(nil, 100),
+		disk(path, 50),
+		disk(mount, 500),
+	}, nil)})
+
+	mountTask := withDisk("mount", disk(&mesos_v1.Resource_DiskInfo_Source{Type: mesos_v1.Resource_DiskInfo_Source_MOUNT.Enum()}, 200))
+	assigned, errs := d.AssignAll([]*manager.Task{
+		withDisk("root", disk(nil, 80)),
+		withDisk("path", disk(path, 50)),
+		mountTask,
+		withDisk("mount-again", disk(&mesos_v1.Resource_DiskInfo_Source{Type: mesos_v1.Resource_DiskInfo_Source_MOUNT.Enum()}, 1)),
+		withDisk("root-too-big", disk(nil, 30)),
+	})
+	if len(assigned) != 3 || len(errs) != 2 {
+		t.Fatal("Disks were not matched correctly")
+	}
+
+	claimed := mountTask.Info.Resources[2]
+	if claimed.GetScalar().GetValue() != 500 || claimed.GetDisk().GetSource().GetMount().GetRoot() != "/mnt/data" {
+		t.Fatal("MOUNT disks should be claimed whole")
+	}
+}
+
+// Ensures offers from agents going into maintenance are skipped until the maintenance is called off.
+func TestDefaultResourceManager_Drain(t *testing.T) {
+	t.Parallel()
+
+	res := []*mesos_v1.Resource{resources.CreateResource("cpus", "", 1), resources.CreateResource("mem", "", 128)}
+	soon := &mesos_v1.Unavailability{Start: &mesos_v1.TimeInfo{Nanoseconds: utils.ProtoInt64(time.Now().Add(time.Minute).UnixNano())}}
+
+	d := NewDefaultResourceManager()
+	offered := createOffer("a", res, nil)
+	offered.Unavailability = soon
+	d.AddOffers([]*mesos_v1.Offer{offered})
+	if _, err := d.Assign(createTask("a", 1, 128, nil)); err != nil {
+		t.Fatal("Offers should only drain once maintenance is within the drain horizon")
+	}
+
+	d.SetDrainHorizon(time.Hour)
+	d.AddOffers([]*mesos_v1.Offer{createOffer("b", res, nil)})
+	d.Drain(&mesos_v1.AgentID{Value: utils.ProtoString("b-agent")}, soon)
+	if _, err := d.Assign(createTask("b", 1, 128, nil)); err == nil {
+		t.Fatal("Offers from draining agents should not be assigned")
+	}
+
+	d.Undrain(&mesos_v1.AgentID{Value: utils.ProtoString("b-agent")})
+	if _, err := d.Assign(createTask("b", 1, 128, nil)); err != nil {
+		t.Fatal("Offers should be assignable again once an agent stops draining")
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"errors"
+	"github.com/golang/protobuf/proto"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/task/manager"
+	"sort"
+	"strconv"
+)
+
+// Describes where a task would be placed and what it would consume.
+type Placement struct {
+	Task      *manager.Task
+	Offer     *mesos_v1.Offer
+	Resources []*mesos_v1.Resource
+}
+
+// Hides the PlacementObserver implementation of a strategy so that planning doesn't alter its state.
+type rankOnly struct {
+	strategy OfferSelectionStrategy
+}
+
+func (r rankOnly) Rank(task *manager.Task, offers []*MesosOfferResources) []*MesosOfferResources {
+	return r.strategy.Rank(task, offers)
+}
+
+// Computes where a batch of tasks would be placed against the current offers, the same way AssignAll would,
+// without altering the manager's state or the tasks.
+// Placements are returned for every task that fits, along with an error if any of them don't.
+func (d *DefaultResourceManager) Plan(tasks []*manager.Task) ([]Placement, error) {
+	d.lock.RLock()
+	scratch := d.scratch()
+	d.lock.RUnlock()
+
+	sorted := make([]*manager.Task, len(tasks))
+	copy(sorted, tasks)
+	sort.Stable(sort.Reverse(byRequestedResources(sorted)))
+
+	var placements []Placement
+	var failed []error
+	for _, task := range sorted {
+		// Work on a copy since assigning a task rewrites its resources.
+		clone := proto.Clone(task.Info).(*mesos_v1.TaskInfo)
+		planned := manager.NewTask(clone, task.State, task.Filters, task.Retry, task.Instances, task.GroupInfo)
+		planned.Strategy = task.Strategy
+		allocation, err := scratch.assign(planned, false)
+		if err != nil {
+			failed = append(failed, err)
+			continue
+		}
+
+		placements = append(placements, Placement{
+			Task:      task,
+			Offer:     allocation.Offer,
+			Resources: allocation.Resources,
+		})
+	}
+
+	if len(failed) > 0 {
+		return placements, errors.New(strconv.Itoa(len(failed)) + " of " + strconv.Itoa(len(tasks)) +
+			" tasks cannot be placed: " + failed[0].Error())
+	}
+
+	return placements, nil
+}
+
+// Makes a throwaway copy of the manager that tasks can be assigned against.
+// Offers are staged copies, the underlying mesos offers and resources are shared.
+// Quotas are copied so that planned tasks use them up without charging the real groups.
+func (d *DefaultResourceManager) scratch() *DefaultResourceManager {
+	s := NewDefaultResourceManager()
+	s.role = d.role
+	s.principal = d.principal
+	s.strategy = rankOnly{d.strategy}
+	s.drainHorizon = d.drainHorizon
+
+	for _, offer := range d.offers {
+		s.offers = append(s.offers, offer.copy())
+	}
+	for agent, tasks := range d.placements {
+		s.placements[agent] = make(map[string]struct{}, len(tasks))
+		for name := range tasks {
+			s.placements[agent][name] = struct{}{}
+		}
+	}
+	for expr, e := range d.constraints {
+		s.constraints[expr] = e
+	}
+	for agent, u := range d.maintenance {
+		s.maintenance[agent] = u
+	}
+	for group, q := range d.quotas {
+		quota := *q
+		s.quotas[group] = &quota
+	}
+	for name, r := range d.reservations {
+		reservation := *r
+		s.reservations[name] = &reservation
+	}
+	for agent, volumes := range d.volumes {
+		s.volumes[agent] = make(map[string]*mesos_v1.Resource, len(volumes))
+		for id, volume := range volumes {
+			s.volumes[agent][id] = volume
+		}
+	}
+
+	return s
+}
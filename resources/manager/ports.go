@@ -0,0 +1,82 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+)
+
+// Makes a deep copy of a list of ranges.
+func copyRanges(ranges []*mesos_v1.Value_Range) []*mesos_v1.Value_Range {
+	c := make([]*mesos_v1.Value_Range, 0, len(ranges))
+	for _, r := range ranges {
+		c = append(c, &mesos_v1.Value_Range{
+			Begin: utils.ProtoUint64(r.GetBegin()),
+			End:   utils.ProtoUint64(r.GetEnd()),
+		})
+	}
+
+	return c
+}
+
+// Counts the total number of ports covered by a list of ranges.
+func countPorts(ranges []*mesos_v1.Value_Range) uint64 {
+	var total uint64
+	for _, r := range ranges {
+		if r.GetEnd() >= r.GetBegin() {
+			total += r.GetEnd() - r.GetBegin() + 1
+		}
+	}
+
+	return total
+}
+
+// Removes a single range from a list of ranges.
+// The range must be fully contained within one of the available ranges, otherwise false is returned
+// and the original list is left untouched.
+func subtractRange(ranges []*mesos_v1.Value_Range, sub *mesos_v1.Value_Range) ([]*mesos_v1.Value_Range, bool) {
+	begin, end := sub.GetBegin(), sub.GetEnd()
+	if begin > end {
+		return ranges, false
+	}
+
+	for i, r := range ranges {
+		if begin < r.GetBegin() || end > r.GetEnd() {
+			continue
+		}
+
+		// Split the containing range into whatever is left on either side of the requested range.
+		result := make([]*mesos_v1.Value_Range, 0, len(ranges)+1)
+		result = append(result, ranges[:i]...)
+		if begin > r.GetBegin() {
+			result = append(result, &mesos_v1.Value_Range{
+				Begin: utils.ProtoUint64(r.GetBegin()),
+				End:   utils.ProtoUint64(begin - 1),
+			})
+		}
+		if end < r.GetEnd() {
+			result = append(result, &mesos_v1.Value_Range{
+				Begin: utils.ProtoUint64(end + 1),
+				End:   utils.ProtoUint64(r.GetEnd()),
+			})
+		}
+		result = append(result, ranges[i+1:]...)
+
+		return result, true
+	}
+
+	return ranges, false
+}
@@ -0,0 +1,101 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/resources"
+	"github.com/verizonlabs/mesos-framework-sdk/task"
+	"testing"
+)
+
+// Ensures ranges are only subtracted when they're fully contained and the rest is split around them.
+func TestSubtractRange(t *testing.T) {
+	t.Parallel()
+
+	ranges := []*mesos_v1.Value_Range{createRange(8000, 8010), createRange(9000, 9000)}
+
+	left, ok := subtractRange(ranges, createRange(8003, 8004))
+	if !ok || len(left) != 3 || countPorts(left) != 10 {
+		t.Fatal("Contained range should split the range around it")
+	}
+	if left[0].GetEnd() != 8002 || left[1].GetBegin() != 8005 || left[2].GetBegin() != 9000 {
+		t.Fatal("Remaining ranges are wrong")
+	}
+
+	if left, ok = subtractRange(ranges, createRange(9000, 9000)); !ok || len(left) != 1 || countPorts(left) != 11 {
+		t.Fatal("Exactly matching range should be removed entirely")
+	}
+	if left, ok = subtractRange(ranges, createRange(8005, 9000)); ok || len(left) != 2 {
+		t.Fatal("Range spanning a gap should not be subtracted")
+	}
+	if _, ok = subtractRange(ranges, createRange(8004, 8003)); ok {
+		t.Fatal("Inverted range should not be subtracted")
+	}
+	if countPorts(ranges) != 12 || countPorts(copyRanges(ranges)) != 12 {
+		t.Fatal("Original ranges should be left untouched")
+	}
+}
+
+// Measures performance of subtracting a range.
+func BenchmarkSubtractRange(b *testing.B) {
+	ranges := []*mesos_v1.Value_Range{createRange(8000, 8010), createRange(9000, 9100)}
+	sub := createRange(9050, 9060)
+	for n := 0; n < b.N; n++ {
+		subtractRange(ranges, sub)
+	}
+}
+
+// Ensures port ranges are carved out of offers and over-requests are rejected.
+func TestDefaultResourceManager_AssignPorts(t *testing.T) {
+	t.Parallel()
+
+	d := NewDefaultResourceManager()
+	d.AddOffers([]*mesos_v1.Offer{
+		createOffer("a", []*mesos_v1.Resource{
+			resources.CreateResource("cpus", "", 4),
+			resources.CreateResource("mem", "", 1024),
+			resources.CreateRangeResource("ports", "", []*mesos_v1.Value_Range{createRange(8000, 8010)}),
+		}, nil),
+	})
+
+	tsk := createTask("ports", 1, 128, []task.Filter{})
+	tsk.Info.Resources = append(tsk.Info.Resources,
+		resources.CreateRangeResource("ports", "", []*mesos_v1.Value_Range{createRange(8000, 8100)}))
+	if _, err := d.Assign(tsk); err == nil {
+		t.Fatal("Task requesting more ports than the offer provides should fail")
+	}
+
+	d.AddOffers([]*mesos_v1.Offer{
+		createOffer("a", []*mesos_v1.Resource{
+			resources.CreateResource("cpus", "", 4),
+			resources.CreateResource("mem", "", 1024),
+			resources.CreateRangeResource("ports", "", []*mesos_v1.Value_Range{createRange(8000, 8010)}),
+		}, nil),
+	})
+	offer := d.offers[0]
+	if !d.allocatePortResource(&mesos_v1.Value_Ranges{Range: []*mesos_v1.Value_Range{createRange(8003, 8004)}}, offer) {
+		t.Fatal("Contained port range should have been allocated")
+	}
+	if countPorts(offer.Ports) != 9 || len(offer.Ports) != 2 {
+		t.Fatal("Port range was not carved out of the offer correctly")
+	}
+	if countPorts(offer.ConsumedPorts) != 2 {
+		t.Fatal("Consumed ports were not tracked")
+	}
+	if d.allocatePortResource(&mesos_v1.Value_Ranges{Range: []*mesos_v1.Value_Range{createRange(8004, 8005)}}, offer) {
+		t.Fatal("Already consumed ports should not be allocated twice")
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"github.com/verizonlabs/mesos-framework-sdk/task/manager"
+	"strconv"
+)
+
+// Limits the total cpus and memory that tasks in a group can be assigned.
+// A limit of 0 means that resource is unlimited.
+type Quota struct {
+	Cpu     float64
+	Mem     float64
+	UsedCpu float64
+	UsedMem float64
+}
+
+// Returned by Assign when placing a task would take its group over quota.
+// Callers can queue the task until resources in the group are released.
+type QuotaExceededError struct {
+	Group     string
+	Resource  string
+	Requested float64
+	Used      float64
+	Limit     float64
+}
+
+func (e *QuotaExceededError) Error() string {
+	return "Task group " + e.Group + " would exceed its " + e.Resource + " quota: " +
+		strconv.FormatFloat(e.Used+e.Requested, 'f', -1, 64) + " requested of " +
+		strconv.FormatFloat(e.Limit, 'f', -1, 64)
+}
+
+// Sets the maximum total cpus and memory that tasks in a group can use.
+// Resources already in use by the group are kept.
+func (d *DefaultResourceManager) SetQuota(group string, cpu, mem float64) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	q, ok := d.quotas[group]
+	if !ok {
+		q = &Quota{}
+		d.quotas[group] = q
+	}
+	q.Cpu = cpu
+	q.Mem = mem
+}
+
+// Removes a group's quota.
+func (d *DefaultResourceManager) RemoveQuota(group string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	delete(d.quotas, group)
+}
+
+// Gets a copy of the quota for a group, if any.
+func (d *DefaultResourceManager) Quota(group string) (Quota, bool) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	q, ok := d.quotas[group]
+	if !ok {
+		return Quota{}, false
+	}
+
+	return *q, true
+}
+
+// Gives a task's resources back to its group's quota, typically once the task has reached a terminal state.
+func (d *DefaultResourceManager) ReleaseQuota(task *manager.Task) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	q, ok := d.quotas[task.GroupInfo.GroupName]
+	if !ok {
+		return
+	}
+
+	q.UsedCpu -= requestedScalar(task.Info, "cpus")
+	q.UsedMem -= requestedScalar(task.Info, "mem")
+	if q.UsedCpu < 0 {
+		q.UsedCpu = 0
+	}
+	if q.UsedMem < 0 {
+		q.UsedMem = 0
+	}
+}
+
+// Checks if the task fits within its group's quota, if the group has one.
+func (d *DefaultResourceManager) checkQuota(task *manager.Task) error {
+	group := task.GroupInfo.GroupName
+	q, ok := d.quotas[group]
+	if !ok {
+		return nil
+	}
+
+	cpu, mem := requestedScalar(task.Info, "cpus"), requestedScalar(task.Info, "mem")
+	if q.Cpu > 0 && q.UsedCpu+cpu > q.Cpu {
+		return &QuotaExceededError{Group: group, Resource: "cpus", Requested: cpu, Used: q.UsedCpu, Limit: q.Cpu}
+	}
+	if q.Mem > 0 && q.UsedMem+mem > q.Mem {
+		return &QuotaExceededError{Group: group, Resource: "mem", Requested: mem, Used: q.UsedMem, Limit: q.Mem}
+	}
+
+	return nil
+}
+
+// Charges a placed task's resources to its group's quota.
+func (d *DefaultResourceManager) useQuota(task *manager.Task) {
+	if q, ok := d.quotas[task.GroupInfo.GroupName]; ok {
+		q.UsedCpu += requestedScalar(task.Info, "cpus")
+		q.UsedMem += requestedScalar(task.Info, "mem")
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/resources"
+)
+
+// Holds resources that were dynamically reserved on an agent for a task.
+type Reservation struct {
+	Task      string
+	AgentID   string
+	Resources []*mesos_v1.Resource
+	Released  bool // Set once the task no longer needs the reservation.
+}
+
+// Records resources that are being dynamically reserved on the offer's agent for a task.
+// The returned RESERVE operation should be sent as part of the accept call for the offer.
+func (d *DefaultResourceManager) Reserve(name string, offer *mesos_v1.Offer, res []*mesos_v1.Resource) *mesos_v1.Offer_Operation {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.reservations[name] = &Reservation{
+		Task:      name,
+		AgentID:   offer.GetAgentId().GetValue(),
+		Resources: res,
+	}
+
+	return resources.ReserveOfferOperation(res)
+}
+
+// Gets the reservation held for a task, if any.
+func (d *DefaultResourceManager) Reservation(name string) (*Reservation, bool) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	r, ok := d.reservations[name]
+
+	return r, ok
+}
+
+// Returns all outstanding reservations.
+func (d *DefaultResourceManager) Reservations() []*Reservation {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	reservations := make([]*Reservation, 0, len(d.reservations))
+	for _, r := range d.reservations {
+		reservations = append(reservations, r)
+	}
+
+	return reservations
+}
+
+// Marks a task's reservation as no longer needed, typically once the task has finished.
+// The reserved resources are unreserved the next time they're offered back to us.
+func (d *DefaultResourceManager) ReleaseReservation(name string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if r, ok := d.reservations[name]; ok {
+		r.Released = true
+	}
+}
+
+// Returns UNRESERVE operations for every released reservation whose resources are contained in the offer.
+// Reservations are forgotten once their operation is handed out.
+func (d *DefaultResourceManager) UnreserveOperations(offer *mesos_v1.Offer) []*mesos_v1.Offer_Operation {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	var operations []*mesos_v1.Offer_Operation
+	for name, r := range d.reservations {
+		if !r.Released || r.AgentID != offer.GetAgentId().GetValue() || !containsReserved(offer, r.Resources) {
+			continue
+		}
+
+		operations = append(operations, resources.UnreserveOfferOperation(r.Resources))
+		delete(d.reservations, name)
+	}
+
+	return operations
+}
+
+// Check if an offer holds at least the given reserved scalar resources for the same role and principal.
+func containsReserved(offer *mesos_v1.Offer, res []*mesos_v1.Resource) bool {
+	for _, want := range res {
+		var available float64
+		for _, have := range offer.GetResources() {
+			if have.GetName() == want.GetName() &&
+				have.GetRole() == want.GetRole() &&
+				have.GetReservation() != nil &&
+				have.GetReservation().GetPrincipal() == want.GetReservation().GetPrincipal() {
+				available += have.GetScalar().GetValue()
+			}
+		}
+
+		if available < want.GetScalar().GetValue() {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,226 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"time"
+)
+
+// Names of the built in strategies in snapshots.
+const (
+	FIRST_FIT           = "first-fit"
+	BIN_PACK            = "bin-pack"
+	SPREAD              = "spread"
+	RANDOM              = "random"
+	SPREAD_BY_ATTRIBUTE = "spread-by-attribute"
+)
+
+type (
+	// A serializable view of the resource manager's state.
+	// Snapshots can be stored with the persistence layer so a restarted scheduler can rebuild its placement state.
+	Snapshot struct {
+		Offers       []SnapshotOffer                     `json:"offers"`
+		Role         string                              `json:"role"`
+		Principal    string                              `json:"principal"`
+		Strategy     *SnapshotStrategy                   `json:"strategy,omitempty"` // Nil if a custom strategy is in use.
+		Placements   map[string][]string                 `json:"placements"`
+		Reservations []*Reservation                      `json:"reservations"`
+		Volumes      map[string][]*mesos_v1.Resource     `json:"volumes"`
+		Quotas       map[string]Quota                    `json:"quotas"`
+		Maintenance  map[string]*mesos_v1.Unavailability `json:"maintenance"` // Agent ID to its scheduled maintenance, from inverse offers.
+		DrainHorizon time.Duration                       `json:"drain_horizon"`
+	}
+
+	// An offer that has not been assigned yet.
+	SnapshotOffer struct {
+		Offer    *mesos_v1.Offer `json:"offer"`
+		Received time.Time       `json:"received"`
+	}
+
+	// One of the built in strategies, along with any state it keeps.
+	SnapshotStrategy struct {
+		Name         string                    `json:"name"`
+		Attribute    string                    `json:"attribute,omitempty"`
+		Distribution map[string]map[string]int `json:"distribution,omitempty"`
+		Replicas     map[string]map[string]int `json:"replicas,omitempty"`
+		Values       map[string]string         `json:"values,omitempty"`
+	}
+)
+
+// Captures the current state of the resource manager.
+func (d *DefaultResourceManager) Snapshot() *Snapshot {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	s := &Snapshot{
+		Role:         d.role,
+		Principal:    d.principal,
+		Strategy:     snapshotStrategy(d.strategy),
+		Placements:   make(map[string][]string, len(d.placements)),
+		Volumes:      make(map[string][]*mesos_v1.Resource, len(d.volumes)),
+		Quotas:       make(map[string]Quota, len(d.quotas)),
+		Maintenance:  make(map[string]*mesos_v1.Unavailability, len(d.maintenance)),
+		DrainHorizon: d.drainHorizon,
+	}
+
+	for _, offer := range d.offers {
+		if !offer.Accepted {
+			s.Offers = append(s.Offers, SnapshotOffer{Offer: offer.Offer, Received: offer.Received})
+		}
+	}
+	for agent, tasks := range d.placements {
+		for name := range tasks {
+			s.Placements[agent] = append(s.Placements[agent], name)
+		}
+	}
+	for _, r := range d.reservations {
+		reservation := *r
+		s.Reservations = append(s.Reservations, &reservation)
+	}
+	for agent, volumes := range d.volumes {
+		for _, volume := range volumes {
+			s.Volumes[agent] = append(s.Volumes[agent], volume)
+		}
+	}
+	for group, q := range d.quotas {
+		s.Quotas[group] = *q
+	}
+	for agent, u := range d.maintenance {
+		s.Maintenance[agent] = u
+	}
+
+	return s
+}
+
+// Replaces the state of the resource manager with a snapshot.
+// The current strategy is kept if the snapshot was taken with a custom strategy.
+func (d *DefaultResourceManager) Restore(s *Snapshot) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.role = s.Role
+	d.principal = s.Principal
+	if strategy := restoreStrategy(s.Strategy); strategy != nil {
+		d.strategy = strategy
+	}
+
+	d.clearOffers()
+	d.placements = make(map[string]map[string]struct{}, len(s.Placements))
+	d.reservations = make(map[string]*Reservation, len(s.Reservations))
+	d.volumes = make(map[string]map[string]*mesos_v1.Resource, len(s.Volumes))
+	d.quotas = make(map[string]*Quota, len(s.Quotas))
+	d.maintenance = make(map[string]*mesos_v1.Unavailability, len(s.Maintenance))
+	d.drainHorizon = s.DrainHorizon
+
+	// Maintenance is restored first so that offers from draining agents are marked as they're added back.
+	for agent, u := range s.Maintenance {
+		d.maintenance[agent] = u
+	}
+	for _, offer := range s.Offers {
+		d.addOffer(offer.Offer, offer.Received)
+	}
+	for agent, tasks := range s.Placements {
+		for _, name := range tasks {
+			d.addPlacement(name, &mesos_v1.AgentID{Value: utils.ProtoString(agent)})
+		}
+	}
+	for _, r := range s.Reservations {
+		reservation := *r
+		d.reservations[r.Task] = &reservation
+	}
+	for agent, volumes := range s.Volumes {
+		for _, volume := range volumes {
+			d.trackVolume(agent, volume)
+		}
+	}
+	for group, q := range s.Quotas {
+		quota := q
+		d.quotas[group] = &quota
+	}
+}
+
+func snapshotStrategy(strategy OfferSelectionStrategy) *SnapshotStrategy {
+	switch s := strategy.(type) {
+	case FirstFit:
+		return &SnapshotStrategy{Name: FIRST_FIT}
+	case BinPack:
+		return &SnapshotStrategy{Name: BIN_PACK}
+	case Spread:
+		return &SnapshotStrategy{Name: SPREAD}
+	case Random:
+		return &SnapshotStrategy{Name: RANDOM}
+	case *SpreadByAttribute:
+		s.RLock()
+		defer s.RUnlock()
+
+		return &SnapshotStrategy{
+			Name:         SPREAD_BY_ATTRIBUTE,
+			Attribute:    s.attribute,
+			Distribution: copyCounts(s.distribution),
+			Replicas:     copyCounts(s.replicas),
+			Values:       copyValues(s.values),
+		}
+	}
+
+	return nil
+}
+
+func restoreStrategy(s *SnapshotStrategy) OfferSelectionStrategy {
+	if s == nil {
+		return nil
+	}
+
+	switch s.Name {
+	case FIRST_FIT:
+		return FirstFit{}
+	case BIN_PACK:
+		return BinPack{}
+	case SPREAD:
+		return Spread{}
+	case RANDOM:
+		return Random{}
+	case SPREAD_BY_ATTRIBUTE:
+		strategy := NewSpreadByAttribute(s.Attribute)
+		strategy.distribution = copyCounts(s.Distribution)
+		strategy.replicas = copyCounts(s.Replicas)
+		strategy.values = copyValues(s.Values)
+		return strategy
+	}
+
+	return nil
+}
+
+func copyCounts(counts map[string]map[string]int) map[string]map[string]int {
+	c := make(map[string]map[string]int, len(counts))
+	for group, values := range counts {
+		c[group] = make(map[string]int, len(values))
+		for value, count := range values {
+			c[group][value] = count
+		}
+	}
+
+	return c
+}
+
+func copyValues(values map[string]string) map[string]string {
+	c := make(map[string]string, len(values))
+	for k, v := range values {
+		c[k] = v
+	}
+
+	return c
+}
@@ -0,0 +1,164 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/task/manager"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Spreads replicas of the same task group evenly across the distinct values of an agent attribute, such as a rack or zone.
+// Offers with the fewest replicas of the group on their attribute value are preferred,
+// and offers from agents without the attribute are used last.
+// Tasks that aren't in a group are spread by their name.
+type SpreadByAttribute struct {
+	attribute    string
+	distribution map[string]map[string]int // Group to the number of its replicas per attribute value.
+	replicas     map[string]map[string]int // Group to the number of its replicas per agent.
+	values       map[string]string         // Agent ID to its attribute value.
+	sync.RWMutex
+}
+
+func NewSpreadByAttribute(attribute string) *SpreadByAttribute {
+	return &SpreadByAttribute{
+		attribute:    attribute,
+		distribution: make(map[string]map[string]int),
+		replicas:     make(map[string]map[string]int),
+		values:       make(map[string]string),
+	}
+}
+
+// Gets the group a task is spread with.
+func spreadGroup(task *manager.Task) string {
+	if task.GroupInfo.InGroup || task.GroupInfo.GroupName != "" {
+		return task.GroupInfo.GroupName
+	}
+
+	return task.Info.GetName()
+}
+
+// Extracts the value of a named attribute from an offer.
+// Set attributes use their sorted items, ranges attributes are not supported.
+func attributeValue(offer *mesos_v1.Offer, name string) (string, bool) {
+	for _, attr := range offer.GetAttributes() {
+		if !strings.EqualFold(attr.GetName(), name) {
+			continue
+		}
+
+		switch attr.GetType() {
+		case mesos_v1.Value_TEXT:
+			return attr.GetText().GetValue(), true
+		case mesos_v1.Value_SCALAR:
+			return strconv.FormatFloat(attr.GetScalar().GetValue(), 'f', -1, 64), true
+		case mesos_v1.Value_SET:
+			items := append([]string(nil), attr.GetSet().GetItem()...)
+			sort.Strings(items)
+			return strings.Join(items, ","), true
+		}
+	}
+
+	return "", false
+}
+
+// Sorts offers by how many replicas of a group already run on their attribute value.
+type byReplicas struct {
+	offers []*MesosOfferResources
+	counts []int
+}
+
+func (b byReplicas) Len() int { return len(b.offers) }
+func (b byReplicas) Swap(i, j int) {
+	b.offers[i], b.offers[j] = b.offers[j], b.offers[i]
+	b.counts[i], b.counts[j] = b.counts[j], b.counts[i]
+}
+func (b byReplicas) Less(i, j int) bool { return b.counts[i] < b.counts[j] }
+
+func (s *SpreadByAttribute) Rank(task *manager.Task, offers []*MesosOfferResources) []*MesosOfferResources {
+	s.RLock()
+	defer s.RUnlock()
+
+	ranked := byReplicas{offers: copyOffers(offers), counts: make([]int, len(offers))}
+	distribution := s.distribution[spreadGroup(task)]
+	for i, offer := range ranked.offers {
+		value, ok := attributeValue(offer.Offer, s.attribute)
+		if !ok {
+			// Agents without the attribute can't help spread the group, use them last.
+			ranked.counts[i] = int(^uint(0) >> 1)
+			continue
+		}
+		ranked.counts[i] = distribution[value]
+	}
+	sort.Stable(ranked)
+
+	return ranked.offers
+}
+
+// Records a replica of the task's group on the offer's attribute value.
+func (s *SpreadByAttribute) Placed(task *manager.Task, offer *mesos_v1.Offer) {
+	value, ok := attributeValue(offer, s.attribute)
+	if !ok {
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	group, agent := spreadGroup(task), offer.GetAgentId().GetValue()
+	if _, ok := s.distribution[group]; !ok {
+		s.distribution[group] = make(map[string]int)
+		s.replicas[group] = make(map[string]int)
+	}
+	s.distribution[group][value]++
+	s.replicas[group][agent]++
+	s.values[agent] = value
+}
+
+// Forgets a replica of a group that was placed on an agent, typically once it has reached a terminal state.
+func (s *SpreadByAttribute) Release(group string, agent *mesos_v1.AgentID) {
+	s.Lock()
+	defer s.Unlock()
+
+	id := agent.GetValue()
+	if s.replicas[group][id] == 0 {
+		return
+	}
+
+	value := s.values[id]
+	s.replicas[group][id]--
+	s.distribution[group][value]--
+	if s.replicas[group][id] == 0 {
+		delete(s.replicas[group], id)
+	}
+	if s.distribution[group][value] == 0 {
+		delete(s.distribution[group], value)
+	}
+}
+
+// Reports how many replicas of a group are placed on each attribute value.
+func (s *SpreadByAttribute) Distribution(group string) map[string]int {
+	s.RLock()
+	defer s.RUnlock()
+
+	distribution := make(map[string]int, len(s.distribution[group]))
+	for value, count := range s.distribution[group] {
+		distribution[value] = count
+	}
+
+	return distribution
+}
@@ -0,0 +1,97 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/task/manager"
+	"math/rand"
+	"sort"
+)
+
+type (
+	// Ranks candidate offers for a task.
+	// Assign will use the first offer in the returned order that satisfies the task.
+	// Implementations must not modify the slice that is passed in.
+	OfferSelectionStrategy interface {
+		Rank(task *manager.Task, offers []*MesosOfferResources) []*MesosOfferResources
+	}
+
+	// Implemented by strategies that need to know where tasks ended up.
+	// Assign calls Placed after a task has been assigned to an offer.
+	PlacementObserver interface {
+		Placed(task *manager.Task, offer *mesos_v1.Offer)
+	}
+
+	// Uses offers in the order they were received.
+	FirstFit struct{}
+
+	// Prefers the offers with the least resources left so that agents are filled up before moving on to the next.
+	BinPack struct{}
+
+	// Prefers the offers with the most resources left so that tasks are spread across agents.
+	Spread struct{}
+
+	// Uses offers in a random order.
+	Random struct{}
+
+	// Sorts offers by their remaining cpus, then by their remaining memory.
+	byRemainingResources []*MesosOfferResources
+)
+
+func (b byRemainingResources) Len() int      { return len(b) }
+func (b byRemainingResources) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b byRemainingResources) Less(i, j int) bool {
+	if b[i].Cpu != b[j].Cpu {
+		return b[i].Cpu < b[j].Cpu
+	}
+
+	return b[i].Mem < b[j].Mem
+}
+
+// Returns a copy of the offers so that strategies can reorder them freely.
+func copyOffers(offers []*MesosOfferResources) []*MesosOfferResources {
+	c := make([]*MesosOfferResources, len(offers))
+	copy(c, offers)
+
+	return c
+}
+
+func (s FirstFit) Rank(task *manager.Task, offers []*MesosOfferResources) []*MesosOfferResources {
+	return copyOffers(offers)
+}
+
+func (s BinPack) Rank(task *manager.Task, offers []*MesosOfferResources) []*MesosOfferResources {
+	ranked := copyOffers(offers)
+	sort.Stable(byRemainingResources(ranked))
+
+	return ranked
+}
+
+func (s Spread) Rank(task *manager.Task, offers []*MesosOfferResources) []*MesosOfferResources {
+	ranked := copyOffers(offers)
+	sort.Stable(sort.Reverse(byRemainingResources(ranked)))
+
+	return ranked
+}
+
+func (s Random) Rank(task *manager.Task, offers []*MesosOfferResources) []*MesosOfferResources {
+	ranked := make([]*MesosOfferResources, len(offers))
+	for i, j := range rand.Perm(len(offers)) {
+		ranked[i] = offers[j]
+	}
+
+	return ranked
+}
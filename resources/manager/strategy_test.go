@@ -0,0 +1,136 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/resources"
+	"github.com/verizonlabs/mesos-framework-sdk/task"
+	"github.com/verizonlabs/mesos-framework-sdk/task/manager"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"testing"
+)
+
+// Creates offers of increasing size.
+func createSizedOffers() []*mesos_v1.Offer {
+	return []*mesos_v1.Offer{
+		createOffer("medium", []*mesos_v1.Resource{
+			resources.CreateResource("cpus", "", 2),
+			resources.CreateResource("mem", "", 512),
+		}, nil),
+		createOffer("small", []*mesos_v1.Resource{
+			resources.CreateResource("cpus", "", 1),
+			resources.CreateResource("mem", "", 256),
+		}, nil),
+		createOffer("large", []*mesos_v1.Resource{
+			resources.CreateResource("cpus", "", 4),
+			resources.CreateResource("mem", "", 1024),
+		}, nil),
+	}
+}
+
+// Ensures each strategy picks the offer it is supposed to.
+func TestOfferSelectionStrategy(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		strategy OfferSelectionStrategy
+		expected string
+	}{
+		"firstfit": {FirstFit{}, "medium"},
+		"binpack":  {BinPack{}, "small"},
+		"spread":   {Spread{}, "large"},
+	}
+
+	for name, test := range tests {
+		d := NewDefaultResourceManager()
+		d.SetStrategy(test.strategy)
+		d.AddOffers(createSizedOffers())
+
+		offer, err := d.Assign(createTask(name, 1, 128, nil))
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if offer.GetId().GetValue() != test.expected {
+			t.Fatal(name + " strategy chose " + offer.GetId().GetValue() + " instead of " + test.expected)
+		}
+		if len(d.offers) != 2 {
+			t.Fatal(name + " strategy did not remove the assigned offer")
+		}
+	}
+
+	d := NewDefaultResourceManager()
+	d.SetStrategy(Random{})
+	d.AddOffers(createSizedOffers())
+	ranked := d.strategy.Rank(nil, d.offers)
+	if len(ranked) != len(d.offers) {
+		t.Fatal("Random strategy lost offers while ranking")
+	}
+}
+
+// Measures performance of ranking offers with the bin packing strategy.
+func BenchmarkBinPack_Rank(b *testing.B) {
+	d := NewDefaultResourceManager()
+	d.AddOffers(createSizedOffers())
+	s := BinPack{}
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		s.Rank(nil, d.offers)
+	}
+}
+
+// Ensures replicas of a group are spread evenly across attribute values.
+func TestSpreadByAttribute(t *testing.T) {
+	t.Parallel()
+
+	zone := func(id, value string) *mesos_v1.Offer {
+		return createOffer(id, []*mesos_v1.Resource{
+			resources.CreateResource("cpus", "", 4),
+			resources.CreateResource("mem", "", 512),
+		}, []*mesos_v1.Attribute{{
+			Name: utils.ProtoString("zone"),
+			Type: mesos_v1.Value_TEXT.Enum(),
+			Text: &mesos_v1.Value_Text{Value: utils.ProtoString(value)},
+		}})
+	}
+	replica := func() *manager.Task {
+		r := createTask("replica", 1, 128, []task.Filter{{Type: UNLIKE_TASK, Value: []string{"none"}}})
+		r.GroupInfo = manager.GroupInfo{GroupName: "db", InGroup: true}
+		return r
+	}
+
+	s := NewSpreadByAttribute("zone")
+	d := NewDefaultResourceManager()
+	d.SetStrategy(s)
+	d.AddOffers([]*mesos_v1.Offer{zone("a", "east"), zone("b", "east"), zone("c", "west"), createOffer("d", nil, nil)})
+
+	for i := 0; i < 4; i++ {
+		if _, err := d.Assign(replica()); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+
+	distribution := s.Distribution("db")
+	if distribution["east"] != 2 || distribution["west"] != 2 {
+		t.Fatal("Replicas were not spread evenly across zones")
+	}
+
+	s.Release("db", &mesos_v1.AgentID{Value: utils.ProtoString("c-agent")})
+	s.Release("db", &mesos_v1.AgentID{Value: utils.ProtoString("missing")})
+	if s.Distribution("db")["west"] != 1 {
+		t.Fatal("Released replicas were not removed from the distribution")
+	}
+}
@@ -17,6 +17,7 @@ package test
 import (
 	"errors"
 	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	resourcemanager "github.com/verizonlabs/mesos-framework-sdk/resources/manager"
 	"github.com/verizonlabs/mesos-framework-sdk/task"
 	"github.com/verizonlabs/mesos-framework-sdk/task/manager"
 )
@@ -27,6 +28,14 @@ func (m MockResourceManager) AddOffers(offers []*mesos_v1.Offer) {
 
 }
 
+func (m MockResourceManager) AddOffersIncremental(offers []*mesos_v1.Offer) {
+
+}
+
+func (m MockResourceManager) RemoveOffer(id *mesos_v1.OfferID) {
+
+}
+
 func (m MockResourceManager) HasResources() bool {
 	return true
 }
@@ -43,6 +52,46 @@ func (m MockResourceManager) Assign(task *manager.Task) (*mesos_v1.Offer, error)
 	return &mesos_v1.Offer{}, nil
 }
 
+func (m MockResourceManager) Allocate(task *manager.Task) (*resourcemanager.Allocation, error) {
+	return &resourcemanager.Allocation{Offer: &mesos_v1.Offer{}}, nil
+}
+
+func (m MockResourceManager) AssignAll(tasks []*manager.Task) (map[*manager.Task]*mesos_v1.Offer, []error) {
+	assigned := make(map[*manager.Task]*mesos_v1.Offer, len(tasks))
+	for _, t := range tasks {
+		assigned[t] = &mesos_v1.Offer{}
+	}
+	return assigned, nil
+}
+
+func (m MockResourceManager) Plan(tasks []*manager.Task) ([]resourcemanager.Placement, error) {
+	placements := make([]resourcemanager.Placement, 0, len(tasks))
+	for _, t := range tasks {
+		placements = append(placements, resourcemanager.Placement{Task: t, Offer: &mesos_v1.Offer{}})
+	}
+	return placements, nil
+}
+
+func (m MockResourceManager) Snapshot() *resourcemanager.Snapshot {
+	return &resourcemanager.Snapshot{}
+}
+
+func (m MockResourceManager) Restore(s *resourcemanager.Snapshot) {
+
+}
+
+func (m MockResourceManager) Drain(agentId *mesos_v1.AgentID, unavailability *mesos_v1.Unavailability) {
+
+}
+
+func (m MockResourceManager) Undrain(agentId *mesos_v1.AgentID) {
+
+}
+
+func (m MockResourceManager) Draining(agentId *mesos_v1.AgentID) bool {
+	return false
+}
+
 func (m MockResourceManager) Offers() []*mesos_v1.Offer {
 	return []*mesos_v1.Offer{
 		{},
@@ -55,6 +104,14 @@ func (m MockBrokenResourceManager) AddOffers(offers []*mesos_v1.Offer) {
 
 }
 
+func (m MockBrokenResourceManager) AddOffersIncremental(offers []*mesos_v1.Offer) {
+
+}
+
+func (m MockBrokenResourceManager) RemoveOffer(id *mesos_v1.OfferID) {
+
+}
+
 func (m MockBrokenResourceManager) HasResources() bool {
 	return false
 }
@@ -71,6 +128,38 @@ func (m MockBrokenResourceManager) Assign(task *mesos_v1.TaskInfo) (*mesos_v1.Of
 	return nil, errors.New("Broken.")
 }
 
+func (m MockBrokenResourceManager) AssignAll(tasks []*manager.Task) (map[*manager.Task]*mesos_v1.Offer, []error) {
+	return nil, []error{errors.New("Broken.")}
+}
+
+func (m MockBrokenResourceManager) Allocate(task *manager.Task) (*resourcemanager.Allocation, error) {
+	return nil, errors.New("Broken.")
+}
+
+func (m MockBrokenResourceManager) Plan(tasks []*manager.Task) ([]resourcemanager.Placement, error) {
+	return nil, errors.New("Broken.")
+}
+
+func (m MockBrokenResourceManager) Snapshot() *resourcemanager.Snapshot {
+	return &resourcemanager.Snapshot{}
+}
+
+func (m MockBrokenResourceManager) Restore(s *resourcemanager.Snapshot) {
+
+}
+
+func (m MockBrokenResourceManager) Drain(agentId *mesos_v1.AgentID, unavailability *mesos_v1.Unavailability) {
+
+}
+
+func (m MockBrokenResourceManager) Undrain(agentId *mesos_v1.AgentID) {
+
+}
+
+func (m MockBrokenResourceManager) Draining(agentId *mesos_v1.AgentID) bool {
+	return true
+}
+
 func (m MockBrokenResourceManager) Offers() []*mesos_v1.Offer {
 	return []*mesos_v1.Offer{
 		{},
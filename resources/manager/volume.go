@@ -0,0 +1,89 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"errors"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/resources"
+)
+
+// Records a persistent volume that is known to exist on an agent.
+func (d *DefaultResourceManager) trackVolume(agent string, volume *mesos_v1.Resource) {
+	volumes, ok := d.volumes[agent]
+	if !ok {
+		volumes = make(map[string]*mesos_v1.Resource)
+		d.volumes[agent] = volumes
+	}
+	volumes[volume.GetDisk().GetPersistence().GetId()] = volume
+}
+
+// Records a persistent volume that is being created on the offer's agent.
+// The returned CREATE operation should be sent as part of the accept call for the offer.
+func (d *DefaultResourceManager) CreateVolume(offer *mesos_v1.Offer, volume *mesos_v1.Resource) (*mesos_v1.Offer_Operation, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if volume.GetDisk().GetPersistence().GetId() == "" {
+		return nil, errors.New("Persistent volume must have an ID")
+	}
+
+	d.trackVolume(offer.GetAgentId().GetValue(), volume)
+
+	return resources.CreateVolumeOfferOperation([]*mesos_v1.Resource{volume}), nil
+}
+
+// Forgets a persistent volume on an agent.
+// The returned DESTROY operation should be sent as part of an accept call for an offer from the same agent.
+func (d *DefaultResourceManager) DestroyVolume(agent *mesos_v1.AgentID, id string) (*mesos_v1.Offer_Operation, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	volume, ok := d.volumes[agent.GetValue()][id]
+	if !ok {
+		return nil, errors.New("No persistent volume " + id + " on agent " + agent.GetValue())
+	}
+
+	volumes := d.volumes[agent.GetValue()]
+	delete(volumes, id)
+	if len(volumes) == 0 {
+		delete(d.volumes, agent.GetValue())
+	}
+
+	return resources.DestroyVolumeOfferOperation([]*mesos_v1.Resource{volume}), nil
+}
+
+// Gets a persistent volume on an agent by its ID.
+func (d *DefaultResourceManager) Volume(agent *mesos_v1.AgentID, id string) (*mesos_v1.Resource, bool) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	volume, ok := d.volumes[agent.GetValue()][id]
+
+	return volume, ok
+}
+
+// Returns all persistent volumes known to exist on an agent.
+func (d *DefaultResourceManager) Volumes(agent *mesos_v1.AgentID) []*mesos_v1.Resource {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	volumes := make([]*mesos_v1.Resource, 0, len(d.volumes[agent.GetValue()]))
+	for _, volume := range d.volumes[agent.GetValue()] {
+		volumes = append(volumes, volume)
+	}
+
+	return volumes
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/verizonlabs/mesos-framework-sdk/task"
 	"github.com/verizonlabs/mesos-framework-sdk/utils"
 	"strings"
+	"time"
 )
 
 func CreateTaskInfo(
@@ -82,6 +83,23 @@ func CreateResource(name, role string, value float64) *mesos_v1.Resource {
 	return resource
 }
 
+// Creates a ranges resource, such as ports, with the given role.
+func CreateRangeResource(name, role string, ranges []*mesos_v1.Value_Range) *mesos_v1.Resource {
+	resource := &mesos_v1.Resource{
+		Name: utils.ProtoString(name),
+		Type: mesos_v1.Value_RANGES.Enum(),
+		Ranges: &mesos_v1.Value_Ranges{
+			Range: ranges,
+		},
+	}
+
+	if role != "" {
+		resource.Role = utils.ProtoString(role)
+	}
+
+	return resource
+}
+
 // Creates a disk based on given task.Disk struct.
 func CreateDisk(disk task.Disk, role string) (*mesos_v1.Resource, error) {
 
@@ -127,8 +145,10 @@ func CreateDisk(disk task.Disk, role string) (*mesos_v1.Resource, error) {
 			return nil, errors.New("Disk source set to Path type, but set mount field. Please set path field instead.")
 		}
 
-		d.Source.Type = mesos_v1.Resource_DiskInfo_Source_PATH.Enum()
-		d.Source.Path.Root = disk.Source.Path
+		d.Source = &mesos_v1.Resource_DiskInfo_Source{
+			Type: mesos_v1.Resource_DiskInfo_Source_PATH.Enum(),
+			Path: &mesos_v1.Resource_DiskInfo_Source_Path{Root: disk.Source.Path},
+		}
 	} else if strings.ToLower(*disk.Source.Type) == "mount" {
 		if disk.Source.Mount == nil {
 			// Mount path type given, must have Mount field set.
@@ -140,8 +160,10 @@ func CreateDisk(disk task.Disk, role string) (*mesos_v1.Resource, error) {
 			return nil, errors.New("Mount type given, but path field set. Please set mount instead.")
 		}
 
-		d.Source.Type = mesos_v1.Resource_DiskInfo_Source_MOUNT.Enum()
-		d.Source.Mount.Root = disk.Source.Mount
+		d.Source = &mesos_v1.Resource_DiskInfo_Source{
+			Type:  mesos_v1.Resource_DiskInfo_Source_MOUNT.Enum(),
+			Mount: &mesos_v1.Resource_DiskInfo_Source_Mount{Root: disk.Source.Mount},
+		}
 	}
 
 	// TODO (tim): Add in external volume capabilities.
@@ -200,6 +222,7 @@ func CreateCommandInfo(
 		Value:       cmd,
 		Arguments:   args,
 		User:        user,
+		Uris:        uris,
 		Environment: env,
 		Shell:       isShell,
 	}
@@ -220,3 +243,88 @@ func LaunchOfferOperation(taskList []*mesos_v1.TaskInfo) *mesos_v1.Offer_Operati
 		Launch: &mesos_v1.Offer_Operation_Launch{TaskInfos: taskList},
 	}
 }
+
+// Launches a group of tasks together on the same agent under a single executor.
+func LaunchGroupOfferOperation(executor *mesos_v1.ExecutorInfo, group *mesos_v1.TaskGroupInfo) *mesos_v1.Offer_Operation {
+	return &mesos_v1.Offer_Operation{
+		Type:        mesos_v1.Offer_Operation_LAUNCH_GROUP.Enum(),
+		LaunchGroup: &mesos_v1.Offer_Operation_LaunchGroup{Executor: executor, TaskGroup: group},
+	}
+}
+
+// Creates the reservation info used to dynamically reserve resources for a principal.
+func CreateReservation(principal string, labels *mesos_v1.Labels) *mesos_v1.Resource_ReservationInfo {
+	reservation := &mesos_v1.Resource_ReservationInfo{
+		Labels: labels,
+	}
+
+	if principal != "" {
+		reservation.Principal = utils.ProtoString(principal)
+	}
+
+	return reservation
+}
+
+// Creates a scalar resource that is dynamically reserved for a role by a principal.
+func CreateReservedResource(name, role, principal string, value float64) *mesos_v1.Resource {
+	resource := CreateResource(name, role, value)
+	resource.Reservation = CreateReservation(principal, nil)
+
+	return resource
+}
+
+func ReserveOfferOperation(res []*mesos_v1.Resource) *mesos_v1.Offer_Operation {
+	return &mesos_v1.Offer_Operation{
+		Type:    mesos_v1.Offer_Operation_RESERVE.Enum(),
+		Reserve: &mesos_v1.Offer_Operation_Reserve{Resources: res},
+	}
+}
+
+func UnreserveOfferOperation(res []*mesos_v1.Resource) *mesos_v1.Offer_Operation {
+	return &mesos_v1.Offer_Operation{
+		Type:      mesos_v1.Offer_Operation_UNRESERVE.Enum(),
+		Unreserve: &mesos_v1.Offer_Operation_Unreserve{Resources: res},
+	}
+}
+
+// Creates a persistent volume from disk reserved for a role.
+// The volume is mounted read-write at the container path of any task that uses it.
+func CreatePersistentVolume(id, role, principal, containerPath string, size float64) *mesos_v1.Resource {
+	resource := CreateReservedResource("disk", role, principal, size)
+	resource.Disk = &mesos_v1.Resource_DiskInfo{
+		Persistence: &mesos_v1.Resource_DiskInfo_Persistence{
+			Id: utils.ProtoString(id),
+		},
+		Volume: &mesos_v1.Volume{
+			Mode:          mesos_v1.Volume_RW.Enum(),
+			ContainerPath: utils.ProtoString(containerPath),
+		},
+	}
+
+	if principal != "" {
+		resource.Disk.Persistence.Principal = utils.ProtoString(principal)
+	}
+
+	return resource
+}
+
+func CreateVolumeOfferOperation(volumes []*mesos_v1.Resource) *mesos_v1.Offer_Operation {
+	return &mesos_v1.Offer_Operation{
+		Type:   mesos_v1.Offer_Operation_CREATE.Enum(),
+		Create: &mesos_v1.Offer_Operation_Create{Volumes: volumes},
+	}
+}
+
+func DestroyVolumeOfferOperation(volumes []*mesos_v1.Resource) *mesos_v1.Offer_Operation {
+	return &mesos_v1.Offer_Operation{
+		Type:    mesos_v1.Offer_Operation_DESTROY.Enum(),
+		Destroy: &mesos_v1.Offer_Operation_Destroy{Volumes: volumes},
+	}
+}
+
+// Creates a kill policy that gives tasks the grace period to shut down before they're forcibly killed.
+func CreateKillPolicy(grace time.Duration) *mesos_v1.KillPolicy {
+	return &mesos_v1.KillPolicy{
+		GracePeriod: &mesos_v1.DurationInfo{Nanoseconds: utils.ProtoInt64(grace.Nanoseconds())},
+	}
+}
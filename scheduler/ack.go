@@ -0,0 +1,176 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"sync"
+	"time"
+)
+
+// How many status update UUIDs, and separately how many terminal tasks, are remembered for deduplication.
+const ACK_HISTORY = 10000
+
+// Acknowledges status updates, drops duplicates and retries acknowledgements that fail.
+// Mesos delivers status updates at least once, so the same update can show up again until it's acknowledged.
+type AckManager struct {
+	scheduler Scheduler
+	seen      map[string]struct{}             // UUIDs of updates that were already handled.
+	history   []string                        // Seen UUIDs in the order they arrived, oldest first.
+	terminal  map[string]struct{}             // Tasks whose terminal state was already handled.
+	terminals []string                        // Terminal task IDs in the order they arrived, oldest first.
+	failed    map[string]*mesos_v1.TaskStatus // Updates whose acknowledgement needs to be retried, by UUID.
+	sync.Mutex
+}
+
+func NewAckManager(s Scheduler) *AckManager {
+	return &AckManager{
+		scheduler: s,
+		seen:      make(map[string]struct{}),
+		terminal:  make(map[string]struct{}),
+		failed:    make(map[string]*mesos_v1.TaskStatus),
+	}
+}
+
+func isTerminal(state mesos_v1.TaskState) bool {
+	switch state {
+	case mesos_v1.TaskState_TASK_FINISHED, mesos_v1.TaskState_TASK_FAILED, mesos_v1.TaskState_TASK_KILLED,
+		mesos_v1.TaskState_TASK_ERROR, mesos_v1.TaskState_TASK_LOST, mesos_v1.TaskState_TASK_DROPPED,
+		mesos_v1.TaskState_TASK_GONE, mesos_v1.TaskState_TASK_GONE_BY_OPERATOR:
+		return true
+	}
+
+	return false
+}
+
+// Acknowledges a status update if it carries a UUID and reports whether it should be handed to user code.
+// Updates already seen and repeated terminal states of the same task are acknowledged but not handed over again.
+// Failed acknowledgements are retried by Retry.
+func (a *AckManager) Handle(status *mesos_v1.TaskStatus) bool {
+	uuid := string(status.GetUuid())
+	taskId := status.GetTaskId().GetValue()
+
+	a.Lock()
+	deliver := true
+	if uuid != "" {
+		if _, ok := a.seen[uuid]; ok {
+			deliver = false
+		} else {
+			a.remember(uuid)
+		}
+	}
+	if deliver && isTerminal(status.GetState()) {
+		if _, ok := a.terminal[taskId]; ok {
+			deliver = false
+		} else {
+			a.rememberTerminal(taskId)
+		}
+	}
+	a.Unlock()
+
+	// Updates without a UUID aren't retried by Mesos and must not be acknowledged.
+	if uuid != "" {
+		a.acknowledge(status)
+	}
+
+	return deliver
+}
+
+// Records a UUID as seen, forgetting the oldest one once the history is full.
+func (a *AckManager) remember(uuid string) {
+	if len(a.history) >= ACK_HISTORY {
+		delete(a.seen, a.history[0])
+		a.history = a.history[1:]
+	}
+	a.seen[uuid] = struct{}{}
+	a.history = append(a.history, uuid)
+}
+
+// Records a task's terminal state as handled, forgetting the oldest task once the history is full.
+func (a *AckManager) rememberTerminal(taskId string) {
+	if len(a.terminals) >= ACK_HISTORY {
+		delete(a.terminal, a.terminals[0])
+		a.terminals = a.terminals[1:]
+	}
+	a.terminal[taskId] = struct{}{}
+	a.terminals = append(a.terminals, taskId)
+}
+
+func (a *AckManager) acknowledge(status *mesos_v1.TaskStatus) {
+	_, err := a.scheduler.Acknowledge(context.Background(), status.GetAgentId(), status.GetTaskId(), status.GetUuid())
+
+	a.Lock()
+	defer a.Unlock()
+
+	if err != nil {
+		a.failed[string(status.GetUuid())] = status
+	} else {
+		delete(a.failed, string(status.GetUuid()))
+	}
+}
+
+// Retries every acknowledgement that failed, returning how many are still failing.
+func (a *AckManager) Retry() int {
+	a.Lock()
+	failed := make([]*mesos_v1.TaskStatus, 0, len(a.failed))
+	for _, status := range a.failed {
+		failed = append(failed, status)
+	}
+	a.Unlock()
+
+	for _, status := range failed {
+		a.acknowledge(status)
+	}
+
+	a.Lock()
+	defer a.Unlock()
+
+	return len(a.failed)
+}
+
+// Retries failed acknowledgements every interval until stop is closed.
+// This blocks and is meant to be run in its own goroutine.
+func (a *AckManager) RunRetries(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			a.Retry()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Forgets the terminal state of a task, typically once it's been removed or is about to be relaunched under the same ID.
+func (a *AckManager) Forget(taskId *mesos_v1.TaskID) {
+	a.Lock()
+	defer a.Unlock()
+
+	id := taskId.GetValue()
+	if _, ok := a.terminal[id]; !ok {
+		return
+	}
+	delete(a.terminal, id)
+	for i, t := range a.terminals {
+		if t == id {
+			a.terminals = append(a.terminals[:i], a.terminals[i+1:]...)
+			break
+		}
+	}
+}
@@ -0,0 +1,546 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package calls builds scheduler calls and validates them before they're sent.
+
+Each call type has its own constructor and fields are added fluently:
+
+	call, err := calls.NewAccept().
+		WithFramework(frameworkId).
+		WithOffers(offerIds...).
+		WithLaunch(tasks...).
+		Build()
+
+Setting a field that doesn't belong to the call, leaving out a field the master requires,
+or mixing up operation types is reported by Build instead of as a 400 from the master.
+*/
+package calls
+
+import (
+	"errors"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	sched "github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_scheduler"
+	"strconv"
+)
+
+// Fluently assembles a single scheduler call.
+type CallBuilder struct {
+	call *sched.Call
+	err  error
+}
+
+func newBuilder(t sched.Call_Type) *CallBuilder {
+	return &CallBuilder{call: &sched.Call{Type: t.Enum()}}
+}
+
+func NewSubscribe() *CallBuilder {
+	return newBuilder(sched.Call_SUBSCRIBE)
+}
+
+func NewTeardown() *CallBuilder {
+	return newBuilder(sched.Call_TEARDOWN)
+}
+
+func NewAccept() *CallBuilder {
+	b := newBuilder(sched.Call_ACCEPT)
+	b.call.Accept = &sched.Call_Accept{}
+
+	return b
+}
+
+func NewDecline() *CallBuilder {
+	b := newBuilder(sched.Call_DECLINE)
+	b.call.Decline = &sched.Call_Decline{}
+
+	return b
+}
+
+func NewAcceptInverseOffers() *CallBuilder {
+	b := newBuilder(sched.Call_ACCEPT_INVERSE_OFFERS)
+	b.call.AcceptInverseOffers = &sched.Call_AcceptInverseOffers{}
+
+	return b
+}
+
+func NewDeclineInverseOffers() *CallBuilder {
+	b := newBuilder(sched.Call_DECLINE_INVERSE_OFFERS)
+	b.call.DeclineInverseOffers = &sched.Call_DeclineInverseOffers{}
+
+	return b
+}
+
+func NewRevive() *CallBuilder {
+	return newBuilder(sched.Call_REVIVE)
+}
+
+func NewSuppress() *CallBuilder {
+	return newBuilder(sched.Call_SUPPRESS)
+}
+
+func NewKill() *CallBuilder {
+	b := newBuilder(sched.Call_KILL)
+	b.call.Kill = &sched.Call_Kill{}
+
+	return b
+}
+
+func NewShutdown() *CallBuilder {
+	b := newBuilder(sched.Call_SHUTDOWN)
+	b.call.Shutdown = &sched.Call_Shutdown{}
+
+	return b
+}
+
+func NewAcknowledge() *CallBuilder {
+	b := newBuilder(sched.Call_ACKNOWLEDGE)
+	b.call.Acknowledge = &sched.Call_Acknowledge{}
+
+	return b
+}
+
+func NewReconcile() *CallBuilder {
+	b := newBuilder(sched.Call_RECONCILE)
+	b.call.Reconcile = &sched.Call_Reconcile{}
+
+	return b
+}
+
+func NewMessage() *CallBuilder {
+	b := newBuilder(sched.Call_MESSAGE)
+	b.call.Message = &sched.Call_Message{}
+
+	return b
+}
+
+func NewRequest() *CallBuilder {
+	b := newBuilder(sched.Call_REQUEST)
+	b.call.Request = &sched.Call_Request{}
+
+	return b
+}
+
+// Records the first error hit while building, which Build hands back.
+func (b *CallBuilder) fail(msg string) *CallBuilder {
+	if b.err == nil {
+		b.err = errors.New(msg)
+	}
+
+	return b
+}
+
+// Reports whether the call is one of the given types, failing the build with the field name otherwise.
+func (b *CallBuilder) allows(field string, types ...sched.Call_Type) bool {
+	for _, t := range types {
+		if b.call.GetType() == t {
+			return true
+		}
+	}
+
+	b.fail(field + " cannot be set on a " + b.call.GetType().String() + " call")
+	return false
+}
+
+// Sets the framework the call is made on behalf of. Every call except SUBSCRIBE requires it.
+func (b *CallBuilder) WithFramework(id *mesos_v1.FrameworkID) *CallBuilder {
+	b.call.FrameworkId = id
+
+	return b
+}
+
+// Sets the framework to subscribe with.
+func (b *CallBuilder) WithFrameworkInfo(info *mesos_v1.FrameworkInfo) *CallBuilder {
+	if b.allows("Framework info", sched.Call_SUBSCRIBE) {
+		b.call.Subscribe = &sched.Call_Subscribe{FrameworkInfo: info}
+		if b.call.FrameworkId == nil {
+			b.call.FrameworkId = info.GetId()
+		}
+	}
+
+	return b
+}
+
+// Adds the offers, or inverse offers, to accept or decline.
+func (b *CallBuilder) WithOffers(ids ...*mesos_v1.OfferID) *CallBuilder {
+	if !b.allows("Offers", sched.Call_ACCEPT, sched.Call_DECLINE,
+		sched.Call_ACCEPT_INVERSE_OFFERS, sched.Call_DECLINE_INVERSE_OFFERS) {
+		return b
+	}
+
+	switch b.call.GetType() {
+	case sched.Call_ACCEPT:
+		b.call.Accept.OfferIds = append(b.call.Accept.OfferIds, ids...)
+	case sched.Call_DECLINE:
+		b.call.Decline.OfferIds = append(b.call.Decline.OfferIds, ids...)
+	case sched.Call_ACCEPT_INVERSE_OFFERS:
+		b.call.AcceptInverseOffers.InverseOfferIds = append(b.call.AcceptInverseOffers.InverseOfferIds, ids...)
+	case sched.Call_DECLINE_INVERSE_OFFERS:
+		b.call.DeclineInverseOffers.InverseOfferIds = append(b.call.DeclineInverseOffers.InverseOfferIds, ids...)
+	}
+
+	return b
+}
+
+// Sets the filters applied to the remaining resources of accepted or declined offers.
+func (b *CallBuilder) WithFilters(filters *mesos_v1.Filters) *CallBuilder {
+	if !b.allows("Filters", sched.Call_ACCEPT, sched.Call_DECLINE,
+		sched.Call_ACCEPT_INVERSE_OFFERS, sched.Call_DECLINE_INVERSE_OFFERS) {
+		return b
+	}
+
+	switch b.call.GetType() {
+	case sched.Call_ACCEPT:
+		b.call.Accept.Filters = filters
+	case sched.Call_DECLINE:
+		b.call.Decline.Filters = filters
+	case sched.Call_ACCEPT_INVERSE_OFFERS:
+		b.call.AcceptInverseOffers.Filters = filters
+	case sched.Call_DECLINE_INVERSE_OFFERS:
+		b.call.DeclineInverseOffers.Filters = filters
+	}
+
+	return b
+}
+
+// Adds already assembled operations to perform on the accepted offers.
+func (b *CallBuilder) WithOperations(ops ...*mesos_v1.Offer_Operation) *CallBuilder {
+	if b.allows("Operations", sched.Call_ACCEPT) {
+		b.call.Accept.Operations = append(b.call.Accept.Operations, ops...)
+	}
+
+	return b
+}
+
+// Adds an operation launching the given tasks.
+func (b *CallBuilder) WithLaunch(tasks ...*mesos_v1.TaskInfo) *CallBuilder {
+	return b.WithOperations(&mesos_v1.Offer_Operation{
+		Type:   mesos_v1.Offer_Operation_LAUNCH.Enum(),
+		Launch: &mesos_v1.Offer_Operation_Launch{TaskInfos: tasks},
+	})
+}
+
+// Adds an operation launching a group of tasks together under the given executor.
+func (b *CallBuilder) WithLaunchGroup(executor *mesos_v1.ExecutorInfo, group *mesos_v1.TaskGroupInfo) *CallBuilder {
+	return b.WithOperations(&mesos_v1.Offer_Operation{
+		Type:        mesos_v1.Offer_Operation_LAUNCH_GROUP.Enum(),
+		LaunchGroup: &mesos_v1.Offer_Operation_LaunchGroup{Executor: executor, TaskGroup: group},
+	})
+}
+
+// Adds an operation dynamically reserving the given resources.
+func (b *CallBuilder) WithReserve(resources ...*mesos_v1.Resource) *CallBuilder {
+	return b.WithOperations(&mesos_v1.Offer_Operation{
+		Type:    mesos_v1.Offer_Operation_RESERVE.Enum(),
+		Reserve: &mesos_v1.Offer_Operation_Reserve{Resources: resources},
+	})
+}
+
+// Adds an operation releasing the given dynamically reserved resources.
+func (b *CallBuilder) WithUnreserve(resources ...*mesos_v1.Resource) *CallBuilder {
+	return b.WithOperations(&mesos_v1.Offer_Operation{
+		Type:      mesos_v1.Offer_Operation_UNRESERVE.Enum(),
+		Unreserve: &mesos_v1.Offer_Operation_Unreserve{Resources: resources},
+	})
+}
+
+// Adds an operation creating the given persistent volumes.
+func (b *CallBuilder) WithCreate(volumes ...*mesos_v1.Resource) *CallBuilder {
+	return b.WithOperations(&mesos_v1.Offer_Operation{
+		Type:   mesos_v1.Offer_Operation_CREATE.Enum(),
+		Create: &mesos_v1.Offer_Operation_Create{Volumes: volumes},
+	})
+}
+
+// Adds an operation destroying the given persistent volumes.
+func (b *CallBuilder) WithDestroy(volumes ...*mesos_v1.Resource) *CallBuilder {
+	return b.WithOperations(&mesos_v1.Offer_Operation{
+		Type:    mesos_v1.Offer_Operation_DESTROY.Enum(),
+		Destroy: &mesos_v1.Offer_Operation_Destroy{Volumes: volumes},
+	})
+}
+
+// Limits reviving or suppressing offers to the given roles.
+func (b *CallBuilder) WithRoles(roles ...string) *CallBuilder {
+	if !b.allows("Roles", sched.Call_REVIVE, sched.Call_SUPPRESS) {
+		return b
+	}
+
+	if b.call.GetType() == sched.Call_REVIVE {
+		b.call.Revive = &sched.Call_Revive{Roles: roles}
+	} else {
+		b.call.Suppress = &sched.Call_Suppress{Roles: roles}
+	}
+
+	return b
+}
+
+// Sets the task to kill or acknowledge an update for.
+func (b *CallBuilder) WithTask(id *mesos_v1.TaskID) *CallBuilder {
+	if !b.allows("Task", sched.Call_KILL, sched.Call_ACKNOWLEDGE) {
+		return b
+	}
+
+	if b.call.Kill != nil {
+		b.call.Kill.TaskId = id
+	} else {
+		b.call.Acknowledge.TaskId = id
+	}
+
+	return b
+}
+
+// Overrides the kill policy of the task being killed.
+func (b *CallBuilder) WithKillPolicy(policy *mesos_v1.KillPolicy) *CallBuilder {
+	if b.allows("Kill policy", sched.Call_KILL) {
+		b.call.Kill.KillPolicy = policy
+	}
+
+	return b
+}
+
+// Sets the agent the call is aimed at.
+func (b *CallBuilder) WithAgent(id *mesos_v1.AgentID) *CallBuilder {
+	if !b.allows("Agent", sched.Call_KILL, sched.Call_SHUTDOWN, sched.Call_ACKNOWLEDGE, sched.Call_MESSAGE) {
+		return b
+	}
+
+	switch b.call.GetType() {
+	case sched.Call_KILL:
+		b.call.Kill.AgentId = id
+	case sched.Call_SHUTDOWN:
+		b.call.Shutdown.AgentId = id
+	case sched.Call_ACKNOWLEDGE:
+		b.call.Acknowledge.AgentId = id
+	case sched.Call_MESSAGE:
+		b.call.Message.AgentId = id
+	}
+
+	return b
+}
+
+// Sets the executor to shut down or message.
+func (b *CallBuilder) WithExecutor(id *mesos_v1.ExecutorID) *CallBuilder {
+	if !b.allows("Executor", sched.Call_SHUTDOWN, sched.Call_MESSAGE) {
+		return b
+	}
+
+	if b.call.Shutdown != nil {
+		b.call.Shutdown.ExecutorId = id
+	} else {
+		b.call.Message.ExecutorId = id
+	}
+
+	return b
+}
+
+// Sets the UUID of the status update being acknowledged.
+func (b *CallBuilder) WithUUID(uuid []byte) *CallBuilder {
+	if b.allows("UUID", sched.Call_ACKNOWLEDGE) {
+		b.call.Acknowledge.Uuid = uuid
+	}
+
+	return b
+}
+
+// Sets the data sent to an executor.
+func (b *CallBuilder) WithData(data []byte) *CallBuilder {
+	if b.allows("Data", sched.Call_MESSAGE) {
+		b.call.Message.Data = data
+	}
+
+	return b
+}
+
+// Adds tasks to reconcile. Reconciling without any tasks asks for the state of all of them.
+func (b *CallBuilder) WithReconcileTasks(tasks ...*mesos_v1.TaskInfo) *CallBuilder {
+	if !b.allows("Reconcile tasks", sched.Call_RECONCILE) {
+		return b
+	}
+
+	for _, task := range tasks {
+		b.call.Reconcile.Tasks = append(b.call.Reconcile.Tasks, &sched.Call_Reconcile_Task{
+			TaskId:  task.GetTaskId(),
+			AgentId: task.GetAgentId(),
+		})
+	}
+
+	return b
+}
+
+// Adds resource requests.
+func (b *CallBuilder) WithRequests(requests ...*mesos_v1.Request) *CallBuilder {
+	if b.allows("Requests", sched.Call_REQUEST) {
+		b.call.Request.Requests = append(b.call.Request.Requests, requests...)
+	}
+
+	return b
+}
+
+// Validates the call and hands it back, ready to be sent.
+func (b *CallBuilder) Build() (*sched.Call, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if err := validate(b.call); err != nil {
+		return nil, err
+	}
+
+	return b.call, nil
+}
+
+// Checks the fields the master requires for each call type.
+func validate(call *sched.Call) error {
+	if call.GetType() != sched.Call_SUBSCRIBE && call.GetFrameworkId() == nil {
+		return errors.New("Framework ID is required for " + call.GetType().String() + " calls")
+	}
+
+	switch call.GetType() {
+	case sched.Call_SUBSCRIBE:
+		info := call.GetSubscribe().GetFrameworkInfo()
+		if info == nil {
+			return errors.New("Framework info is required to subscribe")
+		}
+		if info.User == nil || info.Name == nil {
+			return errors.New("Framework info needs a user and a name to subscribe")
+		}
+	case sched.Call_ACCEPT:
+		if err := validateOfferIds(call.GetAccept().GetOfferIds()); err != nil {
+			return err
+		}
+		if err := validateFilters(call.GetAccept().GetFilters()); err != nil {
+			return err
+		}
+		for n, op := range call.GetAccept().GetOperations() {
+			if err := validateOperation(op); err != nil {
+				return errors.New("Operation " + strconv.Itoa(n) + ": " + err.Error())
+			}
+		}
+	case sched.Call_DECLINE:
+		if err := validateOfferIds(call.GetDecline().GetOfferIds()); err != nil {
+			return err
+		}
+		return validateFilters(call.GetDecline().GetFilters())
+	case sched.Call_ACCEPT_INVERSE_OFFERS:
+		if err := validateOfferIds(call.GetAcceptInverseOffers().GetInverseOfferIds()); err != nil {
+			return err
+		}
+		return validateFilters(call.GetAcceptInverseOffers().GetFilters())
+	case sched.Call_DECLINE_INVERSE_OFFERS:
+		if err := validateOfferIds(call.GetDeclineInverseOffers().GetInverseOfferIds()); err != nil {
+			return err
+		}
+		return validateFilters(call.GetDeclineInverseOffers().GetFilters())
+	case sched.Call_KILL:
+		if call.GetKill().GetTaskId() == nil {
+			return errors.New("Task is required to kill")
+		}
+	case sched.Call_SHUTDOWN:
+		if call.GetShutdown().GetExecutorId() == nil || call.GetShutdown().GetAgentId() == nil {
+			return errors.New("Executor and agent are required to shut down an executor")
+		}
+	case sched.Call_ACKNOWLEDGE:
+		ack := call.GetAcknowledge()
+		if ack.GetAgentId() == nil || ack.GetTaskId() == nil || ack.GetUuid() == nil {
+			return errors.New("Agent, task and UUID are required to acknowledge an update")
+		}
+	case sched.Call_RECONCILE:
+		for _, task := range call.GetReconcile().GetTasks() {
+			if task.GetTaskId() == nil {
+				return errors.New("Task ID is required for every task being reconciled")
+			}
+		}
+	case sched.Call_MESSAGE:
+		msg := call.GetMessage()
+		if msg.GetAgentId() == nil || msg.GetExecutorId() == nil || msg.GetData() == nil {
+			return errors.New("Agent, executor and data are required to send a message")
+		}
+	}
+
+	return nil
+}
+
+func validateOfferIds(ids []*mesos_v1.OfferID) error {
+	for _, id := range ids {
+		if id.GetValue() == "" {
+			return errors.New("Offer IDs must not be empty")
+		}
+	}
+
+	return nil
+}
+
+func validateFilters(filters *mesos_v1.Filters) error {
+	if filters != nil && filters.RefuseSeconds != nil && filters.GetRefuseSeconds() < 0 {
+		return errors.New("Filters cannot refuse offers for a negative amount of time")
+	}
+
+	return nil
+}
+
+// Makes sure an operation's type matches the one field it should have set.
+func validateOperation(op *mesos_v1.Offer_Operation) error {
+	fields := map[mesos_v1.Offer_Operation_Type]bool{
+		mesos_v1.Offer_Operation_LAUNCH:       op.Launch != nil,
+		mesos_v1.Offer_Operation_LAUNCH_GROUP: op.LaunchGroup != nil,
+		mesos_v1.Offer_Operation_RESERVE:      op.Reserve != nil,
+		mesos_v1.Offer_Operation_UNRESERVE:    op.Unreserve != nil,
+		mesos_v1.Offer_Operation_CREATE:       op.Create != nil,
+		mesos_v1.Offer_Operation_DESTROY:      op.Destroy != nil,
+	}
+
+	set := 0
+	for _, ok := range fields {
+		if ok {
+			set++
+		}
+	}
+	if set != 1 || !fields[op.GetType()] {
+		return errors.New(op.GetType().String() + " operations must set only their matching field")
+	}
+
+	switch op.GetType() {
+	case mesos_v1.Offer_Operation_LAUNCH:
+		for _, task := range op.GetLaunch().GetTaskInfos() {
+			if err := validateTask(task); err != nil {
+				return err
+			}
+		}
+	case mesos_v1.Offer_Operation_LAUNCH_GROUP:
+		group := op.GetLaunchGroup()
+		if group.GetExecutor().GetExecutorId() == nil {
+			return errors.New("LAUNCH_GROUP operations need an executor with an ID")
+		}
+		if len(group.GetTaskGroup().GetTasks()) == 0 {
+			return errors.New("LAUNCH_GROUP operations need at least one task")
+		}
+		for _, task := range group.GetTaskGroup().GetTasks() {
+			if err := validateTask(task); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateTask(task *mesos_v1.TaskInfo) error {
+	if task.Name == nil || task.GetTaskId() == nil || task.GetAgentId() == nil {
+		return errors.New("Tasks need a name, task ID and agent ID to be launched")
+	}
+	if task.GetCommand() != nil && task.GetExecutor() != nil {
+		return errors.New("Task " + task.GetName() + " cannot set both a command and an executor")
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calls
+
+import (
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	sched "github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_scheduler"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"testing"
+)
+
+var (
+	framework = &mesos_v1.FrameworkID{Value: utils.ProtoString("framework")}
+	offer     = &mesos_v1.OfferID{Value: utils.ProtoString("offer")}
+	task      = &mesos_v1.TaskInfo{
+		Name:    utils.ProtoString("task"),
+		TaskId:  &mesos_v1.TaskID{Value: utils.ProtoString("task")},
+		AgentId: &mesos_v1.AgentID{Value: utils.ProtoString("agent")},
+	}
+)
+
+// Ensures valid calls are built and invalid ones are rejected before they're sent.
+func TestCallBuilder_Build(t *testing.T) {
+	t.Parallel()
+
+	call, err := NewAccept().WithFramework(framework).WithOffers(offer).WithLaunch(task).Build()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if call.GetType() != sched.Call_ACCEPT || len(call.GetAccept().GetOperations()) != 1 ||
+		call.GetAccept().GetOfferIds()[0] != offer {
+		t.Fatal("Accept call was not built correctly")
+	}
+
+	executor := &mesos_v1.ExecutorInfo{ExecutorId: &mesos_v1.ExecutorID{Value: utils.ProtoString("executor")}}
+	group := &mesos_v1.TaskGroupInfo{Tasks: []*mesos_v1.TaskInfo{task}}
+	if _, err := NewAccept().WithFramework(framework).WithOffers(offer).WithLaunchGroup(executor, group).Build(); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	invalid := map[string]*CallBuilder{
+		"missing framework":      NewDecline().WithOffers(offer),
+		"field of another call":  NewDecline().WithFramework(framework).WithLaunch(task),
+		"empty offer ID":         NewDecline().WithFramework(framework).WithOffers(&mesos_v1.OfferID{}),
+		"negative filters":       NewDecline().WithFramework(framework).WithFilters(&mesos_v1.Filters{RefuseSeconds: utils.ProtoFloat64(-1)}),
+		"task without an agent":  NewAccept().WithFramework(framework).WithLaunch(&mesos_v1.TaskInfo{Name: utils.ProtoString("task")}),
+		"empty task group":       NewAccept().WithFramework(framework).WithLaunchGroup(executor, &mesos_v1.TaskGroupInfo{}),
+		"mismatched operation":   NewAccept().WithFramework(framework).WithOperations(&mesos_v1.Offer_Operation{Type: mesos_v1.Offer_Operation_RESERVE.Enum(), Launch: &mesos_v1.Offer_Operation_Launch{}}),
+		"subscribe without user": NewSubscribe().WithFrameworkInfo(&mesos_v1.FrameworkInfo{Name: utils.ProtoString("name")}),
+		"kill without task":      NewKill().WithFramework(framework),
+		"ack without UUID":       NewAcknowledge().WithFramework(framework).WithTask(task.TaskId).WithAgent(task.AgentId),
+		"message without data":   NewMessage().WithFramework(framework).WithAgent(task.AgentId).WithExecutor(executor.ExecutorId),
+	}
+	for name, b := range invalid {
+		if _, err := b.Build(); err == nil {
+			t.Fatal("Call should have been rejected: " + name)
+		}
+	}
+}
+
+// Measures performance of building an accept call.
+func BenchmarkCallBuilder_Build(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		NewAccept().WithFramework(framework).WithOffers(offer).WithLaunch(task).Build()
+	}
+}
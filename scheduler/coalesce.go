@@ -0,0 +1,135 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"sync"
+	"time"
+)
+
+// Operations waiting to be sent in a single Accept for one agent.
+type batch struct {
+	offers     []*mesos_v1.OfferID
+	seen       map[string]bool
+	operations []*mesos_v1.Offer_Operation
+	filters    *mesos_v1.Filters
+	waiters    []chan error
+	timer      *time.Timer
+}
+
+// Groups operations on the offers of the same agent that arrive within a short window into a single Accept.
+// This cuts down on calls to the master and keeps operations from racing each other for the same offer,
+// which would otherwise see every Accept after the first one fail because the offer was already used.
+type AcceptCoalescer struct {
+	scheduler Scheduler
+	window    time.Duration
+	pending   map[string]*batch
+	sync.Mutex
+}
+
+func NewAcceptCoalescer(s Scheduler, window time.Duration) *AcceptCoalescer {
+	return &AcceptCoalescer{
+		scheduler: s,
+		window:    window,
+		pending:   make(map[string]*batch),
+	}
+}
+
+// Queues operations on offers from the given agent, blocking until the Accept they're part of has been sent.
+// The first operations for an agent open a window, and everything queued for that agent until it closes goes out together.
+// The most recent filters win. A done context stops the wait, but the operations are still sent with the rest.
+func (a *AcceptCoalescer) Accept(ctx context.Context, agentId *mesos_v1.AgentID, offerIds []*mesos_v1.OfferID,
+	operations []*mesos_v1.Offer_Operation, filters *mesos_v1.Filters) error {
+
+	agent := agentId.GetValue()
+	done := make(chan error, 1)
+
+	a.Lock()
+	b, ok := a.pending[agent]
+	if !ok {
+		b = &batch{seen: make(map[string]bool)}
+		b.timer = time.AfterFunc(a.window, func() {
+			a.flush(agent)
+		})
+		a.pending[agent] = b
+	}
+
+	for _, id := range offerIds {
+		if !b.seen[id.GetValue()] {
+			b.seen[id.GetValue()] = true
+			b.offers = append(b.offers, id)
+		}
+	}
+	b.operations = append(b.operations, operations...)
+	if filters != nil {
+		b.filters = filters
+	}
+	b.waiters = append(b.waiters, done)
+	a.Unlock()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Sends everything that's queued without waiting for the windows to close.
+func (a *AcceptCoalescer) Flush() {
+	a.Lock()
+	agents := make([]string, 0, len(a.pending))
+	for agent := range a.pending {
+		agents = append(agents, agent)
+	}
+	a.Unlock()
+
+	for _, agent := range agents {
+		a.flush(agent)
+	}
+}
+
+// Returns the number of operations waiting to be sent.
+func (a *AcceptCoalescer) Pending() int {
+	a.Lock()
+	defer a.Unlock()
+
+	n := 0
+	for _, b := range a.pending {
+		n += len(b.operations)
+	}
+
+	return n
+}
+
+// Sends the queued operations for an agent and hands the result to everything that queued them.
+func (a *AcceptCoalescer) flush(agent string) {
+	a.Lock()
+	b, ok := a.pending[agent]
+	delete(a.pending, agent)
+	a.Unlock()
+
+	if !ok {
+		return
+	}
+	b.timer.Stop()
+
+	_, err := a.scheduler.Accept(context.Background(), b.offers, b.operations, b.filters)
+	for _, done := range b.waiters {
+		done <- err
+	}
+}
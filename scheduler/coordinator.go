@@ -0,0 +1,107 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	resourcemanager "github.com/verizonlabs/mesos-framework-sdk/resources/manager"
+	taskmanager "github.com/verizonlabs/mesos-framework-sdk/task/manager"
+	"sync"
+)
+
+// Suppresses and revives offers based on the state of the resource manager and a queue of pending tasks.
+// Offers are suppressed once there are no resources left to use and nothing waiting to be launched,
+// and revived as soon as new tasks are queued.
+type OfferCoordinator struct {
+	scheduler  Scheduler
+	resources  resourcemanager.ResourceManager
+	pending    *TaskQueue
+	suppressed bool
+	sync.Mutex
+}
+
+func NewOfferCoordinator(s Scheduler, r resourcemanager.ResourceManager) *OfferCoordinator {
+	return &OfferCoordinator{
+		scheduler: s,
+		resources: r,
+		pending:   NewTaskQueue(),
+	}
+}
+
+// Queues tasks that are waiting for offers, reviving offers if they were suppressed.
+func (o *OfferCoordinator) Queue(tasks ...*taskmanager.Task) error {
+	o.Lock()
+	defer o.Unlock()
+
+	o.pending.Push(tasks...)
+	if !o.suppressed || len(tasks) == 0 {
+		return nil
+	}
+
+	if _, err := o.scheduler.Revive(context.Background()); err != nil {
+		return err
+	}
+	o.suppressed = false
+
+	return nil
+}
+
+// Removes and returns the next pending task to try assigning, or nil if nothing is pending.
+// Tasks come out in the order of the pending queue, which is FIFO unless priorities or group weights are set on it.
+func (o *OfferCoordinator) Dequeue() *taskmanager.Task {
+	o.Lock()
+	defer o.Unlock()
+
+	return o.pending.Pop()
+}
+
+// The queue pending tasks wait in, used to set priority classes and group weights.
+func (o *OfferCoordinator) PendingQueue() *TaskQueue {
+	return o.pending
+}
+
+// Returns the number of tasks waiting for offers.
+func (o *OfferCoordinator) Pending() int {
+	o.Lock()
+	defer o.Unlock()
+
+	return o.pending.Len()
+}
+
+// Returns whether the coordinator has suppressed offers.
+func (o *OfferCoordinator) Suppressed() bool {
+	o.Lock()
+	defer o.Unlock()
+
+	return o.suppressed
+}
+
+// Suppresses offers if the resource manager has nothing left to give and no tasks are pending.
+// This should be called after each round of offers has been handled.
+func (o *OfferCoordinator) Update() error {
+	o.Lock()
+	defer o.Unlock()
+
+	if o.suppressed || o.pending.Len() > 0 || o.resources.HasResources() {
+		return nil
+	}
+
+	if _, err := o.scheduler.Suppress(context.Background()); err != nil {
+		return err
+	}
+	o.suppressed = true
+
+	return nil
+}
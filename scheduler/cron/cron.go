@@ -0,0 +1,445 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/persistence"
+	"github.com/verizonlabs/mesos-framework-sdk/task/manager"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	CRON_PREFIX     = "/cron"
+	DEFAULT_HISTORY = 10
+)
+
+var (
+	ErrJobExists   = errors.New("A job with that name is already registered.")
+	ErrJobNotFound = errors.New("There's no job with that name.")
+	ErrNoTask      = errors.New("Jobs need a task definition.")
+)
+
+// What happens when a job is due while an earlier run is still going.
+type ConcurrencyPolicy string
+
+const (
+	ALLOW   ConcurrencyPolicy = "Allow"   // Runs overlap.
+	FORBID  ConcurrencyPolicy = "Forbid"  // The new run is skipped.
+	REPLACE ConcurrencyPolicy = "Replace" // The running tasks are killed and the new run is launched.
+)
+
+// How jobs are launched and replaced.
+// Launch usually queues tasks on an offer coordinator, Kill usually hands the task to a kill manager.
+type Driver interface {
+	Launch(tasks ...*manager.Task) error
+	Kill(task *manager.Task) error
+}
+
+type Job struct {
+	Name     string
+	Schedule string
+	Task     *manager.Task // Definition every run is copied from.
+	Policy   ConcurrencyPolicy
+	schedule *Schedule
+	next     time.Time
+	active   map[string]*Run          // Runs that haven't finished, by task ID.
+	tasks    map[string]*manager.Task // Tasks of the active runs, by task ID.
+}
+
+// A single run of a job, stored in the run history.
+type Run struct {
+	Job       string `json:"job"`
+	TaskId    string `json:"taskId"`
+	Scheduled int64  `json:"scheduled"`
+	Finished  int64  `json:"finished,omitempty"`
+	State     string `json:"state"`
+}
+
+// Launches tasks on cron schedules.
+// Runs that were missed while the scheduler was down are not made up, the job waits for its next time.
+// Every status update must be passed to Update so runs are tracked and recorded.
+type Cron struct {
+	lock       sync.Mutex
+	driver     Driver
+	storage    persistence.KeyValueStore
+	prefix     string
+	jobs       map[string]*Job
+	byTask     map[string]*Job // Task ID of an active run to its job.
+	now        func() time.Time
+	stop       chan struct{}
+	wake       chan struct{}
+	MaxHistory int // How many finished runs are kept per job, 0 keeps them all.
+}
+
+func NewCron(driver Driver, storage persistence.KeyValueStore, prefix string) *Cron {
+	return &Cron{
+		driver:     driver,
+		storage:    storage,
+		prefix:     prefix,
+		jobs:       make(map[string]*Job),
+		byTask:     make(map[string]*Job),
+		now:        time.Now,
+		wake:       make(chan struct{}, 1),
+		MaxHistory: DEFAULT_HISTORY,
+	}
+}
+
+// Registers a job, it first runs at the next time its schedule matches.
+func (c *Cron) Add(job *Job) error {
+	if job.Task == nil || job.Task.Info == nil {
+		return ErrNoTask
+	}
+
+	schedule, err := Parse(job.Schedule)
+	if err != nil {
+		return err
+	}
+	if job.Policy == "" {
+		job.Policy = ALLOW
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if _, ok := c.jobs[job.Name]; ok {
+		return ErrJobExists
+	}
+	job.schedule = schedule
+	job.next = schedule.Next(c.now())
+	job.active = make(map[string]*Run)
+	job.tasks = make(map[string]*manager.Task)
+	c.jobs[job.Name] = job
+	c.notify()
+
+	return nil
+}
+
+// Unregisters a job, runs that are going keep going but are no longer tracked.
+func (c *Cron) Remove(name string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	job, ok := c.jobs[name]
+	if !ok {
+		return ErrJobNotFound
+	}
+	for id := range job.active {
+		delete(c.byTask, id)
+	}
+	delete(c.jobs, name)
+
+	return nil
+}
+
+// Gets when the job runs next, the zero time means never.
+func (c *Cron) Next(name string) (time.Time, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	job, ok := c.jobs[name]
+	if !ok {
+		return time.Time{}, ErrJobNotFound
+	}
+
+	return job.next, nil
+}
+
+// Starts launching jobs as they come due until Stop is called.
+func (c *Cron) Start() {
+	c.lock.Lock()
+	if c.stop != nil {
+		c.lock.Unlock()
+		return
+	}
+	c.stop = make(chan struct{})
+	stop := c.stop
+	c.lock.Unlock()
+
+	go func() {
+		for {
+			timer := time.NewTimer(c.wait())
+			select {
+			case <-timer.C:
+				c.Tick(c.now())
+			case <-c.wake:
+				timer.Stop()
+			case <-stop:
+				timer.Stop()
+				return
+			}
+		}
+	}()
+}
+
+func (c *Cron) Stop() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.stop != nil {
+		close(c.stop)
+		c.stop = nil
+	}
+}
+
+// Launches every job that's due at the given time.
+// Start calls this on its own, it's exported so jobs can be driven by another clock.
+func (c *Cron) Tick(now time.Time) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var errs []string
+	for _, job := range c.jobs {
+		if job.next.IsZero() || now.Before(job.next) {
+			continue
+		}
+
+		// Only the latest of the times that were missed is run.
+		scheduled := job.next
+		for next := job.schedule.Next(scheduled); !next.IsZero() && !now.Before(next); next = job.schedule.Next(next) {
+			scheduled = next
+		}
+		job.next = job.schedule.Next(now)
+		if err := c.run(job, scheduled); err != nil {
+			errs = append(errs, job.Name+": "+err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New("Failed to run jobs: " + strings.Join(errs, ", ") + ".")
+	}
+
+	return nil
+}
+
+// Tracks runs through their status updates and records them once they're done.
+// Reports whether the update belonged to a job's run.
+func (c *Cron) Update(status *mesos_v1.TaskStatus) (bool, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	id := status.GetTaskId().GetValue()
+	job, ok := c.byTask[id]
+	if !ok {
+		return false, nil
+	}
+
+	run := job.active[id]
+	run.State = status.GetState().String()
+	if !manager.IsTerminal(status.GetState()) {
+		return true, c.record(run)
+	}
+
+	run.Finished = c.now().UnixNano()
+	delete(job.active, id)
+	delete(job.tasks, id)
+	delete(c.byTask, id)
+	if err := c.record(run); err != nil {
+		return true, err
+	}
+
+	return true, c.prune(job.Name)
+}
+
+// Gets the recorded runs of a job, oldest first.
+func (c *Cron) History(name string) ([]*Run, error) {
+	runs, err := c.storage.ReadAll(c.jobKey(name))
+	if err != nil {
+		return nil, err
+	}
+
+	history := make(byScheduled, 0, len(runs))
+	for _, value := range runs {
+		run := &Run{}
+		if err := json.Unmarshal([]byte(value), run); err != nil {
+			return nil, err
+		}
+		history = append(history, run)
+	}
+	sort.Sort(history)
+
+	return history, nil
+}
+
+// Must be called with the lock held.
+func (c *Cron) run(job *Job, scheduled time.Time) error {
+	switch job.Policy {
+	case FORBID:
+		if len(job.active) > 0 {
+			return nil
+		}
+	case REPLACE:
+		for id, t := range job.tasks {
+			if err := c.driver.Kill(t); err != nil {
+				return err
+			}
+
+			// The killed run has been replaced, its final status is no longer tracked.
+			run := job.active[id]
+			run.State = mesos_v1.TaskState_TASK_KILLED.String()
+			run.Finished = c.now().UnixNano()
+			delete(job.active, id)
+			delete(job.tasks, id)
+			delete(c.byTask, id)
+			if err := c.record(run); err != nil {
+				return err
+			}
+		}
+	}
+
+	t, err := instance(job, scheduled)
+	if err != nil {
+		return err
+	}
+
+	id := t.Info.GetTaskId().GetValue()
+	run := &Run{
+		Job:       job.Name,
+		TaskId:    id,
+		Scheduled: scheduled.UnixNano(),
+		State:     mesos_v1.TaskState_TASK_STAGING.String(),
+	}
+	if err := c.create(run); err != nil {
+		return err
+	}
+	if err := c.driver.Launch(t); err != nil {
+		return err
+	}
+
+	job.active[id] = run
+	job.tasks[id] = t
+	c.byTask[id] = job
+
+	return nil
+}
+
+// Keeps the newest finished runs up to the history limit.
+func (c *Cron) prune(name string) error {
+	if c.MaxHistory <= 0 {
+		return nil
+	}
+
+	history, err := c.History(name)
+	if err != nil {
+		return err
+	}
+
+	finished := history[:0]
+	for _, run := range history {
+		if run.Finished > 0 {
+			finished = append(finished, run)
+		}
+	}
+	for i := 0; i < len(finished)-c.MaxHistory; i++ {
+		if err := c.storage.Delete(c.runKey(finished[i])); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Cron) create(run *Run) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+
+	return c.storage.Create(c.runKey(run), string(data))
+}
+
+func (c *Cron) record(run *Run) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+
+	return c.storage.Update(c.runKey(run), string(data))
+}
+
+// How long until the next job is due.
+func (c *Cron) wait() time.Duration {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := c.now()
+	wait := time.Hour
+	for _, job := range c.jobs {
+		if job.next.IsZero() {
+			continue
+		}
+		if d := job.next.Sub(now); d < wait {
+			wait = d
+		}
+	}
+	if wait < 0 {
+		wait = 0
+	}
+
+	return wait
+}
+
+// Must be called with the lock held.
+// Lets the loop know the first due job may have changed.
+func (c *Cron) notify() {
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (c *Cron) jobKey(name string) string {
+	return c.prefix + "/" + name + "/"
+}
+
+// Scheduled times are zero padded so runs sort in order under the job.
+func (c *Cron) runKey(run *Run) string {
+	return c.jobKey(run.Job) + fmt.Sprintf("%020d", run.Scheduled) + "-" + run.TaskId
+}
+
+// Copies the job's definition into a new task for a single run.
+func instance(job *Job, scheduled time.Time) (*manager.Task, error) {
+	data, err := job.Task.Encode()
+	if err != nil {
+		return nil, err
+	}
+	t, err := new(manager.Task).Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	name := job.Name + "-" + fmt.Sprint(scheduled.Unix())
+	t.Info.Name = utils.ProtoString(name)
+	t.Info.TaskId = &mesos_v1.TaskID{Value: utils.ProtoString(name + "-" + strings.ToLower(utils.UuidAsString()))}
+	t.Info.AgentId = nil
+	t.State = manager.STAGING
+	t.IsKill = false
+	t.Healthy = nil
+
+	return t, nil
+}
+
+type byScheduled []*Run
+
+func (b byScheduled) Len() int           { return len(b) }
+func (b byScheduled) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byScheduled) Less(i, j int) bool { return b[i].Scheduled < b[j].Scheduled }
@@ -0,0 +1,273 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/persistence/drivers/memory"
+	"github.com/verizonlabs/mesos-framework-sdk/task/manager"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingDriver struct {
+	launched []*manager.Task
+	killed   []string
+	sync.Mutex
+}
+
+func (r *recordingDriver) Launch(tasks ...*manager.Task) error {
+	r.Lock()
+	defer r.Unlock()
+	r.launched = append(r.launched, tasks...)
+	return nil
+}
+
+func (r *recordingDriver) Kill(t *manager.Task) error {
+	r.Lock()
+	defer r.Unlock()
+	r.killed = append(r.killed, t.Info.GetTaskId().GetValue())
+	return nil
+}
+
+func definition() *manager.Task {
+	return &manager.Task{
+		Info: &mesos_v1.TaskInfo{
+			Name:   utils.ProtoString("backup"),
+			TaskId: &mesos_v1.TaskID{Value: utils.ProtoString("backup")},
+		},
+	}
+}
+
+func status(t *manager.Task, state mesos_v1.TaskState) *mesos_v1.TaskStatus {
+	return &mesos_v1.TaskStatus{TaskId: t.Info.GetTaskId(), State: state.Enum()}
+}
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestSchedule_Next(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		expr, from, next string
+	}{
+		{"* * * * *", "2017-06-01 10:00", "2017-06-01 10:01"},
+		{"*/15 * * * *", "2017-06-01 10:07", "2017-06-01 10:15"},
+		{"0 3 * * *", "2017-06-01 10:00", "2017-06-02 03:00"},
+		{"30 9 1,15 * *", "2017-06-02 00:00", "2017-06-15 09:30"},
+		{"0 0 * * mon-fri", "2017-06-02 12:00", "2017-06-05 00:00"}, // Friday to Monday.
+		{"0 0 * * 7", "2017-06-01 00:00", "2017-06-04 00:00"},
+		{"0 0 13 * 5", "2017-06-01 00:00", "2017-06-02 00:00"}, // Either day field matches.
+		{"0 0 29 feb *", "2017-03-01 00:00", "2020-02-29 00:00"},
+		{"@monthly", "2017-12-15 00:00", "2018-01-01 00:00"},
+	}
+	for _, test := range tests {
+		s, err := Parse(test.expr)
+		if err != nil {
+			t.Fatal(test.expr + ": " + err.Error())
+		}
+		if next := s.Next(date(test.from)); !next.Equal(date(test.next)) {
+			t.Fatal(test.expr + " after " + test.from + " ran at " + next.String() + ", expected " + test.next)
+		}
+	}
+
+	s, _ := Parse("0 0 30 2 *")
+	if !s.Next(date("2017-01-01 00:00")).IsZero() {
+		t.Fatal("February 30th should never run")
+	}
+
+	for _, expr := range []string{"* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "x * * * *"} {
+		if _, err := Parse(expr); err == nil {
+			t.Fatal(expr + " should not parse")
+		}
+	}
+}
+
+func BenchmarkSchedule_Next(b *testing.B) {
+	s, _ := Parse("30 9 1,15 * mon")
+	from := date("2017-06-02 00:00")
+	for n := 0; n < b.N; n++ {
+		s.Next(from)
+	}
+}
+
+func TestCron_Tick(t *testing.T) {
+	t.Parallel()
+
+	driver := &recordingDriver{}
+	storage := memory.NewClient()
+	c := NewCron(driver, storage, CRON_PREFIX)
+	now := date("2017-06-01 10:00")
+	c.now = func() time.Time { return now }
+
+	if err := c.Add(&Job{Name: "backup", Schedule: "0 * * * *"}); err != ErrNoTask {
+		t.Fatal("Jobs without a task should be rejected")
+	}
+	if err := c.Add(&Job{Name: "backup", Schedule: "0 * *", Task: definition()}); err == nil {
+		t.Fatal("Invalid schedules should be rejected")
+	}
+	if err := c.Add(&Job{Name: "backup", Schedule: "0 * * * *", Task: definition()}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := c.Add(&Job{Name: "backup", Schedule: "0 * * * *", Task: definition()}); err != ErrJobExists {
+		t.Fatal("Duplicate jobs should be rejected")
+	}
+	if next, _ := c.Next("backup"); !next.Equal(date("2017-06-01 11:00")) {
+		t.Fatal("Job should run next on the hour, got " + next.String())
+	}
+
+	c.Tick(date("2017-06-01 10:30"))
+	if len(driver.launched) != 0 {
+		t.Fatal("Job ran before it was due")
+	}
+
+	// Missed runs aren't made up.
+	now = date("2017-06-01 13:10")
+	if err := c.Tick(now); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(driver.launched) != 1 {
+		t.Fatalf("Expected 1 run, got %d", len(driver.launched))
+	}
+	run := driver.launched[0]
+	if run.Info.GetName() == "backup" || run.Info.GetTaskId().GetValue() == "backup" {
+		t.Fatal("Runs should get their own name and task ID")
+	}
+	if next, _ := c.Next("backup"); !next.Equal(date("2017-06-01 14:00")) {
+		t.Fatal("Job should run next at 14:00, got " + next.String())
+	}
+
+	if ok, err := c.Update(status(run, mesos_v1.TaskState_TASK_RUNNING)); !ok || err != nil {
+		t.Fatal("Update should track the run")
+	}
+	now = now.Add(time.Minute)
+	if ok, err := c.Update(status(run, mesos_v1.TaskState_TASK_FINISHED)); !ok || err != nil {
+		t.Fatal("Update should track the run")
+	}
+	if ok, _ := c.Update(status(definition(), mesos_v1.TaskState_TASK_FINISHED)); ok {
+		t.Fatal("Tasks that aren't runs should be ignored")
+	}
+
+	history, err := c.History("backup")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(history) != 1 || history[0].State != "TASK_FINISHED" || history[0].Finished != now.UnixNano() ||
+		history[0].Scheduled != date("2017-06-01 13:00").UnixNano() {
+		t.Fatalf("Unexpected history %+v", history)
+	}
+}
+
+func BenchmarkCron_Tick(b *testing.B) {
+	c := NewCron(&recordingDriver{}, memory.NewClient(), CRON_PREFIX)
+	c.Add(&Job{Name: "backup", Schedule: "* * * * *", Task: definition()})
+	now := time.Now()
+	for n := 0; n < b.N; n++ {
+		now = now.Add(time.Minute)
+		c.Tick(now)
+	}
+}
+
+func TestCron_Policies(t *testing.T) {
+	t.Parallel()
+
+	for _, policy := range []ConcurrencyPolicy{ALLOW, FORBID, REPLACE} {
+		driver := &recordingDriver{}
+		c := NewCron(driver, memory.NewClient(), CRON_PREFIX)
+		now := date("2017-06-01 10:00")
+		c.now = func() time.Time { return now }
+		c.Add(&Job{Name: "backup", Schedule: "* * * * *", Task: definition(), Policy: policy})
+
+		c.Tick(date("2017-06-01 10:01"))
+		c.Update(status(driver.launched[0], mesos_v1.TaskState_TASK_RUNNING))
+		c.Tick(date("2017-06-01 10:02"))
+
+		switch policy {
+		case ALLOW:
+			if len(driver.launched) != 2 || len(driver.killed) != 0 {
+				t.Fatal("Allowed runs should overlap")
+			}
+		case FORBID:
+			if len(driver.launched) != 1 {
+				t.Fatal("Forbidden runs should be skipped")
+			}
+		case REPLACE:
+			if len(driver.launched) != 2 || len(driver.killed) != 1 ||
+				driver.killed[0] != driver.launched[0].Info.GetTaskId().GetValue() {
+				t.Fatal("Replaced runs should be killed")
+			}
+			if ok, _ := c.Update(status(driver.launched[0], mesos_v1.TaskState_TASK_KILLED)); ok {
+				t.Fatal("Replaced runs should no longer be tracked")
+			}
+		}
+	}
+}
+
+func BenchmarkCron_Policies(b *testing.B) {
+	driver := &recordingDriver{}
+	c := NewCron(driver, memory.NewClient(), CRON_PREFIX)
+	c.Add(&Job{Name: "backup", Schedule: "* * * * *", Task: definition(), Policy: REPLACE})
+	now := time.Now()
+	for n := 0; n < b.N; n++ {
+		now = now.Add(time.Minute)
+		c.Tick(now)
+	}
+}
+
+func TestCron_MaxHistory(t *testing.T) {
+	t.Parallel()
+
+	driver := &recordingDriver{}
+	c := NewCron(driver, memory.NewClient(), CRON_PREFIX)
+	c.MaxHistory = 2
+	now := date("2017-06-01 10:00")
+	c.now = func() time.Time { return now }
+	c.Add(&Job{Name: "backup", Schedule: "* * * * *", Task: definition()})
+
+	for i := 0; i < 4; i++ {
+		now = now.Add(time.Minute)
+		c.Tick(now)
+		c.Update(status(driver.launched[i], mesos_v1.TaskState_TASK_FAILED))
+	}
+
+	history, err := c.History("backup")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(history) != 2 || history[0].Scheduled != date("2017-06-01 10:03").UnixNano() {
+		t.Fatalf("Expected the 2 newest runs, got %+v", history)
+	}
+}
+
+func BenchmarkCron_MaxHistory(b *testing.B) {
+	driver := &recordingDriver{}
+	c := NewCron(driver, memory.NewClient(), CRON_PREFIX)
+	c.MaxHistory = 2
+	c.Add(&Job{Name: "backup", Schedule: "* * * * *", Task: definition()})
+	now := time.Now()
+	for n := 0; n < b.N; n++ {
+		now = now.Add(time.Minute)
+		c.Tick(now)
+		c.Update(status(driver.launched[n], mesos_v1.TaskState_TASK_FINISHED))
+	}
+}
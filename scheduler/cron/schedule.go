@@ -0,0 +1,194 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A parsed cron expression with the standard five fields: minute, hour, day of month, month and day of week.
+// Fields take *, single values, ranges, lists and steps such as */15 or 1-5, months and days can also be named.
+// When both day fields are restricted a day matching either one runs, like cron does.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	domAll, dowAll                bool
+}
+
+var shortcuts = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	months = map[string]int{"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12}
+	days = map[string]int{"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6}
+)
+
+// How far ahead we look for the next run before giving up on expressions that never match, such as February 30th.
+const MAX_SEARCH_YEARS = 5
+
+func Parse(expr string) (*Schedule, error) {
+	if s, ok := shortcuts[strings.ToLower(strings.TrimSpace(expr))]; ok {
+		expr = s
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, errors.New("Cron expressions need 5 fields, got " + strconv.Itoa(len(fields)) + ".")
+	}
+
+	s := &Schedule{}
+	var err error
+	if s.minute, err = parseField(fields[0], 0, 59, nil); err != nil {
+		return nil, errors.New("Invalid minute: " + err.Error())
+	}
+	if s.hour, err = parseField(fields[1], 0, 23, nil); err != nil {
+		return nil, errors.New("Invalid hour: " + err.Error())
+	}
+	if s.dom, err = parseField(fields[2], 1, 31, nil); err != nil {
+		return nil, errors.New("Invalid day of month: " + err.Error())
+	}
+	if s.month, err = parseField(fields[3], 1, 12, months); err != nil {
+		return nil, errors.New("Invalid month: " + err.Error())
+	}
+	if s.dow, err = parseField(fields[4], 0, 7, days); err != nil {
+		return nil, errors.New("Invalid day of week: " + err.Error())
+	}
+
+	// Both 0 and 7 are Sunday.
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domAll = fields[2] == "*" || fields[2] == "?"
+	s.dowAll = fields[4] == "*" || fields[4] == "?"
+
+	return s, nil
+}
+
+// Gets the first time after t the schedule runs, in t's location.
+// The zero time is returned if it never runs within MAX_SEARCH_YEARS.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(MAX_SEARCH_YEARS, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.day(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (s *Schedule) day(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+
+	switch {
+	case s.domAll && s.dowAll:
+		return true
+	case s.domAll:
+		return dow
+	case s.dowAll:
+		return dom
+	}
+
+	return dom || dow
+}
+
+// Turns a field into a bitset of the values it matches.
+func parseField(field string, min, max int, names map[string]int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return 0, errors.New("bad step in " + part)
+			}
+			step = n
+			part = part[:i]
+		}
+
+		start, end := min, max
+		switch {
+		case part == "*" || part == "?":
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if start, err = value(bounds[0], names); err != nil {
+				return 0, err
+			}
+			if end, err = value(bounds[1], names); err != nil {
+				return 0, err
+			}
+		default:
+			n, err := value(part, names)
+			if err != nil {
+				return 0, err
+			}
+			start = n
+			if step == 1 {
+				end = n
+			}
+		}
+
+		if start < min || end > max || start > end {
+			return 0, errors.New(part + " is outside of " + strconv.Itoa(min) + "-" + strconv.Itoa(max))
+		}
+		for i := start; i <= end; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+
+	return bits, nil
+}
+
+func value(s string, names map[string]int) (int, error) {
+	if n, ok := names[strings.ToLower(s)]; ok {
+		return n, nil
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errors.New(s + " is not a number")
+	}
+
+	return n, nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"sync"
+)
+
+// Reasons an offer can be declined.
+type DeclineReason uint8
+
+const (
+	DECLINE_NO_MATCH DeclineReason = iota // Offer resources did not fit any pending task.
+	DECLINE_FILTERED                      // Offer was excluded by placement filters.
+	DECLINE_IDLE                          // Framework has nothing to launch.
+)
+
+// Holds how long Mesos should refuse offers of the same resources after they are declined, per decline reason.
+type DeclinePolicy struct {
+	refuseSeconds map[DeclineReason]float64
+	sync.RWMutex
+}
+
+// Creates a policy using the Mesos default refuse_seconds for mismatched and filtered offers,
+// and a longer refusal while the framework is idle.
+func NewDeclinePolicy() *DeclinePolicy {
+	return &DeclinePolicy{
+		refuseSeconds: map[DeclineReason]float64{
+			DECLINE_NO_MATCH: mesos_v1.Default_Filters_RefuseSeconds,
+			DECLINE_FILTERED: mesos_v1.Default_Filters_RefuseSeconds,
+			DECLINE_IDLE:     120,
+		},
+	}
+}
+
+// Sets how many seconds offers should be refused for when declined for the given reason.
+func (p *DeclinePolicy) SetRefuseSeconds(reason DeclineReason, seconds float64) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.refuseSeconds[reason] = seconds
+}
+
+// Gets how many seconds offers are refused for when declined for the given reason.
+func (p *DeclinePolicy) RefuseSeconds(reason DeclineReason) float64 {
+	p.RLock()
+	defer p.RUnlock()
+
+	if seconds, ok := p.refuseSeconds[reason]; ok {
+		return seconds
+	}
+
+	return mesos_v1.Default_Filters_RefuseSeconds
+}
+
+// Builds the filters to pass along with a decline call for the given reason.
+func (p *DeclinePolicy) Filters(reason DeclineReason) *mesos_v1.Filters {
+	return &mesos_v1.Filters{
+		RefuseSeconds: utils.ProtoFloat64(p.RefuseSeconds(reason)),
+	}
+}
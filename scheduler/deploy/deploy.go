@@ -0,0 +1,271 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"errors"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/task/manager"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNotStarted = errors.New("The deployment hasn't been started.")
+	ErrStarted    = errors.New("The deployment was already started.")
+	ErrTimeout    = errors.New("New instances didn't become healthy in time.")
+)
+
+// Returned once a deployment is rolled back, the cause is the reason it failed.
+type RollbackError struct {
+	Cause error
+}
+
+func (e *RollbackError) Error() string {
+	return "Deployment rolled back: " + e.Cause.Error()
+}
+
+// How the deployment launches and kills instances.
+// Launch usually queues tasks on an offer coordinator, Kill usually hands the task to a kill manager.
+// Neither may block on status updates since those arrive through the deployment's Update.
+type Driver interface {
+	Launch(tasks ...*manager.Task) error
+	Kill(task *manager.Task) error
+}
+
+type Config struct {
+	BatchSize  int           // How many new instances are launched at a time, defaults to 1.
+	MinHealthy int           // How many instances must stay healthy throughout, old and new combined.
+	Gated      bool          // New instances with health checks only count once they report healthy.
+	Timeout    time.Duration // How long each batch has to become healthy before rolling back, 0 waits forever.
+}
+
+type Phase string
+
+const (
+	PENDING     Phase = "Pending"
+	DEPLOYING   Phase = "Deploying"
+	SUCCEEDED   Phase = "Succeeded"
+	ROLLED_BACK Phase = "RolledBack"
+)
+
+// Replaces the instances of a task group with new ones in batches.
+// Each batch of new instances has to become healthy before the next one is launched,
+// and old instances are only killed while enough healthy instances are left to stay at the minimum.
+// If a new instance fails, turns unhealthy or a batch times out, every new instance is killed
+// and the old instances that were already replaced are launched again.
+// Every status update for the group's tasks must be passed to Update.
+type Deployment struct {
+	lock     sync.Mutex
+	driver   Driver
+	config   Config
+	phase    Phase
+	old      []*manager.Task          // Old instances still running.
+	retired  []*manager.Task          // Old instances we killed, relaunched on rollback.
+	next     []*manager.Task          // New instances that haven't been launched yet.
+	launched map[string]*manager.Task // New instances launched so far, by task ID.
+	healthy  map[string]bool          // New instances that count as healthy, by task ID.
+	batch    map[string]bool          // New instances in the current batch that aren't healthy yet.
+	timer    *time.Timer
+	done     chan error
+}
+
+// The new instances must have task IDs, they're matched to status updates by them.
+func NewDeployment(driver Driver, current, next []*manager.Task, config Config) *Deployment {
+	if config.BatchSize < 1 {
+		config.BatchSize = 1
+	}
+
+	return &Deployment{
+		driver:   driver,
+		config:   config,
+		phase:    PENDING,
+		old:      append([]*manager.Task{}, current...),
+		next:     append([]*manager.Task{}, next...),
+		launched: make(map[string]*manager.Task),
+		healthy:  make(map[string]bool),
+		batch:    make(map[string]bool),
+		done:     make(chan error, 1),
+	}
+}
+
+// Launches the first batch.
+func (d *Deployment) Start() error {
+	d.lock.Lock()
+	if d.phase != PENDING {
+		d.lock.Unlock()
+		return ErrStarted
+	}
+	d.phase = DEPLOYING
+	kill, launch := d.step()
+	d.lock.Unlock()
+
+	return d.apply(kill, launch)
+}
+
+// Moves the deployment along with a status update for one of its tasks, other updates are ignored.
+func (d *Deployment) Update(status *mesos_v1.TaskStatus) error {
+	d.lock.Lock()
+	if d.phase != DEPLOYING {
+		d.lock.Unlock()
+		return nil
+	}
+
+	id := status.GetTaskId().GetValue()
+	state := status.GetState()
+
+	// Old instances dying on their own aren't counted as healthy anymore.
+	if manager.IsTerminal(state) {
+		for i, t := range d.old {
+			if t.Info.GetTaskId().GetValue() == id {
+				d.old = append(d.old[:i], d.old[i+1:]...)
+				break
+			}
+		}
+	}
+
+	t, ok := d.launched[id]
+	if !ok {
+		kill, launch := d.step()
+		d.lock.Unlock()
+		return d.apply(kill, launch)
+	}
+
+	switch {
+	case manager.IsTerminal(state):
+		d.lock.Unlock()
+		return d.rollback(errors.New("Task " + t.Info.GetName() + " ended with " + state.String()))
+	case state == manager.RUNNING && (!d.config.Gated || t.Info.HealthCheck == nil || status.GetHealthy()):
+		d.healthy[id] = true
+		delete(d.batch, id)
+	case status.Healthy != nil && !status.GetHealthy() && d.healthy[id]:
+		d.lock.Unlock()
+		return d.rollback(errors.New("Task " + t.Info.GetName() + " became unhealthy"))
+	}
+
+	kill, launch := d.step()
+	d.lock.Unlock()
+
+	return d.apply(kill, launch)
+}
+
+// Gets the result once the deployment is over, nil if it succeeded or a RollbackError if it was rolled back.
+func (d *Deployment) Done() <-chan error {
+	return d.done
+}
+
+func (d *Deployment) Phase() Phase {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return d.phase
+}
+
+// Must be called with the lock held.
+// Works out which old instances can be killed and whether the next batch can go out.
+func (d *Deployment) step() ([]*manager.Task, []*manager.Task) {
+	if len(d.batch) > 0 {
+		return nil, nil
+	}
+	d.stopTimer()
+
+	// Once every new instance is up there's nothing left to wait on, so the rest of the old ones go at once.
+	var kill []*manager.Task
+	for len(d.old) > 0 && (len(kill) < d.config.BatchSize || len(d.next) == 0) &&
+		len(d.old)+len(d.healthy)-1 >= d.config.MinHealthy {
+		kill = append(kill, d.old[0])
+		d.retired = append(d.retired, d.old[0])
+		d.old = d.old[1:]
+	}
+
+	var launch []*manager.Task
+	for len(d.next) > 0 && len(launch) < d.config.BatchSize {
+		t := d.next[0]
+		d.next = d.next[1:]
+		id := t.Info.GetTaskId().GetValue()
+		d.launched[id] = t
+		d.batch[id] = true
+		launch = append(launch, t)
+	}
+
+	if len(launch) > 0 && d.config.Timeout > 0 {
+		d.timer = time.AfterFunc(d.config.Timeout, func() {
+			d.rollback(ErrTimeout)
+		})
+	}
+
+	if len(d.old) == 0 && len(d.next) == 0 && len(d.batch) == 0 {
+		d.phase = SUCCEEDED
+		d.done <- nil
+	}
+
+	return kill, launch
+}
+
+func (d *Deployment) apply(kill, launch []*manager.Task) error {
+	for _, t := range kill {
+		if err := d.driver.Kill(t); err != nil {
+			d.rollback(err)
+			return err
+		}
+	}
+	if len(launch) > 0 {
+		if err := d.driver.Launch(launch...); err != nil {
+			d.rollback(err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Kills every new instance and brings back the old ones that were killed.
+func (d *Deployment) rollback(cause error) error {
+	d.lock.Lock()
+	if d.phase != DEPLOYING {
+		d.lock.Unlock()
+		return nil
+	}
+	d.phase = ROLLED_BACK
+	d.stopTimer()
+
+	launched := make([]*manager.Task, 0, len(d.launched))
+	for _, t := range d.launched {
+		launched = append(launched, t)
+	}
+	retired := d.retired
+	d.lock.Unlock()
+
+	for _, t := range launched {
+		d.driver.Kill(t)
+	}
+
+	var err error
+	if len(retired) > 0 {
+		err = d.driver.Launch(retired...)
+	}
+
+	d.done <- &RollbackError{Cause: cause}
+
+	return err
+}
+
+// Must be called with the lock held.
+func (d *Deployment) stopTimer() {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/task/manager"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingDriver struct {
+	launched []string
+	killed   []string
+	sync.Mutex
+}
+
+func (r *recordingDriver) Launch(tasks ...*manager.Task) error {
+	r.Lock()
+	defer r.Unlock()
+	for _, t := range tasks {
+		r.launched = append(r.launched, t.Info.GetName())
+	}
+	return nil
+}
+
+func (r *recordingDriver) Kill(t *manager.Task) error {
+	r.Lock()
+	defer r.Unlock()
+	r.killed = append(r.killed, t.Info.GetName())
+	return nil
+}
+
+func (r *recordingDriver) counts() (int, int) {
+	r.Lock()
+	defer r.Unlock()
+	return len(r.launched), len(r.killed)
+}
+
+func instances(prefix string, n int, check bool) []*manager.Task {
+	var tasks []*manager.Task
+	for i := 0; i < n; i++ {
+		name := prefix + string('0'+rune(i))
+		info := &mesos_v1.TaskInfo{
+			Name:   utils.ProtoString(name),
+			TaskId: &mesos_v1.TaskID{Value: utils.ProtoString(name)},
+		}
+		if check {
+			info.HealthCheck = &mesos_v1.HealthCheck{}
+		}
+		tasks = append(tasks, manager.NewTask(info, manager.RUNNING, nil, nil, 1, manager.GroupInfo{}))
+	}
+	return tasks
+}
+
+func status(t *manager.Task, state mesos_v1.TaskState, healthy bool) *mesos_v1.TaskStatus {
+	return &mesos_v1.TaskStatus{TaskId: t.Info.TaskId, State: state.Enum(), Healthy: utils.ProtoBool(healthy)}
+}
+
+// Ensures instances are replaced a batch at a time without dropping below the minimum healthy count.
+func TestDeployment(t *testing.T) {
+	t.Parallel()
+
+	driver := &recordingDriver{}
+	next := instances("new", 3, true)
+	d := NewDeployment(driver, instances("old", 3, false), next, Config{BatchSize: 1, MinHealthy: 3, Gated: true})
+	if err := d.Start(); err != nil {
+		t.Fatal(err.Error())
+	}
+	if launched, killed := driver.counts(); launched != 1 || killed != 0 {
+		t.Fatal("Only the first batch should launch and no old instance should die below the minimum")
+	}
+
+	d.Update(status(next[0], manager.RUNNING, false))
+	if launched, _ := driver.counts(); launched != 1 {
+		t.Fatal("The next batch should wait for the new instance to be healthy")
+	}
+
+	for _, n := range next {
+		d.Update(status(n, manager.RUNNING, true))
+	}
+	select {
+	case err := <-d.Done():
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("The deployment should finish once every new instance is healthy")
+	}
+	if launched, killed := driver.counts(); launched != 3 || killed != 3 || d.Phase() != SUCCEEDED {
+		t.Fatal("Every old instance should be replaced")
+	}
+}
+
+// Measures a full rolling replacement.
+func BenchmarkDeployment(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		next := instances("new", 3, false)
+		d := NewDeployment(&recordingDriver{}, instances("old", 3, false), next, Config{BatchSize: 2, MinHealthy: 2})
+		d.Start()
+		for _, t := range next {
+			d.Update(status(t, manager.RUNNING, true))
+		}
+	}
+}
+
+// Ensures a failing new instance or a batch timing out rolls the deployment back.
+func TestDeployment_Rollback(t *testing.T) {
+	t.Parallel()
+
+	driver := &recordingDriver{}
+	next := instances("new", 2, false)
+	d := NewDeployment(driver, instances("old", 2, false), next, Config{BatchSize: 1, MinHealthy: 1})
+	d.Start()
+	d.Update(status(next[0], manager.RUNNING, true))
+	d.Update(status(next[1], manager.FAILED, false))
+
+	err := <-d.Done()
+	if _, ok := err.(*RollbackError); !ok || d.Phase() != ROLLED_BACK {
+		t.Fatal("A failed instance should roll the deployment back")
+	}
+	driver.Lock()
+	relaunched := driver.launched[len(driver.launched)-2:]
+	driver.Unlock()
+	if relaunched[0] != "old0" || relaunched[1] != "old1" {
+		t.Fatal("Old instances that were killed should be launched again")
+	}
+
+	d = NewDeployment(&recordingDriver{}, instances("old", 1, false), instances("new", 1, false), Config{Timeout: time.Millisecond})
+	d.Start()
+	select {
+	case err := <-d.Done():
+		if rollback, ok := err.(*RollbackError); !ok || rollback.Cause != ErrTimeout {
+			t.Fatal("Batches that never become healthy should time out")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("The batch never timed out")
+	}
+}
+
+// Measures rolling back a deployment.
+func BenchmarkDeployment_Rollback(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		next := instances("new", 1, false)
+		d := NewDeployment(&recordingDriver{}, instances("old", 1, false), next, Config{})
+		d.Start()
+		d.Update(status(next[0], manager.FAILED, false))
+	}
+}
@@ -16,6 +16,7 @@ package events
 
 import (
 	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_scheduler"
+	resourcemanager "github.com/verizonlabs/mesos-framework-sdk/resources/manager"
 	"github.com/verizonlabs/mesos-framework-sdk/task/manager"
 )
 
@@ -24,6 +25,8 @@ The events package will hook in how an end user wants to deal with events receiv
 */
 
 // Define the behavior of how an end user will deal with events.
+// Rescind should remove the rescinded offer from the resource manager with RemoveOffer
+// so that it's never assigned to a task, embedding OfferRescinder does this by default.
 type SchedulerEvent interface {
 	Subscribed(*mesos_v1_scheduler.Event_Subscribed)
 	Offers(*mesos_v1_scheduler.Event_Offers)
@@ -38,3 +41,47 @@ type SchedulerEvent interface {
 	Signals()
 	Run(*mesos_v1_scheduler.Event)
 }
+
+// Handles the events of a scheduler event stream.
+// RunEventLoop on the default scheduler dispatches events to a handler so frameworks don't need their own event switch.
+type EventHandler interface {
+	SchedulerEvent
+	Heartbeat()
+}
+
+// Removes rescinded offers from the resource manager.
+// Embed it in a SchedulerEvent implementation to get the default Rescind behavior.
+type OfferRescinder struct {
+	Resources resourcemanager.ResourceManager
+}
+
+func (o OfferRescinder) Rescind(event *mesos_v1_scheduler.Event_Rescind) {
+	o.Resources.RemoveOffer(event.GetOfferId())
+}
+
+// Calls the handler method that matches the event's type.
+// Event types the handler doesn't know about are ignored.
+func Dispatch(handler EventHandler, event *mesos_v1_scheduler.Event) {
+	switch event.GetType() {
+	case mesos_v1_scheduler.Event_SUBSCRIBED:
+		handler.Subscribed(event.GetSubscribed())
+	case mesos_v1_scheduler.Event_OFFERS:
+		handler.Offers(event.GetOffers())
+	case mesos_v1_scheduler.Event_RESCIND:
+		handler.Rescind(event.GetRescind())
+	case mesos_v1_scheduler.Event_UPDATE:
+		handler.Update(event.GetUpdate())
+	case mesos_v1_scheduler.Event_MESSAGE:
+		handler.Message(event.GetMessage())
+	case mesos_v1_scheduler.Event_FAILURE:
+		handler.Failure(event.GetFailure())
+	case mesos_v1_scheduler.Event_ERROR:
+		handler.Error(event.GetError())
+	case mesos_v1_scheduler.Event_INVERSE_OFFERS:
+		handler.InverseOffer(event.GetInverseOffers())
+	case mesos_v1_scheduler.Event_RESCIND_INVERSE_OFFER:
+		handler.RescindInverseOffer(event.GetRescindInverseOffer())
+	case mesos_v1_scheduler.Event_HEARTBEAT:
+		handler.Heartbeat()
+	}
+}
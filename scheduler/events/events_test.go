@@ -0,0 +1,167 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_scheduler"
+	resourcemanager "github.com/verizonlabs/mesos-framework-sdk/resources/manager"
+	"github.com/verizonlabs/mesos-framework-sdk/task/manager"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"testing"
+)
+
+type recordingHandler struct {
+	calls []string
+}
+
+func (r *recordingHandler) Subscribed(*mesos_v1_scheduler.Event_Subscribed) {
+	r.calls = append(r.calls, "subscribed")
+}
+
+func (r *recordingHandler) Offers(*mesos_v1_scheduler.Event_Offers) {
+	r.calls = append(r.calls, "offers")
+}
+
+func (r *recordingHandler) Rescind(*mesos_v1_scheduler.Event_Rescind) {
+	r.calls = append(r.calls, "rescind")
+}
+
+func (r *recordingHandler) Update(*mesos_v1_scheduler.Event_Update) {
+	r.calls = append(r.calls, "update")
+}
+
+func (r *recordingHandler) Message(*mesos_v1_scheduler.Event_Message) {
+	r.calls = append(r.calls, "message")
+}
+
+func (r *recordingHandler) Failure(*mesos_v1_scheduler.Event_Failure) {
+	r.calls = append(r.calls, "failure")
+}
+
+func (r *recordingHandler) Error(*mesos_v1_scheduler.Event_Error) {
+	r.calls = append(r.calls, "error")
+}
+
+func (r *recordingHandler) InverseOffer(*mesos_v1_scheduler.Event_InverseOffers) {
+	r.calls = append(r.calls, "inverse offers")
+}
+
+func (r *recordingHandler) RescindInverseOffer(*mesos_v1_scheduler.Event_RescindInverseOffer) {
+	r.calls = append(r.calls, "rescind inverse offer")
+}
+
+func (r *recordingHandler) Heartbeat() {
+	r.calls = append(r.calls, "heartbeat")
+}
+
+func (r *recordingHandler) Reschedule(*manager.Task) {}
+
+func (r *recordingHandler) Signals() {}
+
+func (r *recordingHandler) Run(*mesos_v1_scheduler.Event) {}
+
+// Ensures events are dispatched to the right handler method.
+func TestDispatch(t *testing.T) {
+	t.Parallel()
+
+	h := new(recordingHandler)
+	types := []mesos_v1_scheduler.Event_Type{
+		mesos_v1_scheduler.Event_SUBSCRIBED,
+		mesos_v1_scheduler.Event_OFFERS,
+		mesos_v1_scheduler.Event_RESCIND,
+		mesos_v1_scheduler.Event_UPDATE,
+		mesos_v1_scheduler.Event_MESSAGE,
+		mesos_v1_scheduler.Event_FAILURE,
+		mesos_v1_scheduler.Event_ERROR,
+		mesos_v1_scheduler.Event_HEARTBEAT,
+		mesos_v1_scheduler.Event_INVERSE_OFFERS,
+		mesos_v1_scheduler.Event_RESCIND_INVERSE_OFFER,
+		mesos_v1_scheduler.Event_UNKNOWN,
+	}
+	for _, typ := range types {
+		Dispatch(h, &mesos_v1_scheduler.Event{Type: typ.Enum()})
+	}
+
+	want := []string{"subscribed", "offers", "rescind", "update", "message", "failure", "error", "heartbeat", "inverse offers", "rescind inverse offer"}
+	if len(h.calls) != len(want) {
+		t.Fatal("Events were not dispatched correctly")
+	}
+	for i := range want {
+		if h.calls[i] != want[i] {
+			t.Fatal("Event dispatched to the wrong handler method")
+		}
+	}
+}
+
+// Measures performance of dispatching an event.
+func BenchmarkDispatch(b *testing.B) {
+	h := new(recordingHandler)
+	event := &mesos_v1_scheduler.Event{Type: mesos_v1_scheduler.Event_HEARTBEAT.Enum()}
+	for n := 0; n < b.N; n++ {
+		Dispatch(h, event)
+		h.calls = h.calls[:0]
+	}
+}
+
+// Records every event except rescinds, which are left to the embedded rescinder.
+type rescindingHandler struct {
+	OfferRescinder
+	recorder
+}
+
+// Nests the recording handler one level deeper so the rescinder's Rescind takes precedence.
+type recorder struct {
+	*recordingHandler
+}
+
+// Ensures rescinded offers are removed from the resource manager by default.
+func TestOfferRescinder_Rescind(t *testing.T) {
+	t.Parallel()
+
+	offer := func(id string) *mesos_v1.Offer {
+		return &mesos_v1.Offer{
+			Id:      &mesos_v1.OfferID{Value: utils.ProtoString(id)},
+			AgentId: &mesos_v1.AgentID{Value: utils.ProtoString(id + "-agent")},
+		}
+	}
+
+	r := resourcemanager.NewDefaultResourceManager()
+	r.AddOffers([]*mesos_v1.Offer{offer("a"), offer("b")})
+
+	h := &rescindingHandler{OfferRescinder{Resources: r}, recorder{new(recordingHandler)}}
+	Dispatch(h, &mesos_v1_scheduler.Event{
+		Type:    mesos_v1_scheduler.Event_RESCIND.Enum(),
+		Rescind: &mesos_v1_scheduler.Event_Rescind{OfferId: &mesos_v1.OfferID{Value: utils.ProtoString("a")}},
+	})
+
+	offers := r.Offers()
+	if len(offers) != 1 || offers[0].GetId().GetValue() != "b" {
+		t.Fatal("Rescinded offers should be removed from the resource manager")
+	}
+	if len(h.calls) != 0 {
+		t.Fatal("The embedded rescinder should handle rescinds")
+	}
+}
+
+// Measures performance of rescinding offers.
+func BenchmarkOfferRescinder_Rescind(b *testing.B) {
+	r := resourcemanager.NewDefaultResourceManager()
+	o := OfferRescinder{Resources: r}
+	event := &mesos_v1_scheduler.Event_Rescind{OfferId: &mesos_v1.OfferID{Value: utils.ProtoString("a")}}
+	for n := 0; n < b.N; n++ {
+		o.Rescind(event)
+	}
+}
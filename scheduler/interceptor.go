@@ -0,0 +1,89 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	sched "github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_scheduler"
+	"github.com/verizonlabs/mesos-framework-sdk/scheduler/events"
+	"net/http"
+)
+
+// Sends a call on to the next interceptor, the last one sends it to Mesos.
+type Invoker func(ctx context.Context, call *sched.Call) (*http.Response, error)
+
+// Wraps every call the scheduler makes, such as to log, measure, add headers or inject failures.
+// Interceptors can change the call or the response, or skip next to fail the call without sending it.
+type Interceptor func(ctx context.Context, call *sched.Call, next Invoker) (*http.Response, error)
+
+// Hands an event on to the next interceptor, the last one dispatches it to the handler.
+type EventInvoker func(event *sched.Event)
+
+// Wraps every event RunEventLoop dispatches, interceptors can drop events by not calling next.
+type EventInterceptor func(event *sched.Event, next EventInvoker)
+
+// Adds interceptors around every call, the first one added is the outermost.
+func (c *DefaultScheduler) Use(interceptors ...Interceptor) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.interceptors = append(c.interceptors, interceptors...)
+}
+
+// Adds interceptors around every event dispatched by RunEventLoop, the first one added is the outermost.
+func (c *DefaultScheduler) UseEvents(interceptors ...EventInterceptor) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.eventInterceptors = append(c.eventInterceptors, interceptors...)
+}
+
+// Sends the call through the interceptors to the client.
+func (c *DefaultScheduler) request(ctx context.Context, call *sched.Call) (*http.Response, error) {
+	c.RLock()
+	interceptors := c.interceptors
+	c.RUnlock()
+
+	invoke := Invoker(func(ctx context.Context, call *sched.Call) (*http.Response, error) {
+		return c.Client.RequestContext(ctx, call)
+	})
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoke
+		invoke = func(ctx context.Context, call *sched.Call) (*http.Response, error) {
+			return interceptor(ctx, call, next)
+		}
+	}
+
+	return invoke(ctx, call)
+}
+
+// Sends the event through the interceptors to the handler.
+func (c *DefaultScheduler) dispatch(handler events.EventHandler, event *sched.Event) {
+	c.RLock()
+	interceptors := c.eventInterceptors
+	c.RUnlock()
+
+	invoke := func(event *sched.Event) {
+		events.Dispatch(handler, event)
+	}
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], EventInvoker(invoke)
+		invoke = func(event *sched.Event) {
+			interceptor(event, next)
+		}
+	}
+
+	invoke(event)
+}
@@ -0,0 +1,134 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	res "github.com/verizonlabs/mesos-framework-sdk/resources"
+	"sync"
+	"time"
+)
+
+// Returned when a task is still alive after every kill attempt.
+type KillTimeoutError struct {
+	TaskId   string
+	Attempts int
+	Grace    time.Duration
+}
+
+func (e *KillTimeoutError) Error() string {
+	return fmt.Sprintf("Task %s did not die after %d kill attempts with a %s grace period", e.TaskId, e.Attempts, e.Grace)
+}
+
+// Tasks being killed and everyone waiting on them.
+type killWatch struct {
+	done    chan struct{}
+	waiters int
+}
+
+// Kills tasks gracefully and makes sure they actually die.
+// Status updates must be passed to Update so that kills know when their task is gone.
+type KillManager struct {
+	scheduler Scheduler
+	Slack     time.Duration // Extra time on top of the grace period to wait for the terminal update.
+	watching  map[string]*killWatch
+	sync.Mutex
+}
+
+func NewKillManager(s Scheduler) *KillManager {
+	return &KillManager{
+		scheduler: s,
+		Slack:     5 * time.Second,
+		watching:  make(map[string]*killWatch),
+	}
+}
+
+// Kills a task with the given grace period and waits for it to die, re-issuing the kill up to the given attempts.
+// This blocks until the task is gone, the context is done or a KillTimeoutError is returned.
+func (k *KillManager) Kill(ctx context.Context, taskId *mesos_v1.TaskID, agentId *mesos_v1.AgentID, grace time.Duration, attempts int) error {
+	done := k.watch(taskId.GetValue())
+	defer k.unwatch(taskId.GetValue())
+
+	policy := res.CreateKillPolicy(grace)
+	for attempt := 0; attempt < attempts; attempt++ {
+		if _, err := k.scheduler.KillWithPolicy(ctx, taskId, agentId, policy); err != nil {
+			return err
+		}
+
+		timer := time.NewTimer(grace + k.Slack)
+		select {
+		case <-done:
+			timer.Stop()
+			return nil
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return &KillTimeoutError{TaskId: taskId.GetValue(), Attempts: attempts, Grace: grace}
+}
+
+// Lets kills waiting on the task know it's gone once it reaches a terminal state.
+// Reports whether a kill was waiting on it.
+func (k *KillManager) Update(status *mesos_v1.TaskStatus) bool {
+	if !isTerminal(status.GetState()) {
+		return false
+	}
+
+	k.Lock()
+	defer k.Unlock()
+
+	w, ok := k.watching[status.GetTaskId().GetValue()]
+	if !ok {
+		return false
+	}
+
+	select {
+	case <-w.done:
+	default:
+		close(w.done)
+	}
+
+	return true
+}
+
+func (k *KillManager) watch(id string) chan struct{} {
+	k.Lock()
+	defer k.Unlock()
+
+	w, ok := k.watching[id]
+	if !ok {
+		w = &killWatch{done: make(chan struct{})}
+		k.watching[id] = w
+	}
+	w.waiters++
+
+	return w.done
+}
+
+func (k *KillManager) unwatch(id string) {
+	k.Lock()
+	defer k.Unlock()
+
+	w := k.watching[id]
+	w.waiters--
+	if w.waiters == 0 {
+		delete(k.watching, id)
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	sched "github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_scheduler"
+	"github.com/verizonlabs/mesos-framework-sdk/scheduler/events"
+)
+
+// Subscribes to Mesos and dispatches every event on the stream to the handler.
+// This blocks until the stream ends or the context is done and returns the reason it did.
+func (c *DefaultScheduler) RunEventLoop(ctx context.Context, handler events.EventHandler) error {
+	eventChan := make(chan *sched.Event)
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.Subscribe(ctx, eventChan)
+		done <- err
+	}()
+
+	for {
+		select {
+		case event := <-eventChan:
+			c.dispatch(handler, event)
+		case err := <-done:
+			return err
+		}
+	}
+}
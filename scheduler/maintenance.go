@@ -0,0 +1,116 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	sched "github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_scheduler"
+	resourcemanager "github.com/verizonlabs/mesos-framework-sdk/resources/manager"
+	taskmanager "github.com/verizonlabs/mesos-framework-sdk/task/manager"
+	"sync"
+	"time"
+)
+
+// Keeps the resource manager aware of agent maintenance learned from inverse offers,
+// and optionally moves tasks off agents before their maintenance starts.
+type MaintenanceManager struct {
+	scheduler   Scheduler
+	resources   resourcemanager.ResourceManager
+	tasks       taskmanager.TaskManager
+	Horizon     time.Duration                // Tasks are migrated once maintenance starts within this long.
+	AutoMigrate bool                         // Whether tasks are migrated at all.
+	Migrate     func(task *taskmanager.Task) // Moves a task off its agent. Tasks are killed by default so they get rescheduled elsewhere.
+	inverse     map[string]*mesos_v1.AgentID // Inverse offer ID to the agent it's for.
+	sync.Mutex
+}
+
+func NewMaintenanceManager(s Scheduler, r resourcemanager.ResourceManager, t taskmanager.TaskManager) *MaintenanceManager {
+	m := &MaintenanceManager{
+		scheduler: s,
+		resources: r,
+		tasks:     t,
+		Horizon:   time.Hour,
+		inverse:   make(map[string]*mesos_v1.AgentID),
+	}
+	m.Migrate = func(task *taskmanager.Task) {
+		m.scheduler.Kill(context.Background(), task.Info.GetTaskId(), task.Info.GetAgentId())
+	}
+
+	return m
+}
+
+// Marks the agents of inverse offers as draining.
+// With migration enabled, tasks are moved off agents whose maintenance is within the horizon
+// and the inverse offers for those agents are accepted.
+func (m *MaintenanceManager) InverseOffers(event *sched.Event_InverseOffers) error {
+	m.Lock()
+	defer m.Unlock()
+
+	var accept []*mesos_v1.OfferID
+	now := time.Now()
+	for _, offer := range event.GetInverseOffers() {
+		agent := offer.GetAgentId()
+		if agent == nil {
+			continue
+		}
+
+		m.inverse[offer.GetId().GetValue()] = agent
+		m.resources.Drain(agent, offer.GetUnavailability())
+
+		if m.AutoMigrate && resourcemanager.UnavailableWithin(offer.GetUnavailability(), now, m.Horizon) {
+			if err := m.migrate(agent); err != nil {
+				return err
+			}
+			accept = append(accept, offer.GetId())
+		}
+	}
+
+	if len(accept) == 0 {
+		return nil
+	}
+
+	_, err := m.scheduler.AcceptInverseOffers(context.Background(), accept, nil)
+	return err
+}
+
+// Stops treating the agent of a rescinded inverse offer as draining.
+func (m *MaintenanceManager) RescindInverseOffer(event *sched.Event_RescindInverseOffer) {
+	m.Lock()
+	defer m.Unlock()
+
+	id := event.GetInverseOfferId().GetValue()
+	if agent, ok := m.inverse[id]; ok {
+		m.resources.Undrain(agent)
+		delete(m.inverse, id)
+	}
+}
+
+// Moves every live task off an agent.
+func (m *MaintenanceManager) migrate(agent *mesos_v1.AgentID) error {
+	tasks, err := m.tasks.All()
+	if err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		if task.Info.GetAgentId().GetValue() != agent.GetValue() || isTerminal(task.State) || task.IsKill {
+			continue
+		}
+		m.Migrate(task)
+	}
+
+	return nil
+}
@@ -0,0 +1,191 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	taskmanager "github.com/verizonlabs/mesos-framework-sdk/task/manager"
+	"sync"
+)
+
+// Orders the tasks waiting for offers.
+// Tasks come out in FIFO order by default.
+// Tasks in higher priority classes always come out first, and once weights are set
+// each task group gets a share of the launches in proportion to its weight instead of waiting its turn.
+// Tasks outside of a group share the empty group name.
+type TaskQueue struct {
+	priorities map[string]int     // Priority class to its priority, unknown classes are 0.
+	weights    map[string]float64 // Task group to its share of launches, unknown groups weigh 1.
+	served     map[string]float64 // Task group to its virtual time, advanced by 1/weight per launch.
+	clock      float64            // Virtual time of the last launch.
+	items      []queuedTask
+	seq        uint64
+	sync.Mutex
+}
+
+type queuedTask struct {
+	task     *taskmanager.Task
+	seq      uint64
+	priority int
+	group    string
+}
+
+func NewTaskQueue() *TaskQueue {
+	return &TaskQueue{
+		priorities: make(map[string]int),
+		weights:    make(map[string]float64),
+		served:     make(map[string]float64),
+	}
+}
+
+// Sets the priority of a class, higher priorities are launched first.
+// This only applies to tasks queued afterwards.
+func (q *TaskQueue) SetPriority(class string, priority int) {
+	q.Lock()
+	defer q.Unlock()
+
+	q.priorities[class] = priority
+}
+
+// Sets the task group's share of launches relative to other groups, which turns on fair sharing.
+// Weights that aren't positive are ignored.
+func (q *TaskQueue) SetWeight(group string, weight float64) {
+	q.Lock()
+	defer q.Unlock()
+
+	if weight > 0 {
+		q.weights[group] = weight
+	}
+}
+
+func (q *TaskQueue) Push(tasks ...*taskmanager.Task) {
+	q.Lock()
+	defer q.Unlock()
+
+	for _, t := range tasks {
+		group := groupOf(t)
+
+		// Groups that had nothing queued start at the current virtual time so they can't make up for being idle.
+		if !q.queued(group) && q.served[group] < q.clock {
+			q.served[group] = q.clock
+		}
+
+		q.seq++
+		q.items = append(q.items, queuedTask{
+			task:     t,
+			seq:      q.seq,
+			priority: q.priorities[t.Priority],
+			group:    group,
+		})
+	}
+}
+
+// Removes and returns the next task to launch, or nil if nothing is queued.
+func (q *TaskQueue) Pop() *taskmanager.Task {
+	q.Lock()
+	defer q.Unlock()
+
+	i := q.next()
+	if i < 0 {
+		return nil
+	}
+
+	item := q.items[i]
+	copy(q.items[i:], q.items[i+1:])
+	q.items[len(q.items)-1] = queuedTask{}
+	q.items = q.items[:len(q.items)-1]
+
+	if len(q.weights) > 0 {
+		q.clock = q.served[item.group]
+		q.served[item.group] += 1 / q.weight(item.group)
+	}
+
+	return item.task
+}
+
+// Removes a queued task, such as one that was killed before it launched.
+// Reports whether the task was queued.
+func (q *TaskQueue) Remove(t *taskmanager.Task) bool {
+	q.Lock()
+	defer q.Unlock()
+
+	for i, item := range q.items {
+		if item.task == t {
+			q.items = append(q.items[:i], q.items[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+func (q *TaskQueue) Len() int {
+	q.Lock()
+	defer q.Unlock()
+
+	return len(q.items)
+}
+
+// Must be called with the lock held.
+// Picks the highest priority task, breaking ties by the group that's furthest behind on its share and then by age.
+func (q *TaskQueue) next() int {
+	best := -1
+	for i, item := range q.items {
+		if best < 0 || q.before(item, q.items[best]) {
+			best = i
+		}
+	}
+
+	return best
+}
+
+// Must be called with the lock held.
+func (q *TaskQueue) before(a, b queuedTask) bool {
+	if a.priority != b.priority {
+		return a.priority > b.priority
+	}
+	if len(q.weights) > 0 && a.group != b.group && q.served[a.group] != q.served[b.group] {
+		return q.served[a.group] < q.served[b.group]
+	}
+
+	return a.seq < b.seq
+}
+
+// Must be called with the lock held.
+func (q *TaskQueue) queued(group string) bool {
+	for _, item := range q.items {
+		if item.group == group {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Must be called with the lock held.
+func (q *TaskQueue) weight(group string) float64 {
+	if w, ok := q.weights[group]; ok {
+		return w
+	}
+
+	return 1
+}
+
+func groupOf(t *taskmanager.Task) string {
+	if t.GroupInfo.InGroup {
+		return t.GroupInfo.GroupName
+	}
+
+	return ""
+}
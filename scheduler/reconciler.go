@@ -0,0 +1,144 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"sync"
+	"time"
+)
+
+// Runs periodic implicit reconciliation of all tasks, and explicit reconciliation with backoff
+// for tasks that are still waiting on a status update.
+// Status updates should be fed to the reconciler, which hands them to the callback.
+type Reconciler struct {
+	scheduler        Scheduler
+	callback         func(status *mesos_v1.TaskStatus)
+	ImplicitInterval time.Duration // How often Mesos is asked for the state of every task.
+	MinBackoff       time.Duration // Wait before a task is explicitly reconciled for the first time.
+	MaxBackoff       time.Duration // Upper bound for the backoff between explicit reconciliations of a task.
+	pending          map[string]*pendingTask
+	lastImplicit     time.Time
+	sync.Mutex
+}
+
+// A task that hasn't had a status update yet.
+type pendingTask struct {
+	info    *mesos_v1.TaskInfo
+	backoff time.Duration
+	next    time.Time
+}
+
+func NewReconciler(s Scheduler, callback func(status *mesos_v1.TaskStatus)) *Reconciler {
+	return &Reconciler{
+		scheduler:        s,
+		callback:         callback,
+		ImplicitInterval: 15 * time.Minute,
+		MinBackoff:       5 * time.Second,
+		MaxBackoff:       5 * time.Minute,
+		pending:          make(map[string]*pendingTask),
+	}
+}
+
+// Starts explicitly reconciling a task until a status update for it comes in.
+// This is typically used for tasks that were just launched, or for all known tasks after a failover.
+func (r *Reconciler) Track(tasks ...*mesos_v1.TaskInfo) {
+	r.Lock()
+	defer r.Unlock()
+
+	now := time.Now()
+	for _, task := range tasks {
+		r.pending[task.GetTaskId().GetValue()] = &pendingTask{
+			info:    task,
+			backoff: r.MinBackoff,
+			next:    now.Add(r.MinBackoff),
+		}
+	}
+}
+
+// Returns the number of tasks still waiting on a status update.
+func (r *Reconciler) Pending() int {
+	r.Lock()
+	defer r.Unlock()
+
+	return len(r.pending)
+}
+
+// Feeds a status update to the reconciler, which stops explicitly reconciling the task and hands the status to the callback.
+func (r *Reconciler) Update(status *mesos_v1.TaskStatus) {
+	r.Lock()
+	delete(r.pending, status.GetTaskId().GetValue())
+	r.Unlock()
+
+	if r.callback != nil {
+		r.callback(status)
+	}
+}
+
+// Sends any reconciliation that's due.
+func (r *Reconciler) reconcile(now time.Time) error {
+	r.Lock()
+	implicit := now.Sub(r.lastImplicit) >= r.ImplicitInterval
+	if implicit {
+		r.lastImplicit = now
+	}
+
+	var explicit []*mesos_v1.TaskInfo
+	for _, task := range r.pending {
+		if now.Before(task.next) {
+			continue
+		}
+
+		explicit = append(explicit, task.info)
+		task.backoff *= 2
+		if task.backoff > r.MaxBackoff {
+			task.backoff = r.MaxBackoff
+		}
+		task.next = now.Add(task.backoff)
+	}
+	r.Unlock()
+
+	if implicit {
+		// Reconciling with no tasks asks Mesos for the state of all of them.
+		if _, err := r.scheduler.Reconcile(context.Background(), nil); err != nil {
+			return err
+		}
+	}
+	if len(explicit) > 0 {
+		if _, err := r.scheduler.Reconcile(context.Background(), explicit); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Checks for due reconciliation every interval until stop is closed.
+// This blocks and is meant to be run in its own goroutine.
+func (r *Reconciler) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	r.reconcile(time.Now())
+	for {
+		select {
+		case now := <-ticker.C:
+			r.reconcile(now)
+		case <-stop:
+			return
+		}
+	}
+}
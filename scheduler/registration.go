@@ -0,0 +1,138 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"encoding/json"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	sched "github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_scheduler"
+	"github.com/verizonlabs/mesos-framework-sdk/persistence"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default key the framework ID is persisted under.
+const FRAMEWORK_ID_KEY = "/frameworkId"
+
+// What we persist about our registration.
+type registration struct {
+	Id   string `json:"id"`
+	Seen int64  `json:"seen"` // Unix time we last heard from the master.
+}
+
+// Persists the framework ID so a restarted scheduler re-subscribes as the same framework and keeps its tasks.
+// The ID is only reused within the framework's failover timeout, after which the master has already removed it.
+type RegistrationManager struct {
+	scheduler Scheduler
+	storage   persistence.KeyValueStore
+	key       string
+	sync.Mutex
+}
+
+func NewRegistrationManager(s Scheduler, storage persistence.KeyValueStore, key string) *RegistrationManager {
+	return &RegistrationManager{
+		scheduler: s,
+		storage:   storage,
+		key:       key,
+	}
+}
+
+// Restores a persisted framework ID into our framework info so the next subscription fails over to it.
+// IDs last seen longer ago than the failover timeout are cleared instead.
+func (r *RegistrationManager) Load() error {
+	r.Lock()
+	defer r.Unlock()
+
+	value, err := r.storage.Read(r.key)
+	if err != nil || value == "" {
+		return err
+	}
+
+	reg := registration{}
+	if err := json.Unmarshal([]byte(value), &reg); err != nil {
+		return err
+	}
+
+	info := r.scheduler.FrameworkInfo()
+	timeout := time.Duration(info.GetFailoverTimeout() * float64(time.Second))
+	if reg.Id == "" || time.Since(time.Unix(reg.Seen, 0)) > timeout {
+		return r.storage.Delete(r.key)
+	}
+
+	r.scheduler.SetFrameworkID(&mesos_v1.FrameworkID{Value: &reg.Id})
+
+	return nil
+}
+
+// Persists the framework ID handed out on SUBSCRIBED.
+func (r *RegistrationManager) Subscribed(id *mesos_v1.FrameworkID) error {
+	r.Lock()
+	defer r.Unlock()
+
+	r.scheduler.SetFrameworkID(id)
+
+	return r.save(id.GetValue())
+}
+
+// Records that the master is still talking to us, which keeps the persisted ID within its failover timeout.
+// Meant to be called on heartbeats.
+func (r *RegistrationManager) Seen() error {
+	r.Lock()
+	defer r.Unlock()
+
+	id := r.scheduler.FrameworkID().GetValue()
+	if id == "" {
+		return nil
+	}
+
+	return r.save(id)
+}
+
+// Forgets the framework ID so the next subscription registers a new framework.
+func (r *RegistrationManager) Rejected() error {
+	r.Lock()
+	defer r.Unlock()
+
+	r.scheduler.SetFrameworkID(nil)
+
+	return r.storage.Delete(r.key)
+}
+
+// Forgets the framework ID if the error means the master no longer knows about our framework.
+// Reports whether the ID was rejected.
+func (r *RegistrationManager) Error(event *sched.Event_Error) (bool, error) {
+	msg := strings.ToLower(event.GetMessage())
+	if !strings.Contains(msg, "framework") {
+		return false, nil
+	}
+
+	for _, reason := range []string{"removed", "not found", "unknown"} {
+		if strings.Contains(msg, reason) {
+			return true, r.Rejected()
+		}
+	}
+
+	return false, nil
+}
+
+func (r *RegistrationManager) save(id string) error {
+	data, err := json.Marshal(registration{Id: id, Seen: time.Now().Unix()})
+	if err != nil {
+		return err
+	}
+
+	return r.storage.Update(r.key, string(data))
+}
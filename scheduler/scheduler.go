@@ -26,32 +26,45 @@ the protobuf required for the call and send it off to the client.
 End users should only create their own scheduler if they wish to change the behavior of their calls.
 */
 import (
+	"context"
 	"errors"
 	"github.com/verizonlabs/mesos-framework-sdk/client"
 	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
 	sched "github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_scheduler"
 	"github.com/verizonlabs/mesos-framework-sdk/logging"
 	"github.com/verizonlabs/mesos-framework-sdk/recordio"
+	res "github.com/verizonlabs/mesos-framework-sdk/resources"
+	"github.com/verizonlabs/mesos-framework-sdk/scheduler/calls"
 	"net/http"
 	"sync"
 )
 
 type Scheduler interface {
 	FrameworkInfo() *mesos_v1.FrameworkInfo
+	FrameworkID() *mesos_v1.FrameworkID
+	SetFrameworkID(id *mesos_v1.FrameworkID)
 
 	// Default Calls for scheduler
-	Subscribe(chan *sched.Event) (*http.Response, error)
-	Teardown() (*http.Response, error)
-	Accept(offerIds []*mesos_v1.OfferID, tasks []*mesos_v1.Offer_Operation, filters *mesos_v1.Filters) (*http.Response, error)
-	Decline(offerIds []*mesos_v1.OfferID, filters *mesos_v1.Filters) (*http.Response, error)
-	Revive() (*http.Response, error)
-	Kill(taskId *mesos_v1.TaskID, agentid *mesos_v1.AgentID) (*http.Response, error)
-	Shutdown(execId *mesos_v1.ExecutorID, agentId *mesos_v1.AgentID) (*http.Response, error)
-	Acknowledge(agentId *mesos_v1.AgentID, taskId *mesos_v1.TaskID, uuid []byte) (*http.Response, error)
-	Reconcile(tasks []*mesos_v1.TaskInfo) (*http.Response, error)
-	Message(agentId *mesos_v1.AgentID, executorId *mesos_v1.ExecutorID, data []byte) (*http.Response, error)
-	SchedRequest(resources []*mesos_v1.Request) (*http.Response, error)
-	Suppress() (*http.Response, error)
+	Subscribe(ctx context.Context, eventChan chan *sched.Event) (*http.Response, error)
+	Teardown(ctx context.Context) (*http.Response, error)
+	Accept(ctx context.Context, offerIds []*mesos_v1.OfferID, tasks []*mesos_v1.Offer_Operation, filters *mesos_v1.Filters) (*http.Response, error)
+	Decline(ctx context.Context, offerIds []*mesos_v1.OfferID, filters *mesos_v1.Filters) (*http.Response, error)
+	AcceptInverseOffers(ctx context.Context, inverseOfferIds []*mesos_v1.OfferID, filters *mesos_v1.Filters) (*http.Response, error)
+	DeclineInverseOffers(ctx context.Context, inverseOfferIds []*mesos_v1.OfferID, filters *mesos_v1.Filters) (*http.Response, error)
+	LaunchGroup(ctx context.Context, executor *mesos_v1.ExecutorInfo, group *mesos_v1.TaskGroupInfo, offerIds ...*mesos_v1.OfferID) (*http.Response, error)
+	Reserve(ctx context.Context, offerIds []*mesos_v1.OfferID, resources []*mesos_v1.Resource) (*http.Response, error)
+	Unreserve(ctx context.Context, offerIds []*mesos_v1.OfferID, resources []*mesos_v1.Resource) (*http.Response, error)
+	CreateVolumes(ctx context.Context, offerIds []*mesos_v1.OfferID, volumes []*mesos_v1.Resource) (*http.Response, error)
+	DestroyVolumes(ctx context.Context, offerIds []*mesos_v1.OfferID, volumes []*mesos_v1.Resource) (*http.Response, error)
+	Revive(ctx context.Context) (*http.Response, error)
+	Kill(ctx context.Context, taskId *mesos_v1.TaskID, agentid *mesos_v1.AgentID) (*http.Response, error)
+	KillWithPolicy(ctx context.Context, taskId *mesos_v1.TaskID, agentId *mesos_v1.AgentID, policy *mesos_v1.KillPolicy) (*http.Response, error)
+	Shutdown(ctx context.Context, execId *mesos_v1.ExecutorID, agentId *mesos_v1.AgentID) (*http.Response, error)
+	Acknowledge(ctx context.Context, agentId *mesos_v1.AgentID, taskId *mesos_v1.TaskID, uuid []byte) (*http.Response, error)
+	Reconcile(ctx context.Context, tasks []*mesos_v1.TaskInfo) (*http.Response, error)
+	Message(ctx context.Context, agentId *mesos_v1.AgentID, executorId *mesos_v1.ExecutorID, data []byte) (*http.Response, error)
+	SchedRequest(ctx context.Context, resources []*mesos_v1.Request) (*http.Response, error)
+	Suppress(ctx context.Context) (*http.Response, error)
 }
 
 // Default Scheduler can be used as a higher-level construct.
@@ -61,6 +74,9 @@ type DefaultScheduler struct {
 	logger        logging.Logger
 	IsSuppressed  bool
 	sync.RWMutex
+
+	interceptors      []Interceptor
+	eventInterceptors []EventInterceptor
 }
 
 func NewDefaultScheduler(c client.Client, info *mesos_v1.FrameworkInfo, logger logging.Logger) *DefaultScheduler {
@@ -72,26 +88,45 @@ func NewDefaultScheduler(c client.Client, info *mesos_v1.FrameworkInfo, logger l
 	}
 }
 
+// The framework ID changes while we run, read it through FrameworkID instead of the returned info.
 func (c *DefaultScheduler) FrameworkInfo() *mesos_v1.FrameworkInfo {
 	return c.frameworkInfo
 }
 
+// Gets the ID the master assigned to us, which is nil until we've registered.
+func (c *DefaultScheduler) FrameworkID() *mesos_v1.FrameworkID {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.frameworkInfo.GetId()
+}
+
+// Sets the ID used for subsequent calls, nil registers a new framework on the next subscription.
+func (c *DefaultScheduler) SetFrameworkID(id *mesos_v1.FrameworkID) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.frameworkInfo.Id = id
+}
+
 // Make a subscription call to mesos.
 // Channel passed is the channel for Event Controller.
-func (c *DefaultScheduler) Subscribe(eventChan chan *sched.Event) (*http.Response, error) {
-	call := &sched.Call{
-		Type: sched.Call_SUBSCRIBE.Enum(),
-		Subscribe: &sched.Call_Subscribe{
-			FrameworkInfo: c.frameworkInfo,
-		},
-		FrameworkId: c.frameworkInfo.Id,
+func (c *DefaultScheduler) Subscribe(ctx context.Context, eventChan chan *sched.Event) (*http.Response, error) {
+	c.RLock()
+	info := *c.frameworkInfo
+	c.RUnlock()
+
+	call, err := calls.NewSubscribe().WithFrameworkInfo(&info).Build()
+	if err != nil {
+		c.logger.Emit(logging.ERROR, "%s", err.Error())
+		return nil, err
 	}
 
 	// If we disconnect we need to reset the stream ID. For this reason always start with a fresh stream ID.
 	// Otherwise we'll never be able to reconnect.
 	c.Client.SetStreamID("")
 
-	resp, err := c.Client.Request(call)
+	resp, err := c.request(ctx, call)
 	if err != nil {
 		return resp, err
 	} else {
@@ -101,14 +136,16 @@ func (c *DefaultScheduler) Subscribe(eventChan chan *sched.Event) (*http.Respons
 }
 
 // Send a teardown request to mesos master.
-func (c *DefaultScheduler) Teardown() (*http.Response, error) {
-	teardown := &sched.Call{
-		FrameworkId: c.frameworkInfo.GetId(),
-		Type:        sched.Call_TEARDOWN.Enum(),
+func (c *DefaultScheduler) Teardown(ctx context.Context) (*http.Response, error) {
+	teardown, err := calls.NewTeardown().WithFramework(c.FrameworkID()).Build()
+	if err != nil {
+		c.logger.Emit(logging.ERROR, "%s", err.Error())
+		return nil, err
 	}
-	resp, err := c.Client.Request(teardown)
+
+	resp, err := c.request(ctx, teardown)
 	if err != nil {
-		c.logger.Emit(logging.ERROR, err.Error())
+		c.logger.Emit(logging.ERROR, "%s", err.Error())
 	}
 
 	c.logger.Emit(logging.INFO, "Tearing down framework")
@@ -116,16 +153,21 @@ func (c *DefaultScheduler) Teardown() (*http.Response, error) {
 }
 
 // Accepts offers from mesos master
-func (c *DefaultScheduler) Accept(offerIds []*mesos_v1.OfferID, tasks []*mesos_v1.Offer_Operation, filters *mesos_v1.Filters) (*http.Response, error) {
-	accept := &sched.Call{
-		FrameworkId: c.frameworkInfo.GetId(),
-		Type:        sched.Call_ACCEPT.Enum(),
-		Accept:      &sched.Call_Accept{OfferIds: offerIds, Operations: tasks, Filters: filters},
+func (c *DefaultScheduler) Accept(ctx context.Context, offerIds []*mesos_v1.OfferID, tasks []*mesos_v1.Offer_Operation, filters *mesos_v1.Filters) (*http.Response, error) {
+	accept, err := calls.NewAccept().
+		WithFramework(c.FrameworkID()).
+		WithOffers(offerIds...).
+		WithOperations(tasks...).
+		WithFilters(filters).
+		Build()
+	if err != nil {
+		c.logger.Emit(logging.ERROR, "%s", err.Error())
+		return nil, err
 	}
 
-	resp, err := c.Client.Request(accept)
+	resp, err := c.request(ctx, accept)
 	if err != nil {
-		c.logger.Emit(logging.ERROR, err.Error())
+		c.logger.Emit(logging.ERROR, "%s", err.Error())
 		return nil, err
 	}
 
@@ -133,17 +175,86 @@ func (c *DefaultScheduler) Accept(offerIds []*mesos_v1.OfferID, tasks []*mesos_v
 	return resp, err
 }
 
-func (c *DefaultScheduler) Decline(offerIds []*mesos_v1.OfferID, filters *mesos_v1.Filters) (*http.Response, error) {
+// Launches a group of tasks, such as the containers of a pod, together on the agent of the given offers.
+// The executor's framework ID is filled in when it isn't set.
+func (c *DefaultScheduler) LaunchGroup(ctx context.Context, executor *mesos_v1.ExecutorInfo, group *mesos_v1.TaskGroupInfo, offerIds ...*mesos_v1.OfferID) (*http.Response, error) {
+	if executor.FrameworkId == nil {
+		executor.FrameworkId = c.FrameworkID()
+	}
+
+	launch := []*mesos_v1.Offer_Operation{res.LaunchGroupOfferOperation(executor, group)}
+
+	resp, err := c.Accept(ctx, offerIds, launch, nil)
+	if err == nil {
+		c.logger.Emit(logging.INFO, "Launching a group of %d tasks", len(group.GetTasks()))
+	}
+
+	return resp, err
+}
+
+// Dynamically reserves resources from the given offers for the framework's role.
+func (c *DefaultScheduler) Reserve(ctx context.Context, offerIds []*mesos_v1.OfferID, resources []*mesos_v1.Resource) (*http.Response, error) {
+	reserve := []*mesos_v1.Offer_Operation{res.ReserveOfferOperation(resources)}
+
+	resp, err := c.Accept(ctx, offerIds, reserve, nil)
+	if err == nil {
+		c.logger.Emit(logging.INFO, "Reserving %d resources", len(resources))
+	}
+
+	return resp, err
+}
+
+// Releases dynamically reserved resources from the given offers.
+func (c *DefaultScheduler) Unreserve(ctx context.Context, offerIds []*mesos_v1.OfferID, resources []*mesos_v1.Resource) (*http.Response, error) {
+	unreserve := []*mesos_v1.Offer_Operation{res.UnreserveOfferOperation(resources)}
+
+	resp, err := c.Accept(ctx, offerIds, unreserve, nil)
+	if err == nil {
+		c.logger.Emit(logging.INFO, "Unreserving %d resources", len(resources))
+	}
+
+	return resp, err
+}
+
+// Creates persistent volumes out of reserved disk resources from the given offers.
+func (c *DefaultScheduler) CreateVolumes(ctx context.Context, offerIds []*mesos_v1.OfferID, volumes []*mesos_v1.Resource) (*http.Response, error) {
+	create := []*mesos_v1.Offer_Operation{res.CreateVolumeOfferOperation(volumes)}
+
+	resp, err := c.Accept(ctx, offerIds, create, nil)
+	if err == nil {
+		c.logger.Emit(logging.INFO, "Creating %d persistent volumes", len(volumes))
+	}
+
+	return resp, err
+}
+
+// Destroys persistent volumes from the given offers, releasing the disk back to the role's reservation.
+func (c *DefaultScheduler) DestroyVolumes(ctx context.Context, offerIds []*mesos_v1.OfferID, volumes []*mesos_v1.Resource) (*http.Response, error) {
+	destroy := []*mesos_v1.Offer_Operation{res.DestroyVolumeOfferOperation(volumes)}
+
+	resp, err := c.Accept(ctx, offerIds, destroy, nil)
+	if err == nil {
+		c.logger.Emit(logging.INFO, "Destroying %d persistent volumes", len(volumes))
+	}
+
+	return resp, err
+}
+
+func (c *DefaultScheduler) Decline(ctx context.Context, offerIds []*mesos_v1.OfferID, filters *mesos_v1.Filters) (*http.Response, error) {
 	// Get a list of the offer ids to decline and any filters.
-	decline := &sched.Call{
-		FrameworkId: c.frameworkInfo.GetId(),
-		Type:        sched.Call_DECLINE.Enum(),
-		Decline:     &sched.Call_Decline{OfferIds: offerIds, Filters: filters},
+	decline, err := calls.NewDecline().
+		WithFramework(c.FrameworkID()).
+		WithOffers(offerIds...).
+		WithFilters(filters).
+		Build()
+	if err != nil {
+		c.logger.Emit(logging.ERROR, "%s", err.Error())
+		return nil, err
 	}
 
-	resp, err := c.Client.Request(decline)
+	resp, err := c.request(ctx, decline)
 	if err != nil {
-		c.logger.Emit(logging.ERROR, err.Error())
+		c.logger.Emit(logging.ERROR, "%s", err.Error())
 	}
 
 	l := len(offerIds)
@@ -154,8 +265,52 @@ func (c *DefaultScheduler) Decline(offerIds []*mesos_v1.OfferID, filters *mesos_
 	return resp, err
 }
 
+// Tells the master we're fine with the agents of the given inverse offers going down for maintenance.
+func (c *DefaultScheduler) AcceptInverseOffers(ctx context.Context, inverseOfferIds []*mesos_v1.OfferID, filters *mesos_v1.Filters) (*http.Response, error) {
+	accept, err := calls.NewAcceptInverseOffers().
+		WithFramework(c.FrameworkID()).
+		WithOffers(inverseOfferIds...).
+		WithFilters(filters).
+		Build()
+	if err != nil {
+		c.logger.Emit(logging.ERROR, "%s", err.Error())
+		return nil, err
+	}
+
+	resp, err := c.request(ctx, accept)
+	if err != nil {
+		c.logger.Emit(logging.ERROR, "%s", err.Error())
+	} else {
+		c.logger.Emit(logging.INFO, "Accepting %d inverse offers", len(inverseOfferIds))
+	}
+
+	return resp, err
+}
+
+// Tells the master we'd rather the agents of the given inverse offers didn't go down for maintenance yet.
+func (c *DefaultScheduler) DeclineInverseOffers(ctx context.Context, inverseOfferIds []*mesos_v1.OfferID, filters *mesos_v1.Filters) (*http.Response, error) {
+	decline, err := calls.NewDeclineInverseOffers().
+		WithFramework(c.FrameworkID()).
+		WithOffers(inverseOfferIds...).
+		WithFilters(filters).
+		Build()
+	if err != nil {
+		c.logger.Emit(logging.ERROR, "%s", err.Error())
+		return nil, err
+	}
+
+	resp, err := c.request(ctx, decline)
+	if err != nil {
+		c.logger.Emit(logging.ERROR, "%s", err.Error())
+	} else {
+		c.logger.Emit(logging.INFO, "Declining %d inverse offers", len(inverseOfferIds))
+	}
+
+	return resp, err
+}
+
 // Sent by the scheduler to remove any/all filters that it has previously set via ACCEPT or DECLINE calls.
-func (c *DefaultScheduler) Revive() (*http.Response, error) {
+func (c *DefaultScheduler) Revive(ctx context.Context) (*http.Response, error) {
 	c.RLock()
 	if !c.IsSuppressed {
 		c.RUnlock()
@@ -163,14 +318,15 @@ func (c *DefaultScheduler) Revive() (*http.Response, error) {
 	}
 	c.RUnlock()
 
-	revive := &sched.Call{
-		FrameworkId: c.frameworkInfo.GetId(),
-		Type:        sched.Call_REVIVE.Enum(),
+	revive, err := calls.NewRevive().WithFramework(c.FrameworkID()).Build()
+	if err != nil {
+		c.logger.Emit(logging.ERROR, "%s", err.Error())
+		return nil, err
 	}
 
-	resp, err := c.Client.Request(revive)
+	resp, err := c.request(ctx, revive)
 	if err != nil {
-		c.logger.Emit(logging.ERROR, err.Error())
+		c.logger.Emit(logging.ERROR, "%s", err.Error())
 	} else {
 		c.Lock()
 		c.IsSuppressed = false
@@ -182,42 +338,51 @@ func (c *DefaultScheduler) Revive() (*http.Response, error) {
 	return resp, err
 }
 
-func (c *DefaultScheduler) Kill(taskId *mesos_v1.TaskID, agentid *mesos_v1.AgentID) (*http.Response, error) {
-	kill := &sched.Call{
-		FrameworkId: c.frameworkInfo.GetId(),
-		Type:        sched.Call_KILL.Enum(),
-		Kill:        &sched.Call_Kill{TaskId: taskId, AgentId: agentid},
+func (c *DefaultScheduler) Kill(ctx context.Context, taskId *mesos_v1.TaskID, agentid *mesos_v1.AgentID) (*http.Response, error) {
+	return c.KillWithPolicy(ctx, taskId, agentid, nil)
+}
+
+// Kills a task, overriding its kill policy when one is given.
+// This can be used to shorten the grace period of a task that is already being killed.
+func (c *DefaultScheduler) KillWithPolicy(ctx context.Context, taskId *mesos_v1.TaskID, agentid *mesos_v1.AgentID, policy *mesos_v1.KillPolicy) (*http.Response, error) {
+	b := calls.NewKill().WithFramework(c.FrameworkID()).WithTask(taskId).WithAgent(agentid)
+	if policy != nil {
+		b.WithKillPolicy(policy)
 	}
 
-	resp, err := c.Client.Request(kill)
+	kill, err := b.Build()
 	if err != nil {
-		c.logger.Emit(logging.ERROR, err.Error())
+		c.logger.Emit(logging.ERROR, "%s", err.Error())
+		return nil, err
+	}
+
+	resp, err := c.request(ctx, kill)
+	if err != nil {
+		c.logger.Emit(logging.ERROR, "%s", err.Error())
 	}
 	// Kill returns a 202 accepted.
-	if resp.StatusCode == 202 {
+	if resp != nil && resp.StatusCode == 202 {
 		c.logger.Emit(logging.INFO, "Killing task %s", taskId.GetValue())
 	}
 	return resp, err
 }
 
-func (c *DefaultScheduler) Shutdown(execId *mesos_v1.ExecutorID, agentId *mesos_v1.AgentID) (*http.Response, error) {
-	shutdown := &sched.Call{
-		FrameworkId: c.frameworkInfo.GetId(),
-		Type:        sched.Call_SHUTDOWN.Enum(),
-		Shutdown: &sched.Call_Shutdown{
-			ExecutorId: execId,
-			AgentId:    agentId,
-		},
+func (c *DefaultScheduler) Shutdown(ctx context.Context, execId *mesos_v1.ExecutorID, agentId *mesos_v1.AgentID) (*http.Response, error) {
+	shutdown, err := calls.NewShutdown().WithFramework(c.FrameworkID()).WithExecutor(execId).WithAgent(agentId).Build()
+	if err != nil {
+		c.logger.Emit(logging.ERROR, "%s", err.Error())
+		return nil, err
 	}
-	resp, err := c.Client.Request(shutdown)
+
+	resp, err := c.request(ctx, shutdown)
 	if err != nil {
-		c.logger.Emit(logging.ERROR, err.Error())
+		c.logger.Emit(logging.ERROR, "%s", err.Error())
 	}
 	c.logger.Emit(logging.INFO, "Shutting down")
 	return resp, err
 }
 
-func (c *DefaultScheduler) Acknowledge(agentId *mesos_v1.AgentID, taskId *mesos_v1.TaskID, uuid []byte) (*http.Response, error) {
+func (c *DefaultScheduler) Acknowledge(ctx context.Context, agentId *mesos_v1.AgentID, taskId *mesos_v1.TaskID, uuid []byte) (*http.Response, error) {
 
 	// Note that with the new API, schedulers are responsible for explicitly acknowledging the receipt of status
 	// updates that have “status.uuid()” set.
@@ -227,85 +392,78 @@ func (c *DefaultScheduler) Acknowledge(agentId *mesos_v1.AgentID, taskId *mesos_
 		return nil, errors.New("No uuid passed in to ACK.")
 	}
 
-	acknowledge := &sched.Call{
-		FrameworkId: c.frameworkInfo.GetId(),
-		Type:        sched.Call_ACKNOWLEDGE.Enum(),
-		Acknowledge: &sched.Call_Acknowledge{
-			AgentId: agentId,
-			TaskId:  taskId,
-			Uuid:    uuid,
-		},
+	acknowledge, err := calls.NewAcknowledge().
+		WithFramework(c.FrameworkID()).
+		WithAgent(agentId).
+		WithTask(taskId).
+		WithUUID(uuid).
+		Build()
+	if err != nil {
+		c.logger.Emit(logging.ERROR, "%s", err.Error())
+		return nil, err
 	}
 
-	resp, err := c.Client.Request(acknowledge)
+	resp, err := c.request(ctx, acknowledge)
 	if err != nil {
-		c.logger.Emit(logging.ERROR, err.Error())
+		c.logger.Emit(logging.ERROR, "%s", err.Error())
 	}
 	return resp, err
 }
 
-func (c *DefaultScheduler) Reconcile(tasks []*mesos_v1.TaskInfo) (*http.Response, error) {
-	reconcileTasks := make([]*sched.Call_Reconcile_Task, 0, len(tasks))
-	for _, task := range tasks {
-		reconcileTasks = append(reconcileTasks, &sched.Call_Reconcile_Task{
-			AgentId: task.GetAgentId(),
-			TaskId:  task.GetTaskId(),
-		})
+func (c *DefaultScheduler) Reconcile(ctx context.Context, tasks []*mesos_v1.TaskInfo) (*http.Response, error) {
+	reconcile, err := calls.NewReconcile().WithFramework(c.FrameworkID()).WithReconcileTasks(tasks...).Build()
+	if err != nil {
+		c.logger.Emit(logging.ERROR, "%s", err.Error())
+		return nil, err
 	}
 
-	reconcile := &sched.Call{
-		FrameworkId: c.frameworkInfo.GetId(),
-		Type:        sched.Call_RECONCILE.Enum(),
-		Reconcile: &sched.Call_Reconcile{
-			Tasks: reconcileTasks,
-		},
-	}
-	resp, err := c.Client.Request(reconcile)
+	resp, err := c.request(ctx, reconcile)
 	if err != nil {
-		c.logger.Emit(logging.ERROR, err.Error())
+		c.logger.Emit(logging.ERROR, "%s", err.Error())
 	}
 
 	c.logger.Emit(logging.INFO, "Reconciling %d tasks", len(tasks))
 	return resp, err
 }
 
-func (c *DefaultScheduler) Message(agentId *mesos_v1.AgentID, executorId *mesos_v1.ExecutorID, data []byte) (*http.Response, error) {
-	message := &sched.Call{
-		FrameworkId: c.frameworkInfo.GetId(),
-		Type:        sched.Call_MESSAGE.Enum(),
-		Message: &sched.Call_Message{
-			AgentId:    agentId,
-			ExecutorId: executorId,
-			Data:       data,
-		},
-	}
-	resp, err := c.Client.Request(message)
+func (c *DefaultScheduler) Message(ctx context.Context, agentId *mesos_v1.AgentID, executorId *mesos_v1.ExecutorID, data []byte) (*http.Response, error) {
+	message, err := calls.NewMessage().
+		WithFramework(c.FrameworkID()).
+		WithAgent(agentId).
+		WithExecutor(executorId).
+		WithData(data).
+		Build()
+	if err != nil {
+		c.logger.Emit(logging.ERROR, "%s", err.Error())
+		return nil, err
+	}
+
+	resp, err := c.request(ctx, message)
 	if err != nil {
-		c.logger.Emit(logging.ERROR, err.Error())
+		c.logger.Emit(logging.ERROR, "%s", err.Error())
 	}
 	c.logger.Emit(logging.INFO, "Message received from agent %s and executor %s", agentId.GetValue(), executorId.GetValue())
 	return resp, err
 }
 
 // NOTE: This method is only kept to conform to official Mesos codebase.  This does nothing.
-func (c *DefaultScheduler) SchedRequest(resources []*mesos_v1.Request) (*http.Response, error) {
-	request := &sched.Call{
-		FrameworkId: c.frameworkInfo.GetId(),
-		Type:        sched.Call_REQUEST.Enum(),
-		Request: &sched.Call_Request{
-			Requests: resources,
-		},
-	}
-	resp, err := c.Client.Request(request)
+func (c *DefaultScheduler) SchedRequest(ctx context.Context, resources []*mesos_v1.Request) (*http.Response, error) {
+	request, err := calls.NewRequest().WithFramework(c.FrameworkID()).WithRequests(resources...).Build()
+	if err != nil {
+		c.logger.Emit(logging.ERROR, "%s", err.Error())
+		return nil, err
+	}
+
+	resp, err := c.request(ctx, request)
 	if err != nil {
-		c.logger.Emit(logging.ERROR, err.Error())
+		c.logger.Emit(logging.ERROR, "%s", err.Error())
 	}
 	c.logger.Emit(logging.INFO, "Requesting resources")
 	return resp, err
 }
 
 // Makes a call to Mesos to suppress any further offers.
-func (c *DefaultScheduler) Suppress() (*http.Response, error) {
+func (c *DefaultScheduler) Suppress(ctx context.Context) (*http.Response, error) {
 	c.RLock()
 	if c.IsSuppressed {
 		c.RUnlock()
@@ -313,13 +471,15 @@ func (c *DefaultScheduler) Suppress() (*http.Response, error) {
 	}
 	c.RUnlock()
 
-	suppress := &sched.Call{
-		FrameworkId: c.frameworkInfo.GetId(),
-		Type:        sched.Call_SUPPRESS.Enum(),
+	suppress, err := calls.NewSuppress().WithFramework(c.FrameworkID()).Build()
+	if err != nil {
+		c.logger.Emit(logging.ERROR, "%s", err.Error())
+		return nil, err
 	}
-	resp, err := c.Client.Request(suppress)
+
+	resp, err := c.request(ctx, suppress)
 	if err != nil {
-		c.logger.Emit(logging.ERROR, err.Error())
+		c.logger.Emit(logging.ERROR, "%s", err.Error())
 	} else {
 		c.Lock()
 		c.IsSuppressed = true
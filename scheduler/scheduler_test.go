@@ -15,13 +15,23 @@
 package scheduler
 
 import (
-	"io"
+	"context"
+	"errors"
+	"github.com/golang/protobuf/proto"
 	"github.com/verizonlabs/mesos-framework-sdk/client"
 	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
 	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_scheduler"
+	"github.com/verizonlabs/mesos-framework-sdk/resources"
+	resourcemanager "github.com/verizonlabs/mesos-framework-sdk/resources/manager"
+	taskmanager "github.com/verizonlabs/mesos-framework-sdk/task/manager"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 )
 
 type mockClient struct{}
@@ -30,6 +40,10 @@ func (m *mockClient) Request(interface{}) (*http.Response, error) {
 	return new(http.Response), nil
 }
 
+func (m *mockClient) RequestContext(ctx context.Context, call interface{}) (*http.Response, error) {
+	return m.Request(call)
+}
+
 func (m *mockClient) StreamID() string {
 	return "test"
 }
@@ -46,7 +60,7 @@ func (m *mockLogger) Emit(severity uint8, template string, args ...interface{})
 
 var (
 	c = new(mockClient)
-	i = &mesos_v1.FrameworkInfo{}
+	i = &mesos_v1.FrameworkInfo{Id: &mesos_v1.FrameworkID{Value: utils.ProtoString("framework")}}
 	l = new(mockLogger)
 )
 
@@ -76,7 +90,7 @@ func TestDefaultScheduler_Accept(t *testing.T) {
 	tasks := []*mesos_v1.Offer_Operation{}
 	filters := &mesos_v1.Filters{}
 
-	_, err := s.Accept(offerIds, tasks, filters)
+	_, err := s.Accept(context.Background(), offerIds, tasks, filters)
 	if err != nil {
 		t.Fatal(err.Error())
 	}
@@ -91,7 +105,7 @@ func BenchmarkDefaultScheduler_Accept(b *testing.B) {
 	b.ResetTimer()
 
 	for n := 0; n < b.N; n++ {
-		s.Accept(offerIds, tasks, filters)
+		s.Accept(context.Background(), offerIds, tasks, filters)
 	}
 }
 
@@ -104,12 +118,12 @@ func TestDefaultScheduler_Acknowledge(t *testing.T) {
 	taskId := &mesos_v1.TaskID{}
 	uuid := []byte{}
 
-	_, err := s.Acknowledge(agentId, taskId, uuid)
+	_, err := s.Acknowledge(context.Background(), agentId, taskId, uuid)
 	if err != nil {
 		t.Fatal(err.Error())
 	}
 
-	_, err = s.Acknowledge(agentId, taskId, nil)
+	_, err = s.Acknowledge(context.Background(), agentId, taskId, nil)
 	if err == nil {
 		t.Fatal("UUID should be required: " + err.Error())
 	}
@@ -124,7 +138,7 @@ func BenchmarkDefaultScheduler_Acknowledge(b *testing.B) {
 	b.ResetTimer()
 
 	for n := 0; n < b.N; n++ {
-		s.Acknowledge(agentId, taskId, uuid)
+		s.Acknowledge(context.Background(), agentId, taskId, uuid)
 	}
 }
 
@@ -136,7 +150,7 @@ func TestDefaultScheduler_Decline(t *testing.T) {
 	offerIds := []*mesos_v1.OfferID{}
 	filters := &mesos_v1.Filters{}
 
-	_, err := s.Decline(offerIds, filters)
+	_, err := s.Decline(context.Background(), offerIds, filters)
 	if err != nil {
 		t.Fatal(err.Error())
 	}
@@ -150,7 +164,7 @@ func BenchmarkDefaultScheduler_Decline(b *testing.B) {
 	b.ResetTimer()
 
 	for n := 0; n < b.N; n++ {
-		s.Decline(offerIds, filters)
+		s.Decline(context.Background(), offerIds, filters)
 	}
 }
 
@@ -170,7 +184,7 @@ func TestDefaultScheduler_Kill(t *testing.T) {
 	taskId := &mesos_v1.TaskID{Value: &val}
 	agentId := &mesos_v1.AgentID{Value: &val}
 
-	_, err := s.Kill(taskId, agentId)
+	_, err := s.Kill(context.Background(), taskId, agentId)
 	if err != nil {
 		t.Fatal(err.Error())
 	}
@@ -184,7 +198,7 @@ func BenchmarkDefaultScheduler_Kill(b *testing.B) {
 	b.ResetTimer()
 
 	for n := 0; n < b.N; n++ {
-		s.Kill(taskId, agentId)
+		s.Kill(context.Background(), taskId, agentId)
 	}
 }
 
@@ -197,7 +211,7 @@ func TestDefaultScheduler_Message(t *testing.T) {
 	execId := &mesos_v1.ExecutorID{}
 	data := []byte{}
 
-	_, err := s.Message(agentId, execId, data)
+	_, err := s.Message(context.Background(), agentId, execId, data)
 	if err != nil {
 		t.Fatal(err.Error())
 	}
@@ -212,7 +226,7 @@ func BenchmarkDefaultScheduler_Message(b *testing.B) {
 	b.ResetTimer()
 
 	for n := 0; n < b.N; n++ {
-		s.Message(agentId, execId, data)
+		s.Message(context.Background(), agentId, execId, data)
 	}
 }
 
@@ -221,9 +235,9 @@ func TestDefaultScheduler_Reconcile(t *testing.T) {
 	t.Parallel()
 
 	s := NewDefaultScheduler(c, i, l)
-	tasks := []*mesos_v1.TaskInfo{{}}
+	tasks := []*mesos_v1.TaskInfo{{TaskId: &mesos_v1.TaskID{Value: utils.ProtoString("task")}}}
 
-	_, err := s.Reconcile(tasks)
+	_, err := s.Reconcile(context.Background(), tasks)
 	if err != nil {
 		t.Fatal(err.Error())
 	}
@@ -236,7 +250,7 @@ func BenchmarkDefaultScheduler_Reconcile(b *testing.B) {
 	b.ResetTimer()
 
 	for n := 0; n < b.N; n++ {
-		s.Reconcile(tasks)
+		s.Reconcile(context.Background(), tasks)
 	}
 }
 
@@ -246,13 +260,13 @@ func TestDefaultScheduler_Revive(t *testing.T) {
 
 	s := NewDefaultScheduler(c, i, l)
 
-	_, err := s.Revive()
+	_, err := s.Revive(context.Background())
 	if err != nil {
 		t.Fatal(err.Error())
 	}
 
-	s.Suppress()
-	_, err = s.Revive()
+	s.Suppress(context.Background())
+	_, err = s.Revive(context.Background())
 	if err != nil {
 		t.Fatal(err.Error())
 	}
@@ -264,7 +278,7 @@ func BenchmarkDefaultScheduler_Revive(b *testing.B) {
 	b.ResetTimer()
 
 	for n := 0; n < b.N; n++ {
-		s.Revive()
+		s.Revive(context.Background())
 	}
 }
 
@@ -275,7 +289,7 @@ func TestDefaultScheduler_SchedRequest(t *testing.T) {
 	s := NewDefaultScheduler(c, i, l)
 	res := []*mesos_v1.Request{}
 
-	_, err := s.SchedRequest(res)
+	_, err := s.SchedRequest(context.Background(), res)
 	if err != nil {
 		t.Fatal(err.Error())
 	}
@@ -288,7 +302,7 @@ func BenchmarkDefaultScheduler_SchedRequest(b *testing.B) {
 	b.ResetTimer()
 
 	for n := 0; n < b.N; n++ {
-		s.SchedRequest(res)
+		s.SchedRequest(context.Background(), res)
 	}
 }
 
@@ -300,7 +314,7 @@ func TestDefaultScheduler_Shutdown(t *testing.T) {
 	execId := &mesos_v1.ExecutorID{}
 	agentId := &mesos_v1.AgentID{}
 
-	_, err := s.Shutdown(execId, agentId)
+	_, err := s.Shutdown(context.Background(), execId, agentId)
 	if err != nil {
 		t.Fatal(err.Error())
 	}
@@ -314,7 +328,7 @@ func BenchmarkDefaultScheduler_Shutdown(b *testing.B) {
 	b.ResetTimer()
 
 	for n := 0; n < b.N; n++ {
-		s.Shutdown(execId, agentId)
+		s.Shutdown(context.Background(), execId, agentId)
 	}
 }
 
@@ -337,20 +351,20 @@ func TestDefaultScheduler_Subscribe(t *testing.T) {
 		Name: &val,
 	}
 
-	_, err := s.Subscribe(ch)
+	_, err := s.Subscribe(context.Background(), ch)
 
 	// We SHOULD get an error in this case; make sure that's true.
 	if err == nil {
 		t.Fatal("Subscribe should have failed but it didn't")
 	}
 
-	_, err = s.Subscribe(ch)
+	_, err = s.Subscribe(context.Background(), ch)
 	if err != io.EOF {
 		t.Fatal("Expected EOF but encountered another error: " + err.Error())
 	}
 
 	s.frameworkInfo = &mesos_v1.FrameworkInfo{}
-	_, err = s.Subscribe(ch)
+	_, err = s.Subscribe(context.Background(), ch)
 	if err == nil {
 		t.Fatal("Subscribe call should have failed due to missing data: " + err.Error())
 	}
@@ -371,7 +385,7 @@ func BenchmarkDefaultScheduler_Subscribe(b *testing.B) {
 	b.ResetTimer()
 
 	for n := 0; n < b.N; n++ {
-		s.Subscribe(ch)
+		s.Subscribe(context.Background(), ch)
 	}
 }
 
@@ -381,7 +395,7 @@ func TestDefaultScheduler_Suppress(t *testing.T) {
 
 	s := NewDefaultScheduler(c, i, l)
 
-	_, err := s.Suppress()
+	_, err := s.Suppress(context.Background())
 	if err != nil {
 		t.Fatal(err.Error())
 	}
@@ -390,7 +404,7 @@ func TestDefaultScheduler_Suppress(t *testing.T) {
 		t.Fatal("Offers were suppressed but the scheduler state does not show as suppressed")
 	}
 
-	resp, err := s.Suppress()
+	resp, err := s.Suppress(context.Background())
 	if resp != nil && err != nil {
 		t.Fatal("Suppressed state not set correctly")
 	}
@@ -402,7 +416,7 @@ func BenchmarkDefaultScheduler_Suppress(b *testing.B) {
 	b.ResetTimer()
 
 	for n := 0; n < b.N; n++ {
-		s.Suppress()
+		s.Suppress(context.Background())
 	}
 }
 
@@ -412,7 +426,7 @@ func TestDefaultScheduler_Teardown(t *testing.T) {
 
 	s := NewDefaultScheduler(c, i, l)
 
-	_, err := s.Teardown()
+	_, err := s.Teardown(context.Background())
 	if err != nil {
 		t.Fatal(err.Error())
 	}
@@ -424,6 +438,815 @@ func BenchmarkDefaultScheduler_Teardown(b *testing.B) {
 	b.ResetTimer()
 
 	for n := 0; n < b.N; n++ {
-		s.Teardown()
+		s.Teardown(context.Background())
+	}
+}
+
+// Tests our launch group call to Mesos.
+func TestDefaultScheduler_LaunchGroup(t *testing.T) {
+	t.Parallel()
+
+	s := NewDefaultScheduler(c, i, l)
+	executor := &mesos_v1.ExecutorInfo{ExecutorId: &mesos_v1.ExecutorID{Value: utils.ProtoString("executor")}}
+	group := &mesos_v1.TaskGroupInfo{Tasks: []*mesos_v1.TaskInfo{{
+		Name:    utils.ProtoString("task"),
+		TaskId:  &mesos_v1.TaskID{Value: utils.ProtoString("task")},
+		AgentId: &mesos_v1.AgentID{Value: utils.ProtoString("agent")},
+	}}}
+
+	_, err := s.LaunchGroup(context.Background(), executor, group, &mesos_v1.OfferID{Value: utils.ProtoString("offer")})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if executor.GetFrameworkId() != i.GetId() {
+		t.Fatal("Executor should be launched under our framework")
+	}
+
+	_, err = s.LaunchGroup(context.Background(), executor, &mesos_v1.TaskGroupInfo{})
+	if err == nil {
+		t.Fatal("Empty task groups should be rejected")
+	}
+}
+
+// Tests our reserve and unreserve calls to Mesos.
+func TestDefaultScheduler_Reserve(t *testing.T) {
+	t.Parallel()
+
+	s := NewDefaultScheduler(c, i, l)
+	offerIds := []*mesos_v1.OfferID{}
+	resources := []*mesos_v1.Resource{}
+
+	_, err := s.Reserve(context.Background(), offerIds, resources)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	_, err = s.Unreserve(context.Background(), offerIds, resources)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+}
+
+type countingClient struct {
+	mockClient
+	calls map[mesos_v1_scheduler.Call_Type]int
+}
+
+func (m *countingClient) RequestContext(_ context.Context, call interface{}) (*http.Response, error) {
+	m.calls[call.(*mesos_v1_scheduler.Call).GetType()]++
+	return new(http.Response), nil
+}
+
+// Ensures the coordinator suppresses and revives offers as tasks come and go.
+func TestOfferCoordinator(t *testing.T) {
+	t.Parallel()
+
+	client := &countingClient{calls: make(map[mesos_v1_scheduler.Call_Type]int)}
+	o := NewOfferCoordinator(NewDefaultScheduler(client, i, l), resourcemanager.NewDefaultResourceManager())
+
+	task := taskmanager.NewTask(&mesos_v1.TaskInfo{}, taskmanager.STAGING, nil, nil, 1, taskmanager.GroupInfo{})
+	if err := o.Queue(task); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := o.Update(); err != nil || o.Suppressed() {
+		t.Fatal("Offers should not be suppressed while tasks are pending")
+	}
+
+	if o.Dequeue() != task || o.Pending() != 0 || o.Dequeue() != nil {
+		t.Fatal("Pending tasks were not dequeued correctly")
+	}
+	if err := o.Update(); err != nil || !o.Suppressed() || client.calls[mesos_v1_scheduler.Call_SUPPRESS] != 1 {
+		t.Fatal("Offers were not suppressed with no resources or pending tasks")
+	}
+	if err := o.Update(); err != nil || client.calls[mesos_v1_scheduler.Call_SUPPRESS] != 1 {
+		t.Fatal("Offers should only be suppressed once")
+	}
+
+	if err := o.Queue(task); err != nil || o.Suppressed() || client.calls[mesos_v1_scheduler.Call_REVIVE] != 1 {
+		t.Fatal("Offers were not revived when a task was queued")
+	}
+}
+
+func queueTask(name, group, priority string) *taskmanager.Task {
+	task := taskmanager.NewTask(&mesos_v1.TaskInfo{Name: utils.ProtoString(name)}, taskmanager.STAGING, nil, nil, 1,
+		taskmanager.GroupInfo{GroupName: group, InGroup: group != ""})
+	task.Priority = priority
+	return task
+}
+
+func popNames(q *TaskQueue) string {
+	names := ""
+	for task := q.Pop(); task != nil; task = q.Pop() {
+		names += task.Info.GetName()
+	}
+	return names
+}
+
+// Ensures the queue is FIFO by default, launches higher priorities first and shares launches by group weight.
+func TestTaskQueue(t *testing.T) {
+	t.Parallel()
+
+	q := NewTaskQueue()
+	q.Push(queueTask("a", "x", ""), queueTask("b", "y", ""), queueTask("c", "x", ""))
+	if names := popNames(q); names != "abc" {
+		t.Fatal("Tasks should come out in FIFO order by default, got " + names)
+	}
+
+	q.SetPriority("high", 10)
+	q.Push(queueTask("a", "", ""), queueTask("b", "", "high"), queueTask("c", "", ""))
+	if names := popNames(q); names != "bac" {
+		t.Fatal("Higher priority tasks should come out first, got " + names)
+	}
+
+	q = NewTaskQueue()
+	q.SetWeight("x", 2)
+	q.SetWeight("y", 1)
+	q.Push(queueTask("1", "x", ""), queueTask("2", "x", ""), queueTask("3", "x", ""), queueTask("4", "x", ""))
+	q.Push(queueTask("5", "y", ""), queueTask("6", "y", ""))
+	if names := popNames(q); names != "152364" {
+		t.Fatal("Groups should share launches by weight, got " + names)
+	}
+
+	task := queueTask("a", "", "")
+	q.Push(task)
+	if !q.Remove(task) || q.Len() != 0 || q.Remove(task) {
+		t.Fatal("Removed tasks should leave the queue")
+	}
+}
+
+// Measures pushing and popping weighted tasks.
+func BenchmarkTaskQueue(b *testing.B) {
+	q := NewTaskQueue()
+	q.SetWeight("x", 2)
+	tasks := []*taskmanager.Task{queueTask("a", "x", ""), queueTask("b", "y", "")}
+	for n := 0; n < b.N; n++ {
+		q.Push(tasks...)
+		q.Pop()
+		q.Pop()
+	}
+}
+
+// Ensures the decline policy builds filters for each reason.
+func TestDeclinePolicy_Filters(t *testing.T) {
+	t.Parallel()
+
+	p := NewDeclinePolicy()
+	if p.Filters(DECLINE_NO_MATCH).GetRefuseSeconds() != mesos_v1.Default_Filters_RefuseSeconds {
+		t.Fatal("Default refuse seconds were not used")
+	}
+
+	p.SetRefuseSeconds(DECLINE_IDLE, 300)
+	if p.Filters(DECLINE_IDLE).GetRefuseSeconds() != 300 {
+		t.Fatal("Refuse seconds were not set for the reason")
+	}
+	if p.RefuseSeconds(DeclineReason(99)) != mesos_v1.Default_Filters_RefuseSeconds {
+		t.Fatal("Unknown reasons should fall back to the Mesos default")
+	}
+}
+
+// Measures performance of building decline filters.
+func BenchmarkDeclinePolicy_Filters(b *testing.B) {
+	p := NewDeclinePolicy()
+	for n := 0; n < b.N; n++ {
+		p.Filters(DECLINE_FILTERED)
+	}
+}
+
+type failingClient struct {
+	mockClient
+}
+
+func (m *failingClient) RequestContext(_ context.Context, _ interface{}) (*http.Response, error) {
+	return nil, errors.New("Master unavailable")
+}
+
+// Ensures the event stream is retried with backoff until stopped.
+func TestDefaultScheduler_SubscribeWithReconnect(t *testing.T) {
+	t.Parallel()
+
+	disconnects := make(chan error, 10)
+	policy := NewReconnectPolicy()
+	policy.MinBackoff = time.Millisecond
+	policy.MaxBackoff = 2 * time.Millisecond
+	policy.OnDisconnected = func(err error) {
+		disconnects <- err
+	}
+
+	s := NewDefaultScheduler(new(failingClient), &mesos_v1.FrameworkInfo{}, l)
+	stop := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		s.SubscribeWithReconnect(make(chan *mesos_v1_scheduler.Event), policy, stop)
+		close(finished)
+	}()
+
+	for i := 0; i < 3; i++ {
+		if err := <-disconnects; err == nil {
+			t.Fatal("Disconnects should report why they happened")
+		}
+	}
+	close(stop)
+	<-finished
+}
+
+// Body that streams the same record until the request is canceled.
+type streamingBody struct {
+	ctx    context.Context
+	record []byte
+	offset int
+	closed chan struct{}
+}
+
+func (b *streamingBody) Read(p []byte) (int, error) {
+	if err := b.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	n := copy(p, b.record[b.offset:])
+	b.offset = (b.offset + n) % len(b.record)
+	return n, nil
+}
+
+func (b *streamingBody) Close() error {
+	close(b.closed)
+	return nil
+}
+
+type streamingClient struct {
+	mockClient
+	record []byte
+	closed chan struct{}
+}
+
+func (m *streamingClient) RequestContext(ctx context.Context, _ interface{}) (*http.Response, error) {
+	return &http.Response{Body: &streamingBody{ctx: ctx, record: m.record, closed: m.closed}}, nil
+}
+
+// Ensures stopping while events are still streaming in lets the subscription finish and closes its body.
+func TestDefaultScheduler_SubscribeWithReconnectStop(t *testing.T) {
+	t.Parallel()
+
+	heartbeat := mesos_v1_scheduler.Event_HEARTBEAT
+	data, err := proto.Marshal(&mesos_v1_scheduler.Event{Type: &heartbeat})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	client := &streamingClient{
+		record: append([]byte(strconv.Itoa(len(data))+"\n"), data...),
+		closed: make(chan struct{}),
+	}
+
+	s := NewDefaultScheduler(client, &mesos_v1.FrameworkInfo{User: utils.ProtoString("test"), Name: utils.ProtoString("test")}, l)
+	events := make(chan *mesos_v1_scheduler.Event)
+	stop := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		s.SubscribeWithReconnect(events, NewReconnectPolicy(), stop)
+		close(finished)
+	}()
+
+	// Leave the decoder holding the next event when we stop.
+	<-events
+	close(stop)
+	<-finished
+
+	select {
+	case <-client.closed:
+	default:
+		t.Fatal("The subscription should have ended and its body been closed")
+	}
+}
+
+// Ensures reconnect backoff grows up to its maximum.
+func TestReconnectPolicy_Backoff(t *testing.T) {
+	t.Parallel()
+
+	p := NewReconnectPolicy()
+	if p.next(40*time.Second) != time.Minute || p.next(time.Second) != 2*time.Second {
+		t.Fatal("Backoff did not grow correctly")
+	}
+
+	for i := 0; i < 100; i++ {
+		if w := p.wait(time.Second); w < 800*time.Millisecond || w > 1200*time.Millisecond {
+			t.Fatal("Jitter is out of bounds")
+		}
+	}
+}
+
+// Ensures implicit and explicit reconciliation are sent when due.
+func TestReconciler(t *testing.T) {
+	t.Parallel()
+
+	client := &countingClient{calls: make(map[mesos_v1_scheduler.Call_Type]int)}
+	var statuses []*mesos_v1.TaskStatus
+	r := NewReconciler(NewDefaultScheduler(client, i, l), func(status *mesos_v1.TaskStatus) {
+		statuses = append(statuses, status)
+	})
+
+	now := time.Now()
+	if err := r.reconcile(now); err != nil || client.calls[mesos_v1_scheduler.Call_RECONCILE] != 1 {
+		t.Fatal("Implicit reconciliation was not sent")
+	}
+
+	id := &mesos_v1.TaskID{Value: utils.ProtoString("task")}
+	r.Track(&mesos_v1.TaskInfo{TaskId: id})
+	r.reconcile(now.Add(time.Second))
+	if client.calls[mesos_v1_scheduler.Call_RECONCILE] != 1 {
+		t.Fatal("Nothing should be reconciled before it's due")
+	}
+
+	r.reconcile(now.Add(r.MinBackoff + time.Second))
+	if client.calls[mesos_v1_scheduler.Call_RECONCILE] != 2 {
+		t.Fatal("Explicit reconciliation was not sent")
+	}
+	r.reconcile(now.Add(r.MinBackoff + 2*time.Second))
+	if client.calls[mesos_v1_scheduler.Call_RECONCILE] != 2 {
+		t.Fatal("Explicit reconciliation should back off")
+	}
+
+	r.Update(&mesos_v1.TaskStatus{TaskId: id})
+	if r.Pending() != 0 || len(statuses) != 1 {
+		t.Fatal("Status updates should stop reconciliation and reach the callback")
+	}
+}
+
+type flakyClient struct {
+	mockClient
+	fail  bool
+	calls int
+}
+
+func (m *flakyClient) RequestContext(_ context.Context, _ interface{}) (*http.Response, error) {
+	m.calls++
+	if m.fail {
+		return nil, errors.New("Master unavailable")
+	}
+	return new(http.Response), nil
+}
+
+// Ensures status updates are acknowledged, deduplicated and retried.
+func TestAckManager(t *testing.T) {
+	t.Parallel()
+
+	client := new(flakyClient)
+	a := NewAckManager(NewDefaultScheduler(client, i, l))
+	status := func(uuid string, state mesos_v1.TaskState) *mesos_v1.TaskStatus {
+		s := &mesos_v1.TaskStatus{
+			TaskId:  &mesos_v1.TaskID{Value: utils.ProtoString("task")},
+			AgentId: &mesos_v1.AgentID{Value: utils.ProtoString("agent")},
+			State:   state.Enum(),
+		}
+		if uuid != "" {
+			s.Uuid = []byte(uuid)
+		}
+		return s
+	}
+
+	if !a.Handle(status("1", mesos_v1.TaskState_TASK_RUNNING)) || client.calls != 1 {
+		t.Fatal("New updates should be acknowledged and delivered")
+	}
+	if a.Handle(status("1", mesos_v1.TaskState_TASK_RUNNING)) || client.calls != 2 {
+		t.Fatal("Duplicate updates should be acknowledged but not delivered")
+	}
+	if !a.Handle(status("", mesos_v1.TaskState_TASK_RUNNING)) || client.calls != 2 {
+		t.Fatal("Updates without a UUID should be delivered but not acknowledged")
+	}
+
+	client.fail = true
+	if !a.Handle(status("2", mesos_v1.TaskState_TASK_FAILED)) {
+		t.Fatal("Terminal states should be delivered once")
+	}
+	if a.Handle(status("3", mesos_v1.TaskState_TASK_FAILED)) {
+		t.Fatal("Repeated terminal states should not be delivered")
+	}
+	if a.Retry() != 2 {
+		t.Fatal("Failed acknowledgements should be kept for retrying")
+	}
+
+	client.fail = false
+	if a.Retry() != 0 {
+		t.Fatal("Retried acknowledgements should be cleared")
+	}
+
+	a.Forget(&mesos_v1.TaskID{Value: utils.ProtoString("task")})
+	if !a.Handle(status("4", mesos_v1.TaskState_TASK_FAILED)) {
+		t.Fatal("Forgotten tasks should have their terminal state delivered again")
+	}
+}
+
+// Ensures the terminal task history is bounded and forgotten tasks are pruned from it.
+func TestAckManager_Prune(t *testing.T) {
+	t.Parallel()
+
+	a := NewAckManager(NewDefaultScheduler(new(flakyClient), i, l))
+	failed := func(id string) *mesos_v1.TaskStatus {
+		return &mesos_v1.TaskStatus{
+			TaskId: &mesos_v1.TaskID{Value: utils.ProtoString(id)},
+			State:  mesos_v1.TaskState_TASK_FAILED.Enum(),
+		}
+	}
+
+	for n := 0; n <= ACK_HISTORY; n++ {
+		a.Handle(failed("task-" + strconv.Itoa(n)))
+	}
+	if len(a.terminal) != ACK_HISTORY || len(a.terminals) != ACK_HISTORY {
+		t.Fatal("Terminal tasks should be bounded by the history size")
+	}
+	if a.Handle(failed("task-" + strconv.Itoa(ACK_HISTORY))) {
+		t.Fatal("Recent terminal tasks should still be deduplicated")
+	}
+	if !a.Handle(failed("task-0")) {
+		t.Fatal("The oldest terminal task should have been pruned")
+	}
+
+	a.Forget(&mesos_v1.TaskID{Value: utils.ProtoString("task-0")})
+	if len(a.terminal) != ACK_HISTORY-1 || len(a.terminals) != ACK_HISTORY-1 {
+		t.Fatal("Forgotten tasks should be pruned from the history")
+	}
+	for _, id := range a.terminals {
+		if id == "task-0" {
+			t.Fatal("Forgotten tasks should not be left in the history")
+		}
+	}
+}
+
+// Measures performance of handling terminal updates for many tasks.
+func BenchmarkAckManager_Prune(b *testing.B) {
+	a := NewAckManager(NewDefaultScheduler(new(flakyClient), i, l))
+	for n := 0; n < b.N; n++ {
+		a.Handle(&mesos_v1.TaskStatus{
+			TaskId: &mesos_v1.TaskID{Value: utils.ProtoString("task-" + strconv.Itoa(n))},
+			State:  mesos_v1.TaskState_TASK_FAILED.Enum(),
+		})
+	}
+}
+
+type staticDetector string
+
+func (d staticDetector) Detect() (string, error) {
+	return string(d), nil
+}
+
+// Ensures the client is pointed at the detected leader.
+func TestDefaultScheduler_FollowLeader(t *testing.T) {
+	t.Parallel()
+
+	cl := client.NewClient(client.ClientData{Endpoint: "http://old:5050/api/v1/scheduler"}, l)
+	s := NewDefaultScheduler(cl, i, l)
+	s.followLeader(staticDetector("new:5050"))
+
+	if cl.(client.Redirectable).Endpoint() != "http://new:5050/api/v1/scheduler" {
+		t.Fatal("Client should follow the leading master")
+	}
+}
+
+type memoryStorage struct {
+	data map[string]string
+	sync.Mutex
+}
+
+func (m *memoryStorage) Create(key, value string) error {
+	return m.Update(key, value)
+}
+
+func (m *memoryStorage) CreateWithLease(key, value string, ttl int64) (int64, error) {
+	return 0, m.Update(key, value)
+}
+
+func (m *memoryStorage) Read(key string) (string, error) {
+	m.Lock()
+	defer m.Unlock()
+	return m.data[key], nil
+}
+
+func (m *memoryStorage) ReadAll(key string) (map[string]string, error) {
+	return nil, nil
+}
+
+func (m *memoryStorage) Update(key, value string) error {
+	m.Lock()
+	defer m.Unlock()
+	m.data[key] = value
+	return nil
+}
+
+func (m *memoryStorage) RefreshLease(int64) error {
+	return nil
+}
+
+func (m *memoryStorage) Delete(key string) error {
+	m.Lock()
+	defer m.Unlock()
+	delete(m.data, key)
+	return nil
+}
+
+// Ensures the framework ID is persisted, restored within the failover timeout and cleared when rejected.
+func TestRegistrationManager(t *testing.T) {
+	t.Parallel()
+
+	storage := &memoryStorage{data: make(map[string]string)}
+	info := &mesos_v1.FrameworkInfo{FailoverTimeout: utils.ProtoFloat64(60)}
+	r := NewRegistrationManager(NewDefaultScheduler(c, info, l), storage, FRAMEWORK_ID_KEY)
+
+	if err := r.Subscribed(&mesos_v1.FrameworkID{Value: utils.ProtoString("framework")}); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	info.Id = nil
+	if err := r.Load(); err != nil || info.GetId().GetValue() != "framework" {
+		t.Fatal("Framework ID should be restored within the failover timeout")
+	}
+
+	storage.data[FRAMEWORK_ID_KEY] = `{"id":"framework","seen":1}`
+	info.Id = nil
+	if err := r.Load(); err != nil || info.GetId() != nil || storage.data[FRAMEWORK_ID_KEY] != "" {
+		t.Fatal("Framework IDs past the failover timeout should be cleared")
+	}
+
+	r.Subscribed(&mesos_v1.FrameworkID{Value: utils.ProtoString("framework")})
+	if rejected, _ := r.Error(&mesos_v1_scheduler.Event_Error{Message: utils.ProtoString("Executor failed")}); rejected {
+		t.Fatal("Unrelated errors should not clear the framework ID")
+	}
+	if rejected, err := r.Error(&mesos_v1_scheduler.Event_Error{Message: utils.ProtoString("Framework has been removed")}); !rejected || err != nil || info.GetId() != nil {
+		t.Fatal("Framework ID should be cleared when the master rejects it")
+	}
+}
+
+// Ensures kills are re-issued until the task dies, time out when it never does and stop when cancelled.
+func TestKillManager_Kill(t *testing.T) {
+	t.Parallel()
+
+	client := &countingClient{calls: make(map[mesos_v1_scheduler.Call_Type]int)}
+	k := NewKillManager(NewDefaultScheduler(client, i, l))
+	k.Slack = 0
+	taskId := &mesos_v1.TaskID{Value: utils.ProtoString("task")}
+	agentId := &mesos_v1.AgentID{Value: utils.ProtoString("agent")}
+
+	err := k.Kill(context.Background(), taskId, agentId, time.Millisecond, 3)
+	if _, ok := err.(*KillTimeoutError); !ok || client.calls[mesos_v1_scheduler.Call_KILL] != 3 {
+		t.Fatal("Kills should be re-issued and time out if the task never dies")
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- k.Kill(context.Background(), taskId, agentId, time.Minute, 3)
+	}()
+
+	for !k.Update(&mesos_v1.TaskStatus{TaskId: taskId, State: mesos_v1.TaskState_TASK_KILLED.Enum()}) {
+		time.Sleep(time.Millisecond)
+	}
+	if err := <-done; err != nil {
+		t.Fatal("Kill should finish once the task dies: " + err.Error())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		done <- k.Kill(ctx, taskId, agentId, time.Minute, 3)
+	}()
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatal("Kill should stop waiting once its context is done")
+	}
+}
+
+type listTaskManager struct {
+	tasks []*taskmanager.Task
+}
+
+func (m *listTaskManager) Add(...*taskmanager.Task) error                          { return nil }
+func (m *listTaskManager) Restore(*taskmanager.Task)                               {}
+func (m *listTaskManager) Delete(...*taskmanager.Task) error                       { return nil }
+func (m *listTaskManager) Get(*string) (*taskmanager.Task, error)                  { return nil, nil }
+func (m *listTaskManager) GetGroup(*taskmanager.Task) ([]*taskmanager.Task, error) { return nil, nil }
+func (m *listTaskManager) GetById(*mesos_v1.TaskID) (*taskmanager.Task, error)     { return nil, nil }
+func (m *listTaskManager) HasTask(*mesos_v1.TaskInfo) bool                         { return false }
+func (m *listTaskManager) Update(...*taskmanager.Task) error                       { return nil }
+func (m *listTaskManager) AllByState(mesos_v1.TaskState) ([]*taskmanager.Task, error) {
+	return nil, nil
+}
+func (m *listTaskManager) TotalTasks() int                   { return len(m.tasks) }
+func (m *listTaskManager) All() ([]*taskmanager.Task, error) { return m.tasks, nil }
+
+// Ensures agents going into maintenance are drained and have their tasks migrated off them.
+func TestMaintenanceManager(t *testing.T) {
+	t.Parallel()
+
+	agent := &mesos_v1.AgentID{Value: utils.ProtoString("agent")}
+	running := taskmanager.NewTask(&mesos_v1.TaskInfo{
+		Name:    utils.ProtoString("running"),
+		TaskId:  &mesos_v1.TaskID{Value: utils.ProtoString("running")},
+		AgentId: agent,
+	}, taskmanager.RUNNING, nil, nil, 1, taskmanager.GroupInfo{})
+	finished := taskmanager.NewTask(&mesos_v1.TaskInfo{
+		Name:    utils.ProtoString("finished"),
+		TaskId:  &mesos_v1.TaskID{Value: utils.ProtoString("finished")},
+		AgentId: agent,
+	}, taskmanager.FINISHED, nil, nil, 1, taskmanager.GroupInfo{})
+
+	client := &countingClient{calls: make(map[mesos_v1_scheduler.Call_Type]int)}
+	r := resourcemanager.NewDefaultResourceManager()
+	m := NewMaintenanceManager(NewDefaultScheduler(client, i, l), r, &listTaskManager{tasks: []*taskmanager.Task{running, finished}})
+
+	var migrated []*taskmanager.Task
+	m.Migrate = func(task *taskmanager.Task) {
+		migrated = append(migrated, task)
+	}
+
+	event := &mesos_v1_scheduler.Event_InverseOffers{InverseOffers: []*mesos_v1.InverseOffer{{
+		Id:             &mesos_v1.OfferID{Value: utils.ProtoString("inverse")},
+		AgentId:        agent,
+		Unavailability: &mesos_v1.Unavailability{Start: &mesos_v1.TimeInfo{Nanoseconds: utils.ProtoInt64(time.Now().Add(time.Minute).UnixNano())}},
+	}}}
+	if err := m.InverseOffers(event); err != nil || len(migrated) != 0 || client.calls[mesos_v1_scheduler.Call_ACCEPT_INVERSE_OFFERS] != 0 {
+		t.Fatal("Tasks should only be migrated when asked to")
+	}
+	if r.Draining(agent) {
+		t.Fatal("Agents should not drain before their maintenance is within the drain horizon")
+	}
+
+	m.AutoMigrate = true
+	r.SetDrainHorizon(time.Hour)
+	if err := m.InverseOffers(event); err != nil || len(migrated) != 1 || migrated[0] != running {
+		t.Fatal("Only live tasks should be migrated off agents going into maintenance")
+	}
+	if !r.Draining(agent) || client.calls[mesos_v1_scheduler.Call_ACCEPT_INVERSE_OFFERS] != 1 {
+		t.Fatal("Agents should be drained and their inverse offers accepted")
+	}
+
+	m.RescindInverseOffer(&mesos_v1_scheduler.Event_RescindInverseOffer{InverseOfferId: &mesos_v1.OfferID{Value: utils.ProtoString("inverse")}})
+	if r.Draining(agent) {
+		t.Fatal("Agents should stop draining once their inverse offer is rescinded")
+	}
+}
+
+type acceptClient struct {
+	mockClient
+	accepts []*mesos_v1_scheduler.Call_Accept
+	sync.Mutex
+}
+
+func (m *acceptClient) RequestContext(_ context.Context, call interface{}) (*http.Response, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.accepts = append(m.accepts, call.(*mesos_v1_scheduler.Call).GetAccept())
+	return new(http.Response), nil
+}
+
+// Ensures operations on the same agent within the window are sent in a single Accept.
+func TestAcceptCoalescer(t *testing.T) {
+	t.Parallel()
+
+	client := new(acceptClient)
+	a := NewAcceptCoalescer(NewDefaultScheduler(client, i, l), time.Hour)
+	agent := func(id string) *mesos_v1.AgentID {
+		return &mesos_v1.AgentID{Value: utils.ProtoString(id)}
+	}
+	offer := []*mesos_v1.OfferID{{Value: utils.ProtoString("offer")}}
+	other := []*mesos_v1.OfferID{{Value: utils.ProtoString("other")}}
+	launch := resources.LaunchOfferOperation([]*mesos_v1.TaskInfo{{
+		Name:    utils.ProtoString("task"),
+		TaskId:  &mesos_v1.TaskID{Value: utils.ProtoString("task")},
+		AgentId: agent("a"),
+	}})
+
+	errs := make(chan error, 3)
+	go func() { errs <- a.Accept(context.Background(), agent("a"), offer, []*mesos_v1.Offer_Operation{launch}, nil) }()
+	go func() { errs <- a.Accept(context.Background(), agent("a"), offer, []*mesos_v1.Offer_Operation{launch}, nil) }()
+	go func() { errs <- a.Accept(context.Background(), agent("b"), other, []*mesos_v1.Offer_Operation{launch}, nil) }()
+	for a.Pending() != 3 {
+		time.Sleep(time.Millisecond)
+	}
+
+	a.Flush()
+	for n := 0; n < 3; n++ {
+		if err := <-errs; err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+
+	client.Lock()
+	defer client.Unlock()
+	if len(client.accepts) != 2 {
+		t.Fatal("Operations should be batched per agent")
+	}
+	for _, accept := range client.accepts {
+		if len(accept.GetOperations()) == 2 && len(accept.GetOfferIds()) != 1 {
+			t.Fatal("Offers shared by batched operations should only be accepted once")
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := a.Accept(ctx, agent("a"), offer, []*mesos_v1.Offer_Operation{launch}, nil); err != context.Canceled || a.Pending() != 1 {
+		t.Fatal("Callers should stop waiting once their context is done without losing the operations")
+	}
+}
+
+// Measures performance of coalescing an Accept.
+func BenchmarkAcceptCoalescer(b *testing.B) {
+	a := NewAcceptCoalescer(NewDefaultScheduler(new(acceptClient), i, l), 0)
+	agent := &mesos_v1.AgentID{Value: utils.ProtoString("agent")}
+	offer := []*mesos_v1.OfferID{{Value: utils.ProtoString("offer")}}
+	for n := 0; n < b.N; n++ {
+		a.Accept(context.Background(), agent, offer, nil, nil)
+	}
+}
+
+// Ensures interceptors wrap every call and event in the order they were added.
+func TestDefaultScheduler_Use(t *testing.T) {
+	t.Parallel()
+
+	s := NewDefaultScheduler(c, i, l)
+	var order []string
+	s.Use(func(ctx context.Context, call *mesos_v1_scheduler.Call, next Invoker) (*http.Response, error) {
+		order = append(order, "outer "+call.GetType().String())
+		return next(ctx, call)
+	}, func(ctx context.Context, call *mesos_v1_scheduler.Call, next Invoker) (*http.Response, error) {
+		order = append(order, "inner")
+		if call.GetType() == mesos_v1_scheduler.Call_SUPPRESS {
+			return nil, errors.New("injected")
+		}
+		return next(ctx, call)
+	})
+
+	if _, err := s.Suppress(context.Background()); err == nil || err.Error() != "injected" {
+		t.Fatal("Interceptors should be able to fail calls")
+	}
+	if len(order) != 2 || order[0] != "outer SUPPRESS" || order[1] != "inner" {
+		t.Fatalf("Unexpected interceptor order %v", order)
+	}
+
+	var dispatched []mesos_v1_scheduler.Event_Type
+	s.UseEvents(func(event *mesos_v1_scheduler.Event, next EventInvoker) {
+		dispatched = append(dispatched, event.GetType())
+		if event.GetType() != mesos_v1_scheduler.Event_HEARTBEAT {
+			next(event)
+		}
+	})
+
+	// Heartbeats are dropped before they reach the handler.
+	s.dispatch(nil, &mesos_v1_scheduler.Event{Type: mesos_v1_scheduler.Event_HEARTBEAT.Enum()})
+	if len(dispatched) != 1 {
+		t.Fatal("Event interceptors should see every event")
+	}
+}
+
+// Measures performance of a call through an interceptor.
+func BenchmarkDefaultScheduler_Use(b *testing.B) {
+	s := NewDefaultScheduler(c, i, l)
+	s.Use(func(ctx context.Context, call *mesos_v1_scheduler.Call, next Invoker) (*http.Response, error) {
+		return next(ctx, call)
+	})
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		s.request(context.Background(), &mesos_v1_scheduler.Call{})
+	}
+}
+
+// Ensures the framework ID can change while calls are being built.
+func TestDefaultScheduler_FrameworkID(t *testing.T) {
+	t.Parallel()
+
+	s := NewDefaultScheduler(c, &mesos_v1.FrameworkInfo{}, l)
+	if s.FrameworkID() != nil {
+		t.Fatal("A new framework should not have an ID")
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for n := 0; n < 100; n++ {
+			s.SetFrameworkID(&mesos_v1.FrameworkID{Value: utils.ProtoString("framework")})
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for n := 0; n < 100; n++ {
+			s.Teardown(context.Background())
+			s.Kill(context.Background(), &mesos_v1.TaskID{Value: utils.ProtoString("task")}, nil)
+		}
+	}()
+	wg.Wait()
+
+	if s.FrameworkID().GetValue() != "framework" {
+		t.Fatal("Framework ID was not set")
+	}
+
+	s.SetFrameworkID(nil)
+	if s.FrameworkInfo().GetId() != nil {
+		t.Fatal("Framework ID should have been cleared")
+	}
+}
+
+// Measures performance of reading the framework ID.
+func BenchmarkDefaultScheduler_FrameworkID(b *testing.B) {
+	s := NewDefaultScheduler(c, i, l)
+	for n := 0; n < b.N; n++ {
+		s.FrameworkID()
 	}
 }
@@ -0,0 +1,189 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"github.com/verizonlabs/mesos-framework-sdk/client"
+	"github.com/verizonlabs/mesos-framework-sdk/detector"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	sched "github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_scheduler"
+	"github.com/verizonlabs/mesos-framework-sdk/logging"
+	"math/rand"
+	"net/url"
+	"time"
+)
+
+// Controls how the event stream is re-established after it drops.
+type ReconnectPolicy struct {
+	MinBackoff     time.Duration                           // Wait before the first retry, also used again once connected.
+	MaxBackoff     time.Duration                           // Upper bound for the exponential backoff.
+	Jitter         float64                                 // Fraction of each wait that is randomized, between 0 and 1.
+	OnConnected    func(frameworkId *mesos_v1.FrameworkID) // Called whenever we're subscribed.
+	OnDisconnected func(err error)                         // Called whenever the stream drops.
+	Detector       detector.MasterDetector                 // Finds the leader before every subscription, if set.
+}
+
+// Creates a reconnect policy with sensible defaults.
+func NewReconnectPolicy() *ReconnectPolicy {
+	return &ReconnectPolicy{
+		MinBackoff: time.Second,
+		MaxBackoff: time.Minute,
+		Jitter:     0.2,
+	}
+}
+
+// Gets how long to wait before the next attempt, randomized by the policy's jitter.
+func (p *ReconnectPolicy) wait(backoff time.Duration) time.Duration {
+	if p.Jitter <= 0 {
+		return backoff
+	}
+
+	jitter := time.Duration(float64(backoff) * p.Jitter * (rand.Float64()*2 - 1))
+	return backoff + jitter
+}
+
+// Doubles the backoff, up to the policy's maximum.
+func (p *ReconnectPolicy) next(backoff time.Duration) time.Duration {
+	backoff *= 2
+	if backoff > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+
+	return backoff
+}
+
+// Subscribes to the event stream and keeps re-subscribing with exponential backoff whenever it drops, until stop is closed.
+// The framework ID from each SUBSCRIBED event is kept so that re-subscribing fails over the same framework.
+// With a detector set the client is pointed at the current leader first, so a master failover is followed
+// as soon as the old leader drops the stream.
+// This blocks and is meant to be run in its own goroutine.
+func (c *DefaultScheduler) SubscribeWithReconnect(eventChan chan *sched.Event, policy *ReconnectPolicy, stop <-chan struct{}) {
+	// Dropping the subscription as soon as we're stopped keeps it from lingering until the master ends it.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stop
+		cancel()
+	}()
+
+	backoff := policy.MinBackoff
+	for {
+		if policy.Detector != nil {
+			c.followLeader(policy.Detector)
+		}
+
+		events := make(chan *sched.Event)
+		done := make(chan error, 1)
+		go func() {
+			resp, err := c.Subscribe(ctx, events)
+			if resp != nil && resp.Body != nil {
+				resp.Body.Close()
+			}
+			done <- err
+		}()
+
+		connected, stopped, err := c.forwardEvents(events, done, eventChan, policy, stop)
+		if stopped {
+			// The decoder can be blocked handing over an event, keep taking them until it sees we're canceled.
+			for {
+				select {
+				case <-events:
+				case <-done:
+					return
+				}
+			}
+		}
+
+		// Start over with the shortest wait if we managed to subscribe this time around.
+		if connected {
+			backoff = policy.MinBackoff
+		}
+
+		c.logger.Emit(logging.ERROR, "Event stream disconnected: %v", err)
+		if policy.OnDisconnected != nil {
+			policy.OnDisconnected(err)
+		}
+
+		select {
+		case <-time.After(policy.wait(backoff)):
+		case <-stop:
+			return
+		}
+		backoff = policy.next(backoff)
+	}
+}
+
+// Hands events from a single subscription over to the caller until the subscription ends.
+// Reports whether we were subscribed and whether we were told to stop.
+func (c *DefaultScheduler) forwardEvents(events chan *sched.Event, done chan error, eventChan chan *sched.Event,
+	policy *ReconnectPolicy, stop <-chan struct{}) (connected, stopped bool, err error) {
+
+	for {
+		select {
+		case event := <-events:
+			if event.GetType() == sched.Event_SUBSCRIBED {
+				id := event.GetSubscribed().GetFrameworkId()
+				c.SetFrameworkID(id)
+
+				connected = true
+				c.logger.Emit(logging.INFO, "Subscribed with framework ID %s", id.GetValue())
+				if policy.OnConnected != nil {
+					policy.OnConnected(id)
+				}
+			}
+
+			select {
+			case eventChan <- event:
+			case <-stop:
+				return connected, true, nil
+			}
+		case err = <-done:
+			return connected, false, err
+		case <-stop:
+			return connected, true, nil
+		}
+	}
+}
+
+// Points the client at the leading master if it isn't already, keeping the scheme and path of the current endpoint.
+func (c *DefaultScheduler) followLeader(d detector.MasterDetector) {
+	r, ok := c.Client.(client.Redirectable)
+	if !ok {
+		return
+	}
+
+	leader, err := d.Detect()
+	if err != nil {
+		c.logger.Emit(logging.ERROR, "Failed to detect the leading master: %s", err.Error())
+		return
+	}
+
+	endpoint, err := url.Parse(r.Endpoint())
+	if err != nil || endpoint.Host == leader {
+		return
+	}
+
+	if endpoint.Scheme == "" {
+		endpoint.Scheme = "http"
+	}
+	if endpoint.Path == "" {
+		endpoint.Path = "/api/v1/scheduler"
+	}
+	endpoint.Host = leader
+
+	c.logger.Emit(logging.INFO, "Following leading master at %s", leader)
+	r.SetEndpoint(endpoint.String())
+}
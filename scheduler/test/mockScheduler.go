@@ -15,6 +15,7 @@
 package test
 
 import (
+	"context"
 	"errors"
 	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
 	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_scheduler"
@@ -35,52 +36,90 @@ func (m MockScheduler) FrameworkInfo() *mesos_v1.FrameworkInfo {
 	return &mesos_v1.FrameworkInfo{}
 }
 
-func (m MockScheduler) Subscribe(chan *mesos_v1_scheduler.Event) (*http.Response, error) {
+func (m MockScheduler) FrameworkID() *mesos_v1.FrameworkID {
+	return nil
+}
+
+func (m MockScheduler) SetFrameworkID(id *mesos_v1.FrameworkID) {}
+
+func (m MockScheduler) Subscribe(ctx context.Context, eventChan chan *mesos_v1_scheduler.Event) (*http.Response, error) {
+
+	return new(http.Response), nil
+}
+
+func (m MockScheduler) Teardown(ctx context.Context) (*http.Response, error) {
+	return new(http.Response), nil
+}
+
+func (m MockScheduler) Accept(ctx context.Context, offerIds []*mesos_v1.OfferID, tasks []*mesos_v1.Offer_Operation, filters *mesos_v1.Filters) (*http.Response, error) {
+	return new(http.Response), nil
+}
+
+func (m MockScheduler) Decline(ctx context.Context, offerIds []*mesos_v1.OfferID, filters *mesos_v1.Filters) (*http.Response, error) {
+	return new(http.Response), nil
+}
+
+func (m MockScheduler) AcceptInverseOffers(ctx context.Context, inverseOfferIds []*mesos_v1.OfferID, filters *mesos_v1.Filters) (*http.Response, error) {
+	return new(http.Response), nil
+}
+
+func (m MockScheduler) DeclineInverseOffers(ctx context.Context, inverseOfferIds []*mesos_v1.OfferID, filters *mesos_v1.Filters) (*http.Response, error) {
+	return new(http.Response), nil
+}
+
+func (m MockScheduler) LaunchGroup(ctx context.Context, executor *mesos_v1.ExecutorInfo, group *mesos_v1.TaskGroupInfo, offerIds ...*mesos_v1.OfferID) (*http.Response, error) {
+	return new(http.Response), nil
+}
+
+func (m MockScheduler) Reserve(ctx context.Context, offerIds []*mesos_v1.OfferID, resources []*mesos_v1.Resource) (*http.Response, error) {
+	return new(http.Response), nil
+}
 
+func (m MockScheduler) Unreserve(ctx context.Context, offerIds []*mesos_v1.OfferID, resources []*mesos_v1.Resource) (*http.Response, error) {
 	return new(http.Response), nil
 }
 
-func (m MockScheduler) Teardown() (*http.Response, error) {
+func (m MockScheduler) CreateVolumes(ctx context.Context, offerIds []*mesos_v1.OfferID, volumes []*mesos_v1.Resource) (*http.Response, error) {
 	return new(http.Response), nil
 }
 
-func (m MockScheduler) Accept(offerIds []*mesos_v1.OfferID, tasks []*mesos_v1.Offer_Operation, filters *mesos_v1.Filters) (*http.Response, error) {
+func (m MockScheduler) DestroyVolumes(ctx context.Context, offerIds []*mesos_v1.OfferID, volumes []*mesos_v1.Resource) (*http.Response, error) {
 	return new(http.Response), nil
 }
 
-func (m MockScheduler) Decline(offerIds []*mesos_v1.OfferID, filters *mesos_v1.Filters) (*http.Response, error) {
+func (m MockScheduler) Revive(ctx context.Context) (*http.Response, error) {
 	return new(http.Response), nil
 }
 
-func (m MockScheduler) Revive() (*http.Response, error) {
+func (m MockScheduler) Kill(ctx context.Context, taskId *mesos_v1.TaskID, agentid *mesos_v1.AgentID) (*http.Response, error) {
 	return new(http.Response), nil
 }
 
-func (m MockScheduler) Kill(taskId *mesos_v1.TaskID, agentid *mesos_v1.AgentID) (*http.Response, error) {
+func (m MockScheduler) KillWithPolicy(ctx context.Context, taskId *mesos_v1.TaskID, agentId *mesos_v1.AgentID, policy *mesos_v1.KillPolicy) (*http.Response, error) {
 	return new(http.Response), nil
 }
 
-func (m MockScheduler) Shutdown(execId *mesos_v1.ExecutorID, agentId *mesos_v1.AgentID) (*http.Response, error) {
+func (m MockScheduler) Shutdown(ctx context.Context, execId *mesos_v1.ExecutorID, agentId *mesos_v1.AgentID) (*http.Response, error) {
 	return new(http.Response), nil
 }
 
-func (m MockScheduler) Acknowledge(agentId *mesos_v1.AgentID, taskId *mesos_v1.TaskID, uuid []byte) (*http.Response, error) {
+func (m MockScheduler) Acknowledge(ctx context.Context, agentId *mesos_v1.AgentID, taskId *mesos_v1.TaskID, uuid []byte) (*http.Response, error) {
 	return new(http.Response), nil
 }
 
-func (m MockScheduler) Reconcile(tasks []*mesos_v1.TaskInfo) (*http.Response, error) {
+func (m MockScheduler) Reconcile(ctx context.Context, tasks []*mesos_v1.TaskInfo) (*http.Response, error) {
 	return new(http.Response), nil
 }
 
-func (m MockScheduler) Message(agentId *mesos_v1.AgentID, executorId *mesos_v1.ExecutorID, data []byte) (*http.Response, error) {
+func (m MockScheduler) Message(ctx context.Context, agentId *mesos_v1.AgentID, executorId *mesos_v1.ExecutorID, data []byte) (*http.Response, error) {
 	return new(http.Response), nil
 }
 
-func (m MockScheduler) SchedRequest(resources []*mesos_v1.Request) (*http.Response, error) {
+func (m MockScheduler) SchedRequest(ctx context.Context, resources []*mesos_v1.Request) (*http.Response, error) {
 	return new(http.Response), nil
 }
 
-func (m MockScheduler) Suppress() (*http.Response, error) {
+func (m MockScheduler) Suppress(ctx context.Context) (*http.Response, error) {
 	return new(http.Response), nil
 }
 
@@ -90,50 +129,88 @@ func (m MockBrokenScheduler) FrameworkInfo() *mesos_v1.FrameworkInfo {
 	return nil
 }
 
-func (m MockBrokenScheduler) Subscribe(chan *mesos_v1_scheduler.Event) (*http.Response, error) {
+func (m MockBrokenScheduler) FrameworkID() *mesos_v1.FrameworkID {
+	return nil
+}
+
+func (m MockBrokenScheduler) SetFrameworkID(id *mesos_v1.FrameworkID) {}
+
+func (m MockBrokenScheduler) Subscribe(ctx context.Context, eventChan chan *mesos_v1_scheduler.Event) (*http.Response, error) {
+	return new(http.Response), errors.New("Broken.")
+}
+
+func (m MockBrokenScheduler) Teardown(ctx context.Context) (*http.Response, error) {
+	return new(http.Response), errors.New("Broken.")
+}
+
+func (m MockBrokenScheduler) Accept(ctx context.Context, offerIds []*mesos_v1.OfferID, tasks []*mesos_v1.Offer_Operation, filters *mesos_v1.Filters) (*http.Response, error) {
+	return new(http.Response), errors.New("Broken.")
+}
+
+func (m MockBrokenScheduler) Decline(ctx context.Context, offerIds []*mesos_v1.OfferID, filters *mesos_v1.Filters) (*http.Response, error) {
+	return new(http.Response), errors.New("Broken.")
+}
+
+func (m MockBrokenScheduler) AcceptInverseOffers(ctx context.Context, inverseOfferIds []*mesos_v1.OfferID, filters *mesos_v1.Filters) (*http.Response, error) {
+	return new(http.Response), errors.New("Broken.")
+}
+
+func (m MockBrokenScheduler) DeclineInverseOffers(ctx context.Context, inverseOfferIds []*mesos_v1.OfferID, filters *mesos_v1.Filters) (*http.Response, error) {
+	return new(http.Response), errors.New("Broken.")
+}
+
+func (m MockBrokenScheduler) LaunchGroup(ctx context.Context, executor *mesos_v1.ExecutorInfo, group *mesos_v1.TaskGroupInfo, offerIds ...*mesos_v1.OfferID) (*http.Response, error) {
+	return new(http.Response), errors.New("Broken.")
+}
+
+func (m MockBrokenScheduler) Reserve(ctx context.Context, offerIds []*mesos_v1.OfferID, resources []*mesos_v1.Resource) (*http.Response, error) {
+	return new(http.Response), errors.New("Broken.")
+}
+
+func (m MockBrokenScheduler) Unreserve(ctx context.Context, offerIds []*mesos_v1.OfferID, resources []*mesos_v1.Resource) (*http.Response, error) {
 	return new(http.Response), errors.New("Broken.")
 }
 
-func (m MockBrokenScheduler) Teardown() (*http.Response, error) {
+func (m MockBrokenScheduler) CreateVolumes(ctx context.Context, offerIds []*mesos_v1.OfferID, volumes []*mesos_v1.Resource) (*http.Response, error) {
 	return new(http.Response), errors.New("Broken.")
 }
 
-func (m MockBrokenScheduler) Accept(offerIds []*mesos_v1.OfferID, tasks []*mesos_v1.Offer_Operation, filters *mesos_v1.Filters) (*http.Response, error) {
+func (m MockBrokenScheduler) DestroyVolumes(ctx context.Context, offerIds []*mesos_v1.OfferID, volumes []*mesos_v1.Resource) (*http.Response, error) {
 	return new(http.Response), errors.New("Broken.")
 }
 
-func (m MockBrokenScheduler) Decline(offerIds []*mesos_v1.OfferID, filters *mesos_v1.Filters) (*http.Response, error) {
+func (m MockBrokenScheduler) Revive(ctx context.Context) (*http.Response, error) {
 	return new(http.Response), errors.New("Broken.")
 }
 
-func (m MockBrokenScheduler) Revive() (*http.Response, error) {
+func (m MockBrokenScheduler) Kill(ctx context.Context, taskId *mesos_v1.TaskID, agentid *mesos_v1.AgentID) (*http.Response, error) {
 	return new(http.Response), errors.New("Broken.")
 }
 
-func (m MockBrokenScheduler) Kill(taskId *mesos_v1.TaskID, agentid *mesos_v1.AgentID) (*http.Response, error) {
+func (m MockBrokenScheduler) KillWithPolicy(ctx context.Context, taskId *mesos_v1.TaskID, agentId *mesos_v1.AgentID, policy *mesos_v1.KillPolicy) (*http.Response, error) {
 	return new(http.Response), errors.New("Broken.")
 }
 
-func (m MockBrokenScheduler) Shutdown(execId *mesos_v1.ExecutorID, agentId *mesos_v1.AgentID) (*http.Response, error) {
+func (m MockBrokenScheduler) Shutdown(ctx context.Context, execId *mesos_v1.ExecutorID, agentId *mesos_v1.AgentID) (*http.Response, error) {
 	return new(http.Response), errors.New("Broken.")
 }
 
-func (m MockBrokenScheduler) Acknowledge(agentId *mesos_v1.AgentID, taskId *mesos_v1.TaskID, uuid []byte) (*http.Response, error) {
+func (m MockBrokenScheduler) Acknowledge(ctx context.Context, agentId *mesos_v1.AgentID, taskId *mesos_v1.TaskID, uuid []byte) (*http.Response, error) {
 	return new(http.Response), errors.New("Broken.")
 }
 
-func (m MockBrokenScheduler) Reconcile(tasks []*mesos_v1.TaskInfo) (*http.Response, error) {
+func (m MockBrokenScheduler) Reconcile(ctx context.Context, tasks []*mesos_v1.TaskInfo) (*http.Response, error) {
 	return new(http.Response), errors.New("Broken.")
 }
 
-func (m MockBrokenScheduler) Message(agentId *mesos_v1.AgentID, executorId *mesos_v1.ExecutorID, data []byte) (*http.Response, error) {
+func (m MockBrokenScheduler) Message(ctx context.Context, agentId *mesos_v1.AgentID, executorId *mesos_v1.ExecutorID, data []byte) (*http.Response, error) {
 	return new(http.Response), errors.New("Broken.")
 }
 
-func (m MockBrokenScheduler) SchedRequest(resources []*mesos_v1.Request) (*http.Response, error) {
+func (m MockBrokenScheduler) SchedRequest(ctx context.Context, resources []*mesos_v1.Request) (*http.Response, error) {
 	return new(http.Response), errors.New("Broken.")
 }
 
-func (m MockBrokenScheduler) Suppress() (*http.Response, error) {
+func (m MockBrokenScheduler) Suppress(ctx context.Context) (*http.Response, error) {
 	return new(http.Response), errors.New("Broken.")
 }
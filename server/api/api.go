@@ -0,0 +1,304 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"github.com/verizonlabs/mesos-framework-sdk/logging"
+	"github.com/verizonlabs/mesos-framework-sdk/scheduler"
+	"github.com/verizonlabs/mesos-framework-sdk/server"
+	"github.com/verizonlabs/mesos-framework-sdk/server/management"
+	"github.com/verizonlabs/mesos-framework-sdk/task"
+	"github.com/verizonlabs/mesos-framework-sdk/task/manager"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Routes served by the API.
+const (
+	TASKS_ENDPOINT     = "/v1/tasks"
+	FRAMEWORK_ENDPOINT = "/v1/framework"
+	HEALTH_ENDPOINT    = "/health"
+	READY_ENDPOINT     = "/ready"
+)
+
+// A task as it's listed by the API.
+type TaskJSON struct {
+	Name    string `json:"name"`
+	Id      string `json:"id"`
+	State   string `json:"state"`
+	Agent   string `json:"agent,omitempty"`
+	Group   string `json:"group,omitempty"`
+	Healthy *bool  `json:"healthy,omitempty"`
+	Killing bool   `json:"killing,omitempty"`
+}
+
+type FrameworkJSON struct {
+	Id     string         `json:"id"`
+	Name   string         `json:"name"`
+	Role   string         `json:"role,omitempty"`
+	Tasks  int            `json:"tasks"`
+	States map[string]int `json:"states"`
+}
+
+type errorJSON struct {
+	Error  string            `json:"error"`
+	Fields []task.FieldError `json:"fields,omitempty"`
+}
+
+// A JSON management API for a framework, backed by its task manager and scheduler.
+// Health and readiness are always served without authentication so orchestrators can probe them.
+type APIServer struct {
+	cfg       server.Configuration
+	mux       *http.ServeMux
+	tasks     manager.TaskManager
+	scheduler scheduler.Scheduler
+	logger    logging.Logger
+
+	// Turns submitted task JSON into a task, frameworks decide how their JSON maps onto task info.
+	// Submitting is disabled when this isn't set.
+	Build func(app *task.ApplicationJSON) (*manager.Task, error)
+
+	// Called with submitted tasks once they're in the task manager, usually an offer coordinator's Queue method.
+	Queue func(...*manager.Task) error
+
+	// Checks every request except health and readiness, every request is allowed when this isn't set.
+	Auth Authenticator
+
+	// Reports whether the framework is ready, by default it's ready once it has a framework ID.
+	Ready func() bool
+}
+
+func NewAPIServer(cfg server.Configuration, tasks manager.TaskManager, s scheduler.Scheduler, logger logging.Logger) *APIServer {
+	a := &APIServer{
+		cfg:       cfg,
+		mux:       http.NewServeMux(),
+		tasks:     tasks,
+		scheduler: s,
+		logger:    logger,
+	}
+
+	a.mux.HandleFunc(TASKS_ENDPOINT, a.authenticated(a.tasksHandler))
+	a.mux.HandleFunc(TASKS_ENDPOINT+"/", a.authenticated(a.taskHandler))
+	a.mux.HandleFunc(FRAMEWORK_ENDPOINT, a.authenticated(a.framework))
+	a.mux.HandleFunc(HEALTH_ENDPOINT, a.health)
+	a.mux.HandleFunc(READY_ENDPOINT, a.ready)
+
+	return a
+}
+
+// Gets the API's routes so they can be mounted on another server.
+// Frameworks can also add their own routes to the returned mux.
+func (a *APIServer) Handler() *http.ServeMux {
+	return a.mux
+}
+
+// Serves the API with or without TLS depending on the configuration.
+// This blocks until the server stops and returns the reason it did.
+func (a *APIServer) Serve() error {
+	srv := a.cfg.Server()
+	srv.Handler = a.mux
+	srv.Addr = ":" + strconv.Itoa(a.cfg.Port())
+	if a.cfg.TLS() {
+		return srv.ListenAndServeTLS(a.cfg.Cert(), a.cfg.Key())
+	}
+
+	return srv.ListenAndServe()
+}
+
+func (a *APIServer) authenticated(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if a.Auth != nil && !a.Auth.Authenticate(r) {
+			a.fail(w, http.StatusUnauthorized, "Unauthorized.")
+			return
+		}
+		if a.cfg != nil && a.cfg.TLS() {
+			w.Header().Add("Strict-Transport-Security", "max-age=63072000; includeSubDomains")
+		}
+
+		handler(w, r)
+	}
+}
+
+// Lists every task or submits a new one.
+func (a *APIServer) tasksHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		all, err := a.tasks.All()
+		if err != nil {
+			a.fail(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		list := make(byName, 0, len(all))
+		for _, t := range all {
+			list = append(list, view(t))
+		}
+		sort.Sort(list)
+		a.write(w, http.StatusOK, list)
+	case http.MethodPost:
+		a.submit(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		a.fail(w, http.StatusMethodNotAllowed, "Method not allowed.")
+	}
+}
+
+// Gets or kills a single task by name.
+func (a *APIServer) taskHandler(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, TASKS_ENDPOINT+"/")
+	t, err := a.tasks.Get(&name)
+	if err != nil || t == nil {
+		a.fail(w, http.StatusNotFound, "There's no task named "+name+".")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		a.write(w, http.StatusOK, view(t))
+	case http.MethodDelete:
+		a.kill(w, r, t)
+	default:
+		w.Header().Set("Allow", "GET, DELETE")
+		a.fail(w, http.StatusMethodNotAllowed, "Method not allowed.")
+	}
+}
+
+func (a *APIServer) submit(w http.ResponseWriter, r *http.Request) {
+	if a.Build == nil {
+		a.fail(w, http.StatusNotImplemented, "This framework doesn't accept tasks.")
+		return
+	}
+
+	app := &task.ApplicationJSON{}
+	if err := json.NewDecoder(r.Body).Decode(app); err != nil {
+		a.fail(w, http.StatusBadRequest, "Invalid task JSON: "+err.Error())
+		return
+	}
+	if err := app.Validate(); err != nil {
+		fields, _ := err.(task.ValidationErrors)
+		a.write(w, http.StatusBadRequest, errorJSON{Error: "Invalid task.", Fields: fields})
+		return
+	}
+
+	t, err := a.Build(app)
+	if err != nil {
+		a.fail(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := management.Submit(a.tasks, a.Queue, a.logger, t); err != nil {
+		if err == manager.ErrTaskExists {
+			a.fail(w, http.StatusConflict, err.Error())
+			return
+		}
+		a.fail(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	a.write(w, http.StatusCreated, view(t))
+}
+
+// Tasks that are still being killed are reported as accepted.
+func (a *APIServer) kill(w http.ResponseWriter, r *http.Request, t *manager.Task) {
+	removed, err := management.Kill(r.Context(), a.tasks, a.scheduler, t)
+	if _, ok := err.(management.KillError); ok {
+		a.fail(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	if err != nil {
+		a.fail(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if removed {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	a.write(w, http.StatusAccepted, view(t))
+}
+
+func (a *APIServer) framework(w http.ResponseWriter, r *http.Request) {
+	info := a.scheduler.FrameworkInfo()
+	all, err := a.tasks.All()
+	if err != nil {
+		a.fail(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	states := make(map[string]int)
+	for _, t := range all {
+		states[t.State.String()]++
+	}
+
+	a.write(w, http.StatusOK, FrameworkJSON{
+		Id:     a.scheduler.FrameworkID().GetValue(),
+		Name:   info.GetName(),
+		Role:   info.GetRole(),
+		Tasks:  len(all),
+		States: states,
+	})
+}
+
+// The process is up if it can answer.
+func (a *APIServer) health(w http.ResponseWriter, r *http.Request) {
+	a.write(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (a *APIServer) ready(w http.ResponseWriter, r *http.Request) {
+	ready := a.Ready
+	if ready == nil {
+		ready = func() bool {
+			return a.scheduler.FrameworkID().GetValue() != ""
+		}
+	}
+
+	if !ready() {
+		a.write(w, http.StatusServiceUnavailable, map[string]string{"status": "not ready"})
+		return
+	}
+	a.write(w, http.StatusOK, map[string]string{"status": "ready"})
+}
+
+func (a *APIServer) write(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		a.logger.Emit(logging.ERROR, "Failed to write API response: %s", err.Error())
+	}
+}
+
+func (a *APIServer) fail(w http.ResponseWriter, code int, msg string) {
+	a.write(w, code, errorJSON{Error: msg})
+}
+
+func view(t *manager.Task) TaskJSON {
+	return TaskJSON{
+		Name:    t.Info.GetName(),
+		Id:      t.Info.GetTaskId().GetValue(),
+		State:   t.State.String(),
+		Agent:   t.Info.GetAgentId().GetValue(),
+		Group:   t.GroupInfo.GroupName,
+		Healthy: t.Healthy,
+		Killing: t.IsKill,
+	}
+}
+
+type byName []TaskJSON
+
+func (b byName) Len() int           { return len(b) }
+func (b byName) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byName) Less(i, j int) bool { return b[i].Name < b[j].Name }
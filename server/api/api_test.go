@@ -0,0 +1,198 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/verizonlabs/mesos-framework-sdk/bus"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	logtest "github.com/verizonlabs/mesos-framework-sdk/logging/test"
+	"github.com/verizonlabs/mesos-framework-sdk/scheduler/test"
+	servertest "github.com/verizonlabs/mesos-framework-sdk/server/test"
+	"github.com/verizonlabs/mesos-framework-sdk/task"
+	"github.com/verizonlabs/mesos-framework-sdk/task/manager"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const app = `{"name": "web", "resources": {"cpu": 0.5, "mem": 128, "disk": {"size": 10}}, "command": {"cmd": "sleep 60"}}`
+
+func build(app *task.ApplicationJSON) (*manager.Task, error) {
+	return manager.NewTask(&mesos_v1.TaskInfo{
+		Name:   utils.ProtoString(app.Name),
+		TaskId: &mesos_v1.TaskID{Value: utils.ProtoString(app.Name + "-id")},
+	}, manager.STAGING, nil, nil, 1, manager.GroupInfo{}), nil
+}
+
+func serve(a *APIServer, method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	r.SetBasicAuth("admin", "secret")
+	a.Handler().ServeHTTP(w, r)
+	return w
+}
+
+func newServer() (*APIServer, manager.TaskManager) {
+	tasks := manager.NewDefaultTaskManager(nil, manager.TASK_PREFIX)
+	a := NewAPIServer(new(servertest.MockServerConfiguration), tasks, test.NewMockScheduler(), new(logtest.MockLogger))
+	a.Build = build
+	a.Auth = BasicAuth("admin", "secret")
+	return a, tasks
+}
+
+// Ensures tasks can be submitted, listed and killed through the API.
+func TestAPIServer(t *testing.T) {
+	t.Parallel()
+
+	a, tasks := newServer()
+	var queued []*manager.Task
+	a.Queue = func(t ...*manager.Task) error {
+		queued = append(queued, t...)
+		return nil
+	}
+
+	if w := serve(a, "POST", TASKS_ENDPOINT, `{"name": "web"}`); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "resources") {
+		t.Fatal("Invalid tasks should be rejected with the invalid fields")
+	}
+	if w := serve(a, "POST", TASKS_ENDPOINT, app); w.Code != http.StatusCreated || len(queued) != 1 || tasks.TotalTasks() != 1 {
+		t.Fatalf("Expected the task to be created and queued, got %d", w.Code)
+	}
+	if w := serve(a, "POST", TASKS_ENDPOINT, app); w.Code != http.StatusConflict {
+		t.Fatal("Duplicate tasks should conflict")
+	}
+
+	w := serve(a, "GET", TASKS_ENDPOINT, "")
+	var list []TaskJSON
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil || len(list) != 1 || list[0].Name != "web" || list[0].State != "TASK_STAGING" {
+		t.Fatal("Unexpected task list " + w.Body.String())
+	}
+	if w := serve(a, "GET", TASKS_ENDPOINT+"/nothing", ""); w.Code != http.StatusNotFound {
+		t.Fatal("Unknown tasks should not be found")
+	}
+
+	web, _ := tasks.Get(utils.ProtoString("web"))
+	web.Info.AgentId = &mesos_v1.AgentID{Value: utils.ProtoString("agent")}
+	if w := serve(a, "DELETE", TASKS_ENDPOINT+"/web", ""); w.Code != http.StatusAccepted || !web.IsKill {
+		t.Fatal("Launched tasks should be killed")
+	}
+
+	web.Info.AgentId = nil
+	if w := serve(a, "DELETE", TASKS_ENDPOINT+"/web", ""); w.Code != http.StatusNoContent || tasks.TotalTasks() != 0 {
+		t.Fatal("Tasks that were never launched should be removed")
+	}
+
+	w = serve(a, "GET", FRAMEWORK_ENDPOINT, "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"tasks":0`) {
+		t.Fatal("Unexpected framework status " + w.Body.String())
+	}
+}
+
+// Measures performance of listing tasks.
+func BenchmarkAPIServer(b *testing.B) {
+	a, _ := newServer()
+	serve(a, "POST", TASKS_ENDPOINT, app)
+	for n := 0; n < b.N; n++ {
+		serve(a, "GET", TASKS_ENDPOINT, "")
+	}
+}
+
+// Ensures requests need credentials except for health and readiness.
+func TestAPIServer_Auth(t *testing.T) {
+	t.Parallel()
+
+	a, _ := newServer()
+	a.Ready = func() bool { return false }
+
+	w := httptest.NewRecorder()
+	a.Handler().ServeHTTP(w, httptest.NewRequest("GET", TASKS_ENDPOINT, nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatal("Requests without credentials should be rejected")
+	}
+
+	w = httptest.NewRecorder()
+	a.Handler().ServeHTTP(w, httptest.NewRequest("GET", HEALTH_ENDPOINT, nil))
+	if w.Code != http.StatusOK {
+		t.Fatal("Health checks shouldn't need credentials")
+	}
+
+	w = httptest.NewRecorder()
+	a.Handler().ServeHTTP(w, httptest.NewRequest("GET", READY_ENDPOINT, nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatal("Frameworks that aren't ready should fail readiness checks")
+	}
+
+	r := httptest.NewRequest("GET", TASKS_ENDPOINT, nil)
+	r.Header.Set("Authorization", "Bearer token")
+	if !TokenAuth("other", "token").Authenticate(r) || TokenAuth("other").Authenticate(r) {
+		t.Fatal("Only known bearer tokens should be allowed")
+	}
+}
+
+// Measures performance of checking credentials.
+func BenchmarkAPIServer_Auth(b *testing.B) {
+	auth := BasicAuth("admin", "secret")
+	r := httptest.NewRequest("GET", TASKS_ENDPOINT, nil)
+	r.SetBasicAuth("admin", "secret")
+	for n := 0; n < b.N; n++ {
+		auth.Authenticate(r)
+	}
+}
+
+// Ensures bus events are streamed to clients as server-sent events.
+func TestAPIServer_Stream(t *testing.T) {
+	t.Parallel()
+
+	a, _ := newServer()
+	b := bus.NewBus(bus.DEFAULT_BUFFER)
+	a.Stream(b)
+	srv := httptest.NewServer(a.Handler())
+	defer srv.Close()
+
+	r, _ := http.NewRequest("GET", srv.URL+EVENTS_ENDPOINT+"?types="+bus.TASK_EVENT, nil)
+	r.SetBasicAuth("admin", "secret")
+	resp, err := http.DefaultClient.Do(r)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatal("Events should be served as an event stream")
+	}
+
+	// The subscription exists once the headers are sent.
+	b.Publish(&bus.Event{Type: bus.OFFERS_EVENT})
+	b.Publish(&bus.Event{Type: bus.TASK_EVENT, Data: &bus.TaskTransition{Id: "web"}})
+
+	reader := bufio.NewReader(resp.Body)
+	event, _ := reader.ReadString('\n')
+	data, _ := reader.ReadString('\n')
+	if event != "event: task\n" || !strings.HasPrefix(data, "data: {") || !strings.Contains(data, `"id":"web"`) {
+		t.Fatal("Unexpected event " + event + data)
+	}
+}
+
+// Measures performance of subscribing to and publishing on the bus.
+func BenchmarkAPIServer_Stream(b *testing.B) {
+	events := bus.NewBus(bus.DEFAULT_BUFFER)
+	for n := 0; n < b.N; n++ {
+		_, cancel := events.Subscribe(bus.TASK_EVENT)
+		events.Publish(&bus.Event{Type: bus.TASK_EVENT})
+		cancel()
+	}
+}
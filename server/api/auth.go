@@ -0,0 +1,66 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// Decides whether a request to the API is allowed.
+type Authenticator interface {
+	Authenticate(r *http.Request) bool
+}
+
+// Lets a plain function be used as an authenticator.
+type AuthFunc func(r *http.Request) bool
+
+func (f AuthFunc) Authenticate(r *http.Request) bool {
+	return f(r)
+}
+
+// Allows requests with the given HTTP basic credentials.
+func BasicAuth(user, password string) Authenticator {
+	return AuthFunc(func(r *http.Request) bool {
+		u, p, ok := r.BasicAuth()
+		return ok && equal(u, user) && equal(p, password)
+	})
+}
+
+// Allows requests that carry one of the tokens as a bearer token.
+func TokenAuth(tokens ...string) Authenticator {
+	return AuthFunc(func(r *http.Request) bool {
+		header := r.Header.Get("Authorization")
+		if !strings.HasPrefix(header, "Bearer ") {
+			return false
+		}
+
+		token := strings.TrimPrefix(header, "Bearer ")
+		allowed := false
+		for _, t := range tokens {
+			if equal(token, t) {
+				allowed = true
+			}
+		}
+
+		return allowed
+	})
+}
+
+// Compares in constant time so credentials can't be guessed from how long a check takes.
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,82 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"github.com/verizonlabs/mesos-framework-sdk/bus"
+	"github.com/verizonlabs/mesos-framework-sdk/logging"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const EVENTS_ENDPOINT = "/v1/events"
+
+// How often idle event streams get a comment so proxies don't close them.
+const KEEPALIVE_INTERVAL = 15 * time.Second
+
+// Serves the bus's events as server-sent events.
+// Clients can pick the event types they want with a comma separated types query parameter.
+func (a *APIServer) Stream(b *bus.Bus) {
+	a.mux.HandleFunc(EVENTS_ENDPOINT, a.authenticated(func(w http.ResponseWriter, r *http.Request) {
+		a.events(w, r, b)
+	}))
+}
+
+func (a *APIServer) events(w http.ResponseWriter, r *http.Request, b *bus.Bus) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		a.fail(w, http.StatusInternalServerError, "Streaming isn't supported.")
+		return
+	}
+
+	var types []string
+	if t := r.URL.Query().Get("types"); t != "" {
+		types = strings.Split(t, ",")
+	}
+	events, cancel := b.Subscribe(types...)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepalive := time.NewTicker(KEEPALIVE_INTERVAL)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case event := <-events:
+			data, err := json.Marshal(event)
+			if err != nil {
+				a.logger.Emit(logging.ERROR, "Failed to encode event: %s", err.Error())
+				continue
+			}
+			if _, err := w.Write([]byte("event: " + event.Type + "\ndata: " + string(data) + "\n\n")); err != nil {
+				return
+			}
+		case <-keepalive.C:
+			if _, err := w.Write([]byte(": keepalive\n\n")); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package management holds the task operations shared by the REST and gRPC management APIs.
+package management
+
+import (
+	"context"
+	"github.com/verizonlabs/mesos-framework-sdk/logging"
+	"github.com/verizonlabs/mesos-framework-sdk/scheduler"
+	"github.com/verizonlabs/mesos-framework-sdk/task/manager"
+)
+
+// Returned when the task was marked as killing but Mesos couldn't be told to kill it.
+type KillError struct {
+	Err error
+}
+
+func (e KillError) Error() string {
+	return e.Err.Error()
+}
+
+// Adds the task to the task manager and hands it to the queue if there is one.
+// Errors from the task manager are returned as is so callers can tell manager.ErrTaskExists apart.
+func Submit(tasks manager.TaskManager, queue func(...*manager.Task) error, logger logging.Logger, t *manager.Task) error {
+	if err := tasks.Add(t); err != nil {
+		return err
+	}
+
+	// The task is in the task manager already, so it still goes out with later offers.
+	if queue != nil {
+		if err := queue(t); err != nil {
+			logger.Emit(logging.ERROR, "Failed to queue task %s: %s", t.Info.GetName(), err.Error())
+		}
+	}
+
+	return nil
+}
+
+// Tasks that were never launched are just removed, the rest are killed and removed once they're gone.
+// Reports whether the task was removed straight away.
+func Kill(ctx context.Context, tasks manager.TaskManager, s scheduler.Scheduler, t *manager.Task) (bool, error) {
+	if t.Info.GetAgentId() == nil {
+		return true, tasks.Delete(t)
+	}
+
+	t.IsKill = true
+	if err := tasks.Update(t); err != nil {
+		return false, err
+	}
+	if _, err := s.Kill(ctx, t.Info.GetTaskId(), t.Info.GetAgentId()); err != nil {
+		return false, KillError{Err: err}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package management
+
+import (
+	"context"
+	"errors"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	logtest "github.com/verizonlabs/mesos-framework-sdk/logging/test"
+	"github.com/verizonlabs/mesos-framework-sdk/scheduler/test"
+	"github.com/verizonlabs/mesos-framework-sdk/task/manager"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"testing"
+)
+
+func newTask(name string) *manager.Task {
+	return manager.NewTask(&mesos_v1.TaskInfo{
+		Name:   utils.ProtoString(name),
+		TaskId: &mesos_v1.TaskID{Value: utils.ProtoString(name + "-id")},
+	}, manager.STAGING, nil, nil, 1, manager.GroupInfo{})
+}
+
+// Ensures submitted tasks stay in the task manager even when they can't be queued.
+func TestSubmit(t *testing.T) {
+	t.Parallel()
+
+	tasks := manager.NewDefaultTaskManager(nil, manager.TASK_PREFIX)
+	logger := new(logtest.MockLogger)
+	var queued int
+	queue := func(t ...*manager.Task) error {
+		queued += len(t)
+		return errors.New("queue is full")
+	}
+
+	if err := Submit(tasks, nil, logger, newTask("db")); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := Submit(tasks, queue, logger, newTask("web")); err != nil {
+		t.Fatal("Queue failures shouldn't fail the submission: " + err.Error())
+	}
+	if queued != 1 || tasks.TotalTasks() != 2 {
+		t.Fatal("Submitted tasks should be added and queued")
+	}
+	if err := Submit(tasks, queue, logger, newTask("web")); err != manager.ErrTaskExists {
+		t.Fatalf("Expected %v, got %v", manager.ErrTaskExists, err)
+	}
+}
+
+// Measures performance of submitting a task.
+func BenchmarkSubmit(b *testing.B) {
+	tasks := manager.NewDefaultTaskManager(nil, manager.TASK_PREFIX)
+	logger := new(logtest.MockLogger)
+	for n := 0; n < b.N; n++ {
+		t := newTask("web")
+		Submit(tasks, nil, logger, t)
+		tasks.Delete(t)
+	}
+}
+
+// Ensures unlaunched tasks are removed and launched ones are marked and killed.
+func TestKill(t *testing.T) {
+	t.Parallel()
+
+	tasks := manager.NewDefaultTaskManager(nil, manager.TASK_PREFIX)
+	web := newTask("web")
+	tasks.Add(web)
+
+	removed, err := Kill(context.Background(), tasks, test.NewMockScheduler(), web)
+	if err != nil || !removed || tasks.TotalTasks() != 0 {
+		t.Fatal("Tasks that were never launched should be removed")
+	}
+
+	web = newTask("web")
+	web.Info.AgentId = &mesos_v1.AgentID{Value: utils.ProtoString("agent")}
+	tasks.Add(web)
+	removed, err = Kill(context.Background(), tasks, test.NewMockScheduler(), web)
+	if err != nil || removed || !web.IsKill || tasks.TotalTasks() != 1 {
+		t.Fatal("Launched tasks should be marked as killed until they're gone")
+	}
+
+	_, err = Kill(context.Background(), tasks, test.MockBrokenScheduler{}, web)
+	if _, ok := err.(KillError); !ok {
+		t.Fatalf("Failed kill calls should be reported as a KillError, got %v", err)
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpc
+
+import (
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/logging"
+	"github.com/verizonlabs/mesos-framework-sdk/scheduler"
+	"github.com/verizonlabs/mesos-framework-sdk/server/management"
+	"github.com/verizonlabs/mesos-framework-sdk/task/manager"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"strings"
+	"sync"
+	"time"
+)
+
+// How many events a stream can fall behind by before it starts missing them.
+const STREAM_BUFFER = 64
+
+// Serves the management API over gRPC, backed by the framework's task manager and scheduler.
+// Every status update must be passed to Update so event streams see it.
+type ManagementService struct {
+	tasks     manager.TaskManager
+	scheduler scheduler.Scheduler
+	logger    logging.Logger
+	lock      sync.Mutex
+	streams   map[chan *TaskEvent]struct{}
+
+	// Called with submitted tasks once they're in the task manager, usually an offer coordinator's Queue method.
+	Queue func(...*manager.Task) error
+}
+
+func NewManagementService(tasks manager.TaskManager, s scheduler.Scheduler, logger logging.Logger) *ManagementService {
+	return &ManagementService{
+		tasks:     tasks,
+		scheduler: s,
+		logger:    logger,
+		streams:   make(map[chan *TaskEvent]struct{}),
+	}
+}
+
+// Adds the service to a gRPC server.
+func (m *ManagementService) Register(s *grpc.Server) {
+	RegisterManagementServer(s, m)
+}
+
+// Adds a task to the task manager, its ID defaults to its name followed by a UUID.
+func (m *ManagementService) SubmitTask(ctx context.Context, req *SubmitTaskRequest) (*SubmitTaskResponse, error) {
+	info := req.GetInfo()
+	if info.GetName() == "" {
+		return nil, grpc.Errorf(codes.InvalidArgument, "Tasks need a name.")
+	}
+	if info.GetTaskId().GetValue() == "" {
+		info.TaskId = &mesos_v1.TaskID{Value: utils.ProtoString(info.GetName() + "-" + strings.ToLower(utils.UuidAsString()))}
+	}
+
+	t := manager.NewTask(info, manager.STAGING, nil, nil, 1, manager.GroupInfo{})
+	t.Priority = req.GetPriority()
+	t.DependsOn = req.GetDependsOn()
+	if err := management.Submit(m.tasks, m.Queue, m.logger, t); err != nil {
+		if err == manager.ErrTaskExists {
+			return nil, grpc.Errorf(codes.AlreadyExists, "%v", err)
+		}
+		return nil, grpc.Errorf(codes.Internal, "%v", err)
+	}
+
+	return &SubmitTaskResponse{Task: view(t)}, nil
+}
+
+// Kills the named task, tasks that were never launched are removed right away.
+func (m *ManagementService) KillTask(ctx context.Context, req *KillTaskRequest) (*KillTaskResponse, error) {
+	name := req.GetName()
+	t, err := m.tasks.Get(&name)
+	if err != nil || t == nil {
+		return nil, grpc.Errorf(codes.NotFound, "There's no task named %s.", name)
+	}
+
+	_, err = management.Kill(ctx, m.tasks, m.scheduler, t)
+	if _, ok := err.(management.KillError); ok {
+		return nil, grpc.Errorf(codes.Unavailable, "%v", err)
+	}
+	if err != nil {
+		return nil, grpc.Errorf(codes.Internal, "%v", err)
+	}
+
+	return &KillTaskResponse{Task: view(t)}, nil
+}
+
+func (m *ManagementService) ListTasks(ctx context.Context, req *ListTasksRequest) (*ListTasksResponse, error) {
+	var all []*manager.Task
+	var err error
+	if req.State != nil {
+		all, err = m.tasks.AllByState(req.GetState())
+	} else {
+		all, err = m.tasks.All()
+	}
+	if err != nil {
+		return nil, grpc.Errorf(codes.Internal, "%v", err)
+	}
+
+	resp := &ListTasksResponse{Tasks: make([]*Task, 0, len(all))}
+	for _, t := range all {
+		resp.Tasks = append(resp.Tasks, view(t))
+	}
+
+	return resp, nil
+}
+
+// Sends every task status update until the client goes away.
+// Streams that fall too far behind miss events instead of holding up the others.
+func (m *ManagementService) StreamEvents(req *StreamEventsRequest, stream Management_StreamEventsServer) error {
+	events := make(chan *TaskEvent, STREAM_BUFFER)
+	m.lock.Lock()
+	m.streams[events] = struct{}{}
+	m.lock.Unlock()
+
+	defer func() {
+		m.lock.Lock()
+		delete(m.streams, events)
+		m.lock.Unlock()
+	}()
+
+	for {
+		select {
+		case event := <-events:
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+// Sends the status update to every event stream.
+func (m *ManagementService) Update(status *mesos_v1.TaskStatus) {
+	t, err := m.tasks.GetById(status.GetTaskId())
+	if err != nil {
+		return
+	}
+
+	event := &TaskEvent{
+		Task:      view(t),
+		Status:    status,
+		Timestamp: utils.ProtoFloat64(float64(time.Now().UnixNano()) / float64(time.Second)),
+	}
+	event.Task.State = status.State
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for events := range m.streams {
+		select {
+		case events <- event:
+		default:
+		}
+	}
+}
+
+func view(t *manager.Task) *Task {
+	state := t.State
+	v := &Task{
+		Name:    utils.ProtoString(t.Info.GetName()),
+		Id:      utils.ProtoString(t.Info.GetTaskId().GetValue()),
+		State:   &state,
+		Healthy: t.Healthy,
+	}
+	if agent := t.Info.GetAgentId().GetValue(); agent != "" {
+		v.AgentId = utils.ProtoString(agent)
+	}
+	if t.GroupInfo.InGroup {
+		v.Group = utils.ProtoString(t.GroupInfo.GroupName)
+	}
+	if t.IsKill {
+		v.Killing = utils.ProtoBool(true)
+	}
+
+	return v
+}
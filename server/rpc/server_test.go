@@ -0,0 +1,157 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpc
+
+import (
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	logtest "github.com/verizonlabs/mesos-framework-sdk/logging/test"
+	"github.com/verizonlabs/mesos-framework-sdk/scheduler/test"
+	"github.com/verizonlabs/mesos-framework-sdk/task/manager"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"testing"
+	"time"
+)
+
+type mockStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	events chan *TaskEvent
+}
+
+func (m *mockStream) Context() context.Context {
+	return m.ctx
+}
+
+func (m *mockStream) Send(event *TaskEvent) error {
+	m.events <- event
+	return nil
+}
+
+func newService() (*ManagementService, manager.TaskManager) {
+	tasks := manager.NewDefaultTaskManager(nil, manager.TASK_PREFIX)
+	return NewManagementService(tasks, test.NewMockScheduler(), new(logtest.MockLogger)), tasks
+}
+
+// Ensures tasks can be submitted, listed and killed.
+func TestManagementService(t *testing.T) {
+	t.Parallel()
+
+	m, tasks := newService()
+	var queued int
+	m.Queue = func(t ...*manager.Task) error {
+		queued += len(t)
+		return nil
+	}
+	ctx := context.Background()
+
+	if _, err := m.SubmitTask(ctx, &SubmitTaskRequest{Info: &mesos_v1.TaskInfo{}}); err == nil {
+		t.Fatal("Tasks without names should be rejected")
+	}
+	resp, err := m.SubmitTask(ctx, &SubmitTaskRequest{
+		Info:      &mesos_v1.TaskInfo{Name: utils.ProtoString("web")},
+		Priority:  utils.ProtoString("high"),
+		DependsOn: []string{"db"},
+	})
+	if err != nil || queued != 1 || resp.GetTask().GetId() == "" || resp.GetTask().GetState() != manager.STAGING {
+		t.Fatal("Submitted tasks should get an ID and be queued")
+	}
+	web, _ := tasks.Get(utils.ProtoString("web"))
+	if web.Priority != "high" || len(web.DependsOn) != 1 {
+		t.Fatal("Submitted tasks should keep their priority and dependencies")
+	}
+	if _, err := m.SubmitTask(ctx, &SubmitTaskRequest{Info: &mesos_v1.TaskInfo{Name: utils.ProtoString("web")}}); err == nil {
+		t.Fatal("Duplicate tasks should be rejected")
+	}
+
+	list, err := m.ListTasks(ctx, &ListTasksRequest{State: manager.RUNNING.Enum()})
+	if err != nil || len(list.GetTasks()) != 0 {
+		t.Fatal("Tasks should be filtered by state")
+	}
+	if list, _ = m.ListTasks(ctx, &ListTasksRequest{}); len(list.GetTasks()) != 1 {
+		t.Fatal("Every task should be listed without a state")
+	}
+
+	if _, err := m.KillTask(ctx, &KillTaskRequest{Name: utils.ProtoString("nothing")}); err == nil {
+		t.Fatal("Unknown tasks can't be killed")
+	}
+	web.Info.AgentId = &mesos_v1.AgentID{Value: utils.ProtoString("agent")}
+	if kill, err := m.KillTask(ctx, &KillTaskRequest{Name: utils.ProtoString("web")}); err != nil || !kill.GetTask().GetKilling() {
+		t.Fatal("Launched tasks should be killed")
+	}
+	web.Info.AgentId = nil
+	if _, err := m.KillTask(ctx, &KillTaskRequest{Name: utils.ProtoString("web")}); err != nil || tasks.TotalTasks() != 0 {
+		t.Fatal("Tasks that were never launched should be removed")
+	}
+}
+
+// Measures performance of listing tasks.
+func BenchmarkManagementService(b *testing.B) {
+	m, _ := newService()
+	m.SubmitTask(context.Background(), &SubmitTaskRequest{Info: &mesos_v1.TaskInfo{Name: utils.ProtoString("web")}})
+	for n := 0; n < b.N; n++ {
+		m.ListTasks(context.Background(), &ListTasksRequest{})
+	}
+}
+
+// Ensures status updates reach event streams until they're closed.
+func TestManagementService_StreamEvents(t *testing.T) {
+	t.Parallel()
+
+	m, _ := newService()
+	resp, _ := m.SubmitTask(context.Background(), &SubmitTaskRequest{Info: &mesos_v1.TaskInfo{Name: utils.ProtoString("web")}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &mockStream{ctx: ctx, events: make(chan *TaskEvent, 1)}
+	done := make(chan error)
+	go func() {
+		done <- m.StreamEvents(&StreamEventsRequest{}, stream)
+	}()
+
+	status := &mesos_v1.TaskStatus{
+		TaskId: &mesos_v1.TaskID{Value: utils.ProtoString(resp.GetTask().GetId())},
+		State:  manager.RUNNING.Enum(),
+	}
+	deadline := time.After(5 * time.Second)
+	for received := false; !received; {
+		m.Update(status)
+		select {
+		case event := <-stream.events:
+			if event.GetTask().GetName() != "web" || event.GetTask().GetState() != manager.RUNNING {
+				t.Fatal("Events should carry the task's new state")
+			}
+			received = true
+		case <-time.After(10 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("Timed out waiting for the event")
+		}
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err.Error())
+	}
+}
+
+// Measures performance of publishing an update.
+func BenchmarkManagementService_StreamEvents(b *testing.B) {
+	m, _ := newService()
+	resp, _ := m.SubmitTask(context.Background(), &SubmitTaskRequest{Info: &mesos_v1.TaskInfo{Name: utils.ProtoString("web")}})
+	status := &mesos_v1.TaskStatus{TaskId: &mesos_v1.TaskID{Value: utils.ProtoString(resp.GetTask().GetId())}}
+	for n := 0; n < b.N; n++ {
+		m.Update(status)
+	}
+}
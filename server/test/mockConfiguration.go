@@ -45,3 +45,7 @@ func (m *MockServerConfiguration) Server() *http.Server {
 func (m *MockServerConfiguration) TLS() bool {
 	return false
 }
+
+func (m *MockServerConfiguration) Mux() *http.ServeMux {
+	return http.NewServeMux()
+}
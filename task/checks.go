@@ -0,0 +1,132 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task
+
+import (
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"strings"
+)
+
+// Checks shared by Validate and the parsers so the two can't disagree.
+// Each takes the path of the field being checked and reports problems relative to it, parsers return the first one.
+
+// Looks up a capability, names are case insensitive and may include the CAP_ prefix.
+func Capability(name string) (mesos_v1.CapabilityInfo_Capability, bool) {
+	value, ok := mesos_v1.CapabilityInfo_Capability_value[strings.TrimPrefix(strings.ToUpper(name), "CAP_")]
+	if !ok || value == int32(mesos_v1.CapabilityInfo_UNKNOWN) {
+		return mesos_v1.CapabilityInfo_UNKNOWN, false
+	}
+
+	return mesos_v1.CapabilityInfo_Capability(value), true
+}
+
+// Looks up an rlimit type, types are case insensitive and may leave out the RLMT_ prefix.
+func RLimitType(name string) (mesos_v1.RLimitInfo_RLimit_Type, bool) {
+	name = strings.ToUpper(name)
+	if !strings.HasPrefix(name, "RLMT_") {
+		name = "RLMT_" + name
+	}
+
+	value, ok := mesos_v1.RLimitInfo_RLimit_Type_value[name]
+	if !ok || value == int32(mesos_v1.RLimitInfo_RLimit_UNKNOWN) {
+		return mesos_v1.RLimitInfo_RLimit_UNKNOWN, false
+	}
+
+	return mesos_v1.RLimitInfo_RLimit_Type(value), true
+}
+
+// Gets the docker network mode, defaulting to host unless there are port mappings which only make sense on a bridge.
+func DockerNetwork(d *DockerJSON) (mesos_v1.ContainerInfo_DockerInfo_Network, bool) {
+	if d.Network == nil {
+		if len(d.PortMappings) > 0 {
+			return mesos_v1.ContainerInfo_DockerInfo_BRIDGE, true
+		}
+		return mesos_v1.ContainerInfo_DockerInfo_HOST, true
+	}
+
+	switch strings.ToLower(*d.Network) {
+	case "host":
+		return mesos_v1.ContainerInfo_DockerInfo_HOST, true
+	case "bridge":
+		return mesos_v1.ContainerInfo_DockerInfo_BRIDGE, true
+	case "none":
+		return mesos_v1.ContainerInfo_DockerInfo_NONE, true
+	case "user":
+		return mesos_v1.ContainerInfo_DockerInfo_USER, true
+	}
+
+	return mesos_v1.ContainerInfo_DockerInfo_HOST, false
+}
+
+// Reports capabilities Mesos doesn't know about.
+func CheckCapabilities(p string, capabilities []string) []FieldError {
+	var errs []FieldError
+	for i, c := range capabilities {
+		if _, ok := Capability(c); !ok {
+			errs = append(errs, FieldError{Path: index(p, i), Message: "is not a known capability"})
+		}
+	}
+
+	return errs
+}
+
+// Reports unknown or repeated rlimit types and half set limits.
+// Mesos treats a limit without either value as unlimited.
+func CheckRLimits(p string, rlimits []RLimitJSON) []FieldError {
+	var errs []FieldError
+	seen := make(map[mesos_v1.RLimitInfo_RLimit_Type]struct{}, len(rlimits))
+	for i, r := range rlimits {
+		rp := index(p, i)
+		if r.Type == "" {
+			errs = append(errs, FieldError{Path: rp + ".type", Message: "missing"})
+		} else if t, ok := RLimitType(r.Type); !ok {
+			errs = append(errs, FieldError{Path: rp + ".type", Message: "is not a known rlimit type"})
+		} else if _, ok := seen[t]; ok {
+			errs = append(errs, FieldError{Path: rp + ".type", Message: "is set more than once"})
+		} else {
+			seen[t] = struct{}{}
+		}
+
+		if (r.Soft == nil) != (r.Hard == nil) {
+			errs = append(errs, FieldError{Path: rp, Message: "must set both soft and hard or neither"})
+		} else if r.Soft != nil && *r.Soft > *r.Hard {
+			errs = append(errs, FieldError{Path: rp + ".soft", Message: "can't exceed the hard limit"})
+		}
+	}
+
+	return errs
+}
+
+// Reports network modes that don't fit the port mappings or networks.
+// User networking attaches the container to a single named network, which has to be given along with the container.
+func CheckDockerNetwork(p string, d *DockerJSON, networks []NetworkJSON) []FieldError {
+	mode, ok := DockerNetwork(d)
+	if !ok {
+		return []FieldError{{Path: p + ".network", Message: "must be host, bridge, none or user"}}
+	}
+
+	switch mode {
+	case mesos_v1.ContainerInfo_DockerInfo_HOST, mesos_v1.ContainerInfo_DockerInfo_NONE:
+		if len(d.PortMappings) > 0 {
+			return []FieldError{{Path: p + ".port_mapping", Message: "requires bridge or user networking"}}
+		}
+	case mesos_v1.ContainerInfo_DockerInfo_USER:
+		if len(networks) != 1 || networks[0].Name == nil || *networks[0].Name == "" {
+			return []FieldError{{Path: p + ".network", Message: "requires exactly one named network for user networking"}}
+		}
+	}
+
+	return nil
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
 	"github.com/verizonlabs/mesos-framework-sdk/task"
 	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"path"
 )
 
 func ParseCommandInfo(cmd *task.CommandJSON) (*mesos_v1.CommandInfo, error) {
@@ -30,7 +31,6 @@ func ParseCommandInfo(cmd *task.CommandJSON) (*mesos_v1.CommandInfo, error) {
 		Value:       cmd.Cmd,
 		Environment: &mesos_v1.Environment{},
 	}
-	uriList := []*mesos_v1.CommandInfo_URI{}
 
 	if cmd.Environment != nil {
 		for name, value := range cmd.Environment {
@@ -41,17 +41,11 @@ func ParseCommandInfo(cmd *task.CommandJSON) (*mesos_v1.CommandInfo, error) {
 		}
 	}
 
-	if len(cmd.Uris) > 0 {
-		// create all the URI'
-		for _, uri := range cmd.Uris {
-			uriList = append(uriList, &mesos_v1.CommandInfo_URI{
-				Value:      uri.Uri,
-				Executable: uri.Execute,
-				Extract:    uri.Extract,
-			})
-		}
-		mesosCmd.Uris = uriList
+	uris, err := ParseURIs(cmd.Uris)
+	if err != nil {
+		return nil, err
 	}
+	mesosCmd.Uris = uris
 
 	if len(mesosCmd.Uris) == 0 && cmd.Cmd == nil {
 		return nil, errors.New("CommandInfo is empty even though a command JSON param was passed in.")
@@ -59,3 +53,31 @@ func ParseCommandInfo(cmd *task.CommandJSON) (*mesos_v1.CommandInfo, error) {
 
 	return mesosCmd, nil
 }
+
+// Parses the artifacts the fetcher downloads into the sandbox.
+func ParseURIs(uris []task.UriJSON) ([]*mesos_v1.CommandInfo_URI, error) {
+	if len(uris) == 0 {
+		return nil, nil
+	}
+
+	uriList := make([]*mesos_v1.CommandInfo_URI, 0, len(uris))
+	for _, uri := range uris {
+		if uri.Uri == nil || *uri.Uri == "" {
+			return nil, errors.New("URIs must have a value to fetch.")
+		}
+
+		if uri.OutputFile != nil && (*uri.OutputFile == "" || path.IsAbs(*uri.OutputFile)) {
+			return nil, errors.New("URI output files must be relative to the sandbox.")
+		}
+
+		uriList = append(uriList, &mesos_v1.CommandInfo_URI{
+			Value:      uri.Uri,
+			Executable: uri.Execute,
+			Extract:    uri.Extract,
+			Cache:      uri.Cache,
+			OutputFile: uri.OutputFile,
+		})
+	}
+
+	return uriList, nil
+}
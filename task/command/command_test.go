@@ -0,0 +1,98 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"encoding/json"
+	"github.com/verizonlabs/mesos-framework-sdk/task"
+	"testing"
+)
+
+func parse(t testing.TB, data string) []task.UriJSON {
+	var uris []task.UriJSON
+	if err := json.Unmarshal([]byte(data), &uris); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	return uris
+}
+
+// Ensures fetcher URIs are parsed and invalid values or output files are rejected.
+func TestParseURIs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		data  string
+		count int
+		fail  bool
+	}{
+		{"none", `[]`, 0, false},
+		{"plain", `[{"uri": "http://example.com/app.tgz"}]`, 1, false},
+		{"options", `[{"uri": "http://example.com/app", "extract": false, "execute": true, "cache": true, "output_file": "bin/app"}]`, 1, false},
+		{"several", `[{"uri": "http://example.com/a"}, {"uri": "http://example.com/b"}]`, 2, false},
+		{"no uri", `[{"extract": true}]`, 0, true},
+		{"empty uri", `[{"uri": ""}]`, 0, true},
+		{"empty output file", `[{"uri": "http://example.com/app", "output_file": ""}]`, 0, true},
+		{"absolute output file", `[{"uri": "http://example.com/app", "output_file": "/etc/app"}]`, 0, true},
+	}
+
+	for _, test := range tests {
+		uris, err := ParseURIs(parse(t, test.data))
+		if (err != nil) != test.fail {
+			t.Fatalf("%s: unexpected error state: %v", test.name, err)
+		}
+		if len(uris) != test.count {
+			t.Fatalf("%s: expected %d URIs, got %d", test.name, test.count, len(uris))
+		}
+	}
+
+	uris, _ := ParseURIs(parse(t, `[{"uri": "http://example.com/app", "extract": false, "execute": true, "cache": true, "output_file": "bin/app"}]`))
+	uri := uris[0]
+	if uri.GetValue() != "http://example.com/app" || uri.GetExtract() || !uri.GetExecutable() || !uri.GetCache() || uri.GetOutputFile() != "bin/app" {
+		t.Fatal("URI options were not carried over")
+	}
+}
+
+// Measures performance of parsing URIs.
+func BenchmarkParseURIs(b *testing.B) {
+	uris := parse(b, `[{"uri": "http://example.com/app.tgz", "extract": true}, {"uri": "http://example.com/conf", "output_file": "conf/app"}]`)
+	for n := 0; n < b.N; n++ {
+		ParseURIs(uris)
+	}
+}
+
+// Ensures commands need either a value or something to fetch.
+func TestParseCommandInfo(t *testing.T) {
+	t.Parallel()
+
+	if _, err := ParseCommandInfo(nil); err == nil {
+		t.Fatal("A nil command should be rejected")
+	}
+	if _, err := ParseCommandInfo(&task.CommandJSON{}); err == nil {
+		t.Fatal("An empty command should be rejected")
+	}
+	if _, err := ParseCommandInfo(&task.CommandJSON{Uris: parse(t, `[{"uri": ""}]`)}); err == nil {
+		t.Fatal("Invalid URIs should be rejected")
+	}
+
+	cmd, err := ParseCommandInfo(&task.CommandJSON{Uris: parse(t, `[{"uri": "http://example.com/app"}]`)})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(cmd.GetUris()) != 1 {
+		t.Fatal("Command URIs were not parsed")
+	}
+}
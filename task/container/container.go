@@ -19,8 +19,11 @@ import (
 	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
 	"github.com/verizonlabs/mesos-framework-sdk/resources"
 	"github.com/verizonlabs/mesos-framework-sdk/task"
+	"github.com/verizonlabs/mesos-framework-sdk/task/environment"
 	"github.com/verizonlabs/mesos-framework-sdk/task/network"
 	"github.com/verizonlabs/mesos-framework-sdk/task/volume"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"strings"
 )
 
 func ParseContainer(c *task.ContainerJSON) (*mesos_v1.ContainerInfo, error) {
@@ -28,11 +31,17 @@ func ParseContainer(c *task.ContainerJSON) (*mesos_v1.ContainerInfo, error) {
 		return nil, nil
 	}
 
-	// "No explicit network info passed in, using default host networking."
-	networks, _ := network.ParseNetworkJSON(c.Network)
+	// No explicit network info passed in, using default host networking.
+	var networks []*mesos_v1.NetworkInfo
+	var err error
+	if len(c.Network) > 0 {
+		networks, err = network.ParseNetworkJSON(c.Network)
+		if err != nil {
+			return nil, errors.New("Error parsing network JSON: " + err.Error())
+		}
+	}
 
 	var vol []*mesos_v1.Volume
-	var err error
 	if len(c.Volumes) > 0 {
 		vol, err = volume.ParseVolumeJSON(c.Volumes)
 		if err != nil {
@@ -40,20 +49,163 @@ func ParseContainer(c *task.ContainerJSON) (*mesos_v1.ContainerInfo, error) {
 		}
 	}
 
+	linux, err := ParseLinuxInfo(c.Capabilities)
+	if err != nil {
+		return nil, err
+	}
+	rlimits, err := ParseRLimits(c.Rlimits)
+	if err != nil {
+		return nil, err
+	}
+	tty, err := ParseTTYInfo(c.Tty, c.WindowSize)
+	if err != nil {
+		return nil, err
+	}
+
 	// Default to the UCR.
 	container := &mesos_v1.ContainerInfo{
 		Type:         mesos_v1.ContainerInfo_MESOS.Enum(),
 		NetworkInfos: networks,
 		Volumes:      vol,
+		LinuxInfo:    linux,
+		RlimitInfo:   rlimits,
+		TtyInfo:      tty,
+	}
+
+	if c.ContainerType != nil && strings.ToLower(*c.ContainerType) == "docker" {
+		// The docker containerizer ignores these, it takes them as docker parameters instead.
+		if linux != nil || rlimits != nil || tty != nil {
+			return nil, errors.New("Capabilities, rlimits and TTYs are only supported by the mesos containerizer.")
+		}
+
+		docker, err := parseDocker(c)
+		if err != nil {
+			return nil, errors.New("Error parsing docker options: " + err.Error())
+		}
+
+		container.Type = mesos_v1.ContainerInfo_DOCKER.Enum()
+		container.Docker = docker
+
+		return container, nil
 	}
 
 	if c.ImageName == nil {
+		if c.PullConfig != nil || c.PullPolicy != "" {
+			return nil, errors.New("Pull settings require an image.")
+		}
 		return container, nil
 	}
 
-	container.Mesos = resources.CreateMesosInfo(
-		resources.CreateImage(mesos_v1.Image_DOCKER.Enum(), *c.ImageName),
-	)
+	image := resources.CreateImage(mesos_v1.Image_DOCKER.Enum(), imageName(c))
+	pull, err := parsePullPolicy(c.PullPolicy)
+	if err != nil {
+		return nil, err
+	}
+	if pull {
+		image.Cached = utils.ProtoBool(false)
+	}
+	if c.PullConfig != nil {
+		config, err := environment.ParseSecret(*c.PullConfig)
+		if err != nil {
+			return nil, errors.New("Invalid pull config: " + err.Error())
+		}
+		image.Docker.Config = config
+	}
+
+	container.Mesos = resources.CreateMesosInfo(image)
 
 	return container, nil
 }
+
+// Reports whether the image should be pulled on every launch instead of using the agent's cache.
+func parsePullPolicy(policy string) (bool, error) {
+	switch strings.ToLower(policy) {
+	case "", "cached":
+		return false, nil
+	case "always":
+		return true, nil
+	default:
+		return false, errors.New("Invalid pull policy, accepted values are cached, always.")
+	}
+}
+
+func imageName(c *task.ContainerJSON) string {
+	if c.Tag != nil && *c.Tag != "" {
+		return *c.ImageName + ":" + *c.Tag
+	}
+
+	return *c.ImageName
+}
+
+func parseDocker(c *task.ContainerJSON) (*mesos_v1.ContainerInfo_DockerInfo, error) {
+	if c.ImageName == nil || *c.ImageName == "" {
+		return nil, errors.New("Docker containers need an image.")
+	}
+
+	image := imageName(c)
+
+	// The docker containerizer pulls with the docker daemon's own credentials.
+	if c.PullConfig != nil {
+		return nil, errors.New("Pull configs are only supported by the mesos containerizer.")
+	}
+	pull, err := parsePullPolicy(c.PullPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := c.Docker
+	if opts == nil {
+		opts = &task.DockerJSON{}
+	}
+
+	if errs := task.CheckDockerNetwork("docker", opts, c.Network); len(errs) > 0 {
+		return nil, errs[0]
+	}
+	mode, _ := task.DockerNetwork(opts)
+
+	ports := make([]*mesos_v1.ContainerInfo_DockerInfo_PortMapping, 0, len(opts.PortMappings))
+	for _, p := range opts.PortMappings {
+		if p.HostPort == nil || p.ContainerPort == nil {
+			return nil, errors.New("Port mappings need both a host and container port.")
+		}
+
+		pm := &mesos_v1.ContainerInfo_DockerInfo_PortMapping{
+			HostPort:      p.HostPort,
+			ContainerPort: p.ContainerPort,
+		}
+		if p.Protocol != nil {
+			protocol := strings.ToLower(*p.Protocol)
+			if protocol != "tcp" && protocol != "udp" {
+				return nil, errors.New("Invalid port mapping protocol, accepted values are tcp, udp.")
+			}
+			pm.Protocol = utils.ProtoString(protocol)
+		}
+		ports = append(ports, pm)
+	}
+
+	params := make([]*mesos_v1.Parameter, 0, len(opts.Parameters))
+	for _, p := range opts.Parameters {
+		if p.Key == "" {
+			return nil, errors.New("Docker parameters need a key.")
+		}
+		params = append(params, &mesos_v1.Parameter{
+			Key:   utils.ProtoString(p.Key),
+			Value: utils.ProtoString(p.Value),
+		})
+	}
+
+	docker := resources.CreateDockerInfo(
+		resources.CreateImage(mesos_v1.Image_DOCKER.Enum(), image),
+		mode.Enum(),
+		ports,
+		params,
+		nil,
+	)
+	docker.Privileged = opts.Privileged
+	docker.ForcePullImage = opts.ForcePullImage
+	if docker.ForcePullImage == nil && pull {
+		docker.ForcePullImage = utils.ProtoBool(true)
+	}
+
+	return docker, nil
+}
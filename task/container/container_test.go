@@ -0,0 +1,225 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"encoding/json"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/task"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"testing"
+)
+
+func parse(t testing.TB, data string) *task.ContainerJSON {
+	var c task.ContainerJSON
+	if err := json.Unmarshal([]byte(data), &c); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	return &c
+}
+
+// Ensures docker network modes default from the port mappings and only fit the mappings and networks they support.
+func TestParseContainer_DockerNetwork(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		data    string
+		mode    mesos_v1.ContainerInfo_DockerInfo_Network
+		invalid bool
+	}{
+		{"default", `{"type": "docker", "image": "nginx"}`, mesos_v1.ContainerInfo_DockerInfo_HOST, false},
+		{"default with port mappings", `{"type": "docker", "image": "nginx", "docker": {
+			"port_mapping": [{"host_port": 31000, "container_port": 80}]
+		}}`, mesos_v1.ContainerInfo_DockerInfo_BRIDGE, false},
+		{"bridge", `{"type": "docker", "image": "nginx", "docker": {"network": "BRIDGE"}}`, mesos_v1.ContainerInfo_DockerInfo_BRIDGE, false},
+		{"none", `{"type": "docker", "image": "nginx", "docker": {"network": "none"}}`, mesos_v1.ContainerInfo_DockerInfo_NONE, false},
+		{"host with port mappings", `{"type": "docker", "image": "nginx", "docker": {
+			"network": "host", "port_mapping": [{"host_port": 31000, "container_port": 80}]
+		}}`, 0, true},
+		{"none with port mappings", `{"type": "docker", "image": "nginx", "docker": {
+			"network": "none", "port_mapping": [{"host_port": 31000, "container_port": 80}]
+		}}`, 0, true},
+		{"user", `{"type": "docker", "image": "nginx", "network": [{"name": "overlay"}], "docker": {
+			"network": "user", "port_mapping": [{"host_port": 31000, "container_port": 80}]
+		}}`, mesos_v1.ContainerInfo_DockerInfo_USER, false},
+		{"user without a network", `{"type": "docker", "image": "nginx", "docker": {"network": "user"}}`, 0, true},
+		{"user with an unnamed network", `{"type": "docker", "image": "nginx", "network": [{"group": ["web"]}], "docker": {"network": "user"}}`, 0, true},
+		{"user with two networks", `{"type": "docker", "image": "nginx", "network": [{"name": "a"}, {"name": "b"}], "docker": {"network": "user"}}`, 0, true},
+		{"unknown", `{"type": "docker", "image": "nginx", "docker": {"network": "overlay"}}`, 0, true},
+	}
+
+	for _, test := range tests {
+		c, err := ParseContainer(parse(t, test.data))
+		if test.invalid {
+			if err == nil {
+				t.Fatal(test.name + ": expected an error")
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(test.name + ": " + err.Error())
+		}
+		if c.GetType() != mesos_v1.ContainerInfo_DOCKER || c.GetDocker().GetNetwork() != test.mode {
+			t.Fatalf("%s: expected %s networking, got %s", test.name, test.mode, c.GetDocker().GetNetwork())
+		}
+	}
+}
+
+// Measures performance of parsing a docker container.
+func BenchmarkParseContainer_DockerNetwork(b *testing.B) {
+	c := parse(b, `{"type": "docker", "image": "nginx", "tag": "1.21", "docker": {
+		"port_mapping": [{"host_port": 31000, "container_port": 80, "protocol": "tcp"}],
+		"parameters": [{"key": "ulimit", "value": "nofile=1024"}]
+	}}`)
+	for n := 0; n < b.N; n++ {
+		ParseContainer(c)
+	}
+}
+
+// Ensures port mappings, parameters and privileged mode are handed to docker and invalid ones are rejected.
+func TestParseContainer_DockerOptions(t *testing.T) {
+	t.Parallel()
+
+	c, err := ParseContainer(parse(t, `{"type": "docker", "image": "nginx", "tag": "1.21", "docker": {
+		"port_mapping": [{"host_port": 31000, "container_port": 80, "protocol": "UDP"}, {"host_port": 31001, "container_port": 443}],
+		"parameters": [{"key": "ulimit", "value": "nofile=1024"}, {"key": "ulimit", "value": "nproc=64"}, {"key": "init"}],
+		"privileged": true
+	}}`))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	docker := c.GetDocker()
+	if docker.GetImage() != "nginx:1.21" || !docker.GetPrivileged() {
+		t.Fatal("The image and privileged mode were not set")
+	}
+	ports := docker.GetPortMappings()
+	if len(ports) != 2 || ports[0].GetHostPort() != 31000 || ports[0].GetContainerPort() != 80 || ports[0].GetProtocol() != "udp" {
+		t.Fatal("Port mappings were not set")
+	}
+	if ports[1].Protocol != nil {
+		t.Fatal("Port mappings without a protocol should leave it to docker")
+	}
+	params := docker.GetParameters()
+	if len(params) != 3 || params[1].GetKey() != "ulimit" || params[1].GetValue() != "nproc=64" || params[2].GetValue() != "" {
+		t.Fatal("Parameters should be passed through in order, including repeated keys")
+	}
+
+	invalid := map[string]string{
+		"no image":           `{"type": "docker"}`,
+		"incomplete mapping": `{"type": "docker", "image": "nginx", "docker": {"port_mapping": [{"host_port": 31000}]}}`,
+		"mapping protocol":   `{"type": "docker", "image": "nginx", "docker": {"port_mapping": [{"host_port": 1, "container_port": 1, "protocol": "sctp"}]}}`,
+		"parameter key":      `{"type": "docker", "image": "nginx", "docker": {"parameters": [{"value": "x"}]}}`,
+		"linux settings":     `{"type": "docker", "image": "nginx", "capabilities": ["NET_ADMIN"]}`,
+	}
+	for name, data := range invalid {
+		if _, err := ParseContainer(parse(t, data)); err == nil {
+			t.Fatal("Expected an error for " + name)
+		}
+	}
+}
+
+// Ensures the pull policy turns into a forced pull unless docker's own setting is given.
+func TestParseContainer_DockerPullPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		data    string
+		force   *bool
+		invalid bool
+	}{
+		{"default", `{"type": "docker", "image": "nginx"}`, nil, false},
+		{"cached", `{"type": "docker", "image": "nginx", "pull_policy": "cached"}`, nil, false},
+		{"always", `{"type": "docker", "image": "nginx", "pull_policy": "Always"}`, utils.ProtoBool(true), false},
+		{"force pull wins", `{"type": "docker", "image": "nginx", "pull_policy": "always", "docker": {"force_pull": false}}`, utils.ProtoBool(false), false},
+		{"unknown", `{"type": "docker", "image": "nginx", "pull_policy": "sometimes"}`, nil, true},
+		{"pull config", `{"type": "docker", "image": "nginx", "pull_config": {"value": "{}"}}`, nil, true},
+	}
+
+	for _, test := range tests {
+		c, err := ParseContainer(parse(t, test.data))
+		if test.invalid {
+			if err == nil {
+				t.Fatal(test.name + ": expected an error")
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(test.name + ": " + err.Error())
+		}
+
+		force := c.GetDocker().ForcePullImage
+		if (force == nil) != (test.force == nil) || (force != nil && *force != *test.force) {
+			t.Fatal(test.name + ": unexpected forced pull")
+		}
+	}
+}
+
+// Ensures mesos images skip the agent's cache when pulled every launch and carry registry credentials.
+func TestParseContainer_MesosPullPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		data    string
+		cached  *bool
+		config  *mesos_v1.Secret_Type
+		invalid bool
+	}{
+		{"default", `{"image": "nginx", "tag": "1.13"}`, nil, nil, false},
+		{"cached", `{"image": "nginx", "pull_policy": "CACHED"}`, nil, nil, false},
+		{"always", `{"image": "nginx", "pull_policy": "always"}`, utils.ProtoBool(false), nil, false},
+		{"inline config", `{"image": "nginx", "pull_config": {"value": "{}"}}`, nil, mesos_v1.Secret_VALUE.Enum(), false},
+		{"referenced config", `{"image": "nginx", "pull_policy": "always", "pull_config": {"name": "registry"}}`, utils.ProtoBool(false), mesos_v1.Secret_REFERENCE.Enum(), false},
+		{"unknown", `{"image": "nginx", "pull_policy": "sometimes"}`, nil, nil, true},
+		{"invalid config", `{"image": "nginx", "pull_config": {}}`, nil, nil, true},
+		{"no image", `{"pull_policy": "always"}`, nil, nil, true},
+		{"no image config", `{"pull_config": {"value": "{}"}}`, nil, nil, true},
+	}
+
+	for _, test := range tests {
+		c, err := ParseContainer(parse(t, test.data))
+		if test.invalid {
+			if err == nil {
+				t.Fatal(test.name + ": expected an error")
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(test.name + ": " + err.Error())
+		}
+
+		image := c.GetMesos().GetImage()
+		if c.GetType() != mesos_v1.ContainerInfo_MESOS || image.GetType() != mesos_v1.Image_DOCKER {
+			t.Fatal(test.name + ": expected a docker image on the mesos containerizer")
+		}
+		if (image.Cached == nil) != (test.cached == nil) || (image.Cached != nil && *image.Cached != *test.cached) {
+			t.Fatal(test.name + ": unexpected image caching")
+		}
+
+		config := image.GetDocker().GetConfig()
+		if (config == nil) != (test.config == nil) || (config != nil && config.GetType() != *test.config) {
+			t.Fatal(test.name + ": unexpected pull config")
+		}
+	}
+
+	c, _ := ParseContainer(parse(t, tests[0].data))
+	if c.GetMesos().GetImage().GetDocker().GetName() != "nginx:1.13" {
+		t.Fatal("Image tag was not carried over")
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"errors"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/task"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+)
+
+// Parses the capabilities granted to the task.
+// Names are case insensitive and may include the CAP_ prefix.
+func ParseLinuxInfo(capabilities []string) (*mesos_v1.LinuxInfo, error) {
+	if len(capabilities) == 0 {
+		return nil, nil
+	}
+
+	if errs := task.CheckCapabilities("capabilities", capabilities); len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	caps := make([]mesos_v1.CapabilityInfo_Capability, 0, len(capabilities))
+	seen := make(map[mesos_v1.CapabilityInfo_Capability]struct{}, len(capabilities))
+	for _, c := range capabilities {
+		value, _ := task.Capability(c)
+		if _, ok := seen[value]; ok {
+			continue
+		}
+		seen[value] = struct{}{}
+		caps = append(caps, value)
+	}
+
+	return &mesos_v1.LinuxInfo{CapabilityInfo: &mesos_v1.CapabilityInfo{Capabilities: caps}}, nil
+}
+
+// Parses resource limits, types are case insensitive and may leave out the RLMT_ prefix.
+func ParseRLimits(rlimits []task.RLimitJSON) (*mesos_v1.RLimitInfo, error) {
+	if len(rlimits) == 0 {
+		return nil, nil
+	}
+
+	if errs := task.CheckRLimits("rlimits", rlimits); len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	info := &mesos_v1.RLimitInfo{}
+	for _, r := range rlimits {
+		value, _ := task.RLimitType(r.Type)
+		info.Rlimits = append(info.Rlimits, &mesos_v1.RLimitInfo_RLimit{
+			Type: value.Enum(),
+			Soft: r.Soft,
+			Hard: r.Hard,
+		})
+	}
+
+	return info, nil
+}
+
+// Parses the terminal attached to the task, a window size can only be given along with a TTY.
+func ParseTTYInfo(tty bool, size *task.WindowSizeJSON) (*mesos_v1.TTYInfo, error) {
+	if !tty {
+		if size != nil {
+			return nil, errors.New("A window size requires a TTY.")
+		}
+		return nil, nil
+	}
+
+	info := &mesos_v1.TTYInfo{}
+	if size != nil {
+		if size.Rows == 0 || size.Columns == 0 {
+			return nil, errors.New("Window sizes need both rows and columns.")
+		}
+		info.WindowSize = &mesos_v1.TTYInfo_WindowSize{
+			Rows:    utils.ProtoUint32(size.Rows),
+			Columns: utils.ProtoUint32(size.Columns),
+		}
+	}
+
+	return info, nil
+}
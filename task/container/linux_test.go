@@ -0,0 +1,167 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"encoding/json"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/task"
+	"testing"
+)
+
+// Ensures capability names are normalized, deduplicated and unknown ones are rejected.
+func TestParseLinuxInfo(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		caps []string
+		want []mesos_v1.CapabilityInfo_Capability
+		fail bool
+	}{
+		{"none", nil, nil, false},
+		{"names", []string{"net_admin", "CAP_SYS_ADMIN"}, []mesos_v1.CapabilityInfo_Capability{mesos_v1.CapabilityInfo_NET_ADMIN, mesos_v1.CapabilityInfo_SYS_ADMIN}, false},
+		{"duplicates", []string{"NET_ADMIN", "cap_net_admin"}, []mesos_v1.CapabilityInfo_Capability{mesos_v1.CapabilityInfo_NET_ADMIN}, false},
+		{"unknown", []string{"NET_ADMIN", "TIME_TRAVEL"}, nil, true},
+		{"unknown value", []string{"UNKNOWN"}, nil, true},
+	}
+
+	for _, test := range tests {
+		info, err := ParseLinuxInfo(test.caps)
+		if (err != nil) != test.fail {
+			t.Fatalf("%s: unexpected error state: %v", test.name, err)
+		}
+		if test.want == nil {
+			if info != nil {
+				t.Fatal(test.name + ": no Linux info should be returned")
+			}
+			continue
+		}
+
+		caps := info.GetCapabilityInfo().GetCapabilities()
+		if len(caps) != len(test.want) {
+			t.Fatalf("%s: expected %v, got %v", test.name, test.want, caps)
+		}
+		for i := range caps {
+			if caps[i] != test.want[i] {
+				t.Fatalf("%s: expected %v, got %v", test.name, test.want, caps)
+			}
+		}
+	}
+}
+
+// Measures performance of parsing capabilities.
+func BenchmarkParseLinuxInfo(b *testing.B) {
+	caps := []string{"NET_ADMIN", "cap_sys_admin", "chown"}
+	for n := 0; n < b.N; n++ {
+		ParseLinuxInfo(caps)
+	}
+}
+
+// Ensures rlimit types are normalized and unknown, repeated or half set limits are rejected.
+func TestParseRLimits(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		data  string
+		count int
+		fail  bool
+	}{
+		{"none", `[]`, 0, false},
+		{"limits", `[{"type": "nofile", "soft": 1024, "hard": 4096}, {"type": "RLMT_CORE"}]`, 2, false},
+		{"missing type", `[{"soft": 1, "hard": 1}]`, 0, true},
+		{"unknown type", `[{"type": "bogus"}]`, 0, true},
+		{"repeated", `[{"type": "nofile"}, {"type": "RLMT_NOFILE"}]`, 0, true},
+		{"soft only", `[{"type": "nofile", "soft": 1024}]`, 0, true},
+		{"hard only", `[{"type": "nofile", "hard": 1024}]`, 0, true},
+		{"soft over hard", `[{"type": "nofile", "soft": 4096, "hard": 1024}]`, 0, true},
+	}
+
+	for _, test := range tests {
+		var rlimits []task.RLimitJSON
+		if err := json.Unmarshal([]byte(test.data), &rlimits); err != nil {
+			t.Fatal(err.Error())
+		}
+
+		info, err := ParseRLimits(rlimits)
+		if (err != nil) != test.fail {
+			t.Fatalf("%s: unexpected error state: %v", test.name, err)
+		}
+		if len(info.GetRlimits()) != test.count {
+			t.Fatalf("%s: expected %d limits, got %d", test.name, test.count, len(info.GetRlimits()))
+		}
+		if test.count == 0 {
+			continue
+		}
+
+		nofile, core := info.GetRlimits()[0], info.GetRlimits()[1]
+		if nofile.GetType() != mesos_v1.RLimitInfo_RLimit_RLMT_NOFILE || nofile.GetSoft() != 1024 || nofile.GetHard() != 4096 {
+			t.Fatal(test.name + ": limits were not carried over")
+		}
+		if core.GetType() != mesos_v1.RLimitInfo_RLimit_RLMT_CORE || core.Soft != nil || core.Hard != nil {
+			t.Fatal(test.name + ": limits without values should be unlimited")
+		}
+	}
+}
+
+// Ensures a TTY is only attached when asked for and window sizes are complete.
+func TestParseTTYInfo(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		tty  bool
+		size *task.WindowSizeJSON
+		fail bool
+	}{
+		{"none", false, nil, false},
+		{"tty", true, nil, false},
+		{"window", true, &task.WindowSizeJSON{Rows: 24, Columns: 80}, false},
+		{"window without tty", false, &task.WindowSizeJSON{Rows: 24, Columns: 80}, true},
+		{"no rows", true, &task.WindowSizeJSON{Columns: 80}, true},
+		{"no columns", true, &task.WindowSizeJSON{Rows: 24}, true},
+	}
+
+	for _, test := range tests {
+		info, err := ParseTTYInfo(test.tty, test.size)
+		if (err != nil) != test.fail {
+			t.Fatalf("%s: unexpected error state: %v", test.name, err)
+		}
+		if err != nil {
+			continue
+		}
+		if (info != nil) != test.tty {
+			t.Fatal(test.name + ": a TTY should only be attached when requested")
+		}
+		if test.size == nil {
+			if info.GetWindowSize() != nil {
+				t.Fatal(test.name + ": no window size was requested")
+			}
+			continue
+		}
+		if info.GetWindowSize().GetRows() != test.size.Rows || info.GetWindowSize().GetColumns() != test.size.Columns {
+			t.Fatal(test.name + ": window size was not carried over")
+		}
+	}
+}
+
+// Measures performance of parsing a TTY.
+func BenchmarkParseTTYInfo(b *testing.B) {
+	size := &task.WindowSizeJSON{Rows: 24, Columns: 80}
+	for n := 0; n < b.N; n++ {
+		ParseTTYInfo(true, size)
+	}
+}
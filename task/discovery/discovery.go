@@ -0,0 +1,136 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discovery
+
+import (
+	"errors"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/task"
+	"github.com/verizonlabs/mesos-framework-sdk/task/labels"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"strings"
+)
+
+var (
+	NoServiceName     error = errors.New("Discovery info needs a service name.")
+	InvalidVisibility error = errors.New("Invalid visibility, accepted values are framework, cluster, external.")
+	InvalidPort       error = errors.New("Discovery ports must have a number greater than 0.")
+	DuplicatePortName error = errors.New("Discovery port names must be unique.")
+)
+
+func ParseDiscovery(json *task.DiscoveryJSON) (*mesos_v1.DiscoveryInfo, error) {
+	if json == nil {
+		return nil, nil
+	}
+	if json.Name == "" {
+		return nil, NoServiceName
+	}
+
+	visibility, err := parseVisibility(json.Visibility, mesos_v1.DiscoveryInfo_FRAMEWORK)
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := labels.ParseLabels(json.Labels)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &mesos_v1.DiscoveryInfo{
+		Visibility: visibility.Enum(),
+		Name:       utils.ProtoString(json.Name),
+		Labels:     l,
+	}
+	if json.Environment != "" {
+		d.Environment = utils.ProtoString(json.Environment)
+	}
+	if json.Location != "" {
+		d.Location = utils.ProtoString(json.Location)
+	}
+	if json.Version != "" {
+		d.Version = utils.ProtoString(json.Version)
+	}
+
+	if len(json.Ports) == 0 {
+		return d, nil
+	}
+
+	d.Ports = &mesos_v1.Ports{}
+	names := make(map[string]struct{}, len(json.Ports))
+	for _, p := range json.Ports {
+		port, err := parsePort(p, visibility)
+		if err != nil {
+			return nil, err
+		}
+		if p.Name != "" {
+			if _, ok := names[p.Name]; ok {
+				return nil, DuplicatePortName
+			}
+			names[p.Name] = struct{}{}
+		}
+		d.Ports.Ports = append(d.Ports.Ports, port)
+	}
+
+	return d, nil
+}
+
+func parsePort(json task.DiscoveryPortJSON, def mesos_v1.DiscoveryInfo_Visibility) (*mesos_v1.Port, error) {
+	if json.Number == 0 {
+		return nil, InvalidPort
+	}
+
+	visibility, err := parseVisibility(json.Visibility, def)
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := labels.ParseLabels(json.Labels)
+	if err != nil {
+		return nil, err
+	}
+
+	port := &mesos_v1.Port{
+		Number:     utils.ProtoUint32(json.Number),
+		Visibility: visibility.Enum(),
+		Labels:     l,
+	}
+	if json.Name != "" {
+		port.Name = utils.ProtoString(json.Name)
+	}
+	if json.Protocol != "" {
+		protocol := strings.ToLower(json.Protocol)
+		if protocol != "tcp" && protocol != "udp" {
+			return nil, errors.New("Invalid discovery port protocol, accepted values are tcp, udp.")
+		}
+		port.Protocol = utils.ProtoString(protocol)
+	}
+
+	return port, nil
+}
+
+func parseVisibility(v string, def mesos_v1.DiscoveryInfo_Visibility) (mesos_v1.DiscoveryInfo_Visibility, error) {
+	switch strings.ToLower(v) {
+	case "":
+		return def, nil
+	case "framework":
+		return mesos_v1.DiscoveryInfo_FRAMEWORK, nil
+	case "cluster":
+		return mesos_v1.DiscoveryInfo_CLUSTER, nil
+	case "external":
+		return mesos_v1.DiscoveryInfo_EXTERNAL, nil
+	default:
+		return def, InvalidVisibility
+	}
+}
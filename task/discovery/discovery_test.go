@@ -0,0 +1,107 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discovery
+
+import (
+	"encoding/json"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/task"
+	"testing"
+)
+
+func parse(t testing.TB, data string) *task.DiscoveryJSON {
+	var d task.DiscoveryJSON
+	if err := json.Unmarshal([]byte(data), &d); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	return &d
+}
+
+// Ensures discovery info is parsed and invalid names, visibilities and ports are rejected.
+func TestParseDiscovery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data string
+		err  error
+	}{
+		{"name only", `{"name": "web"}`, nil},
+		{"full", `{"name": "web", "visibility": "CLUSTER", "environment": "prod", "location": "dc1", "version": "1.0",
+			"labels": {"team": "a"}, "ports": [{"number": 80, "name": "http", "protocol": "TCP"}, {"number": 53, "protocol": "udp"}]}`, nil},
+		{"no name", `{"visibility": "cluster"}`, NoServiceName},
+		{"visibility", `{"name": "web", "visibility": "private"}`, InvalidVisibility},
+		{"port visibility", `{"name": "web", "ports": [{"number": 80, "visibility": "private"}]}`, InvalidVisibility},
+		{"port number", `{"name": "web", "ports": [{"name": "http"}]}`, InvalidPort},
+		{"port names", `{"name": "web", "ports": [{"number": 80, "name": "http"}, {"number": 8080, "name": "http"}]}`, DuplicatePortName},
+	}
+
+	for _, test := range tests {
+		d, err := ParseDiscovery(parse(t, test.data))
+		if err != test.err {
+			t.Fatalf("%s: expected %v, got %v", test.name, test.err, err)
+		}
+		if err == nil && d.GetName() != "web" {
+			t.Fatal(test.name + ": service name was not carried over")
+		}
+	}
+
+	if _, err := ParseDiscovery(parse(t, `{"name": "web", "ports": [{"number": 80, "protocol": "sctp"}]}`)); err == nil {
+		t.Fatal("Unknown port protocols should be rejected")
+	}
+	if d, err := ParseDiscovery(nil); d != nil || err != nil {
+		t.Fatal("Discovery info is optional")
+	}
+}
+
+// Measures performance of parsing discovery info.
+func BenchmarkParseDiscovery(b *testing.B) {
+	d := parse(b, `{"name": "web", "visibility": "cluster", "ports": [{"number": 80, "name": "http", "protocol": "tcp"}]}`)
+	for n := 0; n < b.N; n++ {
+		ParseDiscovery(d)
+	}
+}
+
+// Ensures ports default to the task's visibility and keep their own settings.
+func TestParseDiscovery_Ports(t *testing.T) {
+	t.Parallel()
+
+	d, err := ParseDiscovery(parse(t, `{"name": "web", "visibility": "cluster", "environment": "prod", "location": "dc1", "version": "1.0",
+		"ports": [{"number": 80, "name": "http", "protocol": "TCP", "labels": {"scheme": "http"}}, {"number": 9090, "visibility": "framework"}]}`))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if d.GetVisibility() != mesos_v1.DiscoveryInfo_CLUSTER || d.GetEnvironment() != "prod" || d.GetLocation() != "dc1" || d.GetVersion() != "1.0" {
+		t.Fatal("Discovery settings were not carried over")
+	}
+
+	ports := d.GetPorts().GetPorts()
+	if len(ports) != 2 {
+		t.Fatalf("Expected 2 ports, got %d", len(ports))
+	}
+	if ports[0].GetNumber() != 80 || ports[0].GetName() != "http" || ports[0].GetProtocol() != "tcp" ||
+		ports[0].GetVisibility() != mesos_v1.DiscoveryInfo_CLUSTER || len(ports[0].GetLabels().GetLabels()) != 1 {
+		t.Fatal("Port settings were not carried over")
+	}
+	if ports[1].GetVisibility() != mesos_v1.DiscoveryInfo_FRAMEWORK || ports[1].Name != nil || ports[1].Protocol != nil {
+		t.Fatal("Ports should keep their own visibility and leave unset fields empty")
+	}
+
+	d, _ = ParseDiscovery(parse(t, `{"name": "web"}`))
+	if d.GetVisibility() != mesos_v1.DiscoveryInfo_FRAMEWORK || d.Ports != nil || d.Environment != nil {
+		t.Fatal("Discovery should default to framework visibility without ports")
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package environment
+
+import (
+	"errors"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/task"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"sort"
+)
+
+var (
+	EmptyVariableName error = errors.New("Environment variables need a name.")
+	DuplicateVariable error = errors.New("Environment variable is defined more than once.")
+	InvalidSecret     error = errors.New("Secrets must set either a reference name or a value, not both.")
+)
+
+// Parses plain variables and secrets into an environment.
+// Variables are sorted by name so the same JSON always produces the same task.
+func ParseEnvironment(env map[string]string, secrets map[string]task.SecretJSON) (*mesos_v1.Environment, error) {
+	if len(env) == 0 && len(secrets) == 0 {
+		return nil, nil
+	}
+
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	e := &mesos_v1.Environment{}
+	for _, name := range names {
+		if name == "" {
+			return nil, EmptyVariableName
+		}
+		e.Variables = append(e.Variables, &mesos_v1.Environment_Variable{
+			Name:  utils.ProtoString(name),
+			Type:  mesos_v1.Environment_Variable_VALUE.Enum(),
+			Value: utils.ProtoString(env[name]),
+		})
+	}
+
+	names = make([]string, 0, len(secrets))
+	for name := range secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if name == "" {
+			return nil, EmptyVariableName
+		}
+		if _, ok := env[name]; ok {
+			return nil, DuplicateVariable
+		}
+
+		secret, err := ParseSecret(secrets[name])
+		if err != nil {
+			return nil, err
+		}
+		e.Variables = append(e.Variables, &mesos_v1.Environment_Variable{
+			Name:   utils.ProtoString(name),
+			Type:   mesos_v1.Environment_Variable_SECRET.Enum(),
+			Secret: secret,
+		})
+	}
+
+	return e, nil
+}
+
+func ParseSecret(json task.SecretJSON) (*mesos_v1.Secret, error) {
+	if (json.Name == nil) == (json.Value == nil) {
+		return nil, InvalidSecret
+	}
+
+	if json.Value != nil {
+		return &mesos_v1.Secret{
+			Type:  mesos_v1.Secret_VALUE.Enum(),
+			Value: &mesos_v1.Secret_Value{Data: []byte(*json.Value)},
+		}, nil
+	}
+
+	if *json.Name == "" {
+		return nil, InvalidSecret
+	}
+
+	return &mesos_v1.Secret{
+		Type:      mesos_v1.Secret_REFERENCE.Enum(),
+		Reference: &mesos_v1.Secret_Reference{Name: json.Name, Key: json.Key},
+	}, nil
+}
+
+// Adds the environment to the command, variables already set on the command cannot be redefined.
+func Apply(cmd *mesos_v1.CommandInfo, env *mesos_v1.Environment) error {
+	if env == nil {
+		return nil
+	}
+	if cmd == nil {
+		return errors.New("A command is required to set environment variables.")
+	}
+	if cmd.Environment == nil {
+		cmd.Environment = &mesos_v1.Environment{}
+	}
+
+	set := make(map[string]struct{}, len(cmd.Environment.Variables))
+	for _, v := range cmd.Environment.Variables {
+		set[v.GetName()] = struct{}{}
+	}
+	for _, v := range env.Variables {
+		if _, ok := set[v.GetName()]; ok {
+			return DuplicateVariable
+		}
+		cmd.Environment.Variables = append(cmd.Environment.Variables, v)
+	}
+
+	return nil
+}
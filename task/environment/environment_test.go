@@ -0,0 +1,146 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package environment
+
+import (
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/task"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"testing"
+)
+
+// Ensures variables and secrets are parsed in name order and invalid definitions are rejected.
+func TestParseEnvironment(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		secrets map[string]task.SecretJSON
+		names   []string
+		err     error
+	}{
+		{"empty", nil, nil, nil, nil},
+		{"variables", map[string]string{"B": "2", "A": "1"}, nil, []string{"A", "B"}, nil},
+		{"secrets", map[string]string{"C": "3"}, map[string]task.SecretJSON{
+			"B": {Value: utils.ProtoString("pass")},
+			"A": {Name: utils.ProtoString("db"), Key: utils.ProtoString("password")},
+		}, []string{"C", "A", "B"}, nil},
+		{"empty variable name", map[string]string{"": "1"}, nil, nil, EmptyVariableName},
+		{"empty secret name", nil, map[string]task.SecretJSON{"": {Value: utils.ProtoString("pass")}}, nil, EmptyVariableName},
+		{"duplicate", map[string]string{"A": "1"}, map[string]task.SecretJSON{"A": {Value: utils.ProtoString("pass")}}, nil, DuplicateVariable},
+		{"invalid secret", nil, map[string]task.SecretJSON{"A": {}}, nil, InvalidSecret},
+	}
+
+	for _, test := range tests {
+		e, err := ParseEnvironment(test.env, test.secrets)
+		if err != test.err {
+			t.Fatalf("%s: expected %v, got %v", test.name, test.err, err)
+		}
+		if err != nil {
+			continue
+		}
+		if test.names == nil {
+			if e != nil {
+				t.Fatal(test.name + ": an empty environment should parse to nil")
+			}
+			continue
+		}
+		if len(e.GetVariables()) != len(test.names) {
+			t.Fatalf("%s: expected %d variables, got %d", test.name, len(test.names), len(e.GetVariables()))
+		}
+		for i, v := range e.GetVariables() {
+			if v.GetName() != test.names[i] {
+				t.Fatalf("%s: expected %s at %d, got %s", test.name, test.names[i], i, v.GetName())
+			}
+			_, secret := test.secrets[v.GetName()]
+			if secret && (v.GetType() != mesos_v1.Environment_Variable_SECRET || v.GetSecret() == nil) {
+				t.Fatalf("%s: %s should be a secret", test.name, v.GetName())
+			}
+			if !secret && (v.GetType() != mesos_v1.Environment_Variable_VALUE || v.GetValue() != test.env[v.GetName()]) {
+				t.Fatalf("%s: %s should be a plain value", test.name, v.GetName())
+			}
+		}
+	}
+}
+
+// Measures performance of parsing an environment.
+func BenchmarkParseEnvironment(b *testing.B) {
+	env := map[string]string{"B": "2", "A": "1"}
+	secrets := map[string]task.SecretJSON{"C": {Value: utils.ProtoString("pass")}}
+	for n := 0; n < b.N; n++ {
+		ParseEnvironment(env, secrets)
+	}
+}
+
+// Ensures secrets are either a reference or a value.
+func TestParseSecret(t *testing.T) {
+	t.Parallel()
+
+	s, err := ParseSecret(task.SecretJSON{Value: utils.ProtoString("pass")})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.GetType() != mesos_v1.Secret_VALUE || string(s.GetValue().GetData()) != "pass" {
+		t.Fatal("Value secret was not parsed correctly")
+	}
+
+	s, err = ParseSecret(task.SecretJSON{Name: utils.ProtoString("db"), Key: utils.ProtoString("password")})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.GetType() != mesos_v1.Secret_REFERENCE || s.GetReference().GetName() != "db" || s.GetReference().GetKey() != "password" {
+		t.Fatal("Reference secret was not parsed correctly")
+	}
+
+	invalid := []task.SecretJSON{
+		{},
+		{Name: utils.ProtoString("db"), Value: utils.ProtoString("pass")},
+		{Name: utils.ProtoString("")},
+	}
+	for _, json := range invalid {
+		if _, err := ParseSecret(json); err != InvalidSecret {
+			t.Fatalf("Expected %v, got %v", InvalidSecret, err)
+		}
+	}
+}
+
+// Ensures the environment is appended to the command without redefining its variables.
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	env, err := ParseEnvironment(map[string]string{"A": "1"}, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if err := Apply(nil, nil); err != nil {
+		t.Fatal("A nil environment should be a no-op")
+	}
+	if err := Apply(nil, env); err == nil {
+		t.Fatal("An environment can't be applied without a command")
+	}
+
+	cmd := &mesos_v1.CommandInfo{}
+	if err := Apply(cmd, env); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(cmd.GetEnvironment().GetVariables()) != 1 {
+		t.Fatal("Environment was not applied to the command")
+	}
+	if err := Apply(cmd, env); err != DuplicateVariable {
+		t.Fatalf("Expected %v, got %v", DuplicateVariable, err)
+	}
+}
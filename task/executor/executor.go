@@ -0,0 +1,89 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"errors"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/task"
+	"github.com/verizonlabs/mesos-framework-sdk/task/command"
+	"github.com/verizonlabs/mesos-framework-sdk/task/container"
+	"github.com/verizonlabs/mesos-framework-sdk/task/labels"
+	taskresources "github.com/verizonlabs/mesos-framework-sdk/task/resources"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+)
+
+// Parses a custom executor, the master fills in the framework ID when the task is launched.
+func ParseExecutor(json *task.ExecutorJSON) (*mesos_v1.ExecutorInfo, error) {
+	if json == nil {
+		return nil, nil
+	}
+	if json.Id == "" {
+		return nil, errors.New("Executors need an ID.")
+	}
+	if json.Command == nil {
+		return nil, errors.New("Executors need a command to run.")
+	}
+
+	cmd, err := command.ParseCommandInfo(json.Command)
+	if err != nil {
+		return nil, errors.New("Invalid executor command: " + err.Error())
+	}
+
+	var res []*mesos_v1.Resource
+	if json.Resources != nil {
+		res, err = taskresources.ParseResources(json.Resources)
+		if err != nil {
+			return nil, errors.New("Invalid executor resources: " + err.Error())
+		}
+	}
+
+	con, err := container.ParseContainer(json.Container)
+	if err != nil {
+		return nil, errors.New("Invalid executor container: " + err.Error())
+	}
+
+	l, err := labels.ParseLabels(json.Labels)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &mesos_v1.ExecutorInfo{
+		Type:       mesos_v1.ExecutorInfo_CUSTOM.Enum(),
+		ExecutorId: &mesos_v1.ExecutorID{Value: utils.ProtoString(json.Id)},
+		Command:    cmd,
+		Resources:  res,
+		Container:  con,
+		Labels:     l,
+	}
+	if json.Name != "" {
+		e.Name = utils.ProtoString(json.Name)
+	}
+
+	return e, nil
+}
+
+// Launches the task under the executor, tasks run by an executor can't have their own command.
+func Attach(t *mesos_v1.TaskInfo, e *mesos_v1.ExecutorInfo) error {
+	if e == nil {
+		return nil
+	}
+	if t.Command != nil {
+		return errors.New("Tasks launched under an executor can't set a command.")
+	}
+	t.Executor = e
+
+	return nil
+}
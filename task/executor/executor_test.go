@@ -0,0 +1,107 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"encoding/json"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/task"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"testing"
+)
+
+func parse(t testing.TB, data string) *task.ExecutorJSON {
+	var e task.ExecutorJSON
+	if err := json.Unmarshal([]byte(data), &e); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	return &e
+}
+
+// Ensures custom executors are parsed and missing or invalid parts are rejected.
+func TestParseExecutor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data string
+		fail bool
+	}{
+		{"command", `{"id": "exec", "command": {"cmd": "./executor"}}`, false},
+		{"full", `{"id": "exec", "name": "Executor", "command": {"cmd": "./executor"}, "resources": {"cpu": 0.1, "mem": 32, "disk": {"size": 64}},
+			"container": {"type": "docker", "image": "executor"}, "labels": {"team": "a"}}`, false},
+		{"no id", `{"command": {"cmd": "./executor"}}`, true},
+		{"no command", `{"id": "exec"}`, true},
+		{"empty command", `{"id": "exec", "command": {}}`, true},
+		{"resources", `{"id": "exec", "command": {"cmd": "./executor"}, "resources": {"cpu": 0.1}}`, true},
+		{"container", `{"id": "exec", "command": {"cmd": "./executor"}, "container": {"type": "docker"}}`, true},
+	}
+
+	for _, test := range tests {
+		e, err := ParseExecutor(parse(t, test.data))
+		if (err != nil) != test.fail {
+			t.Fatalf("%s: unexpected error state: %v", test.name, err)
+		}
+		if err != nil {
+			continue
+		}
+		if e.GetType() != mesos_v1.ExecutorInfo_CUSTOM || e.GetExecutorId().GetValue() != "exec" || e.GetCommand().GetValue() != "./executor" {
+			t.Fatal(test.name + ": executor ID and command were not carried over")
+		}
+		if e.FrameworkId != nil {
+			t.Fatal(test.name + ": the framework ID is filled in by the master")
+		}
+	}
+
+	e, _ := ParseExecutor(parse(t, tests[1].data))
+	if e.GetName() != "Executor" || len(e.GetResources()) == 0 || e.GetContainer() == nil || len(e.GetLabels().GetLabels()) != 1 {
+		t.Fatal("Executor settings were not carried over")
+	}
+	if e, err := ParseExecutor(nil); e != nil || err != nil {
+		t.Fatal("Executors are optional")
+	}
+}
+
+// Measures performance of parsing an executor.
+func BenchmarkParseExecutor(b *testing.B) {
+	e := parse(b, `{"id": "exec", "command": {"cmd": "./executor"}, "resources": {"cpu": 0.1, "mem": 32, "disk": {"size": 64}}}`)
+	for n := 0; n < b.N; n++ {
+		ParseExecutor(e)
+	}
+}
+
+// Ensures tasks only take an executor when they don't have their own command.
+func TestAttach(t *testing.T) {
+	t.Parallel()
+
+	e, err := ParseExecutor(parse(t, `{"id": "exec", "command": {"cmd": "./executor"}}`))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	info := &mesos_v1.TaskInfo{}
+	if err := Attach(info, nil); err != nil || info.Executor != nil {
+		t.Fatal("A nil executor should be a no-op")
+	}
+	if err := Attach(info, e); err != nil || info.GetExecutor() != e {
+		t.Fatal("Executor was not attached")
+	}
+
+	info = &mesos_v1.TaskInfo{Command: &mesos_v1.CommandInfo{Value: utils.ProtoString("serve")}}
+	if err := Attach(info, e); err == nil {
+		t.Fatal("Tasks with a command can't be given an executor")
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
 	"github.com/verizonlabs/mesos-framework-sdk/task"
+	"github.com/verizonlabs/mesos-framework-sdk/task/command"
 	"github.com/verizonlabs/mesos-framework-sdk/utils"
 	"strings"
 )
@@ -32,9 +33,11 @@ var (
 	NoTCPHealthCheck       error = errors.New("No TCP health check was defined")
 	NoHTTPHealthCheck      error = errors.New("No HTTP health check was defined")
 	NoCommandHealthCheck   error = errors.New("No error health check was defined")
+	TooManyHealthChecks    error = errors.New("Only one health check can be defined per task.")
 )
 
 const (
+	MINIMUM_DELAY_SECONDS        float64 = 0.0
 	MINIMUM_INTERVAL_SECONDS     float64 = 15.0
 	MINIMUM_TIMEOUT_SECONDS      float64 = 1.0
 	MINIMUM_GRACE_PERIOD_SECONDS float64 = 1.0
@@ -80,11 +83,27 @@ func ParseHealthCheck(json *task.HealthCheckJSON, c *mesos_v1.CommandInfo) (*mes
 		hc.Http = http
 	case "command":
 		hc.Type = mesos_v1.HealthCheck_COMMAND.Enum()
+
+		// Checks can run their own command, otherwise the task's command is used.
+		if json.Command != nil {
+			cmd, err := command.ParseCommandInfo(json.Command)
+			if err != nil {
+				return nil, err
+			}
+			c = cmd
+		}
+		if c == nil {
+			return nil, NoCommandHealthCheck
+		}
+
 		hc.Command = c
 	default:
 		return nil, InvalidHealthCheckType
 	}
 
+	if json.DelaySeconds != nil && *json.DelaySeconds >= MINIMUM_DELAY_SECONDS {
+		hc.DelaySeconds = json.DelaySeconds
+	}
 	if json.TimeoutSeconds != nil && *json.TimeoutSeconds >= MINIMUM_TIMEOUT_SECONDS {
 		hc.TimeoutSeconds = json.TimeoutSeconds
 	}
@@ -101,6 +120,19 @@ func ParseHealthCheck(json *task.HealthCheckJSON, c *mesos_v1.CommandInfo) (*mes
 	return hc, nil
 }
 
+// Parses the health checks defined on a task's container.
+// Nil is returned when there are none since health checking is optional.
+func ParseHealthChecks(checks []task.HealthCheckJSON, c *mesos_v1.CommandInfo) (*mesos_v1.HealthCheck, error) {
+	switch len(checks) {
+	case 0:
+		return nil, nil
+	case 1:
+		return ParseHealthCheck(&checks[0], c)
+	default:
+		return nil, TooManyHealthChecks
+	}
+}
+
 func parseTcpHealthCheck(json *task.TCPHealthCheck) (*mesos_v1.HealthCheck_TCPCheckInfo, error) {
 	tcp := &mesos_v1.HealthCheck_TCPCheckInfo{}
 	if json.Port > MIN_PORT && json.Port < MAX_PORT {
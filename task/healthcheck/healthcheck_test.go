@@ -0,0 +1,151 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"encoding/json"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/task"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"testing"
+)
+
+func parse(t testing.TB, data string) []task.HealthCheckJSON {
+	var checks []task.HealthCheckJSON
+	if err := json.Unmarshal([]byte(data), &checks); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	return checks
+}
+
+// Ensures each type of check is parsed and broken checks are rejected with the matching error.
+func TestParseHealthChecks(t *testing.T) {
+	t.Parallel()
+
+	cmd := &mesos_v1.CommandInfo{Value: utils.ProtoString("serve")}
+	tests := []struct {
+		name      string
+		data      string
+		checkType mesos_v1.HealthCheck_Type
+		err       error
+	}{
+		{"tcp", `[{"type": "TCP", "tcp": {"port": 8080}}]`, mesos_v1.HealthCheck_TCP, nil},
+		{"http", `[{"type": "http", "http": {"scheme": "https", "port": 8443, "path": "/health", "statuses": [200, 204]}}]`, mesos_v1.HealthCheck_HTTP, nil},
+		{"command", `[{"type": "command", "command": {"cmd": "check"}}]`, mesos_v1.HealthCheck_COMMAND, nil},
+		{"task command", `[{"type": "command"}]`, mesos_v1.HealthCheck_COMMAND, nil},
+		{"none", `[]`, 0, nil},
+		{"two", `[{"type": "tcp", "tcp": {"port": 80}}, {"type": "tcp", "tcp": {"port": 81}}]`, 0, TooManyHealthChecks},
+		{"no type", `[{"tcp": {"port": 80}}]`, 0, NoHealthCheckType},
+		{"unknown type", `[{"type": "udp"}]`, 0, InvalidHealthCheckType},
+		{"no tcp", `[{"type": "tcp"}]`, 0, NoTCPHealthCheck},
+		{"tcp port too low", `[{"type": "tcp", "tcp": {"port": 0}}]`, 0, InvalidPortRange},
+		{"tcp port too high", `[{"type": "tcp", "tcp": {"port": 65535}}]`, 0, InvalidPortRange},
+		{"no http", `[{"type": "http"}]`, 0, NoHTTPHealthCheck},
+		{"http scheme", `[{"type": "http", "http": {"scheme": "ftp", "path": "/"}}]`, 0, UnsupportedScheme},
+		{"http path", `[{"type": "http", "http": {"scheme": "http"}}]`, 0, NoHTTPPath},
+	}
+
+	for _, test := range tests {
+		hc, err := ParseHealthChecks(parse(t, test.data), cmd)
+		if err != test.err {
+			t.Fatalf("%s: expected %v, got %v", test.name, test.err, err)
+		}
+		if err != nil {
+			continue
+		}
+		if test.data == `[]` {
+			if hc != nil {
+				t.Fatal(test.name + ": no checks should parse to nil")
+			}
+			continue
+		}
+		if hc.GetType() != test.checkType {
+			t.Fatalf("%s: expected a %s check, got %s", test.name, test.checkType, hc.GetType())
+		}
+	}
+}
+
+// Measures performance of parsing a health check.
+func BenchmarkParseHealthChecks(b *testing.B) {
+	checks := parse(b, `[{"type": "http", "http": {"scheme": "http", "port": 80, "path": "/health"}, "interval": 30}]`)
+	for n := 0; n < b.N; n++ {
+		ParseHealthChecks(checks, nil)
+	}
+}
+
+// Ensures the check's settings are carried over and command checks fall back to the task's command.
+func TestParseHealthCheck(t *testing.T) {
+	t.Parallel()
+
+	checks := parse(t, `[{"type": "http", "http": {"scheme": "HTTP", "port": 8080, "path": "/health", "statuses": [200]}}]`)
+	hc, err := ParseHealthCheck(&checks[0], nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	http := hc.GetHttp()
+	if http.GetScheme() != "HTTP" || http.GetPort() != 8080 || http.GetPath() != "/health" || len(http.GetStatuses()) != 1 {
+		t.Fatal("HTTP check settings were not carried over")
+	}
+
+	checks = parse(t, `[{"type": "tcp", "tcp": {"port": 80}}]`)
+	if hc, _ = ParseHealthCheck(&checks[0], nil); hc.GetTcp().GetPort() != 80 {
+		t.Fatal("TCP check port was not carried over")
+	}
+
+	cmd := &mesos_v1.CommandInfo{Value: utils.ProtoString("serve")}
+	checks = parse(t, `[{"type": "command"}, {"type": "command", "command": {"cmd": "check"}}]`)
+	if hc, _ = ParseHealthCheck(&checks[0], cmd); hc.GetCommand() != cmd {
+		t.Fatal("Command checks without their own command should use the task's")
+	}
+	if hc, _ = ParseHealthCheck(&checks[1], cmd); hc.GetCommand().GetValue() != "check" {
+		t.Fatal("Command checks should prefer their own command")
+	}
+	if _, err := ParseHealthCheck(&checks[0], nil); err != NoCommandHealthCheck {
+		t.Fatal("Command checks need a command from somewhere")
+	}
+
+	if hc, err := ParseHealthCheck(nil, cmd); hc != nil || err != nil {
+		t.Fatal("Health checks are optional")
+	}
+}
+
+// Ensures timings below their minimums are left for Mesos to default.
+func TestParseHealthCheck_Timings(t *testing.T) {
+	t.Parallel()
+
+	checks := parse(t, `[
+		{"type": "tcp", "tcp": {"port": 80}, "delay": 5, "interval": 30, "timeout": 2, "graceperiod": 60, "fails": 3},
+		{"type": "tcp", "tcp": {"port": 80}, "delay": -1, "interval": 1, "timeout": 0.5, "graceperiod": 0, "fails": 0}
+	]`)
+
+	hc, err := ParseHealthCheck(&checks[0], nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if hc.GetDelaySeconds() != 5 || hc.GetIntervalSeconds() != 30 || hc.GetTimeoutSeconds() != 2 ||
+		hc.GetGracePeriodSeconds() != 60 || hc.GetConsecutiveFailures() != 3 {
+		t.Fatal("Timings were not carried over")
+	}
+
+	hc, err = ParseHealthCheck(&checks[1], nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if hc.DelaySeconds != nil || hc.IntervalSeconds != nil || hc.TimeoutSeconds != nil ||
+		hc.GracePeriodSeconds != nil || hc.ConsecutiveFailures != nil {
+		t.Fatal("Timings below their minimums should be left unset")
+	}
+}
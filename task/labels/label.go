@@ -18,15 +18,24 @@ import (
 	"errors"
 	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
 	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"sort"
 )
 
+// Labels are sorted by key so the same JSON always produces the same task.
 func ParseLabels(labels map[string]string) (*mesos_v1.Labels, error) {
 	if labels == nil {
 		return nil, nil
 	}
 
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
 	l := &mesos_v1.Labels{}
-	for name, value := range labels {
+	for _, name := range names {
+		value := labels[name]
 		if name == "" || value == "" {
 			return nil, errors.New("Empty key or value passed in")
 		}
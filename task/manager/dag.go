@@ -0,0 +1,222 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"errors"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"sync"
+)
+
+var (
+	ErrCycle             = errors.New("Task dependencies form a cycle.")
+	ErrMissingDependency = errors.New("A task depends on a task that doesn't exist.")
+)
+
+// Releases tasks to the pending queue only once every task they depend on is running or finished.
+// Dependencies are task names, so simple batch pipelines can be built out of plain task definitions.
+// Tasks whose dependencies fail keep waiting, since the dependency may still be relaunched.
+// Every status update must be passed to Update so waiting tasks are released.
+type DAG struct {
+	tasks     TaskManager
+	queue     func(...*Task) error
+	lock      sync.Mutex
+	waiting   map[string]*Task // Task name to a task held back by its dependencies.
+	satisfied map[string]bool  // Names of tasks that have been running or finished.
+}
+
+// Released tasks are handed to the queue, which is usually an offer coordinator's Queue method.
+func NewDAG(tasks TaskManager, queue func(...*Task) error) *DAG {
+	return &DAG{
+		tasks:     tasks,
+		queue:     queue,
+		waiting:   make(map[string]*Task),
+		satisfied: make(map[string]bool),
+	}
+}
+
+// Adds the tasks to the task manager and queues the ones that have nothing left to wait on.
+// Tasks can depend on each other, on tasks that are still waiting or on tasks already in the task manager.
+func (d *DAG) Submit(tasks ...*Task) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	submitted := make(map[string]*Task, len(tasks))
+	for _, t := range tasks {
+		submitted[t.Info.GetName()] = t
+	}
+	for _, t := range tasks {
+		for _, dep := range t.DependsOn {
+			if _, ok := submitted[dep]; ok {
+				continue
+			}
+			if _, ok := d.waiting[dep]; ok {
+				continue
+			}
+			if _, err := d.tasks.Get(&dep); err != nil {
+				return ErrMissingDependency
+			}
+		}
+	}
+
+	// Tasks that already exist can't depend on new ones, so cycles can only run through the new and waiting tasks.
+	if d.cyclic(submitted) {
+		return ErrCycle
+	}
+
+	if err := d.tasks.Add(tasks...); err != nil {
+		return err
+	}
+	for _, t := range tasks {
+		d.waiting[t.Info.GetName()] = t
+	}
+
+	return d.release()
+}
+
+// Releases the tasks that were waiting on the task in the status update once it's running or finished.
+func (d *DAG) Update(status *mesos_v1.TaskStatus) error {
+	if status.GetState() != RUNNING && status.GetState() != FINISHED {
+		return nil
+	}
+
+	t, err := d.tasks.GetById(status.GetTaskId())
+	if err == ErrTaskNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.satisfied[t.Info.GetName()] = true
+
+	return d.release()
+}
+
+// Gets the tasks still held back by their dependencies.
+func (d *DAG) Waiting() []*Task {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	waiting := make([]*Task, 0, len(d.waiting))
+	for _, t := range d.waiting {
+		waiting = append(waiting, t)
+	}
+
+	return waiting
+}
+
+// Stops holding back a task, such as when it's deleted, without queueing it.
+func (d *DAG) Remove(name string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	delete(d.waiting, name)
+}
+
+// Must be called with the lock held.
+func (d *DAG) release() error {
+	var ready []*Task
+	for _, t := range d.waiting {
+		if d.ready(t) {
+			ready = append(ready, t)
+		}
+	}
+	if len(ready) == 0 {
+		return nil
+	}
+
+	// Tasks keep waiting if they can't be queued so the next release tries them again.
+	if err := d.queue(ready...); err != nil {
+		return err
+	}
+	for _, t := range ready {
+		delete(d.waiting, t.Info.GetName())
+	}
+
+	return nil
+}
+
+// Must be called with the lock held.
+func (d *DAG) ready(t *Task) bool {
+	for _, dep := range t.DependsOn {
+		if d.satisfied[dep] {
+			continue
+		}
+
+		// Tasks that were running before they were submitted here never pass through Update.
+		other, err := d.tasks.Get(&dep)
+		if err != nil {
+			return false
+		}
+		other.lock.Lock()
+		state := other.State
+		other.lock.Unlock()
+		if state != RUNNING && state != FINISHED {
+			return false
+		}
+		d.satisfied[dep] = true
+	}
+
+	return true
+}
+
+// Must be called with the lock held.
+// Walks the dependencies of the submitted and waiting tasks looking for one that leads back to itself.
+func (d *DAG) cyclic(submitted map[string]*Task) bool {
+	const (
+		visiting = iota + 1
+		done
+	)
+
+	marks := make(map[string]int)
+	var visit func(name string) bool
+	visit = func(name string) bool {
+		t, ok := submitted[name]
+		if !ok {
+			if t, ok = d.waiting[name]; !ok {
+				return false
+			}
+		}
+
+		switch marks[name] {
+		case visiting:
+			return true
+		case done:
+			return false
+		}
+
+		marks[name] = visiting
+		for _, dep := range t.DependsOn {
+			if visit(dep) {
+				return true
+			}
+		}
+		marks[name] = done
+
+		return false
+	}
+
+	for name := range submitted {
+		if visit(name) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,315 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"errors"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/persistence"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Default prefix tasks are persisted under.
+const TASK_PREFIX = "/tasks"
+
+var (
+	ErrTaskExists   = errors.New("A task with the same name already exists.")
+	ErrTaskNotFound = errors.New("No task exists with the given name or ID.")
+)
+
+// A task manager implementation that keeps tasks in memory, keyed by name.
+// Every change is written through to storage when set so a restarted scheduler can load its tasks back.
+// It is safe for concurrent use.
+type DefaultTaskManager struct {
+	lock      sync.RWMutex
+	tasks     map[string]*Task
+	ids       map[string]string // Task ID to task name.
+	groups    map[string]*group // Task group name to its desired state.
+	storage   persistence.KeyValueStore
+	prefix    string
+	scaleDown ScalePolicy
+}
+
+// Storage is optional, tasks are only kept in memory without it.
+func NewDefaultTaskManager(storage persistence.KeyValueStore, prefix string) *DefaultTaskManager {
+	return &DefaultTaskManager{
+		tasks:     make(map[string]*Task),
+		ids:       make(map[string]string),
+		groups:    make(map[string]*group),
+		storage:   storage,
+		prefix:    prefix,
+		scaleDown: NEWEST_FIRST,
+	}
+}
+
+// Restores every persisted task along with the desired state of task groups.
+func (m *DefaultTaskManager) Load() error {
+	if m.storage == nil {
+		return nil
+	}
+
+	kvs, err := m.storage.ReadAll(m.prefix + "/")
+	if err != nil {
+		return err
+	}
+
+	for key, value := range kvs {
+		t, err := new(Task).Decode([]byte(value))
+		if err != nil {
+			return errors.New("Failed to decode the task at " + key + ": " + err.Error())
+		}
+		m.Restore(t)
+	}
+
+	return m.loadGroups()
+}
+
+// Adds new tasks, none are added if any of them already exist.
+func (m *DefaultTaskManager) Add(tasks ...*Task) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, t := range tasks {
+		if _, ok := m.tasks[t.Info.GetName()]; ok {
+			return ErrTaskExists
+		}
+	}
+
+	for _, t := range tasks {
+		if t.Created == 0 {
+			t.Created = time.Now().UnixNano()
+		}
+		if err := m.persist(t); err != nil {
+			return err
+		}
+		m.set(t)
+	}
+
+	return nil
+}
+
+// Puts a task back without persisting it, such as when loading it from storage.
+func (m *DefaultTaskManager) Restore(t *Task) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.set(t)
+}
+
+func (m *DefaultTaskManager) Delete(tasks ...*Task) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, t := range tasks {
+		name := t.Info.GetName()
+		if m.storage != nil {
+			if err := m.storage.Delete(m.key(name)); err != nil {
+				return err
+			}
+		}
+
+		if existing, ok := m.tasks[name]; ok {
+			delete(m.ids, existing.Info.GetTaskId().GetValue())
+		}
+		delete(m.tasks, name)
+	}
+
+	return nil
+}
+
+func (m *DefaultTaskManager) Get(name *string) (*Task, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	t, ok := m.tasks[*name]
+	if !ok {
+		return nil, ErrTaskNotFound
+	}
+
+	return t, nil
+}
+
+// Gets every task in the same group as the given one, including itself.
+func (m *DefaultTaskManager) GetGroup(t *Task) ([]*Task, error) {
+	if !t.GroupInfo.InGroup {
+		return []*Task{t}, nil
+	}
+
+	return m.filter(func(other *Task) bool {
+		return other.GroupInfo.InGroup && other.GroupInfo.GroupName == t.GroupInfo.GroupName
+	}), nil
+}
+
+func (m *DefaultTaskManager) GetById(id *mesos_v1.TaskID) (*Task, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	t, ok := m.tasks[m.ids[id.GetValue()]]
+	if !ok {
+		return nil, ErrTaskNotFound
+	}
+
+	return t, nil
+}
+
+func (m *DefaultTaskManager) HasTask(info *mesos_v1.TaskInfo) bool {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	_, ok := m.tasks[info.GetName()]
+
+	return ok
+}
+
+// Replaces existing tasks and persists them, state changes aren't checked.
+func (m *DefaultTaskManager) Update(tasks ...*Task) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, t := range tasks {
+		existing, ok := m.tasks[t.Info.GetName()]
+		if !ok {
+			return ErrTaskNotFound
+		}
+		if err := m.persist(t); err != nil {
+			return err
+		}
+
+		delete(m.ids, existing.Info.GetTaskId().GetValue())
+		m.set(t)
+	}
+
+	return nil
+}
+
+// Moves a task to the state from its status update and persists it.
+// A TransitionError is returned, and nothing changes, if the task can't reach that state from its current one.
+func (m *DefaultTaskManager) Transition(id *mesos_v1.TaskID, state mesos_v1.TaskState) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	t, ok := m.tasks[m.ids[id.GetValue()]]
+	if !ok {
+		return ErrTaskNotFound
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if !CanTransition(t.State, state) {
+		return TransitionError{From: t.State, To: state}
+	}
+
+	previous := t.State
+	t.State = state
+	if err := m.persist(t); err != nil {
+		t.State = previous
+		return err
+	}
+
+	return nil
+}
+
+// Moves a task to the state in its status update like Transition, also recording the result of its health check.
+func (m *DefaultTaskManager) UpdateStatus(status *mesos_v1.TaskStatus) error {
+	if err := m.Transition(status.GetTaskId(), status.GetState()); err != nil || status.Healthy == nil {
+		return err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	t, ok := m.tasks[m.ids[status.GetTaskId().GetValue()]]
+	if !ok {
+		return ErrTaskNotFound
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	healthy := status.GetHealthy()
+	t.Healthy = &healthy
+
+	return m.persist(t)
+}
+
+// Gets the tasks in the state, ordered by name.
+func (m *DefaultTaskManager) AllByState(state mesos_v1.TaskState) ([]*Task, error) {
+	return m.filter(func(t *Task) bool {
+		return t.State == state
+	}), nil
+}
+
+func (m *DefaultTaskManager) TotalTasks() int {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return len(m.tasks)
+}
+
+// Gets every task, ordered by name.
+func (m *DefaultTaskManager) All() ([]*Task, error) {
+	return m.filter(func(*Task) bool {
+		return true
+	}), nil
+}
+
+func (m *DefaultTaskManager) filter(keep func(*Task) bool) []*Task {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	names := make([]string, 0, len(m.tasks))
+	for name := range m.tasks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var tasks []*Task
+	for _, name := range names {
+		if t := m.tasks[name]; keep(t) {
+			tasks = append(tasks, t)
+		}
+	}
+
+	return tasks
+}
+
+// Must be called with the lock held.
+func (m *DefaultTaskManager) set(t *Task) {
+	name := t.Info.GetName()
+	m.tasks[name] = t
+	if id := t.Info.GetTaskId().GetValue(); id != "" {
+		m.ids[id] = name
+	}
+}
+
+func (m *DefaultTaskManager) persist(t *Task) error {
+	if m.storage == nil {
+		return nil
+	}
+
+	data, err := t.Encode()
+	if err != nil {
+		return err
+	}
+
+	return m.storage.Update(m.key(t.Info.GetName()), string(data))
+}
+
+func (m *DefaultTaskManager) key(name string) string {
+	return m.prefix + "/" + name
+}
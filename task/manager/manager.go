@@ -70,6 +70,10 @@ type Task struct {
 	IsKill    bool
 	GroupInfo GroupInfo
 	Strategy  task.Strategy
+	Priority  string   // Priority class the task is queued under while it waits for offers.
+	Healthy   *bool    // Result of the task's last health check, nil until one is reported.
+	Created   int64    // Unix time in nanoseconds the task was added to the task manager.
+	DependsOn []string // Names of the tasks that must be running or finished before this one is queued.
 }
 
 type GroupInfo struct {
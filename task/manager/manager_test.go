@@ -0,0 +1,403 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"errors"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/persistence/drivers/memory"
+	"github.com/verizonlabs/mesos-framework-sdk/task/retry"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testTask(name string, state mesos_v1.TaskState) *Task {
+	return NewTask(&mesos_v1.TaskInfo{
+		Name:   utils.ProtoString(name),
+		TaskId: &mesos_v1.TaskID{Value: utils.ProtoString(name + "-id")},
+	}, state, nil, nil, 1, GroupInfo{})
+}
+
+// Ensures only valid lifecycle transitions are allowed.
+func TestCanTransition(t *testing.T) {
+	t.Parallel()
+
+	valid := [][2]mesos_v1.TaskState{
+		{STAGING, RUNNING},
+		{STARTING, RUNNING},
+		{RUNNING, KILLING},
+		{KILLING, KILLED},
+		{RUNNING, FAILED},
+		{RUNNING, UNREACHABLE},
+		{UNREACHABLE, RUNNING},
+		{UNKNOWN, RUNNING},
+		{FAILED, STAGING},
+		{RUNNING, RUNNING},
+	}
+	for _, v := range valid {
+		if !CanTransition(v[0], v[1]) {
+			t.Fatal(v[0].String() + " should be able to go to " + v[1].String())
+		}
+	}
+
+	invalid := [][2]mesos_v1.TaskState{
+		{RUNNING, STAGING},
+		{RUNNING, STARTING},
+		{KILLING, RUNNING},
+		{FINISHED, RUNNING},
+		{KILLED, FAILED},
+	}
+	for _, v := range invalid {
+		if CanTransition(v[0], v[1]) {
+			t.Fatal(v[0].String() + " should not be able to go to " + v[1].String())
+		}
+	}
+}
+
+// Measures checking a transition.
+func BenchmarkCanTransition(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		CanTransition(UNREACHABLE, RUNNING)
+	}
+}
+
+// Ensures tasks are tracked, persisted through transitions and loaded back.
+func TestDefaultTaskManager(t *testing.T) {
+	t.Parallel()
+
+	storage := memory.NewClient()
+	m := NewDefaultTaskManager(storage, TASK_PREFIX)
+	if err := m.Add(testTask("a", STAGING), testTask("b", STAGING)); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := m.Add(testTask("a", STAGING)); err != ErrTaskExists {
+		t.Fatal("Tasks with the same name should not be added twice")
+	}
+
+	id := &mesos_v1.TaskID{Value: utils.ProtoString("a-id")}
+	if err := m.Transition(id, RUNNING); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := m.Transition(id, STAGING); err == nil {
+		t.Fatal("Running tasks should not go back to staging")
+	} else if _, ok := err.(TransitionError); !ok {
+		t.Fatal("Invalid transitions should return a TransitionError")
+	}
+	if err := m.Transition(&mesos_v1.TaskID{Value: utils.ProtoString("missing")}, RUNNING); err != ErrTaskNotFound {
+		t.Fatal("Transitions for unknown tasks should fail")
+	}
+
+	running, _ := m.AllByState(RUNNING)
+	if len(running) != 1 || running[0].Info.GetName() != "a" {
+		t.Fatal("Only the running task should be returned")
+	}
+
+	loaded := NewDefaultTaskManager(storage, TASK_PREFIX)
+	if err := loaded.Load(); err != nil {
+		t.Fatal(err.Error())
+	}
+	if task, err := loaded.GetById(id); err != nil || task.State != RUNNING || loaded.TotalTasks() != 2 {
+		t.Fatal("Persisted tasks should be loaded with their latest state")
+	}
+
+	b, _ := m.Get(utils.ProtoString("b"))
+	if err := m.Delete(b); err != nil || m.HasTask(b.Info) {
+		t.Fatal("Deleted tasks should be removed")
+	}
+	if value, _ := storage.Read(TASK_PREFIX + "/b"); value != "" {
+		t.Fatal("Deleted tasks should be removed from storage")
+	}
+}
+
+// Measures moving a task through its lifecycle.
+func BenchmarkDefaultTaskManager(b *testing.B) {
+	m := NewDefaultTaskManager(nil, TASK_PREFIX)
+	task := testTask("a", STAGING)
+	m.Add(task)
+	for n := 0; n < b.N; n++ {
+		m.Transition(task.Info.TaskId, RUNNING)
+		m.Transition(task.Info.TaskId, FAILED)
+		m.Transition(task.Info.TaskId, STAGING)
+	}
+}
+
+// Ensures failed tasks are relaunched with backoff until they run out of retries.
+func TestRelauncher(t *testing.T) {
+	t.Parallel()
+
+	m := NewDefaultTaskManager(nil, TASK_PREFIX)
+	failing := testTask("failing", RUNNING)
+	failing.Retry = &retry.TaskRetry{Policy: retry.ON_FAILURE, MaxRetries: 2, Backoff: true}
+	finished := testTask("finished", RUNNING)
+	m.Add(failing, finished)
+
+	queued := make(chan *Task, 1)
+	r := NewRelauncher(m, func(tasks ...*Task) error {
+		queued <- tasks[0]
+		return nil
+	})
+	r.min = time.Millisecond
+	defer r.Stop()
+
+	for i := 0; i < 2; i++ {
+		scheduled, err := r.Update(&mesos_v1.TaskStatus{TaskId: failing.Info.TaskId, State: FAILED.Enum()})
+		if err != nil || !scheduled {
+			t.Fatal("Failed tasks should be relaunched while they have retries left")
+		}
+
+		select {
+		case task := <-queued:
+			if task.State != STAGING {
+				t.Fatal("Relaunched tasks should be staged again")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("The task was never queued again")
+		}
+	}
+
+	if scheduled, _ := r.Update(&mesos_v1.TaskStatus{TaskId: failing.Info.TaskId, State: FAILED.Enum()}); scheduled {
+		t.Fatal("Tasks should not be relaunched once they're out of retries")
+	}
+	if scheduled, _ := r.Update(&mesos_v1.TaskStatus{TaskId: finished.Info.TaskId, State: FINISHED.Enum()}); scheduled {
+		t.Fatal("Finished tasks should not be relaunched on failure")
+	}
+
+	if d := r.delay(&retry.TaskRetry{RetryTime: time.Minute, Backoff: true, TotalRetries: 10}); d != MAX_RELAUNCH_DELAY {
+		t.Fatal("Backoff should be capped, got " + d.String())
+	}
+}
+
+// Task manager that fails a single update.
+type flakyTaskManager struct {
+	TaskManager
+	mu    sync.Mutex
+	calls int
+	fail  int // The update call that fails, counting from 1.
+}
+
+func (f *flakyTaskManager) Update(tasks ...*Task) error {
+	f.mu.Lock()
+	f.calls++
+	call := f.calls
+	f.mu.Unlock()
+
+	if call == f.fail {
+		return errors.New("Storage unavailable.")
+	}
+
+	return f.TaskManager.Update(tasks...)
+}
+
+// Ensures a relaunch that can't be saved is reported and tried again.
+func TestRelauncher_UpdateFailure(t *testing.T) {
+	t.Parallel()
+
+	// The first update saves the retry attempt, the second saves the relaunch.
+	m := &flakyTaskManager{TaskManager: NewDefaultTaskManager(nil, TASK_PREFIX), fail: 2}
+	failing := testTask("failing", RUNNING)
+	m.Add(failing)
+
+	queued := make(chan *Task, 1)
+	failed := make(chan error, 1)
+	r := NewRelauncher(m, func(tasks ...*Task) error {
+		queued <- tasks[0]
+		return nil
+	})
+	r.min = time.Millisecond
+	r.Failed = func(task *Task, err error) {
+		failed <- err
+	}
+	defer r.Stop()
+
+	if scheduled, err := r.Update(&mesos_v1.TaskStatus{TaskId: failing.Info.TaskId, State: FAILED.Enum()}); err != nil || !scheduled {
+		t.Fatal("Failed tasks should be relaunched")
+	}
+
+	select {
+	case err := <-failed:
+		if err.Error() != "Storage unavailable." {
+			t.Fatal("Unexpected relaunch error " + err.Error())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("The failed relaunch was never reported")
+	}
+	select {
+	case task := <-queued:
+		if task.State != STAGING {
+			t.Fatal("Relaunched tasks should be staged again")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("The relaunch was never tried again")
+	}
+}
+
+// Measures performance of arming relaunch timers.
+func BenchmarkRelauncher_UpdateFailure(b *testing.B) {
+	r := NewRelauncher(NewDefaultTaskManager(nil, TASK_PREFIX), func(...*Task) error { return nil })
+	task := testTask("failing", RUNNING)
+	for n := 0; n < b.N; n++ {
+		r.lock.Lock()
+		r.schedule(task, "failing", time.Hour)
+		r.lock.Unlock()
+		r.Stop()
+	}
+}
+
+// Measures deciding whether a task restarts.
+func BenchmarkRelauncher(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		restarts(retry.ON_FAILURE, FAILED)
+	}
+}
+
+// Ensures groups scale up from their definition, scale down by policy and persist their desired count.
+func TestDefaultTaskManager_Scale(t *testing.T) {
+	t.Parallel()
+
+	storage := memory.NewClient()
+	m := NewDefaultTaskManager(storage, TASK_PREFIX)
+	if _, _, err := m.Scale("web", 1); err != ErrNoDefinition {
+		t.Fatal("Groups without instances or a definition can't be scaled up")
+	}
+
+	m.SetDefinition("web", testTask("web", STAGING))
+	launch, kill, err := m.Scale("web", 3)
+	if err != nil || len(launch) != 3 || len(kill) != 0 || m.TotalTasks() != 3 {
+		t.Fatal("Scaling up should add new instances")
+	}
+	if launch[0].Info.GetName() != "web-0" || launch[2].Info.GetName() != "web-2" || launch[0].Info.GetTaskId().GetValue() == launch[1].Info.GetTaskId().GetValue() {
+		t.Fatal("New instances should get unique names and IDs")
+	}
+
+	for _, task := range launch {
+		m.Transition(task.Info.TaskId, RUNNING)
+	}
+	m.UpdateStatus(&mesos_v1.TaskStatus{TaskId: launch[1].Info.TaskId, State: RUNNING.Enum(), Healthy: utils.ProtoBool(false)})
+
+	m.SetScalePolicy(UNHEALTHY_FIRST)
+	_, kill, err = m.Scale("web", 2)
+	if err != nil || len(kill) != 1 || kill[0] != launch[1] || !kill[0].IsKill {
+		t.Fatal("Unhealthy instances should be killed first")
+	}
+
+	loaded := NewDefaultTaskManager(storage, TASK_PREFIX)
+	if err := loaded.Load(); err != nil || loaded.Desired("web") != 2 || loaded.TotalTasks() != 3 {
+		t.Fatal("The desired count should be persisted with the group")
+	}
+
+	m.SetScalePolicy(NEWEST_FIRST)
+	if _, kill, _ = m.Scale("web", 1); len(kill) != 1 || kill[0] != launch[2] {
+		t.Fatal("The newest instance should be killed first")
+	}
+}
+
+// Measures scaling a group up and down.
+func BenchmarkDefaultTaskManager_Scale(b *testing.B) {
+	m := NewDefaultTaskManager(nil, TASK_PREFIX)
+	m.SetDefinition("web", testTask("web", STAGING))
+	for n := 0; n < b.N; n++ {
+		launch, _, _ := m.Scale("web", 2)
+		_, kill, _ := m.Scale("web", 0)
+		m.Delete(append(launch, kill...)...)
+	}
+}
+
+// Ensures tasks are only queued once their dependencies are running or finished.
+func TestDAG(t *testing.T) {
+	t.Parallel()
+
+	m := NewDefaultTaskManager(nil, TASK_PREFIX)
+	var queued []string
+	var fail error
+	d := NewDAG(m, func(tasks ...*Task) error {
+		if fail != nil {
+			return fail
+		}
+		for _, task := range tasks {
+			queued = append(queued, task.Info.GetName())
+		}
+		return nil
+	})
+
+	extract, transform, load := testTask("extract", STAGING), testTask("transform", STAGING), testTask("load", STAGING)
+	transform.DependsOn = []string{"extract"}
+	load.DependsOn = []string{"extract", "transform"}
+
+	missing := testTask("report", STAGING)
+	missing.DependsOn = []string{"nothing"}
+	if err := d.Submit(missing); err != ErrMissingDependency {
+		t.Fatal("Tasks with unknown dependencies should be rejected")
+	}
+
+	a, b := testTask("a", STAGING), testTask("b", STAGING)
+	a.DependsOn, b.DependsOn = []string{"b"}, []string{"a"}
+	if err := d.Submit(a, b); err != ErrCycle || m.TotalTasks() != 0 {
+		t.Fatal("Cyclic dependencies should be rejected")
+	}
+
+	if err := d.Submit(load, transform, extract); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(queued) != 1 || queued[0] != "extract" || len(d.Waiting()) != 2 {
+		t.Fatal("Only tasks without dependencies should be queued")
+	}
+
+	d.Update(&mesos_v1.TaskStatus{TaskId: extract.Info.TaskId, State: STARTING.Enum()})
+	if len(queued) != 1 {
+		t.Fatal("Dependents should wait until the dependency is running")
+	}
+	d.Update(&mesos_v1.TaskStatus{TaskId: extract.Info.TaskId, State: RUNNING.Enum()})
+	if len(queued) != 2 || queued[1] != "transform" {
+		t.Fatal("Tasks should be queued once their dependencies are running")
+	}
+	d.Update(&mesos_v1.TaskStatus{TaskId: transform.Info.TaskId, State: FINISHED.Enum()})
+	if len(queued) != 3 || queued[2] != "load" || len(d.Waiting()) != 0 {
+		t.Fatal("Tasks should be queued once all of their dependencies are done")
+	}
+
+	// Dependencies already in the task manager count by their current state.
+	m.Transition(extract.Info.TaskId, RUNNING)
+	report := testTask("report", STAGING)
+	report.DependsOn = []string{"extract"}
+	if err := d.Submit(report); err != nil || len(queued) != 4 {
+		t.Fatal("Tasks depending on running tasks should be queued right away")
+	}
+
+	fail = errors.New("Queue is unavailable.")
+	later := testTask("later", STAGING)
+	if err := d.Submit(later); err != fail || len(d.Waiting()) != 1 {
+		t.Fatal("Tasks that fail to be queued should keep waiting")
+	}
+	fail = nil
+	d.Update(&mesos_v1.TaskStatus{TaskId: extract.Info.TaskId, State: RUNNING.Enum()})
+	if len(queued) != 5 || queued[4] != "later" || len(d.Waiting()) != 0 {
+		t.Fatal("Tasks that failed to be queued should be queued on the next release")
+	}
+}
+
+// Measures submitting and releasing a small pipeline.
+func BenchmarkDAG(b *testing.B) {
+	m := NewDefaultTaskManager(nil, TASK_PREFIX)
+	d := NewDAG(m, func(...*Task) error { return nil })
+	for n := 0; n < b.N; n++ {
+		first, second := testTask("first", STAGING), testTask("second", STAGING)
+		second.DependsOn = []string{"first"}
+		d.Submit(first, second)
+		d.Update(&mesos_v1.TaskStatus{TaskId: first.Info.TaskId, State: RUNNING.Enum()})
+		m.Delete(first, second)
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/task/retry"
+	"sync"
+	"time"
+)
+
+// Bounds on how long tasks wait between relaunches.
+const (
+	MIN_RELAUNCH_DELAY = time.Second
+	MAX_RELAUNCH_DELAY = 5 * time.Minute
+)
+
+// Relaunches tasks that reach a terminal state their restart policy covers.
+// Tasks wait their retry time before going back into the pending queue, doubling after every attempt when they back off.
+// Tasks we killed on purpose are never relaunched and tasks without a retry policy are restarted on failure.
+type Relauncher struct {
+	tasks  TaskManager
+	queue  func(...*Task) error
+	lock   sync.Mutex
+	timers map[string]*time.Timer // Task name to its pending relaunch.
+	min    time.Duration
+	max    time.Duration
+
+	// Called on a task before it's queued again, such as to give it a new task ID or revise its definition.
+	Revise func(*Task)
+
+	// Called when a relaunched task couldn't be saved, the relaunch is tried again after the task's delay.
+	Failed func(*Task, error)
+}
+
+// Relaunched tasks are handed to the queue, which is usually an offer coordinator's Queue method.
+func NewRelauncher(tasks TaskManager, queue func(...*Task) error) *Relauncher {
+	return &Relauncher{
+		tasks:  tasks,
+		queue:  queue,
+		timers: make(map[string]*time.Timer),
+		min:    MIN_RELAUNCH_DELAY,
+		max:    MAX_RELAUNCH_DELAY,
+	}
+}
+
+// Schedules the task in the status update to be relaunched if its policy says so.
+// Reports whether a relaunch was scheduled, tasks that ran out of retries aren't relaunched.
+func (r *Relauncher) Update(status *mesos_v1.TaskStatus) (bool, error) {
+	t, err := r.tasks.GetById(status.GetTaskId())
+	if err == ErrTaskNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	t.lock.Lock()
+	if t.Retry == nil {
+		t.Retry = &retry.TaskRetry{Name: t.Info.GetName(), Policy: retry.ON_FAILURE}
+	}
+	if t.IsKill || !restarts(t.Retry.Policy, status.GetState()) ||
+		(t.Retry.MaxRetries > 0 && t.Retry.TotalRetries >= t.Retry.MaxRetries) {
+		t.lock.Unlock()
+		return false, nil
+	}
+
+	delay := r.delay(t.Retry)
+	t.Retry.TotalRetries++
+	name := t.Info.GetName()
+	t.lock.Unlock()
+
+	// Persist the attempt so a restarted scheduler doesn't hand out extra retries.
+	if err := r.tasks.Update(t); err != nil {
+		return false, err
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if timer, ok := r.timers[name]; ok {
+		timer.Stop()
+	}
+	r.schedule(t, name, delay)
+
+	return true, nil
+}
+
+// Cancels every relaunch that hasn't happened yet.
+func (r *Relauncher) Stop() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for name, timer := range r.timers {
+		timer.Stop()
+		delete(r.timers, name)
+	}
+}
+
+// Arms the task's relaunch timer, the caller must hold the relauncher's lock.
+// Timers that were stopped or replaced by the time they fire do nothing.
+func (r *Relauncher) schedule(t *Task, name string, delay time.Duration) {
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		r.lock.Lock()
+		current := timer
+		pending := r.timers[name] == current
+		r.lock.Unlock()
+
+		if pending {
+			r.relaunch(t, name, current)
+		}
+	})
+	r.timers[name] = timer
+}
+
+func (r *Relauncher) relaunch(t *Task, name string, timer *time.Timer) {
+	t.lock.Lock()
+	if r.Revise != nil {
+		r.Revise(t)
+	}
+	t.State = STAGING
+	delay := r.delay(t.Retry)
+	t.lock.Unlock()
+
+	if err := r.tasks.Update(t); err != nil {
+		if r.Failed != nil {
+			r.Failed(t, err)
+		}
+
+		r.lock.Lock()
+		if r.timers[name] == timer {
+			r.schedule(t, name, delay)
+		}
+		r.lock.Unlock()
+		return
+	}
+
+	r.lock.Lock()
+	if r.timers[name] == timer {
+		delete(r.timers, name)
+	}
+	r.lock.Unlock()
+
+	// Queueing only fails when offers can't be revived, the task is still pending and goes out with the next offers.
+	r.queue(t)
+}
+
+// Reports whether the policy relaunches tasks that end in the state.
+func restarts(policy string, state mesos_v1.TaskState) bool {
+	if !IsTerminal(state) {
+		return false
+	}
+
+	switch policy {
+	case retry.ALWAYS:
+		return true
+	case retry.NEVER:
+		return false
+	}
+
+	// Finished tasks succeeded and killed tasks were stopped by someone, neither of which is a failure.
+	return state != FINISHED && state != KILLED
+}
+
+func (r *Relauncher) delay(policy *retry.TaskRetry) time.Duration {
+	delay := policy.RetryTime
+	if delay < r.min {
+		delay = r.min
+	}
+
+	if policy.Backoff {
+		for i := 0; i < policy.TotalRetries && delay < r.max; i++ {
+			delay *= 2
+		}
+	}
+	if delay > r.max {
+		delay = r.max
+	}
+
+	return delay
+}
@@ -0,0 +1,250 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// How instances are picked when a group is scaled down.
+type ScalePolicy string
+
+const (
+	NEWEST_FIRST    ScalePolicy = "newest-first"    // The most recently added instances go first.
+	UNHEALTHY_FIRST ScalePolicy = "unhealthy-first" // Instances that aren't running or failed health checks go first, then the newest.
+)
+
+var (
+	ErrNoDefinition  = errors.New("The group has no instances or stored definition to create new ones from.")
+	ErrNegativeScale = errors.New("A group can't be scaled to fewer than 0 instances.")
+)
+
+// What's persisted about a task group.
+type group struct {
+	Instances  int   `json:"instances"`
+	Definition *Task `json:"definition"`
+}
+
+func (m *DefaultTaskManager) SetScalePolicy(policy ScalePolicy) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.scaleDown = policy
+}
+
+// Stores the definition new instances of the group are created from when it's scaled up.
+// Without one, the newest instance in the group is copied.
+func (m *DefaultTaskManager) SetDefinition(name string, definition *Task) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	g := m.group(name)
+	g.Definition = definition
+
+	return m.persistGroup(name, g)
+}
+
+// Gets the number of instances the group was last scaled to, or -1 if it never was.
+func (m *DefaultTaskManager) Desired(name string) int {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	if g, ok := m.groups[name]; ok {
+		return g.Instances
+	}
+
+	return -1
+}
+
+// Adds or marks instances of the group until it has the given number of them, persisting the new count.
+// New instances are added in STAGING and handed back to be launched.
+// Surplus instances are marked as killed, so they aren't relaunched, and handed back to be killed.
+func (m *DefaultTaskManager) Scale(name string, instances int) ([]*Task, []*Task, error) {
+	if instances < 0 {
+		return nil, nil, ErrNegativeScale
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	g := m.group(name)
+	var members []*Task
+	for _, t := range m.tasks {
+		if t.GroupInfo.InGroup && t.GroupInfo.GroupName == name && !t.IsKill && !IsTerminal(t.State) {
+			members = append(members, t)
+		}
+	}
+	sort.Sort(newestFirst(members))
+
+	if g.Definition == nil && len(members) > 0 {
+		g.Definition = members[0]
+	}
+
+	var launch, kill []*Task
+	for i := len(members); i < instances; i++ {
+		if g.Definition == nil {
+			return nil, nil, ErrNoDefinition
+		}
+
+		t, err := m.instance(name, g.Definition)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := m.persist(t); err != nil {
+			return nil, nil, err
+		}
+		m.set(t)
+		launch = append(launch, t)
+	}
+
+	if instances < len(members) {
+		if m.scaleDown == UNHEALTHY_FIRST {
+			sort.Stable(unhealthyFirst(members))
+		}
+
+		for _, t := range members[:len(members)-instances] {
+			t.lock.Lock()
+			t.IsKill = true
+			err := m.persist(t)
+			t.lock.Unlock()
+			if err != nil {
+				return nil, nil, err
+			}
+			kill = append(kill, t)
+		}
+	}
+
+	g.Instances = instances
+	if err := m.persistGroup(name, g); err != nil {
+		return nil, nil, err
+	}
+
+	return launch, kill, nil
+}
+
+// Must be called with the lock held.
+// Copies the definition into a new instance named after the group and the lowest free index.
+func (m *DefaultTaskManager) instance(name string, definition *Task) (*Task, error) {
+	data, err := definition.Encode()
+	if err != nil {
+		return nil, err
+	}
+	t, err := new(Task).Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	i := 0
+	for {
+		if _, ok := m.tasks[name+"-"+strconv.Itoa(i)]; !ok {
+			break
+		}
+		i++
+	}
+	instance := name + "-" + strconv.Itoa(i)
+
+	t.Info.Name = utils.ProtoString(instance)
+	t.Info.TaskId = &mesos_v1.TaskID{Value: utils.ProtoString(instance + "-" + strings.ToLower(utils.UuidAsString()))}
+	t.Info.AgentId = nil
+	t.State = STAGING
+	t.IsKill = false
+	t.Healthy = nil
+	t.Created = time.Now().UnixNano()
+	t.GroupInfo = GroupInfo{GroupName: name, InGroup: true}
+	if t.Retry != nil {
+		t.Retry.TotalRetries = 0
+	}
+
+	return t, nil
+}
+
+// Must be called with the lock held.
+func (m *DefaultTaskManager) group(name string) *group {
+	g, ok := m.groups[name]
+	if !ok {
+		g = &group{}
+		m.groups[name] = g
+	}
+
+	return g
+}
+
+func (m *DefaultTaskManager) persistGroup(name string, g *group) error {
+	if m.storage == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(g)
+	if err != nil {
+		return err
+	}
+
+	return m.storage.Update(m.groupKey(name), string(data))
+}
+
+func (m *DefaultTaskManager) loadGroups() error {
+	kvs, err := m.storage.ReadAll(m.groupKey(""))
+	if err != nil {
+		return err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for key, value := range kvs {
+		g := &group{}
+		if err := json.Unmarshal([]byte(value), g); err != nil {
+			return errors.New("Failed to decode the task group at " + key + ": " + err.Error())
+		}
+		m.groups[strings.TrimPrefix(key, m.groupKey(""))] = g
+	}
+
+	return nil
+}
+
+// Groups live next to the tasks rather than under them so loading tasks doesn't pick them up.
+func (m *DefaultTaskManager) groupKey(name string) string {
+	return m.prefix + "-groups/" + name
+}
+
+type newestFirst []*Task
+
+func (n newestFirst) Len() int      { return len(n) }
+func (n newestFirst) Swap(i, j int) { n[i], n[j] = n[j], n[i] }
+func (n newestFirst) Less(i, j int) bool {
+	if n[i].Created != n[j].Created {
+		return n[i].Created > n[j].Created
+	}
+
+	// Instances added at the same time go by name, higher indexes being newer.
+	return n[i].Info.GetName() > n[j].Info.GetName()
+}
+
+type unhealthyFirst []*Task
+
+func (u unhealthyFirst) Len() int           { return len(u) }
+func (u unhealthyFirst) Swap(i, j int)      { u[i], u[j] = u[j], u[i] }
+func (u unhealthyFirst) Less(i, j int) bool { return unhealthy(u[i]) && !unhealthy(u[j]) }
+
+func unhealthy(t *Task) bool {
+	return t.State != RUNNING || (t.Healthy != nil && !*t.Healthy)
+}
@@ -0,0 +1,66 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import "github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+
+// Returned when a task is moved to a state it can't reach from its current one.
+type TransitionError struct {
+	From mesos_v1.TaskState
+	To   mesos_v1.TaskState
+}
+
+func (e TransitionError) Error() string {
+	return "Task can't go from " + e.From.String() + " to " + e.To.String() + "."
+}
+
+// Terminal states end a task's life, the only way out of them is being launched again.
+func IsTerminal(state mesos_v1.TaskState) bool {
+	switch state {
+	case FINISHED, FAILED, KILLED, ERROR, LOST, DROPPED, GONE, GONE_BY_OPERATOR:
+		return true
+	}
+
+	return false
+}
+
+// Reports whether a task can move between the states.
+// Tasks move forward from STAGING through STARTING and RUNNING to KILLING and on to a terminal state.
+// UNREACHABLE tasks can come back and UNKNOWN tasks can be in any state, so both can go anywhere but backwards to STAGING.
+// Repeated updates for the same state are always allowed since Mesos retries status updates.
+func CanTransition(from, to mesos_v1.TaskState) bool {
+	if from == to {
+		return true
+	}
+	if IsTerminal(from) {
+		return to == STAGING
+	}
+	if IsTerminal(to) || to == UNREACHABLE || to == UNKNOWN {
+		return true
+	}
+
+	switch from {
+	case STAGING:
+		return to == STARTING || to == RUNNING || to == KILLING
+	case STARTING:
+		return to == RUNNING || to == KILLING
+	case RUNNING:
+		return to == KILLING
+	case UNREACHABLE, UNKNOWN:
+		return to == STARTING || to == RUNNING || to == KILLING
+	}
+
+	return false
+}
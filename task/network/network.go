@@ -19,10 +19,13 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
 	"github.com/verizonlabs/mesos-framework-sdk/task"
+	"net"
+	"sort"
 	"strings"
 )
 
 // Parse NetworkJSON into a list of Networkwork Infos.
+// Named networks are joined through CNI, port mappings and labels are handed to the CNI plugin.
 func ParseNetworkJSON(networks []task.NetworkJSON) ([]*mesos_v1.NetworkInfo, error) {
 	if len(networks) == 0 {
 		return []*mesos_v1.NetworkInfo{}, errors.New("Empty list of networks passed in.")
@@ -31,6 +34,10 @@ func ParseNetworkJSON(networks []task.NetworkJSON) ([]*mesos_v1.NetworkInfo, err
 	networkInfos := []*mesos_v1.NetworkInfo{}
 	// Iterate over each network
 	for _, network := range networks {
+		if err := validateNetwork(network); err != nil {
+			return nil, err
+		}
+
 		n := &mesos_v1.NetworkInfo{}
 		if network.Name != nil {
 			n.Name = network.Name
@@ -53,14 +60,59 @@ func ParseNetworkJSON(networks []task.NetworkJSON) ([]*mesos_v1.NetworkInfo, err
 	return networkInfos, nil
 }
 
-// Parses Ip addresses out of the network json struct
+func validateNetwork(network task.NetworkJSON) error {
+	if len(network.PortMapping) > 0 && (network.Name == nil || *network.Name == "") {
+		return errors.New("Port mappings can only be set on a named CNI network.")
+	}
+
+	for _, ipaddr := range network.IpAddresses {
+		if ipaddr.IP != nil && net.ParseIP(*ipaddr.IP) == nil {
+			return errors.New("Invalid IP address " + *ipaddr.IP + " requested.")
+		}
+		if ipaddr.Protocol != nil {
+			protocol := strings.ToLower(*ipaddr.Protocol)
+			if protocol != "ipv4" && protocol != "ipv6" {
+				return errors.New("Invalid IP protocol, accepted values are ipv4, ipv6.")
+			}
+		}
+	}
+
+	for _, portMap := range network.PortMapping {
+		if portMap == nil || portMap.HostPort == nil || portMap.ContainerPort == nil {
+			return errors.New("Port mappings need both a host and container port.")
+		}
+		if portMap.Protocol != nil {
+			protocol := strings.ToLower(*portMap.Protocol)
+			if protocol != "tcp" && protocol != "udp" {
+				return errors.New("Invalid port mapping protocol, accepted values are tcp, udp.")
+			}
+		}
+	}
+
+	return nil
+}
+
+// Parses Ip addresses out of the network json struct.
+// Addresses without a protocol are assumed to match the version of the requested IP.
 func ParseNetworkJSONIpAddresses(ipaddrs []task.IpAddressJSON) (ips []*mesos_v1.NetworkInfo_IPAddress) {
 	for _, ipaddr := range ipaddrs {
 		ip := &mesos_v1.NetworkInfo_IPAddress{}
 		ip.IpAddress = ipaddr.IP
-		if strings.ToLower(*ipaddr.Protocol) == "ipv4" {
+
+		protocol := ""
+		if ipaddr.Protocol != nil {
+			protocol = strings.ToLower(*ipaddr.Protocol)
+		} else if ipaddr.IP != nil {
+			if parsed := net.ParseIP(*ipaddr.IP); parsed != nil && parsed.To4() == nil {
+				protocol = "ipv6"
+			} else if parsed != nil {
+				protocol = "ipv4"
+			}
+		}
+
+		if protocol == "ipv4" {
 			ip.Protocol = mesos_v1.NetworkInfo_IPv4.Enum()
-		} else if strings.ToLower(*ipaddr.Protocol) == "ipv6" {
+		} else if protocol == "ipv6" {
 			ip.Protocol = mesos_v1.NetworkInfo_IPv6.Enum()
 		} else {
 			ip.Protocol = nil
@@ -74,11 +126,15 @@ func ParseNetworkJSONIpAddresses(ipaddrs []task.IpAddressJSON) (ips []*mesos_v1.
 func ParseNetworkJSONLabels(labels []map[string]string) *mesos_v1.Labels {
 	labelList := []*mesos_v1.Label{}
 	for _, label := range labels {
-		l := &mesos_v1.Label{}
-		for k, v := range label {
-			l.Key, l.Value = proto.String(k), proto.String(v)
+		keys := make([]string, 0, len(label))
+		for k := range label {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			labelList = append(labelList, &mesos_v1.Label{Key: proto.String(k), Value: proto.String(label[k])})
 		}
-		labelList = append(labelList, l)
 	}
 	return &mesos_v1.Labels{Labels: labelList}
 }
@@ -86,7 +142,10 @@ func ParseNetworkJSONLabels(labels []map[string]string) *mesos_v1.Labels {
 func ParseNetworkJSONPortMapping(portMap []*task.PortMapping) (portMapList []*mesos_v1.NetworkInfo_PortMapping) {
 	for _, portMap := range portMap {
 		pm := &mesos_v1.NetworkInfo_PortMapping{}
-		portMap.ContainerPort, portMap.HostPort, portMap.Protocol = pm.ContainerPort, pm.HostPort, pm.Protocol
+		pm.ContainerPort, pm.HostPort = portMap.ContainerPort, portMap.HostPort
+		if portMap.Protocol != nil {
+			pm.Protocol = proto.String(strings.ToLower(*portMap.Protocol))
+		}
 		portMapList = append(portMapList, pm)
 	}
 	return portMapList
@@ -0,0 +1,122 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package network
+
+import (
+	"encoding/json"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/task"
+	"testing"
+)
+
+func parse(t testing.TB, data string) []task.NetworkJSON {
+	var networks []task.NetworkJSON
+	if err := json.Unmarshal([]byte(data), &networks); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	return networks
+}
+
+// Ensures networks are parsed and invalid addresses or port mappings are rejected.
+func TestParseNetworkJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data string
+		fail bool
+	}{
+		{"none", `[]`, true},
+		{"named", `[{"name": "cni", "group": ["a"], "labels": [{"b": "2", "a": "1"}]}]`, false},
+		{"port mapping", `[{"name": "cni", "port_mapping": [{"host_port": 8080, "container_port": 80, "protocol": "TCP"}]}]`, false},
+		{"unnamed port mapping", `[{"port_mapping": [{"host_port": 8080, "container_port": 80}]}]`, true},
+		{"incomplete port mapping", `[{"name": "cni", "port_mapping": [{"host_port": 8080}]}]`, true},
+		{"port mapping protocol", `[{"name": "cni", "port_mapping": [{"host_port": 8080, "container_port": 80, "protocol": "sctp"}]}]`, true},
+		{"ip", `[{"ipaddress": [{"ip": "10.0.0.1", "protocol": "IPv4"}]}]`, false},
+		{"bad ip", `[{"ipaddress": [{"ip": "10.0.0"}]}]`, true},
+		{"ip protocol", `[{"ipaddress": [{"ip": "10.0.0.1", "protocol": "ipx"}]}]`, true},
+	}
+
+	for _, test := range tests {
+		networks, err := ParseNetworkJSON(parse(t, test.data))
+		if (err != nil) != test.fail {
+			t.Fatalf("%s: unexpected error state: %v", test.name, err)
+		}
+		if !test.fail && len(networks) != 1 {
+			t.Fatalf("%s: expected 1 network, got %d", test.name, len(networks))
+		}
+	}
+
+	networks, _ := ParseNetworkJSON(parse(t, `[{"name": "cni", "group": ["a"], "labels": [{"b": "2", "a": "1"}],
+		"port_mapping": [{"host_port": 8080, "container_port": 80, "protocol": "UDP"}]}]`))
+	n := networks[0]
+	if n.GetName() != "cni" || len(n.GetGroups()) != 1 {
+		t.Fatal("Network name and groups were not carried over")
+	}
+	if labels := n.GetLabels().GetLabels(); len(labels) != 2 || labels[0].GetKey() != "a" || labels[1].GetKey() != "b" {
+		t.Fatal("Network labels should be sorted by key")
+	}
+	if pm := n.GetPortMappings(); len(pm) != 1 || pm[0].GetHostPort() != 8080 || pm[0].GetContainerPort() != 80 || pm[0].GetProtocol() != "udp" {
+		t.Fatal("Port mappings were not carried over")
+	}
+}
+
+// Measures performance of parsing networks.
+func BenchmarkParseNetworkJSON(b *testing.B) {
+	networks := parse(b, `[{"name": "cni", "ipaddress": [{"ip": "10.0.0.1"}], "port_mapping": [{"host_port": 8080, "container_port": 80}]}]`)
+	for n := 0; n < b.N; n++ {
+		ParseNetworkJSON(networks)
+	}
+}
+
+// Ensures the IP protocol is taken from the request or inferred from the address.
+func TestParseNetworkJSONIpAddresses(t *testing.T) {
+	t.Parallel()
+
+	ipv4, ipv6 := mesos_v1.NetworkInfo_IPv4, mesos_v1.NetworkInfo_IPv6
+	tests := []struct {
+		name     string
+		data     string
+		protocol *mesos_v1.NetworkInfo_Protocol
+	}{
+		{"ipv4", `{"ip": "10.0.0.1"}`, &ipv4},
+		{"ipv6", `{"ip": "fe80::1"}`, &ipv6},
+		{"explicit", `{"ip": "10.0.0.1", "protocol": "IPV6"}`, &ipv6},
+		{"protocol only", `{"protocol": "ipv4"}`, &ipv4},
+		{"neither", `{}`, nil},
+	}
+
+	for _, test := range tests {
+		var ip task.IpAddressJSON
+		if err := json.Unmarshal([]byte(test.data), &ip); err != nil {
+			t.Fatal(err.Error())
+		}
+
+		ips := ParseNetworkJSONIpAddresses([]task.IpAddressJSON{ip})
+		if len(ips) != 1 {
+			t.Fatalf("%s: expected 1 address, got %d", test.name, len(ips))
+		}
+		if test.protocol == nil {
+			if ips[0].Protocol != nil {
+				t.Fatal(test.name + ": the protocol should be left unset")
+			}
+			continue
+		}
+		if ips[0].Protocol == nil || ips[0].GetProtocol() != *test.protocol {
+			t.Fatalf("%s: expected %s, got %s", test.name, *test.protocol, ips[0].GetProtocol())
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pod
+
+import (
+	"errors"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/resources"
+	"github.com/verizonlabs/mesos-framework-sdk/task"
+	"github.com/verizonlabs/mesos-framework-sdk/task/command"
+	"github.com/verizonlabs/mesos-framework-sdk/task/container"
+	"github.com/verizonlabs/mesos-framework-sdk/task/discovery"
+	"github.com/verizonlabs/mesos-framework-sdk/task/environment"
+	"github.com/verizonlabs/mesos-framework-sdk/task/healthcheck"
+	"github.com/verizonlabs/mesos-framework-sdk/task/labels"
+	taskresources "github.com/verizonlabs/mesos-framework-sdk/task/resources"
+	"github.com/verizonlabs/mesos-framework-sdk/task/volume"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+)
+
+// Resources given to the default executor when the pod doesn't ask for any.
+const (
+	EXECUTOR_CPU  = 0.1
+	EXECUTOR_MEM  = 32.0
+	EXECUTOR_DISK = 10.0
+)
+
+// Parses a pod into the default executor and the task group it runs.
+// Task IDs are derived from the pod and container names, agent IDs must be set once an offer is picked.
+func ParsePod(name string, pod *task.PodJSON) (*mesos_v1.ExecutorInfo, *mesos_v1.TaskGroupInfo, error) {
+	if pod == nil || len(pod.Containers) == 0 {
+		return nil, nil, errors.New("Pods need at least one container.")
+	}
+
+	var executorResources []*mesos_v1.Resource
+	if pod.Executor != nil {
+		var err error
+		executorResources, err = taskresources.ParseResources(pod.Executor)
+		if err != nil {
+			return nil, nil, errors.New("Invalid executor resources: " + err.Error())
+		}
+	} else {
+		executorResources = []*mesos_v1.Resource{
+			resources.CreateResource("cpus", "", EXECUTOR_CPU),
+			resources.CreateResource("mem", "", EXECUTOR_MEM),
+			resources.CreateResource("disk", "", EXECUTOR_DISK),
+		}
+	}
+
+	executor := &mesos_v1.ExecutorInfo{
+		Type:       mesos_v1.ExecutorInfo_DEFAULT.Enum(),
+		ExecutorId: &mesos_v1.ExecutorID{Value: utils.ProtoString(name)},
+		Resources:  executorResources,
+	}
+
+	group := &mesos_v1.TaskGroupInfo{}
+	names := make(map[string]struct{}, len(pod.Containers))
+	for _, c := range pod.Containers {
+		if c.Name == "" {
+			return nil, nil, errors.New("Every container in a pod needs a name.")
+		}
+		if _, ok := names[c.Name]; ok {
+			return nil, nil, errors.New("Container " + c.Name + " is defined more than once.")
+		}
+		names[c.Name] = struct{}{}
+
+		t, err := parseContainer(name, c)
+		if err != nil {
+			return nil, nil, errors.New("Invalid container " + c.Name + ": " + err.Error())
+		}
+		group.Tasks = append(group.Tasks, t)
+	}
+
+	return executor, group, nil
+}
+
+func parseContainer(pod string, c task.PodContainerJSON) (*mesos_v1.TaskInfo, error) {
+	if c.Resources == nil {
+		return nil, errors.New("Resources are required.")
+	}
+
+	res, err := taskresources.ParseResources(c.Resources)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Container != nil {
+		persistent, err := volume.ParsePersistentVolumes(c.Container.Volumes, c.Resources.Role)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, persistent...)
+	}
+
+	cmd, err := command.ParseCommandInfo(c.Command)
+	if err != nil {
+		return nil, err
+	}
+
+	env, err := environment.ParseEnvironment(c.Env, c.Secrets)
+	if err != nil {
+		return nil, err
+	}
+	if err := environment.Apply(cmd, env); err != nil {
+		return nil, err
+	}
+
+	con, err := container.ParseContainer(c.Container)
+	if err != nil {
+		return nil, err
+	}
+
+	hc, err := healthcheck.ParseHealthCheck(c.HealthCheck, cmd)
+	if err != nil {
+		return nil, err
+	}
+	if c.Container != nil && len(c.Container.HealthChecks) > 0 {
+		if hc != nil {
+			return nil, healthcheck.TooManyHealthChecks
+		}
+		hc, err = healthcheck.ParseHealthChecks(c.Container.HealthChecks, cmd)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	l, err := labels.ParseLabels(c.Labels)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := discovery.ParseDiscovery(c.Discovery)
+	if err != nil {
+		return nil, err
+	}
+
+	id := pod + "." + c.Name
+	t := resources.CreateTaskInfo(utils.ProtoString(id), &mesos_v1.TaskID{Value: utils.ProtoString(id)}, cmd, res, con, hc, l)
+	t.Discovery = d
+
+	return t, nil
+}
@@ -0,0 +1,168 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pod
+
+import (
+	"encoding/json"
+	"github.com/verizonlabs/mesos-framework-sdk/task"
+	"github.com/verizonlabs/mesos-framework-sdk/task/healthcheck"
+	"strings"
+	"testing"
+)
+
+func parse(t testing.TB, data string) *task.PodJSON {
+	var pod task.PodJSON
+	if err := json.Unmarshal([]byte(data), &pod); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	return &pod
+}
+
+const WEB_POD = `{"containers": [
+	{"name": "web", "resources": {"cpu": 0.5, "mem": 128, "disk": {"size": 10}}, "command": {"cmd": "serve"}},
+	{"name": "sidecar", "resources": {"cpu": 0.1, "mem": 32, "disk": {"size": 1}}, "command": {"cmd": "proxy"}}
+]}`
+
+// Ensures every container becomes a task named after the pod and the executor gets default resources.
+func TestParsePod(t *testing.T) {
+	t.Parallel()
+
+	executor, group, err := ParsePod("app", parse(t, WEB_POD))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if executor.GetExecutorId().GetValue() != "app" || executor.GetType().String() != "DEFAULT" {
+		t.Fatal("Pods should run under the default executor named after the pod")
+	}
+	expected := map[string]float64{"cpus": EXECUTOR_CPU, "mem": EXECUTOR_MEM, "disk": EXECUTOR_DISK}
+	if len(executor.GetResources()) != len(expected) {
+		t.Fatalf("Expected %d executor resources, got %d", len(expected), len(executor.GetResources()))
+	}
+	for _, r := range executor.GetResources() {
+		if r.GetScalar().GetValue() != expected[r.GetName()] {
+			t.Fatal("Unexpected default for executor resource " + r.GetName())
+		}
+	}
+
+	if len(group.GetTasks()) != 2 {
+		t.Fatal("Every container should become a task")
+	}
+	for i, name := range []string{"app.web", "app.sidecar"} {
+		tsk := group.GetTasks()[i]
+		if tsk.GetName() != name || tsk.GetTaskId().GetValue() != name {
+			t.Fatal("Tasks should be named after the pod and container, got " + tsk.GetName())
+		}
+	}
+}
+
+// Measures performance of parsing a pod.
+func BenchmarkParsePod(b *testing.B) {
+	pod := parse(b, WEB_POD)
+	for n := 0; n < b.N; n++ {
+		ParsePod("app", pod)
+	}
+}
+
+// Ensures executor resources given with the pod replace the defaults and are checked.
+func TestParsePod_Executor(t *testing.T) {
+	t.Parallel()
+
+	pod := parse(t, WEB_POD)
+	pod.Executor = &task.ResourceJSON{Cpu: 0.2, Mem: 64, Disk: task.Disk{Size: 20}}
+	executor, _, err := ParsePod("app", pod)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	for _, r := range executor.GetResources() {
+		if r.GetName() == "mem" && r.GetScalar().GetValue() != 64 {
+			t.Fatal("Executor resources given with the pod should be used")
+		}
+	}
+
+	pod.Executor = &task.ResourceJSON{Mem: 64, Disk: task.Disk{Size: 20}}
+	if _, _, err := ParsePod("app", pod); err == nil || !strings.HasPrefix(err.Error(), "Invalid executor resources") {
+		t.Fatal("Invalid executor resources should be rejected")
+	}
+}
+
+// Ensures persistent volumes are added to the container's resources under its role.
+func TestParsePod_PersistentVolume(t *testing.T) {
+	t.Parallel()
+
+	pod := parse(t, `{"containers": [{
+		"name": "db",
+		"resources": {"cpu": 1, "mem": 256, "disk": {"size": 10}, "role": "storage"},
+		"command": {"cmd": "postgres"},
+		"container": {"volume": [{"container_path": "data", "source": {"type": "persistent", "persistent": {"id": "db-data", "size": 512}}}]}
+	}]}`)
+	_, group, err := ParsePod("app", pod)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	found := false
+	for _, r := range group.GetTasks()[0].GetResources() {
+		if r.GetDisk().GetPersistence().GetId() == "db-data" {
+			found = true
+			if r.GetScalar().GetValue() != 512 || r.GetDisk().GetVolume().GetContainerPath() != "data" {
+				t.Fatal("Persistent volumes should keep their size and path")
+			}
+		}
+	}
+	if !found {
+		t.Fatal("Persistent volumes should be added to the task's resources")
+	}
+
+	pod.Containers[0].Resources.Role = ""
+	if _, _, err := ParsePod("app", pod); err == nil {
+		t.Fatal("Persistent volumes should require a reserved role")
+	}
+}
+
+// Ensures invalid pods are rejected with the container at fault.
+func TestParsePod_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{"no containers", `{"containers": []}`, "Pods need at least one container."},
+		{"unnamed", `{"containers": [{"resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}}, "command": {"cmd": "true"}}]}`,
+			"Every container in a pod needs a name."},
+		{"duplicate names", `{"containers": [
+			{"name": "web", "resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}}, "command": {"cmd": "true"}},
+			{"name": "web", "resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}}, "command": {"cmd": "true"}}
+		]}`, "Container web is defined more than once."},
+		{"no resources", `{"containers": [{"name": "web", "command": {"cmd": "true"}}]}`,
+			"Invalid container web: Resources are required."},
+		{"both health check fields", `{"containers": [{
+			"name": "web",
+			"resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}},
+			"command": {"cmd": "true"},
+			"healthcheck": {"type": "tcp", "tcp": {"port": 80}},
+			"container": {"healthChecks": [{"type": "tcp", "tcp": {"port": 81}}]}
+		}]}`, "Invalid container web: " + healthcheck.TooManyHealthChecks.Error()},
+	}
+
+	for _, test := range tests {
+		if _, _, err := ParsePod("app", parse(t, test.data)); err == nil || err.Error() != test.err {
+			t.Fatalf("%s: expected %q, got %v", test.name, test.err, err)
+		}
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
 	"github.com/verizonlabs/mesos-framework-sdk/resources"
 	"github.com/verizonlabs/mesos-framework-sdk/task"
+	"sort"
 )
 
 func ParseResources(res *task.ResourceJSON) ([]*mesos_v1.Resource, error) {
@@ -29,12 +30,48 @@ func ParseResources(res *task.ResourceJSON) ([]*mesos_v1.Resource, error) {
 			"Please make sure you set cpu and mem properly.")
 	}
 
+	// GPUs are optional but can't be negative.
+	if res.Gpu < 0.00 {
+		return nil, errors.New("GPUs must be 0.0 or greater.")
+	}
+
 	cpu := resources.CreateResource("cpus", res.Role, res.Cpu)
 	mem := resources.CreateResource("mem", res.Role, res.Mem)
+
+	// Only cpus and memory can be oversubscribed.
+	// The framework must have the REVOCABLE_RESOURCES capability to be offered revocable resources.
+	if res.Revocable {
+		cpu.Revocable = &mesos_v1.Resource_RevocableInfo{}
+		mem.Revocable = &mesos_v1.Resource_RevocableInfo{}
+	}
+
 	disk, err := resources.CreateDisk(res.Disk, res.Role)
 	if err != nil {
 		return nil, err
 	}
 
-	return []*mesos_v1.Resource{cpu, mem, disk}, nil
+	mesosResources := []*mesos_v1.Resource{cpu, mem, disk}
+	if res.Gpu > 0.00 {
+		mesosResources = append(mesosResources, resources.CreateResource("gpus", res.Role, res.Gpu))
+	}
+
+	// Sort custom resources so tasks are always built the same way.
+	names := make([]string, 0, len(res.Custom))
+	for name, value := range res.Custom {
+		switch name {
+		case "cpus", "mem", "gpus", "disk", "ports":
+			return nil, errors.New("Custom resource " + name + " must be set through its own field.")
+		}
+		if value <= 0.00 {
+			return nil, errors.New("Custom resource " + name + " must be greater than 0.0.")
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		mesosResources = append(mesosResources, resources.CreateResource(name, res.Role, res.Custom[name]))
+	}
+
+	return mesosResources, nil
 }
@@ -15,6 +15,7 @@
 package retry
 
 import (
+	"errors"
 	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
 	"github.com/verizonlabs/mesos-framework-sdk/task"
 	"time"
@@ -38,5 +39,45 @@ type (
 		RetryTime    time.Duration
 		Backoff      bool
 		Name         string
+		Policy       string
 	}
 )
+
+// Restart policies deciding which terminal states a task is relaunched from.
+const (
+	NEVER      = "never"
+	ON_FAILURE = "on-failure"
+	ALWAYS     = "always"
+)
+
+// Creates a task's retry policy from its JSON definition.
+func NewTaskRetry(name string, policy *task.TimeRetry) (*TaskRetry, error) {
+	r := &TaskRetry{Name: name, Policy: ON_FAILURE}
+	if policy == nil {
+		return r, nil
+	}
+
+	if policy.Time != "" {
+		d, err := time.ParseDuration(policy.Time)
+		if err != nil {
+			return nil, errors.New("Invalid retry time: " + err.Error())
+		}
+		r.RetryTime = d
+	}
+	if policy.MaxRetries < 0 {
+		return nil, errors.New("Total retries can't be negative.")
+	}
+
+	switch policy.Policy {
+	case "":
+	case NEVER, ON_FAILURE, ALWAYS:
+		r.Policy = policy.Policy
+	default:
+		return nil, errors.New("Invalid restart policy " + policy.Policy + ", must be never, on-failure or always.")
+	}
+
+	r.Backoff = policy.Backoff
+	r.MaxRetries = policy.MaxRetries
+
+	return r, nil
+}
@@ -15,16 +15,79 @@
 package task
 
 type ApplicationJSON struct {
-	Name        string            `json:"name"`
-	Instances   int               `json:"instances"`
-	Resources   *ResourceJSON     `json:"resources"`
-	Command     *CommandJSON      `json:"command"`
-	Container   *ContainerJSON    `json:"container"`
-	HealthCheck *HealthCheckJSON  `json:"healthcheck"`
-	Labels      map[string]string `json:"labels"`
-	Filters     []Filter          `json:"filters"`
-	Retry       *TimeRetry        `json:"retry"`
-	Strategy    Strategy          `json:"strategy"`
+	Name        string                `json:"name"`
+	Instances   int                   `json:"instances"`
+	Resources   *ResourceJSON         `json:"resources"`
+	Command     *CommandJSON          `json:"command"`
+	Container   *ContainerJSON        `json:"container"`
+	HealthCheck *HealthCheckJSON      `json:"healthcheck"`
+	Labels      map[string]string     `json:"labels"`
+	Env         map[string]string     `json:"env,omitempty"`
+	Secrets     map[string]SecretJSON `json:"secrets,omitempty"`
+	Discovery   *DiscoveryJSON        `json:"discovery,omitempty"`
+	Filters     []Filter              `json:"filters"`
+	Retry       *TimeRetry            `json:"retry"`
+	Strategy    Strategy              `json:"strategy"`
+	Pod         *PodJSON              `json:"pod,omitempty"`
+	Executor    *ExecutorJSON         `json:"executor,omitempty"`
+	DependsOn   []string              `json:"dependsOn,omitempty"` // Names of tasks that must be running or finished first.
+}
+
+// A user provided executor the task is launched under instead of the command executor.
+type ExecutorJSON struct {
+	Id        string            `json:"id"`
+	Name      string            `json:"name,omitempty"`
+	Command   *CommandJSON      `json:"command"`
+	Resources *ResourceJSON     `json:"resources,omitempty"`
+	Container *ContainerJSON    `json:"container,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+// Containers launched together as a task group on the same agent, sharing the executor's network namespace.
+type PodJSON struct {
+	Executor   *ResourceJSON      `json:"executor,omitempty"`
+	Containers []PodContainerJSON `json:"containers"`
+}
+
+type PodContainerJSON struct {
+	Name        string                `json:"name"`
+	Resources   *ResourceJSON         `json:"resources"`
+	Command     *CommandJSON          `json:"command"`
+	Container   *ContainerJSON        `json:"container"`
+	HealthCheck *HealthCheckJSON      `json:"healthcheck"`
+	Labels      map[string]string     `json:"labels"`
+	Env         map[string]string     `json:"env,omitempty"`
+	Secrets     map[string]SecretJSON `json:"secrets,omitempty"`
+	Discovery   *DiscoveryJSON        `json:"discovery,omitempty"`
+}
+
+// Published on the task so discovery services such as Mesos-DNS can find it.
+// Visibility is one of framework, cluster or external and defaults to framework.
+type DiscoveryJSON struct {
+	Name        string              `json:"name"`
+	Visibility  string              `json:"visibility,omitempty"`
+	Environment string              `json:"environment,omitempty"`
+	Location    string              `json:"location,omitempty"`
+	Version     string              `json:"version,omitempty"`
+	Ports       []DiscoveryPortJSON `json:"ports,omitempty"`
+	Labels      map[string]string   `json:"labels,omitempty"`
+}
+
+// Named ports default to the visibility of the task they belong to.
+type DiscoveryPortJSON struct {
+	Number     uint32            `json:"number"`
+	Name       string            `json:"name,omitempty"`
+	Protocol   string            `json:"protocol,omitempty"`
+	Visibility string            `json:"visibility,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+}
+
+// Secrets are exposed to the task as environment variables.
+// Either a reference to a secret in the secret store or an inline value is given, not both.
+type SecretJSON struct {
+	Name  *string `json:"name,omitempty"`
+	Key   *string `json:"key,omitempty"`
+	Value *string `json:"value,omitempty"`
 }
 
 type Strategy struct {
@@ -36,6 +99,7 @@ type TimeRetry struct {
 	Time       string `json:"time"`
 	Backoff    bool   `json:"exp_backoff"`
 	MaxRetries int    `json:"total_retries"`
+	Policy     string `json:"policy,omitempty"` // One of never, on-failure or always, defaults to on-failure.
 }
 
 type HealthCheckJSON struct {
@@ -59,12 +123,13 @@ type HTTPHealthCheck struct {
 }
 
 type TCPHealthCheck struct {
-	Port int
+	Port int `json:"port"`
 }
 
 type Filter struct {
 	Type  string   `json:"type"`
 	Value []string `json:"value"`
+	Regex bool     `json:"regex,omitempty"`
 }
 
 type KillJson struct {
@@ -72,10 +137,15 @@ type KillJson struct {
 }
 
 type ResourceJSON struct {
-	Mem  float64 `json:"mem"`
-	Cpu  float64 `json:"cpu"`
-	Disk Disk    `json:"disk"`
-	Role string  `json:"role"`
+	Mem       float64 `json:"mem"`
+	Cpu       float64 `json:"cpu"`
+	Gpu       float64 `json:"gpu"`
+	Disk      Disk    `json:"disk"`
+	Role      string  `json:"role"`
+	Revocable bool    `json:"revocable,omitempty"`
+
+	// Any other named scalar resources the task needs, such as network bandwidth or license tokens.
+	Custom map[string]float64 `json:"custom,omitempty"`
 }
 
 type Disk struct {
@@ -108,6 +178,50 @@ type ContainerJSON struct {
 	Tag           *string       `json:"tag"`
 	Network       []NetworkJSON `json:"network"`
 	Volumes       []VolumesJSON `json:"volume"`
+	Docker        *DockerJSON   `json:"docker,omitempty"`
+
+	// Registry credentials given as a docker config file, either inline or from the secret store.
+	// The pull policy is cached, the default, or always to pull the image on every launch.
+	PullConfig *SecretJSON `json:"pull_config,omitempty"`
+	PullPolicy string      `json:"pull_policy,omitempty"`
+
+	// Linux capabilities granted to the task, e.g. NET_BIND_SERVICE, and resource limits set on its processes.
+	Capabilities []string     `json:"capabilities,omitempty"`
+	Rlimits      []RLimitJSON `json:"rlimits,omitempty"`
+
+	// Attaches a terminal to the task for interactive workloads, its size defaults to the agent's.
+	Tty        bool            `json:"tty,omitempty"`
+	WindowSize *WindowSizeJSON `json:"window_size,omitempty"`
+
+	// Mesos runs a single health check per task, only one may be given here.
+	HealthChecks []HealthCheckJSON `json:"healthChecks,omitempty"`
+}
+
+type WindowSizeJSON struct {
+	Rows    uint32 `json:"rows"`
+	Columns uint32 `json:"columns"`
+}
+
+// Limits are unlimited when neither soft nor hard is given.
+type RLimitJSON struct {
+	Type string  `json:"type"`
+	Soft *uint64 `json:"soft,omitempty"`
+	Hard *uint64 `json:"hard,omitempty"`
+}
+
+// Options only understood by the Docker containerizer, used when the container type is docker.
+type DockerJSON struct {
+	Network        *string         `json:"network,omitempty"`
+	PortMappings   []PortMapping   `json:"port_mapping,omitempty"`
+	Parameters     []ParameterJSON `json:"parameters,omitempty"`
+	Privileged     *bool           `json:"privileged,omitempty"`
+	ForcePullImage *bool           `json:"force_pull,omitempty"`
+}
+
+// Arbitrary command line options passed to docker run, keys may repeat.
+type ParameterJSON struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
 }
 
 type VolumesJSON struct {
@@ -117,15 +231,31 @@ type VolumesJSON struct {
 	Source        *VolumeSourceJSON `json:"source"`
 }
 
+// Volumes are sourced from docker volume drivers, another container's sandbox, or a persistent volume.
 type VolumeSourceJSON struct {
-	Type         *string          `json:"type"`
-	DockerVolume DockerVolumeJSON `json:"docker_volume"`
+	Type         *string               `json:"type"`
+	DockerVolume DockerVolumeJSON      `json:"docker_volume"`
+	SandboxPath  *SandboxPathJSON      `json:"sandbox_path,omitempty"`
+	Persistent   *PersistentVolumeJSON `json:"persistent,omitempty"`
 }
 
 type DockerVolumeJSON struct {
 	Driver        *string             `json:"driver"`
-	Name          *string             `'json:"name"`
-	DriverOptions []map[string]string `'json:"driver_opts"`
+	Name          *string             `json:"name"`
+	DriverOptions []map[string]string `json:"driver_opts"`
+}
+
+// Paths are relative to the sandbox of this container or, with the parent type, the pod's executor.
+type SandboxPathJSON struct {
+	Type *string `json:"type,omitempty"`
+	Path *string `json:"path"`
+}
+
+// References a persistent volume by ID, tasks consume it as a reserved disk resource rather than a container volume.
+type PersistentVolumeJSON struct {
+	Id        *string `json:"id"`
+	Size      float64 `json:"size"`
+	Principal string  `json:"principal,omitempty"`
 }
 
 type NetworkJSON struct {
@@ -147,8 +277,11 @@ type IpAddressJSON struct {
 	Protocol *string `json:"protocol"`
 }
 
+// Artifacts fetched into the sandbox by the Mesos fetcher before the task starts.
 type UriJSON struct {
-	Uri     *string `json:"uri"`
-	Extract *bool   `json:"extract"`
-	Execute *bool   `json:"execute"`
+	Uri        *string `json:"uri"`
+	Extract    *bool   `json:"extract"`
+	Execute    *bool   `json:"execute"`
+	Cache      *bool   `json:"cache,omitempty"`
+	OutputFile *string `json:"output_file,omitempty"`
 }
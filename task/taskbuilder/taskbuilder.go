@@ -0,0 +1,415 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package taskbuilder assembles tasks in code for frameworks that don't describe them in JSON.
+
+Resources, containers and everything else are set fluently:
+
+	task, err := taskbuilder.NewTask("web").
+		CPU(0.5).
+		Mem(256).
+		Docker("nginx:1.21").
+		Port(80).
+		Env("MODE", "production").
+		Build()
+
+The agent is left for the caller to set once an offer is picked. Invalid settings are reported by Build.
+*/
+package taskbuilder
+
+import (
+	"errors"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/resources"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"time"
+)
+
+// Fluently assembles a single task.
+type TaskBuilder struct {
+	name   string
+	id     string
+	role   string
+	cpu    float64
+	mem    float64
+	disk   float64
+	gpu    float64
+	ports  []uint32
+	cmd    *mesos_v1.CommandInfo
+	image  string
+	docker bool
+	env    []*mesos_v1.Environment_Variable
+	labels []*mesos_v1.Label
+	vols   []*mesos_v1.Volume
+	hc     *mesos_v1.HealthCheck
+	kill   *mesos_v1.KillPolicy
+	agent  *mesos_v1.AgentID
+	err    error
+}
+
+// Starts a task with the given name, its ID defaults to the name followed by a UUID.
+func NewTask(name string) *TaskBuilder {
+	return &TaskBuilder{name: name, id: name + "-" + utils.UuidAsString()}
+}
+
+// Records the first error hit while building, which Build hands back.
+func (b *TaskBuilder) fail(msg string) *TaskBuilder {
+	if b.err == nil {
+		b.err = errors.New(msg)
+	}
+
+	return b
+}
+
+func (b *TaskBuilder) ID(id string) *TaskBuilder {
+	if id == "" {
+		return b.fail("Task IDs can't be empty.")
+	}
+	b.id = id
+
+	return b
+}
+
+// Role the task's resources are allocated from.
+func (b *TaskBuilder) Role(role string) *TaskBuilder {
+	b.role = role
+
+	return b
+}
+
+func (b *TaskBuilder) CPU(cpu float64) *TaskBuilder {
+	if cpu <= 0 {
+		return b.fail("CPU must be greater than 0.")
+	}
+	b.cpu = cpu
+
+	return b
+}
+
+// Memory in megabytes.
+func (b *TaskBuilder) Mem(mem float64) *TaskBuilder {
+	if mem <= 0 {
+		return b.fail("Memory must be greater than 0.")
+	}
+	b.mem = mem
+
+	return b
+}
+
+// Disk in megabytes.
+func (b *TaskBuilder) Disk(disk float64) *TaskBuilder {
+	if disk <= 0 {
+		return b.fail("Disk must be greater than 0.")
+	}
+	b.disk = disk
+
+	return b
+}
+
+func (b *TaskBuilder) GPU(gpu float64) *TaskBuilder {
+	if gpu < 0 {
+		return b.fail("GPUs can't be negative.")
+	}
+	b.gpu = gpu
+
+	return b
+}
+
+// Runs the command through the shell.
+func (b *TaskBuilder) Shell(cmd string) *TaskBuilder {
+	b.command().Value = utils.ProtoString(cmd)
+	b.cmd.Shell = utils.ProtoBool(true)
+
+	return b
+}
+
+// Runs the program directly with the given arguments, the first of which is conventionally the program name.
+func (b *TaskBuilder) Command(cmd string, args ...string) *TaskBuilder {
+	b.command().Value = utils.ProtoString(cmd)
+	b.cmd.Arguments = args
+	b.cmd.Shell = utils.ProtoBool(false)
+
+	return b
+}
+
+// Fetches an artifact into the sandbox before the task starts.
+func (b *TaskBuilder) URI(uri string, extract, executable bool) *TaskBuilder {
+	if uri == "" {
+		return b.fail("URIs can't be empty.")
+	}
+	b.command().Uris = append(b.cmd.Uris, &mesos_v1.CommandInfo_URI{
+		Value:      utils.ProtoString(uri),
+		Extract:    utils.ProtoBool(extract),
+		Executable: utils.ProtoBool(executable),
+	})
+
+	return b
+}
+
+// Runs the image with the Docker containerizer.
+func (b *TaskBuilder) Docker(image string) *TaskBuilder {
+	if image == "" {
+		return b.fail("Images can't be empty.")
+	}
+	b.image, b.docker = image, true
+
+	return b
+}
+
+// Runs the Docker image with the Mesos containerizer.
+func (b *TaskBuilder) Image(image string) *TaskBuilder {
+	if image == "" {
+		return b.fail("Images can't be empty.")
+	}
+	b.image, b.docker = image, false
+
+	return b
+}
+
+// Requests the port from the offer, Docker containers have it mapped to the same port inside the container.
+func (b *TaskBuilder) Port(port uint32) *TaskBuilder {
+	if port == 0 {
+		return b.fail("Ports must be greater than 0.")
+	}
+	for _, p := range b.ports {
+		if p == port {
+			return b.fail("Ports can only be requested once.")
+		}
+	}
+	b.ports = append(b.ports, port)
+
+	return b
+}
+
+func (b *TaskBuilder) Env(name, value string) *TaskBuilder {
+	if name == "" {
+		return b.fail("Environment variables need a name.")
+	}
+	b.env = append(b.env, &mesos_v1.Environment_Variable{
+		Name:  utils.ProtoString(name),
+		Type:  mesos_v1.Environment_Variable_VALUE.Enum(),
+		Value: utils.ProtoString(value),
+	})
+
+	return b
+}
+
+// Exposes a secret from the secret store as an environment variable.
+func (b *TaskBuilder) Secret(name, secret, key string) *TaskBuilder {
+	if name == "" || secret == "" {
+		return b.fail("Secrets need a variable name and a secret to reference.")
+	}
+
+	ref := &mesos_v1.Secret_Reference{Name: utils.ProtoString(secret)}
+	if key != "" {
+		ref.Key = utils.ProtoString(key)
+	}
+	b.env = append(b.env, &mesos_v1.Environment_Variable{
+		Name:   utils.ProtoString(name),
+		Type:   mesos_v1.Environment_Variable_SECRET.Enum(),
+		Secret: &mesos_v1.Secret{Type: mesos_v1.Secret_REFERENCE.Enum(), Reference: ref},
+	})
+
+	return b
+}
+
+func (b *TaskBuilder) Label(key, value string) *TaskBuilder {
+	if key == "" || value == "" {
+		return b.fail("Labels need a key and value.")
+	}
+	b.labels = append(b.labels, &mesos_v1.Label{Key: utils.ProtoString(key), Value: utils.ProtoString(value)})
+
+	return b
+}
+
+// Mounts a path from the agent into the container.
+func (b *TaskBuilder) Volume(hostPath, containerPath string, readOnly bool) *TaskBuilder {
+	if hostPath == "" || containerPath == "" {
+		return b.fail("Volumes need a host and container path.")
+	}
+
+	mode := mesos_v1.Volume_RW
+	if readOnly {
+		mode = mesos_v1.Volume_RO
+	}
+	b.vols = append(b.vols, &mesos_v1.Volume{
+		Mode:          mode.Enum(),
+		HostPath:      utils.ProtoString(hostPath),
+		ContainerPath: utils.ProtoString(containerPath),
+	})
+
+	return b
+}
+
+// Checks the task over HTTP, an empty path hits the root.
+func (b *TaskBuilder) HTTPCheck(port uint32, path string) *TaskBuilder {
+	if path == "" {
+		path = "/"
+	}
+
+	return b.HealthCheck(&mesos_v1.HealthCheck{
+		Type: mesos_v1.HealthCheck_HTTP.Enum(),
+		Http: &mesos_v1.HealthCheck_HTTPCheckInfo{Port: utils.ProtoUint32(port), Path: utils.ProtoString(path)},
+	})
+}
+
+func (b *TaskBuilder) TCPCheck(port uint32) *TaskBuilder {
+	return b.HealthCheck(&mesos_v1.HealthCheck{
+		Type: mesos_v1.HealthCheck_TCP.Enum(),
+		Tcp:  &mesos_v1.HealthCheck_TCPCheckInfo{Port: utils.ProtoUint32(port)},
+	})
+}
+
+// Uses a health check built by hand, replacing any set before.
+func (b *TaskBuilder) HealthCheck(hc *mesos_v1.HealthCheck) *TaskBuilder {
+	if hc == nil || hc.Type == nil {
+		return b.fail("Health checks need a type.")
+	}
+	b.hc = hc
+
+	return b
+}
+
+// Gives the task time to shut down before it's forcibly killed.
+func (b *TaskBuilder) KillGracePeriod(grace time.Duration) *TaskBuilder {
+	if grace < 0 {
+		return b.fail("Kill grace periods can't be negative.")
+	}
+	b.kill = resources.CreateKillPolicy(grace)
+
+	return b
+}
+
+// Sets the agent when the offer is already known.
+func (b *TaskBuilder) Agent(agent *mesos_v1.AgentID) *TaskBuilder {
+	b.agent = agent
+
+	return b
+}
+
+func (b *TaskBuilder) command() *mesos_v1.CommandInfo {
+	if b.cmd == nil {
+		b.cmd = &mesos_v1.CommandInfo{}
+	}
+
+	return b.cmd
+}
+
+// Builds the task, returning the first error hit along the way.
+func (b *TaskBuilder) Build() (*mesos_v1.TaskInfo, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if b.name == "" {
+		return nil, errors.New("Tasks need a name.")
+	}
+	if b.cpu <= 0 || b.mem <= 0 {
+		return nil, errors.New("CPU and memory must be set.")
+	}
+	if b.image == "" && b.cmd.GetValue() == "" {
+		return nil, errors.New("Tasks need a command unless they run an image.")
+	}
+	if b.hc.GetType() == mesos_v1.HealthCheck_HTTP && b.hc.GetHttp().GetPort() == 0 ||
+		b.hc.GetType() == mesos_v1.HealthCheck_TCP && b.hc.GetTcp().GetPort() == 0 {
+		return nil, errors.New("Health checks need a port.")
+	}
+
+	res := []*mesos_v1.Resource{
+		resources.CreateResource("cpus", b.role, b.cpu),
+		resources.CreateResource("mem", b.role, b.mem),
+	}
+	if b.disk > 0 {
+		res = append(res, resources.CreateResource("disk", b.role, b.disk))
+	}
+	if b.gpu > 0 {
+		res = append(res, resources.CreateResource("gpus", b.role, b.gpu))
+	}
+	if len(b.ports) > 0 {
+		ranges := make([]*mesos_v1.Value_Range, 0, len(b.ports))
+		for _, p := range b.ports {
+			ranges = append(ranges, &mesos_v1.Value_Range{Begin: utils.ProtoUint64(uint64(p)), End: utils.ProtoUint64(uint64(p))})
+		}
+		res = append(res, resources.CreateRangeResource("ports", b.role, ranges))
+	}
+
+	// Images are run with their own entrypoint unless a command is given.
+	cmd := b.cmd
+	if cmd == nil {
+		cmd = &mesos_v1.CommandInfo{Shell: utils.ProtoBool(false)}
+	}
+	if len(b.env) > 0 {
+		cmd.Environment = &mesos_v1.Environment{Variables: b.env}
+	}
+
+	var labels *mesos_v1.Labels
+	if len(b.labels) > 0 {
+		labels = &mesos_v1.Labels{Labels: b.labels}
+	}
+
+	task := resources.CreateTaskInfo(
+		utils.ProtoString(b.name),
+		&mesos_v1.TaskID{Value: utils.ProtoString(b.id)},
+		cmd,
+		res,
+		b.container(),
+		b.hc,
+		labels,
+	)
+	task.AgentId = b.agent
+	task.KillPolicy = b.kill
+
+	return task, nil
+}
+
+func (b *TaskBuilder) container() *mesos_v1.ContainerInfo {
+	if b.image == "" && len(b.vols) == 0 {
+		return nil
+	}
+
+	if !b.docker {
+		c := &mesos_v1.ContainerInfo{Type: mesos_v1.ContainerInfo_MESOS.Enum(), Volumes: b.vols}
+		if b.image != "" {
+			c.Mesos = resources.CreateMesosInfo(resources.CreateImage(mesos_v1.Image_DOCKER.Enum(), b.image))
+		}
+
+		return c
+	}
+
+	// Ports are only mapped on a bridge, otherwise the task binds them on the host directly.
+	network := mesos_v1.ContainerInfo_DockerInfo_HOST
+	var mappings []*mesos_v1.ContainerInfo_DockerInfo_PortMapping
+	if len(b.ports) > 0 {
+		network = mesos_v1.ContainerInfo_DockerInfo_BRIDGE
+		for _, p := range b.ports {
+			mappings = append(mappings, &mesos_v1.ContainerInfo_DockerInfo_PortMapping{
+				HostPort:      utils.ProtoUint32(p),
+				ContainerPort: utils.ProtoUint32(p),
+				Protocol:      utils.ProtoString("tcp"),
+			})
+		}
+	}
+
+	return &mesos_v1.ContainerInfo{
+		Type:    mesos_v1.ContainerInfo_DOCKER.Enum(),
+		Volumes: b.vols,
+		Docker: resources.CreateDockerInfo(
+			resources.CreateImage(mesos_v1.Image_DOCKER.Enum(), b.image),
+			network.Enum(),
+			mappings,
+			nil,
+			nil,
+		),
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taskbuilder
+
+import (
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"testing"
+	"time"
+)
+
+// Ensures tasks are built with everything that was set and invalid settings are rejected.
+func TestTaskBuilder_Build(t *testing.T) {
+	t.Parallel()
+
+	task, err := NewTask("web").
+		ID("web-1").
+		CPU(0.5).
+		Mem(256).
+		Docker("nginx:1.21").
+		Port(80).
+		Env("MODE", "production").
+		Secret("TOKEN", "web-token", "").
+		Label("team", "edge").
+		HTTPCheck(80, "/health").
+		KillGracePeriod(5 * time.Second).
+		Build()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if task.GetName() != "web" || task.GetTaskId().GetValue() != "web-1" {
+		t.Fatal("Task name and ID were not set")
+	}
+	if len(task.GetResources()) != 3 || task.GetResources()[2].GetName() != "ports" {
+		t.Fatal("Task resources were not built correctly")
+	}
+
+	docker := task.GetContainer().GetDocker()
+	if task.GetContainer().GetType() != mesos_v1.ContainerInfo_DOCKER || docker.GetImage() != "nginx:1.21" {
+		t.Fatal("Docker container was not set")
+	}
+	if docker.GetNetwork() != mesos_v1.ContainerInfo_DockerInfo_BRIDGE || docker.GetPortMappings()[0].GetHostPort() != 80 {
+		t.Fatal("Port was not mapped on a bridge")
+	}
+	if len(task.GetCommand().GetEnvironment().GetVariables()) != 2 || task.GetCommand().GetShell() {
+		t.Fatal("Command was not built correctly")
+	}
+	if task.GetHealthCheck().GetHttp().GetPath() != "/health" || task.GetKillPolicy() == nil || len(task.GetLabels().GetLabels()) != 1 {
+		t.Fatal("Task options were not set")
+	}
+
+	invalid := map[string]*TaskBuilder{
+		"no resources":         NewTask("task").Shell("true"),
+		"no command or image":  NewTask("task").CPU(1).Mem(1),
+		"negative cpu":         NewTask("task").CPU(-1).Mem(1).Shell("true"),
+		"duplicate port":       NewTask("task").CPU(1).Mem(1).Shell("true").Port(80).Port(80),
+		"health check no port": NewTask("task").CPU(1).Mem(1).Shell("true").TCPCheck(0),
+		"empty name":           NewTask("").CPU(1).Mem(1).Shell("true"),
+	}
+	for name, b := range invalid {
+		if _, err := b.Build(); err == nil {
+			t.Fatal("Expected an error for " + name)
+		}
+	}
+}
+
+// Measures performance of building a typical task.
+func BenchmarkTaskBuilder_Build(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		NewTask("web").CPU(0.5).Mem(256).Docker("nginx:1.21").Port(80).Env("MODE", "production").Build()
+	}
+}
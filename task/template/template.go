@@ -0,0 +1,208 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package template expands variables in task definitions so one definition can launch many tasks.
+
+Strings anywhere in the definition may reference variables as {{name}}.
+Instances are expanded up front with {{instance}} set to each replica's index,
+while ports are only known once an offer is picked and are expanded at launch:
+
+	apps, err := template.Instances(app, template.Vars{"env": "prod"})
+	...
+	launch, err := template.Expand(apps[0], template.Ports(allocated))
+	if missing := template.Unresolved(launch); len(missing) > 0 {
+		...
+	}
+*/
+package template
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/verizonlabs/mesos-framework-sdk/task"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+const INSTANCE_VAR = "instance"
+
+// Template variables by name.
+type Vars map[string]string
+
+var placeholder = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+
+// Builds the variables for allocated ports, the first port is port0.
+func Ports(ports []uint32) Vars {
+	vars := make(Vars, len(ports))
+	for i, p := range ports {
+		vars["port"+strconv.Itoa(i)] = strconv.FormatUint(uint64(p), 10)
+	}
+
+	return vars
+}
+
+// Returns a copy of the definition with the variables substituted.
+// Variables that aren't given are left in place so they can be expanded later.
+func Expand(app *task.ApplicationJSON, vars Vars) (*task.ApplicationJSON, error) {
+	if app == nil {
+		return nil, errors.New("No task definition to expand.")
+	}
+
+	expanded, err := clone(app)
+	if err != nil {
+		return nil, err
+	}
+
+	walk(reflect.ValueOf(expanded).Elem(), func(s string) string {
+		return placeholder.ReplaceAllStringFunc(s, func(m string) string {
+			if v, ok := vars[placeholder.FindStringSubmatch(m)[1]]; ok {
+				return v
+			}
+			return m
+		})
+	})
+
+	return expanded, nil
+}
+
+// Expands a definition into one single instance definition per replica.
+// Names without an {{instance}} variable get the index appended so every task is named uniquely.
+func Instances(app *task.ApplicationJSON, vars Vars) ([]*task.ApplicationJSON, error) {
+	if app == nil {
+		return nil, errors.New("No task definition to expand.")
+	}
+	if app.Instances < 0 {
+		return nil, errors.New("Instances can't be negative.")
+	}
+
+	named := uses(app.Name, INSTANCE_VAR)
+	apps := make([]*task.ApplicationJSON, 0, app.Instances)
+	for i := 0; i < app.Instances; i++ {
+		v := make(Vars, len(vars)+1)
+		for k, val := range vars {
+			v[k] = val
+		}
+		v[INSTANCE_VAR] = strconv.Itoa(i)
+
+		expanded, err := Expand(app, v)
+		if err != nil {
+			return nil, err
+		}
+		if !named {
+			expanded.Name += "-" + strconv.Itoa(i)
+		}
+		expanded.Instances = 1
+
+		apps = append(apps, expanded)
+	}
+
+	return apps, nil
+}
+
+// Lists the variables still referenced by the definition, sorted by name.
+func Unresolved(app *task.ApplicationJSON) []string {
+	if app == nil {
+		return nil
+	}
+
+	found := make(map[string]struct{})
+	c, err := clone(app)
+	if err != nil {
+		return nil
+	}
+	walk(reflect.ValueOf(c).Elem(), func(s string) string {
+		for _, m := range placeholder.FindAllStringSubmatch(s, -1) {
+			found[m[1]] = struct{}{}
+		}
+		return s
+	})
+
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func uses(s, name string) bool {
+	for _, m := range placeholder.FindAllStringSubmatch(s, -1) {
+		if m[1] == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Deep copies the definition so expanding never touches the original.
+func clone(app *task.ApplicationJSON) (*task.ApplicationJSON, error) {
+	data, err := json.Marshal(app)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &task.ApplicationJSON{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Rewrites every string reachable from the value, including map keys.
+func walk(v reflect.Value, f func(string) string) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			walk(v.Elem(), f)
+		}
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(f(v.String()))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath == "" {
+				walk(v.Field(i), f)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			walk(v.Index(i), f)
+		}
+	case reflect.Map:
+		if v.IsNil() || !v.CanSet() {
+			return
+		}
+
+		m := reflect.MakeMap(v.Type())
+		for _, k := range v.MapKeys() {
+			key := reflect.New(k.Type()).Elem()
+			key.Set(k)
+			walk(key, f)
+
+			val := reflect.New(v.Type().Elem()).Elem()
+			val.Set(v.MapIndex(k))
+			walk(val, f)
+
+			m.SetMapIndex(key, val)
+		}
+		v.Set(m)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package template
+
+import (
+	"github.com/verizonlabs/mesos-framework-sdk/task"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"testing"
+)
+
+func app() *task.ApplicationJSON {
+	return &task.ApplicationJSON{
+		Name:      "web",
+		Instances: 2,
+		Command:   &task.CommandJSON{Cmd: utils.ProtoString("serve --port {{port0}} --env {{env}}")},
+		Labels:    map[string]string{"replica": "{{instance}}"},
+	}
+}
+
+// Ensures instances are uniquely named and ports are expanded afterwards.
+func TestInstances(t *testing.T) {
+	t.Parallel()
+
+	original := app()
+	apps, err := Instances(original, Vars{"env": "prod"})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(apps) != 2 || apps[0].Name != "web-0" || apps[1].Name != "web-1" || apps[1].Instances != 1 {
+		t.Fatal("Instances were not expanded correctly")
+	}
+	if apps[1].Labels["replica"] != "1" {
+		t.Fatal("Instance variable was not substituted")
+	}
+	if missing := Unresolved(apps[0]); len(missing) != 1 || missing[0] != "port0" {
+		t.Fatal("Port variable should still be unresolved")
+	}
+	if original.Labels["replica"] != "{{instance}}" || original.Name != "web" {
+		t.Fatal("Expanding modified the original definition")
+	}
+
+	launch, err := Expand(apps[0], Ports([]uint32{31000}))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if *launch.Command.Cmd != "serve --port 31000 --env prod" || len(Unresolved(launch)) != 0 {
+		t.Fatal("Ports were not expanded: " + *launch.Command.Cmd)
+	}
+
+	named := app()
+	named.Name = "web-{{ instance }}"
+	apps, err = Instances(named, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if apps[1].Name != "web-1" {
+		t.Fatal("Instance variable in the name was not used: " + apps[1].Name)
+	}
+}
+
+// Measures performance of expanding a definition.
+func BenchmarkExpand(b *testing.B) {
+	a := app()
+	vars := Vars{"env": "prod", "port0": "31000", "instance": "0"}
+	for n := 0; n < b.N; n++ {
+		Expand(a, vars)
+	}
+}
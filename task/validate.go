@@ -0,0 +1,574 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task
+
+import (
+	"net"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// A problem with a single field, the path is made of the JSON names leading to it.
+	FieldError struct {
+		Path    string
+		Message string
+	}
+
+	// Every problem found in a task.
+	ValidationErrors []FieldError
+
+	validator struct {
+		errs ValidationErrors
+	}
+)
+
+func (f FieldError) Error() string {
+	return f.Path + " " + f.Message
+}
+
+func (v ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(v))
+	for _, e := range v {
+		msgs = append(msgs, e.Error())
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// Checks the whole task before it's parsed, reporting every invalid field instead of stopping at the first.
+// The returned error is a ValidationErrors when anything is wrong.
+func (a *ApplicationJSON) Validate() error {
+	v := &validator{}
+	if a.Name == "" {
+		v.add("name", "missing")
+	}
+	if a.Instances < 0 {
+		v.add("instances", "must not be negative")
+	}
+
+	if a.Pod != nil {
+		v.pod("pod", a.Pod)
+		if a.Executor != nil {
+			v.add("executor", "can't be set on a pod, use pod.executor instead")
+		}
+	} else {
+		if a.Resources == nil {
+			v.add("resources", "missing")
+		} else {
+			v.resources("resources", a.Resources)
+		}
+
+		// Docker images can rely on their entrypoint instead, custom executors run the task themselves.
+		if a.Executor != nil {
+			v.executor("executor", a.Executor)
+			if a.Command != nil {
+				v.add("command", "can't be set on a task launched under an executor")
+			}
+		} else if a.Command != nil {
+			v.command("command", a.Command)
+		} else if a.Container == nil || a.Container.ImageName == nil {
+			v.add("command", "missing")
+		}
+
+		role := ""
+		if a.Resources != nil {
+			role = a.Resources.Role
+		}
+		v.container("container", a.Container, role)
+		v.healthCheck("healthcheck", a.HealthCheck, a.Command != nil)
+		v.env("", a.Env, a.Secrets)
+		v.discovery("discovery", a.Discovery)
+		v.labels("labels", a.Labels)
+	}
+
+	for i, f := range a.Filters {
+		p := index("filters", i)
+		if f.Type == "" {
+			v.add(p+".type", "missing")
+		}
+		if len(f.Value) == 0 {
+			v.add(p+".value", "missing")
+		}
+	}
+
+	if a.Retry != nil && a.Retry.Time != "" {
+		if _, err := time.ParseDuration(a.Retry.Time); err != nil {
+			v.add("retry.time", "is not a valid duration")
+		}
+	}
+	if a.Retry != nil && a.Retry.MaxRetries < 0 {
+		v.add("retry.total_retries", "must not be negative")
+	}
+	if a.Retry != nil {
+		switch a.Retry.Policy {
+		case "", "never", "on-failure", "always":
+		default:
+			v.add("retry.policy", "must be never, on-failure or always")
+		}
+	}
+
+	for i, dep := range a.DependsOn {
+		p := index("dependsOn", i)
+		if dep == "" {
+			v.add(p, "missing")
+		} else if dep == a.Name {
+			v.add(p, "must not be the task itself")
+		}
+	}
+
+	if len(v.errs) > 0 {
+		return v.errs
+	}
+
+	return nil
+}
+
+func (v *validator) add(path, msg string) {
+	v.errs = append(v.errs, FieldError{Path: path, Message: msg})
+}
+
+func (v *validator) pod(p string, pod *PodJSON) {
+	if pod.Executor != nil {
+		v.resources(p+".executor", pod.Executor)
+	}
+	if len(pod.Containers) == 0 {
+		v.add(p+".containers", "must have at least one container")
+	}
+
+	names := make(map[string]struct{}, len(pod.Containers))
+	for i, c := range pod.Containers {
+		cp := index(p+".containers", i)
+		if c.Name == "" {
+			v.add(cp+".name", "missing")
+		} else if _, ok := names[c.Name]; ok {
+			v.add(cp+".name", "is defined more than once")
+		}
+		names[c.Name] = struct{}{}
+
+		role := ""
+		if c.Resources == nil {
+			v.add(cp+".resources", "missing")
+		} else {
+			v.resources(cp+".resources", c.Resources)
+			role = c.Resources.Role
+		}
+		if c.Command == nil {
+			v.add(cp+".command", "missing")
+		} else {
+			v.command(cp+".command", c.Command)
+		}
+
+		v.container(cp+".container", c.Container, role)
+		v.healthCheck(cp+".healthcheck", c.HealthCheck, c.Command != nil)
+		v.env(cp, c.Env, c.Secrets)
+		v.discovery(cp+".discovery", c.Discovery)
+		v.labels(cp+".labels", c.Labels)
+	}
+}
+
+func (v *validator) executor(p string, e *ExecutorJSON) {
+	if e.Id == "" {
+		v.add(p+".id", "missing")
+	}
+	if e.Command == nil {
+		v.add(p+".command", "missing")
+	} else {
+		v.command(p+".command", e.Command)
+	}
+
+	role := ""
+	if e.Resources != nil {
+		v.resources(p+".resources", e.Resources)
+		role = e.Resources.Role
+	}
+	v.container(p+".container", e.Container, role)
+	v.labels(p+".labels", e.Labels)
+}
+
+func (v *validator) resources(p string, r *ResourceJSON) {
+	if r.Cpu <= 0 {
+		v.add(p+".cpu", "must be greater than 0")
+	}
+	if r.Mem <= 0 {
+		v.add(p+".mem", "must be greater than 0")
+	}
+	if r.Gpu < 0 {
+		v.add(p+".gpu", "must not be negative")
+	}
+	if r.Disk.Size <= 0 {
+		v.add(p+".disk.size", "must be greater than 0")
+	}
+
+	if src := r.Disk.Source; src != nil {
+		sp := p + ".disk.source"
+		if src.Type == nil {
+			v.add(sp+".type", "missing")
+		} else {
+			switch strings.ToLower(*src.Type) {
+			case "path":
+				if src.Path == nil {
+					v.add(sp+".path", "missing")
+				}
+				if src.Mount != nil {
+					v.add(sp+".mount", "can't be set on a path disk")
+				}
+			case "mount":
+				if src.Mount == nil {
+					v.add(sp+".mount", "missing")
+				}
+				if src.Path != nil {
+					v.add(sp+".path", "can't be set on a mount disk")
+				}
+			default:
+				v.add(sp+".type", "must be path or mount")
+			}
+		}
+	}
+
+	for name, value := range r.Custom {
+		switch name {
+		case "cpus", "mem", "gpus", "disk", "ports":
+			v.add(p+".custom."+name, "must be set through its own field")
+		}
+		if value <= 0 {
+			v.add(p+".custom."+name, "must be greater than 0")
+		}
+	}
+}
+
+func (v *validator) command(p string, c *CommandJSON) {
+	if c.Cmd == nil && len(c.Uris) == 0 {
+		v.add(p, "needs a cmd or uris")
+	}
+
+	for i, u := range c.Uris {
+		up := index(p+".uris", i)
+		if u.Uri == nil || *u.Uri == "" {
+			v.add(up+".uri", "missing")
+		}
+		if u.OutputFile != nil && (*u.OutputFile == "" || path.IsAbs(*u.OutputFile)) {
+			v.add(up+".output_file", "must be relative to the sandbox")
+		}
+	}
+}
+
+func (v *validator) container(p string, c *ContainerJSON, role string) {
+	if c == nil {
+		return
+	}
+
+	docker := false
+	if c.ContainerType != nil {
+		switch strings.ToLower(*c.ContainerType) {
+		case "docker":
+			docker = true
+		case "mesos":
+		default:
+			v.add(p+".type", "must be mesos or docker")
+		}
+	}
+	if docker && (c.ImageName == nil || *c.ImageName == "") {
+		v.add(p+".image", "missing")
+	}
+	switch strings.ToLower(c.PullPolicy) {
+	case "", "cached", "always":
+	default:
+		v.add(p+".pull_policy", "must be cached or always")
+	}
+	if (c.PullConfig != nil || c.PullPolicy != "") && c.ImageName == nil {
+		v.add(p+".image", "is required for pull settings")
+	}
+	if c.PullConfig != nil {
+		if docker {
+			v.add(p+".pull_config", "requires the mesos container type")
+		} else if (c.PullConfig.Name == nil) == (c.PullConfig.Value == nil) {
+			v.add(p+".pull_config", "must set either name or value")
+		}
+	}
+	if c.Docker != nil && !docker {
+		v.add(p+".docker", "requires the docker container type")
+	}
+	if docker && len(c.Capabilities) > 0 {
+		v.add(p+".capabilities", "require the mesos container type")
+	}
+	if docker && len(c.Rlimits) > 0 {
+		v.add(p+".rlimits", "require the mesos container type")
+	}
+	if docker && c.Tty {
+		v.add(p+".tty", "requires the mesos container type")
+	}
+	if c.WindowSize != nil && !c.Tty {
+		v.add(p+".window_size", "requires a tty")
+	} else if c.WindowSize != nil && (c.WindowSize.Rows == 0 || c.WindowSize.Columns == 0) {
+		v.add(p+".window_size", "needs both rows and columns")
+	}
+	v.errs = append(v.errs, CheckCapabilities(p+".capabilities", c.Capabilities)...)
+	v.errs = append(v.errs, CheckRLimits(p+".rlimits", c.Rlimits)...)
+
+	for i, n := range c.Network {
+		v.network(index(p+".network", i), n)
+	}
+	for i, vol := range c.Volumes {
+		v.volume(index(p+".volume", i), vol, role)
+	}
+	if c.Docker != nil {
+		v.docker(p+".docker", c.Docker, c.Network)
+	}
+
+	if len(c.HealthChecks) > 1 {
+		v.add(p+".healthChecks", "can only define one health check")
+	}
+	for i := range c.HealthChecks {
+		v.healthCheck(index(p+".healthChecks", i), &c.HealthChecks[i], true)
+	}
+}
+
+func (v *validator) network(p string, n NetworkJSON) {
+	if len(n.PortMapping) > 0 && (n.Name == nil || *n.Name == "") {
+		v.add(p+".name", "is required for port mappings")
+	}
+
+	for i, ip := range n.IpAddresses {
+		ipp := index(p+".ipaddress", i)
+		if ip.IP != nil && net.ParseIP(*ip.IP) == nil {
+			v.add(ipp+".ip", "is not a valid IP address")
+		}
+		if ip.Protocol != nil {
+			protocol := strings.ToLower(*ip.Protocol)
+			if protocol != "ipv4" && protocol != "ipv6" {
+				v.add(ipp+".protocol", "must be ipv4 or ipv6")
+			}
+		}
+	}
+
+	for i, pm := range n.PortMapping {
+		if pm == nil {
+			v.add(index(p+".port_mapping", i), "missing")
+			continue
+		}
+		v.portMapping(index(p+".port_mapping", i), *pm)
+	}
+}
+
+func (v *validator) portMapping(p string, pm PortMapping) {
+	if pm.HostPort == nil {
+		v.add(p+".host_port", "missing")
+	}
+	if pm.ContainerPort == nil {
+		v.add(p+".container_port", "missing")
+	}
+	if pm.Protocol != nil {
+		protocol := strings.ToLower(*pm.Protocol)
+		if protocol != "tcp" && protocol != "udp" {
+			v.add(p+".protocol", "must be tcp or udp")
+		}
+	}
+}
+
+func (v *validator) volume(p string, vol VolumesJSON, role string) {
+	if vol.ContainerPath == nil || *vol.ContainerPath == "" {
+		v.add(p+".container_path", "missing")
+	}
+	if vol.Mode != nil {
+		mode := strings.ToLower(*vol.Mode)
+		if mode != "ro" && mode != "rw" {
+			v.add(p+".mode", "must be ro or rw")
+		}
+	}
+
+	if vol.Source == nil || vol.Source.Type == nil {
+		if vol.HostPath == nil {
+			v.add(p+".host_path", "missing")
+		}
+		return
+	}
+	if vol.HostPath != nil {
+		v.add(p+".host_path", "can't be set on a volume with a source")
+	}
+
+	sp := p + ".source"
+	switch strings.ToLower(*vol.Source.Type) {
+	case "docker":
+		if vol.Source.DockerVolume.Name == nil || *vol.Source.DockerVolume.Name == "" {
+			v.add(sp+".docker_volume.name", "missing")
+		}
+	case "sandbox_path":
+		sandbox := vol.Source.SandboxPath
+		if sandbox == nil || sandbox.Path == nil || *sandbox.Path == "" {
+			v.add(sp+".sandbox_path.path", "missing")
+		} else if cleaned := path.Clean(*sandbox.Path); path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+			v.add(sp+".sandbox_path.path", "must be relative and stay inside the sandbox")
+		}
+		if sandbox != nil && sandbox.Type != nil {
+			t := strings.ToLower(*sandbox.Type)
+			if t != "self" && t != "parent" {
+				v.add(sp+".sandbox_path.type", "must be self or parent")
+			}
+		}
+	case "persistent":
+		persistent := vol.Source.Persistent
+		if persistent == nil || persistent.Id == nil || *persistent.Id == "" {
+			v.add(sp+".persistent.id", "missing")
+		}
+		if persistent != nil && persistent.Size <= 0 {
+			v.add(sp+".persistent.size", "must be greater than 0")
+		}
+		if role == "" || role == "*" {
+			v.add(sp+".persistent", "requires the task to use a reserved role")
+		}
+	default:
+		v.add(sp+".type", "must be docker, sandbox_path or persistent")
+	}
+}
+
+func (v *validator) docker(p string, d *DockerJSON, networks []NetworkJSON) {
+	v.errs = append(v.errs, CheckDockerNetwork(p, d, networks)...)
+
+	for i, pm := range d.PortMappings {
+		v.portMapping(index(p+".port_mapping", i), pm)
+	}
+	for i, param := range d.Parameters {
+		if param.Key == "" {
+			v.add(index(p+".parameters", i)+".key", "missing")
+		}
+	}
+}
+
+// Command checks fall back to the task's command so they're only invalid when neither is set.
+func (v *validator) healthCheck(p string, hc *HealthCheckJSON, hasCommand bool) {
+	if hc == nil {
+		return
+	}
+	if hc.Type == nil {
+		v.add(p+".type", "missing")
+		return
+	}
+
+	switch strings.ToLower(*hc.Type) {
+	case "tcp":
+		if hc.Tcp == nil {
+			v.add(p+".tcp", "missing")
+		} else if hc.Tcp.Port <= 0 || hc.Tcp.Port >= 65535 {
+			v.add(p+".tcp.port", "must be between 0 and 65535")
+		}
+	case "http":
+		if hc.Http == nil {
+			v.add(p+".http", "missing")
+			return
+		}
+		if hc.Http.Path == nil {
+			v.add(p+".http.path", "missing")
+		}
+		if hc.Http.Scheme != nil {
+			scheme := strings.ToLower(*hc.Http.Scheme)
+			if scheme != "http" && scheme != "https" {
+				v.add(p+".http.scheme", "must be http or https")
+			}
+		}
+	case "command":
+		if hc.Command != nil {
+			v.command(p+".command", hc.Command)
+		} else if !hasCommand {
+			v.add(p+".command", "missing")
+		}
+	default:
+		v.add(p+".type", "must be tcp, http or command")
+	}
+}
+
+func (v *validator) env(p string, env map[string]string, secrets map[string]SecretJSON) {
+	prefix := p
+	if prefix != "" {
+		prefix += "."
+	}
+
+	for name := range env {
+		if name == "" {
+			v.add(prefix+"env", "has a variable without a name")
+		}
+	}
+	for name, secret := range secrets {
+		sp := prefix + "secrets." + name
+		if name == "" {
+			v.add(prefix+"secrets", "has a secret without a name")
+		}
+		if _, ok := env[name]; ok && name != "" {
+			v.add(sp, "is already defined in env")
+		}
+		if (secret.Name == nil) == (secret.Value == nil) {
+			v.add(sp, "must set either name or value")
+		} else if secret.Name != nil && *secret.Name == "" {
+			v.add(sp+".name", "missing")
+		}
+	}
+}
+
+func (v *validator) discovery(p string, d *DiscoveryJSON) {
+	if d == nil {
+		return
+	}
+	if d.Name == "" {
+		v.add(p+".name", "missing")
+	}
+	v.visibility(p+".visibility", d.Visibility)
+	v.labels(p+".labels", d.Labels)
+
+	names := make(map[string]struct{}, len(d.Ports))
+	for i, port := range d.Ports {
+		pp := index(p+".ports", i)
+		if port.Number == 0 {
+			v.add(pp+".number", "must be greater than 0")
+		}
+		if port.Name != "" {
+			if _, ok := names[port.Name]; ok {
+				v.add(pp+".name", "is defined more than once")
+			}
+			names[port.Name] = struct{}{}
+		}
+		if port.Protocol != "" {
+			protocol := strings.ToLower(port.Protocol)
+			if protocol != "tcp" && protocol != "udp" {
+				v.add(pp+".protocol", "must be tcp or udp")
+			}
+		}
+		v.visibility(pp+".visibility", port.Visibility)
+		v.labels(pp+".labels", port.Labels)
+	}
+}
+
+func (v *validator) visibility(p, visibility string) {
+	switch strings.ToLower(visibility) {
+	case "", "framework", "cluster", "external":
+	default:
+		v.add(p, "must be framework, cluster or external")
+	}
+}
+
+func (v *validator) labels(p string, labels map[string]string) {
+	for k, val := range labels {
+		if k == "" {
+			v.add(p, "has a label without a key")
+		} else if val == "" {
+			v.add(p+"."+k, "missing")
+		}
+	}
+}
+
+func index(p string, i int) string {
+	return p + "[" + strconv.Itoa(i) + "]"
+}
@@ -0,0 +1,475 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package task
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+)
+
+// Returns every problem found in the task JSON as "path message", sorted since some come from maps.
+func validate(t testing.TB, data string) []string {
+	var app ApplicationJSON
+	if err := json.Unmarshal([]byte(data), &app); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err := app.Validate()
+	if err == nil {
+		return nil
+	}
+	errs, ok := err.(ValidationErrors)
+	if !ok {
+		t.Fatalf("Expected a ValidationErrors, got %T", err)
+	}
+
+	msgs := make([]string, 0, len(errs))
+	for _, e := range errs {
+		msgs = append(msgs, e.Error())
+	}
+	sort.Strings(msgs)
+
+	return msgs
+}
+
+type validateTest struct {
+	name     string
+	data     string
+	expected []string
+}
+
+func runValidateTests(t *testing.T, tests []validateTest) {
+	for _, test := range tests {
+		errs := validate(t, test.data)
+		if len(errs) != len(test.expected) {
+			t.Fatalf("%s: expected %q, got %q", test.name, test.expected, errs)
+		}
+		for i := range errs {
+			if errs[i] != test.expected[i] {
+				t.Fatalf("%s: expected %q, got %q", test.name, test.expected, errs)
+			}
+		}
+	}
+}
+
+// Ensures valid tasks pass and every problem in a task is reported at once.
+func TestApplicationJSON_Validate(t *testing.T) {
+	t.Parallel()
+
+	runValidateTests(t, []validateTest{
+		{"valid command", `{"name": "web", "resources": {"cpu": 0.5, "mem": 128, "disk": {"size": 10}}, "command": {"cmd": "sleep 10"}}`, nil},
+		{"valid docker", `{"name": "web", "resources": {"cpu": 0.5, "mem": 128, "disk": {"size": 10}}, "container": {"type": "docker", "image": "nginx"}}`, nil},
+		{"empty", `{"instances": -1}`, []string{
+			"command missing",
+			"instances must not be negative",
+			"name missing",
+			"resources missing",
+		}},
+		{"filters and retry", `{
+			"name": "web",
+			"resources": {"cpu": 0.5, "mem": 128, "disk": {"size": 10}},
+			"command": {"cmd": "sleep 10"},
+			"filters": [{"type": "TEXT", "value": ["a"]}, {}],
+			"retry": {"time": "soon", "total_retries": -1}
+		}`, []string{
+			"filters[1].type missing",
+			"filters[1].value missing",
+			"retry.time is not a valid duration",
+			"retry.total_retries must not be negative",
+		}},
+		{"labels and env", `{
+			"name": "web",
+			"resources": {"cpu": 0.5, "mem": 128, "disk": {"size": 10}},
+			"command": {"cmd": "sleep 10"},
+			"labels": {"": "a", "team": ""},
+			"env": {"TOKEN": "plain", "": "x"},
+			"secrets": {"TOKEN": {"name": "token"}, "KEY": {}, "EMPTY": {"name": ""}}
+		}`, []string{
+			"env has a variable without a name",
+			"labels has a label without a key",
+			"labels.team missing",
+			"secrets.EMPTY.name missing",
+			"secrets.KEY must set either name or value",
+			"secrets.TOKEN is already defined in env",
+		}},
+	})
+
+	if _, ok := (&ApplicationJSON{}).Validate().(ValidationErrors); !ok {
+		t.Fatal("Invalid tasks should return ValidationErrors")
+	}
+}
+
+// Measures performance of validating a task.
+func BenchmarkApplicationJSON_Validate(b *testing.B) {
+	var app ApplicationJSON
+	json.Unmarshal([]byte(`{"name": "web", "resources": {"cpu": 0.5, "mem": 128, "disk": {"size": 10}}, "command": {"cmd": "sleep 10"}}`), &app)
+	for n := 0; n < b.N; n++ {
+		app.Validate()
+	}
+}
+
+// Ensures resource problems are reported under their field paths, including nested disks and custom resources.
+func TestApplicationJSON_ValidateResources(t *testing.T) {
+	t.Parallel()
+
+	runValidateTests(t, []validateTest{
+		{"scalars", `{"name": "web", "command": {"cmd": "true"}, "resources": {"cpu": 0, "mem": -1, "gpu": -1}}`, []string{
+			"resources.cpu must be greater than 0",
+			"resources.disk.size must be greater than 0",
+			"resources.gpu must not be negative",
+			"resources.mem must be greater than 0",
+		}},
+		{"path disk", `{"name": "web", "command": {"cmd": "true"}, "resources": {"cpu": 1, "mem": 1, "disk": {"size": 1, "source": {"type": "path", "mount": "/mnt"}}}}`, []string{
+			"resources.disk.source.mount can't be set on a path disk",
+			"resources.disk.source.path missing",
+		}},
+		{"mount disk", `{"name": "web", "command": {"cmd": "true"}, "resources": {"cpu": 1, "mem": 1, "disk": {"size": 1, "source": {"type": "mount", "path": "/data"}}}}`, []string{
+			"resources.disk.source.mount missing",
+			"resources.disk.source.path can't be set on a mount disk",
+		}},
+		{"disk type", `{"name": "web", "command": {"cmd": "true"}, "resources": {"cpu": 1, "mem": 1, "disk": {"size": 1, "source": {"type": "block"}}}}`, []string{
+			"resources.disk.source.type must be path or mount",
+		}},
+		{"custom", `{"name": "web", "command": {"cmd": "true"}, "resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}, "custom": {"mem": 1, "licenses": 0}}}`, []string{
+			"resources.custom.licenses must be greater than 0",
+			"resources.custom.mem must be set through its own field",
+		}},
+	})
+}
+
+// Measures performance of validating resources.
+func BenchmarkApplicationJSON_ValidateResources(b *testing.B) {
+	var app ApplicationJSON
+	json.Unmarshal([]byte(`{"name": "web", "command": {"cmd": "true"}, "resources": {"cpu": 0, "disk": {"source": {"type": "block"}}}}`), &app)
+	for n := 0; n < b.N; n++ {
+		app.Validate()
+	}
+}
+
+// Ensures network, port mapping and docker problems point at the exact entry.
+func TestApplicationJSON_ValidatePorts(t *testing.T) {
+	t.Parallel()
+
+	runValidateTests(t, []validateTest{
+		{"network port mappings", `{
+			"name": "web",
+			"resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}},
+			"command": {"cmd": "true"},
+			"container": {"network": [
+				{"name": "overlay"},
+				{"port_mapping": [{"host_port": 80, "container_port": 8080}, null, {"protocol": "sctp"}],
+				 "ipaddress": [{"ip": "10.0.0.1"}, {"ip": "nope", "protocol": "ipv5"}]}
+			]}
+		}`, []string{
+			"container.network[1].ipaddress[1].ip is not a valid IP address",
+			"container.network[1].ipaddress[1].protocol must be ipv4 or ipv6",
+			"container.network[1].name is required for port mappings",
+			"container.network[1].port_mapping[1] missing",
+			"container.network[1].port_mapping[2].container_port missing",
+			"container.network[1].port_mapping[2].host_port missing",
+			"container.network[1].port_mapping[2].protocol must be tcp or udp",
+		}},
+		{"docker port mappings", `{
+			"name": "web",
+			"resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}},
+			"container": {"type": "docker", "image": "nginx", "docker": {
+				"network": "host",
+				"port_mapping": [{"host_port": 80, "container_port": 80, "protocol": "tcp"}, {"host_port": 81}],
+				"parameters": [{"key": "ulimit", "value": "nofile=1024"}, {"value": "x"}]
+			}}
+		}`, []string{
+			"container.docker.parameters[1].key missing",
+			"container.docker.port_mapping requires bridge or user networking",
+			"container.docker.port_mapping[1].container_port missing",
+		}},
+		{"docker network", `{
+			"name": "web",
+			"resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}},
+			"container": {"type": "docker", "image": "nginx", "docker": {"network": "overlay"}}
+		}`, []string{
+			"container.docker.network must be host, bridge, none or user",
+		}},
+		{"docker user network", `{
+			"name": "web",
+			"resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}},
+			"container": {"type": "docker", "image": "nginx", "docker": {"network": "user"}}
+		}`, []string{
+			"container.docker.network requires exactly one named network for user networking",
+		}},
+		{"docker named user network", `{
+			"name": "web",
+			"resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}},
+			"container": {"type": "docker", "image": "nginx", "network": [{"name": "overlay"}], "docker": {"network": "user"}}
+		}`, nil},
+		{"linux", `{
+			"name": "web",
+			"resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}},
+			"command": {"cmd": "true"},
+			"container": {
+				"capabilities": ["net_admin", "CAP_SYS_TIME", "BOGUS"],
+				"rlimits": [
+					{"type": "nofile", "soft": 1, "hard": 2},
+					{"type": "RLMT_NOFILE"},
+					{"type": "bogus"},
+					{"type": "cpu", "soft": 1},
+					{"type": "as", "soft": 2, "hard": 1},
+					{}
+				]
+			}
+		}`, []string{
+			"container.capabilities[2] is not a known capability",
+			"container.rlimits[1].type is set more than once",
+			"container.rlimits[2].type is not a known rlimit type",
+			"container.rlimits[3] must set both soft and hard or neither",
+			"container.rlimits[4].soft can't exceed the hard limit",
+			"container.rlimits[5].type missing",
+		}},
+		{"discovery ports", `{
+			"name": "web",
+			"resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}},
+			"command": {"cmd": "true"},
+			"discovery": {"visibility": "world", "ports": [
+				{"number": 80, "name": "http"},
+				{"number": 0, "name": "http", "protocol": "icmp", "visibility": "private"}
+			]}
+		}`, []string{
+			"discovery.name missing",
+			"discovery.ports[1].name is defined more than once",
+			"discovery.ports[1].number must be greater than 0",
+			"discovery.ports[1].protocol must be tcp or udp",
+			"discovery.ports[1].visibility must be framework, cluster or external",
+			"discovery.visibility must be framework, cluster or external",
+		}},
+	})
+}
+
+// Measures performance of validating ports.
+func BenchmarkApplicationJSON_ValidatePorts(b *testing.B) {
+	var app ApplicationJSON
+	json.Unmarshal([]byte(`{"name": "web", "resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}}, "command": {"cmd": "true"},
+		"container": {"network": [{"port_mapping": [{"protocol": "sctp"}]}]}}`), &app)
+	for n := 0; n < b.N; n++ {
+		app.Validate()
+	}
+}
+
+// Ensures health check problems are reported for the task, its container and pod containers.
+func TestApplicationJSON_ValidateHealthChecks(t *testing.T) {
+	t.Parallel()
+
+	runValidateTests(t, []validateTest{
+		{"missing type", `{"name": "web", "resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}}, "command": {"cmd": "true"}, "healthcheck": {}}`, []string{
+			"healthcheck.type missing",
+		}},
+		{"unknown type", `{"name": "web", "resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}}, "command": {"cmd": "true"}, "healthcheck": {"type": "udp"}}`, []string{
+			"healthcheck.type must be tcp, http or command",
+		}},
+		{"tcp", `{"name": "web", "resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}}, "command": {"cmd": "true"}, "healthcheck": {"type": "TCP", "tcp": {"port": 70000}}}`, []string{
+			"healthcheck.tcp.port must be between 0 and 65535",
+		}},
+		{"http", `{"name": "web", "resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}}, "command": {"cmd": "true"}, "healthcheck": {"type": "http", "http": {"scheme": "ftp"}}}`, []string{
+			"healthcheck.http.path missing",
+			"healthcheck.http.scheme must be http or https",
+		}},
+		{"command falls back to the task", `{"name": "web", "resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}}, "command": {"cmd": "true"}, "healthcheck": {"type": "command"}}`, nil},
+		{"command without a task command", `{
+			"name": "web",
+			"resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}},
+			"container": {"type": "docker", "image": "nginx"},
+			"healthcheck": {"type": "command"}
+		}`, []string{
+			"healthcheck.command missing",
+		}},
+		{"container health checks", `{
+			"name": "web",
+			"resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}},
+			"command": {"cmd": "true"},
+			"container": {"healthChecks": [{"type": "tcp", "tcp": {"port": 80}}, {"type": "http"}]}
+		}`, []string{
+			"container.healthChecks can only define one health check",
+			"container.healthChecks[1].http missing",
+		}},
+	})
+}
+
+// Measures performance of validating health checks.
+func BenchmarkApplicationJSON_ValidateHealthChecks(b *testing.B) {
+	var app ApplicationJSON
+	json.Unmarshal([]byte(`{"name": "web", "resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}}, "command": {"cmd": "true"}, "healthcheck": {"type": "http", "http": {"scheme": "ftp"}}}`), &app)
+	for n := 0; n < b.N; n++ {
+		app.Validate()
+	}
+}
+
+// Ensures volume problems are reported under the volume's index for every source type.
+func TestApplicationJSON_ValidateVolumes(t *testing.T) {
+	t.Parallel()
+
+	runValidateTests(t, []validateTest{
+		{"host paths", `{
+			"name": "web",
+			"resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}},
+			"command": {"cmd": "true"},
+			"container": {"volume": [
+				{"container_path": "/data", "host_path": "/mnt/data", "mode": "RW"},
+				{"mode": "rwx"}
+			]}
+		}`, []string{
+			"container.volume[1].container_path missing",
+			"container.volume[1].host_path missing",
+			"container.volume[1].mode must be ro or rw",
+		}},
+		{"docker and sandbox sources", `{
+			"name": "web",
+			"resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}},
+			"command": {"cmd": "true"},
+			"container": {"volume": [
+				{"container_path": "/a", "host_path": "/mnt", "source": {"type": "docker"}},
+				{"container_path": "/b", "source": {"type": "sandbox_path", "sandbox_path": {"type": "sibling", "path": "../escape"}}},
+				{"container_path": "/c", "source": {"type": "sandbox_path", "sandbox_path": {"type": "parent", "path": "shared"}}},
+				{"container_path": "/d", "source": {"type": "nfs"}}
+			]}
+		}`, []string{
+			"container.volume[0].host_path can't be set on a volume with a source",
+			"container.volume[0].source.docker_volume.name missing",
+			"container.volume[1].source.sandbox_path.path must be relative and stay inside the sandbox",
+			"container.volume[1].source.sandbox_path.type must be self or parent",
+			"container.volume[3].source.type must be docker, sandbox_path or persistent",
+		}},
+		{"persistent without a role", `{
+			"name": "web",
+			"resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}},
+			"command": {"cmd": "true"},
+			"container": {"volume": [{"container_path": "data", "source": {"type": "persistent", "persistent": {"size": 0}}}]}
+		}`, []string{
+			"container.volume[0].source.persistent requires the task to use a reserved role",
+			"container.volume[0].source.persistent.id missing",
+			"container.volume[0].source.persistent.size must be greater than 0",
+		}},
+		{"persistent with a role", `{
+			"name": "web",
+			"resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}, "role": "db"},
+			"command": {"cmd": "true"},
+			"container": {"volume": [{"container_path": "data", "source": {"type": "persistent", "persistent": {"id": "pv-1", "size": 100}}}]}
+		}`, nil},
+	})
+}
+
+// Measures performance of validating volumes.
+func BenchmarkApplicationJSON_ValidateVolumes(b *testing.B) {
+	var app ApplicationJSON
+	json.Unmarshal([]byte(`{"name": "web", "resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}}, "command": {"cmd": "true"},
+		"container": {"volume": [{"source": {"type": "sandbox_path", "sandbox_path": {"path": "/abs"}}}]}}`), &app)
+	for n := 0; n < b.N; n++ {
+		app.Validate()
+	}
+}
+
+// Ensures pod containers and executors are validated with paths leading through them.
+func TestApplicationJSON_ValidatePods(t *testing.T) {
+	t.Parallel()
+
+	runValidateTests(t, []validateTest{
+		{"no containers", `{"name": "web", "pod": {"containers": []}}`, []string{
+			"pod.containers must have at least one container",
+		}},
+		{"containers", `{
+			"name": "web",
+			"executor": {"id": "exec"},
+			"pod": {"executor": {"cpu": 0.1, "mem": 32}, "containers": [
+				{"name": "app", "resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}}, "command": {"cmd": "true"}},
+				{"name": "app", "container": {"type": "mesos", "volume": [{"container_path": "/x"}]}, "labels": {"tier": ""}}
+			]}
+		}`, []string{
+			"executor can't be set on a pod, use pod.executor instead",
+			"pod.containers[1].command missing",
+			"pod.containers[1].container.volume[0].host_path missing",
+			"pod.containers[1].labels.tier missing",
+			"pod.containers[1].name is defined more than once",
+			"pod.containers[1].resources missing",
+			"pod.executor.disk.size must be greater than 0",
+		}},
+		{"executor", `{
+			"name": "web",
+			"resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}},
+			"command": {"cmd": "true"},
+			"executor": {"command": {"uris": [{"uri": ""}, {"uri": "http://x/y", "output_file": "/etc/passwd"}]}, "resources": {"cpu": 1, "mem": 1}}
+		}`, []string{
+			"command can't be set on a task launched under an executor",
+			"executor.command.uris[0].uri missing",
+			"executor.command.uris[1].output_file must be relative to the sandbox",
+			"executor.id missing",
+			"executor.resources.disk.size must be greater than 0",
+		}},
+	})
+}
+
+// Measures performance of validating pods.
+func BenchmarkApplicationJSON_ValidatePods(b *testing.B) {
+	var app ApplicationJSON
+	json.Unmarshal([]byte(`{"name": "web", "pod": {"containers": [{"name": "app"}, {"name": "app"}]}}`), &app)
+	for n := 0; n < b.N; n++ {
+		app.Validate()
+	}
+}
+
+// Ensures restart policies are limited to the known ones.
+func TestApplicationJSON_ValidateRetryPolicy(t *testing.T) {
+	t.Parallel()
+
+	for _, policy := range []string{"", "never", "on-failure", "always"} {
+		errs := validate(t, `{"name": "web", "resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}}, "command": {"cmd": "true"}, "retry": {"policy": "`+policy+`"}}`)
+		if len(errs) > 0 {
+			t.Fatalf("Policy %q should be valid, got %q", policy, errs)
+		}
+	}
+
+	runValidateTests(t, []validateTest{
+		{"unknown policy", `{"name": "web", "resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}}, "command": {"cmd": "true"}, "retry": {"policy": "sometimes", "time": "1s"}}`, []string{
+			"retry.policy must be never, on-failure or always",
+		}},
+	})
+}
+
+// Measures performance of validating restart policies.
+func BenchmarkApplicationJSON_ValidateRetryPolicy(b *testing.B) {
+	var app ApplicationJSON
+	json.Unmarshal([]byte(`{"name": "web", "resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}}, "command": {"cmd": "true"}, "retry": {"policy": "sometimes"}}`), &app)
+	for n := 0; n < b.N; n++ {
+		app.Validate()
+	}
+}
+
+// Ensures dependencies are named and never point at the task itself.
+func TestApplicationJSON_ValidateDependsOn(t *testing.T) {
+	t.Parallel()
+
+	runValidateTests(t, []validateTest{
+		{"valid", `{"name": "web", "resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}}, "command": {"cmd": "true"}, "dependsOn": ["db", "cache"]}`, nil},
+		{"invalid", `{"name": "web", "resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}}, "command": {"cmd": "true"}, "dependsOn": ["db", "", "web"]}`, []string{
+			"dependsOn[1] missing",
+			"dependsOn[2] must not be the task itself",
+		}},
+	})
+}
+
+// Measures performance of validating dependencies.
+func BenchmarkApplicationJSON_ValidateDependsOn(b *testing.B) {
+	var app ApplicationJSON
+	json.Unmarshal([]byte(`{"name": "web", "resources": {"cpu": 1, "mem": 1, "disk": {"size": 1}}, "command": {"cmd": "true"}, "dependsOn": ["db", "", "web"]}`), &app)
+	for n := 0; n < b.N; n++ {
+		app.Validate()
+	}
+}
@@ -17,60 +17,66 @@ package volume
 import (
 	"errors"
 	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/resources"
 	"github.com/verizonlabs/mesos-framework-sdk/task"
+	"path"
+	"sort"
 	"strings"
 
 	"github.com/golang/protobuf/proto"
 )
 
+// Parses container volumes, persistent volumes are left out since they're requested as resources instead.
 func ParseVolumeJSON(volumes []task.VolumesJSON) ([]*mesos_v1.Volume, error) {
 	mesosVolumes := []*mesos_v1.Volume{}
 	for _, volume := range volumes {
-		v := mesos_v1.Volume{}
-		if volume.Mode == nil {
-			v.Mode = mesos_v1.Volume_RW.Enum()
-		} else {
-			if strings.ToLower(*volume.Mode) == "RO" {
-				v.Mode = mesos_v1.Volume_RO.Enum()
-			} else if strings.ToLower(*volume.Mode) == "RW" {
-				v.Mode = mesos_v1.Volume_RW.Enum()
-			}
+		if volume.ContainerPath == nil || *volume.ContainerPath == "" {
+			return nil, errors.New("Volumes must have a container path.")
 		}
-		// Logical XOR to tell if both are set or not.
-		if (volume.ContainerPath == nil) != (volume.HostPath == nil) {
-			// Fail parsing and pass back error.
-			return nil, errors.New("Both container and host path must be set.")
+
+		v := mesos_v1.Volume{ContainerPath: volume.ContainerPath}
+		mode, err := parseMode(volume.Mode)
+		if err != nil {
+			return nil, err
 		}
-		if volume.ContainerPath != nil {
-			v.ContainerPath = volume.ContainerPath
+		v.Mode = mode
+
+		if volume.Source == nil || volume.Source.Type == nil {
+			if volume.HostPath == nil {
+				return nil, errors.New("Both container and host path must be set.")
+			}
+			v.HostPath = volume.HostPath
+			mesosVolumes = append(mesosVolumes, &v)
+			continue
 		}
+
 		if volume.HostPath != nil {
-			v.HostPath = volume.HostPath
+			return nil, errors.New("Host path can't be set on a volume with a source.")
 		}
 
-		if (volume.Source != nil) && (volume.Source.Type != nil) {
-			src := mesos_v1.Volume_Source{}
-			if strings.ToLower(*volume.Source.Type) == "docker" {
-				src.Type = mesos_v1.Volume_Source_DOCKER_VOLUME.Enum()
-				src.DockerVolume = ParseDockerVolumeJSON(&volume.Source.DockerVolume)
-			} else {
-				src.Type = mesos_v1.Volume_Source_SANDBOX_PATH.Enum()
-				sandbox := mesos_v1.Volume_Source_SandboxPath{}
-				sandbox.Type = mesos_v1.Volume_Source_SandboxPath_SELF.Enum()
-				sandbox.Path = proto.String(".")
-
-				v.Source = &src
-				v.Source.SandboxPath = &sandbox
+		switch strings.ToLower(*volume.Source.Type) {
+		case "docker":
+			docker, err := ParseDockerVolumeJSON(&volume.Source.DockerVolume)
+			if err != nil {
+				return nil, err
+			}
+			v.Source = &mesos_v1.Volume_Source{
+				Type:         mesos_v1.Volume_Source_DOCKER_VOLUME.Enum(),
+				DockerVolume: docker,
+			}
+		case "sandbox_path":
+			sandbox, err := ParseSandboxPathJSON(volume.Source.SandboxPath)
+			if err != nil {
+				return nil, err
 			}
-		} else {
-			src := mesos_v1.Volume_Source{}
-			src.Type = mesos_v1.Volume_Source_SANDBOX_PATH.Enum()
-			sandbox := mesos_v1.Volume_Source_SandboxPath{}
-			sandbox.Type = mesos_v1.Volume_Source_SandboxPath_SELF.Enum()
-			sandbox.Path = proto.String(".")
-
-			v.Source = &src
-			v.Source.SandboxPath = &sandbox
+			v.Source = &mesos_v1.Volume_Source{
+				Type:        mesos_v1.Volume_Source_SANDBOX_PATH.Enum(),
+				SandboxPath: sandbox,
+			}
+		case "persistent":
+			continue
+		default:
+			return nil, errors.New("Invalid volume source, accepted values are docker, sandbox_path, persistent.")
 		}
 
 		mesosVolumes = append(mesosVolumes, &v)
@@ -78,8 +84,47 @@ func ParseVolumeJSON(volumes []task.VolumesJSON) ([]*mesos_v1.Volume, error) {
 	return mesosVolumes, nil
 }
 
-func ParseDockerVolumeJSON(dockerVolume *task.DockerVolumeJSON) *mesos_v1.Volume_Source_DockerVolume {
-	source := mesos_v1.Volume_Source_DockerVolume{}
+// Parses persistent volume references into the disk resources that mount them.
+func ParsePersistentVolumes(volumes []task.VolumesJSON, role string) ([]*mesos_v1.Resource, error) {
+	var res []*mesos_v1.Resource
+	for _, volume := range volumes {
+		if volume.Source == nil || volume.Source.Type == nil || strings.ToLower(*volume.Source.Type) != "persistent" {
+			continue
+		}
+
+		p := volume.Source.Persistent
+		if p == nil || p.Id == nil || *p.Id == "" {
+			return nil, errors.New("Persistent volumes must reference an ID.")
+		}
+		if p.Size <= 0 {
+			return nil, errors.New("Persistent volumes must have a positive size.")
+		}
+		if role == "" || role == "*" {
+			return nil, errors.New("Persistent volumes can only be created for a reserved role.")
+		}
+		if volume.ContainerPath == nil || *volume.ContainerPath == "" {
+			return nil, errors.New("Volumes must have a container path.")
+		}
+
+		resource := resources.CreatePersistentVolume(*p.Id, role, p.Principal, *volume.ContainerPath, p.Size)
+		mode, err := parseMode(volume.Mode)
+		if err != nil {
+			return nil, err
+		}
+		resource.Disk.Volume.Mode = mode
+
+		res = append(res, resource)
+	}
+
+	return res, nil
+}
+
+func ParseDockerVolumeJSON(dockerVolume *task.DockerVolumeJSON) (*mesos_v1.Volume_Source_DockerVolume, error) {
+	if dockerVolume.Name == nil || *dockerVolume.Name == "" {
+		return nil, errors.New("Docker volumes must have a name.")
+	}
+
+	source := mesos_v1.Volume_Source_DockerVolume{Name: dockerVolume.Name}
 	// Do we only want to support certain drivers?
 	if dockerVolume.Driver != nil {
 		source.Driver = dockerVolume.Driver
@@ -87,17 +132,61 @@ func ParseDockerVolumeJSON(dockerVolume *task.DockerVolumeJSON) *mesos_v1.Volume
 	if len(dockerVolume.DriverOptions) > 0 {
 		params := []*mesos_v1.Parameter{}
 		for _, options := range dockerVolume.DriverOptions {
-			for k, v := range options {
-				p := mesos_v1.Parameter{}
-				p.Key, p.Value = proto.String(k), proto.String(v)
-				params = append(params, &p)
+			keys := make([]string, 0, len(options))
+			for k := range options {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+
+			for _, k := range keys {
+				params = append(params, &mesos_v1.Parameter{Key: proto.String(k), Value: proto.String(options[k])})
 			}
 		}
-		source.DriverOptions.Parameter = params
+		source.DriverOptions = &mesos_v1.Parameters{Parameter: params}
 	}
-	if dockerVolume.Name != nil {
-		source.Name = dockerVolume.Name
+
+	return &source, nil
+}
+
+// Parses a path shared from this container's sandbox or, for pods, the executor's.
+func ParseSandboxPathJSON(sandboxPath *task.SandboxPathJSON) (*mesos_v1.Volume_Source_SandboxPath, error) {
+	if sandboxPath == nil || sandboxPath.Path == nil || *sandboxPath.Path == "" {
+		return nil, errors.New("Sandbox path volumes must have a path.")
 	}
 
-	return &source
+	p := path.Clean(*sandboxPath.Path)
+	if path.IsAbs(p) || p == ".." || strings.HasPrefix(p, "../") {
+		return nil, errors.New("Sandbox paths must be relative and can't leave the sandbox.")
+	}
+
+	sandbox := &mesos_v1.Volume_Source_SandboxPath{
+		Type: mesos_v1.Volume_Source_SandboxPath_SELF.Enum(),
+		Path: proto.String(p),
+	}
+	if sandboxPath.Type != nil {
+		switch strings.ToLower(*sandboxPath.Type) {
+		case "self":
+		case "parent":
+			sandbox.Type = mesos_v1.Volume_Source_SandboxPath_PARENT.Enum()
+		default:
+			return nil, errors.New("Invalid sandbox path type, accepted values are self, parent.")
+		}
+	}
+
+	return sandbox, nil
+}
+
+func parseMode(mode *string) (*mesos_v1.Volume_Mode, error) {
+	if mode == nil {
+		return mesos_v1.Volume_RW.Enum(), nil
+	}
+
+	switch strings.ToLower(*mode) {
+	case "ro":
+		return mesos_v1.Volume_RO.Enum(), nil
+	case "rw":
+		return mesos_v1.Volume_RW.Enum(), nil
+	default:
+		return nil, errors.New("Invalid volume mode, accepted values are ro, rw.")
+	}
 }
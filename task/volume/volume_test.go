@@ -0,0 +1,179 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package volume
+
+import (
+	"encoding/json"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	"github.com/verizonlabs/mesos-framework-sdk/task"
+	"testing"
+)
+
+func parse(t testing.TB, data string) []task.VolumesJSON {
+	var volumes []task.VolumesJSON
+	if err := json.Unmarshal([]byte(data), &volumes); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	return volumes
+}
+
+// Ensures each volume source is parsed and invalid volumes are rejected.
+func TestParseVolumeJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		data  string
+		count int
+		fail  bool
+	}{
+		{"host", `[{"container_path": "/data", "host_path": "/mnt/data", "mode": "RO"}]`, 1, false},
+		{"docker", `[{"container_path": "/data", "source": {"type": "docker", "docker_volume": {"name": "data", "driver": "rexray"}}}]`, 1, false},
+		{"sandbox", `[{"container_path": "/data", "source": {"type": "SANDBOX_PATH", "sandbox_path": {"type": "parent", "path": "shared"}}}]`, 1, false},
+		{"persistent", `[{"container_path": "data", "source": {"type": "persistent", "persistent": {"id": "db", "size": 64}}}]`, 0, false},
+		{"no container path", `[{"host_path": "/mnt/data"}]`, 0, true},
+		{"no host path", `[{"container_path": "/data"}]`, 0, true},
+		{"mode", `[{"container_path": "/data", "host_path": "/mnt/data", "mode": "wo"}]`, 0, true},
+		{"host path with source", `[{"container_path": "/data", "host_path": "/mnt/data", "source": {"type": "docker", "docker_volume": {"name": "data"}}}]`, 0, true},
+		{"unknown source", `[{"container_path": "/data", "source": {"type": "nfs"}}]`, 0, true},
+		{"docker no name", `[{"container_path": "/data", "source": {"type": "docker", "docker_volume": {"driver": "rexray"}}}]`, 0, true},
+		{"sandbox no path", `[{"container_path": "/data", "source": {"type": "sandbox_path"}}]`, 0, true},
+	}
+
+	for _, test := range tests {
+		volumes, err := ParseVolumeJSON(parse(t, test.data))
+		if (err != nil) != test.fail {
+			t.Fatalf("%s: unexpected error state: %v", test.name, err)
+		}
+		if len(volumes) != test.count {
+			t.Fatalf("%s: expected %d volumes, got %d", test.name, test.count, len(volumes))
+		}
+	}
+
+	volumes, _ := ParseVolumeJSON(parse(t, `[{"container_path": "/data", "host_path": "/mnt/data"}]`))
+	if volumes[0].GetMode() != mesos_v1.Volume_RW || volumes[0].GetHostPath() != "/mnt/data" {
+		t.Fatal("Host volumes should default to read-write")
+	}
+}
+
+// Measures performance of parsing volumes.
+func BenchmarkParseVolumeJSON(b *testing.B) {
+	volumes := parse(b, `[{"container_path": "/data", "host_path": "/mnt/data"},
+		{"container_path": "/cache", "source": {"type": "docker", "docker_volume": {"name": "cache"}}}]`)
+	for n := 0; n < b.N; n++ {
+		ParseVolumeJSON(volumes)
+	}
+}
+
+// Ensures docker volumes carry their driver and sorted options.
+func TestParseDockerVolumeJSON(t *testing.T) {
+	t.Parallel()
+
+	volumes := parse(t, `[{"source": {"docker_volume": {"name": "data", "driver": "rexray", "driver_opts": [{"size": "10", "iops": "100"}]}}}]`)
+	docker, err := ParseDockerVolumeJSON(&volumes[0].Source.DockerVolume)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if docker.GetName() != "data" || docker.GetDriver() != "rexray" {
+		t.Fatal("Docker volume name and driver were not carried over")
+	}
+	params := docker.GetDriverOptions().GetParameter()
+	if len(params) != 2 || params[0].GetKey() != "iops" || params[1].GetKey() != "size" {
+		t.Fatal("Driver options should be sorted by key")
+	}
+}
+
+// Ensures sandbox paths stay inside the sandbox.
+func TestParseSandboxPathJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		data string
+		path string
+		kind mesos_v1.Volume_Source_SandboxPath_Type
+		fail bool
+	}{
+		{`{"path": "shared"}`, "shared", mesos_v1.Volume_Source_SandboxPath_SELF, false},
+		{`{"type": "SELF", "path": "a/./b/"}`, "a/b", mesos_v1.Volume_Source_SandboxPath_SELF, false},
+		{`{"type": "parent", "path": "shared"}`, "shared", mesos_v1.Volume_Source_SandboxPath_PARENT, false},
+		{`{"type": "sibling", "path": "shared"}`, "", 0, true},
+		{`{"path": ""}`, "", 0, true},
+		{`{"path": "/shared"}`, "", 0, true},
+		{`{"path": "../shared"}`, "", 0, true},
+		{`{"path": "a/../.."}`, "", 0, true},
+	}
+
+	for _, test := range tests {
+		var sandboxPath task.SandboxPathJSON
+		if err := json.Unmarshal([]byte(test.data), &sandboxPath); err != nil {
+			t.Fatal(err.Error())
+		}
+
+		sandbox, err := ParseSandboxPathJSON(&sandboxPath)
+		if (err != nil) != test.fail {
+			t.Fatalf("%s: unexpected error state: %v", test.data, err)
+		}
+		if err != nil {
+			continue
+		}
+		if sandbox.GetPath() != test.path || sandbox.GetType() != test.kind {
+			t.Fatalf("%s: expected %s %s, got %s %s", test.data, test.kind, test.path, sandbox.GetType(), sandbox.GetPath())
+		}
+	}
+
+	if _, err := ParseSandboxPathJSON(nil); err == nil {
+		t.Fatal("A missing sandbox path should be rejected")
+	}
+}
+
+// Ensures persistent volumes become reserved disk resources.
+func TestParsePersistentVolumes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		data  string
+		role  string
+		count int
+		fail  bool
+	}{
+		{"persistent", `[{"container_path": "data", "mode": "ro", "source": {"type": "persistent", "persistent": {"id": "db", "size": 64, "principal": "fw"}}}]`, "role", 1, false},
+		{"other sources", `[{"container_path": "/data", "host_path": "/mnt/data"}]`, "role", 0, false},
+		{"no id", `[{"container_path": "data", "source": {"type": "persistent", "persistent": {"size": 64}}}]`, "role", 0, true},
+		{"no size", `[{"container_path": "data", "source": {"type": "persistent", "persistent": {"id": "db"}}}]`, "role", 0, true},
+		{"unreserved", `[{"container_path": "data", "source": {"type": "persistent", "persistent": {"id": "db", "size": 64}}}]`, "*", 0, true},
+		{"no role", `[{"container_path": "data", "source": {"type": "persistent", "persistent": {"id": "db", "size": 64}}}]`, "", 0, true},
+		{"no container path", `[{"source": {"type": "persistent", "persistent": {"id": "db", "size": 64}}}]`, "role", 0, true},
+		{"mode", `[{"container_path": "data", "mode": "wo", "source": {"type": "persistent", "persistent": {"id": "db", "size": 64}}}]`, "role", 0, true},
+	}
+
+	for _, test := range tests {
+		res, err := ParsePersistentVolumes(parse(t, test.data), test.role)
+		if (err != nil) != test.fail {
+			t.Fatalf("%s: unexpected error state: %v", test.name, err)
+		}
+		if len(res) != test.count {
+			t.Fatalf("%s: expected %d resources, got %d", test.name, test.count, len(res))
+		}
+	}
+
+	res, _ := ParsePersistentVolumes(parse(t, tests[0].data), "role")
+	disk := res[0].GetDisk()
+	if disk.GetPersistence().GetId() != "db" || disk.GetPersistence().GetPrincipal() != "fw" ||
+		disk.GetVolume().GetContainerPath() != "data" || disk.GetVolume().GetMode() != mesos_v1.Volume_RO {
+		t.Fatal("Persistent volume settings were not carried over")
+	}
+}
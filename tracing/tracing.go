@@ -0,0 +1,163 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"github.com/verizonlabs/mesos-framework-sdk/client"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	sched "github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_scheduler"
+	"github.com/verizonlabs/mesos-framework-sdk/scheduler"
+	"net/http"
+	"strings"
+)
+
+/*
+The tracing package puts a span around every call the scheduler makes and every event it dispatches.
+Spans are created through the Tracer interface so any tracing library can back it,
+an OpenTelemetry tracer with an OTLP exporter only needs a thin adapter.
+*/
+
+// Attribute keys set on spans.
+const (
+	STREAM_ID    = "mesos.stream_id"
+	FRAMEWORK_ID = "mesos.framework_id"
+	CALL_TYPE    = "mesos.call.type"
+	EVENT_TYPE   = "mesos.event.type"
+	TASK_IDS     = "mesos.task_ids"
+	OFFER_IDS    = "mesos.offer_ids"
+	AGENT_ID     = "mesos.agent_id"
+	STATUS_CODE  = "http.status_code"
+)
+
+// Starts spans, the returned context carries the span so nested spans become its children.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+// Traces every call, named after the call's type.
+// Subscribe spans last as long as the event stream does.
+func Interceptor(tracer Tracer, c client.Client) scheduler.Interceptor {
+	return func(ctx context.Context, call *sched.Call, next scheduler.Invoker) (*http.Response, error) {
+		ctx, span := tracer.Start(ctx, "mesos."+call.GetType().String())
+		defer span.End()
+
+		span.SetAttribute(CALL_TYPE, call.GetType().String())
+		if id := call.GetFrameworkId().GetValue(); id != "" {
+			span.SetAttribute(FRAMEWORK_ID, id)
+		}
+		if id := c.StreamID(); id != "" {
+			span.SetAttribute(STREAM_ID, id)
+		}
+		if ids := callTasks(call); len(ids) > 0 {
+			span.SetAttribute(TASK_IDS, strings.Join(ids, ","))
+		}
+		if ids := callOffers(call); len(ids) > 0 {
+			span.SetAttribute(OFFER_IDS, strings.Join(ids, ","))
+		}
+
+		resp, err := next(ctx, call)
+		if resp != nil {
+			span.SetAttribute(STATUS_CODE, resp.StatusCode)
+		}
+		if err != nil {
+			span.RecordError(err)
+		}
+
+		return resp, err
+	}
+}
+
+// Traces the handling of every event, named after the event's type.
+func EventInterceptor(tracer Tracer, c client.Client) scheduler.EventInterceptor {
+	return func(event *sched.Event, next scheduler.EventInvoker) {
+		_, span := tracer.Start(context.Background(), "mesos.event."+event.GetType().String())
+		defer span.End()
+
+		span.SetAttribute(EVENT_TYPE, event.GetType().String())
+		if id := c.StreamID(); id != "" {
+			span.SetAttribute(STREAM_ID, id)
+		}
+
+		switch event.GetType() {
+		case sched.Event_OFFERS:
+			var ids []string
+			for _, offer := range event.GetOffers().GetOffers() {
+				ids = append(ids, offer.GetId().GetValue())
+			}
+			span.SetAttribute(OFFER_IDS, strings.Join(ids, ","))
+		case sched.Event_RESCIND:
+			span.SetAttribute(OFFER_IDS, event.GetRescind().GetOfferId().GetValue())
+		case sched.Event_UPDATE:
+			status := event.GetUpdate().GetStatus()
+			span.SetAttribute(TASK_IDS, status.GetTaskId().GetValue())
+			span.SetAttribute(AGENT_ID, status.GetAgentId().GetValue())
+		case sched.Event_FAILURE:
+			span.SetAttribute(AGENT_ID, event.GetFailure().GetAgentId().GetValue())
+		}
+
+		next(event)
+	}
+}
+
+// Gets the IDs of the tasks a call acts on.
+func callTasks(call *sched.Call) []string {
+	var ids []string
+	switch call.GetType() {
+	case sched.Call_ACCEPT:
+		for _, op := range call.GetAccept().GetOperations() {
+			for _, t := range op.GetLaunch().GetTaskInfos() {
+				ids = append(ids, t.GetTaskId().GetValue())
+			}
+			for _, t := range op.GetLaunchGroup().GetTaskGroup().GetTasks() {
+				ids = append(ids, t.GetTaskId().GetValue())
+			}
+		}
+	case sched.Call_KILL:
+		ids = append(ids, call.GetKill().GetTaskId().GetValue())
+	case sched.Call_ACKNOWLEDGE:
+		ids = append(ids, call.GetAcknowledge().GetTaskId().GetValue())
+	case sched.Call_RECONCILE:
+		for _, t := range call.GetReconcile().GetTasks() {
+			ids = append(ids, t.GetTaskId().GetValue())
+		}
+	}
+
+	return ids
+}
+
+// Gets the IDs of the offers a call responds to.
+func callOffers(call *sched.Call) []string {
+	var offers []*mesos_v1.OfferID
+	switch call.GetType() {
+	case sched.Call_ACCEPT:
+		offers = call.GetAccept().GetOfferIds()
+	case sched.Call_DECLINE:
+		offers = call.GetDecline().GetOfferIds()
+	}
+
+	ids := make([]string, 0, len(offers))
+	for _, id := range offers {
+		ids = append(ids, id.GetValue())
+	}
+
+	return ids
+}
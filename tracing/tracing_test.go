@@ -0,0 +1,153 @@
+// Copyright 2017 Verizon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"github.com/verizonlabs/mesos-framework-sdk/client"
+	"github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1"
+	sched "github.com/verizonlabs/mesos-framework-sdk/include/mesos_v1_scheduler"
+	"github.com/verizonlabs/mesos-framework-sdk/utils"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+type mockClient struct{}
+
+func (m *mockClient) Request(interface{}) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusAccepted}, nil
+}
+
+func (m *mockClient) RequestContext(ctx context.Context, call interface{}) (*http.Response, error) {
+	return m.Request(call)
+}
+
+func (m *mockClient) StreamID() string {
+	return "stream"
+}
+
+func (m *mockClient) SetStreamID(string) client.Client {
+	return m
+}
+
+type span struct {
+	name       string
+	attributes map[string]interface{}
+	err        error
+	ended      bool
+}
+
+func (s *span) SetAttribute(key string, value interface{}) { s.attributes[key] = value }
+func (s *span) RecordError(err error)                      { s.err = err }
+func (s *span) End()                                       { s.ended = true }
+
+type recordingTracer struct {
+	spans []*span
+	sync.Mutex
+}
+
+func (r *recordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	r.Lock()
+	defer r.Unlock()
+	s := &span{name: name, attributes: make(map[string]interface{})}
+	r.spans = append(r.spans, s)
+	return ctx, s
+}
+
+// Ensures calls are traced with the tasks and offers they act on.
+func TestInterceptor(t *testing.T) {
+	t.Parallel()
+
+	tracer := &recordingTracer{}
+	c := new(mockClient)
+	intercept := Interceptor(tracer, c)
+	call := &sched.Call{
+		Type:        sched.Call_ACCEPT.Enum(),
+		FrameworkId: &mesos_v1.FrameworkID{Value: utils.ProtoString("framework")},
+		Accept: &sched.Call_Accept{
+			OfferIds: []*mesos_v1.OfferID{{Value: utils.ProtoString("offer")}},
+			Operations: []*mesos_v1.Offer_Operation{{
+				Type: mesos_v1.Offer_Operation_LAUNCH.Enum(),
+				Launch: &mesos_v1.Offer_Operation_Launch{TaskInfos: []*mesos_v1.TaskInfo{
+					{TaskId: &mesos_v1.TaskID{Value: utils.ProtoString("a")}},
+					{TaskId: &mesos_v1.TaskID{Value: utils.ProtoString("b")}},
+				}},
+			}},
+		},
+	}
+	intercept(context.Background(), call, func(ctx context.Context, call *sched.Call) (*http.Response, error) {
+		return c.RequestContext(ctx, call)
+	})
+
+	s := tracer.spans[0]
+	if s.name != "mesos.ACCEPT" || !s.ended || s.err != nil {
+		t.Fatal("Calls should be traced in a span that ends with the call")
+	}
+	if s.attributes[TASK_IDS] != "a,b" || s.attributes[OFFER_IDS] != "offer" || s.attributes[STREAM_ID] != "stream" ||
+		s.attributes[FRAMEWORK_ID] != "framework" || s.attributes[STATUS_CODE] != http.StatusAccepted {
+		t.Fatalf("Unexpected attributes %v", s.attributes)
+	}
+
+	intercept(context.Background(), &sched.Call{Type: sched.Call_REVIVE.Enum()},
+		func(ctx context.Context, call *sched.Call) (*http.Response, error) {
+			return nil, errors.New("unreachable")
+		})
+	if tracer.spans[1].err == nil {
+		t.Fatal("Failed calls should record their error")
+	}
+}
+
+// Measures performance of tracing a call.
+func BenchmarkInterceptor(b *testing.B) {
+	c := new(mockClient)
+	intercept := Interceptor(&recordingTracer{}, c)
+	call := &sched.Call{Type: sched.Call_REVIVE.Enum()}
+	for n := 0; n < b.N; n++ {
+		intercept(context.Background(), call, func(ctx context.Context, call *sched.Call) (*http.Response, error) {
+			return c.RequestContext(ctx, call)
+		})
+	}
+}
+
+// Ensures events are traced with the task they're about.
+func TestEventInterceptor(t *testing.T) {
+	t.Parallel()
+
+	tracer := &recordingTracer{}
+	handled := false
+	EventInterceptor(tracer, new(mockClient))(&sched.Event{
+		Type: sched.Event_UPDATE.Enum(),
+		Update: &sched.Event_Update{Status: &mesos_v1.TaskStatus{
+			TaskId:  &mesos_v1.TaskID{Value: utils.ProtoString("a")},
+			AgentId: &mesos_v1.AgentID{Value: utils.ProtoString("agent")},
+		}},
+	}, func(*sched.Event) { handled = true })
+
+	s := tracer.spans[0]
+	if !handled || !s.ended || s.name != "mesos.event.UPDATE" || s.attributes[TASK_IDS] != "a" || s.attributes[AGENT_ID] != "agent" {
+		t.Fatalf("Unexpected span %v", s)
+	}
+}
+
+// Measures performance of tracing an event.
+func BenchmarkEventInterceptor(b *testing.B) {
+	intercept := EventInterceptor(&recordingTracer{}, new(mockClient))
+	event := &sched.Event{Type: sched.Event_HEARTBEAT.Enum()}
+	for n := 0; n < b.N; n++ {
+		intercept(event, func(*sched.Event) {})
+	}
+}
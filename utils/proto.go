@@ -37,3 +37,7 @@ func ProtoInt32(i int32) *int32 {
 func ProtoUint32(i uint32) *uint32 {
 	return &i
 }
+
+func ProtoUint64(i uint64) *uint64 {
+	return &i
+}